@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+// newApplyCmd creates the `apply` command
+func newApplyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply <plan-file>",
+		Short: "Make the changes of a saved plan",
+		Long: `Make the changes of a plan saved with --plan by a bulk command such as labrat spoke
+hibernate, spoke resume, spoke delete, spoke label, or spoke annotate.
+
+The plan is shown and must be confirmed before anything is changed; pass --yes to skip
+the confirmation, which is required without a terminal. The command of the plan then
+runs with the flags it was planned with against exactly the clusters in the plan, even
+if its selector would match other clusters by now. The plan must be applied to the hub
+it was made against.
+
+Examples:
+  # Plan to hibernate every cluster of a partner, review the plan, and apply it
+  labrat spoke hibernate --selector partner=acme --plan hibernate.yaml
+  labrat apply hibernate.yaml
+
+  # Apply a reviewed plan in a pipeline
+  labrat apply cleanup.yaml --yes`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yes, _ := cmd.Flags().GetBool("yes")
+
+			p, err := plan.Load(args[0])
+			if err != nil {
+				return err
+			}
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if p.Hub != cfg.HubName() {
+				return fmt.Errorf("plan was made against hub %s but hub %s is selected, pass --hub %s", p.Hub, cfg.HubName(), p.Hub)
+			}
+
+			target, _, err := cmd.Root().Find(strings.Fields(p.Command))
+			if err != nil || target == cmd.Root() || target.Flags().Lookup("plan") == nil {
+				return fmt.Errorf("labrat %s cannot apply plans", p.Command)
+			}
+			if err := target.ParseFlags(nil); err != nil {
+				return err
+			}
+			names := make([]string, 0, len(p.Flags))
+			for name := range p.Flags {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				if unplannedFlags[name] {
+					return fmt.Errorf("plan sets --%s, which plans cannot set", name)
+				}
+				if err := target.Flags().Set(name, p.Flags[name]); err != nil {
+					return fmt.Errorf("invalid flag --%s of plan: %w", name, err)
+				}
+			}
+
+			if err := p.WriteSummary(os.Stdout); err != nil {
+				return err
+			}
+			if !yes {
+				if !isTerminal(os.Stdin) {
+					return fmt.Errorf("refusing to apply the plan without confirmation, pass --yes")
+				}
+				prompt := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+				if !prompt.confirm(fmt.Sprintf("Apply the plan to %d clusters?", len(p.Changes))) {
+					return fmt.Errorf("apply aborted")
+				}
+			}
+
+			if err := target.Flags().Set("yes", "true"); err != nil {
+				return err
+			}
+			return target.RunE(target, append(p.Clusters(), p.Args...))
+		},
+	}
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation before applying the plan")
+	return cmd
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/audit"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	// auditLog records the runs of audited commands; nil until the config is loaded, in which
+	// case runs are recorded in the default audit log
+	auditLog *audit.Logger
+	// auditHub is the name of the hub the audited command runs against
+	auditHub string
+	// auditTargets are the clusters a bulk command selected with --selector, --clusterset, or
+	// --all; they are recorded instead of the targets given as arguments
+	auditTargets []string
+)
+
+// setupAudit writes the audit records to the audit log configured by cfg
+func setupAudit(cfg *config.Config) {
+	file := cfg.Audit.File
+	if file == "" {
+		file = config.ExpandPath(audit.DefaultFile)
+	}
+	auditLog = audit.NewLogger(audit.NewFileSink(file))
+	auditHub = cfg.HubName()
+}
+
+// setupHubAudit also writes the audit records to the hub of kubeClient if audit.configMap of
+// cfg is set
+func setupHubAudit(cfg *config.Config, kubeClient *kube.Client) {
+	if !cfg.Audit.ConfigMap {
+		return
+	}
+	auditLog.AddSink(audit.NewConfigMapSink(kubeClient.GetCoreClient().CoreV1(), cfg.Hub.Namespace, clientOptions...))
+}
+
+// audited records every run of cmd in the audit log with its arguments, flags, and result.
+// Runs with --dry-run or --plan change nothing and are not recorded. A record that cannot be written
+// is reported on stderr without failing the command, which has already run.
+func audited(cmd *cobra.Command) *cobra.Command {
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		err := run(cmd, args)
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return err
+		}
+		if planPath(cmd) != "" {
+			return err
+		}
+
+		record := audit.Record{
+			Source:  audit.SourceCLI,
+			Action:  cmd.CommandPath(),
+			Targets: args,
+			Hub:     auditHub,
+			Result:  audit.ResultSuccess,
+		}
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			record.Targets, record.Args = args[:dash], args[dash:]
+		}
+		if auditTargets != nil {
+			record.Targets, record.Args = auditTargets, args
+		}
+		cmd.Flags().Visit(func(flag *pflag.Flag) {
+			if record.Flags == nil {
+				record.Flags = make(map[string]string)
+			}
+			record.Flags[flag.Name] = flag.Value.String()
+		})
+		if err != nil {
+			record.Result = audit.ResultFailure
+			record.Error = err.Error()
+		}
+
+		if auditLog == nil {
+			setupAudit(config.NewDefaultConfig())
+		}
+		if logErr := auditLog.Log(context.Background(), record); logErr != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Failed to write audit record: %v\n", logErr)
+		}
+		return err
+	}
+	return cmd
+}
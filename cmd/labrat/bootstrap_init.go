@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// bootstrapInitReportName is the report name of the connectivity checks of `bootstrap init`
+const bootstrapInitReportName = "labrat.bootstrap.init"
+
+// defaultInitConfigPath is where `bootstrap init` writes the config unless --config is given
+const defaultInitConfigPath = "~/.labrat/config.yaml"
+
+// newBootstrapInitCmd creates the `bootstrap init` command
+func newBootstrapInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Initialize local labrat configuration",
+		Long: `Generate a labrat configuration file, by default ~/.labrat/config.yaml.
+
+The contexts of the hub kubeconfig are detected and offered as choices, then the
+hub namespace and spoke defaults are prompted for. Values given as flags are not
+prompted for. With --non-interactive, or when stdin is not a terminal, only flags and
+defaults are used.
+
+Before the file is written, the chosen hub is checked the same way as bootstrap
+validate: the kubeconfig must be usable, the API reachable, and the namespace present.
+An existing config file is only overwritten with --force.
+
+Examples:
+  # Answer the prompts
+  labrat bootstrap init
+
+  # Generate a config without prompts
+  labrat bootstrap init --non-interactive --context hub-admin --provider aws --region us-east-1
+
+  # Write the config somewhere else
+  labrat bootstrap init -c ./config.yaml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			configPath := defaultInitConfigPath
+			if cmd.Flags().Changed("config") {
+				configPath, _ = cmd.Flags().GetString("config")
+			}
+			configPath = config.ExpandPath(configPath)
+			force, _ := cmd.Flags().GetBool("force")
+			skipValidation, _ := cmd.Flags().GetBool("skip-validation")
+			nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+
+			if _, err := os.Stat(configPath); err == nil && !force {
+				return fmt.Errorf("config file %s already exists, use --force to overwrite it", configPath)
+			}
+
+			var p *prompter
+			if !nonInteractive && isTerminal(os.Stdin) {
+				p = &prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+				fmt.Fprintf(os.Stderr, "⚙️  Initializing LABRAT configuration in %s\n\n", configPath)
+			}
+
+			cfg, err := initConfig(cmd, p)
+			if err != nil {
+				return err
+			}
+
+			if !skipValidation {
+				report := check.Report{Name: bootstrapInitReportName}
+				checkHubConnectivity(cmd.Context(), &report, "Hub", cfg.Hub)
+				if err := check.NewWriter(check.OutputFormatTable, os.Stderr).Write(report); err != nil {
+					return fmt.Errorf("failed to write output: %w", err)
+				}
+				if err := report.Err(); err != nil {
+					return fmt.Errorf("hub connectivity check failed, config not written: %w", err)
+				}
+			}
+
+			if err := cfg.Save(configPath); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Config written to %s\n", configPath)
+			if configPath != config.ExpandPath(defaultInitConfigPath) {
+				fmt.Fprintf(os.Stderr, "  Pass it to labrat with: -c %s\n", configPath)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("hub-kubeconfig", "", "Kubeconfig of the hub (default: $KUBECONFIG or ~/.kube/config)")
+	cmd.Flags().String("context", "", "Kubeconfig context of the hub (default: current context)")
+	cmd.Flags().String("namespace", config.NewDefaultConfig().Hub.Namespace, "Hub namespace")
+	cmd.Flags().String("hub-name", "", "Name of the hub (default: \"default\")")
+	cmd.Flags().String("provider", "", "Default spoke provider ("+strings.Join(config.SupportedProviders, "|")+")")
+	cmd.Flags().String("region", "", "Default spoke region")
+	cmd.Flags().Bool("non-interactive", false, "Do not prompt, use flags and defaults only")
+	cmd.Flags().Bool("force", false, "Overwrite an existing config file")
+	cmd.Flags().Bool("skip-validation", false, "Write the config without checking hub connectivity")
+	return cmd
+}
+
+// initConfig builds the config of `bootstrap init` from its flags, prompting with p for the
+// values not given as flags. A nil p never prompts.
+func initConfig(cmd *cobra.Command, p *prompter) (*config.Config, error) {
+	cfg := config.NewDefaultConfig()
+
+	kubeconfig, _ := cmd.Flags().GetString("hub-kubeconfig")
+	if kubeconfig == "" {
+		kubeconfig = defaultKubeconfigPath()
+	}
+	if !cmd.Flags().Changed("hub-kubeconfig") {
+		kubeconfig = p.ask("Hub kubeconfig", kubeconfig)
+	}
+	cfg.Hub.Kubeconfig = config.ExpandPath(kubeconfig)
+
+	contexts, current, err := kube.Contexts(cfg.Hub.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("kubeconfig %s has no contexts", cfg.Hub.Kubeconfig)
+	}
+	cfg.Hub.Context, _ = cmd.Flags().GetString("context")
+	if cmd.Flags().Changed("context") {
+		if !slices.Contains(contexts, cfg.Hub.Context) {
+			return nil, fmt.Errorf("context %q not found in %s (contexts: %s)", cfg.Hub.Context, cfg.Hub.Kubeconfig, strings.Join(contexts, ", "))
+		}
+	} else {
+		if current == "" {
+			current = contexts[0]
+		}
+		cfg.Hub.Context = p.choose("Hub context", contexts, current)
+	}
+
+	namespace, _ := cmd.Flags().GetString("namespace")
+	if !cmd.Flags().Changed("namespace") {
+		namespace = p.ask("Hub namespace", namespace)
+	}
+	cfg.Hub.Namespace = namespace
+
+	hubName, _ := cmd.Flags().GetString("hub-name")
+	if !cmd.Flags().Changed("hub-name") {
+		hubName = p.ask("Hub name", config.DefaultHubName)
+	}
+	if hubName != config.DefaultHubName {
+		cfg.Hub.Name = hubName
+	}
+
+	provider, _ := cmd.Flags().GetString("provider")
+	if !cmd.Flags().Changed("provider") {
+		provider = p.ask("Default spoke provider ("+strings.Join(config.SupportedProviders, "|")+")", provider)
+	}
+	if provider != "" && !slices.Contains(config.SupportedProviders, provider) {
+		return nil, fmt.Errorf("unsupported provider %q (supported: %v)", provider, config.SupportedProviders)
+	}
+	cfg.Defaults.Spoke.Provider = provider
+
+	region, _ := cmd.Flags().GetString("region")
+	if !cmd.Flags().Changed("region") {
+		region = p.ask("Default spoke region", region)
+	}
+	cfg.Defaults.Spoke.Region = region
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// defaultKubeconfigPath returns the kubeconfig labrat falls back to without a config, or
+// ~/.kube/config if there is none
+func defaultKubeconfigPath() string {
+	if path := kube.ResolveKubeconfig(); path != "" {
+		return path
+	}
+	return "~/.kube/config"
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// prompter asks for config values on a terminal. A nil prompter returns the defaults.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prompts for a value, returning def when the answer is empty
+func (p *prompter) ask(label, def string) string {
+	if p == nil {
+		return def
+	}
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", label)
+	}
+	answer, _ := p.in.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// confirm asks a yes/no question, returning true only for an explicit yes
+func (p *prompter) confirm(question string) bool {
+	if p == nil {
+		return false
+	}
+	answer := strings.ToLower(p.ask(question+" [y/N]", ""))
+	return answer == "y" || answer == "yes"
+}
+
+// choose prompts for one of options, by number or name, until a valid answer is given
+func (p *prompter) choose(label string, options []string, def string) string {
+	if p == nil {
+		return def
+	}
+	fmt.Fprintf(p.out, "%s:\n", label)
+	for i, option := range options {
+		marker := " "
+		if option == def {
+			marker = "*"
+		}
+		fmt.Fprintf(p.out, "  %s %d) %s\n", marker, i+1, option)
+	}
+	for {
+		answer := p.ask("Choose a number or name", def)
+		if slices.Contains(options, answer) {
+			return answer
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1]
+		}
+		fmt.Fprintf(p.out, "Invalid choice %q\n", answer)
+		if _, err := p.in.Peek(1); err != nil {
+			return def
+		}
+	}
+}
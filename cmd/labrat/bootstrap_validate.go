@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// bootstrapValidateReportName is the report (and JUnit test suite) name of `bootstrap validate`
+const bootstrapValidateReportName = "labrat.bootstrap.validate"
+
+// newBootstrapValidateCmd creates the `bootstrap validate` command
+func newBootstrapValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the local labrat configuration and hub connectivity",
+		Long: `Validate that the labrat configuration can be loaded, that the hub kubeconfig
+works, and that the configured hub namespace exists.
+
+The command exits non-zero if any check fails.
+
+Examples:
+  # Validate the default configuration
+  labrat bootstrap validate
+
+  # Validate a specific config and write a JUnit report
+  labrat bootstrap validate -c ./config.yaml -o junit > validate.xml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			configPath, _ := cmd.Flags().GetString("config")
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			report := validateBootstrap(cmd.Context(), config.ExpandPath(configPath))
+
+			if err := check.NewWriter(check.OutputFormat(outputFormat), os.Stdout).Write(report); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+
+			return report.Err()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json|junit)")
+	return cmd
+}
+
+// validateBootstrap checks the configuration at configPath and connectivity to the hub it points to.
+// Checks that depend on an earlier failed check are not run.
+func validateBootstrap(ctx context.Context, configPath string) check.Report {
+	report := check.Report{Name: bootstrapValidateReportName}
+
+	var cfg *config.Config
+	result := report.Run("Config file valid", func() (check.Status, string) {
+		var err error
+		cfg, err = config.Load(configPath)
+		if err != nil {
+			return check.StatusFail, err.Error()
+		}
+		return check.StatusPass, configPath
+	})
+	if result.Status == check.StatusFail {
+		return report
+	}
+
+	report.Run("Default spoke provider supported", func() (check.Status, string) {
+		provider := cfg.Defaults.Spoke.Provider
+		if provider == "" {
+			return check.StatusWarn, "defaults.spoke.provider is not set"
+		}
+		if !slices.Contains(config.SupportedProviders, provider) {
+			return check.StatusWarn, fmt.Sprintf("unknown provider %q (supported: %v)", provider, config.SupportedProviders)
+		}
+		return check.StatusPass, provider
+	})
+
+	checkHubConnectivity(ctx, &report, "Hub", cfg.Hub)
+
+	return report
+}
+
+// checkHubConnectivity adds checks that the kubeconfig of a hub is usable, that its API is
+// reachable, and that its namespace exists, each prefixed with label. It returns the hub
+// client, or nil if the hub cannot be reached.
+func checkHubConnectivity(ctx context.Context, report *check.Report, label string, h config.HubConfig) *kube.Client {
+	var kubeClient *kube.Client
+	result := report.Run(label+" kubeconfig usable", func() (check.Status, string) {
+		var err error
+		kubeClient, err = hubBackend.NewClient(h.Kubeconfig, h.Context, clientOptions...)
+		if err != nil {
+			return check.StatusFail, err.Error()
+		}
+		return check.StatusPass, h.Kubeconfig
+	})
+	if result.Status == check.StatusFail {
+		return nil
+	}
+
+	result = report.Run(label+" API reachable", func() (check.Status, string) {
+		version, err := kubeClient.GetCoreClient().Discovery().ServerVersion()
+		if err != nil {
+			return check.StatusFail, fmt.Sprintf("failed to reach API server: %v", err)
+		}
+		return check.StatusPass, fmt.Sprintf("Kubernetes %s", version.GitVersion)
+	})
+	if result.Status == check.StatusFail {
+		return nil
+	}
+
+	report.Run(label+" namespace exists", func() (check.Status, string) {
+		_, err := kubeClient.GetCoreClient().CoreV1().Namespaces().Get(ctx, h.Namespace, metav1.GetOptions{})
+		if err != nil {
+			return check.StatusFail, fmt.Sprintf("namespace %s: %v", h.Namespace, err)
+		}
+		return check.StatusPass, h.Namespace
+	})
+
+	return kubeClient
+}
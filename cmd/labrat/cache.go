@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+// newCacheCmd creates the `cache` command group
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the on-disk cache of cluster lists",
+		Long: `Manage the on-disk cache of cluster lists.
+
+When the cache section of the config sets a ttl, the ManagedCluster and
+ClusterDeployment lists of each hub are stored in ~/.labrat/cache and reused
+until they expire, so repeated listings over a slow connection return at once.
+Use --no-cache to list from the hub for a single command.`,
+	}
+	cmd.AddCommand(newCacheClearCmd())
+	return cmd
+}
+
+// newCacheClearCmd creates the `cache clear` command
+func newCacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove every cached cluster list",
+		Long: `Remove every cached cluster list, so the next listing reads from the hub.
+
+Examples:
+  # Clear the cache after changing clusters outside of labrat
+  labrat cache clear`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			removed, err := cache.New(cacheDir(cfg), cfg.Cache.TTL).Clear()
+			if err != nil {
+				return fmt.Errorf("failed to clear cache: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "✓ Removed %d cache entries\n", removed)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/cache"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/kubefile"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/log"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// clientOptions are passed to every Kubernetes client the commands create, set by
+// setupClientOptions
+var clientOptions []kube.Option
+
+// logger is the logger of the commands, built from --log-level, --log-format, and -v
+var logger = slog.New(slog.DiscardHandler)
+
+// setupLogging builds the logger of the commands, passed to every client by
+// setupClientOptions. Without --log-level, -v logs the API requests of every client to stderr.
+func setupLogging(cmd *cobra.Command) error {
+	verbosity, _ := cmd.Flags().GetInt("verbose")
+	level := log.LevelForVerbosity(verbosity)
+	if name, _ := cmd.Flags().GetString("log-level"); name != "" {
+		parsed, err := log.ParseLevel(name)
+		if err != nil {
+			return err
+		}
+		level = parsed
+	}
+
+	format, _ := cmd.Flags().GetString("log-format")
+	l, err := log.New(os.Stderr, level, log.Format(format))
+	if err != nil {
+		return err
+	}
+
+	logger = l
+	return nil
+}
+
+// setupClientOptions sets the options of every client: the logger, kube.DefaultRequestTimeout
+// for every API request, and retries of API calls that fail transiently, as set by --retries
+// and --retry-backoff or, for flags that are not given, by the retry section of cfg. It is
+// called before the config is loaded with a nil cfg, and again once the config is loaded,
+// replacing the options set before.
+func setupClientOptions(cmd *cobra.Command, cfg *config.Config) error {
+	retries, _ := cmd.Flags().GetInt("retries")
+	backoff, _ := cmd.Flags().GetDuration("retry-backoff")
+	if cfg != nil {
+		if cfg.Retry.Retries != nil && !cmd.Flags().Changed("retries") {
+			retries = *cfg.Retry.Retries
+		}
+		if cfg.Retry.Backoff > 0 && !cmd.Flags().Changed("retry-backoff") {
+			backoff = cfg.Retry.Backoff
+		}
+	}
+	if retries < 0 {
+		return fmt.Errorf("--retries must not be negative, got %d", retries)
+	}
+	if backoff <= 0 {
+		return fmt.Errorf("--retry-backoff must be positive, got %s", backoff)
+	}
+
+	clientOptions = []kube.Option{
+		kube.WithLogger(logger),
+		kube.WithRequestTimeout(kube.DefaultRequestTimeout),
+		kube.WithRetry(retries, backoff),
+	}
+	return nil
+}
+
+// cancelTimeout releases the deadline set by --timeout; nil without one
+var cancelTimeout context.CancelFunc
+
+// setupTimeout bounds the command by the global --timeout. Commands that wait bound the wait
+// by --wait-timeout.
+func setupTimeout(cmd *cobra.Command) error {
+	timeout, _ := cmd.Root().PersistentFlags().GetDuration("timeout")
+	if timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative, got %s", timeout)
+	}
+	if timeout > 0 {
+		var ctx context.Context
+		ctx, cancelTimeout = context.WithTimeoutCause(cmd.Context(), timeout, fmt.Errorf("labrat did not finish within --timeout %s", timeout))
+		cmd.SetContext(ctx)
+	}
+	return nil
+}
+
+// addWaitTimeoutFlag adds --wait-timeout, bounding the wait of a command, and the local
+// --timeout it replaced, deprecated as it shadows the global --timeout
+func addWaitTimeoutFlag(cmd *cobra.Command, value time.Duration, usage string) {
+	cmd.Flags().Duration("wait-timeout", value, usage)
+	cmd.Flags().Duration("timeout", value, usage)
+	_ = cmd.Flags().MarkDeprecated("timeout", "use --wait-timeout; --timeout will bound the whole command")
+}
+
+// waitTimeout returns --wait-timeout, or the deprecated local --timeout if only it is given
+func waitTimeout(cmd *cobra.Command) time.Duration {
+	name := "wait-timeout"
+	if !cmd.Flags().Changed(name) && cmd.Flags().Changed("timeout") {
+		name = "timeout"
+	}
+	timeout, _ := cmd.Flags().GetDuration(name)
+	return timeout
+}
+
+// hubBackend creates the clients of the hubs: the API servers of their kubeconfigs, or the
+// fixture files of --backend file://<dir>
+var hubBackend kube.Backend
+
+// setupBackend sets hubBackend from --backend
+func setupBackend(cmd *cobra.Command) error {
+	value, _ := cmd.Root().PersistentFlags().GetString("backend")
+	backend, err := kubefile.ParseBackend(value)
+	if err != nil {
+		return err
+	}
+	hubBackend = backend
+	return nil
+}
+
+// listCache stores cluster lists between invocations; nil unless the cache section of the
+// config sets a TTL and --no-cache is not given
+var listCache hub.ListCache
+
+// setupCache enables the on-disk cache of cluster lists configured by the cache section of cfg
+func setupCache(cmd *cobra.Command, cfg *config.Config) {
+	listCache = nil
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache || cfg.Cache.TTL == 0 {
+		return
+	}
+	listCache = cache.New(cacheDir(cfg), cfg.Cache.TTL)
+}
+
+// cacheDir returns the directory of the on-disk cache configured by cfg
+func cacheDir(cfg *config.Config) string {
+	if cfg.Cache.Dir != "" {
+		return cfg.Cache.Dir
+	}
+	return config.ExpandPath(cache.DefaultDir)
+}
+
+// newHubBackend creates the hub.Backend of the clusters of kubeClient
+func newHubBackend(kubeClient *kube.Client) hub.Backend {
+	return hub.NewDynamicBackend(kubeClient.GetDynamicClient(), clientOptions...)
+}
+
+// newListingHubBackend creates the hub.Backend of the clusters of kubeClient whose lists are
+// served from the cache if it is enabled, for commands that only display them
+func newListingHubBackend(kubeClient *kube.Client) hub.Backend {
+	backend := newHubBackend(kubeClient)
+	if listCache == nil {
+		return backend
+	}
+	return hub.NewCachedBackend(backend, listCache, kubeClient.Host())
+}
+
+// warnDecodeErrors reports on stderr the ClusterDeployments a listing could not decode and
+// returns nil, so commands that only display lists go on with the others. Other errors are
+// returned unchanged.
+func warnDecodeErrors(err error) error {
+	var decodeErr *hub.ClusterDeploymentDecodeError
+	if !errors.As(err, &decodeErr) {
+		return err
+	}
+	for _, e := range decodeErr.Errs {
+		fmt.Fprintf(os.Stderr, "⚠️  Skipping %v\n", e)
+	}
+	return nil
+}
+
+// loadConfig loads the labrat config referenced by the persistent --config flag and
+// activates the hub selected with --hub. With --hub all the primary hub stays active.
+// If --config is not given and the default config file does not exist, the default config
+// is used, whose hub is resolved by kube.NewClient.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	defer timings.Start("load config")()
+
+	configPath, _ := cmd.Flags().GetString("config")
+	hubName, _ := cmd.Flags().GetString("hub")
+
+	// Expand path to support both $HOME and ~
+	cfg, err := config.Load(config.ExpandPath(configPath))
+	if errors.Is(err, fs.ErrNotExist) && !cmd.Flags().Changed("config") {
+		// Without a config file, e.g. in CI jobs and cluster cronjobs, the hub is reached through
+		// LABRAT_HUB_KUBECONFIG, $KUBECONFIG, ~/.kube/config, or the in-cluster config
+		cfg, err = config.Default()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if hubName != config.AllHubs {
+		if err := cfg.SelectHub(hubName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := setupClientOptions(cmd, cfg); err != nil {
+		return nil, err
+	}
+	setupCache(cmd, cfg)
+	setupAudit(cfg)
+	return cfg, nil
+}
+
+// informerSyncTimeout is how long long-running commands wait for the hub cache to list the
+// clusters before giving up
+const informerSyncTimeout = 2 * time.Minute
+
+// startHubCache starts a hub.NewInformerCache of the hub and waits for its initial list.
+// The informers run until ctx is done.
+func startHubCache(ctx context.Context, kubeClient *kube.Client, resync time.Duration, withClusterInfo bool) (kube.InformerCache, error) {
+	if resync < 0 {
+		return nil, fmt.Errorf("--resync must not be negative, got %s", resync)
+	}
+	informerCache := hub.NewInformerCache(kubeClient.GetDynamicClient(), resync, withClusterInfo, clientOptions...)
+	informerCache.Start(ctx)
+
+	syncCtx, cancel := context.WithTimeoutCause(ctx, informerSyncTimeout, fmt.Errorf("hub not listed within %s", informerSyncTimeout))
+	defer cancel()
+	if err := informerCache.WaitForSync(syncCtx); err != nil {
+		return nil, err
+	}
+	return informerCache, nil
+}
+
+// newHubClient loads the labrat config and creates a Kubernetes client for the selected hub
+func newHubClient(cmd *cobra.Command) (*config.Config, *kube.Client, error) {
+	if hubName, _ := cmd.Flags().GetString("hub"); hubName == config.AllHubs {
+		return nil, nil, fmt.Errorf("--hub %s is not supported by %s", config.AllHubs, cmd.CommandPath())
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	endPhase := timings.Start("connect to hub")
+	kubeClient, err := hubBackend.NewClient(cfg.GetHubKubeconfig(), cfg.Hub.Context, clientOptions...)
+	endPhase()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	setupHubAudit(cfg, kubeClient)
+
+	return cfg, kubeClient, nil
+}
+
+// newSpokeClient extracts the admin kubeconfig of a spoke cluster from the hub
+// and creates a Kubernetes client connected to the spoke
+func newSpokeClient(ctx context.Context, hubClient *kube.Client, clusterName string) (*kube.Client, error) {
+	extractor := spoke.NewKubeconfigExtractor(
+		hubClient.GetDynamicClient(),
+		hubClient.GetCoreClient().CoreV1(),
+		clientOptions...,
+	)
+
+	defer timings.Start("connect to spoke")()
+
+	kubeconfig, err := extractor.Extract(ctx, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract kubeconfig: %w", err)
+	}
+
+	spokeClient, err := kube.NewClientFromKubeconfig(kubeconfig, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for spoke %s: %w", clusterName, err)
+	}
+
+	return spokeClient, nil
+}
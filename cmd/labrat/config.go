@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configValidateReportName is the report (and JUnit test suite) name of `config validate`
+const configValidateReportName = "labrat.config.validate"
+
+// newConfigCmd creates the `config` command group
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and edit the labrat configuration",
+	}
+	cmd.AddCommand(newConfigValidateCmd(), newConfigViewCmd(), newConfigSetCmd())
+	return cmd
+}
+
+// newConfigValidateCmd creates the `config validate` command
+func newConfigValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Report every problem of the labrat configuration",
+		Long: `Check the configuration file given with --config against the schema of labrat and
+report every problem found, not only the first one as other commands do:
+
+  - required fields that are missing, such as hub.kubeconfig and hub.namespace
+  - values of the wrong type, such as durations like cache.ttl that are not 30s or 2m
+  - invalid values, such as negative retries, CIDRs, and standby or active hubs that are
+    not configured
+  - keys that are not part of the configuration, which labrat ignores (warning)
+  - a defaults.spoke.provider labrat does not support (warning)
+  - kubeconfigs, TLS files, token files, and template directories that do not exist (warning)
+
+The hub is not contacted; use labrat bootstrap validate to check its connectivity. The
+command exits non-zero if there are errors, or with --strict also if there are warnings.
+
+Examples:
+  # Validate the configuration
+  labrat config validate -c ~/.labrat/config.yaml
+
+  # Fail on unknown keys and missing files too, e.g. in CI
+  labrat config validate -c ./config.yaml --strict -o junit > config.xml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			configPath, _ := cmd.Flags().GetString("config")
+			outputFormat, _ := cmd.Flags().GetString("output")
+			strict, _ := cmd.Flags().GetBool("strict")
+
+			report := validateConfigFile(config.ExpandPath(configPath), strict)
+
+			if err := check.NewWriter(check.OutputFormat(outputFormat), os.Stdout).Write(report); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return report.Err()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json|junit)")
+	cmd.Flags().Bool("strict", false, "Fail on warnings too, such as unknown keys and files that do not exist")
+	return cmd
+}
+
+// validateConfigFile reports each problem of the configuration at configPath as a check
+// named after its field; with strict, warnings fail too
+func validateConfigFile(configPath string, strict bool) check.Report {
+	report := check.Report{Name: configValidateReportName}
+
+	problems, err := config.Check(configPath)
+	if err != nil {
+		report.Add(check.Result{Name: "Config file valid", Status: check.StatusFail, Message: err.Error()})
+		return report
+	}
+	if len(problems) == 0 {
+		report.Add(check.Result{Name: "Config file valid", Status: check.StatusPass, Message: configPath})
+		return report
+	}
+
+	for _, problem := range problems {
+		result := check.Result{Name: problem.Field, Status: check.StatusFail, Message: problem.String()}
+		if result.Name == "" {
+			result.Name = "config"
+		}
+		if problem.Severity == config.SeverityWarning && !strict {
+			result.Status = check.StatusWarn
+		}
+		report.Add(result)
+	}
+	return report
+}
+
+// newConfigViewCmd creates the `config view` command
+func newConfigViewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "Print the effective labrat configuration",
+		Long: `Print the configuration labrat runs with: the config file given with --config, or the
+defaults without one, overridden by the LABRAT_ environment variables and by the global
+flags --hub, --retries, and --retry-backoff. Paths are expanded.
+
+The hashes of API tokens and template values whose names suggest secrets, such as
+pullSecret or adminPassword, are printed as REDACTED.
+
+Examples:
+  # Print the effective configuration
+  labrat config view
+
+  # Print the configuration of another hub as JSON
+  labrat config view --hub lab-east -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			if outputFormat != "yaml" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("retries") {
+				retries, _ := cmd.Flags().GetInt("retries")
+				cfg.Retry.Retries = &retries
+			}
+			if cmd.Flags().Changed("retry-backoff") {
+				cfg.Retry.Backoff, _ = cmd.Flags().GetDuration("retry-backoff")
+			}
+
+			return writeConfig(outputFormat, cfg.Redacted())
+		},
+	}
+	cmd.Flags().StringP("output", "o", "yaml", "Output format (yaml|json)")
+	return cmd
+}
+
+// writeConfig prints cfg to stdout as YAML or as JSON with the keys of the config file
+func writeConfig(outputFormat string, cfg *config.Config) error {
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if outputFormat == "yaml" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(out.Bytes(), &values); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	jsonEncoder := json.NewEncoder(os.Stdout)
+	jsonEncoder.SetIndent("", "  ")
+	return jsonEncoder.Encode(values)
+}
+
+// newConfigSetCmd creates the `config set` command
+func newConfigSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a key of the labrat config file",
+		Long: `Set a key of the config file given with --config, keeping its comments and the rest of
+its content. Keys are YAML paths such as defaults.spoke.region, with the index of list items
+as in hubs[1].namespace; missing sections are added, and the file is created if it does not
+exist.
+
+Keys that are not part of the configuration, values of the wrong type, and values that make
+the configuration invalid, such as a negative retry.retries, are refused. Lists and whole
+sections cannot be set.
+
+Examples:
+  # Create clusters in us-west-2 by default
+  labrat config set defaults.spoke.region us-west-2
+
+  # Cache cluster lists for two minutes
+  labrat config set cache.ttl 2m
+
+  # Change the namespace of the second hub
+  labrat config set hubs[1].namespace multicluster-engine`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, _ := cmd.Flags().GetString("config")
+			configPath = config.ExpandPath(configPath)
+
+			if err := config.Set(configPath, args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Set %s in %s\n", args[0], configPath)
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newHubAddOnsCmd creates the `hub addons` command group
+func newHubAddOnsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "addons",
+		Short: "Show the ACM add-ons of all clusters",
+	}
+	cmd.AddCommand(newHubAddOnsStatusCmd())
+	return cmd
+}
+
+// newHubAddOnsStatusCmd creates the `hub addons status` command
+func newHubAddOnsStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of every add-on on every cluster as a matrix",
+		Long: `Show the status of the ACM add-ons of every managed cluster as a matrix with a row per
+cluster and a column per add-on, to catch broken observability, search, or policy agents
+across the fleet at a glance.
+
+Each cell is the status of the ManagedClusterAddOn of the cluster: Available, Degraded,
+Progressing, Unavailable, or Unknown, or Missing if the add-on is installed on the hub
+but not enabled on the cluster. The columns are every add-on installed on the hub or
+enabled on a cluster, or those given with --addon. With --problems only the clusters with
+an add-on that is not available are shown.
+
+The number of clusters with problems is printed to stderr; see labrat spoke addons list
+for the messages of the add-ons of one cluster.
+
+Examples:
+  # Show the add-on matrix
+  labrat hub addons status
+
+  # Check the observability and policy agents, showing only the clusters with problems
+  labrat hub addons status --addon observability-controller --addon config-policy-controller --problems
+
+  # Export the matrix as JSON
+  labrat hub addons status -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			names, _ := cmd.Flags().GetStringSlice("addon")
+			problems, _ := cmd.Flags().GetBool("problems")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			dynamicClient := kubeClient.GetDynamicClient()
+			managedClusters, err := newHubBackend(kubeClient).ManagedClusters().List(ctx)
+			if err != nil {
+				return err
+			}
+			addons := hub.NewManagedClusterAddOnClient(dynamicClient, clientOptions...)
+			available, err := addons.Available(ctx)
+			if err != nil {
+				return err
+			}
+			enabled, err := addons.ListAll(ctx)
+			if err != nil {
+				return err
+			}
+
+			clusters := make([]string, 0, len(managedClusters))
+			for _, cluster := range managedClusters {
+				clusters = append(clusters, cluster.Name)
+			}
+			matrix := hub.NewAddOnMatrix(clusters, available, names, enabled)
+
+			unhealthy := 0
+			rows := make([]hub.AddOnMatrixRow, 0, len(matrix.Clusters))
+			for _, row := range matrix.Clusters {
+				if !row.Healthy() {
+					unhealthy++
+				} else if problems {
+					continue
+				}
+				rows = append(rows, row)
+			}
+			matrix.Clusters = rows
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(matrix, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+			} else if problems && unhealthy == 0 {
+				fmt.Fprintln(os.Stdout, "No clusters with add-on problems found")
+			} else if err := writeAddOnMatrix(matrix); err != nil {
+				return err
+			}
+
+			if unhealthy > 0 {
+				fmt.Fprintf(os.Stderr, "⚠️  %d of %d clusters have add-ons that are not available\n", unhealthy, len(clusters))
+			} else if len(clusters) > 0 {
+				fmt.Fprintf(os.Stderr, "✓ All add-ons available on %d clusters\n", len(clusters))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().StringSlice("addon", nil, "Only show these add-ons (repeatable or comma-separated)")
+	cmd.Flags().Bool("problems", false, "Only show clusters with an add-on that is not available")
+	return cmd
+}
+
+// writeAddOnMatrix prints the add-on matrix as a table with a column per add-on
+func writeAddOnMatrix(matrix hub.AddOnMatrix) error {
+	if len(matrix.Clusters) == 0 {
+		fmt.Fprintln(os.Stdout, "No clusters found")
+		return nil
+	}
+	if len(matrix.AddOns) == 0 {
+		fmt.Fprintln(os.Stdout, "No add-ons found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "CLUSTER\t%s\n", strings.ToUpper(strings.Join(matrix.AddOns, "\t")))
+	for _, row := range matrix.Clusters {
+		cells := make([]string, 0, len(matrix.AddOns))
+		for _, name := range matrix.AddOns {
+			cells = append(cells, row.Status[name])
+		}
+		fmt.Fprintf(w, "%s\t%s\n", row.Cluster, strings.Join(cells, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
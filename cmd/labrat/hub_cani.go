@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newHubCanICmd creates the `hub can-i` command
+func newHubCanICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "can-i",
+		Short: "Check the hub permissions of the current identity",
+		Long: `Check whether the current identity has the hub permissions labrat commands need, such
+as listing ManagedClusters, reading the secrets of cluster namespaces, and patching
+ClusterDeployments. Every permission is reviewed with a SelfSubjectAccessReview and the
+results are printed as one matrix, with the commands that need each permission, instead
+of a command failing midway through an operation.
+
+Clusters live in a namespace each; their resources are checked in every namespace, or
+only in the namespace of the cluster given with --cluster for identities that are bound
+to individual cluster namespaces.
+
+The command exits non-zero if any permission is denied, so it can gate CI pipelines.
+
+Examples:
+  # Check the permissions on the active hub
+  labrat hub can-i
+
+  # Check the permissions on a single cluster's namespace
+  labrat hub can-i --cluster my-cluster
+
+  # Write a JUnit report for CI dashboards
+  labrat hub can-i -o junit > hub-can-i.xml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			clusterName, _ := cmd.Flags().GetString("cluster")
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			checker := hub.NewAccessChecker(kubeClient.GetCoreClient().AuthorizationV1(), clientOptions...)
+			report := checker.Check(cmd.Context(), hub.HubPermissions(cfg.Hub.Namespace, clusterName))
+
+			if err := check.NewWriter(check.OutputFormat(outputFormat), os.Stdout).Write(report); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+
+			return report.Err()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json|junit)")
+	cmd.Flags().String("cluster", "", "Check the cluster resources in the namespace of this cluster only (default: every namespace)")
+	return cmd
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// capacityProviders are the providers whose default credentials hub capacity reads quotas with
+var capacityProviders = []string{cloud.ProviderAWS, cloud.ProviderAzure, cloud.ProviderGCP}
+
+// capacityReport is the usage of the lab per region and the quota headroom of its cloud accounts
+type capacityReport struct {
+	Usage    []hub.RegionUsage `json:"usage"`
+	Accounts []accountCapacity `json:"accounts,omitempty"`
+}
+
+// accountCapacity is the quota headroom of the account of a credential secret in a region, or
+// the error that prevented reading it
+type accountCapacity struct {
+	Credentials string `json:"credentials"`
+	cloud.Capacity
+	// Clusters is how many more clusters fit, -1 if unknown
+	Clusters  int    `json:"clusters"`
+	LimitedBy string `json:"limitedBy,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// newHubCapacityCmd creates the `hub capacity` command
+func newHubCapacityCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "capacity",
+		Short: "Report cluster usage per region and how many more clusters cloud quotas allow",
+		Long: `Report how the lab uses its cloud accounts and how many more clusters can be
+provisioned in each region before a quota runs out.
+
+The usage is aggregated from the ClusterDeployments of the hub: the clusters of each
+platform and region, how many are running and hibernating, and their worker machines by
+instance type from their MachinePools.
+
+The quotas are read with the cloud credential secrets given with --credentials, by
+default the credentials of each provider in defaults.spoke, in the regions given with
+--region, by default every region the provider has clusters in and its default region.
+They are compared with the footprint of a new cluster with the instance types of
+defaults.spoke.<provider>:
+
+  - AWS:   standard On-Demand vCPUs, Elastic IPs (one per zone), and VPCs
+  - GCP:   regional CPUs, the CPUs of the machine family, e.g. N2_CPUS, and SSD GB
+  - Azure: total regional vCPUs
+
+Quotas count what the account runs now: hibernating clusters do not use vCPUs, but will
+again when they are resumed. Accounts whose quotas cannot be read are listed with the
+error and the command exits non-zero.
+
+Examples:
+  # Report the usage and the headroom of the default accounts
+  labrat hub capacity
+
+  # Check two AWS accounts in the regions the lab uses
+  labrat hub capacity --credentials aws-lab-1,aws-lab-2 --region us-east-1 --region us-east-2
+
+  # Export the report for a dashboard
+  labrat hub capacity -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			credentialNames, _ := cmd.Flags().GetStringSlice("credentials")
+			credentialsNamespace, _ := cmd.Flags().GetString("credentials-namespace")
+			regions, _ := cmd.Flags().GetStringSlice("region")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			deployments, err := newHubBackend(kubeClient).ClusterDeployments().List(ctx)
+			if err := warnDecodeErrors(err); err != nil {
+				return err
+			}
+			pools, err := spoke.NewMachinePoolClient(kubeClient.GetDynamicClient(), clientOptions...).ListAll(ctx)
+			if err != nil {
+				return err
+			}
+			report := capacityReport{Usage: hub.SummarizeUsage(deployments, workerMachines(pools))}
+
+			credentials, err := capacityCredentials(ctx, kubeClient, cfg, credentialNames, credentialsNamespace)
+			if err != nil {
+				return err
+			}
+			if len(credentials) == 0 {
+				fmt.Fprintln(os.Stderr, "⚠️  No cloud credentials to read quotas with, pass --credentials or set defaults.spoke.<provider>.credentials")
+			}
+
+			reader := cloud.NewCapacityReader(nil, nil)
+			var failed []string
+			for _, creds := range credentials {
+				name := creds.Namespace + "/" + creds.Name
+				platform := cfg.Defaults.Spoke.Platform(creds.Provider)
+				spec := cloud.ClusterSpec{
+					ControlPlane: cloud.MachinePool{InstanceType: platform.ControlPlaneType},
+					Compute:      cloud.MachinePool{InstanceType: platform.ComputeType},
+				}
+
+				credentialRegions := regions
+				if len(credentialRegions) == 0 {
+					credentialRegions = capacityRegions(cfg, creds.Provider, report.Usage)
+				}
+				if len(credentialRegions) == 0 {
+					fmt.Fprintf(os.Stderr, "⚠️  No region to read the quotas of %s in, pass --region\n", name)
+				}
+
+				for _, region := range credentialRegions {
+					account := accountCapacity{Credentials: name, Clusters: -1}
+					capacity, err := reader.Read(ctx, creds, region, spec)
+					if err != nil {
+						account.Provider, account.Region, account.Error = creds.Provider, region, err.Error()
+						failed = append(failed, name+" in "+region)
+					} else {
+						account.Capacity = *capacity
+						account.Clusters, account.LimitedBy = capacity.Clusters()
+					}
+					report.Accounts = append(report.Accounts, account)
+				}
+			}
+
+			if err := writeCapacity(report, outputFormat); err != nil {
+				return err
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("failed to read the quotas of %s", strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().StringSlice("credentials", nil, "Cloud credential secrets whose accounts to read quotas of (defaults to defaults.spoke.<provider>.credentials of each provider)")
+	cmd.Flags().String("credentials-namespace", "", "Namespace of the credential secrets given with --credentials (defaults to the hub namespace)")
+	cmd.Flags().StringSlice("region", nil, "Regions to read quotas in (defaults to the regions of the clusters of the provider and its default region)")
+	return cmd
+}
+
+// workerMachines counts the machines of each cluster by instance type; autoscaled pools count
+// with their minimum
+func workerMachines(pools map[string][]spoke.MachinePoolInfo) map[string]map[string]int64 {
+	machines := make(map[string]map[string]int64, len(pools))
+	for cluster, clusterPools := range pools {
+		machines[cluster] = make(map[string]int64)
+		for _, pool := range clusterPools {
+			replicas := pool.Replicas
+			if pool.Autoscaled() {
+				replicas = pool.MinReplicas
+			}
+			machines[cluster][pool.InstanceType] += replicas
+		}
+	}
+	return machines
+}
+
+// capacityCredentials reads the credential secrets named with --credentials or, without them,
+// the default credentials of each provider in the config
+func capacityCredentials(ctx context.Context, kubeClient *kube.Client, cfg *config.Config, names []string, namespace string) ([]*cloud.Credentials, error) {
+	client := cloud.NewCredentialClient(kubeClient.GetCoreClient().CoreV1(), clientOptions...)
+
+	type secretRef struct{ namespace, name string }
+	var refs []secretRef
+	if len(names) > 0 {
+		if namespace == "" {
+			namespace = cfg.Hub.Namespace
+		}
+		for _, name := range names {
+			refs = append(refs, secretRef{namespace, name})
+		}
+	} else {
+		for _, provider := range capacityProviders {
+			platform := cfg.Defaults.Spoke.Platform(provider)
+			if platform.Credentials == "" {
+				continue
+			}
+			ns := platform.CredentialsNamespace
+			if ns == "" {
+				ns = cfg.Hub.Namespace
+			}
+			refs = append(refs, secretRef{ns, platform.Credentials})
+		}
+	}
+
+	credentials := make([]*cloud.Credentials, 0, len(refs))
+	for _, ref := range refs {
+		creds, err := client.Get(ctx, ref.namespace, ref.name)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, creds)
+	}
+	return credentials, nil
+}
+
+// capacityRegions returns the regions of the clusters of provider and its default region
+func capacityRegions(cfg *config.Config, provider string, usage []hub.RegionUsage) []string {
+	seen := make(map[string]bool)
+	var regions []string
+	add := func(region string) {
+		if region != "" && region != "N/A" && !seen[region] {
+			seen[region] = true
+			regions = append(regions, region)
+		}
+	}
+
+	for _, u := range usage {
+		if u.Platform == provider {
+			add(u.Region)
+		}
+	}
+	if region := cfg.Defaults.Spoke.Platform(provider).Region; region != "" {
+		add(region)
+	} else if cfg.Defaults.Spoke.Provider == provider {
+		add(cfg.Defaults.Spoke.Region)
+	}
+	if provider == cloud.ProviderAWS && len(regions) == 0 {
+		add(cloud.DefaultAWSRegion)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// writeCapacity prints the capacity report as tables or JSON
+func writeCapacity(report capacityReport, outputFormat string) error {
+	if outputFormat == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	if len(report.Usage) == 0 {
+		fmt.Fprintln(w, "No ClusterDeployments found")
+	} else {
+		fmt.Fprintln(w, "PLATFORM\tREGION\tCLUSTERS\tRUNNING\tHIBERNATING\tWORKERS")
+		for _, u := range report.Usage {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n", u.Platform, u.Region, u.Clusters, u.Running, u.Hibernating, hub.FormatWorkers(u.Workers))
+		}
+	}
+
+	if len(report.Accounts) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "CREDENTIALS\tACCOUNT\tREGION\tMORE CLUSTERS\tLIMITED BY\tQUOTAS")
+		for _, a := range report.Accounts {
+			if a.Error != "" {
+				fmt.Fprintf(w, "%s\t-\t%s\t-\t-\t%s\n", a.Credentials, a.Region, a.Error)
+				continue
+			}
+			clusters := "unlimited"
+			if a.Clusters >= 0 {
+				clusters = fmt.Sprint(a.Clusters)
+			}
+			quotas := make([]string, 0, len(a.Quotas))
+			for _, q := range a.Quotas {
+				quotas = append(quotas, fmt.Sprintf("%s %d/%d (%d each)", q.Name, q.Used, q.Limit, q.PerCluster))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", a.Credentials, a.Account, a.Region, clusters, valueOrNA(a.LimitedBy), strings.Join(quotas, ", "))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// clusterDeploymentRow is a ClusterDeployment as listed by `hub clusterdeployments`
+type clusterDeploymentRow struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	Platform    string `json:"platform,omitempty"`
+	Region      string `json:"region,omitempty"`
+	PowerState  string `json:"powerState,omitempty"`
+	Installed   bool   `json:"installed"`
+	Version     string `json:"version,omitempty"`
+	ClusterPool string `json:"clusterPool,omitempty"`
+	RequestID   string `json:"requestID,omitempty"`
+	// Imported reports whether a ManagedCluster of the same name exists
+	Imported bool `json:"imported"`
+}
+
+// newHubClusterDeploymentsCmd creates the `hub clusterdeployments` command
+func newHubClusterDeploymentsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clusterdeployments",
+		Short: "List Hive ClusterDeployments",
+		Long: `List the Hive ClusterDeployments in all namespaces of the hub, whether or not they
+are imported into ACM as ManagedClusters.
+
+Unlike hub managedclusters, which starts from the ManagedClusters, this lists clusters
+that are still installing, were created by a ClusterPool and not yet claimed, or were
+never imported. The IMPORTED column shows whether a ManagedCluster of the same name
+exists.
+
+Examples:
+  # List all ClusterDeployments
+  labrat hub clusterdeployments
+
+  # Find Hive clusters that were never imported into ACM
+  labrat hub clusterdeployments --not-imported
+
+  # List as JSON
+  labrat hub clusterdeployments -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			notImported, _ := cmd.Flags().GetBool("not-imported")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			deployments, err := newListingHubBackend(kubeClient).ClusterDeployments().List(ctx)
+			if err := warnDecodeErrors(err); err != nil {
+				return err
+			}
+			managedClusters, err := newListingHubBackend(kubeClient).ManagedClusters().List(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list managed clusters: %w", err)
+			}
+
+			imported := make(map[string]bool, len(managedClusters))
+			for _, mc := range managedClusters {
+				imported[mc.Name] = true
+			}
+
+			rows := make([]clusterDeploymentRow, 0, len(deployments))
+			for _, cd := range deployments {
+				if notImported && imported[cd.Name] {
+					continue
+				}
+				rows = append(rows, clusterDeploymentRow{
+					Name:        cd.Name,
+					Namespace:   cd.Namespace,
+					Platform:    cd.Platform,
+					Region:      cd.Region,
+					PowerState:  cd.PowerState,
+					Installed:   cd.Installed,
+					Version:     cd.Version,
+					ClusterPool: cd.ClusterPool,
+					RequestID:   cd.RequestID,
+					Imported:    imported[cd.Name],
+				})
+			}
+			sort.Slice(rows, func(i, j int) bool {
+				if rows[i].Namespace != rows[j].Namespace {
+					return rows[i].Namespace < rows[j].Namespace
+				}
+				return rows[i].Name < rows[j].Name
+			})
+
+			if written, err := writeListOutput(outputFormat, rows); written {
+				return err
+			}
+
+			if len(rows) == 0 {
+				fmt.Fprintln(os.Stdout, "No ClusterDeployments found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "NAME\tNAMESPACE\tPLATFORM\tREGION\tPOWER STATE\tINSTALLED\tVERSION\tPOOL\tIMPORTED\n")
+			for _, r := range rows {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%s\t%s\t%t\n",
+					r.Name, r.Namespace, valueOrNA(r.Platform), valueOrNA(r.Region), valueOrNA(r.PowerState),
+					r.Installed, valueOrNA(r.Version), valueOrNA(r.ClusterPool), r.Imported)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	cmd.Flags().Bool("not-imported", false, "Only list ClusterDeployments without a ManagedCluster")
+	return cmd
+}
+
+// valueOrNA renders an empty value as N/A in table output
+func valueOrNA(value string) string {
+	if value == "" {
+		return "N/A"
+	}
+	return value
+}
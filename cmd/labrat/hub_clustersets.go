@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newHubClusterSetsCmd creates the `hub clustersets` command
+func newHubClusterSetsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clustersets",
+		Short: "Group managed clusters in ManagedClusterSets",
+	}
+	cmd.AddCommand(newHubClusterSetsListCmd(), audited(newHubClusterSetsCreateCmd()), audited(newHubClusterSetsAddCmd()), audited(newHubClusterSetsRemoveCmd()))
+	return cmd
+}
+
+// newHubClusterSetsListCmd creates the `hub clustersets list` command
+func newHubClusterSetsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the ManagedClusterSets of the hub",
+		Long: `List the ManagedClusterSets of the hub with their member clusters.
+
+The members of sets that select their clusters by label selector, such as the
+global set, are not resolved and listed as N/A.
+
+Examples:
+  # List the cluster sets
+  labrat hub clustersets list
+
+  # List them as JSON
+  labrat hub clustersets list -o json`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			sets, err := hub.NewManagedClusterSetClient(kubeClient.GetDynamicClient(), clientOptions...).List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if written, err := writeListOutput(outputFormat, sets); written {
+				return err
+			}
+
+			if len(sets) == 0 {
+				fmt.Fprintln(os.Stdout, "No cluster sets found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "NAME\tSELECTOR\tCOUNT\tCLUSTERS\n")
+			for _, set := range sets {
+				if set.SelectorType != hub.ClusterSetSelectorExclusive {
+					fmt.Fprintf(w, "%s\t%s\tN/A\tN/A\n", set.Name, set.SelectorType)
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", set.Name, set.SelectorType, len(set.Clusters), valueOrNA(strings.Join(set.Clusters, ",")))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	return cmd
+}
+
+// newHubClusterSetsCreateCmd creates the `hub clustersets create` command
+func newHubClusterSetsCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <set-name>",
+		Short: "Create a ManagedClusterSet",
+		Long: `Create a ManagedClusterSet to group partner clusters, e.g. per partner or per
+program. Clusters are added to it with labrat hub clustersets add.
+
+Examples:
+  # Create a cluster set for a partner
+  labrat hub clustersets create acme`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setName := args[0]
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			created, err := hub.NewManagedClusterSetClient(kubeClient.GetDynamicClient(), clientOptions...).Create(cmd.Context(), setName)
+			if err != nil {
+				return err
+			}
+			if !created {
+				fmt.Fprintf(os.Stderr, "✓ Cluster set %s already exists\n", setName)
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "✓ Cluster set %s created\n", setName)
+			fmt.Fprintf(os.Stderr, "  Add clusters with: labrat hub clustersets add %s <cluster-name>\n", setName)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// newHubClusterSetsAddCmd creates the `hub clustersets add` command
+func newHubClusterSetsAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <set-name> <cluster-name>...",
+		Short: "Add managed clusters to a ManagedClusterSet",
+		Long: `Add managed clusters to a ManagedClusterSet by labeling them with
+cluster.open-cluster-management.io/clusterset. A cluster belongs to one set at a
+time, so a cluster in another set is moved. Adding clusters requires the create
+permission on the managedclustersets/join subresource of the set.
+
+Examples:
+  # Add two clusters to a partner's cluster set
+  labrat hub clustersets add acme acme-dev acme-test`,
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setName, clusterNames := args[0], args[1:]
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			sets := hub.NewManagedClusterSetClient(kubeClient.GetDynamicClient(), clientOptions...)
+			for _, clusterName := range clusterNames {
+				if err := sets.AddCluster(cmd.Context(), setName, clusterName); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "✓ Cluster %s added to cluster set %s\n", clusterName, setName)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// newHubClusterSetsRemoveCmd creates the `hub clustersets remove` command
+func newHubClusterSetsRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <set-name> <cluster-name>...",
+		Short: "Remove managed clusters from a ManagedClusterSet",
+		Long: `Remove managed clusters from a ManagedClusterSet by removing their
+cluster.open-cluster-management.io/clusterset label. ACM then puts them back in the
+default set.
+
+Examples:
+  # Remove a cluster from a partner's cluster set
+  labrat hub clustersets remove acme acme-dev`,
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setName, clusterNames := args[0], args[1:]
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			sets := hub.NewManagedClusterSetClient(kubeClient.GetDynamicClient(), clientOptions...)
+			for _, clusterName := range clusterNames {
+				if err := sets.RemoveCluster(cmd.Context(), setName, clusterName); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "✓ Cluster %s removed from cluster set %s\n", clusterName, setName)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
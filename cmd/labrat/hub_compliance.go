@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newHubComplianceCmd creates the `hub compliance` command
+func newHubComplianceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compliance",
+		Short: "Review Compliance Operator results across the fleet",
+	}
+	cmd.AddCommand(newHubComplianceReportCmd())
+	return cmd
+}
+
+// newHubComplianceReportCmd creates the `hub compliance report` command
+func newHubComplianceReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report [cluster-name...]",
+		Short: "Aggregate the latest compliance scan results of spoke clusters",
+		Long: `Collect the results of the latest scan of a compliance profile, as started with
+labrat spoke compliance scan, from spoke clusters into a single table.
+
+Every Ready managed cluster is inspected unless cluster names are given. Clusters
+that were never scanned with the profile or cannot be reached are listed with the
+error and the command exits non-zero.
+
+Examples:
+  # Report CIS results of every ready cluster
+  labrat hub compliance report --profile cis
+
+  # Report results of specific clusters as JSON
+  labrat hub compliance report cluster-a cluster-b -o json`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			outputFormat, _ := cmd.Flags().GetString("output")
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1, got %d", concurrency)
+			}
+			profile = spoke.ComplianceProfile(profile)
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			clusterNames := args
+			if len(clusterNames) == 0 {
+				clusterNames, err = fleet.Clusters(ctx, newHubBackend(kubeClient).ManagedClusters(),
+					hub.ManagedClusterFilter{Status: hub.StatusReady})
+				if err != nil {
+					return err
+				}
+			}
+
+			rows := make([]complianceReportRow, len(clusterNames))
+			index := make(map[string]int, len(clusterNames))
+			for i, name := range clusterNames {
+				rows[i].Cluster = name
+				index[name] = i
+			}
+
+			results := fleet.NewRunner(fleet.Options{Concurrency: concurrency, Logger: logger}).Run(ctx, clusterNames, func(ctx context.Context, name string) error {
+				spokeClient, err := newSpokeClient(ctx, kubeClient, name)
+				if err != nil {
+					return err
+				}
+				summary, err := spoke.NewComplianceScanner(spokeClient.GetDynamicClient(), spoke.ComplianceOptions{}, clientOptions...).Summary(ctx, profile)
+				if err != nil {
+					return err
+				}
+				rows[index[name]].ComplianceSummary = summary
+				return nil
+			})
+			for _, r := range results {
+				if r.Err != nil {
+					rows[index[r.Cluster]].Error = r.Err.Error()
+				}
+			}
+
+			if err := writeComplianceReport(rows, outputFormat); err != nil {
+				return err
+			}
+			return fleet.Summarize(results).Err()
+		},
+	}
+	cmd.Flags().String("profile", "cis", "Compliance profile to report on")
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().Int("concurrency", fleet.DefaultConcurrency, "Maximum number of clusters inspected in parallel")
+	return cmd
+}
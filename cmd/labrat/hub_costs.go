@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cost"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// costReport is the estimated cost of the running clusters, per cluster and per partner
+type costReport struct {
+	Currency string             `json:"currency"`
+	Clusters []cost.ClusterCost `json:"clusters"`
+	Partners []cost.PartnerCost `json:"partners"`
+	Daily    float64            `json:"daily"`
+	Monthly  float64            `json:"monthly"`
+}
+
+// newHubCostsCmd creates the `hub costs` command
+func newHubCostsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "costs",
+		Short: "Estimate the daily and monthly cost of the running clusters per cluster and partner",
+		Long: `Estimate what the running clusters of the hub cost per day and per month, per cluster
+and per partner.
+
+The nodes of each running cluster are read from its ManagedClusterInfo and priced by
+their instance type label with a pricing table, using the platform and region of its
+ClusterDeployment. Hibernating clusters are left out: they only pay for storage. The
+partner of a cluster is the value of the label given with --partner-label on its
+ManagedCluster.
+
+The bundled pricing table holds approximate on-demand list prices of common instance
+types on AWS, GCP, and Azure. Pass --pricing with a YAML file to use negotiated prices or
+price other instance types; it is merged over the bundled table:
+
+  currency: USD
+  platforms:
+    aws:
+      instanceTypes:
+        m6i.2xlarge: 0.384    # hourly price in the reference region
+      regions:
+        eu-west-1: 1.1        # multiplier of the prices in the region
+
+Nodes whose instance type has no price are not estimated and are reported; estimates
+that miss them are marked with *. Estimates assume the clusters keep running around the
+clock, with 730 hours a month.
+
+JSON output holds both the clusters and the partners; CSV output has a row per cluster,
+or per partner with --by partner.
+
+Examples:
+  # Estimate the costs per cluster and partner
+  labrat hub costs
+
+  # Price with the negotiated rates of the lab
+  labrat hub costs --pricing ~/.labrat/pricing.yaml
+
+  # Export the costs per partner for a spreadsheet
+  labrat hub costs -o csv --by partner > costs.csv`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			pricingFile, _ := cmd.Flags().GetString("pricing")
+			partnerLabel, _ := cmd.Flags().GetString("partner-label")
+			by, _ := cmd.Flags().GetString("by")
+
+			if outputFormat != "table" && outputFormat != "json" && outputFormat != "csv" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+			if by != "cluster" && by != "partner" {
+				return fmt.Errorf("invalid --by %q: must be cluster or partner", by)
+			}
+
+			pricing, err := cost.LoadPricing(pricingFile)
+			if err != nil {
+				return err
+			}
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			backend := newHubBackend(kubeClient)
+			deployments, err := backend.ClusterDeployments().List(ctx)
+			if err := warnDecodeErrors(err); err != nil {
+				return err
+			}
+			managedClusters, err := backend.ManagedClusters().List(ctx)
+			if err != nil {
+				return err
+			}
+			infos, err := backend.ClusterInfos().List(ctx)
+			if err != nil {
+				return err
+			}
+
+			partners := make(map[string]string, len(managedClusters))
+			for _, cluster := range managedClusters {
+				partners[cluster.Name] = cluster.Labels[partnerLabel]
+			}
+			clusterInfos := make(map[string]hub.ClusterAgentInfo, len(infos))
+			for _, info := range infos {
+				clusterInfos[info.Name] = info
+			}
+
+			var usage []cost.ClusterUsage
+			for _, deployment := range deployments {
+				if deployment.PowerState != spoke.PowerStateRunning {
+					continue
+				}
+				info, ok := clusterInfos[deployment.Name]
+				if !ok || info.NodeCount == 0 {
+					fmt.Fprintf(os.Stderr, "⚠️  No nodes reported for %s, its cost is not estimated\n", deployment.Name)
+				}
+				usage = append(usage, cost.ClusterUsage{
+					Name:     deployment.Name,
+					Partner:  partners[deployment.Name],
+					Platform: deployment.Platform,
+					Region:   deployment.Region,
+					Nodes:    clusterNodes(info),
+				})
+			}
+
+			report := costReport{Currency: pricing.Currency, Clusters: cost.Estimate(pricing, usage)}
+			report.Partners = cost.ByPartner(report.Clusters)
+			for _, c := range report.Clusters {
+				report.Daily += c.Daily
+				report.Monthly += c.Monthly
+				if len(c.Unpriced) > 0 {
+					fmt.Fprintf(os.Stderr, "⚠️  No price for %s nodes of %s, pass --pricing to price them\n", strings.Join(c.Unpriced, ", "), c.Cluster)
+				}
+			}
+			return writeCosts(report, outputFormat, by)
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json|csv)")
+	cmd.Flags().String("pricing", "", "YAML pricing table to merge over the bundled prices")
+	cmd.Flags().String("partner-label", "partner", "ManagedCluster label holding the partner of a cluster")
+	cmd.Flags().String("by", "cluster", "Rows of CSV output (cluster|partner)")
+	return cmd
+}
+
+// clusterNodes counts the nodes of a cluster by instance type, with the nodes without an
+// instance type label as cost.Unlabeled
+func clusterNodes(info hub.ClusterAgentInfo) map[string]int {
+	nodes := make(map[string]int, len(info.InstanceTypes)+1)
+	labeled := 0
+	for instanceType, count := range info.InstanceTypes {
+		nodes[instanceType] = count
+		labeled += count
+	}
+	if unlabeled := info.NodeCount - labeled; unlabeled > 0 {
+		nodes[cost.Unlabeled] = unlabeled
+	}
+	return nodes
+}
+
+// formatNodes formats the nodes of a cluster by instance type, most common first
+func formatNodes(nodes map[string]int) string {
+	counts := make(map[string]int64, len(nodes))
+	for instanceType, count := range nodes {
+		counts[instanceType] = int64(count)
+	}
+	return hub.FormatWorkers(counts)
+}
+
+// formatCost formats an amount of the report with two decimals
+func formatCost(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// writeCosts prints the cost report as tables, JSON, or CSV rows per cluster or partner
+func writeCosts(report costReport, outputFormat, by string) error {
+	switch outputFormat {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+		return nil
+	case "csv":
+		var records [][]string
+		if by == "partner" {
+			records = append(records, []string{"Partner", "Clusters", "Daily", "Monthly", "Incomplete", "Currency"})
+			for _, p := range report.Partners {
+				records = append(records, []string{p.Partner, strconv.Itoa(p.Clusters), formatCost(p.Daily), formatCost(p.Monthly), strconv.FormatBool(p.Incomplete), report.Currency})
+			}
+		} else {
+			records = append(records, []string{"Cluster", "Partner", "Platform", "Region", "Nodes", "Hourly", "Daily", "Monthly", "Unpriced", "Currency"})
+			for _, c := range report.Clusters {
+				records = append(records, []string{c.Cluster, c.Partner, c.Platform, c.Region, formatNodes(c.Nodes), strconv.FormatFloat(c.Hourly, 'f', 4, 64), formatCost(c.Daily), formatCost(c.Monthly), strings.Join(c.Unpriced, " "), report.Currency})
+			}
+		}
+		if err := csv.NewWriter(os.Stdout).WriteAll(records); err != nil {
+			return fmt.Errorf("failed to write csv output: %w", err)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	if len(report.Clusters) == 0 {
+		fmt.Fprintln(w, "No running clusters found")
+	} else {
+		fmt.Fprintf(w, "CLUSTER\tPARTNER\tPLATFORM\tREGION\tNODES\tDAILY (%s)\tMONTHLY (%s)\n", report.Currency, report.Currency)
+		for _, c := range report.Clusters {
+			incomplete := ""
+			if len(c.Unpriced) > 0 {
+				incomplete = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s%s\t%s%s\n", c.Cluster, valueOrNA(c.Partner), valueOrNA(c.Platform), valueOrNA(c.Region), formatNodes(c.Nodes),
+				formatCost(c.Daily), incomplete, formatCost(c.Monthly), incomplete)
+		}
+
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "PARTNER\tCLUSTERS\tDAILY (%s)\tMONTHLY (%s)\n", report.Currency, report.Currency)
+		for _, p := range report.Partners {
+			incomplete := ""
+			if p.Incomplete {
+				incomplete = "*"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s%s\t%s%s\n", valueOrNA(p.Partner), p.Clusters, formatCost(p.Daily), incomplete, formatCost(p.Monthly), incomplete)
+		}
+		fmt.Fprintf(w, "TOTAL\t%d\t%s\t%s\n", len(report.Clusters), formatCost(report.Daily), formatCost(report.Monthly))
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// newHubCredentialsCmd creates the `hub credentials` command group
+func newHubCredentialsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "credentials",
+		Short: "Manage the cloud credential secrets spoke clusters are provisioned with",
+		Long: `Manage the cloud credential secrets spoke clusters are provisioned with.
+
+Credentials are stored in the format of the ACM console, so both 'labrat spoke create'
+and ACM can provision with them. Verify stored credentials against the provider with
+'labrat bootstrap credentials verify'.`,
+	}
+	cmd.AddCommand(newHubCredentialsListCmd(), audited(newHubCredentialsCreateCmd()), audited(newHubCredentialsDeleteCmd()))
+	return cmd
+}
+
+// newHubCredentialsListCmd creates the `hub credentials list` command
+func newHubCredentialsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the cloud credential secrets of the hub",
+		Long: `List the cloud credential secrets labeled by ACM, with their provider and the base
+domain stored with them. The contents of the secrets are not shown.
+
+Examples:
+  # List the credentials in the hub namespace
+  labrat hub credentials list
+
+  # List the credentials of every namespace as JSON
+  labrat hub credentials list -A -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			namespace, _ := cmd.Flags().GetString("namespace")
+			allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+			switch {
+			case allNamespaces:
+				namespace = ""
+			case namespace == "":
+				namespace = cfg.Hub.Namespace
+			}
+
+			list, err := cloud.NewCredentialClient(kubeClient.GetCoreClient().CoreV1(), clientOptions...).List(cmd.Context(), namespace)
+			if err != nil {
+				return err
+			}
+
+			if written, err := writeListOutput(outputFormat, list); written {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "NAMESPACE\tNAME\tPROVIDER\tBASE DOMAIN\tAGE")
+			for _, creds := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", creds.Namespace, creds.Name, creds.Provider,
+					valueOrNA(creds.BaseDomain), duration.HumanDuration(time.Since(creds.CreatedAt)))
+			}
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	cmd.Flags().StringP("namespace", "n", "", "Namespace of the credentials (defaults to the hub namespace)")
+	cmd.Flags().BoolP("all-namespaces", "A", false, "List the credentials of every namespace")
+	return cmd
+}
+
+// newHubCredentialsCreateCmd creates the `hub credentials create` command
+func newHubCredentialsCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Store cloud credentials in a secret on the hub",
+		Long: `Store cloud credentials in a credential secret on the hub, read from environment
+variables, a file, or the configuration of the provider's CLI.
+
+Without --from-env or --from-file the credentials are read from the first of:
+
+  aws     $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY, then the --profile (default:
+          $AWS_PROFILE or default) of ~/.aws/credentials
+  azure   $AZURE_CLIENT_ID, $AZURE_CLIENT_SECRET, $AZURE_TENANT_ID, and
+          $AZURE_SUBSCRIPTION_ID, then ~/.azure/osServicePrincipal.json
+  gcp     the service account key of $GOOGLE_APPLICATION_CREDENTIALS, then
+          ~/.gcp/osServiceAccount.json, then the gcloud application default credentials
+
+Temporary AWS credentials and GCP user credentials are refused, as Hive needs the
+credentials for the lifetime of the cluster. The base domain, pull secret, and SSH key
+stored with the credentials are used by 'labrat spoke create' unless overridden there.
+
+Examples:
+  # Store the AWS key of a CLI profile
+  labrat hub credentials create aws-partner-lab --provider aws --profile partner-labs \
+    --base-domain labs.example.com
+
+  # Store a GCP service account key with a pull secret and SSH key
+  labrat hub credentials create gcp-partner-lab --provider gcp --from-file ./labrat-sa.json \
+    --pull-secret ~/pull-secret.json --ssh-key ~/.ssh/labs_ed25519
+
+  # Store the Azure service principal of the environment
+  labrat hub credentials create azure-partner-lab --provider azure --from-env`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			provider, _ := cmd.Flags().GetString("provider")
+			namespace, _ := cmd.Flags().GetString("namespace")
+			fromEnv, _ := cmd.Flags().GetBool("from-env")
+			fromFile, _ := cmd.Flags().GetString("from-file")
+			profile, _ := cmd.Flags().GetString("profile")
+			baseDomain, _ := cmd.Flags().GetString("base-domain")
+			pullSecretPath, _ := cmd.Flags().GetString("pull-secret")
+			sshKeyPath, _ := cmd.Flags().GetString("ssh-key")
+
+			creds, source, err := cloud.LoadCredentials(provider, cloud.CredentialSource{
+				FromEnv: fromEnv,
+				File:    config.ExpandPath(fromFile),
+				Profile: profile,
+			})
+			if err != nil {
+				return err
+			}
+			creds.Name = name
+			creds.BaseDomain = baseDomain
+			if pullSecretPath != "" {
+				if creds.PullSecret, err = os.ReadFile(config.ExpandPath(pullSecretPath)); err != nil {
+					return fmt.Errorf("failed to read pull secret: %w", err)
+				}
+			}
+			if sshKeyPath != "" {
+				sshKeyPath = config.ExpandPath(sshKeyPath)
+				if creds.SSHPrivateKey, err = os.ReadFile(sshKeyPath); err != nil {
+					return fmt.Errorf("failed to read SSH key: %w", err)
+				}
+				publicKey, err := os.ReadFile(sshKeyPath + ".pub")
+				if err != nil {
+					return fmt.Errorf("failed to read SSH public key: %w", err)
+				}
+				creds.SSHPublicKey = strings.TrimSpace(string(publicKey))
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+			if namespace == "" {
+				namespace = cfg.Hub.Namespace
+			}
+			creds.Namespace = namespace
+
+			if err := cloud.NewCredentialClient(kubeClient.GetCoreClient().CoreV1(), clientOptions...).Create(cmd.Context(), creds); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Created %s credentials %s/%s from %s\n", provider, namespace, name, source)
+			fmt.Fprintf(os.Stderr, "Verify them with: labrat bootstrap credentials verify %s -n %s\n", name, namespace)
+			return nil
+		},
+	}
+	cmd.Flags().String("provider", "", "Cloud provider of the credentials (aws|azure|gcp)")
+	cmd.Flags().StringP("namespace", "n", "", "Namespace of the credential secret (defaults to the hub namespace)")
+	cmd.Flags().Bool("from-env", false, "Read the credentials from environment variables only")
+	cmd.Flags().String("from-file", "", "AWS credentials file, Azure service principal file, or GCP service account key file")
+	cmd.Flags().String("profile", "", "Profile of the AWS credentials file (defaults to $AWS_PROFILE, then default)")
+	cmd.Flags().String("base-domain", "", "Base DNS domain of the clusters provisioned with the credentials")
+	cmd.Flags().String("pull-secret", "", "OpenShift pull secret file to store with the credentials")
+	cmd.Flags().String("ssh-key", "", "Private SSH key file for the nodes to store with the credentials; the public key is read from <file>.pub")
+	_ = cmd.MarkFlagRequired("provider")
+	return cmd
+}
+
+// newHubCredentialsDeleteCmd creates the `hub credentials delete` command
+func newHubCredentialsDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a cloud credential secret from the hub",
+		Long: `Delete a cloud credential secret from the hub. Secrets that are not cloud credentials
+are refused. Clusters provisioned with the credentials keep working, as they use a copy
+of the credentials in their own namespace.
+
+Examples:
+  # Delete credentials from the hub namespace
+  labrat hub credentials delete aws-partner-lab`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			namespace, _ := cmd.Flags().GetString("namespace")
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+			if namespace == "" {
+				namespace = cfg.Hub.Namespace
+			}
+
+			if err := cloud.NewCredentialClient(kubeClient.GetCoreClient().CoreV1(), clientOptions...).Delete(cmd.Context(), namespace, name); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Deleted credentials %s/%s\n", namespace, name)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("namespace", "n", "", "Namespace of the credential secret (defaults to the hub namespace)")
+	return cmd
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// fleetEventKinds are the kinds of the objects whose events hub events watch streams
+var fleetEventKinds = []string{"ClusterDeployment", "ManagedCluster"}
+
+// newHubEventsCmd creates the `hub events` command
+func newHubEventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Follow the Hive and ACM events of every cluster on the hub",
+	}
+	cmd.AddCommand(newHubEventsWatchCmd())
+	return cmd
+}
+
+// newHubEventsWatchCmd creates the `hub events watch` command
+func newHubEventsWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Stream the ClusterDeployment and ManagedCluster events of the fleet",
+		Long: `Stream the events Hive and ACM record about the ClusterDeployments and ManagedClusters
+of every cluster on the hub, one line per event, until interrupted: an activity feed of
+installs, hibernation, imports, and their failures across the lab. The existing events
+are printed first, oldest first, then every event as it is recorded or repeated.
+
+--type keeps the events of a severity (Normal or Warning) and --reason the events with
+one of the given reasons. -o json writes one JSON object per event and line, e.g. for
+forwarding the feed to the lab operations channel.
+
+Examples:
+  # Follow the activity of the fleet
+  labrat hub events watch
+
+  # Follow the warnings only, skipping the existing events older than 10 minutes
+  labrat hub events watch --type Warning --since 10m
+
+  # Follow failed installs and deprovisions as JSON
+  labrat hub events watch --reason ProvisionFailed,DeprovisionFailed -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			since, _ := cmd.Flags().GetDuration("since")
+			eventTypes, _ := cmd.Flags().GetStringSlice("type")
+			reasons, _ := cmd.Flags().GetStringSlice("reason")
+
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+			if since < 0 {
+				return fmt.Errorf("--since must not be negative, got %s", since)
+			}
+			opts := spoke.EventOptions{Reasons: reasons, Kinds: fleetEventKinds}
+			if since > 0 {
+				opts.Since = time.Now().Add(-since)
+			}
+			for _, eventType := range eventTypes {
+				switch {
+				case strings.EqualFold(eventType, corev1.EventTypeNormal):
+					opts.Types = append(opts.Types, corev1.EventTypeNormal)
+				case strings.EqualFold(eventType, corev1.EventTypeWarning):
+					opts.Types = append(opts.Types, corev1.EventTypeWarning)
+				default:
+					return fmt.Errorf("unsupported event type: %s (supported: Normal, Warning)", eventType)
+				}
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			// A failed write, e.g. to a closed pipe, ends the stream
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+			reader := spoke.NewEventReader(kubeClient.GetCoreClient(), spoke.EventSourceHub, clientOptions...)
+			var writeErr error
+			err = reader.Follow(ctx, "", opts, func(event spoke.ClusterEvent) {
+				if writeErr != nil {
+					return
+				}
+				if writeErr = writeFleetEvent(os.Stdout, outputFormat, event); writeErr != nil {
+					cancel()
+				}
+			})
+			if err != nil {
+				return err
+			}
+			return writeErr
+		},
+	}
+	cmd.Flags().StringP("output", "o", "text", "Output format (text|json)")
+	cmd.Flags().Duration("since", 0, "Skip the existing events last seen before this duration, e.g. 30m (0 prints them all)")
+	cmd.Flags().StringSlice("type", nil, "Only show events of these types (Normal|Warning)")
+	cmd.Flags().StringSlice("reason", nil, "Only show events with these reasons, e.g. ProvisionFailed")
+	return cmd
+}
+
+// writeFleetEvent writes event as a log line, or as a JSON line with -o json
+func writeFleetEvent(out io.Writer, outputFormat string, event spoke.ClusterEvent) error {
+	if outputFormat == "json" {
+		if err := json.NewEncoder(out).Encode(event); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+	repeated := ""
+	if event.Count > 1 {
+		repeated = fmt.Sprintf(" (x%d)", event.Count)
+	}
+	_, err := fmt.Fprintf(out, "%s %-7s %s %s: %s%s\n", event.LastSeen.Format(time.RFC3339), event.Type,
+		event.Object, event.Reason, event.Message, repeated)
+	if err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
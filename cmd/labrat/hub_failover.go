@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/spf13/cobra"
+)
+
+// hubFailoverReportName is the report (and JUnit test suite) name of `hub failover`
+const hubFailoverReportName = "labrat.hub.failover"
+
+// newHubFailoverCmd creates the `hub failover` command
+func newHubFailoverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "failover",
+		Short: "Switch the active hub to its standby",
+		Long: `Switch the active hub to its standby hub, as part of the ACM hub DR runbook.
+
+The standby is the hub named by the standby field of the active hub in the config,
+or the hub given with --to. Before switching, the command checks that the standby
+kubeconfig is usable, that its API is reachable, and that its namespace exists, and
+compares the ManagedClusters of both hubs. Clusters only known to the old hub are
+flagged as warnings: they must be restored from backup or re-imported on the standby.
+If the old hub cannot be reached, the comparison is skipped with a warning.
+
+If every check passes, activeHub is recorded in the config file so later commands
+use the standby without --hub. The command exits non-zero and leaves the active hub
+unchanged if any check fails.
+
+Examples:
+  # Check the standby without switching
+  labrat hub failover --dry-run
+
+  # Fail over to the configured standby
+  labrat hub failover
+
+  # Fail back to the original hub
+  labrat hub failover --to production`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			configPath, _ := cmd.Flags().GetString("config")
+			outputFormat, _ := cmd.Flags().GetString("output")
+			target, _ := cmd.Flags().GetString("to")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			if hubName, _ := cmd.Flags().GetString("hub"); hubName == config.AllHubs {
+				return fmt.Errorf("--hub %s is not supported by %s", config.AllHubs, cmd.CommandPath())
+			}
+
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			current := cfg.HubConfigs()[0]
+			if target == "" {
+				target = current.Standby
+			}
+			if target == "" {
+				return fmt.Errorf("hub %s has no standby configured (set standby in the config or pass --to)", current.Name)
+			}
+			if target == current.Name {
+				return fmt.Errorf("hub %s is already the active hub", target)
+			}
+
+			var standby *config.HubConfig
+			for _, h := range cfg.HubConfigs() {
+				if h.Name == target {
+					standby = &h
+					break
+				}
+			}
+			if standby == nil {
+				return fmt.Errorf("unknown hub %q", target)
+			}
+
+			report := validateFailover(cmd.Context(), current, *standby)
+
+			if err := check.NewWriter(check.OutputFormat(outputFormat), os.Stdout).Write(report); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			if err := report.Err(); err != nil {
+				return fmt.Errorf("failover to %s aborted, active hub is still %s: %w", target, current.Name, err)
+			}
+
+			if dryRun {
+				fmt.Fprintf(os.Stderr, "Dry run: active hub is still %s\n", current.Name)
+				return nil
+			}
+
+			if err := config.SetActiveHub(config.ExpandPath(configPath), target); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Active hub switched from %s to %s\n", current.Name, target)
+			return nil
+		},
+	}
+	cmd.Flags().String("to", "", "Hub to fail over to (defaults to the standby of the active hub)")
+	cmd.Flags().Bool("dry-run", false, "Run the checks without switching the active hub")
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json|junit)")
+	return cmd
+}
+
+// validateFailover checks connectivity to the standby hub and flags clusters that are only
+// known to the current hub
+func validateFailover(ctx context.Context, current, standby config.HubConfig) check.Report {
+	report := check.Report{Name: hubFailoverReportName}
+
+	standbyClient := checkHubConnectivity(ctx, &report, "Standby hub", standby)
+	if standbyClient == nil || report.Failed() {
+		return report
+	}
+
+	report.Run("Clusters known to standby hub", func() (check.Status, string) {
+		standbyClusters, err := newHubBackend(standbyClient).ManagedClusters().List(ctx)
+		if err != nil {
+			return check.StatusFail, err.Error()
+		}
+
+		currentClient, err := hubBackend.NewClient(current.Kubeconfig, current.Context, clientOptions...)
+		if err != nil {
+			return check.StatusWarn, fmt.Sprintf("hub %s is unusable, skipping comparison: %v", current.Name, err)
+		}
+		currentClusters, err := newHubBackend(currentClient).ManagedClusters().List(ctx)
+		if err != nil {
+			return check.StatusWarn, fmt.Sprintf("hub %s is unreachable, skipping comparison: %v", current.Name, err)
+		}
+
+		known := make(map[string]bool, len(standbyClusters))
+		for _, c := range standbyClusters {
+			known[c.Name] = true
+		}
+		var missing []string
+		for _, c := range currentClusters {
+			if !known[c.Name] {
+				missing = append(missing, c.Name)
+			}
+		}
+
+		if len(missing) > 0 {
+			return check.StatusWarn, fmt.Sprintf("%d cluster(s) only known to hub %s, re-import them on %s: %s",
+				len(missing), current.Name, standby.Name, strings.Join(missing, ", "))
+		}
+		return check.StatusPass, fmt.Sprintf("all %d cluster(s) of hub %s are known to %s", len(currentClusters), current.Name, standby.Name)
+	})
+
+	return report
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/state"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// historyFlushInterval is how often watch modes write the statuses they observed to the state file
+const historyFlushInterval = 10 * time.Second
+
+// historyMu serializes the updates of the state file by the hubs listed concurrently
+var historyMu sync.Mutex
+
+// newHubFlappingCmd creates the `hub flapping` command
+func newHubFlappingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "flapping",
+		Short: "List the clusters whose availability changed often",
+		Long: `List the clusters whose Available condition changed more than --threshold times within
+--window, e.g. because their klusterlet keeps losing the connection to the hub.
+
+The changes are recorded in ~/.labrat/state.json when history.enabled is set in the config,
+whenever labrat observes the managed clusters: on every labrat hub managedclusters, and
+continuously while labrat hub managedclusters --watch or labrat serve run. Changes between
+two observations are not seen, so run one of the watch modes for a complete history.
+Recorded changes are kept for history.retention (default: 168h).
+
+Examples:
+  # Clusters whose availability changed more than 3 times in the last 24 hours
+  labrat hub flapping
+
+  # Any change in the last 6 hours
+  labrat hub flapping --window 6h --threshold 0`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			window, _ := cmd.Flags().GetDuration("window")
+			threshold, _ := cmd.Flags().GetInt("threshold")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+			if window <= 0 {
+				return fmt.Errorf("--window must be positive, got %s", window)
+			}
+			if threshold < 0 {
+				return fmt.Errorf("--threshold must not be negative, got %d", threshold)
+			}
+
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if !cfg.History.Enabled {
+				fmt.Fprintln(os.Stderr, "⚠️  history.enabled is not set in the config, no status changes are recorded")
+			}
+
+			recorded, err := state.NewStore(config.ExpandPath(state.DefaultFile)).Load()
+			if err != nil {
+				return err
+			}
+			flapping := recorded.Flapping(cfg.HubName(), time.Now().Add(-window), threshold)
+
+			if written, err := writeListOutput(outputFormat, flapping); written {
+				return err
+			}
+			if len(flapping) == 0 {
+				fmt.Fprintf(os.Stdout, "No cluster changed its availability more than %d times in the last %s\n", threshold, window)
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "CLUSTER\tCHANGES\tLAST CHANGE\tAVAILABLE")
+			for _, cluster := range flapping {
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", cluster.Cluster, cluster.Transitions,
+					cluster.LastTransition.Local().Format(time.RFC3339), valueOrNA(cluster.Available))
+			}
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	cmd.Flags().Duration("window", 24*time.Hour, "How far back changes are counted")
+	cmd.Flags().Int("threshold", 3, "Report clusters with more changes than this")
+	return cmd
+}
+
+// statusObservation is the Available condition of a cluster observed at a point in time
+type statusObservation struct {
+	cluster   string
+	available string
+	at        time.Time
+}
+
+// recordStatusHistory records the observations of the clusters of hubName, in order, in the
+// state file if history.enabled is set. Failures are logged, as the history must not fail
+// the command that observed the clusters.
+func recordStatusHistory(cfg *config.Config, hubName string, observations []statusObservation) {
+	if !cfg.History.Enabled || len(observations) == 0 {
+		return
+	}
+	retention := cfg.History.Retention
+	if retention == 0 {
+		retention = state.DefaultHistoryRetention
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	err := state.NewStore(config.ExpandPath(state.DefaultFile)).Update(func(s *state.State) {
+		for _, observation := range observations {
+			s.ObserveStatus(hubName, observation.cluster, observation.available, observation.at)
+		}
+		s.PruneHistory(time.Now().UTC().Add(-retention))
+	})
+	if err != nil {
+		logger.Warn("failed to record the status history", "error", err)
+	}
+}
+
+// recordListedStatuses records the Available condition of listed clusters, observed now.
+// Lists of the on-disk cache can be out of date, so nothing is recorded while it is enabled.
+func recordListedStatuses(cfg *config.Config, hubName string, clusters []hub.ManagedClusterInfo) {
+	if listCache != nil {
+		return
+	}
+	now := time.Now().UTC()
+	observations := make([]statusObservation, 0, len(clusters))
+	for _, cluster := range clusters {
+		observations = append(observations, statusObservation{cluster: cluster.Name, available: cluster.Available, at: now})
+	}
+	recordStatusHistory(cfg, hubName, observations)
+}
+
+// combinedAsManaged returns the name and Available condition of combined clusters, for
+// recordListedStatuses
+func combinedAsManaged(combined []hub.CombinedClusterInfo) []hub.ManagedClusterInfo {
+	clusters := make([]hub.ManagedClusterInfo, 0, len(combined))
+	for _, cluster := range combined {
+		clusters = append(clusters, hub.ManagedClusterInfo{Name: cluster.Name, Available: cluster.Available})
+	}
+	return clusters
+}
+
+// watchStatusHistory records the Available condition of every change of the managed
+// clusters of hubName streamed by mcClient until ctx is done, if history.enabled is set.
+// The observations are written to the state file every historyFlushInterval; the returned
+// function stops the recording and waits for the last write.
+func watchStatusHistory(ctx context.Context, cfg *config.Config, hubName string, mcClient hub.ManagedClusterClient) (stop func()) {
+	if !cfg.History.Enabled {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	events, err := mcClient.Watch(ctx)
+	if err != nil {
+		cancel()
+		logger.Warn("failed to watch the managed clusters for the status history", "error", err)
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(historyFlushInterval)
+		defer ticker.Stop()
+		var observations []statusObservation
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					recordStatusHistory(cfg, hubName, observations)
+					return
+				}
+				if event.Type == watch.Error {
+					logger.Warn("watch of the managed clusters for the status history failed", "error", event.Err)
+					continue
+				}
+				if event.Type != watch.Deleted {
+					observations = append(observations, statusObservation{
+						cluster:   event.Cluster.Name,
+						available: event.Cluster.Available,
+						at:        time.Now().UTC(),
+					})
+				}
+			case <-ticker.C:
+				recordStatusHistory(cfg, hubName, observations)
+				observations = nil
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newHubGCCmd creates the `hub gc` command
+func newHubGCCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Delete hub resources left behind by deprovisioned clusters",
+		Long: `Find and delete hub resources left behind after clusters are deprovisioned:
+
+  - Cluster namespaces with neither a ClusterDeployment nor a ManagedCluster
+  - Admin kubeconfig and kubeadmin password secrets whose ClusterDeployment is gone
+  - Hive DNSZones whose ClusterDeployment is gone
+
+The leftovers are listed and the command asks for confirmation before deleting them.
+Pass --yes to skip the prompt, e.g. in scripts; without a terminal the command fails
+unless --yes is given. Run with --dry-run to only review what would be deleted.
+
+Examples:
+  # Show what would be deleted
+  labrat hub gc --dry-run
+
+  # Delete leftovers after confirming
+  labrat hub gc
+
+  # Delete leftovers without a prompt, e.g. from a cron job
+  labrat hub gc --yes`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			yes, _ := cmd.Flags().GetBool("yes")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+			if !dryRun && !yes && !isTerminal(os.Stdin) {
+				return fmt.Errorf("refusing to delete leftover resources without confirmation, pass --yes or --dry-run")
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			collector := hub.NewGarbageCollector(kubeClient.GetCoreClient().CoreV1(), kubeClient.GetDynamicClient(), clientOptions...)
+			garbage, err := collector.Find(ctx)
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(garbage, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+			} else if len(garbage) == 0 {
+				fmt.Fprintln(os.Stdout, "No leftover resources found")
+			} else {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+				fmt.Fprintf(w, "KIND\tNAMESPACE\tNAME\tREASON\n")
+				for _, g := range garbage {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", g.Kind, g.Namespace, g.Name, g.Reason)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+
+			if dryRun || len(garbage) == 0 {
+				return nil
+			}
+			if !yes {
+				p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+				if !p.confirm(fmt.Sprintf("Delete %d leftover resources?", len(garbage))) {
+					return fmt.Errorf("gc aborted")
+				}
+			}
+			if err := collector.Delete(ctx, garbage); err != nil {
+				return err
+			}
+			if outputFormat == "table" {
+				fmt.Printf("🗑️  Deleted %d leftover resources\n", len(garbage))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().Bool("dry-run", false, "Only list leftover resources without deleting them")
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	return cmd
+}
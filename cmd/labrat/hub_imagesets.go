@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/release"
+	"github.com/spf13/cobra"
+)
+
+// newHubImageSetsCmd creates the `hub imagesets` command group
+func newHubImageSetsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "imagesets",
+		Short: "Manage the OpenShift releases available for provisioning",
+		Long: `Manage the Hive ClusterImageSets of the hub. Each ClusterImageSet offers an OpenShift
+release image that clusters and cluster pools can be provisioned with.`,
+	}
+	cmd.AddCommand(newHubImageSetsListCmd(), audited(newHubImageSetsCreateCmd()), audited(newHubImageSetsDeleteCmd()))
+	return cmd
+}
+
+// newHubImageSetsListCmd creates the `hub imagesets list` command
+func newHubImageSetsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the ClusterImageSets of the hub",
+		Long: `List the ClusterImageSets of the hub and the release image each one offers.
+
+Examples:
+  # List the releases available for provisioning
+  labrat hub imagesets list
+
+  # List the release images only
+  labrat hub imagesets list -o jsonpath='{range .[*]}{.ReleaseImage}{"\n"}{end}'`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			imageSets, err := hub.NewClusterImageSetClient(kubeClient.GetDynamicClient(), clientOptions...).List(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if written, err := writeListOutput(outputFormat, imageSets); written {
+				return err
+			}
+
+			if len(imageSets) == 0 {
+				fmt.Fprintln(os.Stdout, "No ClusterImageSets found")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "NAME\tRELEASE IMAGE")
+			for _, imageSet := range imageSets {
+				fmt.Fprintf(w, "%s\t%s\n", imageSet.Name, valueOrNA(imageSet.ReleaseImage))
+			}
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	return cmd
+}
+
+// newHubImageSetsCreateCmd creates the `hub imagesets create` command
+func newHubImageSetsCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Offer an OpenShift release for provisioning",
+		Long: `Create a ClusterImageSet offering an OpenShift release image for provisioning.
+
+Give the release image with --release-image, or let --latest-stable resolve the newest
+stable release of a minor version, e.g. 4.16, from the public OpenShift release
+controller. --arch selects the architecture of the release, amd64 by default.
+
+The name defaults to the one ACM gives its own ClusterImageSets, e.g. img4.16.12-x86-64,
+when the release is resolved with --latest-stable; it is required with --release-image.
+
+Examples:
+  # Offer the latest stable 4.16 release
+  labrat hub imagesets create --latest-stable 4.16
+
+  # Offer a specific release image and show it in the ACM console
+  labrat hub imagesets create img4.15.30-x86-64 \
+    --release-image quay.io/openshift-release-dev/ocp-release:4.15.30-x86_64 \
+    --label visible=true`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseImage, _ := cmd.Flags().GetString("release-image")
+			latestStable, _ := cmd.Flags().GetString("latest-stable")
+			arch, _ := cmd.Flags().GetString("arch")
+			labels, _ := cmd.Flags().GetStringToString("label")
+
+			if (releaseImage == "") == (latestStable == "") {
+				return fmt.Errorf("exactly one of --release-image or --latest-stable is required")
+			}
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+			if name == "" && releaseImage != "" {
+				return fmt.Errorf("a name is required with --release-image")
+			}
+
+			ctx := cmd.Context()
+			if latestStable != "" {
+				rel, err := release.NewClient("", arch, nil).LatestStable(ctx, latestStable)
+				if err != nil {
+					return fmt.Errorf("failed to resolve the latest stable %s release: %w", latestStable, err)
+				}
+				fmt.Fprintf(os.Stderr, "✓ Latest stable %s release is %s\n", latestStable, rel.Version)
+				releaseImage = rel.PullSpec
+				if name == "" {
+					name = release.ImageSetName(*rel)
+				}
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			imageSets := hub.NewClusterImageSetClient(kubeClient.GetDynamicClient(), clientOptions...)
+			if err := imageSets.Create(ctx, name, releaseImage, labels); err != nil {
+				return err
+			}
+			auditTargets = []string{name}
+			fmt.Fprintf(os.Stderr, "✓ ClusterImageSet %s created\n", name)
+			fmt.Fprintf(os.Stderr, "   Release image: %s\n", releaseImage)
+			return nil
+		},
+	}
+	cmd.Flags().String("release-image", "", "OpenShift release image to offer")
+	cmd.Flags().String("latest-stable", "", "Offer the latest stable release of a minor version, e.g. 4.16")
+	cmd.Flags().String("arch", release.DefaultArchitecture, "Architecture of the release resolved with --latest-stable, e.g. arm64")
+	cmd.Flags().StringToString("label", nil, "Label to add to the ClusterImageSet, as key=value (repeatable)")
+	return cmd
+}
+
+// newHubImageSetsDeleteCmd creates the `hub imagesets delete` command
+func newHubImageSetsDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Stop offering an OpenShift release for provisioning",
+		Long: `Delete a ClusterImageSet. Existing clusters are not affected, but no new clusters can be
+provisioned with its release.
+
+ClusterImageSets that a ClusterPool provisions its clusters with are not deleted unless
+--force is given, since the pool could no longer replace claimed clusters.
+
+Examples:
+  # Stop offering a release
+  labrat hub imagesets delete img4.14.20-x86-64`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			force, _ := cmd.Flags().GetBool("force")
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			dynamicClient := kubeClient.GetDynamicClient()
+			if !force {
+				pools, err := hub.NewClusterPoolClient(dynamicClient, clientOptions...).List(ctx)
+				if err != nil {
+					return err
+				}
+				var users []string
+				for _, pool := range pools {
+					if pool.ImageSet == name {
+						users = append(users, pool.Namespace+"/"+pool.Name)
+					}
+				}
+				if len(users) > 0 {
+					return fmt.Errorf("ClusterImageSet %s is used by ClusterPool %s; use --force to delete it anyway", name, strings.Join(users, ", "))
+				}
+			}
+
+			if err := hub.NewClusterImageSetClient(dynamicClient, clientOptions...).Delete(ctx, name); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ ClusterImageSet %s deleted\n", name)
+			return nil
+		},
+	}
+	cmd.Flags().Bool("force", false, "Delete the ClusterImageSet even if a ClusterPool uses it")
+	return cmd
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newHubImportCmd creates the `hub import` command
+func newHubImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <cluster-name>",
+		Short: "Import an existing cluster into ACM",
+		Long: `Attach an existing OpenShift cluster, e.g. one a partner brought along, to the hub.
+
+The cluster namespace, a ManagedCluster, and a KlusterletAddonConfig are created on the
+hub. ACM then generates the import manifests, which install the klusterlet agent on the
+cluster. With --spoke-kubeconfig the manifests are applied to the cluster directly;
+otherwise they are written to stdout or the file given with --output, to be applied with
+oc apply -f by someone with cluster-admin access to the cluster.
+
+The import manifests contain a token that lets the cluster register with the hub, so
+store them like a credential. Re-running the command resumes an interrupted import.
+
+Examples:
+  # Import a cluster and install the klusterlet with its admin kubeconfig
+  labrat hub import partner-cluster --spoke-kubeconfig ./partner-kubeconfig
+
+  # Generate the import manifests for the partner to apply
+  labrat hub import partner-cluster -o partner-cluster-import.yaml --label partner=acme`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			spokeKubeconfig, _ := cmd.Flags().GetString("spoke-kubeconfig")
+			spokeContext, _ := cmd.Flags().GetString("spoke-context")
+			outputPath, _ := cmd.Flags().GetString("output")
+			labels, _ := cmd.Flags().GetStringToString("label")
+			timeout := waitTimeout(cmd)
+
+			if spokeKubeconfig != "" && outputPath != "" {
+				return fmt.Errorf("--spoke-kubeconfig and --output cannot be used together")
+			}
+
+			var spokeClient *kube.Client
+			if spokeKubeconfig != "" {
+				var err error
+				spokeClient, err = kube.NewClient(config.ExpandPath(spokeKubeconfig), spokeContext, clientOptions...)
+				if err != nil {
+					return fmt.Errorf("failed to create client for spoke %s: %w", clusterName, err)
+				}
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			importer := hub.NewImporter(
+				kubeClient.GetCoreClient().CoreV1(),
+				kubeClient.GetDynamicClient(),
+				hub.ImportOptions{Timeout: timeout},
+				clientOptions...,
+			)
+			if err := importer.Create(ctx, clusterName, labels); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ ManagedCluster %s created\n", clusterName)
+
+			fmt.Fprintf(os.Stderr, "⏳ Waiting for the import manifests of %s...\n", clusterName)
+			manifests, err := importer.Manifests(ctx, clusterName)
+			if err != nil {
+				return err
+			}
+
+			if spokeClient != nil {
+				applier := kube.NewManifestApplier(spokeClient.GetDynamicClient(), spokeClient.GetRESTMapper(), clientOptions...)
+				if err := applier.Apply(ctx, manifests.CRDs); err != nil {
+					return fmt.Errorf("failed to apply the klusterlet CRDs to %s: %w", clusterName, err)
+				}
+				if err := applier.Apply(ctx, manifests.Import); err != nil {
+					return fmt.Errorf("failed to apply the import manifests to %s: %w", clusterName, err)
+				}
+				fmt.Fprintf(os.Stderr, "✓ Klusterlet installed on %s\n", clusterName)
+				fmt.Fprintf(os.Stderr, "  Track the import with: labrat hub managedclusters\n")
+				return nil
+			}
+
+			if outputPath == "" {
+				fmt.Print(string(manifests.YAML()))
+				return nil
+			}
+			if err := os.WriteFile(outputPath, manifests.YAML(), 0600); err != nil {
+				return fmt.Errorf("failed to write import manifests: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "✓ Import manifests saved to: %s\n", outputPath)
+			fmt.Fprintf(os.Stderr, "  Apply them on the cluster with: oc apply -f %s\n", outputPath)
+			return nil
+		},
+	}
+	cmd.Flags().String("spoke-kubeconfig", "", "Kubeconfig of the cluster to install the klusterlet with")
+	cmd.Flags().String("spoke-context", "", "Context of --spoke-kubeconfig (default: current context)")
+	cmd.Flags().StringP("output", "o", "", "File to write the import manifests to (default: stdout)")
+	cmd.Flags().StringToString("label", nil, "Label to add to the ManagedCluster, as key=value (repeatable)")
+	addWaitTimeoutFlag(cmd, hub.DefaultImportTimeout, "Maximum time to wait for ACM to generate the import manifests")
+	return cmd
+}
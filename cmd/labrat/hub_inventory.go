@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newHubExportCmd creates the `hub export` command
+func newHubExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Save a snapshot of the cluster inventory of the hub",
+		Long: `Save a snapshot of every cluster of the hub to --file as JSON: the combined cluster
+info of labrat hub managedclusters --wide, the labels of the ManagedClusters, and the
+leases. The snapshot is always listed from the hub, never from the on-disk cache.
+Compare a later state of the hub with it using labrat hub diff.
+
+Examples:
+  # Save this week's inventory
+  labrat hub export --file inventory-$(date +%F).json
+
+  # Print the inventory
+  labrat hub export --file -`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, _ := cmd.Flags().GetString("file")
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			inventory, err := snapshotInventory(cmd.Context(), kubeClient, cfg.HubName())
+			if err != nil {
+				return err
+			}
+			if err := inventory.Save(path); err != nil {
+				return err
+			}
+			if path != "-" {
+				fmt.Fprintf(os.Stderr, "✓ Saved the inventory of %d clusters of %s to %s\n", len(inventory.Clusters), inventory.Hub, path)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("file", "f", "inventory.json", "File to save the inventory to, - for stdout")
+	return cmd
+}
+
+// newHubDiffCmd creates the `hub diff` command
+func newHubDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <inventory-file>",
+		Short: "Compare the hub with an inventory saved by hub export",
+		Long: `Compare the clusters of the hub with an inventory saved by labrat hub export, for
+weekly reporting: the clusters added and removed since, and for the clusters in both,
+every change of their status, power state, platform, region, version, node count,
+cluster set, lease, and labels.
+
+Examples:
+  # Show what changed since last week
+  labrat hub diff inventory-2026-01-05.json
+
+  # The changes as JSON, for a report
+  labrat hub diff inventory-2026-01-05.json -o json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			previous, err := hub.LoadInventory(args[0])
+			if err != nil {
+				return err
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+			if previous.Hub != cfg.HubName() {
+				fmt.Fprintf(os.Stderr, "⚠️  The inventory was taken of hub %s, comparing it with hub %s\n", previous.Hub, cfg.HubName())
+			}
+
+			current, err := snapshotInventory(cmd.Context(), kubeClient, cfg.HubName())
+			if err != nil {
+				return err
+			}
+			diff := hub.DiffInventories(previous, current)
+
+			if outputFormat == "json" {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(diff); err != nil {
+					return fmt.Errorf("failed to write output: %w", err)
+				}
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Changes of %s since %s\n\n", cfg.HubName(), previous.CreatedAt.Local().Format(time.RFC1123))
+			if diff.Empty() {
+				fmt.Fprintln(os.Stdout, "No changes")
+				return nil
+			}
+			return hub.WriteInventoryDiff(os.Stdout, diff)
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	return cmd
+}
+
+// snapshotInventory lists the combined clusters, labels, and leases of the hub into an
+// inventory, bypassing the on-disk cache so the snapshot is current
+func snapshotInventory(ctx context.Context, kubeClient *kube.Client, hubName string) (*hub.Inventory, error) {
+	backend := newHubBackend(kubeClient)
+	combined, err := backend.Clusters().ListCombined(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list combined clusters: %w", err)
+	}
+	managed, err := backend.ManagedClusters().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	leases, err := hub.NewLeaseClient(kubeClient.GetDynamicClient(), clientOptions...).List(ctx)
+	if err := warnDecodeErrors(err); err != nil {
+		return nil, err
+	}
+	return hub.NewInventory(hubName, combined, managed, leases), nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// leaseRow is a lease with its state, as listed by `hub leases`
+type leaseRow struct {
+	hub.LeaseInfo
+	State string
+}
+
+// newHubLeasesCmd creates the `hub leases` command
+func newHubLeasesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "leases",
+		Short: "List the leases of spoke clusters",
+		Long: `List the spoke clusters with a lease, ordered by the end of their lease. Clusters
+whose lease ends within --warning are reported as Expiring, clusters whose lease has
+ended as Expired. Set leases with labrat spoke lease set.
+
+Examples:
+  # List every lease
+  labrat hub leases
+
+  # List the clusters whose lease ends within a week, including expired ones
+  labrat hub leases --within 168h
+
+  # List the expired clusters as JSON
+  labrat hub leases --expired-only -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			expiredOnly, _ := cmd.Flags().GetBool("expired-only")
+			within, _ := cmd.Flags().GetDuration("within")
+			warning, _ := cmd.Flags().GetDuration("warning")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			leases, err := hub.NewLeaseClient(kubeClient.GetDynamicClient(), clientOptions...).List(cmd.Context())
+			if err := warnDecodeErrors(err); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			rows := make([]leaseRow, 0, len(leases))
+			for _, lease := range leases {
+				if expiredOnly && !lease.Expired(now) {
+					continue
+				}
+				if within > 0 && lease.ExpiresAt.Sub(now) > within {
+					continue
+				}
+				rows = append(rows, leaseRow{LeaseInfo: lease, State: lease.State(now, warning)})
+			}
+
+			if written, err := writeListOutput(outputFormat, rows); written {
+				return err
+			}
+
+			if len(rows) == 0 {
+				fmt.Fprintln(os.Stdout, "No matching leases found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "NAME\tEXPIRES\tREMAINING\tSTATE\tPOWER\n")
+			for _, row := range rows {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", row.Cluster, row.ExpiresAt.Local().Format(time.RFC3339),
+					formatRemaining(row.ExpiresAt.Sub(now)), row.State, valueOrNA(row.PowerState))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	cmd.Flags().Bool("expired-only", false, "Only list clusters whose lease has expired")
+	cmd.Flags().Duration("within", 0, "Only list clusters whose lease ends within this duration (0 lists all)")
+	cmd.Flags().Duration("warning", hub.DefaultLeaseWarning, "Report leases ending within this duration as Expiring")
+	return cmd
+}
+
+// formatRemaining formats the time left on a lease in days and hours, e.g. 3d4h, or the time
+// since it ended, e.g. -2d1h
+func formatRemaining(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	if days == 0 {
+		minutes := int(d % time.Hour / time.Minute)
+		return fmt.Sprintf("%s%dh%dm", sign, hours, minutes)
+	}
+	return fmt.Sprintf("%s%dd%dh", sign, days, hours)
+}
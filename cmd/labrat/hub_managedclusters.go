@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/yaml"
+)
+
+// newHubManagedClustersGetCmd creates the `hub managedclusters get` command
+func newHubManagedClustersGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <name>",
+		Short: "Show the full state of a managed cluster",
+		Long: `Show the full state of a ManagedCluster: its status, labels, taints, every condition,
+the capacity and allocatable resources, and the ClusterClaims reported by the klusterlet,
+such as id.openshift.io with the cluster ID and version.openshift.io with the OpenShift
+version.
+
+Examples:
+  # Show a managed cluster as YAML
+  labrat hub managedclusters get partner-a
+
+  # Print the cluster ID from the claims
+  labrat hub managedclusters get partner-a -o json | jq -r '.Claims["id.openshift.io"]'`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			if outputFormat != "yaml" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			detail, err := newHubBackend(kubeClient).ManagedClusters().Get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			var data []byte
+			if outputFormat == "json" {
+				data, err = json.MarshalIndent(detail, "", "  ")
+				data = append(data, '\n')
+			} else {
+				data, err = yaml.Marshal(detail)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s: %w", outputFormat, err)
+			}
+			if _, err := os.Stdout.Write(data); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "yaml", "Output format (yaml|json)")
+	return cmd
+}
+
+// listManagedClusters lists the ManagedClusters of one hub, keeping those matching filter
+func listManagedClusters(ctx context.Context, kubeClient *kube.Client, filter hub.ManagedClusterFilter) ([]hub.ManagedClusterInfo, error) {
+	mcClient := newListingHubBackend(kubeClient).ManagedClusters()
+
+	clusters, err := mcClient.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed clusters: %w", err)
+	}
+
+	return mcClient.Filter(clusters, filter), nil
+}
+
+// watchManagedClusters writes the ManagedClusters of one hub and then each change of them,
+// keeping those matching filter, until the command is interrupted. Meanwhile the lifecycle
+// events of every cluster are sent to the destinations configured under notify.
+func watchManagedClusters(ctx context.Context, cfg *config.Config, kubeClient *kube.Client, output *hub.OutputWriter, filter hub.ManagedClusterFilter) error {
+	informerCache, err := startHubCache(ctx, kubeClient, kube.DefaultResyncPeriod, false)
+	if err != nil {
+		return err
+	}
+	backend := hub.NewInformerBackend(kubeClient.GetDynamicClient(), informerCache, clientOptions...)
+	mcClient := backend.ManagedClusters()
+	watchNotifications(ctx, cfg, mcClient, backend.ClusterDeployments())
+	defer watchStatusHistory(ctx, cfg, cfg.HubName(), mcClient)()
+	events, err := mcClient.Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	for event := range events {
+		if event.Type == watch.Error {
+			return event.Err
+		}
+		if !filter.Matches(event.Cluster) {
+			continue
+		}
+		if err := output.WriteEvent(event); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return nil
+}
+
+// listCombinedClusters lists the clusters of one hub enriched with ClusterDeployment and
+// ManagedClusterInfo data, keeping those matching filter
+func listCombinedClusters(ctx context.Context, kubeClient *kube.Client, filter hub.ManagedClusterFilter) ([]hub.CombinedClusterInfo, error) {
+	combined, err := newListingHubBackend(kubeClient).Clusters().ListCombined(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list combined clusters: %w", err)
+	}
+
+	if filter != (hub.ManagedClusterFilter{}) {
+		filtered := make([]hub.CombinedClusterInfo, 0)
+		for _, cluster := range combined {
+			if filter.MatchesCombined(cluster) {
+				filtered = append(filtered, cluster)
+			}
+		}
+		combined = filtered
+	}
+	return combined, nil
+}
+
+// fanOutHubs runs list against every configured hub concurrently and merges the results in
+// hub order. Hubs that fail are reported on stderr and counted in the returned error; the
+// results of the hubs that succeeded are returned either way.
+func fanOutHubs[T any](ctx context.Context, cfg *config.Config, list func(ctx context.Context, kubeClient *kube.Client, hubName string) ([]T, error)) ([]T, error) {
+	hubs := cfg.HubConfigs()
+	names := make([]string, 0, len(hubs))
+	byName := make(map[string]config.HubConfig, len(hubs))
+	for _, h := range hubs {
+		names = append(names, h.Name)
+		byName[h.Name] = h
+	}
+
+	var mu sync.Mutex
+	perHub := make(map[string][]T, len(hubs))
+	results := fleet.NewRunner(fleet.Options{Concurrency: len(hubs), Logger: logger}).Run(ctx, names, func(ctx context.Context, name string) error {
+		h := byName[name]
+		kubeClient, err := hubBackend.NewClient(h.Kubeconfig, h.Context, clientOptions...)
+		if err != nil {
+			return fmt.Errorf("failed to create kubernetes client: %w", err)
+		}
+		items, err := list(ctx, kubeClient, name)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		perHub[name] = items
+		mu.Unlock()
+		return nil
+	})
+
+	merged := make([]T, 0)
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  hub %s: %v\n", result.Cluster, result.Err)
+			failed++
+			continue
+		}
+		merged = append(merged, perHub[result.Cluster]...)
+	}
+	if failed > 0 {
+		return merged, fmt.Errorf("%d of %d hubs failed", failed, len(hubs))
+	}
+	return merged, nil
+}
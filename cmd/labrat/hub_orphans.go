@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newHubOrphansCmd creates the `hub orphans` command
+func newHubOrphansCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "orphans",
+		Short: "List ClusterDeployments and ManagedClusters without a counterpart",
+		Long: `Report mismatches between Hive ClusterDeployments and ACM ManagedClusters, which
+accumulate over time and silently waste cloud resources:
+
+  - ClusterDeployments without a ManagedCluster of the same name
+  - ManagedClusters without a ClusterDeployment whose klusterlet never joined the
+    hub (imported clusters are expected to have no ClusterDeployment)
+
+Examples:
+  # List orphans as a table
+  labrat hub orphans
+
+  # List orphans as JSON
+  labrat hub orphans -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			backend := newHubBackend(kubeClient)
+			detector := hub.NewOrphanDetector(backend.ManagedClusters(), backend.ClusterDeployments())
+			orphans, err := detector.Detect(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(orphans, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+				return nil
+			}
+
+			if len(orphans) == 0 {
+				fmt.Fprintln(os.Stdout, "No orphaned clusters found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "KIND\tNAME\tNAMESPACE\tREQUEST\tDETAIL\n")
+			for _, o := range orphans {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", o.Kind, o.Name, o.Namespace, o.RequestID, o.Detail)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	return cmd
+}
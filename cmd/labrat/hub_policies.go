@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newHubPoliciesCmd creates the `hub policies` command
+func newHubPoliciesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policies",
+		Short: "List ACM governance policies and their compliance per cluster",
+		Long: `List the ACM governance Policies of the hub with their compliance on every cluster
+they are placed on, one row per policy and cluster. Policies that are not placed on
+any cluster are listed without a cluster.
+
+Examples:
+  # Audit every policy
+  labrat hub policies
+
+  # List the policies a cluster violates
+  labrat hub policies --cluster my-cluster --noncompliant
+
+  # Export the compliance of every policy as JSON
+  labrat hub policies -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			cluster, _ := cmd.Flags().GetString("cluster")
+			nonCompliant, _ := cmd.Flags().GetBool("noncompliant")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			client := hub.NewPolicyClient(kubeClient.GetDynamicClient(), clientOptions...)
+			policies, err := client.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+			policies = client.Filter(policies, hub.PolicyFilter{Cluster: cluster, NonCompliantOnly: nonCompliant})
+
+			if outputFormat == "json" {
+				if policies == nil {
+					policies = []hub.PolicyInfo{}
+				}
+				data, err := json.MarshalIndent(policies, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+				return nil
+			}
+
+			if len(policies) == 0 {
+				fmt.Fprintln(os.Stdout, "No matching policies found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "NAMESPACE\tPOLICY\tREMEDIATION\tCLUSTER\tCOMPLIANCE\n")
+			for _, policy := range policies {
+				remediation := valueOrNA(policy.RemediationAction)
+				if policy.Disabled {
+					remediation += " (disabled)"
+				}
+				if len(policy.Clusters) == 0 {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", policy.Namespace, policy.Name, remediation, "N/A", policy.Compliant)
+					continue
+				}
+				for _, status := range policy.Clusters {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", policy.Namespace, policy.Name, remediation, status.Cluster, status.Compliant)
+				}
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().String("cluster", "", "Only show the compliance of policies on this cluster")
+	cmd.Flags().Bool("noncompliant", false, "Only show clusters that violate a policy")
+	return cmd
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// securityReportRow is the security posture of one spoke, or the error that prevented reading it
+type securityReportRow struct {
+	Cluster string `json:"cluster"`
+	*spoke.SecurityPosture
+	Error string `json:"error,omitempty"`
+}
+
+// newHubSecurityCmd creates the `hub security` command
+func newHubSecurityCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "security",
+		Short: "Review the security configuration of the fleet",
+	}
+	cmd.AddCommand(newHubSecurityReportCmd())
+	return cmd
+}
+
+// newHubSecurityReportCmd creates the `hub security report` command
+func newHubSecurityReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report [cluster-name...]",
+		Short: "Report the security posture of spoke clusters",
+		Long: `Collect the security-relevant configuration of spoke clusters into a single table
+for periodic security reviews:
+
+  - ETCD ENCRYPTION: spec.encryption.type of the APIServer config (identity when disabled)
+  - AUDIT PROFILE:   spec.audit.profile of the APIServer config
+  - DEFAULT SCC:     SCCs granted to every authenticated user
+  - POD SECURITY:    cluster-wide default PodSecurity admission enforce level
+  - FIPS:            whether the cluster was installed in FIPS mode
+
+Every Ready managed cluster is inspected unless cluster names are given. Spokes are
+reached with the admin kubeconfig of their ClusterDeployment, in parallel bounded by
+--concurrency. Clusters that cannot be inspected are listed with the error and the
+command exits non-zero.
+
+Examples:
+  # Report on every ready cluster
+  labrat hub security report
+
+  # Report on specific clusters as JSON
+  labrat hub security report cluster-a cluster-b -o json`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1, got %d", concurrency)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			clusterNames := args
+			if len(clusterNames) == 0 {
+				clusterNames, err = fleet.Clusters(ctx, newHubBackend(kubeClient).ManagedClusters(),
+					hub.ManagedClusterFilter{Status: hub.StatusReady})
+				if err != nil {
+					return err
+				}
+			}
+
+			rows := make([]securityReportRow, len(clusterNames))
+			index := make(map[string]int, len(clusterNames))
+			for i, name := range clusterNames {
+				rows[i].Cluster = name
+				index[name] = i
+			}
+
+			results := fleet.NewRunner(fleet.Options{Concurrency: concurrency, Logger: logger}).Run(ctx, clusterNames, func(ctx context.Context, name string) error {
+				spokeClient, err := newSpokeClient(ctx, kubeClient, name)
+				if err != nil {
+					return err
+				}
+				posture, err := spoke.NewSecurityInspector(spokeClient.GetCoreClient(), spokeClient.GetDynamicClient(), clientOptions...).Inspect(ctx)
+				if err != nil {
+					return err
+				}
+				rows[index[name]].SecurityPosture = posture
+				return nil
+			})
+			for _, r := range results {
+				if r.Err != nil {
+					rows[index[r.Cluster]].Error = r.Err.Error()
+				}
+			}
+
+			if err := writeSecurityReport(rows, outputFormat); err != nil {
+				return err
+			}
+			return fleet.Summarize(results).Err()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().Int("concurrency", fleet.DefaultConcurrency, "Maximum number of clusters inspected in parallel")
+	return cmd
+}
+
+// writeSecurityReport prints the security report as a table or JSON
+func writeSecurityReport(rows []securityReportRow, outputFormat string) error {
+	if outputFormat == "json" {
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+		return nil
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintln(os.Stdout, "No clusters to inspect")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "CLUSTER\tETCD ENCRYPTION\tAUDIT PROFILE\tDEFAULT SCC\tPOD SECURITY\tFIPS\tERROR\n")
+	for _, r := range rows {
+		if r.SecurityPosture == nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t%s\n", r.Cluster, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t\n", r.Cluster, r.EtcdEncryption, r.AuditProfile, r.DefaultSCC, r.PodSecurity, r.FIPS)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/sshkey"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// newHubSSHKeysCmd creates the `hub sshkeys` command group
+func newHubSSHKeysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sshkeys",
+		Short: "Manage the SSH keys of the cluster nodes",
+		Long: `Manage the SSH keys Hive installs the nodes of clusters with: the ssh-privatekey of the
+secret referenced by spec.provisioning.sshPrivateKeySecretRef of each ClusterDeployment,
+<cluster>-ssh-private-key in the cluster namespace for clusters provisioned by labrat.
+labrat spoke ssh connects to the nodes with these keys.`,
+	}
+	cmd.AddCommand(newHubSSHKeysListCmd(), audited(newHubSSHKeysCreateCmd()), audited(newHubSSHKeysRotateCmd()))
+	return cmd
+}
+
+// newHubSSHKeysListCmd creates the `hub sshkeys list` command
+func newHubSSHKeysListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the SSH keys of the clusters",
+		Long: `List the SSH key of every ClusterDeployment with its secret, fingerprint, age, and the
+bastion labrat spoke ssh jumps through. Clusters whose secret is missing or holds no
+usable key show the problem instead of a fingerprint.
+
+Examples:
+  # List the SSH keys
+  labrat hub sshkeys list
+
+  # Show the public key of every cluster
+  labrat hub sshkeys list -o jsonpath='{range .[*]}{.cluster}{"\t"}{.publicKey}{"\n"}{end}'`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			keys, err := newSSHKeyManager(kubeClient).List(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if written, err := writeListOutput(outputFormat, keys); written {
+				return err
+			}
+
+			if len(keys) == 0 {
+				fmt.Fprintln(os.Stdout, "No ClusterDeployments found")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "CLUSTER\tSECRET\tFINGERPRINT\tAGE\tBASTION")
+			for _, key := range keys {
+				fingerprint, age := key.Fingerprint, "N/A"
+				if key.Problem != "" {
+					fingerprint = "⚠️  " + key.Problem
+				}
+				if !key.UpdatedAt.IsZero() {
+					age = duration.HumanDuration(time.Since(key.UpdatedAt))
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", key.Cluster, key.Secret, fingerprint, age, valueOrNA(key.Bastion))
+			}
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	return cmd
+}
+
+// newHubSSHKeysCreateCmd creates the `hub sshkeys create` command
+func newHubSSHKeysCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <cluster-name>",
+		Short: "Store an SSH key for a cluster that has none",
+		Long: `Store an SSH key for a cluster whose SSH key secret is missing, e.g. because it was
+deleted, and authorize it for the core user on the nodes of the cluster.
+
+A new ed25519 key is generated unless --ssh-key gives a private key file. The key is
+authorized through the MachineConfigs of the spoke that set the SSH keys of the core
+user, which the machine config operator rolls out without rebooting the nodes; with
+--no-authorize the key is only stored, e.g. because it is already authorized.
+
+Use labrat hub sshkeys rotate to replace an existing key.
+
+Examples:
+  # Generate and authorize a key for a cluster
+  labrat hub sshkeys create my-cluster
+
+  # Store the key the cluster was installed with
+  labrat hub sshkeys create my-cluster --ssh-key ~/.ssh/labs_ed25519 --no-authorize`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			sshKeyPath, _ := cmd.Flags().GetString("ssh-key")
+			noAuthorize, _ := cmd.Flags().GetBool("no-authorize")
+
+			pair, err := sshKeyPair(clusterName, sshKeyPath)
+			if err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			info, err := newSSHKeyManager(kubeClient).Create(ctx, clusterName, *pair)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ SSH key of %s stored in %s/%s (%s)\n", clusterName, clusterName, info.Secret, info.Fingerprint)
+
+			if noAuthorize {
+				return nil
+			}
+			spokeClient, err := newSpokeClient(ctx, kubeClient, clusterName)
+			if err != nil {
+				return fmt.Errorf("key stored but not authorized on the nodes: %w", err)
+			}
+			changed, err := spoke.NewAuthorizedKeyManager(spokeClient.GetDynamicClient(), clientOptions...).Authorize(ctx, pair.PublicKey)
+			if err != nil {
+				return fmt.Errorf("key stored but not authorized on the nodes: %w", err)
+			}
+			printAuthorizedKeyChange("Authorized", clusterName, changed)
+			return nil
+		},
+	}
+	cmd.Flags().String("ssh-key", "", "Private SSH key file to store instead of generating a key")
+	cmd.Flags().Bool("no-authorize", false, "Only store the key, without authorizing it on the nodes")
+	return cmd
+}
+
+// newHubSSHKeysRotateCmd creates the `hub sshkeys rotate` command
+func newHubSSHKeysRotateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate <cluster-name>",
+		Short: "Replace the SSH key of a cluster",
+		Long: `Replace the SSH key of a cluster, e.g. after it was shared with a partner who no longer
+needs access to the nodes.
+
+A new ed25519 key is generated unless --ssh-key gives a private key file. The new key is
+authorized for the core user on the nodes first, then stored in the SSH key secret of
+the cluster, and finally the old key is removed from the nodes unless --keep-old is
+given. The nodes are changed through the MachineConfigs of the spoke that set the SSH
+keys of the core user, which the machine config operator rolls out without rebooting
+them. The cluster must be running.
+
+Examples:
+  # Rotate the SSH key of a cluster
+  labrat hub sshkeys rotate my-cluster
+
+  # Rotate to a given key and keep the old key working
+  labrat hub sshkeys rotate my-cluster --ssh-key ~/.ssh/labs_ed25519 --keep-old`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			sshKeyPath, _ := cmd.Flags().GetString("ssh-key")
+			keepOld, _ := cmd.Flags().GetBool("keep-old")
+
+			pair, err := sshKeyPair(clusterName, sshKeyPath)
+			if err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			keys := newSSHKeyManager(kubeClient)
+			old, err := keys.Get(ctx, clusterName)
+			if err != nil {
+				return err
+			}
+			if sshkey.Equal(old.PublicKey, pair.PublicKey) {
+				return fmt.Errorf("%s already uses this SSH key", clusterName)
+			}
+			spokeClient, err := newSpokeClient(ctx, kubeClient, clusterName)
+			if err != nil {
+				return err
+			}
+			authorized := spoke.NewAuthorizedKeyManager(spokeClient.GetDynamicClient(), clientOptions...)
+
+			changed, err := authorized.Authorize(ctx, pair.PublicKey)
+			if err != nil {
+				return err
+			}
+			printAuthorizedKeyChange("Authorized new key", clusterName, changed)
+
+			info, err := keys.Update(ctx, clusterName, *pair)
+			if err != nil {
+				return fmt.Errorf("new key authorized but not stored, the old key still works: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "✓ SSH key of %s replaced in %s/%s (%s)\n", clusterName, clusterName, info.Secret, info.Fingerprint)
+
+			if keepOld {
+				return nil
+			}
+			changed, err = authorized.Revoke(ctx, old.PublicKey)
+			if err != nil {
+				return fmt.Errorf("failed to remove the old key from the nodes: %w", err)
+			}
+			printAuthorizedKeyChange("Removed old key", clusterName, changed)
+			return nil
+		},
+	}
+	cmd.Flags().String("ssh-key", "", "Private SSH key file to rotate to instead of generating a key")
+	cmd.Flags().Bool("keep-old", false, "Keep the old key authorized on the nodes")
+	return cmd
+}
+
+// newSSHKeyManager creates an SSHKeyManager on the hub
+func newSSHKeyManager(kubeClient *kube.Client) spoke.SSHKeyManager {
+	return spoke.NewSSHKeyManager(kubeClient.GetDynamicClient(), kubeClient.GetCoreClient().CoreV1(), clientOptions...)
+}
+
+// sshKeyPair reads the private key file at path and derives its public key, or generates a
+// key pair for the cluster if path is empty
+func sshKeyPair(clusterName, path string) (*sshkey.KeyPair, error) {
+	if path == "" {
+		return sshkey.Generate("labrat-" + clusterName)
+	}
+	privateKey, err := os.ReadFile(config.ExpandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	publicKey, err := sshkey.PublicKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key %s: %w", path, err)
+	}
+	return &sshkey.KeyPair{PrivateKey: privateKey, PublicKey: publicKey}, nil
+}
+
+// printAuthorizedKeyChange reports the MachineConfigs of a cluster whose SSH keys changed
+func printAuthorizedKeyChange(action, clusterName string, changed []string) {
+	if len(changed) == 0 {
+		fmt.Fprintf(os.Stderr, "✓ %s on the nodes of %s: no change needed\n", action, clusterName)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "✓ %s on the nodes of %s via MachineConfig %s\n", action, clusterName, strings.Join(changed, ", "))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newHubStatusCmd creates the `hub status` command
+func newHubStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Check health of the ACM hub",
+		Long: `Run health checks against the ACM hub: API server reachability, ACM and Hive
+API availability, MultiClusterHub phase, and managed cluster availability.
+
+The command exits non-zero if any check fails, so it can gate CI pipelines.
+
+Examples:
+  # Show hub health as a table
+  labrat hub status
+
+  # Write a JUnit report for CI dashboards
+  labrat hub status -o junit > hub-status.xml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			checker := hub.NewStatusChecker(
+				kubeClient.GetCoreClient().Discovery(),
+				kubeClient.GetDynamicClient(),
+				cfg.Hub.Namespace,
+				clientOptions...,
+			)
+			report := checker.Check(cmd.Context())
+
+			if err := check.NewWriter(check.OutputFormat(outputFormat), os.Stdout).Write(report); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+
+			return report.Err()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json|junit)")
+	return cmd
+}
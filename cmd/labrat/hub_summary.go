@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newHubSummaryCmd creates the `hub summary` command
+func newHubSummaryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "summary",
+		Short: "Summarize the clusters of the hub by status, platform, region, version, and power state",
+		Long: `Aggregate ManagedCluster and ClusterDeployment data into cluster counts per status,
+platform, region, OpenShift version, and power state, for a quick health overview of
+the lab. Clusters without a value, e.g. imported clusters without a ClusterDeployment,
+are counted as N/A.
+
+With --hub all the clusters of every configured hub are summarized together, with a
+count per hub.
+
+Examples:
+  # Summarize the active hub
+  labrat hub summary
+
+  # Summarize every hub as JSON
+  labrat hub summary --hub all -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			hubName, _ := cmd.Flags().GetString("hub")
+
+			if format, _ := hub.ParseOutputFormat(outputFormat); format != hub.OutputFormatTable && format != hub.OutputFormatJSON &&
+				format != hub.OutputFormatYAML && !format.IsTemplate() {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			ctx := cmd.Context()
+			if hubName != config.AllHubs {
+				_, kubeClient, err := newHubClient(cmd)
+				if err != nil {
+					return err
+				}
+				clusters, err := listCombinedClusters(ctx, kubeClient, hub.ManagedClusterFilter{})
+				if err != nil {
+					return err
+				}
+				return writeSummary(outputFormat, clusters)
+			}
+
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			// Hubs that fail are reported on stderr; the others are still summarized
+			clusters, err := fanOutHubs(ctx, cfg, func(ctx context.Context, kubeClient *kube.Client, hubName string) ([]hub.CombinedClusterInfo, error) {
+				clusters, err := listCombinedClusters(ctx, kubeClient, hub.ManagedClusterFilter{})
+				for i := range clusters {
+					clusters[i].Hub = hubName
+				}
+				return clusters, err
+			})
+			if writeErr := writeSummary(outputFormat, clusters); writeErr != nil {
+				return writeErr
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json|yaml|jsonpath=...|go-template=...)")
+	return cmd
+}
+
+// writeSummary writes the summary of clusters to stdout
+func writeSummary(outputFormat string, clusters []hub.CombinedClusterInfo) error {
+	if err := hub.NewOutputWriter(hub.OutputFormat(outputFormat), os.Stdout).WriteSummary(hub.Summarize(clusters)); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
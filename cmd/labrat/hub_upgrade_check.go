@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// upgradeCheckRow is the version of one spoke compared with its target version, or the error
+// that prevented reading it
+type upgradeCheckRow struct {
+	Cluster string `json:"cluster"`
+	*spoke.VersionStatus
+	LatestUpdate string `json:"latestUpdate,omitempty"`
+	Target       string `json:"target,omitempty"`
+	MinorsBehind int    `json:"minorsBehind"`
+	Error        string `json:"error,omitempty"`
+}
+
+// newHubUpgradeCheckCmd creates the `hub upgrade-check` command
+func newHubUpgradeCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade-check [cluster-name...]",
+		Short: "Audit the OpenShift versions of spoke clusters against their update channel",
+		Long: `Compare the installed OpenShift version of spoke clusters with the version they
+should run, to find clusters that fell behind:
+
+  - VERSION: the last version completely rolled out, from the ClusterVersion of the spoke
+  - CHANNEL: the update channel of the ClusterVersion, or the one requested by the
+             ClusterCurator of the cluster on the hub
+  - LATEST:  the newest update the update graph of the channel offers
+  - TARGET:  the update requested by the ClusterCurator, otherwise the newer of LATEST
+             and the minor version of the channel (4.16 for stable-4.16)
+  - BEHIND:  how many minor versions VERSION is behind TARGET
+
+Every Ready managed cluster is inspected unless cluster names are given. Spokes are
+reached with the admin kubeconfig of their ClusterDeployment, in parallel bounded by
+--concurrency. With --behind only clusters at least that many minor versions behind are
+listed. Clusters that cannot be inspected are listed with the error and the command exits
+non-zero.
+
+Examples:
+  # Audit every ready cluster
+  labrat hub upgrade-check
+
+  # List the clusters two or more minor versions behind as JSON for reporting
+  labrat hub upgrade-check --behind 2 -o json`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+			behind, _ := cmd.Flags().GetInt("behind")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1, got %d", concurrency)
+			}
+			if behind < 0 {
+				return fmt.Errorf("--behind must not be negative, got %d", behind)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			clusterNames := args
+			if len(clusterNames) == 0 {
+				clusterNames, err = fleet.Clusters(ctx, newHubBackend(kubeClient).ManagedClusters(),
+					hub.ManagedClusterFilter{Status: hub.StatusReady})
+				if err != nil {
+					return err
+				}
+			}
+
+			curators, err := hub.NewClusterCuratorClient(kubeClient.GetDynamicClient(), clientOptions...).List(ctx)
+			if err != nil {
+				return err
+			}
+			curatorOf := make(map[string]hub.ClusterCuratorInfo, len(curators))
+			for _, curator := range curators {
+				curatorOf[curator.Cluster] = curator
+			}
+
+			rows := make([]upgradeCheckRow, len(clusterNames))
+			index := make(map[string]int, len(clusterNames))
+			for i, name := range clusterNames {
+				rows[i].Cluster = name
+				index[name] = i
+			}
+
+			results := fleet.NewRunner(fleet.Options{Concurrency: concurrency, Logger: logger}).Run(ctx, clusterNames, func(ctx context.Context, name string) error {
+				spokeClient, err := newSpokeClient(ctx, kubeClient, name)
+				if err != nil {
+					return err
+				}
+				status, err := spoke.NewVersionInspector(spokeClient.GetDynamicClient(), clientOptions...).Inspect(ctx)
+				if err != nil {
+					return err
+				}
+				if curator, ok := curatorOf[name]; ok {
+					status.DesiredUpdate = curator.DesiredUpdate
+					if curator.Channel != "" {
+						status.Channel = curator.Channel
+					}
+				}
+
+				row := &rows[index[name]]
+				row.VersionStatus = status
+				row.LatestUpdate = status.LatestUpdate()
+				row.Target = status.Target()
+				row.MinorsBehind = status.MinorsBehind()
+				return nil
+			})
+			for _, r := range results {
+				if r.Err != nil {
+					rows[index[r.Cluster]].Error = r.Err.Error()
+				}
+			}
+
+			listed := make([]upgradeCheckRow, 0, len(rows))
+			for _, row := range rows {
+				if row.Error != "" || row.MinorsBehind >= behind {
+					listed = append(listed, row)
+				}
+			}
+
+			if err := writeUpgradeCheck(listed, outputFormat); err != nil {
+				return err
+			}
+			return fleet.Summarize(results).Err()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().Int("concurrency", fleet.DefaultConcurrency, "Maximum number of clusters inspected in parallel")
+	cmd.Flags().Int("behind", 0, "Only list clusters at least this many minor versions behind their target")
+	return cmd
+}
+
+// writeUpgradeCheck prints the version audit as a table or JSON
+func writeUpgradeCheck(rows []upgradeCheckRow, outputFormat string) error {
+	if outputFormat == "json" {
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+		return nil
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintln(os.Stdout, "No clusters behind their target version")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "CLUSTER\tVERSION\tCHANNEL\tLATEST\tTARGET\tBEHIND\tERROR\n")
+	for _, r := range rows {
+		if r.VersionStatus == nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t%s\n", r.Cluster, r.Error)
+			continue
+		}
+		version := r.Version
+		if r.Updating != "" {
+			version += " (updating to " + r.Updating + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t\n", r.Cluster, version, valueOrNA(r.Channel), valueOrNA(r.LatestUpdate), valueOrNA(r.Target), r.MinorsBehind)
+	}
+	return w.Flush()
+}
@@ -2,13 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/log"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/retry"
 	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
 	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
-	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
 	"github.com/spf13/cobra"
 )
 
@@ -21,76 +25,178 @@ func main() {
 		Short: "Lab Administration, Bootstrapping, and Resource Automation Toolkit",
 		Long: `LABRAT is the primary CLI utility for the OpenShift Partner Labs offering.
 It provides a centralized interface for managing the ACM Hub and partner spoke clusters.`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := setupLogging(cmd); err != nil {
+				return err
+			}
+			if err := setupClientOptions(cmd, nil); err != nil {
+				return err
+			}
+			if err := setupTimeout(cmd); err != nil {
+				return err
+			}
+			if err := setupBackend(cmd); err != nil {
+				return err
+			}
+			return startProfiling(cmd)
+		},
 	}
 
 	// Persistent Flags
 	rootCmd.PersistentFlags().StringP("config", "c", "$PWD/config.yaml", "path to labrat config")
-	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "enable debug logging")
+	rootCmd.PersistentFlags().IntP("verbose", "v", 0, "log verbosity; -v logs API requests to stderr, -v=5 also prints a timing breakdown")
+	rootCmd.PersistentFlags().Lookup("verbose").NoOptDefVal = "1"
+	rootCmd.PersistentFlags().String("log-level", "", "minimum level of log records (debug|info|warn|error); overrides -v (default: warn, or debug with -v)")
+	rootCmd.PersistentFlags().String("log-format", string(log.FormatText), "format of log records on stderr (text|json)")
+	rootCmd.PersistentFlags().Int("retries", retry.DefaultRetries, "number of retries of API calls that fail transiently, 0 to disable; overrides retry.retries of the config")
+	rootCmd.PersistentFlags().Duration("retry-backoff", retry.DefaultBackoff, "delay before the first retry of a failed API call, doubled for each further retry; overrides retry.backoff of the config")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "maximum time the command may take, e.g. 5m; 0 for no limit. Each API request is bounded separately, and commands that wait bound the wait by --wait-timeout")
+	rootCmd.PersistentFlags().String("backend", "", "serve the hubs from the Kubernetes objects of the YAML files in a directory instead of their API servers, e.g. file://./test/fixtures; changes are kept in memory only")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "list clusters from the hub instead of the on-disk cache enabled by cache.ttl of the config")
+	rootCmd.PersistentFlags().String("hub", "", "name of the hub to use from the config, or \"all\" to query every hub (default: primary hub)")
+	addProfilingFlags(rootCmd)
 
 	// --- HUB COMMAND ---
 	hubCmd := &cobra.Command{
 		Use:   "hub",
 		Short: "Interact with the primary ACM management cluster",
 	}
-	hubStatusCmd := &cobra.Command{
-		Use:   "status",
-		Short: "Check health of the ACM hub",
-		Run: func(_ *cobra.Command, _ []string) {
-			fmt.Println("🔍 Checking ACM Hub status...")
-			// Logic for OpenShift API calls would go here
-		},
-	}
-
 	hubManagedClustersCmd := &cobra.Command{
 		Use:   "managedclusters",
 		Short: "List ACM managed clusters",
-		Long:  `List all managed clusters from the ACM hub with status information.`,
+		Long: `List all managed clusters from the ACM hub with status information.
+
+With --hub all, every hub in the config is queried concurrently and the results
+are merged with a HUB column. Hubs that cannot be reached are reported and the
+command exits non-zero after listing the clusters of the others.
+
+With --clusterset, only the clusters of a ManagedClusterSet are listed; see
+labrat hub clustersets. --name keeps the clusters whose name matches a glob pattern.
+With --wide, clusters can also be filtered by --platform, --region, --power, and
+--version, which takes a version (4.15 matches every 4.15.z) or a comma-separated
+range such as '>=4.14,<4.16'. All filters can be combined, e.g.
+  labrat hub managedclusters --wide --platform aws --region us-east-1 --version '<4.15' --name 'partner-*'
+
+With --watch, the clusters are listed and then every change is streamed as it
+happens, like kubectl get --watch, until the command is interrupted. Meanwhile the
+lifecycle events of every cluster are sent to the destinations configured under notify.
+
+With --sort-by the clusters are ordered by name, status, version, region, or power
+state instead of API order; append :desc to reverse the order, e.g. version:desc.
+Version, region, and power are only known with --wide.
+
+With -o jsonpath=... or -o go-template=..., like kubectl, only the fields selected
+by the template are printed. The template sees the clusters as -o json prints them,
+wrapped in an object with an items field, e.g.
+  labrat hub managedclusters -o jsonpath='{range .items[*]}{.Name}{"\n"}{end}'`,
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			// 1. Get flags
-			configPath, _ := cmd.Flags().GetString("config")
 			outputFormat, _ := cmd.Flags().GetString("output")
 			statusFilter, _ := cmd.Flags().GetString("status")
+			clusterSet, _ := cmd.Flags().GetString("clusterset")
+			namePattern, _ := cmd.Flags().GetString("name")
+			platform, _ := cmd.Flags().GetString("platform")
+			region, _ := cmd.Flags().GetString("region")
+			version, _ := cmd.Flags().GetString("version")
+			powerState, _ := cmd.Flags().GetString("power")
 			wide, _ := cmd.Flags().GetBool("wide")
+			hubName, _ := cmd.Flags().GetString("hub")
+			watchClusters, _ := cmd.Flags().GetBool("watch")
+			sortBy, _ := cmd.Flags().GetString("sort-by")
 
-			// 2. Load config (expand path to support both $HOME and ~)
-			cfg, err := config.Load(config.ExpandPath(configPath))
+			if watchClusters && (wide || hubName == config.AllHubs) {
+				return fmt.Errorf("--watch cannot be combined with --wide or --hub %s", config.AllHubs)
+			}
+			if format, _ := hub.ParseOutputFormat(outputFormat); watchClusters && (format == hub.OutputFormatCSV || format == hub.OutputFormatTSV || format.IsTemplate()) {
+				return fmt.Errorf("--watch does not support output format %s", format)
+			}
+			sortOptions, err := hub.ParseSortBy(sortBy)
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
+			}
+			if sortOptions.Field != "" && watchClusters {
+				return fmt.Errorf("--sort-by cannot be combined with --watch")
+			}
+			if sortOptions.Field.RequiresCombined() && !wide {
+				return fmt.Errorf("sorting by %s requires --wide", sortOptions.Field)
+			}
+			filter := hub.ManagedClusterFilter{
+				Status:     hub.ClusterStatus(statusFilter),
+				ClusterSet: clusterSet,
+				Name:       namePattern,
+				Platform:   platform,
+				Region:     region,
+				Version:    hub.VersionConstraint(version),
+				PowerState: powerState,
+			}
+			if err := filter.Validate(); err != nil {
+				return err
+			}
+			if filter.RequiresCombined() && !wide {
+				return fmt.Errorf("--platform, --region, --version, and --power require --wide")
 			}
 
-			// 3. Create Kubernetes client
-			kubeClient, err := kube.NewClient(cfg.GetHubKubeconfig(), cfg.Hub.Context)
+			// 2. Load config, activating the hub selected with --hub
+			cfg, err := loadConfig(cmd)
 			if err != nil {
-				return fmt.Errorf("failed to create kubernetes client: %w", err)
+				return err
 			}
 
-			// 4. Create output writer
+			// 3. Create output writer
 			output := hub.NewOutputWriter(hub.OutputFormat(outputFormat), os.Stdout)
-
-			// 5. If --wide flag is set, use combined cluster view
-			ctx := context.Background()
-			if wide {
-				// Create both ManagedCluster and ClusterDeployment clients
-				mcClient := hub.NewManagedClusterClient(kubeClient.GetDynamicClient())
-				cdClient := hub.NewClusterDeploymentClient(kubeClient.GetDynamicClient())
-				combinedClient := hub.NewCombinedClusterClient(mcClient, cdClient)
-
-				// List combined clusters
-				combined, err := combinedClient.ListCombined(ctx)
-				if err != nil {
-					return fmt.Errorf("failed to list combined clusters: %w", err)
+			output.SetSort(sortOptions)
+
+			// 4. With --hub all, query every hub concurrently and tag clusters with their hub
+			ctx := cmd.Context()
+			if hubName == config.AllHubs {
+				if wide {
+					combined, err := fanOutHubs(ctx, cfg, func(ctx context.Context, kubeClient *kube.Client, hubName string) ([]hub.CombinedClusterInfo, error) {
+						clusters, err := listCombinedClusters(ctx, kubeClient, filter)
+						for i := range clusters {
+							clusters[i].Hub = hubName
+						}
+						recordListedStatuses(cfg, hubName, combinedAsManaged(clusters))
+						return clusters, err
+					})
+					if writeErr := output.WriteCombined(combined, true); writeErr != nil {
+						return fmt.Errorf("failed to write output: %w", writeErr)
+					}
+					return err
 				}
 
-				// Apply filter if specified (filter on Status field)
-				if statusFilter != "" {
-					filtered := make([]hub.CombinedClusterInfo, 0)
-					for _, cluster := range combined {
-						if string(cluster.Status) == statusFilter {
-							filtered = append(filtered, cluster)
-						}
+				clusters, err := fanOutHubs(ctx, cfg, func(ctx context.Context, kubeClient *kube.Client, hubName string) ([]hub.ManagedClusterInfo, error) {
+					clusters, err := listManagedClusters(ctx, kubeClient, filter)
+					for i := range clusters {
+						clusters[i].Hub = hubName
 					}
-					combined = filtered
+					recordListedStatuses(cfg, hubName, clusters)
+					return clusters, err
+				})
+				if writeErr := output.Write(clusters); writeErr != nil {
+					return fmt.Errorf("failed to write output: %w", writeErr)
 				}
+				return err
+			}
+
+			// 5. Create Kubernetes client
+			kubeClient, err := hubBackend.NewClient(cfg.GetHubKubeconfig(), cfg.Hub.Context, clientOptions...)
+			if err != nil {
+				return fmt.Errorf("failed to create kubernetes client: %w", err)
+			}
+
+			// 6. With --watch, stream changes until interrupted
+			if watchClusters {
+				return watchManagedClusters(ctx, cfg, kubeClient, output, filter)
+			}
+
+			// 7. If --wide flag is set, use combined cluster view
+			if wide {
+				combined, err := listCombinedClusters(ctx, kubeClient, filter)
+				if err != nil {
+					return err
+				}
+				recordListedStatuses(cfg, cfg.HubName(), combinedAsManaged(combined))
 
 				// Output combined results
 				if err := output.WriteCombined(combined, true); err != nil {
@@ -98,21 +204,11 @@ It provides a centralized interface for managing the ACM Hub and partner spoke c
 				}
 			} else {
 				// Use standard ManagedCluster view
-				mcClient := hub.NewManagedClusterClient(kubeClient.GetDynamicClient())
-
-				// List clusters
-				clusters, err := mcClient.List(ctx)
+				clusters, err := listManagedClusters(ctx, kubeClient, filter)
 				if err != nil {
-					return fmt.Errorf("failed to list managed clusters: %w", err)
-				}
-
-				// Apply filter if specified
-				if statusFilter != "" {
-					filter := hub.ManagedClusterFilter{
-						Status: hub.ClusterStatus(statusFilter),
-					}
-					clusters = mcClient.Filter(clusters, filter)
+					return err
 				}
+				recordListedStatuses(cfg, cfg.HubName(), clusters)
 
 				// Output results
 				if err := output.Write(clusters); err != nil {
@@ -124,128 +220,60 @@ It provides a centralized interface for managing the ACM Hub and partner spoke c
 		},
 	}
 
-	hubManagedClustersCmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	hubManagedClustersCmd.Flags().StringP("output", "o", "table", "Output format (table|json|yaml|csv|tsv|jsonpath=...|go-template=...)")
 	hubManagedClustersCmd.Flags().String("status", "", "Filter by status (Ready|NotReady|Unknown)")
-	hubManagedClustersCmd.Flags().Bool("wide", false, "Show additional cluster details from ClusterDeployment")
-
-	hubCmd.AddCommand(hubStatusCmd, hubManagedClustersCmd)
+	hubManagedClustersCmd.Flags().String("clusterset", "", "Filter by ManagedClusterSet")
+	hubManagedClustersCmd.Flags().String("name", "", "Filter by a glob pattern of the cluster name, e.g. 'partner-*'")
+	hubManagedClustersCmd.Flags().String("platform", "", "Filter by cloud platform, e.g. aws (requires --wide)")
+	hubManagedClustersCmd.Flags().String("region", "", "Filter by cloud region, e.g. us-east-1 (requires --wide)")
+	hubManagedClustersCmd.Flags().String("version", "", "Filter by OpenShift version or range, e.g. 4.15 or '>=4.14,<4.16' (requires --wide)")
+	hubManagedClustersCmd.Flags().String("power", "", "Filter by power state, e.g. Hibernating (requires --wide)")
+	hubManagedClustersCmd.Flags().Bool("wide", false, "Show additional cluster details from ClusterDeployment and ManagedClusterInfo")
+	hubManagedClustersCmd.Flags().BoolP("watch", "w", false, "Stream cluster status changes after listing the clusters")
+	hubManagedClustersCmd.Flags().String("sort-by", "", "Sort clusters by name|status|version|region|power, with :desc to reverse (default: API order)")
+	hubManagedClustersCmd.AddCommand(newHubManagedClustersGetCmd())
+
+	hubCmd.AddCommand(newHubStatusCmd(), newHubCanICmd(), newHubSummaryCmd(), hubManagedClustersCmd, newHubClusterDeploymentsCmd(), newHubOrphansCmd(), audited(newHubGCCmd()), audited(newHubFailoverCmd()), audited(newHubImportCmd()), newHubLeasesCmd(), newHubCapacityCmd(), newHubCostsCmd(), newHubAddOnsCmd(), newHubImageSetsCmd(), newHubSSHKeysCmd(), newHubPoliciesCmd(), newHubSecurityCmd(), newHubUpgradeCheckCmd(), newHubComplianceCmd(), newHubCredentialsCmd(), newHubClusterSetsCmd(), newHubEventsCmd(), newHubExportCmd(), newHubDiffCmd(), newHubFlappingCmd())
 
 	// --- SPOKE COMMAND ---
 	spokeCmd := &cobra.Command{
 		Use:   "spoke",
 		Short: "Manage individual partner-requested clusters",
 	}
-	spokeCreateCmd := &cobra.Command{
-		Use:   "create",
-		Short: "Provision a new partner cluster",
-		Run: func(cmd *cobra.Command, _ []string) {
-			requestID, err := cmd.Flags().GetString("request-id")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error getting request-id: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("🚀 Initiating bootstrap for request: %s\n", requestID)
-		},
-	}
-	spokeCreateCmd.Flags().String("request-id", "", "ID of the partner request (Required)")
-	if err := spokeCreateCmd.MarkFlagRequired("request-id"); err != nil {
-		fmt.Fprintf(os.Stderr, "Error marking flag required: %v\n", err)
-		os.Exit(1)
-	}
-
-	spokeKubeconfigCmd := &cobra.Command{
-		Use:   "kubeconfig <cluster-name>",
-		Short: "Extract admin kubeconfig for a spoke cluster",
-		Long: `Extract the admin kubeconfig from a spoke cluster's ClusterDeployment secret.
-
-This command retrieves the admin kubeconfig which has full cluster-admin privileges.
-Use with caution and store securely.
-
-Examples:
-  # Print kubeconfig to stdout
-  labrat spoke kubeconfig my-cluster
-
-  # Save kubeconfig to file
-  labrat spoke kubeconfig my-cluster -o /tmp/my-cluster.kubeconfig
-
-  # Use the kubeconfig with kubectl
-  labrat spoke kubeconfig my-cluster -o /tmp/kubeconfig
-  kubectl --kubeconfig /tmp/kubeconfig get nodes`,
-		Args: cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			clusterName := args[0]
-			configPath, _ := cmd.Flags().GetString("config")
-			outputPath, _ := cmd.Flags().GetString("output")
-
-			// Load config
-			cfg, err := config.Load(config.ExpandPath(configPath))
-			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
-			}
-
-			// Create Kubernetes client
-			kubeClient, err := kube.NewClient(cfg.GetHubKubeconfig(), cfg.Hub.Context)
-			if err != nil {
-				return fmt.Errorf("failed to create kubernetes client: %w", err)
-			}
 
-			// Create kubeconfig extractor
-			extractor := spoke.NewKubeconfigExtractor(
-				kubeClient.GetDynamicClient(),
-				kubeClient.GetCoreClient().CoreV1(),
-			)
-
-			ctx := context.Background()
-
-			// Display security warning
-			fmt.Fprintf(os.Stderr, "\n⚠️  WARNING: This is an admin kubeconfig with full cluster-admin privileges!\n")
-			fmt.Fprintf(os.Stderr, "    Please store it securely and restrict access appropriately.\n\n")
-
-			if outputPath != "" {
-				// Extract to file
-				if err := extractor.ExtractToFile(ctx, clusterName, outputPath); err != nil {
-					return fmt.Errorf("failed to extract kubeconfig: %w", err)
-				}
-				fmt.Fprintf(os.Stderr, "✓ Kubeconfig saved to: %s\n", outputPath)
-				fmt.Fprintf(os.Stderr, "  File permissions set to 0600 (owner read/write only)\n\n")
-				fmt.Fprintf(os.Stderr, "You can now use it with kubectl:\n")
-				fmt.Fprintf(os.Stderr, "  kubectl --kubeconfig %s get nodes\n", outputPath)
-			} else {
-				// Extract to stdout
-				kubeconfig, err := extractor.Extract(ctx, clusterName)
-				if err != nil {
-					return fmt.Errorf("failed to extract kubeconfig: %w", err)
-				}
-				fmt.Print(string(kubeconfig))
-			}
-
-			return nil
-		},
-	}
-	spokeKubeconfigCmd.Flags().StringP("output", "o", "", "Output file path (default: stdout)")
-
-	spokeCmd.AddCommand(spokeCreateCmd, spokeKubeconfigCmd)
+	spokeCmd.AddCommand(audited(newSpokeCreateCmd()), audited(newSpokeDeleteCmd()), audited(newSpokeDetachCmd()), newSpokeLeaseCmd(), audited(newSpokeKubeconfigCmd()), audited(newSpokeExecCmd()), audited(newSpokeSSHCmd()), newSpokeNodesCmd(), audited(newSpokeCredentialsCmd()), audited(newSpokeHibernateCmd()), audited(newSpokeResumeCmd()), audited(newSpokeScaleCmd()), newSpokeMachinePoolsCmd(), newSpokeSmokeCmd(), newSpokeHealthCmd(), newSpokeCSRCmd(), audited(newSpokeConsoleCmd()), audited(newSpokeCloudConsoleCmd()), audited(newSpokeLabelCmd()), audited(newSpokeAnnotateCmd()), audited(newSpokeUpgradeCmd()), audited(newSpokePauseReconcileCmd()), audited(newSpokeResumeReconcileCmd()), newSpokeDRCmd(), newSpokeAddOnsCmd(), newSpokeComplianceCmd(), newSpokeVulnsCmd(), newSpokeStatusCmd(), newSpokeLogsCmd(), newSpokeEventsCmd())
 
 	// --- BOOTSTRAP COMMAND ---
 	bootstrapCmd := &cobra.Command{
 		Use:   "bootstrap",
 		Short: "Initialize new lab environments",
 	}
-	bootstrapInitCmd := &cobra.Command{
-		Use:   "init",
-		Short: "Initialize local labrat configuration",
-		Run: func(_ *cobra.Command, _ []string) {
-			fmt.Println("⚙️ Initializing LABRAT environment...")
-		},
-	}
-	bootstrapCmd.AddCommand(bootstrapInitCmd)
+	bootstrapCmd.AddCommand(newBootstrapInitCmd(), newBootstrapValidateCmd(), newBootstrapCredentialsCmd())
 
 	// Add all top-level commands to root
-	rootCmd.AddCommand(hubCmd, spokeCmd, bootstrapCmd)
-
-	// Execute
-	if err := rootCmd.Execute(); err != nil {
+	rootCmd.AddCommand(hubCmd, spokeCmd, bootstrapCmd, newConfigCmd(), newApplyCmd(), newRequestCmd(), newPoolCmd(), newScheduleCmd(), newCacheCmd(), newServeCmd(), newTUICmd())
+
+	// Execute, canceling the command on the first interrupt; a second one kills labrat
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if cancelTimeout != nil {
+		cancelTimeout()
+	}
+	finishProfiling()
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.code)
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/notify"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/server"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+// notifyEvents sends the events published to broker to the destinations configured under
+// notify until ctx is done. It does nothing if none is configured. Failed notifications are
+// logged and not retried.
+func notifyEvents(ctx context.Context, cfg *config.Config, broker *server.Broker) {
+	dispatcher := notify.New(cfg.Notify, nil)
+	if dispatcher == nil {
+		return
+	}
+
+	events, unsubscribe := broker.Subscribe()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				err := dispatcher.Notify(ctx, notify.Event{
+					Type:    string(event.Type),
+					Cluster: event.Cluster,
+					Hub:     cfg.HubName(),
+					Time:    event.Time,
+					Message: event.Message,
+				})
+				if err != nil {
+					logger.Warn("failed to send notification", "event", event.Type, "cluster", event.Cluster, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// watchNotifications polls the hub for lifecycle events and sends them to the destinations
+// configured under notify until ctx is done, for watch modes that do not run an
+// EventSource of their own. It does nothing if none is configured.
+func watchNotifications(ctx context.Context, cfg *config.Config, mcClient hub.ManagedClusterClient, cdClient hub.ClusterDeploymentClient) {
+	if !cfg.Notify.Enabled() {
+		return
+	}
+	broker := server.NewBroker()
+	notifyEvents(ctx, cfg, broker)
+	go server.NewEventSource(mcClient, cdClient, broker).Run(ctx, server.DefaultEventPollInterval)
+}
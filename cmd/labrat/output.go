@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+// listOutputHelp is the help of the --output flag of the list commands
+const listOutputHelp = "Output format (table|json|jsonpath=...|go-template=...)"
+
+// validateListOutput checks the --output flag of a list command, which supports table, JSON,
+// and the jsonpath and go-template formats
+func validateListOutput(value string) error {
+	format, _ := hub.ParseOutputFormat(value)
+	if format != hub.OutputFormatTable && format != hub.OutputFormatJSON && !format.IsTemplate() {
+		return fmt.Errorf("unsupported output format: %s", value)
+	}
+	return nil
+}
+
+// writeListOutput writes items to stdout as JSON or rendered with the template of the --output
+// flag. It reports whether it wrote the items, leaving the table format to the caller.
+func writeListOutput(value string, items interface{}) (bool, error) {
+	format, template := hub.ParseOutputFormat(value)
+	switch {
+	case format.IsTemplate():
+		return true, hub.WriteTemplate(os.Stdout, format, template, items)
+	case format == hub.OutputFormatJSON:
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return true, fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/audit"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/plan"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// unplannedFlags are the flags that select the clusters of a plan or make it; a plan records
+// the clusters they resolved to instead
+var unplannedFlags = map[string]bool{
+	"plan":         true,
+	"selector":     true,
+	"clusterset":   true,
+	"all":          true,
+	"yes":          true,
+	"expired-only": true,
+}
+
+// addPlanFlag registers the --plan flag of commands that labrat apply can run
+func addPlanFlag(cmd *cobra.Command) {
+	cmd.Flags().String("plan", "", "Save the changes to this file (- for stdout) for labrat apply instead of making them")
+}
+
+// planPath returns the file given with --plan, empty if the changes are made right away
+func planPath(cmd *cobra.Command) string {
+	path, _ := cmd.Flags().GetString("plan")
+	return path
+}
+
+// savePlan saves the plan of cmd against clusters to the file given with --plan. describe
+// describes the change of each cluster; args are the arguments after the cluster names.
+func savePlan(ctx context.Context, cmd *cobra.Command, cfg *config.Config, clusters, args []string, describe func(ctx context.Context, cluster string) (string, error)) error {
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	p := plan.New(cfg.HubName(), command, audit.CurrentUser())
+	p.Args = args
+	cmd.LocalFlags().Visit(func(flag *pflag.Flag) {
+		if unplannedFlags[flag.Name] {
+			return
+		}
+		if p.Flags == nil {
+			p.Flags = make(map[string]string)
+		}
+		p.Flags[flag.Name] = flag.Value.String()
+	})
+	for _, cluster := range clusters {
+		description, err := describe(ctx, cluster)
+		if err != nil {
+			return fmt.Errorf("failed to plan %s: %w", cluster, err)
+		}
+		p.Add(cluster, description)
+	}
+
+	path := planPath(cmd)
+	if err := p.Save(path); err != nil {
+		return err
+	}
+	if path == "-" {
+		return nil
+	}
+	if err := p.WriteSummary(os.Stdout); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Plan saved to %s, review it and run labrat apply %s\n", path, path)
+	return nil
+}
+
+// describePowerState returns a describer of the power state changes of clusters
+func describePowerState(ctx context.Context, kubeClient *kube.Client, state string) (func(ctx context.Context, cluster string) (string, error), error) {
+	deployments, err := newHubBackend(kubeClient).ClusterDeployments().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	powerStates := make(map[string]string, len(deployments))
+	for _, deployment := range deployments {
+		powerStates[deployment.Name] = deployment.PowerState
+	}
+
+	return func(ctx context.Context, cluster string) (string, error) {
+		current, ok := powerStates[cluster]
+		switch {
+		case !ok:
+			return "no ClusterDeployment, will fail", nil
+		case current == state:
+			return "no change, already " + state, nil
+		default:
+			return fmt.Sprintf("powerState: %s → %s", current, state), nil
+		}
+	}, nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newPoolCmd creates the `pool` command group
+func newPoolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pool",
+		Short: "Claim lab clusters from Hive ClusterPools",
+		Long: `Claim lab clusters from Hive ClusterPools.
+
+ClusterPools keep a number of installed, hibernating clusters ready, so claiming one
+hands a cluster to a partner in minutes instead of a full install. Releasing a claim
+deletes its cluster, and the pool installs a replacement.`,
+	}
+	cmd.AddCommand(newPoolListCmd(), audited(newPoolClaimCmd()), audited(newPoolReleaseCmd()))
+	return cmd
+}
+
+// newPoolListCmd creates the `pool list` command
+func newPoolListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the ClusterPools or ClusterClaims of the hub",
+		Long: `List the ClusterPools of the hub with their size, the clusters ready to be claimed,
+the clusters still installing (standby), and the image set and platform they install.
+
+With --claims the ClusterClaims are listed instead, with the partner they were made for,
+the cluster assigned to them, and their status (Pending, Assigned, or Running).
+
+Examples:
+  # List the pools
+  labrat pool list
+
+  # List the claims as JSON
+  labrat pool list --claims -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			claims, _ := cmd.Flags().GetBool("claims")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			pools := hub.NewClusterPoolClient(kubeClient.GetDynamicClient(), clientOptions...)
+			if claims {
+				list, err := pools.ListClaims(ctx)
+				if err != nil {
+					return err
+				}
+				return writeClaims(outputFormat, list)
+			}
+
+			list, err := pools.List(ctx)
+			if err != nil {
+				return err
+			}
+			if written, err := writeListOutput(outputFormat, list); written {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "NAMESPACE\tNAME\tSIZE\tREADY\tSTANDBY\tMAX SIZE\tIMAGE SET\tPLATFORM\tREGION")
+			for _, pool := range list {
+				maxSize := "N/A"
+				if pool.MaxSize > 0 {
+					maxSize = fmt.Sprintf("%d", pool.MaxSize)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n", pool.Namespace, pool.Name, pool.Size, pool.Ready,
+					pool.Standby, maxSize, valueOrNA(pool.ImageSet), valueOrNA(pool.Platform), valueOrNA(pool.Region))
+			}
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	cmd.Flags().Bool("claims", false, "List the ClusterClaims instead of the ClusterPools")
+	return cmd
+}
+
+// writeClaims writes claims to stdout
+func writeClaims(outputFormat string, claims []hub.ClaimInfo) error {
+	if written, err := writeListOutput(outputFormat, claims); written {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tPOOL\tPARTNER\tCLUSTER\tSTATUS\tLIFETIME")
+	for _, claim := range claims {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", claim.Namespace, claim.Name, claim.Pool,
+			valueOrNA(claim.Partner), valueOrNA(claim.Cluster), claim.Status, valueOrNA(claim.Lifetime))
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// newPoolClaimCmd creates the `pool claim` command
+func newPoolClaimCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "claim <pool>",
+		Short: "Claim a cluster from a ClusterPool for a partner",
+		Long: `Create a ClusterClaim against a ClusterPool for a partner. Hive assigns a ready
+cluster of the pool to the claim and resumes it; follow the claim with
+'labrat pool list --claims' until it is Running.
+
+The pool is looked up by name in all namespaces unless --namespace is given. With
+--lifetime Hive deletes the claimed cluster once the lifetime has passed.
+
+The name of the claim is printed to stdout.
+
+Examples:
+  # Claim a cluster for a partner
+  labrat pool claim aws-small --for acme
+
+  # Claim a cluster that is deleted after two weeks
+  labrat pool claim aws-small --for acme --lifetime 2w`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			poolName := args[0]
+			partner, _ := cmd.Flags().GetString("for")
+			namespace, _ := cmd.Flags().GetString("namespace")
+			lifetimeValue, _ := cmd.Flags().GetString("lifetime")
+
+			var lifetime time.Duration
+			if lifetimeValue != "" {
+				var err error
+				lifetime, err = hub.ParseLeaseDuration(lifetimeValue)
+				if err != nil {
+					return fmt.Errorf("invalid --lifetime: %w", err)
+				}
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			claim, err := hub.NewClusterPoolClient(kubeClient.GetDynamicClient(), clientOptions...).Claim(cmd.Context(), hub.ClaimRequest{
+				Pool:      poolName,
+				Namespace: namespace,
+				Partner:   partner,
+				Lifetime:  lifetime,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "✓ Claimed a cluster from pool %s for %s: ClusterClaim %s/%s\n", poolName, partner, claim.Namespace, claim.Name)
+			fmt.Fprintln(os.Stdout, claim.Name)
+			return nil
+		},
+	}
+	cmd.Flags().String("for", "", "Partner the cluster is claimed for")
+	cmd.Flags().StringP("namespace", "n", "", "Namespace of the pool (default: look up the pool by name)")
+	cmd.Flags().String("lifetime", "", "Delete the claimed cluster after this duration, e.g. 14d, 2w, or 36h (default: unlimited)")
+	_ = cmd.MarkFlagRequired("for")
+	return cmd
+}
+
+// newPoolReleaseCmd creates the `pool release` command
+func newPoolReleaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release <claim>",
+		Short: "Release a ClusterClaim and delete its cluster",
+		Long: `Delete a ClusterClaim. Hive deletes the cluster assigned to the claim, and the pool
+installs a replacement.
+
+The claim is looked up by name in all namespaces unless --namespace is given.
+
+Examples:
+  # Release a claim
+  labrat pool release aws-small-acme-x7k2p`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			claimName := args[0]
+			namespace, _ := cmd.Flags().GetString("namespace")
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			if err := hub.NewClusterPoolClient(kubeClient.GetDynamicClient(), clientOptions...).Release(cmd.Context(), claimName, namespace); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "✓ Released ClusterClaim %s; Hive deletes its cluster\n", claimName)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("namespace", "n", "", "Namespace of the claim (default: look up the claim by name)")
+	return cmd
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/profile"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// timingVerbosity is the -v level at which the timing breakdown of a command run is printed
+const timingVerbosity = 5
+
+// timings records the phases and API requests of the command run
+var timings = profile.NewTimings()
+
+// finishProfiling writes the profiles and timings requested for the command run. It is
+// replaced by startProfiling and called after the command ran, whether it failed or not.
+var finishProfiling = func() {}
+
+// addProfilingFlags registers the hidden profiling flags on the root command
+func addProfilingFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.String("profile-cpu", "", "write a pprof CPU profile of the command run to this file")
+	flags.String("profile-mem", "", "write a pprof heap profile at the end of the command run to this file")
+	for _, name := range []string{"profile-cpu", "profile-mem"} {
+		if err := flags.MarkHidden(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error hiding flag: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// startProfiling starts the CPU profile and API request timing requested by the flags of cmd
+func startProfiling(cmd *cobra.Command) error {
+	cpuPath, _ := cmd.Flags().GetString("profile-cpu")
+	memPath, _ := cmd.Flags().GetString("profile-mem")
+	verbosity, _ := cmd.Flags().GetInt("verbose")
+
+	stopCPU := func() error { return nil }
+	if cpuPath != "" {
+		var err error
+		stopCPU, err = profile.StartCPU(cpuPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if verbosity >= timingVerbosity {
+		kube.SetTransportWrapper(timings.WrapTransport)
+	}
+
+	finishProfiling = func() {
+		if err := stopCPU(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if memPath != "" {
+			if err := profile.WriteHeap(memPath); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+		if verbosity >= timingVerbosity {
+			fmt.Fprintln(os.Stderr)
+			if err := timings.Write(os.Stderr); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+	return nil
+}
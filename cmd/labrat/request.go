@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newRequestCmd creates the `request` command group
+func newRequestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "request",
+		Short: "Look up clusters by partner request",
+	}
+	cmd.AddCommand(newRequestResolveCmd())
+	return cmd
+}
+
+// newRequestResolveCmd creates the `request resolve` command
+func newRequestResolveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resolve <request-id>",
+		Short: "Show the cluster provisioned for a partner request",
+		Long: `Resolve a partner request ID to the cluster provisioned for it, with its status
+and URLs.
+
+Clusters are found through the request index, a ConfigMap in the hub namespace
+that spoke create keeps up to date. If the index has no entry, ClusterDeployments
+are searched by their request-id label and the index is repaired.
+
+Examples:
+  # Show the cluster for request 1234
+  labrat request resolve 1234
+
+  # Show the cluster as JSON
+  labrat request resolve 1234 -o json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			requestID := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			backend := newHubBackend(kubeClient)
+			resolver := hub.NewRequestResolver(
+				hub.NewRequestIndex(kubeClient.GetCoreClient().CoreV1(), cfg.Hub.Namespace, clientOptions...),
+				backend.ClusterDeployments(),
+				backend.ManagedClusters(),
+			)
+			info, err := resolver.Resolve(cmd.Context(), requestID)
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(info, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "REQUEST\tCLUSTER\tSTATUS\tPOWER STATE\tAPI URL\tCONSOLE URL\n")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				info.RequestID, info.Cluster, info.Status, info.PowerState, info.APIURL, info.ConsoleURL)
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	return cmd
+}
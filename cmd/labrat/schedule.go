@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/schedule"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// scheduleTimeFormat is how schedule commands print when a schedule fires
+const scheduleTimeFormat = "Mon 2006-01-02 15:04 MST"
+
+// newScheduleCmd creates the `schedule` command group
+func newScheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Hibernate and resume clusters on a schedule",
+		Long: `Hibernate and resume clusters on a schedule, e.g. hibernate them on weeknights and
+weekends and resume them on weekday mornings in the time zone of the partner.
+
+A schedule is a pair of cron expressions recorded as annotations on the ClusterDeployment
+of a cluster. labrat schedule run enforces the schedules of every cluster and is meant to
+run periodically, e.g. from a CronJob on the hub.`,
+	}
+	cmd.AddCommand(newScheduleListCmd(), audited(newScheduleSetCmd()), audited(newScheduleClearCmd()), audited(newScheduleRunCmd()))
+	return cmd
+}
+
+// newScheduleListCmd creates the `schedule list` command
+func newScheduleListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the power schedules of the clusters",
+		Long: `List the clusters with a power schedule, their schedules and time zone, their power
+state, and the next scheduled action.
+
+Examples:
+  # List the schedules
+  labrat schedule list
+
+  # List the schedules as JSON
+  labrat schedule list -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			schedules, err := hub.NewScheduleClient(kubeClient.GetDynamicClient(), clientOptions...).List(cmd.Context())
+			if err := warnDecodeErrors(err); err != nil {
+				return err
+			}
+			if written, err := writeListOutput(outputFormat, schedules); written {
+				return err
+			}
+
+			if len(schedules) == 0 {
+				fmt.Fprintln(os.Stdout, "No cluster schedules found")
+				return nil
+			}
+			now := time.Now()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "CLUSTER\tHIBERNATE\tRESUME\tTIMEZONE\tPOWER STATE\tNEXT")
+			for _, s := range schedules {
+				next := "N/A"
+				if policy, err := schedule.NewPolicy(s.Hibernate, s.Resume, s.Timezone); err != nil {
+					next = "invalid: " + err.Error()
+				} else if state, at, ok := policy.Next(now); ok {
+					next = fmt.Sprintf("%s %s", scheduleAction(state), at.Format(scheduleTimeFormat))
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Cluster, valueOrNA(s.Hibernate), valueOrNA(s.Resume),
+					valueOrNA(s.Timezone), valueOrNA(s.PowerState), next)
+			}
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	return cmd
+}
+
+// newScheduleSetCmd creates the `schedule set` command
+func newScheduleSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <cluster-name>",
+		Short: "Set the power schedule of a cluster",
+		Long: `Set when a cluster is hibernated and resumed, replacing any existing schedule.
+
+--hibernate and --resume take five field cron expressions (minute hour day-of-month month
+day-of-week), evaluated in the IANA time zone given with --timezone, UTC by default.
+Either may be left out, e.g. to hibernate a cluster every night and let its users resume
+it when they need it.
+
+The schedule takes effect on the next labrat schedule run: if the cluster is not in the
+state of the schedule that fired last, it is hibernated or resumed. After that each
+schedule acts once when it fires, so a cluster resumed by hand in the evening stays
+running until the next hibernate time.
+
+Examples:
+  # Hibernate at 20:00 and resume at 08:00 on weekdays in Berlin, off on weekends
+  labrat schedule set my-cluster --hibernate '0 20 * * 1-5' --resume '0 8 * * 1-5' --timezone Europe/Berlin
+
+  # Hibernate every night and leave resuming to the partner
+  labrat schedule set my-cluster --hibernate '0 22 * * *' --timezone America/New_York`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			hibernate, _ := cmd.Flags().GetString("hibernate")
+			resume, _ := cmd.Flags().GetString("resume")
+			timezone, _ := cmd.Flags().GetString("timezone")
+
+			policy, err := schedule.NewPolicy(hibernate, resume, timezone)
+			if err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			schedules := hub.NewScheduleClient(kubeClient.GetDynamicClient(), clientOptions...)
+			if err := schedules.Set(cmd.Context(), clusterName, hibernate, resume, timezone); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Schedule of %s set\n", clusterName)
+			if state, at, ok := policy.Next(time.Now()); ok {
+				fmt.Fprintf(os.Stderr, "   Next: %s %s\n", scheduleAction(state), at.Format(scheduleTimeFormat))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("hibernate", "", "Cron expression of when to hibernate the cluster, e.g. '0 20 * * 1-5'")
+	cmd.Flags().String("resume", "", "Cron expression of when to resume the cluster, e.g. '0 8 * * 1-5'")
+	cmd.Flags().String("timezone", "", "IANA time zone of the schedules, e.g. Europe/Berlin (default UTC)")
+	return cmd
+}
+
+// newScheduleClearCmd creates the `schedule clear` command
+func newScheduleClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear <cluster-name>",
+		Short: "Remove the power schedule of a cluster",
+		Long: `Remove the power schedule of a cluster. Its power state is left as it is.
+
+Examples:
+  # Stop hibernating a cluster on a schedule
+  labrat schedule clear my-cluster`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			schedules := hub.NewScheduleClient(kubeClient.GetDynamicClient(), clientOptions...)
+			if err := schedules.Clear(cmd.Context(), clusterName); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Schedule of %s cleared\n", clusterName)
+			return nil
+		},
+	}
+}
+
+// newScheduleRunCmd creates the `schedule run` command
+func newScheduleRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Hibernate and resume the clusters whose schedule is due",
+		Long: `Enforce the power schedules of all clusters once: every cluster whose hibernate or
+resume schedule fired since it was last enforced is hibernated or resumed, unless it is
+already in that state. When the schedule was enforced is recorded on the
+ClusterDeployment, so runs that were missed are caught up and clusters changed by hand
+in between are left alone until their next schedule fires. Clusters that are not
+installed yet are skipped.
+
+Run it periodically, e.g. from a CronJob on the hub every 5 to 15 minutes; schedules act
+up to one period late. Clusters whose schedule is invalid or cannot be enforced are
+reported and the command exits non-zero after the others are done.
+
+Examples:
+  # Show what would be hibernated or resumed now
+  labrat schedule run --dry-run
+
+  # Enforce the schedules
+  labrat schedule run`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			scheduleClient := hub.NewScheduleClient(kubeClient.GetDynamicClient(), clientOptions...)
+			power := spoke.NewPowerManager(kubeClient.GetDynamicClient(), clientOptions...)
+			schedules, err := scheduleClient.List(ctx)
+			if err := warnDecodeErrors(err); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			var changed, failed, planned []string
+			for _, s := range schedules {
+				policy, err := schedule.NewPolicy(s.Hibernate, s.Resume, s.Timezone)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Invalid schedule of %s: %v\n", s.Cluster, err)
+					failed = append(failed, s.Cluster)
+					continue
+				}
+				if !s.Installed {
+					continue
+				}
+				state, firedAt, due := policy.Due(now, s.AppliedAt)
+				if !due {
+					continue
+				}
+
+				fired := firedAt.Format(scheduleTimeFormat)
+				if dryRun {
+					if s.PowerState != state {
+						planned = append(planned, s.Cluster)
+						fmt.Fprintf(os.Stdout, "Would %s %s, scheduled %s\n", strings.ToLower(scheduleAction(state)), s.Cluster, fired)
+					}
+					continue
+				}
+				if s.PowerState != state {
+					if err := power.SetPowerState(ctx, s.Cluster, state); err != nil {
+						fmt.Fprintf(os.Stderr, "⚠️  Failed to %s %s: %v\n", strings.ToLower(scheduleAction(state)), s.Cluster, err)
+						failed = append(failed, s.Cluster)
+						continue
+					}
+					changed = append(changed, s.Cluster)
+					fmt.Fprintf(os.Stderr, "✓ %s %s, scheduled %s\n", scheduleDone(state), s.Cluster, fired)
+				}
+				if err := scheduleClient.MarkApplied(ctx, s.Cluster, firedAt); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+					failed = append(failed, s.Cluster)
+				}
+			}
+			auditTargets = changed
+			if dryRun && len(planned) == 0 {
+				fmt.Fprintln(os.Stdout, "No clusters are due to be hibernated or resumed")
+			}
+
+			if len(failed) > 0 {
+				return fmt.Errorf("failed to enforce the schedules of %s", strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "Only show which clusters would be hibernated or resumed")
+	return cmd
+}
+
+// scheduleAction names the action that brings a cluster to state
+func scheduleAction(state string) string {
+	if state == spoke.PowerStateHibernating {
+		return "Hibernate"
+	}
+	return "Resume"
+}
+
+// scheduleDone names the action that brought a cluster to state in the past tense
+func scheduleDone(state string) string {
+	return scheduleAction(state) + "d"
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/server"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// serveShutdownTimeout is how long in-flight requests may take to finish after an interrupt
+const serveShutdownTimeout = 10 * time.Second
+
+// newServeCmd creates the `serve` command
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the labrat HTTP API",
+		Long: `Serve an HTTP API exposing the hub data labrat gathers to other Partner Labs tooling,
+such as the web portal. Requests are authenticated with the bearer tokens and OIDC
+provider of serve.auth in the config, and each role may only call its routes:
+
+  GET  /api/v1/clusters                    viewer     List clusters (?status=Ready)
+  GET  /api/v1/clusters/<name>             viewer     Cluster details, as spoke status -o json
+  GET  /api/v1/clusters/<name>/kubeconfig  operator   Admin kubeconfig of the cluster
+  POST /api/v1/clusters/<name>/hibernate   operator   Hibernate the cluster
+  POST /api/v1/clusters/<name>/resume      operator   Resume the cluster
+  GET  /api/v1/events                      viewer     Server-sent cluster lifecycle events
+  GET  /metrics                            viewer     Prometheus metrics of the clusters and API
+  GET  /healthz                            -          Liveness check, no token needed
+
+The clusters, ClusterDeployments, and ManagedClusterInfos of the hub are listed once at
+startup and then kept current by watches, so cluster routes, events, and metrics are
+answered from memory; every --resync the cached objects are compared again.
+
+While it runs, cluster lifecycle events are also sent to the Slack channel and webhook
+configured under notify.
+
+The server listens on serve.address (default :8080), with HTTPS when serve.tlsCertFile
+and serve.tlsKeyFile are set, until it is interrupted.
+
+Examples:
+  # Serve the API of the active hub
+  labrat serve
+
+  # Listen on another address
+  labrat serve --address 127.0.0.1:9090
+
+  # List the clusters through the API
+  curl -H "Authorization: Bearer $TOKEN" http://localhost:8080/api/v1/clusters`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			interval, _ := cmd.Flags().GetDuration("event-interval")
+			metricsInterval, _ := cmd.Flags().GetDuration("metrics-interval")
+			resync, _ := cmd.Flags().GetDuration("resync")
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+			address := cfg.Serve.Address
+			if cmd.Flags().Changed("address") || address == "" {
+				address, _ = cmd.Flags().GetString("address")
+			}
+			if (cfg.Serve.TLSCertFile == "") != (cfg.Serve.TLSKeyFile == "") {
+				return fmt.Errorf("serve.tlsCertFile and serve.tlsKeyFile must be set together")
+			}
+
+			ctx := cmd.Context()
+
+			auth, err := server.NewAuthenticator(ctx, cfg.Serve.Auth)
+			if err != nil {
+				return err
+			}
+
+			informerCache, err := startHubCache(ctx, kubeClient, resync, true)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "✓ Hub cache synced")
+
+			dynamicClient := kubeClient.GetDynamicClient()
+			hubData := hub.NewInformerBackend(dynamicClient, informerCache, clientOptions...)
+			mcClient := hubData.ManagedClusters()
+			cdClient := hubData.ClusterDeployments()
+			backend := server.Backend{
+				Clusters:    hubData.Clusters(),
+				Status:      spoke.NewStatusReader(dynamicClient, kubeClient.GetCoreClient(), clientOptions...),
+				Kubeconfigs: spoke.NewKubeconfigExtractor(dynamicClient, kubeClient.GetCoreClient().CoreV1(), clientOptions...),
+				Power:       spoke.NewPowerManager(dynamicClient, clientOptions...),
+				Audit:       auditLog,
+			}
+
+			broker := server.NewBroker()
+			notifyEvents(ctx, cfg, broker)
+			defer watchStatusHistory(ctx, cfg, cfg.HubName(), mcClient)()
+			go server.NewEventSource(mcClient, cdClient, broker).Run(ctx, interval)
+			metrics := server.NewMetrics(mcClient, cdClient)
+			go metrics.Run(ctx, metricsInterval)
+
+			httpServer := &http.Server{
+				Addr:              address,
+				Handler:           server.NewHandler(backend, auth, broker, metrics, logger),
+				ReadHeaderTimeout: 10 * time.Second,
+				// Requests, including open event streams, are canceled on interrupt
+				BaseContext: func(net.Listener) context.Context { return ctx },
+			}
+
+			scheme := "http"
+			if cfg.Serve.TLSCertFile != "" {
+				scheme = "https"
+			}
+			fmt.Fprintf(os.Stderr, "🚀 Serving the labrat API on %s://%s\n", scheme, address)
+
+			serveErr := make(chan error, 1)
+			go func() {
+				if scheme == "https" {
+					serveErr <- httpServer.ListenAndServeTLS(cfg.Serve.TLSCertFile, cfg.Serve.TLSKeyFile)
+				} else {
+					serveErr <- httpServer.ListenAndServe()
+				}
+			}()
+
+			select {
+			case err := <-serveErr:
+				return fmt.Errorf("failed to serve the API: %w", err)
+			case <-ctx.Done():
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+			defer cancel()
+			if err := httpServer.Shutdown(shutdownCtx); err != nil {
+				return fmt.Errorf("failed to stop the API server: %w", err)
+			}
+			fmt.Fprintln(os.Stderr, "✓ API server stopped")
+			return nil
+		},
+	}
+	cmd.Flags().String("address", server.DefaultAddress, "Address to listen on; overrides serve.address of the config")
+	cmd.Flags().Duration("event-interval", server.DefaultEventPollInterval, "How often the hub is polled for cluster lifecycle events")
+	cmd.Flags().Duration("metrics-interval", server.DefaultMetricsPollInterval, "How often the hub is polled for the cluster metrics")
+	cmd.Flags().Duration("resync", kube.DefaultResyncPeriod, "How often the cached hub objects are redelivered to catch missed changes (0 disables)")
+	return cmd
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// addOnStatusDisabled is the status listed for add-ons installed on the hub but not enabled on the cluster
+const addOnStatusDisabled = "Disabled"
+
+// newSpokeAddOnsCmd creates the `spoke addons` command
+func newSpokeAddOnsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "addons",
+		Short: "List and enable the ACM add-ons of spoke clusters",
+	}
+	cmd.AddCommand(newSpokeAddOnsListCmd(), audited(newSpokeAddOnsEnableCmd()))
+	return cmd
+}
+
+// newSpokeAddOnsListCmd creates the `spoke addons list` command
+func newSpokeAddOnsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list <cluster-name>",
+		Short: "List the ACM add-ons of a spoke cluster",
+		Long: `List the ManagedClusterAddOns in the namespace of a spoke cluster on the hub,
+with the status reported by their agents on the spoke.
+
+With --all the add-ons installed on the hub (ClusterManagementAddOns) that are not
+enabled on the cluster are listed too, with the status Disabled.
+
+Examples:
+  # List the enabled add-ons
+  labrat spoke addons list my-cluster
+
+  # Also list the add-ons that can be enabled
+  labrat spoke addons list my-cluster --all`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+			all, _ := cmd.Flags().GetBool("all")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			addons := hub.NewManagedClusterAddOnClient(kubeClient.GetDynamicClient(), clientOptions...)
+			list, err := addons.List(ctx, clusterName)
+			if err != nil {
+				return err
+			}
+
+			if all {
+				available, err := addons.Available(ctx)
+				if err != nil {
+					return err
+				}
+				enabled := make(map[string]bool, len(list))
+				for _, addon := range list {
+					enabled[addon.Name] = true
+				}
+				for _, name := range available {
+					if !enabled[name] {
+						list = append(list, hub.AddOnInfo{Name: name, Status: addOnStatusDisabled})
+					}
+				}
+			}
+
+			if written, err := writeListOutput(outputFormat, list); written {
+				return err
+			}
+
+			if len(list) == 0 {
+				fmt.Fprintf(os.Stdout, "No add-ons enabled on %s\n", clusterName)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "ADDON\tSTATUS\tINSTALL NAMESPACE\tMESSAGE\n")
+			for _, addon := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", addon.Name, addon.Status, valueOrNA(addon.InstallNamespace), addon.Message)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	cmd.Flags().Bool("all", false, "Also list the add-ons installed on the hub that are not enabled")
+	return cmd
+}
+
+// newSpokeAddOnsEnableCmd creates the `spoke addons enable` command
+func newSpokeAddOnsEnableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable <cluster-name> <addon>",
+		Short: "Enable an ACM add-on on a spoke cluster",
+		Long: `Enable an ACM add-on on a spoke cluster by creating its ManagedClusterAddOn in the
+cluster namespace on the hub. The add-on manager then deploys the add-on agent to the
+spoke. The add-on must be installed on the hub; run labrat spoke addons list --all to
+see which ones are.
+
+Common add-ons are search-collector (search), observability-controller (observability),
+and governance-policy-framework and config-policy-controller (policies).
+
+Examples:
+  # Enable search on a cluster
+  labrat spoke addons enable my-cluster search-collector`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName, addon := args[0], args[1]
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			created, err := hub.NewManagedClusterAddOnClient(kubeClient.GetDynamicClient(), clientOptions...).Enable(cmd.Context(), clusterName, addon)
+			if err != nil {
+				return err
+			}
+			if !created {
+				fmt.Fprintf(os.Stderr, "✓ Add-on %s is already enabled on %s\n", addon, clusterName)
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "✓ Add-on %s enabled on %s\n", addon, clusterName)
+			fmt.Fprintf(os.Stderr, "  Track the rollout with: labrat spoke addons list %s\n", clusterName)
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// cloudConsoleLink is the JSON representation of a cloud console deep link
+type cloudConsoleLink struct {
+	Name     string `json:"name"`
+	Platform string `json:"platform"`
+	Region   string `json:"region"`
+	InfraID  string `json:"infraID"`
+	URL      string `json:"url"`
+}
+
+// newSpokeCloudConsoleCmd creates the `spoke cloud-console` command
+func newSpokeCloudConsoleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cloud-console <cluster-name>",
+		Short: "Print a link to the cluster's resources in the cloud provider console",
+		Long: `Build a deep link to the cloud provider console, filtered by the cluster's
+infrastructure ID, so its instances and other resources can be inspected directly.
+
+AWS links are built from the ClusterDeployment alone. Azure links need the
+subscription and GCP links need the project that hosts the cluster.
+
+Examples:
+  # Print the EC2 console link for an AWS cluster
+  labrat spoke cloud-console my-cluster
+
+  # Print the resource group link for an Azure cluster
+  labrat spoke cloud-console my-cluster --azure-subscription 00000000-0000-0000-0000-000000000000
+
+  # Print the link as JSON
+  labrat spoke cloud-console my-cluster -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+			azureSubscription, _ := cmd.Flags().GetString("azure-subscription")
+			gcpProject, _ := cmd.Flags().GetString("gcp-project")
+
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			cdClient := newHubBackend(kubeClient).ClusterDeployments()
+			cd, err := cdClient.Get(cmd.Context(), clusterName)
+			if err != nil {
+				return fmt.Errorf("failed to get ClusterDeployment: %w", err)
+			}
+
+			url, err := cd.CloudConsoleURL(hub.CloudConsoleOptions{
+				AzureSubscriptionID: azureSubscription,
+				GCPProjectID:        gcpProject,
+			})
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(cloudConsoleLink{
+					Name:     cd.Name,
+					Platform: cd.Platform,
+					Region:   cd.Region,
+					InfraID:  cd.InfraID,
+					URL:      url,
+				}, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+				return nil
+			}
+
+			fmt.Fprintln(os.Stdout, url)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "text", "Output format (text|json)")
+	cmd.Flags().String("azure-subscription", "", "Azure subscription ID hosting the cluster (required for Azure)")
+	cmd.Flags().String("gcp-project", "", "GCP project ID hosting the cluster (required for GCP)")
+	return cmd
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// complianceReportRow is the compliance summary of one spoke, or the error that prevented reading it
+type complianceReportRow struct {
+	Cluster string `json:"cluster"`
+	*spoke.ComplianceSummary
+	Error string `json:"error,omitempty"`
+}
+
+// newSpokeComplianceCmd creates the `spoke compliance` command
+func newSpokeComplianceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compliance",
+		Short: "Run Compliance Operator scans on spoke clusters",
+	}
+	cmd.AddCommand(audited(newSpokeComplianceScanCmd()))
+	return cmd
+}
+
+// newSpokeComplianceScanCmd creates the `spoke compliance scan` command
+func newSpokeComplianceScanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan <cluster-name>",
+		Short: "Scan a spoke cluster with a compliance profile",
+		Long: `Scan a spoke cluster with a Compliance Operator profile and print a summary of
+the results.
+
+The Compliance Operator is installed on the spoke if needed, and the profile is bound
+to the default ScanSetting with the ScanSettingBinding labrat-<profile>. If the profile
+was scanned before, the scan is run again. Short profile names are expanded to
+platform profiles (cis becomes ocp4-cis).
+
+By default the command waits for the scan to finish, which takes several minutes, and
+lists the failed checks of high severity. Use labrat hub compliance report to compare
+the latest results across the fleet.
+
+Examples:
+  # Run a CIS scan and wait for the results
+  labrat spoke compliance scan my-cluster --profile cis
+
+  # Start a scan of the node profile without waiting
+  labrat spoke compliance scan my-cluster --profile ocp4-cis-node --wait=false`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			profile, _ := cmd.Flags().GetString("profile")
+			waitForScan, _ := cmd.Flags().GetBool("wait")
+			timeout := waitTimeout(cmd)
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+			profile = spoke.ComplianceProfile(profile)
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			spokeClient, err := newSpokeClient(ctx, kubeClient, clusterName)
+			if err != nil {
+				return err
+			}
+
+			scanner := spoke.NewComplianceScanner(spokeClient.GetDynamicClient(), spoke.ComplianceOptions{Timeout: timeout}, clientOptions...)
+			started := time.Now()
+			if err := scanner.Scan(ctx, profile); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "🔍 Scan of %s started on %s\n", profile, clusterName)
+
+			if !waitForScan {
+				return nil
+			}
+
+			summary, err := scanner.Wait(ctx, profile, started)
+			if err != nil {
+				return err
+			}
+			return writeComplianceReport([]complianceReportRow{{Cluster: clusterName, ComplianceSummary: summary}}, outputFormat)
+		},
+	}
+	cmd.Flags().String("profile", "cis", "Compliance profile to scan with (e.g. cis, ocp4-cis-node, moderate)")
+	cmd.Flags().Bool("wait", true, "Wait for the scan to finish and print the results")
+	addWaitTimeoutFlag(cmd, spoke.DefaultComplianceTimeout, "Maximum time to wait for the operator install and the scan")
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	return cmd
+}
+
+// writeComplianceReport prints compliance summaries as a table or JSON. The table lists the
+// failed checks of high severity below the summary of a single cluster.
+func writeComplianceReport(rows []complianceReportRow, outputFormat string) error {
+	if outputFormat == "json" {
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+		return nil
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintln(os.Stdout, "No clusters to report")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "CLUSTER\tPROFILE\tPHASE\tRESULT\tPASS\tFAIL\tMANUAL\tERRORS\tFAILED HIGH\tERROR\n")
+	for _, r := range rows {
+		if r.ComplianceSummary == nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t-\t-\t-\t%s\n", r.Cluster, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t\n", r.Cluster, r.Profile, r.Phase, r.Result,
+			r.Pass, r.Fail, r.Manual, r.Errors, len(r.FailedHigh))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(rows) == 1 && rows[0].ComplianceSummary != nil && len(rows[0].FailedHigh) > 0 {
+		fmt.Fprintf(os.Stdout, "\nFailed checks of high severity:\n  %s\n", strings.Join(rows[0].FailedHigh, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// consoleLink is the JSON representation of the web console of a cluster
+type consoleLink struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// newSpokeConsoleCmd creates the `spoke console` command
+func newSpokeConsoleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "console <cluster-name>",
+		Short: "Print or open the OpenShift web console of a spoke cluster",
+		Long: `Print the OpenShift web console URL of a spoke cluster, as reported by its
+ClusterDeployment.
+
+With --open the console is opened in the default browser. With --password the
+kubeadmin credentials are read from the cluster's admin password secret on the hub
+and printed to stderr (or included in the JSON output), ready to log in with.
+
+Examples:
+  # Print the console URL
+  labrat spoke console my-cluster
+
+  # Open the console and print the kubeadmin credentials
+  labrat spoke console my-cluster --open --password`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+			open, _ := cmd.Flags().GetBool("open")
+			showPassword, _ := cmd.Flags().GetBool("password")
+
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			cd, err := newHubBackend(kubeClient).ClusterDeployments().Get(ctx, clusterName)
+			if err != nil {
+				return fmt.Errorf("failed to get ClusterDeployment: %w", err)
+			}
+			if cd.ConsoleURL == "" {
+				return fmt.Errorf("cluster %s has no console URL yet (installed: %t)", clusterName, cd.Installed)
+			}
+
+			link := consoleLink{Name: cd.Name, URL: cd.ConsoleURL}
+			if showPassword {
+				creds, err := spoke.NewKubeadminPasswordExtractor(
+					kubeClient.GetDynamicClient(),
+					kubeClient.GetCoreClient().CoreV1(),
+					clientOptions...,
+				).Extract(ctx, clusterName)
+				if err != nil {
+					return err
+				}
+				link.Username, link.Password = creds.Username, creds.Password
+			}
+
+			if open {
+				if err := openBrowser(link.URL); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "✓ Opened %s in the default browser\n", link.URL)
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(link, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+				return nil
+			}
+
+			if !open {
+				fmt.Fprintln(os.Stdout, link.URL)
+			}
+			if showPassword {
+				fmt.Fprintf(os.Stderr, "\n⚠️  WARNING: These are the cluster-admin kubeadmin credentials!\n")
+				fmt.Fprintf(os.Stderr, "  Username: %s\n", link.Username)
+				fmt.Fprintf(os.Stderr, "  Password: %s\n", link.Password)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "text", "Output format (text|json)")
+	cmd.Flags().Bool("open", false, "Open the console in the default browser")
+	cmd.Flags().Bool("password", false, "Also print the kubeadmin credentials")
+	return cmd
+}
+
+// openBrowser opens url in the default browser of the platform
+func openBrowser(url string) error {
+	var browser *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		browser = exec.Command("open", url)
+	case "windows":
+		browser = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		browser = exec.Command("xdg-open", url)
+	}
+	if err := browser.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	return nil
+}
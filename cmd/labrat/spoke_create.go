@@ -0,0 +1,651 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/snippet"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/template"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/registry"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke/waiter"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/sshkey"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// newSpokeCreateCmd creates the `spoke create` command
+func newSpokeCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Provision a new partner cluster",
+		Long: `Provision a new partner cluster for a partner request through Hive.
+
+The cluster is created in a namespace of the same name on the hub, with:
+
+  - a copy of the cloud credential secret named by --credentials, or by the
+    credentials of the provider in defaults.spoke
+  - the pull secret stored with the credentials (or the hub's global pull secret)
+  - the node SSH key stored with the credentials (or the key file given with --ssh-key)
+  - an install-config rendered from the flags, with the provider and region defaulting
+    to defaults.spoke in the config and the platform settings (instance types, network,
+    Azure resource groups, GCP project, vSphere placement and VIPs) taken from the
+    section of the provider in defaults.spoke
+  - a ClusterDeployment labeled with the request ID, and a ManagedCluster so ACM imports
+    the cluster once Hive has installed it
+
+Creation is idempotent per request: if a ClusterDeployment labeled with the request
+ID already exists, it is reported and reused instead of provisioning a duplicate,
+so retries from the portal or automation are safe. The resources an interrupted run
+did not create, such as the ManagedCluster or the worker MachinePool, are created;
+existing ones are left unchanged and the preflights are skipped.
+
+Before anything is applied, preflight checks confirm that the target account can
+host the cluster: the requested instance types must be offered in the region's (or
+the requested) availability zones, on AWS the region's vCPU, Elastic IP, and VPC
+quotas must cover the requested footprint, and the base domain must have a public
+DNS zone (Route 53, Azure DNS, or Cloud DNS) in which the cluster's api and *.apps
+records do not exist yet. The release image of the requested ClusterImageSet must
+also be pullable with the pull secret. Preflights can be skipped with --skip-preflight.
+
+With --template the ClusterDeployment and the other manifests are rendered from a
+cluster template, a Go template of multi-document YAML in ~/.labrat/templates, with
+variables from defaults.spoke.values in the config and --set key=value. The namespace
+and the secrets holding the credentials, pull secret, and SSH key are still rendered by
+labrat; templates reference them by name.
+
+With --snippet the install-config is extended with install-config snippets: YAML
+fragments in ~/.labrat/snippets, e.g. fips.yaml with "fips: true", merged in after the
+snippets of defaults.spoke.snippets. Mappings are merged field by field and other values
+replace those rendered from the flags; snippets setting the same field to different
+values conflict and nothing is applied.
+
+With --preset the cluster gets an additional autoscaled worker MachinePool per preset,
+e.g. GPU workers for OpenShift AI. The preset decides the instance type on each provider,
+the autoscaling bounds, and the labels and taints of the nodes; the nodes of the GPU
+presets are labeled node-role.kubernetes.io/gpu and tainted nvidia.com/gpu:NoSchedule,
+so only workloads tolerating the taint are scheduled on them. Presets:
+
+  gpu-small    1 NVIDIA GPU per node, 1 to 3 nodes
+  gpu-medium   4 NVIDIA GPUs per node, 1 to 2 nodes
+  gpu-a100     NVIDIA A100 GPUs (8 per node on AWS, 1 on Azure and GCP), 1 to 2 nodes
+
+With --wait the command follows the install until the cluster is installed, showing
+the current phase, install attempt, and stage of the Hive provision. If Hive reports
+the install failed, the last lines of the installer log are printed and the command
+exits non-zero; Hive may still retry the install, which can be followed with
+'labrat spoke status'.
+
+With --dry-run nothing is applied: the manifests that would be applied are printed to
+stdout as YAML, or written to one file each in --output-dir, with the values of the
+secrets redacted and the install-config decoded for review.
+
+Examples:
+  # Provision a cluster for a request with the default footprint
+  labrat spoke create --request-id 1234 --credentials aws-partner-lab --imageset img4.16.12-x86-64-appsub
+
+  # Provision larger compute nodes in a specific region
+  labrat spoke create --request-id 1234 --credentials aws-partner-lab --imageset img4.16.12-x86-64-appsub \
+    --region eu-west-1 --compute-type m6i.2xlarge --compute-replicas 5
+
+  # Provision a cluster and wait until it is installed
+  labrat spoke create --request-id 1234 --credentials aws-partner-lab --imageset img4.16.12-x86-64-appsub --wait
+
+  # Review the manifests without touching the hub
+  labrat spoke create --request-id 1234 --credentials aws-partner-lab --imageset img4.16.12-x86-64-appsub \
+    --dry-run --output-dir ./manifests
+
+  # Render the cluster from a template with variables
+  labrat spoke create --request-id 1234 --credentials aws-partner-lab --imageset img4.16.12-x86-64-appsub \
+    --template gpu-workers --set gpuType=g5.2xlarge --set gpuReplicas=2
+
+  # Provision a FIPS cluster behind the proxy of the partner
+  labrat spoke create --request-id 1234 --credentials aws-partner-lab --imageset img4.16.12-x86-64-appsub \
+    --snippet fips --snippet proxy-acme
+
+  # Provision a cluster with single-GPU workers for OpenShift AI
+  labrat spoke create --request-id 1234 --credentials aws-partner-lab --imageset img4.16.12-x86-64-appsub \
+    --preset gpu-small
+
+  # Provision on vSphere with the credentials and VIPs of defaults.spoke.vsphere
+  labrat spoke create --request-id 1234 --provider vsphere --imageset img4.16.12-x86-64-appsub
+
+  # Restrict the cluster to two availability zones
+  labrat spoke create --request-id 1234 --credentials aws-partner-lab --imageset img4.16.12-x86-64-appsub \
+    --zones us-east-2a,us-east-2b`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			requestID, _ := cmd.Flags().GetString("request-id")
+			name, _ := cmd.Flags().GetString("name")
+			skipPreflight, _ := cmd.Flags().GetBool("skip-preflight")
+			outputFormat, _ := cmd.Flags().GetString("output")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			outputDir, _ := cmd.Flags().GetString("output-dir")
+			waitForInstall, _ := cmd.Flags().GetBool("wait")
+			timeout := waitTimeout(cmd)
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+			if outputDir != "" && !dryRun {
+				return fmt.Errorf("--output-dir requires --dry-run")
+			}
+			if waitForInstall && dryRun {
+				return fmt.Errorf("--wait cannot be combined with --dry-run")
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			requestIndex := hub.NewRequestIndex(kubeClient.GetCoreClient().CoreV1(), cfg.Hub.Namespace, clientOptions...)
+			existing, err := newHubBackend(kubeClient).ClusterDeployments().FindByRequestID(ctx, requestID)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				if name != "" && name != existing.Name {
+					return fmt.Errorf("request %s is already provisioned as cluster %s, not %s", requestID, existing.Name, name)
+				}
+				if dryRun {
+					fmt.Fprintf(os.Stderr, "♻️  Request %s is already provisioned as cluster %s, nothing would be applied\n", requestID, existing.Name)
+					return nil
+				}
+			}
+
+			spec, err := spokeProvisionSpec(ctx, cmd, cfg, kubeClient)
+			if err != nil {
+				return err
+			}
+			installed := false
+			if existing != nil {
+				spec.Name = existing.Name
+				installed = existing.Installed
+			}
+
+			// The preflights expect a cluster that does not exist yet, e.g. no DNS records
+			if !skipPreflight && existing == nil {
+				if err := runSpokePreflight(ctx, kubeClient, spec); err != nil {
+					return err
+				}
+			}
+
+			provisioner := spoke.NewProvisioner(kubeClient.GetDynamicClient(), clientOptions...)
+			if dryRun {
+				objects, err := provisioner.Render(ctx, *spec)
+				if err != nil {
+					return err
+				}
+				return writeManifests(spoke.RedactSecrets(objects), outputDir)
+			}
+
+			if existing != nil {
+				fmt.Fprintf(os.Stderr, "♻️  Request %s is already provisioned as cluster %s (installed: %t, power state: %s), creating any resources it is missing\n",
+					requestID, existing.Name, existing.Installed, existing.PowerState)
+			} else {
+				fmt.Fprintf(os.Stderr, "🚀 Provisioning cluster %s for request %s\n", spec.Name, requestID)
+			}
+			resources, err := provisioner.Provision(ctx, *spec)
+			if err != nil {
+				return err
+			}
+			if err := requestIndex.Record(ctx, requestID, spec.Name); err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(resources, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+			} else {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+				fmt.Fprintf(w, "KIND\tNAMESPACE\tNAME\tRESULT\n")
+				for _, r := range resources {
+					result := "created"
+					if !r.Created {
+						result = "unchanged (already exists)"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Kind, valueOrNA(r.Namespace), r.Name, result)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+				if !waitForInstall && !installed {
+					fmt.Fprintf(os.Stdout, "\nHive is installing %s; follow progress with: labrat spoke status %s\n", spec.Name, spec.Name)
+				}
+			}
+
+			if waitForInstall && !installed {
+				return waitForSpokeInstall(ctx, kubeClient, spec.Name, timeout)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("request-id", "", "ID of the partner request (Required)")
+	cmd.Flags().String("name", "", "Cluster name (defaults to the request ID)")
+	cmd.Flags().String("base-domain", "", "Base DNS domain of the cluster (defaults to defaults.spoke.<provider>.baseDomain, then baseDomain in the credential secret)")
+	cmd.Flags().String("imageset", "", "ClusterImageSet providing the OpenShift release to install (Required)")
+	cmd.Flags().String("credentials", "", "Cloud credential secret used to provision the cluster (defaults to defaults.spoke.<provider>.credentials)")
+	cmd.Flags().String("credentials-namespace", "", "Namespace of the credential secret (defaults to defaults.spoke.<provider>.credentialsNamespace, then the hub namespace)")
+	cmd.Flags().String("provider", "", "Cloud provider of the cluster: aws, azure, gcp, or vsphere (defaults to defaults.spoke.provider)")
+	cmd.Flags().String("region", "", "Region to provision into (defaults to defaults.spoke.<provider>.region, then defaults.spoke.region)")
+	cmd.Flags().StringSlice("zones", nil, "Availability zones to provision into (defaults to every zone in the region)")
+	cmd.Flags().String("control-plane-type", "", "Control plane instance type (defaults to defaults.spoke.<provider>.controlPlaneType, then the installer default)")
+	cmd.Flags().String("compute-type", "", "Compute instance type (defaults to defaults.spoke.<provider>.computeType, then the installer default)")
+	cmd.Flags().Int("compute-replicas", cloud.DefaultComputeReplicas, "Number of compute nodes")
+	cmd.Flags().String("ssh-key", "", "Private SSH key file for the nodes; the public key is read from <file>.pub or derived from the key (defaults to the key in the credential secret)")
+	cmd.Flags().String("template", "", "Cluster template rendering the manifests, by name in ~/.labrat/templates or as a file path (defaults to defaults.spoke.template)")
+	cmd.Flags().StringArray("set", nil, "Template variable as key=value, overriding defaults.spoke.values (repeatable)")
+	cmd.Flags().StringArray("snippet", nil, "Install-config snippet to merge, by name in ~/.labrat/snippets or as a file path, after defaults.spoke.snippets (repeatable)")
+	cmd.Flags().StringArray("preset", nil, "Machine pool preset to add as an autoscaled worker pool: "+strings.Join(spoke.MachinePoolPresetNames(), ", ")+" (repeatable)")
+	cmd.Flags().Bool("skip-preflight", false, "Skip cloud account preflight checks")
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().Bool("dry-run", false, "Print the manifests that would be applied instead of applying them")
+	cmd.Flags().String("output-dir", "", "With --dry-run, write one YAML file per manifest to this directory instead of stdout")
+	cmd.Flags().Bool("wait", false, "Wait for Hive to install the cluster, showing the progress of the install")
+	addWaitTimeoutFlag(cmd, waiter.DefaultTimeout, "Maximum time to wait for the install with --wait")
+	if err := cmd.MarkFlagRequired("request-id"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error marking flag required: %v\n", err)
+		os.Exit(1)
+	}
+	return cmd
+}
+
+// spokeProvisionSpec resolves the flags of `spoke create` against the config defaults and the
+// credential secret into the spec of the cluster to provision
+func spokeProvisionSpec(ctx context.Context, cmd *cobra.Command, cfg *config.Config, kubeClient *kube.Client) (*spoke.ProvisionSpec, error) {
+	requestID, _ := cmd.Flags().GetString("request-id")
+	name, _ := cmd.Flags().GetString("name")
+	baseDomain, _ := cmd.Flags().GetString("base-domain")
+	imageSet, _ := cmd.Flags().GetString("imageset")
+	credentialsName, _ := cmd.Flags().GetString("credentials")
+	credentialsNamespace, _ := cmd.Flags().GetString("credentials-namespace")
+	provider, _ := cmd.Flags().GetString("provider")
+	region, _ := cmd.Flags().GetString("region")
+	zones, _ := cmd.Flags().GetStringSlice("zones")
+	controlPlaneType, _ := cmd.Flags().GetString("control-plane-type")
+	computeType, _ := cmd.Flags().GetString("compute-type")
+	computeReplicas, _ := cmd.Flags().GetInt("compute-replicas")
+	sshKeyPath, _ := cmd.Flags().GetString("ssh-key")
+	templateName, _ := cmd.Flags().GetString("template")
+	setValues, _ := cmd.Flags().GetStringArray("set")
+	snippetNames, _ := cmd.Flags().GetStringArray("snippet")
+	presetNames, _ := cmd.Flags().GetStringArray("preset")
+
+	if imageSet == "" {
+		return nil, fmt.Errorf("--imageset is required")
+	}
+	if computeReplicas < 0 {
+		return nil, fmt.Errorf("--compute-replicas must not be negative, got %d", computeReplicas)
+	}
+
+	if templateName == "" {
+		templateName = cfg.Defaults.Spoke.Template
+	}
+	if len(setValues) > 0 && templateName == "" {
+		return nil, fmt.Errorf("--set requires --template")
+	}
+	var manifestTemplate spoke.ManifestTemplate
+	values := make(map[string]string, len(cfg.Defaults.Spoke.Values)+len(setValues))
+	if templateName != "" {
+		templateDir := cfg.Defaults.Spoke.TemplateDir
+		if templateDir == "" {
+			templateDir = config.ExpandPath(template.DefaultDir)
+		}
+		tmpl, err := template.NewLoader(templateDir).Load(templateName)
+		if err != nil {
+			return nil, err
+		}
+		manifestTemplate = tmpl
+
+		overrides, err := template.ParseValues(setValues)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(values, cfg.Defaults.Spoke.Values)
+		maps.Copy(values, overrides)
+	}
+
+	var snippets []spoke.InstallConfigSnippet
+	if names := append(slices.Clone(cfg.Defaults.Spoke.Snippets), snippetNames...); len(names) > 0 {
+		snippetDir := cfg.Defaults.Spoke.SnippetDir
+		if snippetDir == "" {
+			snippetDir = config.ExpandPath(snippet.DefaultDir)
+		}
+		loaded, err := snippet.NewLoader(snippetDir).LoadAll(names)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range loaded {
+			snippets = append(snippets, spoke.InstallConfigSnippet{Name: s.Name, Values: s.Values})
+		}
+	}
+
+	if provider == "" {
+		provider = cfg.Defaults.Spoke.Provider
+	}
+	platform := cfg.Defaults.Spoke.Platform(provider)
+	if credentialsName == "" {
+		credentialsName = platform.Credentials
+	}
+	if credentialsName == "" {
+		return nil, fmt.Errorf("--credentials is required unless defaults.spoke.<provider>.credentials is set")
+	}
+	if credentialsNamespace == "" {
+		credentialsNamespace = platform.CredentialsNamespace
+	}
+	if credentialsNamespace == "" {
+		credentialsNamespace = cfg.Hub.Namespace
+	}
+	if name == "" {
+		name = requestID
+	}
+
+	creds, err := cloud.NewCredentialClient(kubeClient.GetCoreClient().CoreV1()).Get(ctx, credentialsNamespace, credentialsName)
+	if err != nil {
+		return nil, err
+	}
+	if provider != "" && creds.Provider != provider {
+		return nil, fmt.Errorf("credential secret %s/%s is for %s, not %s", credentialsNamespace, credentialsName, creds.Provider, provider)
+	}
+	if provider == "" {
+		provider = creds.Provider
+		platform = cfg.Defaults.Spoke.Platform(provider)
+	}
+
+	if region == "" {
+		region = platform.Region
+	}
+	if region == "" && (cfg.Defaults.Spoke.Provider == "" || provider == cfg.Defaults.Spoke.Provider) {
+		region = cfg.Defaults.Spoke.Region
+	}
+	if controlPlaneType == "" {
+		controlPlaneType = platform.ControlPlaneType
+	}
+	if computeType == "" {
+		computeType = platform.ComputeType
+	}
+	if baseDomain == "" {
+		baseDomain = platform.BaseDomain
+	}
+	if baseDomain == "" {
+		baseDomain = creds.BaseDomain
+	}
+	if baseDomain == "" {
+		return nil, fmt.Errorf("--base-domain is required: the credential secret has no baseDomain")
+	}
+
+	presets := make([]spoke.MachinePoolPreset, 0, len(presetNames))
+	for _, presetName := range presetNames {
+		preset, err := spoke.GetMachinePoolPreset(presetName)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := preset.InstanceType(provider); err != nil {
+			return nil, err
+		}
+		presets = append(presets, preset)
+	}
+
+	pullSecret, err := pullSecretFor(ctx, kubeClient, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	sshPrivateKey, sshPublicKey := creds.SSHPrivateKey, creds.SSHPublicKey
+	if sshKeyPath != "" {
+		sshKeyPath = config.ExpandPath(sshKeyPath)
+		if sshPrivateKey, err = os.ReadFile(sshKeyPath); err != nil {
+			return nil, fmt.Errorf("failed to read SSH key: %w", err)
+		}
+		// Without a readable .pub file, the public key is derived from the private key
+		if data, err := os.ReadFile(sshKeyPath + ".pub"); err == nil {
+			sshPublicKey = strings.TrimSpace(string(data))
+		} else if sshPublicKey, err = sshkey.PublicKey(sshPrivateKey); err != nil {
+			return nil, fmt.Errorf("failed to read %s.pub and to derive the public key from %s: %w", sshKeyPath, sshKeyPath, err)
+		}
+	}
+	if len(sshPrivateKey) == 0 {
+		return nil, fmt.Errorf("--ssh-key is required: the credential secret has no ssh-privatekey")
+	}
+
+	return &spoke.ProvisionSpec{
+		Name:          name,
+		RequestID:     requestID,
+		BaseDomain:    baseDomain,
+		ImageSet:      imageSet,
+		Region:        region,
+		Zones:         zones,
+		ControlPlane:  cloud.MachinePool{InstanceType: controlPlaneType, Replicas: cloud.DefaultControlPlaneReplicas},
+		Compute:       cloud.MachinePool{InstanceType: computeType, Replicas: computeReplicas},
+		Credentials:   creds,
+		PullSecret:    pullSecret,
+		SSHPrivateKey: sshPrivateKey,
+		SSHPublicKey:  sshPublicKey,
+		Networking: spoke.Networking{
+			NetworkType: platform.Network.Type,
+			MachineCIDR: platform.Network.MachineCIDR,
+			ClusterCIDR: platform.Network.ClusterCIDR,
+			ServiceCIDR: platform.Network.ServiceCIDR,
+		},
+		Azure:    spokeAzurePlatform(cfg.Defaults.Spoke.Azure),
+		GCP:      spokeGCPPlatform(cfg.Defaults.Spoke.GCP),
+		VSphere:  spokeVSpherePlatform(cfg.Defaults.Spoke.VSphere, creds.VSphere),
+		Template: manifestTemplate,
+		Values:   values,
+		Snippets: snippets,
+		Presets:  presets,
+	}, nil
+}
+
+// spokeAzurePlatform returns the Azure settings of new clusters from the config defaults
+func spokeAzurePlatform(defaults config.AzureDefaults) spoke.AzurePlatform {
+	return spoke.AzurePlatform{
+		BaseDomainResourceGroup: defaults.BaseDomainResourceGroup,
+		NetworkResourceGroup:    defaults.NetworkResourceGroup,
+		VirtualNetwork:          defaults.VirtualNetwork,
+		ControlPlaneSubnet:      defaults.ControlPlaneSubnet,
+		ComputeSubnet:           defaults.ComputeSubnet,
+	}
+}
+
+// spokeGCPPlatform returns the GCP settings of new clusters from the config defaults
+func spokeGCPPlatform(defaults config.GCPDefaults) spoke.GCPPlatform {
+	return spoke.GCPPlatform{
+		ProjectID:          defaults.ProjectID,
+		Network:            defaults.VPCNetwork,
+		ControlPlaneSubnet: defaults.ControlPlaneSubnet,
+		ComputeSubnet:      defaults.ComputeSubnet,
+	}
+}
+
+// spokeVSpherePlatform returns the vSphere settings of new clusters from the config defaults,
+// falling back to the placement stored with the credentials
+func spokeVSpherePlatform(defaults config.VSphereDefaults, creds *cloud.VSphereCredentials) spoke.VSpherePlatform {
+	platform := spoke.VSpherePlatform{
+		VCenter:          defaults.VCenter,
+		Datacenter:       defaults.Datacenter,
+		DefaultDatastore: defaults.DefaultDatastore,
+		Cluster:          defaults.Cluster,
+		Network:          defaults.PortGroup,
+		Folder:           defaults.Folder,
+		APIVIP:           defaults.APIVIP,
+		IngressVIP:       defaults.IngressVIP,
+	}
+	if creds == nil {
+		return platform
+	}
+	for _, field := range []struct {
+		value    *string
+		fallback string
+	}{
+		{&platform.VCenter, creds.VCenter},
+		{&platform.Datacenter, creds.Datacenter},
+		{&platform.DefaultDatastore, creds.DefaultDatastore},
+		{&platform.Cluster, creds.Cluster},
+		{&platform.Folder, creds.Folder},
+	} {
+		if *field.value == "" {
+			*field.value = field.fallback
+		}
+	}
+	return platform
+}
+
+// writeManifests writes objects as YAML, to stdout as one multi-document stream or, if dir is
+// set, to one numbered file per object in dir
+func writeManifests(objects []*unstructured.Unstructured, dir string) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	for i, obj := range objects {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if dir == "" {
+			fmt.Fprintf(os.Stdout, "---\n%s", data)
+			continue
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("%02d-%s-%s.yaml", i+1, strings.ToLower(obj.GetKind()), obj.GetName()))
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Wrote %s\n", path)
+	}
+	return nil
+}
+
+// runSpokePreflight checks that the target cloud account can host the requested cluster
+// and returns an error if any preflight check fails
+func runSpokePreflight(ctx context.Context, kubeClient *kube.Client, spec *spoke.ProvisionSpec) error {
+	var additionalCompute []cloud.MachinePool
+	for _, preset := range spec.Presets {
+		instanceType, _ := preset.InstanceType(spec.Credentials.Provider)
+		additionalCompute = append(additionalCompute, cloud.MachinePool{InstanceType: instanceType, Replicas: int(preset.MinReplicas)})
+	}
+	report := cloud.NewPreflight(nil, nil).Run(ctx, spec.Credentials, cloud.ClusterSpec{
+		Name:              spec.Name,
+		Region:            spec.Region,
+		BaseDomain:        spec.BaseDomain,
+		Zones:             spec.Zones,
+		ControlPlane:      spec.ControlPlane,
+		Compute:           spec.Compute,
+		AdditionalCompute: additionalCompute,
+	})
+
+	report.Run("Release image", func() (check.Status, string) {
+		return checkReleaseImage(ctx, kubeClient, spec.PullSecret, spec.ImageSet)
+	})
+
+	if err := check.NewWriter(check.OutputFormatTable, os.Stderr).Write(report); err != nil {
+		return fmt.Errorf("failed to write preflight results: %w", err)
+	}
+	if err := report.Err(); err != nil {
+		return fmt.Errorf("preflight failed: %w", err)
+	}
+	return nil
+}
+
+// pullSecretFor returns the pull secret stored with the cloud credentials or, failing that,
+// the hub's global pull secret
+func pullSecretFor(ctx context.Context, kubeClient *kube.Client, creds *cloud.Credentials) ([]byte, error) {
+	if len(creds.PullSecret) > 0 {
+		return creds.PullSecret, nil
+	}
+	secret, err := kubeClient.GetCoreClient().CoreV1().Secrets("openshift-config").Get(ctx, "pull-secret", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("credential secret has no pullSecret and the hub pull secret is unavailable: %w", err)
+	}
+	return secret.Data[corev1.DockerConfigJsonKey], nil
+}
+
+// checkReleaseImage verifies that the release image of a ClusterImageSet can be pulled with a pull secret
+func checkReleaseImage(ctx context.Context, kubeClient *kube.Client, pullSecretData []byte, imageSetName string) (check.Status, string) {
+	imageSet, err := hub.NewClusterImageSetClient(kubeClient.GetDynamicClient(), clientOptions...).Get(ctx, imageSetName)
+	if err != nil {
+		return check.StatusFail, err.Error()
+	}
+
+	pullSecret, err := registry.ParsePullSecret(pullSecretData)
+	if err != nil {
+		return check.StatusFail, err.Error()
+	}
+
+	digest, err := registry.NewClient(pullSecret, nil).Head(ctx, imageSet.ReleaseImage)
+	switch {
+	case errors.Is(err, registry.ErrNotFound):
+		return check.StatusFail, fmt.Sprintf("%s does not exist: check the version in ClusterImageSet %s", imageSet.ReleaseImage, imageSetName)
+	case errors.Is(err, registry.ErrUnauthorized):
+		return check.StatusFail, fmt.Sprintf("%v: refresh the pull secret from console.redhat.com", err)
+	case err != nil:
+		return check.StatusFail, err.Error()
+	}
+	return check.StatusPass, fmt.Sprintf("%s (%s)", imageSet.ReleaseImage, digest)
+}
+
+// waitForSpokeInstall follows the install of a cluster until it completes, with a spinner on
+// a terminal and a line per change otherwise. A failed install prints the end of the
+// installer log.
+func waitForSpokeInstall(ctx context.Context, kubeClient *kube.Client, clusterName string, timeout time.Duration) error {
+	installWaiter := waiter.New(kubeClient.GetDynamicClient(), kubeClient.GetCoreClient(), waiter.Options{Timeout: timeout}, clientOptions...)
+
+	fmt.Fprintf(os.Stderr, "⏳ Waiting for Hive to install %s (timeout %s)...\n", clusterName, timeout)
+	var err error
+	if isTerminal(os.Stderr) {
+		spinner := waiter.NewSpinner(os.Stderr)
+		spinner.Update(spoke.InstallPhasePending)
+		spinner.Start()
+		err = installWaiter.Wait(ctx, clusterName, func(progress waiter.Progress) {
+			spinner.Update(progress.String())
+		})
+		spinner.Stop("")
+	} else {
+		err = installWaiter.Wait(ctx, clusterName, func(progress waiter.Progress) {
+			fmt.Fprintf(os.Stderr, "   %s\n", progress)
+		})
+	}
+
+	var installErr *waiter.InstallFailedError
+	if errors.As(err, &installErr) {
+		fmt.Fprintf(os.Stderr, "⚠️  Install of %s failed: %s\n", clusterName, installErr.Reason)
+		if len(installErr.Logs) > 0 {
+			fmt.Fprintln(os.Stderr, "Last lines of the installer log:")
+			for _, line := range installErr.Logs {
+				fmt.Fprintf(os.Stderr, "   %s\n", line)
+			}
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Cluster %s installed; get its kubeconfig with: labrat spoke kubeconfig %s\n", clusterName, clusterName)
+	return nil
+}
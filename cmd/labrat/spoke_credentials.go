@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// maskedPassword replaces the kubeadmin password in output unless --show is given
+const maskedPassword = "********"
+
+// clusterCredentials is the JSON representation of the login details of a cluster
+type clusterCredentials struct {
+	Name       string `json:"name"`
+	APIURL     string `json:"apiURL"`
+	ConsoleURL string `json:"consoleURL"`
+	Username   string `json:"username"`
+	Password   string `json:"password,omitempty"`
+}
+
+// newSpokeCredentialsCmd creates the `spoke credentials` command
+func newSpokeCredentialsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "credentials <cluster-name>",
+		Short: "Print the kubeadmin login details of a spoke cluster",
+		Long: `Print the API URL, console URL, and kubeadmin credentials of a spoke cluster.
+
+The credentials are read from the secret referenced by the ClusterDeployment's
+spec.clusterMetadata.adminPasswordSecretRef. The password is masked unless --show
+is given, so the command can be run while screen sharing.
+
+Examples:
+  # Print the login details with the password masked
+  labrat spoke credentials my-cluster
+
+  # Reveal the password
+  labrat spoke credentials my-cluster --show
+
+  # Log in with oc
+  oc login "$(labrat spoke credentials my-cluster -o json | jq -r .apiURL)" -u kubeadmin`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+			show, _ := cmd.Flags().GetBool("show")
+
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			cd, err := newHubBackend(kubeClient).ClusterDeployments().Get(ctx, clusterName)
+			if err != nil {
+				return fmt.Errorf("failed to get ClusterDeployment: %w", err)
+			}
+
+			creds, err := spoke.NewKubeadminPasswordExtractor(
+				kubeClient.GetDynamicClient(),
+				kubeClient.GetCoreClient().CoreV1(),
+				clientOptions...,
+			).Extract(ctx, clusterName)
+			if err != nil {
+				return err
+			}
+
+			details := clusterCredentials{
+				Name:       cd.Name,
+				APIURL:     cd.APIUrl,
+				ConsoleURL: cd.ConsoleURL,
+				Username:   creds.Username,
+			}
+			if show {
+				details.Password = creds.Password
+				fmt.Fprintf(os.Stderr, "⚠️  WARNING: These are the cluster-admin kubeadmin credentials!\n\n")
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(details, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+				return nil
+			}
+
+			password := details.Password
+			if !show {
+				password = maskedPassword + " (use --show to reveal)"
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "Cluster:\t%s\n", details.Name)
+			fmt.Fprintf(w, "API URL:\t%s\n", valueOrNA(details.APIURL))
+			fmt.Fprintf(w, "Console URL:\t%s\n", valueOrNA(details.ConsoleURL))
+			fmt.Fprintf(w, "Username:\t%s\n", details.Username)
+			fmt.Fprintf(w, "Password:\t%s\n", password)
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "text", "Output format (text|json)")
+	cmd.Flags().Bool("show", false, "Print the password instead of masking it")
+	return cmd
+}
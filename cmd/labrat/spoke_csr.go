@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// csrRow is a CSR of a cluster and where it was created, hub or spoke
+type csrRow struct {
+	spoke.CSRInfo
+	Location string `json:"location"`
+}
+
+// newSpokeCSRCmd creates the `spoke csr` command group
+func newSpokeCSRCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "csr",
+		Short: "List and approve the certificate signing requests of imported clusters",
+		Long: `List and approve the CertificateSigningRequests that block the registration of a
+spoke: those the klusterlet creates on the hub, labeled with the name of the cluster,
+and optionally the kubelet CSRs on the spoke itself.
+
+ACM approves the klusterlet CSRs of clusters it provisions or imports itself, but the
+CSRs of manually imported clusters often wait for an administrator.`,
+	}
+	cmd.AddCommand(newSpokeCSRListCmd(), audited(newSpokeCSRApproveCmd()))
+	return cmd
+}
+
+// addSpokeCSRFlags adds the flags selecting how the CSRs of the spoke are read
+func addSpokeCSRFlags(cmd *cobra.Command) {
+	cmd.Flags().String("spoke-kubeconfig", "", "Kubeconfig of the spoke (default: the admin kubeconfig extracted from the hub)")
+	cmd.Flags().String("spoke-context", "", "Context of --spoke-kubeconfig (default: current context)")
+}
+
+// newSpokeCSRListCmd creates the `spoke csr list` command
+func newSpokeCSRListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list <cluster-name>",
+		Short: "List the klusterlet CSRs of a cluster",
+		Long: `List the CSRs the klusterlet of a cluster created on the hub, oldest first, with their
+requestor, signer, and status. With --spoke the kubelet CSRs on the spoke are listed too.
+
+Examples:
+  # List the klusterlet CSRs of a cluster
+  labrat spoke csr list partner-cluster
+
+  # Include the node CSRs of the spoke, reading them with its kubeconfig
+  labrat spoke csr list partner-cluster --spoke --spoke-kubeconfig ./partner-kubeconfig`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+			includeSpoke, _ := cmd.Flags().GetBool("spoke")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, hubClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			var spokeClient *kube.Client
+			if includeSpoke {
+				if spokeClient, err = spokeCSRClient(ctx, cmd, hubClient, clusterName); err != nil {
+					return err
+				}
+			}
+			rows, err := listClusterCSRs(ctx, hubClient, spokeClient, clusterName)
+			if err != nil {
+				return err
+			}
+			if written, err := writeListOutput(outputFormat, rows); written {
+				return err
+			}
+
+			if len(rows) == 0 {
+				fmt.Fprintf(os.Stdout, "No certificate signing requests found for %s\n", clusterName)
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "NAME\tLOCATION\tAGE\tSIGNER\tREQUESTOR\tCONDITION")
+			for _, row := range rows {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", row.Name, row.Location, duration.HumanDuration(time.Since(row.CreatedAt)),
+					row.SignerName, row.Requestor, row.Status)
+			}
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	cmd.Flags().Bool("spoke", false, "Also list the node CSRs on the spoke")
+	addSpokeCSRFlags(cmd)
+	return cmd
+}
+
+// newSpokeCSRApproveCmd creates the `spoke csr approve` command
+func newSpokeCSRApproveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve <cluster-name> [csr-name...]",
+		Short: "Approve the pending klusterlet CSRs of a cluster",
+		Long: `Approve CSRs that block the registration of a spoke, like oc adm certificate approve.
+
+Name the klusterlet CSRs to approve, or approve every pending klusterlet CSR of the
+cluster on the hub with --pending. --all also approves the pending kubelet CSRs on the
+spoke, e.g. of nodes that joined while the cluster was not registered; they are read
+with --spoke-kubeconfig, or the admin kubeconfig extracted from the hub for clusters
+provisioned by Hive. CSRs that were already approved or denied are left as they are.
+
+Examples:
+  # Approve the pending klusterlet CSRs of a manually imported cluster
+  labrat spoke csr approve partner-cluster --pending
+
+  # Approve one CSR
+  labrat spoke csr approve partner-cluster partner-cluster-x7k2p
+
+  # Also approve the pending node CSRs of the spoke
+  labrat spoke csr approve partner-cluster --all --spoke-kubeconfig ./partner-kubeconfig`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName, names := args[0], args[1:]
+			pending, _ := cmd.Flags().GetBool("pending")
+			all, _ := cmd.Flags().GetBool("all")
+
+			if len(names) > 0 && (pending || all) {
+				return fmt.Errorf("CSR names cannot be combined with --pending or --all")
+			}
+			if len(names) == 0 && !pending && !all {
+				return fmt.Errorf("name the CSRs to approve or pass --pending or --all")
+			}
+
+			_, hubClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			managers := map[string]spoke.CSRManager{"hub": spoke.NewCSRManager(hubClient.GetCoreClient(), clientOptions...)}
+			var spokeClient *kube.Client
+			if all {
+				if spokeClient, err = spokeCSRClient(ctx, cmd, hubClient, clusterName); err != nil {
+					return err
+				}
+				managers["spoke"] = spoke.NewCSRManager(spokeClient.GetCoreClient(), clientOptions...)
+			}
+			rows, err := listClusterCSRs(ctx, hubClient, spokeClient, clusterName)
+			if err != nil {
+				return err
+			}
+
+			var selected []csrRow
+			for _, name := range names {
+				i := slices.IndexFunc(rows, func(row csrRow) bool { return row.Name == name })
+				if i < 0 {
+					return fmt.Errorf("certificate signing request %s of cluster %s not found", name, clusterName)
+				}
+				selected = append(selected, rows[i])
+			}
+			if len(names) == 0 {
+				for _, row := range rows {
+					if row.Pending() {
+						selected = append(selected, row)
+					}
+				}
+			}
+			if len(selected) == 0 {
+				fmt.Fprintf(os.Stderr, "No pending certificate signing requests of %s\n", clusterName)
+				return nil
+			}
+
+			var failed []string
+			for _, row := range selected {
+				if err := managers[row.Location].Approve(ctx, row.Name); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+					failed = append(failed, row.Name)
+					continue
+				}
+				fmt.Fprintf(os.Stderr, "✓ Approved %s on the %s (%s)\n", row.Name, row.Location, row.SignerName)
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("failed to approve %s", strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("pending", false, "Approve every pending klusterlet CSR of the cluster")
+	cmd.Flags().Bool("all", false, "Like --pending, and also approve the pending node CSRs on the spoke")
+	addSpokeCSRFlags(cmd)
+	return cmd
+}
+
+// listClusterCSRs lists the klusterlet CSRs of a cluster on the hub and, if spokeClient is not
+// nil, the node CSRs on the spoke
+func listClusterCSRs(ctx context.Context, hubClient, spokeClient *kube.Client, clusterName string) ([]csrRow, error) {
+	hubCSRs, err := spoke.NewCSRManager(hubClient.GetCoreClient(), clientOptions...).List(ctx, spoke.KlusterletCSRSelector(clusterName))
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]csrRow, 0, len(hubCSRs))
+	for _, csr := range hubCSRs {
+		rows = append(rows, csrRow{CSRInfo: csr, Location: "hub"})
+	}
+	if spokeClient == nil {
+		return rows, nil
+	}
+
+	spokeCSRs, err := spoke.NewCSRManager(spokeClient.GetCoreClient(), clientOptions...).List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the CSRs of spoke %s: %w", clusterName, err)
+	}
+	for _, csr := range spokeCSRs {
+		if csr.Node() {
+			rows = append(rows, csrRow{CSRInfo: csr, Location: "spoke"})
+		}
+	}
+	return rows, nil
+}
+
+// spokeCSRClient connects to the spoke with --spoke-kubeconfig, or with the admin kubeconfig
+// extracted from the hub
+func spokeCSRClient(ctx context.Context, cmd *cobra.Command, hubClient *kube.Client, clusterName string) (*kube.Client, error) {
+	spokeKubeconfig, _ := cmd.Flags().GetString("spoke-kubeconfig")
+	spokeContext, _ := cmd.Flags().GetString("spoke-context")
+	if spokeKubeconfig == "" {
+		return newSpokeClient(ctx, hubClient, clusterName)
+	}
+	spokeClient, err := kube.NewClient(config.ExpandPath(spokeKubeconfig), spokeContext, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for spoke %s: %w", clusterName, err)
+	}
+	return spokeClient, nil
+}
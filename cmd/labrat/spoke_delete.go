@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeDeleteCmd creates the `spoke delete` command
+func newSpokeDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete [cluster-name...]",
+		Short: "Delete spoke clusters and deprovision their cloud resources",
+		Long: `Delete a spoke cluster by deleting its ClusterDeployment. Hive then runs a
+ClusterDeprovision job that destroys the cloud resources of the cluster.
+
+By default the ManagedCluster is deleted first, so ACM detaches the cluster before it
+goes away. Use --detach=false to leave the ManagedCluster in place, e.g. when it is
+managed by another tool.
+
+With --wait the command polls the deprovision and prints its progress until the
+ClusterDeployment is gone. The cluster namespace and its secrets are left behind;
+remove them with labrat hub gc.
+
+Several clusters are deleted in parallel, bounded by --concurrency, with a summary of
+per-cluster results at the end. Instead of naming them, clusters can be selected by the
+labels of their ManagedCluster with --selector, by ManagedClusterSet with --clusterset,
+or all at once with --all. Before several clusters are deleted they are listed for
+confirmation; pass --yes to skip it. With --plan the clusters are saved to a plan file
+for review instead, and only deleted by labrat apply.
+
+With --expired-only the cluster is only deleted if its lease has expired (see labrat
+spoke lease). Without a cluster name every cluster with an expired lease is deleted.
+
+Examples:
+  # Delete a cluster and return immediately
+  labrat spoke delete my-cluster
+
+  # Delete a cluster and wait up to 90 minutes for the deprovision to finish
+  labrat spoke delete my-cluster --wait --wait-timeout 90m
+
+  # Delete the ClusterDeployment but keep the ManagedCluster
+  labrat spoke delete my-cluster --detach=false
+
+  # Delete every cluster whose lease has expired
+  labrat spoke delete --expired-only
+
+  # Delete the clusters of a finished workshop
+  labrat spoke delete --selector workshop=2026-03 --yes
+
+  # Plan the deletion of every expired cluster for review
+  labrat spoke delete --expired-only --plan cleanup.yaml`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if expiredOnly, _ := cmd.Flags().GetBool("expired-only"); expiredOnly {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+			return targetArgs(1)(cmd, args)
+		},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			detach, _ := cmd.Flags().GetBool("detach")
+			waitForDeprovision, _ := cmd.Flags().GetBool("wait")
+			timeout := waitTimeout(cmd)
+			expiredOnly, _ := cmd.Flags().GetBool("expired-only")
+
+			opts, err := batchOptions(cmd)
+			if err != nil {
+				return err
+			}
+			if expiredOnly && hasTargetFlags(cmd) {
+				return fmt.Errorf("--expired-only cannot be combined with --selector, --clusterset, or --all")
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			clusterNames := args
+			if expiredOnly {
+				clusterNames, err = expiredClusters(ctx, kubeClient.GetDynamicClient(), args)
+				if err != nil {
+					return err
+				}
+				if len(clusterNames) == 0 {
+					fmt.Fprintln(os.Stderr, "No clusters with an expired lease")
+					return nil
+				}
+			} else {
+				if clusterNames, err = resolveTargets(ctx, cmd, kubeClient, clusterNames); err != nil {
+					return err
+				}
+				if len(clusterNames) == 0 {
+					fmt.Fprintln(os.Stderr, "No clusters selected")
+					return nil
+				}
+				if (len(clusterNames) > 1 || hasTargetFlags(cmd)) && planPath(cmd) == "" {
+					if err := confirmTargets(cmd, "delete", clusterNames); err != nil {
+						return err
+					}
+				}
+			}
+
+			if planPath(cmd) != "" {
+				return savePlan(ctx, cmd, cfg, clusterNames, nil, func(ctx context.Context, clusterName string) (string, error) {
+					if detach {
+						return "delete ManagedCluster and ClusterDeployment, deprovision cloud resources", nil
+					}
+					return "delete ClusterDeployment, deprovision cloud resources", nil
+				})
+			}
+
+			deprovisioner := spoke.NewDeprovisioner(kubeClient.GetDynamicClient(), spoke.DeprovisionOptions{
+				Timeout: timeout,
+			}, clientOptions...)
+
+			if len(clusterNames) == 1 {
+				return deleteCluster(ctx, deprovisioner, clusterNames[0], detach, waitForDeprovision, timeout)
+			}
+			results := fleet.NewRunner(opts).Run(ctx, clusterNames, func(ctx context.Context, clusterName string) error {
+				return deleteCluster(ctx, deprovisioner, clusterName, detach, waitForDeprovision, timeout)
+			})
+			return reportBatch(results)
+		},
+	}
+	addBatchFlags(cmd)
+	addTargetFlags(cmd)
+	addPlanFlag(cmd)
+	cmd.Flags().Bool("detach", true, "Delete the ManagedCluster so ACM detaches the cluster")
+	cmd.Flags().Bool("wait", false, "Wait for the deprovision to finish")
+	addWaitTimeoutFlag(cmd, spoke.DefaultDeprovisionTimeout, "Maximum time to wait for the deprovision with --wait")
+	cmd.Flags().Bool("expired-only", false, "Only delete the cluster if its lease has expired; without a cluster name, delete every expired cluster")
+	return cmd
+}
+
+// deleteCluster deletes a cluster and, with waitForDeprovision, prints the progress of its
+// deprovision until it is gone
+func deleteCluster(ctx context.Context, deprovisioner spoke.Deprovisioner, clusterName string, detach, waitForDeprovision bool, timeout time.Duration) error {
+	if err := deprovisioner.Delete(ctx, clusterName, detach); err != nil {
+		return err
+	}
+	if detach {
+		fmt.Fprintf(os.Stderr, "✓ ManagedCluster %s deleted\n", clusterName)
+	}
+	fmt.Fprintf(os.Stderr, "✓ ClusterDeployment %s deleted, Hive will deprovision the cluster\n", clusterName)
+
+	if !waitForDeprovision {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "⏳ Waiting for the deprovision of %s (timeout %s)...\n", clusterName, timeout)
+	err := deprovisioner.Wait(ctx, clusterName, func(status spoke.DeprovisionStatus) {
+		if status.Phase == spoke.DeprovisionPhaseBlocked {
+			fmt.Fprintf(os.Stderr, "⚠️  %s: %s\n", status.Phase, status.Message)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "   %s: %s\n", status.Phase, status.Message)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Cluster %s deprovisioned\n", clusterName)
+	return nil
+}
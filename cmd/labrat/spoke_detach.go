@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeDetachCmd creates the `spoke detach` command
+func newSpokeDetachCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "detach <cluster-name>",
+		Short: "Remove a spoke cluster from ACM without destroying it",
+		Long: `Remove a spoke cluster from ACM by deleting its ManagedCluster, e.g. to hand the
+cluster over to a partner for good. The ClusterDeployment and the cloud resources of the
+cluster are left intact, so the cluster keeps running.
+
+With --remove-klusterlet the ACM agents are uninstalled from the cluster as well: the
+Klusterlet is deleted through the cluster's admin kubeconfig and, once the klusterlet
+operator has cleaned up, the klusterlet namespaces are removed.
+
+The command asks for confirmation before each step. Pass --yes to skip the prompts, e.g.
+in scripts; without a terminal the command fails unless --yes is given.
+
+Examples:
+  # Detach a cluster, keeping the agents on it
+  labrat spoke detach my-cluster
+
+  # Detach a cluster and uninstall the ACM agents without prompting
+  labrat spoke detach my-cluster --remove-klusterlet --yes`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			removeKlusterlet, _ := cmd.Flags().GetBool("remove-klusterlet")
+			yes, _ := cmd.Flags().GetBool("yes")
+			timeout := waitTimeout(cmd)
+
+			var p *prompter
+			if !yes {
+				if !isTerminal(os.Stdin) {
+					return fmt.Errorf("refusing to detach %s without confirmation, pass --yes", clusterName)
+				}
+				p = &prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+			}
+			confirmed := func(question string) bool {
+				return yes || p.confirm(question)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			// The klusterlet is removed first, while the admin kubeconfig is still known to be usable
+			// and before the hub stops tracking the cluster
+			if removeKlusterlet {
+				if !confirmed(fmt.Sprintf("Uninstall the ACM agents from %s?", clusterName)) {
+					return fmt.Errorf("detach of %s aborted", clusterName)
+				}
+				spokeClient, err := newSpokeClient(ctx, kubeClient, clusterName)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "⏳ Removing the klusterlet from %s...\n", clusterName)
+				remover := spoke.NewKlusterletRemover(spokeClient.GetDynamicClient(), spoke.KlusterletOptions{Timeout: timeout}, clientOptions...)
+				if err := remover.Remove(ctx); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "✓ Klusterlet removed from %s\n", clusterName)
+			}
+
+			if !confirmed(fmt.Sprintf("Delete the ManagedCluster %s? The cluster and its ClusterDeployment are kept.", clusterName)) {
+				return fmt.Errorf("detach of %s aborted", clusterName)
+			}
+			if err := spoke.NewDetacher(kubeClient.GetDynamicClient(), clientOptions...).Detach(ctx, clusterName); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Cluster %s detached from ACM, its ClusterDeployment and cloud resources are kept\n", clusterName)
+			return nil
+		},
+	}
+	cmd.Flags().Bool("remove-klusterlet", false, "Also uninstall the ACM agents from the cluster")
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	addWaitTimeoutFlag(cmd, spoke.DefaultKlusterletRemovalTimeout, "Maximum time to wait for the klusterlet removal")
+	return cmd
+}
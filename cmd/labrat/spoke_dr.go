@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeDRCmd creates the `spoke dr` command
+func newSpokeDRCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dr",
+		Short: "Manage disaster recovery of spoke clusters",
+	}
+	cmd.AddCommand(audited(newSpokeDREnableCmd()))
+	return cmd
+}
+
+// newSpokeDREnableCmd creates the `spoke dr enable` command
+func newSpokeDREnableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable <cluster-name>",
+		Short: "Install OADP and configure backups to S3 on a spoke cluster",
+		Long: `Install the OADP operator on a spoke cluster and configure a Velero backup
+storage location in an S3 bucket.
+
+The operator subscription, the bucket credentials, and the DataProtectionApplication
+are delivered to the spoke in the ManifestWork labrat-oadp in the cluster namespace
+on the hub, so the setup is reapplied if it drifts. Running the command again updates
+the ManifestWork with the new bucket settings.
+
+The bucket is accessed with the AWS access key of the cloud credential secret named by
+--credentials. The bucket region defaults to the region of the cluster.
+
+Examples:
+  # Back up a cluster to a prefix of the lab backup bucket
+  labrat spoke dr enable my-cluster --bucket s3://lab-backups/my-cluster --credentials aws-partner-lab
+
+  # Use a bucket in another region
+  labrat spoke dr enable my-cluster --bucket s3://lab-backups --region us-west-2 --credentials aws-partner-lab`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			bucketURL, _ := cmd.Flags().GetString("bucket")
+			region, _ := cmd.Flags().GetString("region")
+			credentialsName, _ := cmd.Flags().GetString("credentials")
+			credentialsNamespace, _ := cmd.Flags().GetString("credentials-namespace")
+
+			bucket, prefix, err := spoke.ParseBucketURL(bucketURL)
+			if err != nil {
+				return err
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			if region == "" {
+				cd, err := newHubBackend(kubeClient).ClusterDeployments().Get(ctx, clusterName)
+				if err != nil {
+					return fmt.Errorf("failed to get ClusterDeployment: %w", err)
+				}
+				if cd.Region == "" {
+					return fmt.Errorf("cluster %s has no region label, pass --region", clusterName)
+				}
+				region = cd.Region
+			}
+
+			if credentialsNamespace == "" {
+				credentialsNamespace = cfg.Hub.Namespace
+			}
+			creds, err := cloud.NewCredentialClient(kubeClient.GetCoreClient().CoreV1()).Get(ctx, credentialsNamespace, credentialsName)
+			if err != nil {
+				return err
+			}
+			if creds.AWS == nil {
+				return fmt.Errorf("credential secret %s/%s is not an AWS credential", credentialsNamespace, credentialsName)
+			}
+
+			err = spoke.NewDRManager(kubeClient.GetDynamicClient(), clientOptions...).Enable(ctx, clusterName, spoke.BackupStorage{
+				Bucket:          bucket,
+				Prefix:          prefix,
+				Region:          region,
+				AccessKeyID:     creds.AWS.AccessKeyID,
+				SecretAccessKey: creds.AWS.SecretAccessKey,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("✓ OADP configured on %s: backups go to %s (%s)\n", clusterName, bucketURL, region)
+			fmt.Printf("  Track the rollout with: oc get manifestwork %s -n %s\n", spoke.DRManifestWorkName, clusterName)
+			return nil
+		},
+	}
+	cmd.Flags().String("bucket", "", "S3 bucket for backups, as s3://<bucket>[/<prefix>] (Required)")
+	cmd.Flags().String("region", "", "Region of the bucket (defaults to the cluster region)")
+	cmd.Flags().String("credentials", "", "Cloud credential secret with the AWS access key for the bucket (Required)")
+	cmd.Flags().String("credentials-namespace", "", "Namespace of the credential secret (defaults to the hub namespace)")
+	for _, name := range []string{"bucket", "credentials"} {
+		if err := cmd.MarkFlagRequired(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error marking flag required: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	return cmd
+}
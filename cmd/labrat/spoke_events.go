@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// newSpokeEventsCmd creates the `spoke events` command
+func newSpokeEventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events <cluster-name>",
+		Short: "List or stream the events of a spoke cluster",
+		Long: `List the events of the cluster namespace on the hub, where Hive and ACM record the
+progress and failures of installs, hibernation, and imports, oldest first. With --spoke,
+the events of every namespace of the spoke cluster itself are included, read with the
+admin kubeconfig extracted from the hub; a spoke that cannot be reached is reported as a
+warning and only the hub events are shown.
+
+--follow keeps streaming events as they are recorded or repeated until interrupted.
+-o json writes one JSON object per event and line.
+
+Examples:
+  # List the events of a cluster
+  labrat spoke events my-cluster
+
+  # Show the warnings of the last 30 minutes, from the hub and the spoke
+  labrat spoke events my-cluster --since 30m --type Warning --spoke
+
+  # Stream the events of a stuck install
+  labrat spoke events my-cluster --follow`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+			since, _ := cmd.Flags().GetDuration("since")
+			eventTypes, _ := cmd.Flags().GetStringSlice("type")
+			includeSpoke, _ := cmd.Flags().GetBool("spoke")
+			follow, _ := cmd.Flags().GetBool("follow")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+			if since < 0 {
+				return fmt.Errorf("--since must not be negative, got %s", since)
+			}
+			opts := spoke.EventOptions{}
+			if since > 0 {
+				opts.Since = time.Now().Add(-since)
+			}
+			for _, eventType := range eventTypes {
+				switch {
+				case strings.EqualFold(eventType, corev1.EventTypeNormal):
+					opts.Types = append(opts.Types, corev1.EventTypeNormal)
+				case strings.EqualFold(eventType, corev1.EventTypeWarning):
+					opts.Types = append(opts.Types, corev1.EventTypeWarning)
+				default:
+					return fmt.Errorf("unsupported event type: %s (supported: Normal, Warning)", eventType)
+				}
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			// The namespace of the cluster on the hub, and every namespace of the spoke
+			readers := map[string]spoke.EventReader{
+				clusterName: spoke.NewEventReader(kubeClient.GetCoreClient(), spoke.EventSourceHub, clientOptions...),
+			}
+			if includeSpoke {
+				spokeClient, err := newSpokeClient(ctx, kubeClient, clusterName)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Skipping the events of the spoke: %v\n", err)
+				} else {
+					readers[""] = spoke.NewEventReader(spokeClient.GetCoreClient(), spoke.EventSourceSpoke, clientOptions...)
+				}
+			}
+
+			writer := newEventWriter(os.Stdout, outputFormat, includeSpoke)
+			if follow {
+				return followClusterEvents(ctx, readers, opts, writer)
+			}
+
+			var events []spoke.ClusterEvent
+			for namespace, reader := range readers {
+				read, err := reader.List(ctx, namespace, opts)
+				if err != nil {
+					return err
+				}
+				events = append(events, read...)
+			}
+			if len(events) == 0 {
+				fmt.Fprintf(os.Stderr, "No events found for cluster %s\n", clusterName)
+				return nil
+			}
+			sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeen.Before(events[j].LastSeen) })
+			return writer.Write(events...)
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().Duration("since", 0, "Only show events last seen within this duration, e.g. 30m (0 for all)")
+	cmd.Flags().StringSlice("type", nil, "Only show events of these types (Normal|Warning)")
+	cmd.Flags().Bool("spoke", false, "Include the events of the spoke cluster itself")
+	cmd.Flags().BoolP("follow", "f", false, "Stream events as they are recorded until interrupted")
+	return cmd
+}
+
+// followClusterEvents streams the events of every reader to writer until ctx is done
+func followClusterEvents(ctx context.Context, readers map[string]spoke.EventReader, opts spoke.EventOptions, writer *eventWriter) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for namespace, reader := range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := reader.Follow(ctx, namespace, opts, func(event spoke.ClusterEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				if err := writer.Write(event); err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// eventWriter writes events as table rows, printing the header before the first one, or
+// as JSON lines
+type eventWriter struct {
+	out          io.Writer
+	outputFormat string
+	// withSource adds the SOURCE and NAMESPACE columns, for events from the hub and the spoke
+	withSource    bool
+	headerWritten bool
+}
+
+// newEventWriter creates an eventWriter writing to out in outputFormat
+func newEventWriter(out io.Writer, outputFormat string, withSource bool) *eventWriter {
+	return &eventWriter{out: out, outputFormat: outputFormat, withSource: withSource}
+}
+
+// Write writes events and flushes them, so that followed events show up at once
+func (e *eventWriter) Write(events ...spoke.ClusterEvent) error {
+	if e.outputFormat == "json" {
+		encoder := json.NewEncoder(e.out)
+		for _, event := range events {
+			if err := encoder.Encode(event); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(e.out, 0, 0, 3, ' ', 0)
+	if !e.headerWritten {
+		if e.withSource {
+			fmt.Fprintln(w, "LAST SEEN\tSOURCE\tNAMESPACE\tTYPE\tREASON\tOBJECT\tMESSAGE")
+		} else {
+			fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE")
+		}
+		e.headerWritten = true
+	}
+	for _, event := range events {
+		lastSeen := duration.HumanDuration(time.Since(event.LastSeen))
+		if event.Count > 1 {
+			lastSeen += fmt.Sprintf(" (x%d)", event.Count)
+		}
+		if e.withSource {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", lastSeen, event.Source, event.Namespace,
+				event.Type, event.Reason, event.Object, event.Message)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", lastSeen, event.Type, event.Reason, event.Object, event.Message)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
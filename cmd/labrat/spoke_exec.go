@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// exitCodeError reports that a command run by labrat exited with code, which labrat exits
+// with as well. The command has already explained the failure on stderr.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// newSpokeExecCmd creates the `spoke exec` command
+func newSpokeExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec <cluster-name> -- <kubectl args...>",
+		Short: "Run kubectl or oc against a spoke cluster",
+		Long: `Run kubectl or oc with the admin kubeconfig of a spoke cluster.
+
+The kubeconfig is extracted from the hub to a temporary file readable only by the
+current user, passed to the command through $KUBECONFIG, and removed when the command
+exits. stdin, stdout, and stderr are connected to the command, and labrat exits with
+its exit code.
+
+kubectl is used if it is on the PATH, otherwise oc; --binary selects another client.
+
+Examples:
+  # List the nodes of a spoke
+  labrat spoke exec my-cluster -- get nodes
+
+  # Follow the logs of a pod with oc
+  labrat spoke exec my-cluster --binary oc -- logs -f -n openshift-console deploy/console
+
+  # Open a shell in a pod
+  labrat spoke exec my-cluster -- exec -it -n my-app deploy/web -- sh`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+				return fmt.Errorf("expected a cluster name followed by -- and the arguments of the command")
+			}
+			return nil
+		},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName, kubectlArgs := args[0], args[1:]
+			binary, _ := cmd.Flags().GetString("binary")
+
+			binaryPath, err := findKubectl(binary)
+			if err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			kubeconfig, err := spoke.NewKubeconfigExtractor(
+				kubeClient.GetDynamicClient(),
+				kubeClient.GetCoreClient().CoreV1(),
+				clientOptions...,
+			).Extract(ctx, clusterName)
+			if err != nil {
+				return fmt.Errorf("failed to extract kubeconfig: %w", err)
+			}
+
+			kubeconfigPath, cleanup, err := writeTempKubeconfig(clusterName, kubeconfig)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			return runWithKubeconfig(binaryPath, kubectlArgs, kubeconfigPath)
+		},
+	}
+	cmd.Flags().String("binary", "", "Client to run (default: kubectl, or oc if kubectl is not on the PATH)")
+	return cmd
+}
+
+// findKubectl returns the path of binary, or of kubectl or oc if binary is empty
+func findKubectl(binary string) (string, error) {
+	if binary != "" {
+		path, err := exec.LookPath(binary)
+		if err != nil {
+			return "", fmt.Errorf("failed to find %s: %w", binary, err)
+		}
+		return path, nil
+	}
+	for _, candidate := range []string{"kubectl", "oc"} {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("neither kubectl nor oc found on the PATH, install one or set --binary")
+}
+
+// writeTempKubeconfig writes kubeconfig to a temporary file readable only by the current user
+// and returns its path and a function that removes it
+func writeTempKubeconfig(clusterName string, kubeconfig []byte) (string, func(), error) {
+	return writeTempFile("kubeconfig", "labrat-"+clusterName+"-*.kubeconfig", kubeconfig)
+}
+
+// writeTempFile writes data to a temporary file named after pattern and readable only by the
+// current user, and returns its path and a function that removes it; what names the file in
+// errors
+func writeTempFile(what, pattern string, data []byte) (string, func(), error) {
+	// CreateTemp creates the file with mode 0600
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create %s file: %w", what, err)
+	}
+	cleanup := func() { _ = os.Remove(file.Name()) }
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write %s file: %w", what, err)
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write %s file: %w", what, err)
+	}
+	return file.Name(), cleanup, nil
+}
+
+// runWithKubeconfig runs binary with args and $KUBECONFIG set to kubeconfigPath, connected to
+// the standard streams of labrat
+func runWithKubeconfig(binary string, args []string, kubeconfigPath string) error {
+	return runAttached(binary, args, "KUBECONFIG="+kubeconfigPath)
+}
+
+// runAttached runs binary with args and the environment of labrat extended by env, connected
+// to the standard streams of labrat. Interrupts are passed on to the command instead of ending
+// labrat, so temporary files are always removed after the command exits.
+func runAttached(binary string, args []string, env ...string) error {
+	command := exec.Command(binary, args...)
+	command.Env = append(os.Environ(), env...)
+	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := command.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", binary, err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = command.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := command.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// ExitCode is -1 if the command was ended by a signal
+		return &exitCodeError{code: max(exitErr.ExitCode(), 1)}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", binary, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeHealthCmd creates the `spoke health` command
+func newSpokeHealthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "health <cluster-name>",
+		Short: "Check the health of the platform of a spoke cluster",
+		Long: `Check the health of the platform of a spoke cluster using its admin kubeconfig:
+
+  - ClusterOperators are available and not degraded (warns while they are progressing)
+  - nodes are ready (warns about cordoned nodes)
+  - MachineConfigPools are not degraded (warns while they are updating)
+  - no CertificateSigningRequests are pending (warns otherwise)
+  - the certificates of the TLS secrets in openshift-* and kube-* namespaces are not
+    expired (warns about those expiring within --cert-warning)
+
+Each check passes, warns, or fails. The command exits non-zero if any check fails, so it
+can gate CI pipelines that run against lab clusters; warnings do not fail it.
+
+Examples:
+  # Check the health of a cluster
+  labrat spoke health my-cluster
+
+  # Warn about certificates expiring within 30 days and write a JUnit report
+  labrat spoke health my-cluster --cert-warning 720h -o junit > health.xml`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+			certWarning, _ := cmd.Flags().GetDuration("cert-warning")
+
+			_, hubClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			spokeClient, err := newSpokeClient(ctx, hubClient, clusterName)
+			if err != nil {
+				return err
+			}
+
+			checker := spoke.NewHealthChecker(spokeClient.GetCoreClient(), spokeClient.GetDynamicClient(), spoke.HealthOptions{
+				CertWarning: certWarning,
+			}, clientOptions...)
+			report := checker.Run(ctx)
+
+			if err := check.NewWriter(check.OutputFormat(outputFormat), os.Stdout).Write(report); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+
+			return report.Err()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json|junit)")
+	cmd.Flags().Duration("cert-warning", spoke.DefaultCertWarning, "Warn about certificates expiring within this duration")
+	return cmd
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/state"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeKubeconfigCmd creates the `spoke kubeconfig` command
+func newSpokeKubeconfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kubeconfig <cluster-name>",
+		Short: "Extract admin kubeconfig for a spoke cluster",
+		Long: `Extract the admin kubeconfig from a spoke cluster's ClusterDeployment secret.
+
+This command retrieves the admin kubeconfig which has full cluster-admin privileges.
+Use with caution and store securely.
+
+With --merge, the kubeconfig is merged into the kubeconfig kubectl uses (the first path
+of $KUBECONFIG, or ~/.kube/config) or into the file given with -o, as a context, cluster,
+and user named labrat-<cluster-name>. A labrat context of the same cluster is refreshed;
+one pointing to another API server is only replaced with --force.
+
+Kubeconfigs saved with -o or --merge are tracked in ~/.labrat/state.json, so
+'labrat spoke kubeconfig refresh' can overwrite them once their certificates expire.
+With --verify, the expiry of the client certificate and the reachability of the API
+server are checked instead: of the kubeconfig saved at -o, of the labrat context with
+--merge, or otherwise of the kubeconfig stored on the hub.
+
+Examples:
+  # Print kubeconfig to stdout
+  labrat spoke kubeconfig my-cluster
+
+  # Save kubeconfig to file
+  labrat spoke kubeconfig my-cluster -o /tmp/my-cluster.kubeconfig
+
+  # Use the kubeconfig with kubectl
+  labrat spoke kubeconfig my-cluster -o /tmp/kubeconfig
+  kubectl --kubeconfig /tmp/kubeconfig get nodes
+
+  # Merge it into ~/.kube/config as context labrat-my-cluster and switch to it
+  labrat spoke kubeconfig my-cluster --merge --set-current
+
+  # Check whether a saved kubeconfig still works
+  labrat spoke kubeconfig my-cluster -o /tmp/kubeconfig --verify`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputPath, _ := cmd.Flags().GetString("output")
+			merge, _ := cmd.Flags().GetBool("merge")
+			setCurrent, _ := cmd.Flags().GetBool("set-current")
+			force, _ := cmd.Flags().GetBool("force")
+			verify, _ := cmd.Flags().GetBool("verify")
+			if !merge && (setCurrent || force) {
+				return fmt.Errorf("--set-current and --force require --merge")
+			}
+			if merge && outputPath == "" {
+				outputPath = spoke.DefaultKubeconfigPath()
+			}
+
+			if verify && outputPath != "" {
+				// Verify the saved kubeconfig without contacting the hub
+				if _, err := loadConfig(cmd); err != nil {
+					return err
+				}
+				kubeconfig, err := os.ReadFile(outputPath)
+				if err != nil {
+					return fmt.Errorf("failed to read kubeconfig: %w", err)
+				}
+				return writeKubeconfigReport(spoke.VerifyKubeconfig(kubeconfig, spoke.ContextName(clusterName), clientOptions...))
+			}
+
+			// Load config and create Kubernetes client for the selected hub
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Create kubeconfig extractor
+			extractor := spoke.NewKubeconfigExtractor(
+				kubeClient.GetDynamicClient(),
+				kubeClient.GetCoreClient().CoreV1(),
+				clientOptions...,
+			)
+
+			ctx := cmd.Context()
+
+			if verify {
+				kubeconfig, err := extractor.Extract(ctx, clusterName)
+				if err != nil {
+					return fmt.Errorf("failed to extract kubeconfig: %w", err)
+				}
+				return writeKubeconfigReport(spoke.VerifyKubeconfig(kubeconfig, "", clientOptions...))
+			}
+
+			// Display security warning
+			fmt.Fprintf(os.Stderr, "\n⚠️  WARNING: This is an admin kubeconfig with full cluster-admin privileges!\n")
+			fmt.Fprintf(os.Stderr, "    Please store it securely and restrict access appropriately.\n\n")
+
+			if merge {
+				// Merge into an existing kubeconfig
+				kubeconfig, err := extractor.Extract(ctx, clusterName)
+				if err != nil {
+					return fmt.Errorf("failed to extract kubeconfig: %w", err)
+				}
+				if err := spoke.MergeKubeconfig(kubeconfig, clusterName, outputPath, spoke.MergeOptions{SetCurrent: setCurrent, Force: force}); err != nil {
+					return err
+				}
+				recordSavedKubeconfig(clusterName, outputPath, true)
+				contextName := spoke.ContextName(clusterName)
+				fmt.Fprintf(os.Stderr, "✓ Kubeconfig merged into %s as context %s\n", outputPath, contextName)
+				if setCurrent {
+					fmt.Fprintf(os.Stderr, "  Current context switched to %s\n", contextName)
+				} else {
+					fmt.Fprintf(os.Stderr, "  kubectl --context %s get nodes\n", contextName)
+				}
+			} else if outputPath != "" {
+				// Extract to file
+				if err := extractor.ExtractToFile(ctx, clusterName, outputPath); err != nil {
+					return fmt.Errorf("failed to extract kubeconfig: %w", err)
+				}
+				recordSavedKubeconfig(clusterName, outputPath, false)
+				fmt.Fprintf(os.Stderr, "✓ Kubeconfig saved to: %s\n", outputPath)
+				fmt.Fprintf(os.Stderr, "  File permissions set to 0600 (owner read/write only)\n\n")
+				fmt.Fprintf(os.Stderr, "You can now use it with kubectl:\n")
+				fmt.Fprintf(os.Stderr, "  kubectl --kubeconfig %s get nodes\n", outputPath)
+			} else {
+				// Extract to stdout
+				kubeconfig, err := extractor.Extract(ctx, clusterName)
+				if err != nil {
+					return fmt.Errorf("failed to extract kubeconfig: %w", err)
+				}
+				fmt.Print(string(kubeconfig))
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "Output file path, or with --merge the kubeconfig to merge into (default: stdout)")
+	cmd.Flags().Bool("merge", false, "Merge into an existing kubeconfig as context labrat-<cluster-name> (default: $KUBECONFIG or ~/.kube/config)")
+	cmd.Flags().Bool("set-current", false, "With --merge, make the merged context the current context")
+	cmd.Flags().Bool("force", false, "With --merge, replace a context of the same name that points to another API server")
+	cmd.Flags().Bool("verify", false, "Check the client certificate expiry and API server reachability instead of printing the kubeconfig")
+
+	cmd.AddCommand(audited(newSpokeKubeconfigRefreshCmd()))
+	return cmd
+}
+
+// newSpokeKubeconfigRefreshCmd creates the `spoke kubeconfig refresh` command
+func newSpokeKubeconfigRefreshCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh [cluster-name...]",
+		Short: "Re-extract the saved kubeconfigs of spoke clusters",
+		Long: `Re-extract the admin kubeconfigs of spoke clusters from the hub and overwrite every
+kubeconfig previously saved with 'labrat spoke kubeconfig -o' or merged with --merge,
+as tracked in ~/.labrat/state.json. Use it when the certificates of saved kubeconfigs
+have expired or the cluster was reinstalled. Saved files that no longer exist are
+skipped.
+
+Examples:
+  # Refresh the saved kubeconfigs of a cluster
+  labrat spoke kubeconfig refresh my-cluster
+
+  # Refresh every saved kubeconfig
+  labrat spoke kubeconfig refresh --all`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+			if all == (len(args) > 0) {
+				return fmt.Errorf("specify cluster names or --all")
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+			store := state.NewStore(config.ExpandPath(state.DefaultFile))
+			saved, err := store.Load()
+			if err != nil {
+				return err
+			}
+			clusterNames := args
+			if all {
+				clusterNames = saved.Clusters()
+			}
+			if len(clusterNames) == 0 {
+				fmt.Fprintln(os.Stderr, "No saved kubeconfigs to refresh")
+				return nil
+			}
+
+			extractor := spoke.NewKubeconfigExtractor(
+				kubeClient.GetDynamicClient(),
+				kubeClient.GetCoreClient().CoreV1(),
+				clientOptions...,
+			)
+			ctx := cmd.Context()
+
+			var refreshed []state.SavedKubeconfig
+			failed := 0
+			for _, clusterName := range clusterNames {
+				kubeconfigs := saved.KubeconfigsOf(clusterName)
+				if len(kubeconfigs) == 0 {
+					fmt.Fprintf(os.Stderr, "⚠️  %s: no saved kubeconfigs; save one with labrat spoke kubeconfig %s -o <file>\n", clusterName, clusterName)
+					failed++
+					continue
+				}
+				kubeconfig, err := extractor.Extract(ctx, clusterName)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  %s: failed to extract kubeconfig: %v\n", clusterName, err)
+					failed++
+					continue
+				}
+
+				for _, entry := range kubeconfigs {
+					if _, err := os.Stat(entry.Path); err != nil {
+						fmt.Fprintf(os.Stderr, "⚠️  %s: skipping %s: %v\n", clusterName, entry.Path, err)
+						continue
+					}
+					if entry.Merged {
+						// The context is labrat's own, so it follows the cluster to a new API server
+						err = spoke.MergeKubeconfig(kubeconfig, clusterName, entry.Path, spoke.MergeOptions{Force: true})
+					} else {
+						err = spoke.WriteKubeconfig(kubeconfig, entry.Path)
+					}
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "⚠️  %s: %v\n", clusterName, err)
+						failed++
+						continue
+					}
+					entry.SavedAt = time.Now().UTC()
+					refreshed = append(refreshed, entry)
+					fmt.Fprintf(os.Stderr, "✓ Refreshed the kubeconfig of %s in %s\n", clusterName, entry.Path)
+				}
+			}
+
+			if err := store.Update(func(s *state.State) {
+				for _, entry := range refreshed {
+					s.RecordKubeconfig(entry)
+				}
+			}); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to refresh %d kubeconfig(s)", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("all", false, "Refresh the saved kubeconfigs of every cluster")
+	return cmd
+}
+
+// recordSavedKubeconfig tracks a kubeconfig saved at path in the state file, so it can be
+// refreshed later. A failure is reported on stderr, as the kubeconfig has been saved.
+func recordSavedKubeconfig(clusterName, path string, merged bool) {
+	if err := trackSavedKubeconfig(clusterName, path, merged); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+}
+
+// trackSavedKubeconfig records a kubeconfig saved at path in the state file
+func trackSavedKubeconfig(clusterName, path string, merged bool) error {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+	store := state.NewStore(config.ExpandPath(state.DefaultFile))
+	err := store.Update(func(s *state.State) {
+		s.RecordKubeconfig(state.SavedKubeconfig{Cluster: clusterName, Path: path, Merged: merged, SavedAt: time.Now().UTC()})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to track the saved kubeconfig for refresh: %w", err)
+	}
+	return nil
+}
+
+// writeKubeconfigReport prints the checks of a kubeconfig and fails if any check failed
+func writeKubeconfigReport(report check.Report) error {
+	if err := check.NewWriter(check.OutputFormatTable, os.Stdout).Write(report); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return report.Err()
+}
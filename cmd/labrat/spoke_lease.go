@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+)
+
+// newSpokeLeaseCmd creates the `spoke lease` command
+func newSpokeLeaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lease",
+		Short: "Set or clear the lease of spoke clusters",
+		Long: `Manage the lease of spoke clusters. A lease is the date a cluster is due to be
+reclaimed, recorded as an annotation on its ClusterDeployment. labrat never acts on an
+expired lease by itself: list leases with labrat hub leases and reclaim expired
+clusters with labrat spoke hibernate --expired-only or labrat spoke delete --expired-only.`,
+	}
+	cmd.AddCommand(audited(newSpokeLeaseSetCmd()), audited(newSpokeLeaseClearCmd()))
+	return cmd
+}
+
+// newSpokeLeaseSetCmd creates the `spoke lease set` command
+func newSpokeLeaseSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <cluster-name>",
+		Short: "Set the lease of a spoke cluster",
+		Long: `Set the lease of a spoke cluster to end --duration from now, replacing any
+existing lease. The duration accepts days and weeks (14d, 2w) as well as Go durations
+(36h).
+
+Examples:
+  # Lease a cluster for two weeks
+  labrat spoke lease set my-cluster --duration 14d
+
+  # Extend a lease by setting a new one
+  labrat spoke lease set my-cluster --duration 3d`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			durationValue, _ := cmd.Flags().GetString("duration")
+
+			duration, err := hub.ParseLeaseDuration(durationValue)
+			if err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			expiresAt := time.Now().Add(duration).Truncate(time.Second)
+			leases := hub.NewLeaseClient(kubeClient.GetDynamicClient(), clientOptions...)
+			if err := leases.Set(cmd.Context(), clusterName, expiresAt); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Lease of %s ends %s\n", clusterName, expiresAt.Format(time.RFC3339))
+			return nil
+		},
+	}
+	cmd.Flags().String("duration", "", "Length of the lease, e.g. 14d, 2w, or 36h")
+	_ = cmd.MarkFlagRequired("duration")
+	return cmd
+}
+
+// newSpokeLeaseClearCmd creates the `spoke lease clear` command
+func newSpokeLeaseClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear <cluster-name>",
+		Short: "Remove the lease of a spoke cluster so it never expires",
+		Long: `Remove the lease of a spoke cluster so it never expires.
+
+Examples:
+  # Keep a cluster indefinitely
+  labrat spoke lease clear my-cluster`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			leases := hub.NewLeaseClient(kubeClient.GetDynamicClient(), clientOptions...)
+			if err := leases.Clear(cmd.Context(), clusterName); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ Lease of %s cleared\n", clusterName)
+			return nil
+		},
+	}
+}
+
+// expiredOnlyArgs accepts no cluster names when --expired-only is set or the clusters are
+// selected by flags, and otherwise requires at least one
+func expiredOnlyArgs(cmd *cobra.Command, args []string) error {
+	if expiredOnly, _ := cmd.Flags().GetBool("expired-only"); expiredOnly {
+		return nil
+	}
+	return targetArgs(1)(cmd, args)
+}
+
+// expiredClusters returns the clusters whose lease has ended. Without names every expired
+// cluster is returned; otherwise names is returned if all of them are expired.
+func expiredClusters(ctx context.Context, dynamicClient dynamic.Interface, names []string) ([]string, error) {
+	leases, err := hub.NewLeaseClient(dynamicClient, clientOptions...).List(ctx)
+	if err := warnDecodeErrors(err); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expired := make(map[string]bool, len(leases))
+	var all []string
+	for _, lease := range leases {
+		if lease.Expired(now) {
+			expired[lease.Cluster] = true
+			all = append(all, lease.Cluster)
+		}
+	}
+
+	if len(names) == 0 {
+		return all, nil
+	}
+	for _, name := range names {
+		if !expired[name] {
+			return nil, fmt.Errorf("lease of cluster %s has not expired", name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeLogsCmd creates the `spoke logs` command group
+func newSpokeLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the Hive logs of spoke clusters",
+	}
+	cmd.AddCommand(newSpokeLogsProvisionCmd())
+	return cmd
+}
+
+// newSpokeLogsProvisionCmd creates the `spoke logs provision` command
+func newSpokeLogsProvisionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "provision <cluster-name>",
+		Short: "Show the installer log of a spoke cluster",
+		Long: `Show the installer log of the latest Hive install attempt of a spoke cluster. The
+install pod is located through the ClusterProvision of the attempt in the cluster
+namespace on the hub, so there is no need to look up its name.
+
+Once Hive has cleaned up the install pod, the end of the installer log that Hive saved
+in the ClusterProvision is shown instead.
+
+Examples:
+  # Show the installer log of a cluster
+  labrat spoke logs provision my-cluster
+
+  # Follow the install as it runs, starting from the last 100 lines
+  labrat spoke logs provision my-cluster --follow --tail 100`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			follow, _ := cmd.Flags().GetBool("follow")
+			tail, _ := cmd.Flags().GetInt("tail")
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			err = spoke.NewProvisionLogReader(kubeClient.GetDynamicClient(), kubeClient.GetCoreClient(), clientOptions...).
+				Stream(ctx, clusterName, spoke.ProvisionLogOptions{Follow: follow, TailLines: tail}, os.Stdout)
+			if errors.Is(err, spoke.ErrNoInstallPod) {
+				return fmt.Errorf("%w; Hive has not started installing %s, or cleaned up the install pod without saving its log", err, clusterName)
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		},
+	}
+	cmd.Flags().BoolP("follow", "f", false, "Stream the log until the install pod ends")
+	cmd.Flags().Int("tail", -1, "Number of lines to show from the end of the log (-1 for all)")
+	return cmd
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeMachinePoolsCmd creates the `spoke machinepools` command
+func newSpokeMachinePoolsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "machinepools",
+		Short: "List the machine pools of spoke clusters and change their autoscaling",
+	}
+	cmd.AddCommand(newSpokeMachinePoolsListCmd(), audited(newSpokeMachinePoolsAutoscaleCmd()))
+	return cmd
+}
+
+// newSpokeMachinePoolsListCmd creates the `spoke machinepools list` command
+func newSpokeMachinePoolsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list <cluster-name>",
+		Short: "List the machine pools of a spoke cluster",
+		Long: `List the Hive MachinePools of a spoke cluster with their instance type, their desired
+and current replicas, and the bounds the cluster autoscaler sizes autoscaled pools in.
+
+Examples:
+  # List the machine pools of a cluster
+  labrat spoke machinepools list my-cluster
+
+  # List the names of the autoscaled pools
+  labrat spoke machinepools list my-cluster -o jsonpath='{range .[?(@.maxReplicas)]}{.name}{"\n"}{end}'`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			pools, err := spoke.NewMachinePoolClient(kubeClient.GetDynamicClient(), clientOptions...).List(cmd.Context(), clusterName)
+			if err != nil {
+				return err
+			}
+			if written, err := writeListOutput(outputFormat, pools); written {
+				return err
+			}
+
+			if len(pools) == 0 {
+				fmt.Fprintf(os.Stdout, "No MachinePools found for %s\n", clusterName)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "POOL\tINSTANCE TYPE\tDESIRED\tCURRENT\tMIN\tMAX\n")
+			for _, p := range pools {
+				desired, minReplicas, maxReplicas := strconv.FormatInt(p.Replicas, 10), "N/A", "N/A"
+				if p.Autoscaled() {
+					desired = "-"
+					minReplicas = strconv.FormatInt(p.MinReplicas, 10)
+					maxReplicas = strconv.FormatInt(p.MaxReplicas, 10)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", p.Name, valueOrNA(p.InstanceType), desired, p.CurrentReplicas, minReplicas, maxReplicas)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	return cmd
+}
+
+// newSpokeMachinePoolsAutoscaleCmd creates the `spoke machinepools autoscale` command
+func newSpokeMachinePoolsAutoscaleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "autoscale <cluster-name>",
+		Short: "Set the autoscaling bounds of a machine pool of a spoke cluster",
+		Long: `Let the cluster autoscaler size a Hive MachinePool of a spoke cluster between --min and
+--max machines, replacing its fixed replicas. Hive creates the MachineAutoscalers of the
+pool, and a ClusterAutoscaler if the cluster has none, asynchronously. Running the
+command on an autoscaled pool changes its bounds.
+
+With --disable the pool is set back to a fixed number of machines: --replicas, or the
+number of machines it currently has. Fixed pools are resized with labrat spoke scale.
+
+Examples:
+  # Autoscale the worker pool between 3 and 9 machines
+  labrat spoke machinepools autoscale my-cluster --pool worker --min 3 --max 9
+
+  # Let a GPU pool scale down to no machines
+  labrat spoke machinepools autoscale my-cluster --pool gpu-small --min 0 --max 3
+
+  # Keep the worker pool at its current size
+  labrat spoke machinepools autoscale my-cluster --pool worker --disable`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			pool, _ := cmd.Flags().GetString("pool")
+			minReplicas, _ := cmd.Flags().GetInt64("min")
+			maxReplicas, _ := cmd.Flags().GetInt64("max")
+			disable, _ := cmd.Flags().GetBool("disable")
+			replicas, _ := cmd.Flags().GetInt64("replicas")
+
+			boundsSet := cmd.Flags().Changed("min") || cmd.Flags().Changed("max")
+			switch {
+			case disable && boundsSet:
+				return fmt.Errorf("--disable cannot be combined with --min and --max")
+			case !disable && cmd.Flags().Changed("replicas"):
+				return fmt.Errorf("--replicas requires --disable")
+			case !disable && (!cmd.Flags().Changed("min") || !cmd.Flags().Changed("max")):
+				return fmt.Errorf("--min and --max are required unless --disable is given")
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			pools := spoke.NewMachinePoolClient(kubeClient.GetDynamicClient(), clientOptions...)
+
+			if !disable {
+				if err := pools.Autoscale(ctx, clusterName, pool, minReplicas, maxReplicas); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "✓ MachinePool %s of %s autoscaled between %d and %d replicas\n", pool, clusterName, minReplicas, maxReplicas)
+				return nil
+			}
+
+			if !cmd.Flags().Changed("replicas") {
+				list, err := pools.List(ctx, clusterName)
+				if err != nil {
+					return err
+				}
+				for _, p := range list {
+					if p.Name == pool {
+						replicas = max(p.CurrentReplicas, p.MinReplicas)
+					}
+				}
+			}
+			if err := pools.DisableAutoscaling(ctx, clusterName, pool, replicas); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ MachinePool %s of %s no longer autoscaled, set to %d replicas\n", pool, clusterName, replicas)
+			return nil
+		},
+	}
+	cmd.Flags().String("pool", "worker", "Name of the machine pool")
+	cmd.Flags().Int64("min", 0, "Minimum number of machines in the pool")
+	cmd.Flags().Int64("max", 0, "Maximum number of machines in the pool")
+	cmd.Flags().Bool("disable", false, "Stop autoscaling the pool, keeping a fixed number of machines")
+	cmd.Flags().Int64("replicas", 0, "With --disable, number of machines in the pool (defaults to its current machines)")
+	return cmd
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/spf13/cobra"
+)
+
+// newSpokePauseReconcileCmd creates the `spoke pause-reconcile` command
+func newSpokePauseReconcileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause-reconcile <cluster-name>",
+		Short: "Put a spoke cluster in maintenance so Hive stops reconciling it",
+		Long: `Put a spoke cluster in maintenance before changing it by hand. The ClusterDeployment
+is annotated with hive.openshift.io/reconcile-pause, so Hive does not undo the changes,
+and the ManagedCluster is labeled labrat.openshift-partner-labs.io/maintenance=true, so
+other operators see the cluster is in maintenance: list commands show its status as
+Ready,Maintenance. Imported clusters, which have no ClusterDeployment, are only labeled.
+
+End the maintenance with labrat spoke resume-reconcile.
+
+Examples:
+  # Pause Hive before a manual change
+  labrat spoke pause-reconcile my-cluster --reason "rotating the ingress certificate"
+
+  # Hibernate the clusters that are not in maintenance
+  labrat spoke hibernate --selector '!labrat.openshift-partner-labs.io/maintenance' --yes`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			reason, _ := cmd.Flags().GetString("reason")
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			maintenance := hub.NewMaintenanceClient(kubeClient.GetDynamicClient(), clientOptions...)
+			change, err := maintenance.Start(cmd.Context(), clusterName, reason)
+			if err != nil {
+				return err
+			}
+			if !change.ClusterDeployment {
+				fmt.Fprintf(os.Stderr, "⚠️  %s has no ClusterDeployment; only the ManagedCluster was labeled\n", clusterName)
+			}
+			fmt.Fprintf(os.Stderr, "✓ %s is in maintenance\n", clusterName)
+			return nil
+		},
+	}
+	cmd.Flags().String("reason", "", "Why the cluster is in maintenance, recorded on the ManagedCluster")
+	return cmd
+}
+
+// newSpokeResumeReconcileCmd creates the `spoke resume-reconcile` command
+func newSpokeResumeReconcileCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume-reconcile <cluster-name>",
+		Short: "End the maintenance of a spoke cluster so Hive reconciles it again",
+		Long: `End the maintenance of a spoke cluster: remove the reconcile-pause annotation of its
+ClusterDeployment, so Hive reconciles it again, and the maintenance label and reason of
+its ManagedCluster.
+
+Examples:
+  # Hand a cluster back to Hive after a manual change
+  labrat spoke resume-reconcile my-cluster`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			maintenance := hub.NewMaintenanceClient(kubeClient.GetDynamicClient(), clientOptions...)
+			if _, err := maintenance.End(cmd.Context(), clusterName); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✓ %s is out of maintenance\n", clusterName)
+			return nil
+		},
+	}
+}
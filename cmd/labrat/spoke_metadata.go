@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeLabelCmd creates the `spoke label` command
+func newSpokeLabelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "label <cluster-name...> key=value... [key-...]",
+		Short: "Set or remove labels of spoke clusters",
+		Long: `Set or remove labels of a spoke cluster's ManagedCluster, like kubectl label.
+key=value sets a label and key- removes it. An existing label is only replaced with
+--overwrite. With --cluster-deployment the ClusterDeployment of the cluster is labeled
+too; the labels of neither resource change if one of them rejects the changes.
+
+Labels are what partner and owner filtering works with, e.g. in ACM placements and
+kubectl get managedclusters -l.
+
+Several clusters can be named before the changes, or selected with --selector,
+--clusterset, or --all; they are changed in parallel, bounded by --concurrency, with a
+summary of per-cluster results at the end. Selected clusters are listed for
+confirmation first; pass --yes to skip it. With --plan the current and new value of each
+changed label are saved to a plan file for review, and only changed by labrat apply.
+
+Examples:
+  # Label a cluster with its partner and owner
+  labrat spoke label my-cluster partner=acme owner=jdoe
+
+  # Hand the cluster over to another owner
+  labrat spoke label my-cluster owner=asmith --overwrite
+
+  # Remove a label from the ManagedCluster and the ClusterDeployment
+  labrat spoke label my-cluster owner- --cluster-deployment
+
+  # Hand every cluster of a partner over to another owner
+  labrat spoke label --selector partner=acme owner=asmith --overwrite
+
+  # Plan the hand-over and review it first
+  labrat spoke label --selector partner=acme owner=asmith --overwrite --plan handover.yaml`,
+		Args:         targetArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSpokeMetadata(cmd, args, spoke.MetadataLabels)
+		},
+	}
+	addMetadataFlags(cmd)
+	return cmd
+}
+
+// newSpokeAnnotateCmd creates the `spoke annotate` command
+func newSpokeAnnotateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "annotate <cluster-name...> key=value... [key-...]",
+		Short: "Set or remove annotations of spoke clusters",
+		Long: `Set or remove annotations of a spoke cluster's ManagedCluster, like kubectl
+annotate. key=value sets an annotation and key- removes it. An existing annotation is
+only replaced with --overwrite. With --cluster-deployment the ClusterDeployment of the
+cluster is annotated too. Several clusters are selected like for labrat spoke label.
+
+Examples:
+  # Note what a cluster is used for
+  labrat spoke annotate my-cluster labrat.io/purpose="Partner demo"
+
+  # Remove the note again
+  labrat spoke annotate my-cluster labrat.io/purpose-`,
+		Args:         targetArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSpokeMetadata(cmd, args, spoke.MetadataAnnotations)
+		},
+	}
+	addMetadataFlags(cmd)
+	return cmd
+}
+
+// addMetadataFlags registers the flags shared by spoke label and spoke annotate
+func addMetadataFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("overwrite", false, "Replace the existing values of keys")
+	cmd.Flags().Bool("cluster-deployment", false, "Also change the ClusterDeployment of the cluster")
+	addBatchFlags(cmd)
+	addTargetFlags(cmd)
+	addPlanFlag(cmd)
+}
+
+// runSpokeMetadata applies the key=value and key- arguments to the labels or annotations of
+// the clusters named before them, or of the clusters selected by flags
+func runSpokeMetadata(cmd *cobra.Command, args []string, kind spoke.MetadataKind) error {
+	overwrite, _ := cmd.Flags().GetBool("overwrite")
+	clusterDeployment, _ := cmd.Flags().GetBool("cluster-deployment")
+
+	// Cluster names contain neither = nor end with -, so the changes start at the first
+	// argument that does
+	split := len(args)
+	for i, arg := range args {
+		if strings.Contains(arg, "=") || strings.HasSuffix(arg, "-") {
+			split = i
+			break
+		}
+	}
+	clusterNames, changeArgs := args[:split], args[split:]
+	if len(clusterNames) == 0 && !hasTargetFlags(cmd) {
+		return fmt.Errorf("no cluster given, name the clusters before the changes or select them with --selector, --clusterset, or --all")
+	}
+	if len(changeArgs) == 0 {
+		return fmt.Errorf("no %s to change, pass key=value or key-", kind)
+	}
+	changes, err := spoke.ParseMetadataChanges(kind, changeArgs)
+	if err != nil {
+		return err
+	}
+
+	batch, err := batchOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, kubeClient, err := newHubClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if hasTargetFlags(cmd) {
+		if clusterNames, err = resolveTargets(ctx, cmd, kubeClient, clusterNames); err != nil {
+			return err
+		}
+		if len(clusterNames) == 0 {
+			fmt.Fprintln(os.Stderr, "No clusters selected")
+			return nil
+		}
+		if planPath(cmd) == "" {
+			if err := confirmTargets(cmd, fmt.Sprintf("change the %s of", kind), clusterNames); err != nil {
+				return err
+			}
+		}
+	}
+
+	editor := spoke.NewMetadataEditor(kubeClient.GetDynamicClient(), clientOptions...)
+	opts := spoke.MetadataOptions{Overwrite: overwrite, ClusterDeployment: clusterDeployment}
+	if planPath(cmd) != "" {
+		return savePlan(ctx, cmd, cfg, clusterNames, changeArgs, func(ctx context.Context, clusterName string) (string, error) {
+			current, err := editor.Current(ctx, clusterName, kind)
+			if err != nil {
+				return "", err
+			}
+			return spoke.DescribeMetadataChanges(current, changes, overwrite), nil
+		})
+	}
+	if len(clusterNames) == 1 {
+		if err := editor.Apply(ctx, clusterNames[0], kind, changes, opts); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✓ Updated %d %s of %s\n", len(changes), kind, clusterNames[0])
+		return nil
+	}
+	results := fleet.NewRunner(batch).Run(ctx, clusterNames, func(ctx context.Context, clusterName string) error {
+		return editor.Apply(ctx, clusterName, kind, changes, opts)
+	})
+	return reportBatch(results)
+}
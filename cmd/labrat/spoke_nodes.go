@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// newSpokeNodesCmd creates the `spoke nodes` command
+func newSpokeNodesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nodes <cluster-name>",
+		Short: "Show the node status of a spoke cluster",
+		Long: `List the nodes of a spoke cluster with their status, roles, kubelet version, and
+cloud instance type, using the admin kubeconfig extracted from the hub. Useful to verify
+a freshly provisioned cluster before handing it to a partner.
+
+The number of ready nodes is printed to stderr; the command fails if a node is not ready.
+
+Examples:
+  # Show the nodes of a cluster
+  labrat spoke nodes my-cluster
+
+  # Show the nodes as JSON
+  labrat spoke nodes my-cluster -o json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if err := validateListOutput(outputFormat); err != nil {
+				return err
+			}
+
+			_, hubClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			spokeClient, err := newSpokeClient(ctx, hubClient, clusterName)
+			if err != nil {
+				return err
+			}
+
+			nodes, err := spoke.NewNodeLister(spokeClient.GetCoreClient(), clientOptions...).List(ctx)
+			if err != nil {
+				return err
+			}
+
+			written, err := writeListOutput(outputFormat, nodes)
+			if err != nil {
+				return err
+			}
+			if !written {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+				fmt.Fprintln(w, "NAME\tSTATUS\tROLES\tVERSION\tINSTANCE TYPE\tAGE")
+				for _, node := range nodes {
+					roles := strings.Join(node.Roles, ",")
+					if roles == "" {
+						roles = "<none>"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", node.Name, node.Status, roles, node.Version,
+						valueOrNA(node.InstanceType), duration.HumanDuration(time.Since(node.CreatedAt)))
+				}
+				if err := w.Flush(); err != nil {
+					return fmt.Errorf("failed to write output: %w", err)
+				}
+			}
+
+			ready := 0
+			for _, node := range nodes {
+				if node.Ready() {
+					ready++
+				}
+			}
+			if ready < len(nodes) {
+				fmt.Fprintf(os.Stderr, "\n⚠️  %d/%d nodes ready\n", ready, len(nodes))
+				return fmt.Errorf("%d nodes of cluster %s are not ready", len(nodes)-ready, clusterName)
+			}
+			fmt.Fprintf(os.Stderr, "\n✓ %d/%d nodes ready\n", ready, len(nodes))
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", listOutputHelp)
+	return cmd
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeHibernateCmd creates the `spoke hibernate` command
+func newSpokeHibernateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hibernate <cluster-name> [cluster-name...]",
+		Short: "Hibernate one or more spoke clusters",
+		Long: `Hibernate spoke clusters by setting spec.powerState=Hibernating on their ClusterDeployment.
+
+Multiple clusters are processed in parallel, bounded by --concurrency. If the hub
+starts throttling requests, labrat automatically lowers the number of in-flight
+requests and retries with backoff. A summary of per-cluster results is printed at
+the end and the command exits non-zero if any cluster failed.
+
+Instead of naming them, clusters can be selected by the labels of their ManagedCluster
+with --selector, by ManagedClusterSet with --clusterset, or all at once with --all. The
+selected clusters are listed for confirmation first; pass --yes to skip it.
+
+With --plan nothing is changed: the selected clusters and the power state change of each
+are saved to a plan file instead, to be reviewed and then run with labrat apply.
+
+With --expired-only only clusters whose lease has expired are hibernated (see labrat
+spoke lease). Without cluster names every cluster with an expired lease is hibernated.
+
+Examples:
+  # Hibernate a single cluster
+  labrat spoke hibernate my-cluster
+
+  # Hibernate several clusters, at most 10 at a time
+  labrat spoke hibernate cluster-a cluster-b cluster-c --concurrency 10
+
+  # Stop at the first failure instead of processing every cluster
+  labrat spoke hibernate cluster-a cluster-b --continue-on-error=false
+
+  # Hibernate every cluster of a partner without asking
+  labrat spoke hibernate --selector partner=acme --yes
+
+  # Hibernate every cluster whose lease has expired
+  labrat spoke hibernate --expired-only
+
+  # Plan to hibernate every cluster, then review and apply the plan
+  labrat spoke hibernate --all --plan hibernate.yaml
+  labrat apply hibernate.yaml`,
+		Args: expiredOnlyArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expiredOnly, _ := cmd.Flags().GetBool("expired-only")
+			return runSetPowerState(cmd, args, spoke.PowerStateHibernating, expiredOnly)
+		},
+	}
+	addBatchFlags(cmd)
+	addTargetFlags(cmd)
+	addPlanFlag(cmd)
+	cmd.Flags().Bool("expired-only", false, "Only hibernate clusters whose lease has expired; without cluster names, hibernate all of them")
+	return cmd
+}
+
+// newSpokeResumeCmd creates the `spoke resume` command
+func newSpokeResumeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume <cluster-name> [cluster-name...]",
+		Short: "Resume one or more hibernating spoke clusters",
+		Long: `Resume spoke clusters by setting spec.powerState=Running on their ClusterDeployment.
+
+Multiple clusters are processed in parallel, bounded by --concurrency. Clusters can
+be selected with --selector, --clusterset, or --all, and the changes saved for labrat
+apply with --plan, like for labrat spoke hibernate.
+
+Examples:
+  # Resume a single cluster
+  labrat spoke resume my-cluster
+
+  # Resume several clusters one at a time
+  labrat spoke resume cluster-a cluster-b --concurrency 1
+
+  # Resume the clusters of a cluster set
+  labrat spoke resume --clusterset partner-acme`,
+		Args: targetArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSetPowerState(cmd, args, spoke.PowerStateRunning, false)
+		},
+	}
+	addBatchFlags(cmd)
+	addTargetFlags(cmd)
+	addPlanFlag(cmd)
+	return cmd
+}
+
+// addBatchFlags registers the flags shared by batch commands
+func addBatchFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("concurrency", fleet.DefaultConcurrency, "Maximum number of clusters processed in parallel")
+	cmd.Flags().Bool("continue-on-error", true, "Keep processing remaining clusters after a failure")
+}
+
+// batchOptions builds batch options from the flags of a batch command
+func batchOptions(cmd *cobra.Command) (fleet.Options, error) {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
+	if concurrency < 1 {
+		return fleet.Options{}, fmt.Errorf("--concurrency must be at least 1, got %d", concurrency)
+	}
+	return fleet.Options{
+		Concurrency: concurrency,
+		FailFast:    !continueOnError,
+		Logger:      logger,
+	}, nil
+}
+
+// runSetPowerState applies the requested power state to every cluster in clusterNames, or to
+// the clusters selected by flags. With expiredOnly the clusters are restricted to those whose
+// lease has expired.
+func runSetPowerState(cmd *cobra.Command, clusterNames []string, state string, expiredOnly bool) error {
+	opts, err := batchOptions(cmd)
+	if err != nil {
+		return err
+	}
+	if expiredOnly && hasTargetFlags(cmd) {
+		return fmt.Errorf("--expired-only cannot be combined with --selector, --clusterset, or --all")
+	}
+
+	cfg, kubeClient, err := newHubClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if expiredOnly {
+		clusterNames, err = expiredClusters(ctx, kubeClient.GetDynamicClient(), clusterNames)
+		if err != nil {
+			return err
+		}
+		if len(clusterNames) == 0 {
+			fmt.Fprintln(os.Stderr, "No clusters with an expired lease")
+			return nil
+		}
+	} else if hasTargetFlags(cmd) {
+		if clusterNames, err = resolveTargets(ctx, cmd, kubeClient, clusterNames); err != nil {
+			return err
+		}
+		if len(clusterNames) == 0 {
+			fmt.Fprintln(os.Stderr, "No clusters selected")
+			return nil
+		}
+		action := "resume"
+		if state == spoke.PowerStateHibernating {
+			action = "hibernate"
+		}
+		if planPath(cmd) == "" {
+			if err := confirmTargets(cmd, action, clusterNames); err != nil {
+				return err
+			}
+		}
+	}
+
+	if planPath(cmd) != "" {
+		describe, err := describePowerState(ctx, kubeClient, state)
+		if err != nil {
+			return err
+		}
+		return savePlan(ctx, cmd, cfg, clusterNames, nil, describe)
+	}
+
+	power := spoke.NewPowerManager(kubeClient.GetDynamicClient(), clientOptions...)
+
+	results := fleet.NewRunner(opts).Run(ctx, clusterNames, func(ctx context.Context, name string) error {
+		return power.SetPowerState(ctx, name, state)
+	})
+
+	return reportBatch(results)
+}
+
+// reportBatch prints the per-cluster summary of a batch run and returns an error if any cluster failed
+func reportBatch(results []fleet.Result) error {
+	if err := fleet.WriteSummary(os.Stdout, results); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return fleet.Summarize(results).Err()
+}
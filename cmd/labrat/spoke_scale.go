@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeScaleCmd creates the `spoke scale` command
+func newSpokeScaleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scale <cluster-name>",
+		Short: "List or resize the machine pools of a spoke cluster",
+		Long: `Resize a Hive MachinePool of a spoke cluster by setting its replicas. Hive
+reconciles the change onto the cluster's MachineSets asynchronously.
+
+Without --replicas the MachinePools of the cluster are listed with their desired and
+current replicas. Autoscaled pools cannot be scaled this way, as the autoscaler
+sizes them between their minimum and maximum replicas.
+
+Examples:
+  # List the machine pools of a cluster
+  labrat spoke scale my-cluster
+
+  # Resize the worker pool to 5 machines
+  labrat spoke scale my-cluster --pool worker --replicas 5`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			pool, _ := cmd.Flags().GetString("pool")
+			replicas, _ := cmd.Flags().GetInt64("replicas")
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			pools := spoke.NewMachinePoolClient(kubeClient.GetDynamicClient(), clientOptions...)
+
+			if cmd.Flags().Changed("replicas") {
+				if err := pools.Scale(ctx, clusterName, pool, replicas); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "✓ MachinePool %s of %s scaled to %d replicas\n", pool, clusterName, replicas)
+				return nil
+			}
+
+			list, err := pools.List(ctx, clusterName)
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(list, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+				return nil
+			}
+
+			if len(list) == 0 {
+				fmt.Fprintf(os.Stdout, "No MachinePools found for %s\n", clusterName)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "POOL\tDESIRED\tCURRENT\tAUTOSCALING\tINSTANCE TYPE\n")
+			for _, p := range list {
+				desired := strconv.FormatInt(p.Replicas, 10)
+				autoscaling := "N/A"
+				if p.Autoscaled() {
+					desired = "-"
+					autoscaling = fmt.Sprintf("%d-%d", p.MinReplicas, p.MaxReplicas)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", p.Name, desired, p.CurrentReplicas, autoscaling, valueOrNA(p.InstanceType))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().String("pool", "worker", "Name of the machine pool to scale")
+	cmd.Flags().Int64("replicas", 0, "Number of machines in the pool")
+	cmd.Flags().StringP("output", "o", "table", "Output format of the pool list (table|json)")
+	return cmd
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeSmokeCmd creates the `spoke smoke` command
+func newSpokeSmokeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "smoke <cluster-name>",
+		Short: "Run quick functional smoke tests against a spoke cluster",
+		Long: `Run a curated set of quick checks against a spoke cluster using its admin kubeconfig:
+
+  - create and delete a temporary namespace
+  - run a pod that pulls a test image
+  - resolve the kubernetes service name from inside the pod (cluster DNS)
+  - request the ingress canary route (default ingress controller)
+
+The command exits non-zero if any check fails, so it can be used as a gate before
+handing a lab cluster to a partner.
+
+Examples:
+  # Smoke test a cluster
+  labrat spoke smoke my-cluster
+
+  # Use a mirrored test image and write a JUnit report
+  labrat spoke smoke my-cluster --image registry.example.com/ubi9/ubi:latest -o junit`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+			image, _ := cmd.Flags().GetString("image")
+			timeout, _ := cmd.Flags().GetDuration("pod-timeout")
+
+			_, hubClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			spokeClient, err := newSpokeClient(ctx, hubClient, clusterName)
+			if err != nil {
+				return err
+			}
+
+			tester := spoke.NewSmokeTester(spokeClient.GetCoreClient(), spokeClient.GetDynamicClient(), spoke.SmokeOptions{
+				Image:   image,
+				Timeout: timeout,
+			}, clientOptions...)
+			report := tester.Run(ctx)
+
+			if err := check.NewWriter(check.OutputFormat(outputFormat), os.Stdout).Write(report); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+
+			return report.Err()
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json|junit)")
+	cmd.Flags().String("image", spoke.DefaultSmokeImage, "Container image used for the test pod")
+	cmd.Flags().Duration("pod-timeout", spoke.DefaultSmokeTimeout, "Maximum time to wait for the test pod to complete")
+	return cmd
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newSpokeSSHCmd creates the `spoke ssh` command
+func newSpokeSSHCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ssh <cluster-name> <node-name> [-- <command...>]",
+		Short: "Open an SSH session to a node of a spoke cluster",
+		Long: `Open an SSH session to a node of a spoke cluster as the core user, with the SSH key
+Hive installed the cluster with, or run a command on the node.
+
+The private key is read from the hub (see labrat hub sshkeys list), written to a
+temporary file readable only by the current user, and removed when ssh exits. The
+address of the node is read from the spoke: nodes without a public address are
+reached through a bastion, given with --bastion or recorded on the ClusterDeployment:
+
+  labrat spoke annotate my-cluster --cluster-deployment \
+    labrat.openshift-partner-labs.io/ssh-bastion=core@bastion.acme.example
+
+ssh authenticates to the bastion with the keys of the current user, e.g. from the SSH
+agent. Node host keys are trusted on first use and checked afterwards against the
+known_hosts file of the cluster, in the known_hosts directory of the labrat cache
+(~/.labrat/cache/known_hosts/<cluster-name>). A replaced node may reuse the address of
+an old one with a new host key: remove its line from that file, or skip the check with
+--insecure-skip-host-key-check. ssh must be on the PATH; labrat exits with its exit code.
+
+Examples:
+  # Open a shell on a node
+  labrat spoke ssh my-cluster ip-10-0-1-12.us-east-2.compute.internal
+
+  # Run a command on a node through a bastion
+  labrat spoke ssh my-cluster ip-10-0-1-12.us-east-2.compute.internal --bastion ec2-user@bastion.acme.example -- sudo crictl ps`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 && dash != 2 {
+				return fmt.Errorf("expected a cluster name and a node name before --")
+			}
+			if len(args) < 2 || (cmd.ArgsLenAtDash() < 0 && len(args) > 2) {
+				return fmt.Errorf("expected a cluster name and a node name, optionally followed by -- and a command")
+			}
+			return nil
+		},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName, nodeName, command := args[0], args[1], args[2:]
+			bastion, _ := cmd.Flags().GetString("bastion")
+			user, _ := cmd.Flags().GetString("user")
+			insecure, _ := cmd.Flags().GetBool("insecure-skip-host-key-check")
+
+			sshPath, err := exec.LookPath("ssh")
+			if err != nil {
+				return fmt.Errorf("ssh not found on the PATH: %w", err)
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			key, err := newSSHKeyManager(kubeClient).Get(ctx, clusterName)
+			if err != nil {
+				return err
+			}
+			if bastion == "" {
+				bastion = key.Bastion
+			}
+
+			spokeClient, err := newSpokeClient(ctx, kubeClient, clusterName)
+			if err != nil {
+				return err
+			}
+			node, err := spoke.NewNodeLister(spokeClient.GetCoreClient(), clientOptions...).Get(ctx, nodeName)
+			if err != nil {
+				return err
+			}
+			address := node.ExternalIP
+			if bastion != "" {
+				address = node.InternalIP
+			}
+			if address == "" && bastion == "" {
+				return fmt.Errorf("node %s has no public address: give --bastion or annotate the ClusterDeployment of %s with %s",
+					nodeName, clusterName, spoke.SSHBastionAnnotation)
+			}
+			if address == "" {
+				return fmt.Errorf("node %s reports no internal IP address", nodeName)
+			}
+
+			keyPath, cleanup, err := writeTempFile("SSH key", "labrat-"+clusterName+"-*.key", key.PrivateKey)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			knownHosts := os.DevNull
+			hostKeyChecking := "no"
+			if !insecure {
+				knownHosts = filepath.Join(cacheDir(cfg), "known_hosts", clusterName)
+				hostKeyChecking = "accept-new"
+				if err := os.MkdirAll(filepath.Dir(knownHosts), 0700); err != nil {
+					return fmt.Errorf("failed to create known_hosts directory: %w", err)
+				}
+			}
+
+			sshArgs := []string{
+				"-i", keyPath,
+				"-o", "IdentitiesOnly=yes",
+				"-o", "StrictHostKeyChecking=" + hostKeyChecking,
+				"-o", "UserKnownHostsFile=" + knownHosts,
+				"-o", "LogLevel=ERROR",
+			}
+			if bastion != "" {
+				sshArgs = append(sshArgs, "-J", bastion)
+			}
+			sshArgs = append(sshArgs, user+"@"+address)
+			if len(command) > 0 {
+				sshArgs = append(append(sshArgs, "--"), command...)
+			}
+			return runAttached(sshPath, sshArgs)
+		},
+	}
+	cmd.Flags().String("bastion", "", "Host to jump through, as [user@]host[:port] (defaults to the ssh-bastion annotation of the ClusterDeployment)")
+	cmd.Flags().String("user", spoke.CoreUser, "User to log in to the node as")
+	cmd.Flags().Bool("insecure-skip-host-key-check", false, "Do not check or record the host key of the node")
+	return cmd
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// newSpokeStatusCmd creates the `spoke status` command
+func newSpokeStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <cluster-name>",
+		Short: "Show everything the hub knows about a spoke cluster",
+		Long: `Show the status of a single spoke cluster as seen from the hub: its platform, version,
+and power state, the install progress and the install attempts of Hive with the status
+of their jobs, the conditions of the ManagedCluster and ClusterDeployment, the health of
+its add-ons, and the most recent events of the cluster namespace.
+
+Imported clusters have no ClusterDeployment and clusters that are still being created
+may not have a ManagedCluster yet; the missing parts are skipped. The table omits the
+ClusterDeployment conditions Hive has not evaluated yet; -o json includes them.
+
+Examples:
+  # Show the status of a cluster
+  labrat spoke status my-cluster
+
+  # Show the 50 most recent events too
+  labrat spoke status my-cluster --events 50
+
+  # Show the status as JSON
+  labrat spoke status my-cluster -o json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			outputFormat, _ := cmd.Flags().GetString("output")
+			maxEvents, _ := cmd.Flags().GetInt("events")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			status, err := spoke.NewStatusReader(kubeClient.GetDynamicClient(), kubeClient.GetCoreClient(), clientOptions...).Read(cmd.Context(), clusterName, maxEvents)
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+				return nil
+			}
+			return writeSpokeStatus(os.Stdout, status)
+		},
+	}
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	cmd.Flags().Int("events", 10, "Number of recent events to show (0 for all)")
+	return cmd
+}
+
+// writeSpokeStatus writes the sections of a cluster status as tables to out
+func writeSpokeStatus(out io.Writer, status *spoke.ClusterStatus) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Cluster:\t%s\n", status.Name)
+	fmt.Fprintf(w, "Platform:\t%s\n", valueOrNA(joinNonEmpty(status.Platform, status.Region)))
+	fmt.Fprintf(w, "Version:\t%s\n", valueOrNA(status.Version))
+	fmt.Fprintf(w, "Power state:\t%s\n", valueOrNA(status.PowerState))
+	fmt.Fprintf(w, "Install:\t%s\n", installSummary(status.Install))
+	fmt.Fprintf(w, "API URL:\t%s\n", valueOrNA(status.APIURL))
+	fmt.Fprintf(w, "Console:\t%s\n", valueOrNA(status.ConsoleURL))
+
+	if status.Managed {
+		fmt.Fprintln(w, "\nMANAGEDCLUSTER CONDITION\tSTATUS\tREASON\tAGE\tMESSAGE")
+		for _, condition := range status.ManagedClusterConditions {
+			printStatusCondition(w, condition)
+		}
+	} else {
+		fmt.Fprintln(w, "\nNo ManagedCluster: the cluster is not managed by ACM")
+	}
+
+	if status.Provisioned {
+		fmt.Fprintln(w, "\nCLUSTERDEPLOYMENT CONDITION\tSTATUS\tREASON\tAGE\tMESSAGE")
+		for _, condition := range status.ClusterDeploymentConditions {
+			// Hive initializes every condition it knows as Unknown until it evaluates it
+			if condition.Status == "Unknown" && condition.Reason == "Initialized" {
+				continue
+			}
+			printStatusCondition(w, condition)
+		}
+
+		if len(status.Provisions) > 0 {
+			fmt.Fprintln(w, "\nATTEMPT\tCLUSTERPROVISION\tSTAGE\tJOB\tAGE")
+			for _, provision := range status.Provisions {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", provision.Attempt, provision.Name, provision.Stage,
+					valueOrNA(provision.Job), duration.HumanDuration(time.Since(provision.CreatedAt)))
+			}
+		}
+	}
+
+	if len(status.AddOns) > 0 {
+		fmt.Fprintln(w, "\nADDON\tSTATUS\tMESSAGE")
+		for _, addon := range status.AddOns {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", addon.Name, addon.Status, addon.Message)
+		}
+	}
+
+	if len(status.Events) > 0 {
+		fmt.Fprintln(w, "\nLAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE")
+		for _, event := range status.Events {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", duration.HumanDuration(time.Since(event.LastSeen)),
+				event.Type, event.Reason, event.Object, event.Message)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// printStatusCondition prints a condition row
+func printStatusCondition(w *tabwriter.Writer, condition spoke.StatusCondition) {
+	age := "N/A"
+	if !condition.LastTransitionTime.IsZero() {
+		age = duration.HumanDuration(time.Since(condition.LastTransitionTime))
+	}
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, valueOrNA(condition.Reason), age, condition.Message)
+}
+
+// installSummary describes the install progress in one line
+func installSummary(install spoke.InstallProgress) string {
+	switch install.Phase {
+	case spoke.InstallPhaseInstalled:
+		if install.InstalledAt != nil {
+			return fmt.Sprintf("%s %s ago", install.Phase, duration.HumanDuration(time.Since(*install.InstalledAt)))
+		}
+	case spoke.InstallPhaseProvisioning:
+		return fmt.Sprintf("%s (attempt %d, stage %s, %d restarts)", install.Phase, install.Attempt, install.Stage, install.Restarts)
+	case spoke.InstallPhaseFailed:
+		return fmt.Sprintf("%s after %d restarts: %s", install.Phase, install.Restarts, install.Message)
+	}
+	return install.Phase
+}
+
+// joinNonEmpty joins the non-empty values with slashes
+func joinNonEmpty(values ...string) string {
+	joined := ""
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if joined != "" {
+			joined += "/"
+		}
+		joined += value
+	}
+	return joined
+}
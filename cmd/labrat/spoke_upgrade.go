@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke/waiter"
+	"github.com/spf13/cobra"
+)
+
+// upgradeOptions are the flags of spoke upgrade that apply to every upgraded cluster
+type upgradeOptions struct {
+	version string
+	channel string
+	direct  bool
+	wait    bool
+	timeout time.Duration
+}
+
+// newSpokeUpgradeCmd creates the `spoke upgrade` command
+func newSpokeUpgradeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade <cluster-name> --to <version>",
+		Short: "Upgrade the OpenShift version of a spoke cluster",
+		Long: `Upgrade a spoke cluster to another OpenShift version.
+
+By default the upgrade is requested from ACM with the ClusterCurator of the cluster on
+the hub, which is created if there is none; its cluster-curator job then drives the
+update of the spoke. With --direct, spec.desiredUpdate of the ClusterVersion on the spoke
+is set instead, using the admin kubeconfig of the cluster; the version must then be one
+of the available updates of its channel (see labrat hub upgrade-check). With --channel
+the cluster is switched to another update channel, e.g. to move to the next minor version.
+
+With --wait the command follows the update on the spoke until the version is completely
+rolled out, showing the progress of the cluster version operator.
+
+With --canary <clusterset>, the clusters of a ManagedClusterSet are upgraded one after the
+other, each waited for, stopping at the first failure; put a few representative clusters in
+a canary set to try a version before upgrading the rest of the fleet.
+
+Examples:
+  # Upgrade a cluster to a z-stream release and follow it
+  labrat spoke upgrade my-cluster --to 4.16.21 --wait
+
+  # Move a cluster to the next minor version
+  labrat spoke upgrade my-cluster --to 4.17.3 --channel stable-4.17
+
+  # Upgrade through the ClusterVersion of a cluster without ACM cluster-curator
+  labrat spoke upgrade my-cluster --to 4.16.21 --direct
+
+  # Try a version on the clusters of the canary cluster set first
+  labrat spoke upgrade --canary canary --to 4.16.21`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if canary, _ := cmd.Flags().GetString("canary"); canary != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			canary, _ := cmd.Flags().GetString("canary")
+			opts := upgradeOptions{}
+			opts.version, _ = cmd.Flags().GetString("to")
+			opts.channel, _ = cmd.Flags().GetString("channel")
+			opts.direct, _ = cmd.Flags().GetBool("direct")
+			opts.wait, _ = cmd.Flags().GetBool("wait")
+			opts.timeout = waitTimeout(cmd)
+
+			_, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			if canary == "" {
+				return upgradeSpoke(ctx, kubeClient, args[0], opts)
+			}
+
+			clusterNames, err := clusterSetMembers(ctx, kubeClient, canary)
+			if err != nil {
+				return err
+			}
+			opts.wait = true
+			fmt.Fprintf(os.Stderr, "🚀 Canary upgrade of %d cluster(s) of cluster set %s to %s\n", len(clusterNames), canary, opts.version)
+			for i, clusterName := range clusterNames {
+				fmt.Fprintf(os.Stderr, "\n[%d/%d] %s\n", i+1, len(clusterNames), clusterName)
+				if err := upgradeSpoke(ctx, kubeClient, clusterName, opts); err != nil {
+					return fmt.Errorf("canary upgrade stopped at %s: %w", clusterName, err)
+				}
+			}
+			fmt.Fprintf(os.Stderr, "\n✓ All clusters of cluster set %s run %s\n", canary, opts.version)
+			return nil
+		},
+	}
+	cmd.Flags().String("to", "", "OpenShift version to upgrade to, e.g. 4.16.21 (required)")
+	cmd.Flags().String("channel", "", "Update channel to switch the cluster to, e.g. stable-4.17")
+	cmd.Flags().Bool("direct", false, "Set the desired update of the ClusterVersion on the spoke instead of using a ClusterCurator")
+	cmd.Flags().Bool("wait", false, "Wait until the update is completely rolled out")
+	addWaitTimeoutFlag(cmd, spoke.DefaultUpgradeTimeout, "Maximum time to wait for the update of each cluster")
+	cmd.Flags().String("canary", "", "Upgrade the clusters of this ManagedClusterSet one after the other, stopping at the first failure")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+// clusterSetMembers returns the clusters of a ManagedClusterSet, which must have some
+func clusterSetMembers(ctx context.Context, kubeClient *kube.Client, setName string) ([]string, error) {
+	sets, err := hub.NewManagedClusterSetClient(kubeClient.GetDynamicClient(), clientOptions...).List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, set := range sets {
+		if set.Name != setName {
+			continue
+		}
+		if set.SelectorType != hub.ClusterSetSelectorExclusive {
+			return nil, fmt.Errorf("cluster set %s selects its clusters by label selector; use a set managed with labrat hub clustersets", setName)
+		}
+		if len(set.Clusters) == 0 {
+			return nil, fmt.Errorf("cluster set %s has no clusters", setName)
+		}
+		return set.Clusters, nil
+	}
+	return nil, fmt.Errorf("cluster set %s does not exist", setName)
+}
+
+// upgradeSpoke requests the upgrade of a cluster and, with opts.wait, follows it until it
+// completes
+func upgradeSpoke(ctx context.Context, kubeClient *kube.Client, clusterName string, opts upgradeOptions) error {
+	var spokeClient *kube.Client
+	if opts.direct || opts.wait {
+		var err error
+		spokeClient, err = newSpokeClient(ctx, kubeClient, clusterName)
+		if err != nil {
+			return err
+		}
+	}
+
+	started := time.Now()
+	curators := hub.NewClusterCuratorClient(kubeClient.GetDynamicClient(), clientOptions...)
+	if opts.direct {
+		if err := spoke.NewVersionUpgrader(spokeClient.GetDynamicClient(), clientOptions...).Upgrade(ctx, opts.version, opts.channel); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✓ Update of %s to %s requested from its ClusterVersion\n", clusterName, opts.version)
+	} else {
+		created, err := curators.Upgrade(ctx, clusterName, opts.version, opts.channel)
+		if err != nil {
+			return err
+		}
+		action := "updated"
+		if created {
+			action = "created"
+		}
+		fmt.Fprintf(os.Stderr, "✓ ClusterCurator %s/%s %s to upgrade to %s\n", clusterName, clusterName, action, opts.version)
+	}
+
+	if !opts.wait {
+		fmt.Fprintf(os.Stderr, "  Follow the update with: labrat hub upgrade-check %s\n", clusterName)
+		return nil
+	}
+
+	waitOpts := spoke.UpgradeWaitOptions{Timeout: opts.timeout}
+	if !opts.direct {
+		// A failure of an earlier curation is still reported until the new job runs
+		waitOpts.Failed = func(ctx context.Context) (string, error) {
+			curator, err := curators.Get(ctx, clusterName)
+			if err != nil {
+				return "", err
+			}
+			if curator.FailedAt == nil || curator.FailedAt.Before(started.Truncate(time.Second)) {
+				return "", nil
+			}
+			return curator.Failure, nil
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "⏳ Waiting for %s to update to %s (timeout %s)...\n", clusterName, opts.version, opts.timeout)
+	inspector := spoke.NewVersionInspector(spokeClient.GetDynamicClient(), clientOptions...)
+	var err error
+	if isTerminal(os.Stderr) {
+		spinner := waiter.NewSpinner(os.Stderr)
+		spinner.Update("Requested")
+		spinner.Start()
+		err = spoke.WaitForUpgrade(ctx, inspector, opts.version, waitOpts, func(status *spoke.VersionStatus) {
+			spinner.Update(describeUpgrade(status))
+		})
+		spinner.Stop("")
+	} else {
+		err = spoke.WaitForUpgrade(ctx, inspector, opts.version, waitOpts, func(status *spoke.VersionStatus) {
+			fmt.Fprintf(os.Stderr, "   %s\n", describeUpgrade(status))
+		})
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Cluster %s runs %s\n", clusterName, opts.version)
+	return nil
+}
+
+// describeUpgrade describes the progress of an update in one line
+func describeUpgrade(status *spoke.VersionStatus) string {
+	description := "Running " + status.Version
+	if status.Updating != "" {
+		description = fmt.Sprintf("Updating %s to %s", status.Version, status.Updating)
+		if status.Progress != "" {
+			description += ": " + status.Progress
+		}
+	}
+	if status.Failing != "" {
+		description += " (failing: " + status.Failing + ")"
+	}
+	return description
+}
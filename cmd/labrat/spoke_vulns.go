@@ -0,0 +1,126 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/acs"
+	"github.com/spf13/cobra"
+)
+
+// acsTokenEnv is the environment variable roxctl also reads the Central API token from
+const acsTokenEnv = "ROX_API_TOKEN"
+
+// newSpokeVulnsCmd creates the `spoke vulns` command
+func newSpokeVulnsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vulns <cluster-name>",
+		Short: "Summarize image vulnerabilities of a spoke's workloads from ACS",
+		Long: `Summarize the vulnerabilities of the workloads running on a spoke cluster, as
+reported by Red Hat Advanced Cluster Security (ACS) Central.
+
+The distinct CVEs found in the images of the cluster's deployments are counted per
+severity, with the number that have a fix available and the number of deployments
+affected. The spoke must be secured by the Central configured in acs.endpoint under
+its managed cluster name.
+
+The API token is read from the ROX_API_TOKEN environment variable, or from the file
+named by acs.tokenFile. A token with read access to images and deployments (for
+example the Analyst role) is sufficient.
+
+Examples:
+  # Summarize vulnerabilities of every workload on a cluster
+  labrat spoke vulns my-cluster
+
+  # Limit the summary to the partner's namespace, as JSON
+  labrat spoke vulns my-cluster --namespace partner-app -o json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName := args[0]
+			namespace, _ := cmd.Flags().GetString("namespace")
+			outputFormat, _ := cmd.Flags().GetString("output")
+
+			if outputFormat != "table" && outputFormat != "json" {
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := newACSClient(cfg.ACS)
+			if err != nil {
+				return err
+			}
+
+			summary, err := client.WorkloadVulnerabilities(cmd.Context(), clusterName, namespace)
+			if err != nil {
+				return fmt.Errorf("failed to summarize vulnerabilities of %s: %w", clusterName, err)
+			}
+
+			if outputFormat == "json" {
+				data, err := json.MarshalIndent(summary, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Fprintln(os.Stdout, string(data))
+				return nil
+			}
+
+			if summary.Deployments == 0 {
+				fmt.Fprintf(os.Stdout, "No deployments of %s found in ACS\n", clusterName)
+				return nil
+			}
+
+			fmt.Fprintf(os.Stdout, "%d deployments, %d images scanned\n\n", summary.Deployments, summary.Images)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintf(w, "SEVERITY\tCVES\tFIXABLE\tDEPLOYMENTS\n")
+			for _, s := range summary.Severities {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", s.Severity, s.CVEs, s.Fixable, s.Deployments)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringP("namespace", "n", "", "Only include workloads in this namespace")
+	cmd.Flags().StringP("output", "o", "table", "Output format (table|json)")
+	return cmd
+}
+
+// newACSClient creates an ACS Central client from the acs config section and the API token
+// in ROX_API_TOKEN or acs.tokenFile
+func newACSClient(cfg config.ACSConfig) (acs.Client, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("acs.endpoint is not set in the config")
+	}
+
+	token := os.Getenv(acsTokenEnv)
+	if token == "" {
+		if cfg.TokenFile == "" {
+			return nil, fmt.Errorf("no ACS API token: set %s or acs.tokenFile", acsTokenEnv)
+		}
+		data, err := os.ReadFile(cfg.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ACS token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	var httpClient *http.Client
+	if cfg.InsecureSkipVerify {
+		httpClient = &http.Client{
+			Timeout: 5 * time.Minute,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // opt-in for lab Centrals with self-signed certificates
+			},
+		}
+	}
+	return acs.NewClient(cfg.Endpoint, token, httpClient), nil
+}
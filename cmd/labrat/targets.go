@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// maxListedTargets is how many clusters a confirmation prompt names before summarizing the rest
+const maxListedTargets = 20
+
+// addTargetFlags registers the flags that select the clusters of a bulk command instead of
+// naming them
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("selector", "l", "", "Select the clusters whose ManagedCluster matches a label selector, e.g. partner=acme")
+	cmd.Flags().String("clusterset", "", "Select the clusters of a ManagedClusterSet")
+	cmd.Flags().Bool("all", false, "Select every managed cluster")
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation before changing the selected clusters")
+}
+
+// hasTargetFlags reports whether the clusters are selected with --selector, --clusterset, or --all
+func hasTargetFlags(cmd *cobra.Command) bool {
+	selector, _ := cmd.Flags().GetString("selector")
+	clusterSet, _ := cmd.Flags().GetString("clusterset")
+	all, _ := cmd.Flags().GetBool("all")
+	return selector != "" || clusterSet != "" || all
+}
+
+// targetArgs validates the arguments of a bulk command that needs minArgs arguments when
+// its clusters are named, and minArgs-1 when they are selected by flags
+func targetArgs(minArgs int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if hasTargetFlags(cmd) {
+			return cobra.MinimumNArgs(minArgs-1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(minArgs)(cmd, args)
+	}
+}
+
+// resolveTargets returns the clusters a bulk command runs against: names, or the managed
+// clusters selected by --selector, --clusterset, or --all. Selected clusters are recorded in
+// the audit log.
+func resolveTargets(ctx context.Context, cmd *cobra.Command, kubeClient *kube.Client, names []string) ([]string, error) {
+	if !hasTargetFlags(cmd) {
+		return names, nil
+	}
+	selector, _ := cmd.Flags().GetString("selector")
+	clusterSet, _ := cmd.Flags().GetString("clusterset")
+	all, _ := cmd.Flags().GetBool("all")
+	if len(names) > 0 {
+		return nil, fmt.Errorf("cluster names cannot be combined with --selector, --clusterset, or --all")
+	}
+	if all && (selector != "" || clusterSet != "") {
+		return nil, fmt.Errorf("--all cannot be combined with --selector or --clusterset")
+	}
+
+	filter := hub.ManagedClusterFilter{Selector: selector, ClusterSet: clusterSet}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	targets, err := fleet.Clusters(ctx, newHubBackend(kubeClient).ManagedClusters(), filter)
+	if err != nil {
+		return nil, err
+	}
+	auditTargets = targets
+	return targets, nil
+}
+
+// confirmTargets asks whether to run action, e.g. "hibernate", against clusters unless --yes
+// is given. Without a terminal to ask on, --yes is required.
+func confirmTargets(cmd *cobra.Command, action string, clusters []string) error {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return nil
+	}
+	if !isTerminal(os.Stdin) {
+		return fmt.Errorf("refusing to %s %d clusters without confirmation, pass --yes", action, len(clusters))
+	}
+
+	listed := clusters
+	if len(listed) > maxListedTargets {
+		listed = listed[:maxListedTargets]
+	}
+	fmt.Fprintf(os.Stderr, "Clusters to %s:\n  %s\n", action, strings.Join(listed, "\n  "))
+	if len(clusters) > len(listed) {
+		fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(clusters)-len(listed))
+	}
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+	if !p.confirm(fmt.Sprintf("%s %d clusters?", strings.ToUpper(action[:1])+action[1:], len(clusters))) {
+		return fmt.Errorf("%s aborted", action)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/tui"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/spf13/cobra"
+)
+
+// newTUICmd creates the `tui` command
+func newTUICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Browse and operate the clusters of a hub in a terminal UI",
+		Long: `Show a live table of the clusters of the hub in a terminal UI, refreshed every
+--refresh, and act on the selected cluster with single keys:
+
+  ↑/↓, j/k      Move the selection (pgup/pgdown, g/G to jump)
+  /             Filter the clusters by name, status, power state, platform, region,
+                version, or cluster set; every word must match, esc clears the filter
+  enter         Show the status of the cluster, as labrat spoke status does
+  h / r         Hibernate or resume the cluster, after confirming with y
+  c             Merge the admin kubeconfig of the cluster into --kubeconfig-file as
+                context labrat-<cluster-name>
+  R             Refresh now
+  q             Quit, or leave the cluster status
+
+The clusters are listed from the hub once at startup and then kept current by watches,
+so refreshes read from memory; every --resync the cached objects are compared again.
+
+Hibernate, resume, and kubeconfig actions are recorded in the audit log like the
+commands they correspond to.
+
+Examples:
+  # Browse the clusters of the primary hub
+  labrat tui
+
+  # Browse the clusters of another hub, refreshing every 10 seconds
+  labrat tui --hub lab-east --refresh 10s`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			refresh, _ := cmd.Flags().GetDuration("refresh")
+			kubeconfigPath, _ := cmd.Flags().GetString("kubeconfig-file")
+			resync, _ := cmd.Flags().GetDuration("resync")
+			if refresh <= 0 {
+				return fmt.Errorf("--refresh must be positive, got %s", refresh)
+			}
+			if !isTerminal(os.Stdout) {
+				return fmt.Errorf("labrat tui requires a terminal; use labrat hub managedclusters --wide instead")
+			}
+
+			cfg, kubeClient, err := newHubClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			fmt.Fprintln(os.Stderr, "Listing the clusters of the hub...")
+			informerCache, err := startHubCache(ctx, kubeClient, resync, true)
+			if err != nil {
+				return err
+			}
+
+			dynamicClient := kubeClient.GetDynamicClient()
+			backend := tui.Backend{
+				Clusters:    hub.NewInformerBackend(dynamicClient, informerCache, clientOptions...).Clusters(),
+				Status:      spoke.NewStatusReader(dynamicClient, kubeClient.GetCoreClient(), clientOptions...),
+				Kubeconfigs: spoke.NewKubeconfigExtractor(dynamicClient, kubeClient.GetCoreClient().CoreV1(), clientOptions...),
+				Power:       spoke.NewPowerManager(dynamicClient, clientOptions...),
+				Audit:       auditLog,
+				Hub:         cfg.HubName(),
+			}
+			return tui.Run(ctx, backend, tui.Options{
+				RefreshInterval: refresh,
+				KubeconfigPath:  kubeconfigPath,
+				KubeconfigSaved: func(cluster, path string) error {
+					return trackSavedKubeconfig(cluster, path, true)
+				},
+				WriteStatus: writeSpokeStatus,
+			})
+		},
+	}
+	cmd.Flags().Duration("refresh", tui.DefaultRefreshInterval, "How often the cluster table is redrawn from the hub cache")
+	cmd.Flags().Duration("resync", kube.DefaultResyncPeriod, "How often the cached hub objects are redelivered to catch missed changes (0 disables)")
+	cmd.Flags().String("kubeconfig-file", spoke.DefaultKubeconfigPath(), "Kubeconfig the admin kubeconfigs extracted with c are merged into")
+	return cmd
+}
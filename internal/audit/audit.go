@@ -0,0 +1,112 @@
+// Package audit records the mutating operations of labrat, such as creating, deleting, or
+// hibernating clusters and extracting their credentials, for compliance reviews. Each
+// operation is appended as a JSON record to a local audit log and optionally to a ConfigMap
+// on the hub.
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/user"
+	"time"
+)
+
+// DefaultFile is the local audit log unless audit.file of the config sets another one
+const DefaultFile = "~/.labrat/audit.log"
+
+// Result is the outcome of an audited operation
+type Result string
+
+const (
+	// ResultSuccess marks operations that completed
+	ResultSuccess Result = "success"
+	// ResultFailure marks operations that returned an error
+	ResultFailure Result = "failure"
+)
+
+// Source tells where an audited operation was requested
+type Source string
+
+const (
+	// SourceCLI marks operations run from the command line
+	SourceCLI Source = "cli"
+	// SourceAPI marks operations requested through the labrat serve API
+	SourceAPI Source = "api"
+	// SourceTUI marks operations requested through the labrat tui terminal UI
+	SourceTUI Source = "tui"
+)
+
+// Record describes one audited operation
+type Record struct {
+	Time time.Time `json:"time"`
+	// User is user@host for the CLI, or the authenticated principal for the API
+	User   string `json:"user"`
+	Source Source `json:"source"`
+	// Action is the command path, e.g. "labrat spoke delete", or the API operation
+	Action string `json:"action"`
+	// Targets are the clusters or other resources the operation was run against
+	Targets []string `json:"targets,omitempty"`
+	// Args are the arguments after the targets, e.g. the command of spoke exec
+	Args []string `json:"args,omitempty"`
+	// Flags are the flags set on the command line
+	Flags  map[string]string `json:"flags,omitempty"`
+	Hub    string            `json:"hub,omitempty"`
+	Result Result            `json:"result"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// Sink stores audit records
+type Sink interface {
+	// Write stores a record
+	Write(ctx context.Context, record Record) error
+}
+
+// Logger writes audit records to every one of its sinks
+type Logger struct {
+	sinks []Sink
+}
+
+// NewLogger creates a Logger writing to sinks
+func NewLogger(sinks ...Sink) *Logger {
+	return &Logger{sinks: sinks}
+}
+
+// AddSink adds a sink records are written to from now on
+func (l *Logger) AddSink(sink Sink) {
+	l.sinks = append(l.sinks, sink)
+}
+
+// Log writes record to every sink, setting its time and user if they are empty. A failing
+// sink does not keep the record from the others; the errors of all sinks are returned.
+func (l *Logger) Log(ctx context.Context, record Record) error {
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	if record.User == "" {
+		record.User = CurrentUser()
+	}
+
+	var errs []error
+	for _, sink := range l.sinks {
+		if err := sink.Write(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CurrentUser returns the user running labrat as user@host
+func CurrentUser() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return name + "@" + host
+	}
+	return name
+}
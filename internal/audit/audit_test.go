@@ -0,0 +1,144 @@
+//go:build test
+
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/audit"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// failingSink is a Sink whose writes always fail
+type failingSink struct{}
+
+func (failingSink) Write(_ context.Context, _ audit.Record) error {
+	return errors.New("disk full")
+}
+
+// readRecords decodes the JSON lines of the audit log at path
+func readRecords(path string) []audit.Record {
+	f, err := os.Open(path)
+	Expect(err).NotTo(HaveOccurred())
+	defer f.Close()
+
+	var records []audit.Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record audit.Record
+		Expect(json.Unmarshal(scanner.Bytes(), &record)).To(Succeed())
+		records = append(records, record)
+	}
+	Expect(scanner.Err()).NotTo(HaveOccurred())
+	return records
+}
+
+var _ = Describe("Logger", func() {
+	var (
+		ctx  context.Context
+		path string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		path = filepath.Join(GinkgoT().TempDir(), "audit", "audit.log")
+	})
+
+	It("should append records to the audit log", func() {
+		logger := audit.NewLogger(audit.NewFileSink(path))
+		Expect(logger.Log(ctx, audit.Record{Action: "labrat spoke delete", Targets: []string{"lab-1"}, Result: audit.ResultSuccess})).To(Succeed())
+		Expect(logger.Log(ctx, audit.Record{Action: "labrat spoke hibernate", Targets: []string{"lab-2"}, Result: audit.ResultFailure, Error: "not found"})).To(Succeed())
+
+		records := readRecords(path)
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Action).To(Equal("labrat spoke delete"))
+		Expect(records[0].Targets).To(Equal([]string{"lab-1"}))
+		Expect(records[1].Result).To(Equal(audit.ResultFailure))
+		Expect(records[1].Error).To(Equal("not found"))
+	})
+
+	It("should set the time and user of records", func() {
+		logger := audit.NewLogger(audit.NewFileSink(path))
+		Expect(logger.Log(ctx, audit.Record{Action: "labrat spoke delete"})).To(Succeed())
+
+		records := readRecords(path)
+		Expect(records[0].Time).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(records[0].User).To(Equal(audit.CurrentUser()))
+	})
+
+	It("should keep the audit log private", func() {
+		logger := audit.NewLogger(audit.NewFileSink(path))
+		Expect(logger.Log(ctx, audit.Record{Action: "labrat spoke kubeconfig"})).To(Succeed())
+
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("should write to the other sinks when one fails", func() {
+		logger := audit.NewLogger(failingSink{})
+		logger.AddSink(audit.NewFileSink(path))
+
+		err := logger.Log(ctx, audit.Record{Action: "labrat spoke delete"})
+		Expect(err).To(MatchError(ContainSubstring("disk full")))
+		Expect(readRecords(path)).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("ConfigMapSink", func() {
+	var (
+		ctx       context.Context
+		clientset *fake.Clientset
+		sink      audit.Sink
+		record    audit.Record
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clientset = fake.NewSimpleClientset()
+		sink = audit.NewConfigMapSink(clientset.CoreV1(), "open-cluster-management")
+		record = audit.Record{
+			Time:    time.Date(2026, 1, 31, 14, 5, 9, 0, time.UTC),
+			User:    "alice@laptop",
+			Action:  "labrat spoke delete",
+			Targets: []string{"lab-1"},
+			Result:  audit.ResultSuccess,
+		}
+	})
+
+	It("should store the records of a day in one ConfigMap", func() {
+		Expect(sink.Write(ctx, record)).To(Succeed())
+		later := record
+		later.Time = record.Time.Add(time.Hour)
+		Expect(sink.Write(ctx, later)).To(Succeed())
+
+		cm, err := clientset.CoreV1().ConfigMaps("open-cluster-management").Get(ctx, "labrat-audit-2026-01-31", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "labrat"))
+		Expect(cm.Data).To(HaveLen(2))
+
+		var stored audit.Record
+		Expect(json.Unmarshal([]byte(cm.Data["140509.000000000"]), &stored)).To(Succeed())
+		Expect(stored.User).To(Equal("alice@laptop"))
+		Expect(stored.Targets).To(Equal([]string{"lab-1"}))
+	})
+
+	It("should keep records of the same instant", func() {
+		Expect(sink.Write(ctx, record)).To(Succeed())
+		Expect(sink.Write(ctx, record)).To(Succeed())
+
+		cm, err := clientset.CoreV1().ConfigMaps("open-cluster-management").Get(ctx, audit.ConfigMapName(record), metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Data).To(HaveKey("140509.000000000"))
+		Expect(cm.Data).To(HaveKey("140509.000000000-1"))
+	})
+})
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// ConfigMapPrefix starts the names of the hub ConfigMaps holding audit records, one per
+	// day, e.g. labrat-audit-2026-01-31
+	ConfigMapPrefix = "labrat-audit-"
+	// configMapKeyFormat keys the records of a day by their time, so they sort chronologically
+	configMapKeyFormat = "150405.000000000"
+)
+
+type configMapSink struct {
+	coreClient corev1client.CoreV1Interface
+	namespace  string
+	options    kube.Options
+}
+
+// NewConfigMapSink creates a Sink storing records in a ConfigMap per day in the hub namespace,
+// so the operations of every administrator can be reviewed in one place
+func NewConfigMapSink(coreClient corev1client.CoreV1Interface, namespace string, options ...kube.Option) Sink {
+	return &configMapSink{
+		coreClient: coreClient,
+		namespace:  namespace,
+		options:    kube.NewOptions(options...),
+	}
+}
+
+// ConfigMapName returns the name of the ConfigMap holding the records of record's day
+func ConfigMapName(record Record) string {
+	return ConfigMapPrefix + record.Time.UTC().Format("2006-01-02")
+}
+
+// Write adds record to the ConfigMap of its day, creating the ConfigMap if needed
+func (s *configMapSink) Write(ctx context.Context, record Record) error {
+	name := ConfigMapName(record)
+	ctx, cancel, err := s.options.Start(ctx, "write audit record", "configmap", name)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	key := record.Time.UTC().Format(configMapKeyFormat)
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMaps := s.coreClient.ConfigMaps(s.namespace)
+
+		cm, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: s.namespace,
+					Labels:    map[string]string{"app.kubernetes.io/managed-by": "labrat"},
+				},
+				Data: map[string]string{key: string(data)},
+			}, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// Created concurrently; retry the update path
+				return apierrors.NewConflict(corev1.Resource("configmaps"), name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		// Records of the same instant get a numbered key instead of replacing each other
+		unique := key
+		for i := 1; cm.Data[unique] != ""; i++ {
+			unique = key + "-" + strconv.Itoa(i)
+		}
+		cm.Data[unique] = string(data)
+
+		_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write audit record to %s/%s: %w", s.namespace, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type fileSink struct {
+	path string
+}
+
+// NewFileSink creates a Sink appending records as JSON lines to the file at path. The file
+// and its directory are created readable by the current user only, as the records name the
+// clusters of the hub.
+func NewFileSink(path string) Sink {
+	return &fileSink{path: path}
+}
+
+// Write appends record to the file
+func (s *fileSink) Write(_ context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	// A single append of the whole line keeps concurrent labrat processes from interleaving
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %w", s.path, err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log %s: %w", s.path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package cache stores list results of the hub on disk between invocations of labrat, so
+// repeated listings over a slow connection are answered locally until they expire.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDir is the directory cached list results are stored in
+	DefaultDir = "~/.labrat/cache"
+	// fileSuffix marks cache entries, so Clear only removes files written by the cache
+	fileSuffix = ".json"
+)
+
+// Cache stores JSON encoded values in files that expire after a TTL. Entries are written
+// atomically and readable by the current user only, as they describe the clusters of the hub.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// New creates a Cache storing entries in dir that expire after ttl
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// Get decodes the entry stored under key into v. It returns false if the entry does not exist,
+// has expired, or cannot be decoded.
+func (c *Cache) Get(key string, v interface{}) bool {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.ttl {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// Set stores v under key, replacing any existing entry
+func (c *Cache) Set(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", c.dir, err)
+	}
+
+	// Write to a temporary file first, so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every entry and returns the number of entries removed. A missing cache
+// directory is not an error.
+func (c *Cache) Clear() (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cache directory %s: %w", c.dir, err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileSuffix) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove cache entry %s: %w", entry.Name(), err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// path returns the file of the entry stored under key. Keys are hashed, so any key, e.g. one
+// containing the URL of the API server, is a safe file name.
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+fileSuffix)
+}
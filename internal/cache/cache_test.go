@@ -0,0 +1,100 @@
+//go:build test
+
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/cache"
+)
+
+type entry struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+var _ = Describe("Cache", func() {
+	var (
+		dir string
+		c   *cache.Cache
+	)
+
+	BeforeEach(func() {
+		dir = filepath.Join(GinkgoT().TempDir(), "cache")
+		c = cache.New(dir, time.Minute)
+	})
+
+	// entryFiles returns the files of the cache directory
+	entryFiles := func() []string {
+		files, err := filepath.Glob(filepath.Join(dir, "*"))
+		Expect(err).NotTo(HaveOccurred())
+		return files
+	}
+
+	It("should return stored entries", func() {
+		Expect(c.Set("https://api.hub.example.com:6443/managedclusters", []entry{{Name: "spoke", Count: 3}})).To(Succeed())
+
+		var got []entry
+		Expect(c.Get("https://api.hub.example.com:6443/managedclusters", &got)).To(BeTrue())
+		Expect(got).To(Equal([]entry{{Name: "spoke", Count: 3}}))
+	})
+
+	It("should miss keys that were never stored", func() {
+		var got []entry
+		Expect(c.Get("missing", &got)).To(BeFalse())
+	})
+
+	It("should write entries readable by the current user only", func() {
+		Expect(c.Set("key", entry{Name: "spoke"})).To(Succeed())
+
+		files := entryFiles()
+		Expect(files).To(HaveLen(1))
+		info, err := os.Stat(files[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("should miss expired entries", func() {
+		Expect(c.Set("key", entry{Name: "spoke"})).To(Succeed())
+		old := time.Now().Add(-2 * time.Minute)
+		Expect(os.Chtimes(entryFiles()[0], old, old)).To(Succeed())
+
+		var got entry
+		Expect(c.Get("key", &got)).To(BeFalse())
+	})
+
+	It("should miss entries that cannot be decoded", func() {
+		Expect(c.Set("key", entry{Name: "spoke"})).To(Succeed())
+		Expect(os.WriteFile(entryFiles()[0], []byte("{not json"), 0600)).To(Succeed())
+
+		var got entry
+		Expect(c.Get("key", &got)).To(BeFalse())
+	})
+
+	Describe("Clear", func() {
+		It("should remove every entry and leave other files", func() {
+			Expect(c.Set("a", entry{Name: "a"})).To(Succeed())
+			Expect(c.Set("b", entry{Name: "b"})).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0600)).To(Succeed())
+
+			removed, err := c.Clear()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(removed).To(Equal(2))
+			Expect(entryFiles()).To(ConsistOf(filepath.Join(dir, "notes.txt")))
+
+			var got entry
+			Expect(c.Get("a", &got)).To(BeFalse())
+		})
+
+		It("should succeed without a cache directory", func() {
+			removed, err := c.Clear()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(removed).To(BeZero())
+		})
+	})
+})
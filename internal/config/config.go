@@ -1,37 +1,277 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the LABRAT configuration
 type Config struct {
-	Hub      HubConfig `yaml:"hub"`
-	Defaults Defaults  `yaml:"defaults"`
-	Verbose  bool      `yaml:"verbose"`
+	Hub HubConfig `yaml:"hub"`
+	// Hubs are additional ACM hubs that can be selected with --hub or queried together with --hub all
+	Hubs []HubConfig `yaml:"hubs,omitempty"`
+	// ActiveHub names the hub used when --hub is not given; set by `labrat hub failover`
+	ActiveHub string      `yaml:"activeHub,omitempty"`
+	Defaults  Defaults    `yaml:"defaults,omitempty"`
+	Serve     ServeConfig `yaml:"serve,omitempty"`
+	ACS       ACSConfig   `yaml:"acs,omitempty"`
+	Retry     RetryConfig `yaml:"retry,omitempty"`
+	Cache     CacheConfig `yaml:"cache,omitempty"`
+	Audit     AuditConfig `yaml:"audit,omitempty"`
+	// History configures the recording of cluster status transitions for labrat hub flapping
+	History HistoryConfig `yaml:"history,omitempty"`
+	// Notify configures where serve and watch modes send cluster lifecycle events
+	Notify  NotifyConfig `yaml:"notify,omitempty"`
+	Verbose bool         `yaml:"verbose,omitempty"`
+
+	// hubEnv holds the environment of ApplyEnv when it overrides the active hub, so SelectHub
+	// can apply the overrides to the hub it selects
+	hubEnv map[string]string
+	// configuredHub is the active hub without the environment overrides
+	configuredHub HubConfig
 }
 
+// AllHubs selects every configured hub in commands that support fan-out queries
+const AllHubs = "all"
+
+// DefaultHubName is the name of the primary hub when hub.name is not set
+const DefaultHubName = "default"
+
 // HubConfig contains configuration for the ACM Hub cluster
 type HubConfig struct {
+	Name       string `yaml:"name,omitempty"`
 	Kubeconfig string `yaml:"kubeconfig"`
-	Context    string `yaml:"context"`
+	Context    string `yaml:"context,omitempty"`
 	Namespace  string `yaml:"namespace"`
+	// Standby names the hub that takes over from this one in `labrat hub failover`
+	Standby string `yaml:"standby,omitempty"`
 }
 
 // Defaults contains default configurations for resources
 type Defaults struct {
-	Spoke SpokeDefaults `yaml:"spoke"`
+	Spoke SpokeDefaults `yaml:"spoke,omitempty"`
 }
 
 // SpokeDefaults contains default configuration for spoke clusters
 type SpokeDefaults struct {
-	Provider string `yaml:"provider"`
-	Region   string `yaml:"region"`
+	Provider string `yaml:"provider,omitempty"`
+	// Region is the region of clusters on the default provider, unless its platform section
+	// sets one
+	Region string `yaml:"region,omitempty"`
+	// Template is the cluster template `spoke create` uses without --template
+	Template string `yaml:"template,omitempty"`
+	// TemplateDir is the directory of cluster templates (default: ~/.labrat/templates)
+	TemplateDir string `yaml:"templateDir,omitempty"`
+	// Values are the variables of cluster templates, overridden by --set
+	Values map[string]string `yaml:"values,omitempty"`
+	// Snippets are the install-config snippets every cluster is created with, before those
+	// given with --snippet
+	Snippets []string `yaml:"snippets,omitempty"`
+	// SnippetDir is the directory of install-config snippets (default: ~/.labrat/snippets)
+	SnippetDir string `yaml:"snippetDir,omitempty"`
+	// AWS, Azure, GCP, and VSphere are the defaults of clusters on each platform
+	AWS     PlatformDefaults `yaml:"aws,omitempty"`
+	Azure   AzureDefaults    `yaml:"azure,omitempty"`
+	GCP     GCPDefaults      `yaml:"gcp,omitempty"`
+	VSphere VSphereDefaults  `yaml:"vsphere,omitempty"`
+}
+
+// PlatformDefaults contains the defaults of spoke clusters shared by every platform
+type PlatformDefaults struct {
+	// Credentials is the cloud credential secret `spoke create` uses without --credentials
+	Credentials string `yaml:"credentials,omitempty"`
+	// CredentialsNamespace is the namespace of the credential secret (default: the hub namespace)
+	CredentialsNamespace string `yaml:"credentialsNamespace,omitempty"`
+	// BaseDomain overrides the base domain stored with the credentials
+	BaseDomain       string          `yaml:"baseDomain,omitempty"`
+	Region           string          `yaml:"region,omitempty"`
+	ControlPlaneType string          `yaml:"controlPlaneType,omitempty"`
+	ComputeType      string          `yaml:"computeType,omitempty"`
+	Network          NetworkDefaults `yaml:"network,omitempty"`
+}
+
+// NetworkDefaults contains the cluster networking; unset fields use the installer defaults
+type NetworkDefaults struct {
+	// Type is the cluster network plugin (default: OVNKubernetes)
+	Type        string `yaml:"type,omitempty"`
+	MachineCIDR string `yaml:"machineCIDR,omitempty"`
+	ClusterCIDR string `yaml:"clusterCIDR,omitempty"`
+	ServiceCIDR string `yaml:"serviceCIDR,omitempty"`
+}
+
+// AzureDefaults contains the defaults of spoke clusters on Azure
+type AzureDefaults struct {
+	PlatformDefaults `yaml:",inline"`
+	// BaseDomainResourceGroup is the resource group of the DNS zone of the base domain
+	BaseDomainResourceGroup string `yaml:"baseDomainResourceGroup,omitempty"`
+	// NetworkResourceGroup, VirtualNetwork, ControlPlaneSubnet, and ComputeSubnet select an
+	// existing virtual network
+	NetworkResourceGroup string `yaml:"networkResourceGroup,omitempty"`
+	VirtualNetwork       string `yaml:"virtualNetwork,omitempty"`
+	ControlPlaneSubnet   string `yaml:"controlPlaneSubnet,omitempty"`
+	ComputeSubnet        string `yaml:"computeSubnet,omitempty"`
+}
+
+// GCPDefaults contains the defaults of spoke clusters on GCP
+type GCPDefaults struct {
+	PlatformDefaults `yaml:",inline"`
+	// ProjectID overrides the project of the service account
+	ProjectID string `yaml:"projectID,omitempty"`
+	// VPCNetwork, ControlPlaneSubnet, and ComputeSubnet select an existing VPC network
+	VPCNetwork         string `yaml:"vpcNetwork,omitempty"`
+	ControlPlaneSubnet string `yaml:"controlPlaneSubnet,omitempty"`
+	ComputeSubnet      string `yaml:"computeSubnet,omitempty"`
+}
+
+// VSphereDefaults contains the defaults of spoke clusters on vSphere. The vCenter, datacenter,
+// datastore, cluster, and folder default to the ones stored with the credentials.
+type VSphereDefaults struct {
+	PlatformDefaults `yaml:",inline"`
+	VCenter          string `yaml:"vCenter,omitempty"`
+	Datacenter       string `yaml:"datacenter,omitempty"`
+	DefaultDatastore string `yaml:"defaultDatastore,omitempty"`
+	Cluster          string `yaml:"cluster,omitempty"`
+	Folder           string `yaml:"folder,omitempty"`
+	// PortGroup is the network the VMs are attached to
+	PortGroup string `yaml:"portGroup,omitempty"`
+	// APIVIP and IngressVIP are the virtual IPs of the API and the ingress routers
+	APIVIP     string `yaml:"apiVIP,omitempty"`
+	IngressVIP string `yaml:"ingressVIP,omitempty"`
+}
+
+// Platform returns the defaults shared by every platform for the clusters of provider
+func (d SpokeDefaults) Platform(provider string) PlatformDefaults {
+	switch provider {
+	case "aws":
+		return d.AWS
+	case "azure":
+		return d.Azure.PlatformDefaults
+	case "gcp":
+		return d.GCP.PlatformDefaults
+	case "vsphere":
+		return d.VSphere.PlatformDefaults
+	default:
+		return PlatformDefaults{}
+	}
+}
+
+// ServeConfig contains configuration for the labrat API server
+type ServeConfig struct {
+	// Address is the host:port the server listens on, default :8080
+	Address string `yaml:"address,omitempty"`
+	// TLSCertFile and TLSKeyFile enable HTTPS; without them the server speaks plain HTTP and
+	// should only be reached through a TLS-terminating proxy, e.g. an OpenShift edge route
+	TLSCertFile string     `yaml:"tlsCertFile,omitempty"`
+	TLSKeyFile  string     `yaml:"tlsKeyFile,omitempty"`
+	Auth        AuthConfig `yaml:"auth"`
+}
+
+// AuthConfig configures how API clients authenticate. Static tokens and OIDC can be
+// combined; a bearer token is checked against the static tokens first.
+type AuthConfig struct {
+	Tokens []TokenConfig `yaml:"tokens"`
+	OIDC   *OIDCConfig   `yaml:"oidc"`
+}
+
+// TokenConfig is a static API token. Only the SHA-256 hash of the token is stored.
+type TokenConfig struct {
+	Name   string `yaml:"name"`
+	Role   string `yaml:"role"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// OIDCConfig configures authentication with ID tokens from an OIDC provider
+type OIDCConfig struct {
+	IssuerURL     string `yaml:"issuerURL"`
+	ClientID      string `yaml:"clientID"`
+	UsernameClaim string `yaml:"usernameClaim"`
+	GroupsClaim   string `yaml:"groupsClaim"`
+	// Roles maps role names (viewer, operator, admin) to the groups granted that role
+	Roles map[string][]string `yaml:"roles"`
+}
+
+// ACSConfig configures access to the Red Hat Advanced Cluster Security Central API
+type ACSConfig struct {
+	// Endpoint is the Central URL, e.g. https://central-stackrox.apps.hub.example.com
+	Endpoint string `yaml:"endpoint"`
+	// TokenFile holds an API token; the ROX_API_TOKEN environment variable takes precedence
+	TokenFile string `yaml:"tokenFile"`
+	// InsecureSkipVerify disables TLS verification for Centrals with self-signed certificates
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+}
+
+// RetryConfig configures retries of API calls that fail transiently; --retries and
+// --retry-backoff take precedence
+type RetryConfig struct {
+	// Retries is the number of retries after the first attempt; unset uses the default, 0
+	// disables retries
+	Retries *int `yaml:"retries,omitempty"`
+	// Backoff is the delay before the first retry, doubled for each further retry, e.g. 500ms
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+}
+
+// CacheConfig configures the on-disk cache of cluster lists
+type CacheConfig struct {
+	// TTL is how long cached cluster lists are used, e.g. 2m; unset disables the cache
+	TTL time.Duration `yaml:"ttl,omitempty"`
+	// Dir is the directory of the cache (default: ~/.labrat/cache)
+	Dir string `yaml:"dir,omitempty"`
+}
+
+// AuditConfig configures the audit log of mutating operations
+type AuditConfig struct {
+	// File is the local audit log (default: ~/.labrat/audit.log)
+	File string `yaml:"file,omitempty"`
+	// ConfigMap also stores the records in a ConfigMap per day in the hub namespace
+	ConfigMap bool `yaml:"configMap,omitempty"`
+}
+
+// HistoryConfig configures the recording of the status transitions of clusters
+type HistoryConfig struct {
+	// Enabled records every change of the Available condition labrat observes while listing
+	// or watching the managed clusters in ~/.labrat/state.json
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Retention is how long transitions are kept, e.g. 72h (default: 168h)
+	Retention time.Duration `yaml:"retention,omitempty"`
+}
+
+// NotifyEvents are the cluster lifecycle events that can be sent as notifications
+var NotifyEvents = []string{"created", "provisioned", "ready", "failed", "hibernated", "resumed", "expiring"}
+
+// NotifyConfig configures the notifications of cluster lifecycle events
+type NotifyConfig struct {
+	Slack   SlackNotifyConfig   `yaml:"slack,omitempty"`
+	Webhook WebhookNotifyConfig `yaml:"webhook,omitempty"`
+}
+
+// SlackNotifyConfig configures notifications to a Slack channel
+type SlackNotifyConfig struct {
+	// WebhookURL is the URL of a Slack incoming webhook; unset disables Slack notifications
+	WebhookURL string `yaml:"webhookURL,omitempty"`
+	// Events are the events sent to Slack (default: all of NotifyEvents)
+	Events []string `yaml:"events,omitempty"`
+}
+
+// WebhookNotifyConfig configures notifications posted as JSON to an HTTP endpoint
+type WebhookNotifyConfig struct {
+	// URL is the endpoint the events are posted to; unset disables webhook notifications
+	URL string `yaml:"url,omitempty"`
+	// Headers are added to every request, e.g. Authorization
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Events are the events posted (default: all of NotifyEvents)
+	Events []string `yaml:"events,omitempty"`
+}
+
+// Enabled reports whether any notification target is configured
+func (n NotifyConfig) Enabled() bool {
+	return n.Slack.WebhookURL != "" || n.Webhook.URL != ""
 }
 
 // Load reads and parses the configuration file from the given path
@@ -46,6 +286,11 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	// The environment overrides the file; flags are applied over both by the commands
+	if err := cfg.ApplyEnv(os.Environ()); err != nil {
+		return nil, err
+	}
+
 	// Expand paths after unmarshaling
 	cfg.expandPaths()
 
@@ -53,22 +298,185 @@ func Load(path string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.SelectHub(cfg.ActiveHub); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
-// Validate checks if the configuration is valid
-func (c *Config) Validate() error {
-	if c.Hub.Kubeconfig == "" {
-		return fmt.Errorf("validation failed: hub kubeconfig is required")
+// Default returns the default configuration with the LABRAT_ environment variables applied,
+// for running without a config file
+func Default() (*Config, error) {
+	cfg := NewDefaultConfig()
+	if err := cfg.ApplyEnv(os.Environ()); err != nil {
+		return nil, err
 	}
+	cfg.expandPaths()
+	return cfg, nil
+}
 
-	if c.Hub.Namespace == "" {
-		return fmt.Errorf("validation failed: hub namespace is required")
+// HubName returns the name of the active hub
+func (c *Config) HubName() string {
+	if c.Hub.Name == "" {
+		return DefaultHubName
 	}
+	return c.Hub.Name
+}
 
+// HubConfigs returns every configured hub, the active hub first, each with its name set
+func (c *Config) HubConfigs() []HubConfig {
+	primary := c.Hub
+	primary.Name = c.HubName()
+	return append([]HubConfig{primary}, c.Hubs...)
+}
+
+// SelectHub makes the named hub the active one by swapping it with the current one.
+// An empty name keeps the current hub.
+func (c *Config) SelectHub(name string) error {
+	if name == "" || name == c.HubName() {
+		return nil
+	}
+	for i, h := range c.Hubs {
+		if h.Name == name {
+			previous := c.Hub
+			if c.hubEnv != nil {
+				// The LABRAT_HUB_ variables override the active hub only
+				previous = c.configuredHub
+				previous.Kubeconfig = ExpandPath(previous.Kubeconfig)
+				c.configuredHub = h
+				if _, err := applyEnv(reflect.ValueOf(&h).Elem(), EnvVar("hub"), c.hubEnv); err != nil {
+					return err
+				}
+				h.Kubeconfig = ExpandPath(h.Kubeconfig)
+			}
+			previous.Name = c.HubName()
+			c.Hub, c.Hubs[i] = h, previous
+			return nil
+		}
+	}
+	names := make([]string, 0, len(c.Hubs)+1)
+	for _, h := range c.HubConfigs() {
+		names = append(names, h.Name)
+	}
+	return fmt.Errorf("unknown hub %q (configured hubs: %s)", name, strings.Join(names, ", "))
+}
+
+// SetActiveHub records the active hub in the config file at path, preserving the rest of
+// the file including comments
+func SetActiveHub(path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+
+	root := doc.Content[0]
+	updated := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "activeHub" {
+			root.Content[i+1].SetString(name)
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: "activeHub"}
+		value := &yaml.Node{}
+		value.SetString(name)
+		root.Content = append(root.Content, key, value)
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat config file: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// Save validates the configuration and writes it to path, creating the parent directory.
+// The file is readable only by its owner as it points to hub credentials.
+func (c *Config) Save(path string) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
 	return nil
 }
 
+// RedactedValue replaces secrets in configurations returned by Redacted
+const RedactedValue = "REDACTED"
+
+// Redacted returns a copy of the configuration that can be shown, with the hashes of the
+// API tokens, the Slack webhook URL, the webhook headers, and the template values whose names
+// suggest secrets, such as pullSecret or adminPassword, replaced by RedactedValue
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Serve.Auth.Tokens = nil
+	for _, token := range c.Serve.Auth.Tokens {
+		token.SHA256 = RedactedValue
+		redacted.Serve.Auth.Tokens = append(redacted.Serve.Auth.Tokens, token)
+	}
+	if c.Defaults.Spoke.Values != nil {
+		redacted.Defaults.Spoke.Values = make(map[string]string, len(c.Defaults.Spoke.Values))
+		for name, value := range c.Defaults.Spoke.Values {
+			if isSecretName(name) {
+				value = RedactedValue
+			}
+			redacted.Defaults.Spoke.Values[name] = value
+		}
+	}
+	if c.Notify.Slack.WebhookURL != "" {
+		redacted.Notify.Slack.WebhookURL = RedactedValue
+	}
+	if c.Notify.Webhook.Headers != nil {
+		redacted.Notify.Webhook.Headers = make(map[string]string, len(c.Notify.Webhook.Headers))
+		for name := range c.Notify.Webhook.Headers {
+			redacted.Notify.Webhook.Headers[name] = RedactedValue
+		}
+	}
+	return &redacted
+}
+
+// isSecretName reports whether a value named name is likely a secret
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"secret", "password", "token", "apikey", "privatekey"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetHubKubeconfig returns the path to the hub kubeconfig
 func (c *Config) GetHubKubeconfig() string {
 	return c.Hub.Kubeconfig
@@ -87,6 +495,16 @@ func NewDefaultConfig() *Config {
 // expandPaths expands environment variables and ~ in path fields
 func (c *Config) expandPaths() {
 	c.Hub.Kubeconfig = ExpandPath(c.Hub.Kubeconfig)
+	for i := range c.Hubs {
+		c.Hubs[i].Kubeconfig = ExpandPath(c.Hubs[i].Kubeconfig)
+	}
+	c.ACS.TokenFile = ExpandPath(c.ACS.TokenFile)
+	c.Serve.TLSCertFile = ExpandPath(c.Serve.TLSCertFile)
+	c.Serve.TLSKeyFile = ExpandPath(c.Serve.TLSKeyFile)
+	c.Cache.Dir = ExpandPath(c.Cache.Dir)
+	c.Audit.File = ExpandPath(c.Audit.File)
+	c.Defaults.Spoke.TemplateDir = ExpandPath(c.Defaults.Spoke.TemplateDir)
+	c.Defaults.Spoke.SnippetDir = ExpandPath(c.Defaults.Spoke.SnippetDir)
 }
 
 // ExpandPath expands environment variables and ~ in a single path
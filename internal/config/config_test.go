@@ -5,6 +5,8 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -42,6 +44,56 @@ defaults:
   spoke:
     provider: aws
     region: us-east-1
+    template: standard
+    templateDir: $HOME/labrat-templates
+    snippets:
+      - fips
+    snippetDir: $HOME/labrat-snippets
+    values:
+      workerType: m6i.2xlarge
+    azure:
+      credentials: azure-partner-lab
+      region: eastus
+      computeType: Standard_D8s_v3
+      baseDomainResourceGroup: labs-dns
+    vsphere:
+      credentials: vsphere-lab
+      credentialsNamespace: labrat-credentials
+      portGroup: VM Network
+      apiVIP: 192.168.10.10
+      ingressVIP: 192.168.10.11
+      network:
+        machineCIDR: 192.168.10.0/24
+
+serve:
+  address: 127.0.0.1:9090
+  tlsCertFile: ~/labrat/tls.crt
+  auth:
+    tokens:
+      - name: portal
+        role: viewer
+        sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+    oidc:
+      issuerURL: https://sso.example.com/realms/partner-labs
+      clientID: labrat
+      roles:
+        admin: [partner-labs-admins]
+
+acs:
+  endpoint: https://central-stackrox.apps.hub.example.com
+  tokenFile: $HOME/.labrat/acs-token
+
+retry:
+  retries: 5
+  backoff: 2s
+
+cache:
+  ttl: 5m
+  dir: $HOME/.cache/labrat
+
+audit:
+  file: ~/.labrat/audit/labrat.log
+  configMap: true
 
 verbose: false
 `
@@ -70,6 +122,79 @@ verbose: false
 
 				Expect(cfg.Defaults.Spoke.Provider).To(Equal("aws"))
 				Expect(cfg.Defaults.Spoke.Region).To(Equal("us-east-1"))
+				Expect(cfg.Defaults.Spoke.Template).To(Equal("standard"))
+				Expect(cfg.Defaults.Spoke.TemplateDir).To(Equal(filepath.Join(os.Getenv("HOME"), "labrat-templates")))
+				Expect(cfg.Defaults.Spoke.Values).To(HaveKeyWithValue("workerType", "m6i.2xlarge"))
+				Expect(cfg.Defaults.Spoke.Snippets).To(Equal([]string{"fips"}))
+				Expect(cfg.Defaults.Spoke.SnippetDir).To(Equal(filepath.Join(os.Getenv("HOME"), "labrat-snippets")))
+			})
+
+			It("should parse the defaults of each platform", func() {
+				cfg, err := config.Load(configPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				spoke := cfg.Defaults.Spoke
+				Expect(spoke.Azure.BaseDomainResourceGroup).To(Equal("labs-dns"))
+				Expect(spoke.Platform("azure")).To(Equal(config.PlatformDefaults{
+					Credentials: "azure-partner-lab",
+					Region:      "eastus",
+					ComputeType: "Standard_D8s_v3",
+				}))
+				Expect(spoke.VSphere.PortGroup).To(Equal("VM Network"))
+				Expect(spoke.VSphere.APIVIP).To(Equal("192.168.10.10"))
+				Expect(spoke.Platform("vsphere").CredentialsNamespace).To(Equal("labrat-credentials"))
+				Expect(spoke.Platform("vsphere").Network.MachineCIDR).To(Equal("192.168.10.0/24"))
+				Expect(spoke.Platform("aws")).To(Equal(config.PlatformDefaults{}))
+			})
+
+			It("should parse serve auth configuration", func() {
+				cfg, err := config.Load(configPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cfg.Serve.Address).To(Equal("127.0.0.1:9090"))
+				Expect(cfg.Serve.TLSCertFile).To(Equal(filepath.Join(os.Getenv("HOME"), "labrat", "tls.crt")))
+
+				Expect(cfg.Serve.Auth.Tokens).To(Equal([]config.TokenConfig{{
+					Name:   "portal",
+					Role:   "viewer",
+					SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+				}}))
+				Expect(cfg.Serve.Auth.OIDC).NotTo(BeNil())
+				Expect(cfg.Serve.Auth.OIDC.IssuerURL).To(Equal("https://sso.example.com/realms/partner-labs"))
+				Expect(cfg.Serve.Auth.OIDC.Roles).To(HaveKeyWithValue("admin", []string{"partner-labs-admins"}))
+			})
+
+			It("should parse ACS configuration and expand the token file path", func() {
+				cfg, err := config.Load(configPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cfg.ACS.Endpoint).To(Equal("https://central-stackrox.apps.hub.example.com"))
+				Expect(cfg.ACS.TokenFile).To(Equal(filepath.Join(os.Getenv("HOME"), ".labrat/acs-token")))
+				Expect(cfg.ACS.InsecureSkipVerify).To(BeFalse())
+			})
+
+			It("should parse retry configuration", func() {
+				cfg, err := config.Load(configPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cfg.Retry.Retries).To(HaveValue(Equal(5)))
+				Expect(cfg.Retry.Backoff).To(Equal(2 * time.Second))
+			})
+
+			It("should parse and expand cache configuration", func() {
+				cfg, err := config.Load(configPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cfg.Cache.TTL).To(Equal(5 * time.Minute))
+				Expect(cfg.Cache.Dir).To(Equal(filepath.Join(os.Getenv("HOME"), ".cache/labrat")))
+			})
+
+			It("should parse and expand audit configuration", func() {
+				cfg, err := config.Load(configPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cfg.Audit.File).To(Equal(filepath.Join(os.Getenv("HOME"), ".labrat/audit/labrat.log")))
+				Expect(cfg.Audit.ConfigMap).To(BeTrue())
 			})
 
 			It("should set verbose to false by default", func() {
@@ -167,6 +292,172 @@ verbose: true
 				"namespace is required",
 			),
 		)
+
+		It("should reject negative retry settings", func() {
+			negative := -1
+			cfg := &config.Config{Hub: config.HubConfig{Kubeconfig: "/path/to/kubeconfig", Namespace: "open-cluster-management"}}
+
+			cfg.Retry = config.RetryConfig{Retries: &negative}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("retry retries must not be negative")))
+
+			cfg.Retry = config.RetryConfig{Backoff: -time.Second}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("retry backoff must not be negative")))
+		})
+
+		It("should reject a negative cache ttl", func() {
+			cfg := &config.Config{Hub: config.HubConfig{Kubeconfig: "/path/to/kubeconfig", Namespace: "open-cluster-management"}}
+			cfg.Cache = config.CacheConfig{TTL: -time.Minute}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("cache ttl must not be negative")))
+		})
+
+		It("should reject a negative history retention", func() {
+			cfg := &config.Config{Hub: config.HubConfig{Kubeconfig: "/path/to/kubeconfig", Namespace: "open-cluster-management"}}
+			cfg.History = config.HistoryConfig{Enabled: true, Retention: -time.Hour}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("history retention must not be negative")))
+		})
+
+		It("should reject invalid platform networks", func() {
+			cfg := &config.Config{Hub: config.HubConfig{Kubeconfig: "/path/to/kubeconfig", Namespace: "open-cluster-management"}}
+			cfg.Defaults.Spoke.GCP.Network.ServiceCIDR = "172.30.0.0"
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("defaults.spoke.gcp.network.serviceCIDR")))
+
+			cfg.Defaults.Spoke.GCP.Network.ServiceCIDR = ""
+			cfg.Defaults.Spoke.VSphere.IngressVIP = "apps.example.com"
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("defaults.spoke.vsphere.ingressVIP")))
+		})
+
+		It("should reject invalid notification URLs and unknown events", func() {
+			cfg := &config.Config{Hub: config.HubConfig{Kubeconfig: "/path/to/kubeconfig", Namespace: "open-cluster-management"}}
+			cfg.Notify.Slack.WebhookURL = "hooks.slack.com/services/T000/B000/XXX"
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("notify.slack.webhookURL must be an http or https URL")))
+
+			cfg.Notify.Slack.WebhookURL = "https://hooks.slack.com/services/T000/B000/XXX"
+			cfg.Notify.Webhook = config.WebhookNotifyConfig{URL: "https://portal.example.com/hooks/labrat", Events: []string{"failed", "deleted"}}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(`notify.webhook.events has unknown event "deleted"`)))
+
+			cfg.Notify.Webhook.Events = []string{"failed", "expiring"}
+			Expect(cfg.Validate()).To(Succeed())
+			Expect(cfg.Notify.Enabled()).To(BeTrue())
+			Expect(config.NotifyConfig{}.Enabled()).To(BeFalse())
+		})
+	})
+
+	Describe("Multiple hubs", func() {
+		var cfg *config.Config
+
+		BeforeEach(func() {
+			cfg = &config.Config{
+				Hub: config.HubConfig{Name: "production", Kubeconfig: "/prod/kubeconfig", Namespace: "open-cluster-management"},
+				Hubs: []config.HubConfig{
+					{Name: "staging", Kubeconfig: "/staging/kubeconfig", Context: "staging", Namespace: "open-cluster-management"},
+				},
+			}
+		})
+
+		It("should list every hub with the primary hub first", func() {
+			hubs := cfg.HubConfigs()
+			Expect(hubs).To(HaveLen(2))
+			Expect(hubs[0].Name).To(Equal("production"))
+			Expect(hubs[1].Name).To(Equal("staging"))
+		})
+
+		It("should name the primary hub default when hub.name is not set", func() {
+			cfg.Hub.Name = ""
+			Expect(cfg.HubName()).To(Equal(config.DefaultHubName))
+			Expect(cfg.HubConfigs()[0].Name).To(Equal(config.DefaultHubName))
+		})
+
+		It("should make the selected hub active", func() {
+			Expect(cfg.SelectHub("staging")).To(Succeed())
+			Expect(cfg.HubName()).To(Equal("staging"))
+			Expect(cfg.GetHubKubeconfig()).To(Equal("/staging/kubeconfig"))
+		})
+
+		It("should keep every hub listed once after switching", func() {
+			Expect(cfg.SelectHub("staging")).To(Succeed())
+			names := []string{}
+			for _, h := range cfg.HubConfigs() {
+				names = append(names, h.Name)
+			}
+			Expect(names).To(Equal([]string{"staging", "production"}))
+
+			Expect(cfg.SelectHub("production")).To(Succeed())
+			Expect(cfg.GetHubKubeconfig()).To(Equal("/prod/kubeconfig"))
+		})
+
+		It("should reject a standby that is not another configured hub", func() {
+			cfg.Hub.Standby = "dr"
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(`standby of hub production must name another configured hub, got "dr"`)))
+
+			cfg.Hub.Standby = "staging"
+			Expect(cfg.Validate()).To(Succeed())
+		})
+
+		It("should reject an unknown active hub", func() {
+			cfg.ActiveHub = "dr"
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(`activeHub "dr" is not a configured hub`)))
+		})
+
+		Context("when loaded from a file", func() {
+			const multiHubConfig = `# Partner Labs hubs
+hub:
+  name: production
+  kubeconfig: /prod/kubeconfig
+  namespace: open-cluster-management
+  standby: staging
+
+hubs:
+  - name: staging
+    kubeconfig: /staging/kubeconfig
+    namespace: open-cluster-management
+`
+
+			BeforeEach(func() {
+				Expect(os.WriteFile(configPath, []byte(multiHubConfig), 0600)).To(Succeed())
+			})
+
+			It("should activate the primary hub by default", func() {
+				loaded, err := config.Load(configPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(loaded.HubName()).To(Equal("production"))
+				Expect(loaded.Hub.Standby).To(Equal("staging"))
+			})
+
+			It("should record and apply the active hub", func() {
+				Expect(config.SetActiveHub(configPath, "staging")).To(Succeed())
+
+				loaded, err := config.Load(configPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(loaded.HubName()).To(Equal("staging"))
+				Expect(loaded.GetHubKubeconfig()).To(Equal("/staging/kubeconfig"))
+
+				data, err := os.ReadFile(configPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(HavePrefix("# Partner Labs hubs\nhub:\n  name: production\n"))
+				Expect(string(data)).To(HaveSuffix("activeHub: staging\n"))
+
+				Expect(config.SetActiveHub(configPath, "production")).To(Succeed())
+				data, err = os.ReadFile(configPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strings.Count(string(data), "activeHub")).To(Equal(1))
+			})
+		})
+
+		It("should reject unknown hub names", func() {
+			Expect(cfg.SelectHub("dr")).To(MatchError(`unknown hub "dr" (configured hubs: production, staging)`))
+		})
+
+		DescribeTable("validating additional hubs",
+			func(extra config.HubConfig, expectedError string) {
+				cfg.Hubs = append(cfg.Hubs, extra)
+				Expect(cfg.Validate()).To(MatchError(ContainSubstring(expectedError)))
+			},
+			Entry("missing name", config.HubConfig{Kubeconfig: "/k", Namespace: "ns"}, "hubs[1] name is required"),
+			Entry("reserved name", config.HubConfig{Name: "all", Kubeconfig: "/k", Namespace: "ns"}, `name "all" is reserved`),
+			Entry("duplicate name", config.HubConfig{Name: "production", Kubeconfig: "/k", Namespace: "ns"}, `"production" is used more than once`),
+			Entry("missing kubeconfig", config.HubConfig{Name: "dr", Namespace: "ns"}, "hub dr kubeconfig is required"),
+			Entry("missing namespace", config.HubConfig{Name: "dr", Kubeconfig: "/k"}, "hub dr namespace is required"),
+		)
 	})
 
 	Describe("GetHubKubeconfig", func() {
@@ -183,6 +474,390 @@ verbose: true
 		})
 	})
 
+	Describe("Save", func() {
+		It("should write a config that loads back", func() {
+			cfg := config.NewDefaultConfig()
+			cfg.Hub.Kubeconfig = "/home/user/.kube/config"
+			cfg.Hub.Context = "hub-cluster"
+			cfg.Defaults.Spoke.Provider = "aws"
+			savePath := filepath.Join(tempDir, "nested", "config.yaml")
+
+			Expect(cfg.Save(savePath)).To(Succeed())
+
+			info, err := os.Stat(savePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+			loaded, err := config.Load(savePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.Hub).To(Equal(cfg.Hub))
+			Expect(loaded.Defaults).To(Equal(cfg.Defaults))
+		})
+
+		It("should not write an invalid config", func() {
+			err := config.NewDefaultConfig().Save(configPath)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("hub kubeconfig is required"))
+
+			_, err = os.Stat(configPath)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Describe("Check", func() {
+		It("should report every problem of a config file", func() {
+			kubeconfig := filepath.Join(tempDir, "kubeconfig")
+			Expect(os.WriteFile(kubeconfig, []byte("apiVersion: v1\n"), 0600)).To(Succeed())
+			Expect(os.WriteFile(configPath, []byte(`hub:
+  kubeconfig: `+kubeconfig+`
+  namepsace: open-cluster-management
+defaults:
+  spoke:
+    provider: openstack
+    aws:
+      regoin: us-east-1
+cache:
+  ttl: 2 minutes
+retry:
+  retries: many
+acs:
+  tokenFile: `+filepath.Join(tempDir, "missing-token")+`
+`), 0644)).To(Succeed())
+
+			problems, err := config.Check(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(ConsistOf(
+				config.Problem{Field: "hub.namepsace", Severity: config.SeverityWarning, Line: 3, Message: "unknown key hub.namepsace, did you mean namespace?"},
+				config.Problem{Field: "defaults.spoke.aws.regoin", Severity: config.SeverityWarning, Line: 8, Message: "unknown key defaults.spoke.aws.regoin, did you mean region?"},
+				config.Problem{Field: "cache.ttl", Severity: config.SeverityError, Line: 10, Message: `cache.ttl must be a duration such as 30s or 2m, got "2 minutes"`},
+				config.Problem{Field: "retry.retries", Severity: config.SeverityError, Line: 12, Message: "retry.retries must be an integer"},
+				config.Problem{Field: "hub.namespace", Severity: config.SeverityError, Message: "hub namespace is required"},
+				config.Problem{Field: "defaults.spoke.provider", Severity: config.SeverityWarning, Message: `defaults.spoke.provider "openstack" is not supported (supported: aws, azure, gcp, vsphere, on-prem)`},
+				config.Problem{Field: "acs.tokenFile", Severity: config.SeverityWarning, Message: "acs.tokenFile " + filepath.Join(tempDir, "missing-token") + " does not exist"},
+			))
+		})
+
+		It("should accept the keys of inlined platform defaults and maps", func() {
+			Expect(os.WriteFile(configPath, []byte(`hub:
+  kubeconfig: /path/to/kubeconfig
+  namespace: open-cluster-management
+defaults:
+  spoke:
+    provider: azure
+    values:
+      owner: jdoe
+    azure:
+      region: eastus
+      baseDomainResourceGroup: dns
+cache:
+  ttl: 2m
+serve:
+  auth:
+    oidc:
+      roles:
+        admin: [labrat-admins]
+`), 0644)).To(Succeed())
+
+			problems, err := config.Check(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(ConsistOf(
+				config.Problem{Field: "hub.kubeconfig", Severity: config.SeverityWarning, Message: "hub.kubeconfig /path/to/kubeconfig does not exist"},
+			))
+		})
+
+		It("should fail on files that are not YAML", func() {
+			Expect(os.WriteFile(configPath, []byte("hub: [unclosed\n"), 0644)).To(Succeed())
+
+			_, err := config.Check(configPath)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse config")))
+		})
+	})
+
+	Describe("Problems", func() {
+		It("should report every invalid hub instead of the first one", func() {
+			cfg := &config.Config{
+				Hub:  config.HubConfig{Kubeconfig: "/path/to/kubeconfig", Namespace: "open-cluster-management", Standby: "dr"},
+				Hubs: []config.HubConfig{{Kubeconfig: "/k"}, {Name: "all", Kubeconfig: "/k", Namespace: "ns"}},
+			}
+
+			var messages []string
+			for _, problem := range cfg.Problems() {
+				if problem.Severity == config.SeverityError {
+					messages = append(messages, problem.Message)
+				}
+			}
+			Expect(messages).To(Equal([]string{
+				"hubs[0] name is required",
+				"hub hubs[0] namespace is required",
+				`hubs[1] name "all" is reserved`,
+				`standby of hub default must name another configured hub, got "dr"`,
+			}))
+			Expect(cfg.Validate()).To(MatchError("validation failed: hubs[0] name is required"))
+		})
+
+		It("should not fail validation on warnings", func() {
+			cfg := &config.Config{Hub: config.HubConfig{Kubeconfig: "/path/to/kubeconfig", Namespace: "open-cluster-management"}}
+			cfg.Defaults.Spoke.Provider = "openstack"
+
+			Expect(cfg.Problems()).To(HaveLen(2))
+			Expect(cfg.Validate()).To(Succeed())
+		})
+	})
+
+	Describe("Set", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(configPath, []byte(`# labrat config
+hub:
+  kubeconfig: /home/user/.kube/config # the ACM hub
+  namespace: open-cluster-management
+hubs:
+  - name: staging
+    kubeconfig: /home/user/.kube/staging
+    namespace: open-cluster-management
+`), 0o640)).To(Succeed())
+		})
+
+		It("should add a key and keep the comments of the file", func() {
+			Expect(config.Set(configPath, "defaults.spoke.region", "us-west-2")).To(Succeed())
+
+			data, err := os.ReadFile(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("# labrat config"))
+			Expect(string(data)).To(ContainSubstring("# the ACM hub"))
+
+			cfg, err := config.Load(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Defaults.Spoke.Region).To(Equal("us-west-2"))
+
+			info, err := os.Stat(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o640)))
+		})
+
+		It("should set typed values, template values, and list items", func() {
+			Expect(config.Set(configPath, "cache.ttl", "2m")).To(Succeed())
+			Expect(config.Set(configPath, "retry.retries", "5")).To(Succeed())
+			Expect(config.Set(configPath, "verbose", "true")).To(Succeed())
+			Expect(config.Set(configPath, "defaults.spoke.values.workerType", "m6i.2xlarge")).To(Succeed())
+			Expect(config.Set(configPath, "hubs[0].namespace", "multicluster-engine")).To(Succeed())
+			Expect(config.Set(configPath, "hub.context", "true")).To(Succeed())
+
+			cfg, err := config.Load(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Cache.TTL).To(Equal(2 * time.Minute))
+			Expect(cfg.Retry.Retries).To(HaveValue(Equal(5)))
+			Expect(cfg.Verbose).To(BeTrue())
+			Expect(cfg.Defaults.Spoke.Values).To(HaveKeyWithValue("workerType", "m6i.2xlarge"))
+			Expect(cfg.Hubs[0].Namespace).To(Equal("multicluster-engine"))
+			Expect(cfg.Hub.Context).To(Equal("true"))
+		})
+
+		It("should create a config file that does not exist", func() {
+			newPath := filepath.Join(tempDir, "new", "config.yaml")
+
+			Expect(config.Set(newPath, "hub.kubeconfig", "/home/user/.kube/config")).To(Succeed())
+
+			info, err := os.Stat(newPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+		})
+
+		DescribeTable("refusing keys and values",
+			func(key, value, message string) {
+				before, err := os.ReadFile(configPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = config.Set(configPath, key, value)
+				Expect(err).To(MatchError(ContainSubstring(message)))
+
+				after, err := os.ReadFile(configPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(after).To(Equal(before))
+			},
+			Entry("unknown key", "defaults.spoke.regoin", "x", "unknown config key defaults.spoke.regoin, did you mean defaults.spoke.region?"),
+			Entry("invalid duration", "cache.ttl", "soon", "cache.ttl must be a duration"),
+			Entry("invalid integer", "retry.retries", "many", "retry.retries must be an integer"),
+			Entry("invalid boolean", "verbose", "maybe", "verbose must be true or false"),
+			Entry("invalid value", "retry.retries", "-1", "retry retries must not be negative"),
+			Entry("section", "defaults.spoke", "x", "defaults.spoke is not a single value"),
+			Entry("list", "hubs", "x", "hubs is not a single value"),
+			Entry("list without index", "hubs.namespace", "x", "select an item such as hubs[0]"),
+			Entry("missing list item", "hubs[3].namespace", "x", "hubs has no item 3"),
+		)
+	})
+
+	Describe("Redacted", func() {
+		It("should redact token hashes and secret template values", func() {
+			cfg := config.NewDefaultConfig()
+			cfg.Serve.Auth.Tokens = []config.TokenConfig{{Name: "portal", Role: "viewer", SHA256: "9f86d0"}}
+			cfg.Defaults.Spoke.Values = map[string]string{"pullSecret": "{}", "workerType": "m6i.2xlarge"}
+
+			redacted := cfg.Redacted()
+			Expect(redacted.Serve.Auth.Tokens).To(Equal([]config.TokenConfig{{Name: "portal", Role: "viewer", SHA256: config.RedactedValue}}))
+			Expect(redacted.Defaults.Spoke.Values).To(Equal(map[string]string{"pullSecret": config.RedactedValue, "workerType": "m6i.2xlarge"}))
+
+			Expect(cfg.Serve.Auth.Tokens[0].SHA256).To(Equal("9f86d0"))
+			Expect(cfg.Defaults.Spoke.Values).To(HaveKeyWithValue("pullSecret", "{}"))
+		})
+
+		It("should redact the Slack webhook URL and the webhook headers", func() {
+			cfg := config.NewDefaultConfig()
+			cfg.Notify.Slack.WebhookURL = "https://hooks.slack.com/services/T000/B000/XXX"
+			cfg.Notify.Webhook = config.WebhookNotifyConfig{URL: "https://portal.example.com/hooks/labrat", Headers: map[string]string{"Authorization": "Bearer abc"}}
+
+			redacted := cfg.Redacted()
+			Expect(redacted.Notify.Slack.WebhookURL).To(Equal(config.RedactedValue))
+			Expect(redacted.Notify.Webhook.URL).To(Equal("https://portal.example.com/hooks/labrat"))
+			Expect(redacted.Notify.Webhook.Headers).To(Equal(map[string]string{"Authorization": config.RedactedValue}))
+			Expect(cfg.Notify.Webhook.Headers).To(HaveKeyWithValue("Authorization", "Bearer abc"))
+		})
+	})
+
+	Describe("Environment overrides", func() {
+		DescribeTable("naming the variable of a field",
+			func(path, name string) {
+				Expect(config.EnvVar(path)).To(Equal(name))
+			},
+			Entry("hub kubeconfig", "hub.kubeconfig", "LABRAT_HUB_KUBECONFIG"),
+			Entry("camel case key", "serve.tlsCertFile", "LABRAT_SERVE_TLS_CERT_FILE"),
+			Entry("trailing acronym", "defaults.spoke.aws.network.machineCIDR", "LABRAT_DEFAULTS_SPOKE_AWS_NETWORK_MACHINE_CIDR"),
+			Entry("acronym before a word", "serve.auth.oidc.issuerURL", "LABRAT_SERVE_AUTH_OIDC_ISSUER_URL"),
+			Entry("slack webhook", "notify.slack.webhookURL", "LABRAT_NOTIFY_SLACK_WEBHOOK_URL"),
+		)
+
+		It("should override the fields that are set", func() {
+			cfg := config.NewDefaultConfig()
+			cfg.Hub.Kubeconfig = "/from/file"
+
+			Expect(cfg.ApplyEnv([]string{
+				"LABRAT_HUB_CONTEXT=hub-admin",
+				"LABRAT_HUB_NAMESPACE=acm",
+				"LABRAT_DEFAULTS_SPOKE_REGION=eu-west-1",
+				"LABRAT_DEFAULTS_SPOKE_AWS_NETWORK_MACHINE_CIDR=10.1.0.0/16",
+				"LABRAT_ACS_INSECURE_SKIP_VERIFY=true",
+				"LABRAT_RETRY_RETRIES=5",
+				"LABRAT_CACHE_TTL=2m",
+				"LABRAT_UNKNOWN=ignored",
+				"PATH=/usr/bin",
+			})).To(Succeed())
+
+			Expect(cfg.Hub.Kubeconfig).To(Equal("/from/file"))
+			Expect(cfg.Hub.Context).To(Equal("hub-admin"))
+			Expect(cfg.Hub.Namespace).To(Equal("acm"))
+			Expect(cfg.Defaults.Spoke.Region).To(Equal("eu-west-1"))
+			Expect(cfg.Defaults.Spoke.AWS.Network.MachineCIDR).To(Equal("10.1.0.0/16"))
+			Expect(cfg.ACS.InsecureSkipVerify).To(BeTrue())
+			Expect(cfg.Retry.Retries).To(HaveValue(Equal(5)))
+			Expect(cfg.Cache.TTL).To(Equal(2 * time.Minute))
+			Expect(cfg.Serve.Auth.OIDC).To(BeNil())
+		})
+
+		It("should allocate optional sections only when overridden", func() {
+			cfg := config.NewDefaultConfig()
+
+			Expect(cfg.ApplyEnv([]string{"LABRAT_SERVE_AUTH_OIDC_ISSUER_URL=https://sso.example.com"})).To(Succeed())
+			Expect(cfg.Serve.Auth.OIDC).NotTo(BeNil())
+			Expect(cfg.Serve.Auth.OIDC.IssuerURL).To(Equal("https://sso.example.com"))
+		})
+
+		DescribeTable("rejecting invalid values",
+			func(entry, message string) {
+				err := config.NewDefaultConfig().ApplyEnv([]string{entry})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("duration", "LABRAT_CACHE_TTL=soon", "invalid LABRAT_CACHE_TTL"),
+			Entry("integer", "LABRAT_RETRY_RETRIES=many", "invalid LABRAT_RETRY_RETRIES"),
+			Entry("boolean", "LABRAT_VERBOSE=maybe", "invalid LABRAT_VERBOSE"),
+		)
+
+		It("should apply the environment over the config file when loading", func() {
+			Expect(os.WriteFile(configPath, []byte(`
+hub:
+  kubeconfig: /from/file
+  namespace: open-cluster-management
+`), 0o600)).To(Succeed())
+			GinkgoT().Setenv("LABRAT_HUB_KUBECONFIG", "$HOME/env-kubeconfig")
+
+			cfg, err := config.Load(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Hub.Kubeconfig).To(Equal(filepath.Join(os.Getenv("HOME"), "env-kubeconfig")))
+			Expect(cfg.Hub.Namespace).To(Equal("open-cluster-management"))
+		})
+
+		It("should apply the hub overrides to the active hub when loading", func() {
+			Expect(os.WriteFile(configPath, []byte(`
+hub:
+  kubeconfig: /from/file
+  namespace: open-cluster-management
+hubs:
+  - name: dr
+    kubeconfig: /from/file-dr
+    context: dr-admin
+    namespace: open-cluster-management
+activeHub: dr
+`), 0o600)).To(Succeed())
+			GinkgoT().Setenv("LABRAT_HUB_KUBECONFIG", "$HOME/env-kubeconfig")
+			GinkgoT().Setenv("LABRAT_HUB_NAMESPACE", "acm")
+
+			cfg, err := config.Load(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.HubName()).To(Equal("dr"))
+			Expect(cfg.Hub.Kubeconfig).To(Equal(filepath.Join(os.Getenv("HOME"), "env-kubeconfig")))
+			Expect(cfg.Hub.Context).To(Equal("dr-admin"))
+			Expect(cfg.Hub.Namespace).To(Equal("acm"))
+			Expect(cfg.Hubs).To(Equal([]config.HubConfig{
+				{Name: config.DefaultHubName, Kubeconfig: "/from/file", Namespace: "open-cluster-management"},
+			}))
+		})
+
+		It("should move the hub overrides to the hub selected after loading", func() {
+			Expect(os.WriteFile(configPath, []byte(`
+hub:
+  kubeconfig: /from/file
+  namespace: open-cluster-management
+hubs:
+  - name: dr
+    kubeconfig: /from/file-dr
+    namespace: open-cluster-management
+`), 0o600)).To(Succeed())
+			GinkgoT().Setenv("LABRAT_HUB_CONTEXT", "admin")
+
+			cfg, err := config.Load(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Hub.Context).To(Equal("admin"))
+
+			Expect(cfg.SelectHub("dr")).To(Succeed())
+			Expect(cfg.Hub).To(Equal(config.HubConfig{Name: "dr", Kubeconfig: "/from/file-dr", Context: "admin", Namespace: "open-cluster-management"}))
+			Expect(cfg.Hubs[0].Context).To(BeEmpty())
+
+			Expect(cfg.SelectHub(config.DefaultHubName)).To(Succeed())
+			Expect(cfg.Hub.Context).To(Equal("admin"))
+			Expect(cfg.Hubs[0].Context).To(BeEmpty())
+		})
+
+		It("should validate the overridden configuration", func() {
+			Expect(os.WriteFile(configPath, []byte(`
+hub:
+  kubeconfig: /from/file
+  namespace: open-cluster-management
+`), 0o600)).To(Succeed())
+			GinkgoT().Setenv("LABRAT_HUB_NAMESPACE", "")
+
+			_, err := config.Load(configPath)
+			Expect(err).To(MatchError(ContainSubstring("hub namespace is required")))
+		})
+
+		It("should apply the environment to the default configuration", func() {
+			GinkgoT().Setenv("LABRAT_HUB_KUBECONFIG", "/etc/labrat/hub.kubeconfig")
+
+			cfg, err := config.Default()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Hub.Kubeconfig).To(Equal("/etc/labrat/hub.kubeconfig"))
+			Expect(cfg.Hub.Namespace).To(Equal("open-cluster-management"))
+		})
+	})
+
 	Describe("Default Configuration", func() {
 		Context("when loading defaults", func() {
 			It("should provide sensible defaults for missing optional fields", func() {
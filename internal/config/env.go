@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// EnvPrefix starts the names of the environment variables that override config fields
+const EnvPrefix = "LABRAT_"
+
+// EnvVar returns the environment variable overriding the config field at a YAML path, e.g.
+// LABRAT_HUB_KUBECONFIG for hub.kubeconfig and LABRAT_SERVE_TLS_CERT_FILE for
+// serve.tlsCertFile
+func EnvVar(path string) string {
+	var name strings.Builder
+	name.WriteString(strings.TrimSuffix(EnvPrefix, "_"))
+	for _, key := range strings.Split(path, ".") {
+		name.WriteString("_" + upperSnakeCase(key))
+	}
+	return name.String()
+}
+
+// ApplyEnv overrides the fields of the configuration with the LABRAT_ environment variables
+// of environ, as os.Environ returns it. Every string, boolean, number, and duration field can
+// be overridden, except those in lists and maps such as hubs and defaults.spoke.values.
+// The LABRAT_HUB_ variables override the active hub, and move with it when SelectHub selects
+// another one.
+func (c *Config) ApplyEnv(environ []string) error {
+	env := make(map[string]string)
+	for _, entry := range environ {
+		if name, value, ok := strings.Cut(entry, "="); ok && strings.HasPrefix(name, EnvPrefix) {
+			env[name] = value
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	configured := c.Hub
+	if _, err := applyEnv(reflect.ValueOf(c).Elem(), strings.TrimSuffix(EnvPrefix, "_"), env); err != nil {
+		return err
+	}
+	if c.Hub != configured {
+		c.hubEnv, c.configuredHub = env, configured
+	}
+	return nil
+}
+
+// applyEnv sets v from the environment variable name, or the fields of a struct v from the
+// variables name_FIELD, and reports whether any variable was set
+func applyEnv(v reflect.Value, name string, env map[string]string) (bool, error) {
+	if v.Kind() == reflect.Struct {
+		changed := false
+		for i := 0; i < v.NumField(); i++ {
+			key, inline, ok := yamlKey(v.Type().Field(i))
+			if !ok {
+				continue
+			}
+			fieldName := name
+			if !inline {
+				fieldName = name + "_" + upperSnakeCase(key)
+			}
+			fieldChanged, err := applyEnv(v.Field(i), fieldName, env)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || fieldChanged
+		}
+		return changed, nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		// Only allocate optional sections and values that are overridden
+		value := reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			value.Elem().Set(v.Elem())
+		}
+		changed, err := applyEnv(value.Elem(), name, env)
+		if changed {
+			v.Set(value)
+		}
+		return changed, err
+	}
+
+	raw, ok := env[name]
+	if !ok {
+		return false, nil
+	}
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s %q: must be a duration such as 30s or 2m", name, raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s %q: must be true or false", name, raw)
+		}
+		v.SetBool(b)
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return false, fmt.Errorf("invalid %s %q: must be an integer", name, raw)
+		}
+		v.SetInt(n)
+	default:
+		// Lists and maps have no environment variables
+		return false, nil
+	}
+	return true, nil
+}
+
+// upperSnakeCase converts a camel case YAML key to upper snake case, keeping acronyms
+// together: tlsCertFile becomes TLS_CERT_FILE and machineCIDR becomes MACHINE_CIDR
+func upperSnakeCase(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Set sets the key at a YAML path such as defaults.spoke.region or hubs[1].namespace to
+// value in the config file at path, preserving the rest of the file including comments.
+// The file is created if it does not exist. Keys that are not part of the configuration,
+// values of the wrong type, and values that make the configuration invalid are refused.
+func Set(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to parse config: top level is not a mapping")
+	}
+
+	steps, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+	if err := setNode(doc.Content[0], reflect.TypeOf(Config{}), steps, "", value); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	// Refuse the errors the new value causes, but not those the file already had, so that a
+	// new config file can be written one key at a time
+	before, err := checkData(data)
+	if err != nil {
+		return err
+	}
+	after, err := checkData(out.Bytes())
+	if err != nil {
+		return err
+	}
+	for _, problem := range after {
+		if problem.Severity == SeverityError && !hasError(before, problem.Message) {
+			return fmt.Errorf("validation failed: %s", problem.Message)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), mode); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// hasError reports whether problems include an error with message
+func hasError(problems []Problem, message string) bool {
+	for _, problem := range problems {
+		if problem.Severity == SeverityError && problem.Message == message {
+			return true
+		}
+	}
+	return false
+}
+
+// splitKey splits a key such as hubs[1].namespace into the steps hubs, [1], and namespace
+func splitKey(key string) ([]string, error) {
+	var steps []string
+	for _, part := range strings.Split(key, ".") {
+		name, index, indexed := strings.Cut(part, "[")
+		if name == "" {
+			return nil, fmt.Errorf("invalid config key %q", key)
+		}
+		steps = append(steps, name)
+		if indexed {
+			if !strings.HasSuffix(index, "]") {
+				return nil, fmt.Errorf("invalid config key %q", key)
+			}
+			steps = append(steps, "["+index)
+		}
+	}
+	return steps, nil
+}
+
+// setNode sets the key at steps below node, the field of type t at path field, to value,
+// adding the mappings that do not exist yet
+func setNode(node *yaml.Node, t reflect.Type, steps []string, field, value string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if len(steps) == 0 {
+		return setScalar(node, t, field, value)
+	}
+
+	step := steps[0]
+	switch {
+	case t.Kind() == reflect.Struct && t != durationType:
+		fields := yamlFields(t)
+		fieldType, ok := fields[step]
+		if !ok {
+			message := fmt.Sprintf("unknown config key %s", joinField(field, step))
+			if suggestion := closestKey(step, fields); suggestion != "" {
+				message += fmt.Sprintf(", did you mean %s?", joinField(field, suggestion))
+			}
+			return errors.New(message)
+		}
+		child, err := mappingValue(node, field, step)
+		if err != nil {
+			return err
+		}
+		return setNode(child, fieldType, steps[1:], joinField(field, step), value)
+	case t.Kind() == reflect.Map:
+		child, err := mappingValue(node, field, step)
+		if err != nil {
+			return err
+		}
+		return setNode(child, t.Elem(), steps[1:], joinField(field, step), value)
+	case t.Kind() == reflect.Slice:
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(step, "["), "]"))
+		if !strings.HasPrefix(step, "[") || err != nil {
+			return fmt.Errorf("%s is a list; select an item such as %s[0]", field, field)
+		}
+		if node.Kind != yaml.SequenceNode || index < 0 || index >= len(node.Content) {
+			return fmt.Errorf("%s has no item %d", field, index)
+		}
+		return setNode(node.Content[index], t.Elem(), steps[1:], fmt.Sprintf("%s[%d]", field, index), value)
+	default:
+		return fmt.Errorf("%s is a single value and has no key %s", field, step)
+	}
+}
+
+// mappingValue returns the value of key in the mapping node, adding the key if it is missing.
+// An empty node becomes a mapping.
+func mappingValue(node *yaml.Node, field, key string) (*yaml.Node, error) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: node.HeadComment, LineComment: node.LineComment}
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s is not a mapping in the config file", field)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1], nil
+		}
+	}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value, nil
+}
+
+// setScalar sets node, the field of type t at path field, to value after checking that it
+// is of the type of the field
+func setScalar(node *yaml.Node, t reflect.Type, field, value string) error {
+	tag := "!!str"
+	switch {
+	case t == durationType:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("%s must be a duration such as 30s or 2m, got %q", field, value)
+		}
+	case t.Kind() == reflect.String:
+	case t.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", field, value)
+		}
+		tag, value = "!!bool", strconv.FormatBool(b)
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		if _, err := strconv.ParseInt(value, 10, t.Bits()); err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", field, value)
+		}
+		tag = "!!int"
+	default:
+		return fmt.Errorf("%s is not a single value; set one of its keys instead", field)
+	}
+
+	node.Kind, node.Tag, node.Value, node.Style, node.Content = yaml.ScalarNode, tag, value, 0, nil
+	return nil
+}
@@ -0,0 +1,403 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SupportedProviders lists the spoke providers accepted in defaults.spoke.provider
+var SupportedProviders = []string{"aws", "azure", "gcp", "vsphere", "on-prem"}
+
+// Severity tells whether a problem of the configuration keeps labrat from using it
+type Severity string
+
+const (
+	// SeverityError marks problems that make Load fail
+	SeverityError Severity = "error"
+	// SeverityWarning marks problems labrat tolerates, such as unknown keys, which are
+	// ignored, or files that do not exist on this machine
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a problem of a configuration
+type Problem struct {
+	// Field is the path of the key the problem is about, e.g. defaults.spoke.provider
+	Field    string   `json:"field,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Line is the line of the key in the config file, 0 if it is not known
+	Line int `json:"line,omitempty"`
+}
+
+// String returns the message of the problem, prefixed with its line if it is known
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+	}
+	return p.Message
+}
+
+// durationType is decoded from strings such as 30s or 2m
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Validate checks if the configuration is valid, returning its first error. Warnings are
+// not errors; see Problems.
+func (c *Config) Validate() error {
+	for _, problem := range c.Problems() {
+		if problem.Severity == SeverityError {
+			return fmt.Errorf("validation failed: %s", problem.Message)
+		}
+	}
+	return nil
+}
+
+// Problems returns every problem of the configuration: missing required fields, invalid
+// values, hubs that are not configured, unsupported providers, and files that do not exist
+func (c *Config) Problems() []Problem {
+	var problems []Problem
+	addError := func(field, format string, args ...interface{}) {
+		problems = append(problems, Problem{Field: field, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+	}
+	addWarning := func(field, format string, args ...interface{}) {
+		problems = append(problems, Problem{Field: field, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Hub.Kubeconfig == "" {
+		addError("hub.kubeconfig", "hub kubeconfig is required")
+	}
+	if c.Hub.Namespace == "" {
+		addError("hub.namespace", "hub namespace is required")
+	}
+	if c.Retry.Retries != nil && *c.Retry.Retries < 0 {
+		addError("retry.retries", "retry retries must not be negative")
+	}
+	if c.Retry.Backoff < 0 {
+		addError("retry.backoff", "retry backoff must not be negative")
+	}
+	if c.Cache.TTL < 0 {
+		addError("cache.ttl", "cache ttl must not be negative")
+	}
+	if c.History.Retention < 0 {
+		addError("history.retention", "history retention must not be negative")
+	}
+	c.Defaults.Spoke.validate(addError)
+	c.validateHubs(addError)
+	c.Notify.validate(addError)
+
+	if provider := c.Defaults.Spoke.Provider; provider != "" && !slices.Contains(SupportedProviders, provider) {
+		addWarning("defaults.spoke.provider", "defaults.spoke.provider %q is not supported (supported: %s)", provider, strings.Join(SupportedProviders, ", "))
+	}
+
+	paths := [][2]string{{"hub.kubeconfig", c.Hub.Kubeconfig}}
+	for i, h := range c.Hubs {
+		paths = append(paths, [2]string{fmt.Sprintf("hubs[%d].kubeconfig", i), h.Kubeconfig})
+	}
+	paths = append(paths,
+		[2]string{"serve.tlsCertFile", c.Serve.TLSCertFile},
+		[2]string{"serve.tlsKeyFile", c.Serve.TLSKeyFile},
+		[2]string{"acs.tokenFile", c.ACS.TokenFile},
+		[2]string{"defaults.spoke.templateDir", c.Defaults.Spoke.TemplateDir},
+		[2]string{"defaults.spoke.snippetDir", c.Defaults.Spoke.SnippetDir},
+	)
+	for _, path := range paths {
+		if path[1] == "" {
+			continue
+		}
+		if _, err := os.Stat(path[1]); errors.Is(err, os.ErrNotExist) {
+			addWarning(path[0], "%s %s does not exist", path[0], path[1])
+		}
+	}
+	return problems
+}
+
+// validate checks the networks of the platform defaults
+func (d SpokeDefaults) validate(addError func(field, format string, args ...interface{})) {
+	for _, provider := range []string{"aws", "azure", "gcp", "vsphere"} {
+		network := d.Platform(provider).Network
+		for _, cidr := range [][2]string{
+			{"machineCIDR", network.MachineCIDR},
+			{"clusterCIDR", network.ClusterCIDR},
+			{"serviceCIDR", network.ServiceCIDR},
+		} {
+			if _, _, err := net.ParseCIDR(cidr[1]); cidr[1] != "" && err != nil {
+				field := fmt.Sprintf("defaults.spoke.%s.network.%s", provider, cidr[0])
+				addError(field, "%s %q is not a CIDR", field, cidr[1])
+			}
+		}
+	}
+	for _, ip := range [][2]string{{"apiVIP", d.VSphere.APIVIP}, {"ingressVIP", d.VSphere.IngressVIP}} {
+		if ip[1] != "" && net.ParseIP(ip[1]) == nil {
+			field := "defaults.spoke.vsphere." + ip[0]
+			addError(field, "%s %q is not an IP address", field, ip[1])
+		}
+	}
+}
+
+// validate checks that the notification URLs are HTTP(S) URLs and the events are known
+func (n NotifyConfig) validate(addError func(field, format string, args ...interface{})) {
+	for _, target := range []struct {
+		urlField, url, eventsField string
+		events                     []string
+	}{
+		{"notify.slack.webhookURL", n.Slack.WebhookURL, "notify.slack.events", n.Slack.Events},
+		{"notify.webhook.url", n.Webhook.URL, "notify.webhook.events", n.Webhook.Events},
+	} {
+		if target.url != "" {
+			if u, err := url.Parse(target.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				addError(target.urlField, "%s must be an http or https URL", target.urlField)
+			}
+		}
+		for _, event := range target.events {
+			if !slices.Contains(NotifyEvents, event) {
+				addError(target.eventsField, "%s has unknown event %q (known: %s)", target.eventsField, event, strings.Join(NotifyEvents, ", "))
+			}
+		}
+	}
+}
+
+// validateHubs checks the additional hubs: each needs a unique name, a kubeconfig, and a namespace
+func (c *Config) validateHubs(addError func(field, format string, args ...interface{})) {
+	seen := map[string]bool{c.HubName(): true}
+	for i, h := range c.Hubs {
+		field := fmt.Sprintf("hubs[%d]", i)
+		switch {
+		case h.Name == "":
+			addError(field+".name", "hubs[%d] name is required", i)
+		case h.Name == AllHubs:
+			addError(field+".name", "hubs[%d] name %q is reserved", i, AllHubs)
+		case seen[h.Name]:
+			addError(field+".name", "hub name %q is used more than once", h.Name)
+		}
+		seen[h.Name] = true
+		name := h.Name
+		if name == "" {
+			name = field
+		}
+		if h.Kubeconfig == "" {
+			addError(field+".kubeconfig", "hub %s kubeconfig is required", name)
+		}
+		if h.Namespace == "" {
+			addError(field+".namespace", "hub %s namespace is required", name)
+		}
+	}
+
+	for _, h := range c.HubConfigs() {
+		if h.Standby == "" {
+			continue
+		}
+		if h.Standby == h.Name || !seen[h.Standby] {
+			addError("standby", "standby of hub %s must name another configured hub, got %q", h.Name, h.Standby)
+		}
+	}
+	if c.ActiveHub != "" && !seen[c.ActiveHub] {
+		addError("activeHub", "activeHub %q is not a configured hub", c.ActiveHub)
+	}
+}
+
+// Check reads the configuration file at path and returns every problem of it: keys that are
+// not part of the configuration, values of the wrong type such as invalid durations, and the
+// problems of Problems. Only a file that cannot be read or is not YAML is an error.
+func Check(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return checkData(data)
+}
+
+// checkData returns every problem of the configuration file contents data
+func checkData(data []byte) ([]Problem, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	var problems []Problem
+	if len(doc.Content) > 0 {
+		problems = checkNode(doc.Content[0], reflect.TypeOf(Config{}), "")
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+		// checkNode reports the type errors with the key they are about
+		if !slices.ContainsFunc(problems, func(p Problem) bool { return p.Severity == SeverityError }) {
+			for _, message := range typeErr.Errors {
+				problems = append(problems, Problem{Severity: SeverityError, Message: message})
+			}
+		}
+	}
+	cfg.expandPaths()
+	return append(problems, cfg.Problems()...), nil
+}
+
+// checkNode checks a node of the config file against the field of type t it is decoded into,
+// reporting unknown keys and values of the wrong type
+func checkNode(node *yaml.Node, t reflect.Type, path string) []Problem {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	mismatch := func(expected string) []Problem {
+		return []Problem{{Field: path, Severity: SeverityError, Line: node.Line, Message: fmt.Sprintf("%s must be %s", path, expected)}}
+	}
+
+	switch {
+	case t == durationType:
+		if node.Kind != yaml.ScalarNode {
+			return mismatch("a duration such as 30s or 2m")
+		}
+		if _, err := time.ParseDuration(node.Value); err != nil {
+			return mismatch(fmt.Sprintf("a duration such as 30s or 2m, got %q", node.Value))
+		}
+	case t.Kind() == reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return mismatch("a mapping")
+		}
+		fields := yamlFields(t)
+		var problems []Problem
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			field := joinField(path, key.Value)
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				message := fmt.Sprintf("unknown key %s", field)
+				if suggestion := closestKey(key.Value, fields); suggestion != "" {
+					message += fmt.Sprintf(", did you mean %s?", suggestion)
+				}
+				problems = append(problems, Problem{Field: field, Severity: SeverityWarning, Line: key.Line, Message: message})
+				continue
+			}
+			problems = append(problems, checkNode(node.Content[i+1], fieldType, field)...)
+		}
+		return problems
+	case t.Kind() == reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return mismatch("a mapping")
+		}
+		var problems []Problem
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = append(problems, checkNode(node.Content[i+1], t.Elem(), joinField(path, node.Content[i].Value))...)
+		}
+		return problems
+	case t.Kind() == reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return mismatch("a list")
+		}
+		var problems []Problem
+		for i, item := range node.Content {
+			problems = append(problems, checkNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+	case t.Kind() == reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			return mismatch("true or false")
+		}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			return mismatch("an integer")
+		}
+	case t.Kind() == reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			return mismatch("a string")
+		}
+	}
+	return nil
+}
+
+// yamlFields returns the types of the fields of a struct by their YAML key, including the
+// fields of inlined structs
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, inline, ok := yamlKey(field)
+		switch {
+		case !ok:
+		case inline:
+			for key, fieldType := range yamlFields(field.Type) {
+				fields[key] = fieldType
+			}
+		default:
+			fields[key] = field.Type
+		}
+	}
+	return fields
+}
+
+// yamlKey returns the YAML key of a struct field as yaml.v3 decodes it, whether the field is
+// inlined, and false for fields that are not decoded
+func yamlKey(field reflect.StructField) (string, bool, bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return "", false, false
+	}
+	if strings.Contains(options, "inline") {
+		return "", true, true
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, false, true
+}
+
+// closestKey returns the known key a misspelled key most likely means, empty if none is close
+func closestKey(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for known := range fields {
+		distance := editDistance(strings.ToLower(key), strings.ToLower(known))
+		if distance < bestDistance || (distance == bestDistance && known < best) {
+			best, bestDistance = known, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance of two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// joinField appends a key to the path of its parent
+func joinField(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,159 @@
+// Package kubefile serves the API of a hub in memory from the Kubernetes objects of YAML and
+// JSON files, for demos and tests without a cluster, as --backend file://<dir> selects.
+// It links the fake clients of client-go, so only the labrat command imports it.
+package kubefile
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// Prefix prefixes the directory of a file backend, e.g. file://./fixtures
+const Prefix = "file://"
+
+// ParseBackend parses a backend: empty for the API servers of the kubeconfigs, or
+// file://<dir> for NewClient of the fixture files in dir
+func ParseBackend(value string) (kube.Backend, error) {
+	if value == "" {
+		return kube.APIBackend{}, nil
+	}
+	dir, ok := strings.CutPrefix(value, Prefix)
+	if !ok || dir == "" {
+		return nil, fmt.Errorf("unsupported backend %q: expected %s<dir>", value, Prefix)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access backend directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("backend %s is not a directory", dir)
+	}
+	return &fileBackend{dir: dir}, nil
+}
+
+// fileBackend serves every hub from the same NewClient, so changes made through one
+// client are seen by the others
+type fileBackend struct {
+	dir    string
+	once   sync.Once
+	client *kube.Client
+	err    error
+}
+
+func (b *fileBackend) NewClient(_, _ string, _ ...kube.Option) (*kube.Client, error) {
+	b.once.Do(func() {
+		b.client, b.err = NewClient(b.dir)
+	})
+	return b.client, b.err
+}
+
+// NewClient creates a Client whose API is served in memory from the objects of the YAML
+// and JSON files in dir and its subdirectories, for demos and tests without a cluster.
+// Changes made through the client are kept in memory only. Documents that are not
+// Kubernetes objects, such as labrat configs, are skipped. Objects of the core API groups,
+// e.g. Secrets and Events, are served by the core client too.
+func NewClient(dir string) (*kube.Client, error) {
+	objects, err := kube.LoadManifestDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicObjects := make([]runtime.Object, 0, len(objects))
+	listed := make(map[schema.GroupVersionResource]bool)
+	served := make(map[string][]metav1.APIResource)
+	var coreObjects []runtime.Object
+	for _, obj := range objects {
+		dynamicObjects = append(dynamicObjects, obj)
+		gvr, _ := meta.UnsafeGuessKindToResource(obj.GroupVersionKind())
+		if !listed[gvr] {
+			listed[gvr] = true
+			groupVersion := gvr.GroupVersion().String()
+			served[groupVersion] = append(served[groupVersion], metav1.APIResource{
+				Name:       gvr.Resource,
+				Kind:       obj.GetKind(),
+				Namespaced: obj.GetNamespace() != "",
+				Verbs:      metav1.Verbs{"get", "list", "watch", "create", "update", "patch", "delete"},
+			})
+		}
+
+		typed, err := scheme.Scheme.New(obj.GroupVersionKind())
+		if err != nil {
+			continue
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+			return nil, fmt.Errorf("failed to convert %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		coreObjects = append(coreObjects, typed)
+	}
+
+	// discovery serves the API resources of the loaded objects
+	core := kubernetesfake.NewClientset(coreObjects...)
+	discovery := core.Discovery().(*discoveryfake.FakeDiscovery)
+	for _, groupVersion := range slices.Sorted(maps.Keys(served)) {
+		discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{
+			GroupVersion: groupVersion,
+			APIResources: served[groupVersion],
+		})
+	}
+
+	dynamicClient := &fileDynamicClient{
+		FakeDynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), dynamicObjects...),
+		listed:            listed,
+	}
+	return kube.NewClientForInterfaces(Prefix+dir, dynamicClient, core), nil
+}
+
+// fileDynamicClient is the dynamic client of NewClient. The fake dynamic client can
+// only list the kinds it was created with, so the resources of other kinds list no objects.
+type fileDynamicClient struct {
+	*dynamicfake.FakeDynamicClient
+	listed map[schema.GroupVersionResource]bool
+}
+
+func (c *fileDynamicClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	resource := c.FakeDynamicClient.Resource(gvr)
+	if c.listed[gvr] {
+		return resource
+	}
+	return unlistedResource{resource}
+}
+
+// unlistedResource is a resource of a kind without fixtures, whose lists are empty
+type unlistedResource struct {
+	dynamic.NamespaceableResourceInterface
+}
+
+func (r unlistedResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return unlistedNamespacedResource{r.NamespaceableResourceInterface.Namespace(namespace)}
+}
+
+func (r unlistedResource) List(_ context.Context, _ metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return &unstructured.UnstructuredList{}, nil
+}
+
+// unlistedNamespacedResource is an unlistedResource in a namespace
+type unlistedNamespacedResource struct {
+	dynamic.ResourceInterface
+}
+
+func (r unlistedNamespacedResource) List(_ context.Context, _ metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return &unstructured.UnstructuredList{}, nil
+}
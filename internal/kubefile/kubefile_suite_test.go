@@ -0,0 +1,15 @@
+//go:build test
+
+package kubefile_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestKubefile(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Kubefile Suite")
+}
@@ -0,0 +1,107 @@
+//go:build test
+
+package kubefile_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/kubefile"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+var _ = Describe("File backend", func() {
+	var (
+		ctx           context.Context
+		managedGVR    schema.GroupVersionResource
+		deploymentGVR schema.GroupVersionResource
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		managedGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		deploymentGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+	})
+
+	Describe("NewClient", func() {
+		var client *kube.Client
+
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(dir, "hub.yaml"), []byte(`apiVersion: cluster.open-cluster-management.io/v1
+kind: ManagedCluster
+metadata:
+  name: partner-a
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: partner-a-admin-kubeconfig
+  namespace: partner-a
+data:
+  kubeconfig: a3ViZWNvbmZpZw==
+`), 0600)).To(Succeed())
+
+			var err error
+			client, err = kubefile.NewClient(dir)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should serve the objects of the files", func() {
+			list, err := client.GetDynamicClient().Resource(managedGVR).List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list.Items).To(HaveLen(1))
+			Expect(list.Items[0].GetName()).To(Equal("partner-a"))
+			Expect(client.Host()).To(HavePrefix(kubefile.Prefix))
+		})
+
+		It("should list no objects of kinds without files", func() {
+			list, err := client.GetDynamicClient().Resource(deploymentGVR).Namespace("partner-a").List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list.Items).To(BeEmpty())
+		})
+
+		It("should serve core objects by the core client", func() {
+			secret, err := client.GetCoreClient().CoreV1().Secrets("partner-a").Get(ctx, "partner-a-admin-kubeconfig", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(secret.Data["kubeconfig"])).To(Equal("kubeconfig"))
+		})
+
+		It("should serve the API resources of the objects by discovery", func() {
+			resources, err := client.GetCoreClient().Discovery().ServerResourcesForGroupVersion("cluster.open-cluster-management.io/v1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resources.APIResources).To(HaveLen(1))
+			Expect(resources.APIResources[0].Name).To(Equal("managedclusters"))
+			Expect(resources.APIResources[0].Namespaced).To(BeFalse())
+		})
+	})
+
+	Describe("ParseBackend", func() {
+		It("should share one client between the hubs of a file backend", func() {
+			backend, err := kubefile.ParseBackend(kubefile.Prefix + "../../test/fixtures")
+			Expect(err).NotTo(HaveOccurred())
+
+			first, err := backend.NewClient("/nonexistent/kubeconfig", "hub-a")
+			Expect(err).NotTo(HaveOccurred())
+			second, err := backend.NewClient("/nonexistent/kubeconfig", "hub-b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+		})
+
+		It("should reject unsupported backends", func() {
+			_, err := kubefile.ParseBackend("s3://fixtures")
+			Expect(err).To(MatchError(`unsupported backend "s3://fixtures": expected file://<dir>`))
+		})
+
+		It("should reject missing directories", func() {
+			_, err := kubefile.ParseBackend(kubefile.Prefix + "/nonexistent")
+			Expect(err).To(MatchError(ContainSubstring("failed to access backend directory")))
+		})
+	})
+})
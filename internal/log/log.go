@@ -0,0 +1,63 @@
+// Package log builds the slog loggers of labrat from the --log-level, --log-format, and
+// --verbose flags. The loggers are passed to the kube, hub, spoke, and fleet packages with
+// kube.WithLogger and fleet.Options.Logger.
+package log
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Format is the output format of log records
+type Format string
+
+const (
+	// FormatText writes records as key=value pairs
+	FormatText Format = "text"
+	// FormatJSON writes records as one JSON object per line
+	FormatJSON Format = "json"
+)
+
+// DefaultLevel is the level used when neither --log-level nor --verbose is given, so that
+// only warnings and errors are logged
+const DefaultLevel = slog.LevelWarn
+
+// ParseLevel parses a level name: debug, info, warn, or error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unsupported log level: %s (supported: debug, info, warn, error)", name)
+	}
+}
+
+// LevelForVerbosity maps the --verbose count to a level: 0 keeps DefaultLevel, 1 and above
+// log debug records including every API request
+func LevelForVerbosity(verbosity int) slog.Level {
+	if verbosity > 0 {
+		return slog.LevelDebug
+	}
+	return DefaultLevel
+}
+
+// New creates a logger writing records of at least level to w in the given format
+func New(w io.Writer, level slog.Level, format Format) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: level}
+	switch format {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format: %s (supported: text, json)", format)
+	}
+}
@@ -0,0 +1,74 @@
+//go:build test
+
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/log"
+)
+
+var _ = Describe("Log", func() {
+	Describe("ParseLevel", func() {
+		DescribeTable("should parse level names",
+			func(name string, expected slog.Level) {
+				level, err := log.ParseLevel(name)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(level).To(Equal(expected))
+			},
+			Entry("debug", "debug", slog.LevelDebug),
+			Entry("info", "INFO", slog.LevelInfo),
+			Entry("warn", "warn", slog.LevelWarn),
+			Entry("warning", "warning", slog.LevelWarn),
+			Entry("error", "error", slog.LevelError),
+		)
+
+		It("should reject unknown levels", func() {
+			_, err := log.ParseLevel("trace")
+			Expect(err).To(MatchError(ContainSubstring("unsupported log level: trace")))
+		})
+	})
+
+	Describe("LevelForVerbosity", func() {
+		It("should log debug records from -v on", func() {
+			Expect(log.LevelForVerbosity(0)).To(Equal(log.DefaultLevel))
+			Expect(log.LevelForVerbosity(1)).To(Equal(slog.LevelDebug))
+			Expect(log.LevelForVerbosity(5)).To(Equal(slog.LevelDebug))
+		})
+	})
+
+	Describe("New", func() {
+		It("should write JSON records at or above the level", func() {
+			var buf bytes.Buffer
+			logger, err := log.New(&buf, slog.LevelInfo, log.FormatJSON)
+			Expect(err).NotTo(HaveOccurred())
+
+			logger.Debug("hidden")
+			logger.Info("list ManagedClusters", "cluster", "cluster-1")
+
+			var record map[string]interface{}
+			Expect(json.Unmarshal(buf.Bytes(), &record)).To(Succeed())
+			Expect(record).To(HaveKeyWithValue("msg", "list ManagedClusters"))
+			Expect(record).To(HaveKeyWithValue("cluster", "cluster-1"))
+		})
+
+		It("should write text records", func() {
+			var buf bytes.Buffer
+			logger, err := log.New(&buf, slog.LevelDebug, log.FormatText)
+			Expect(err).NotTo(HaveOccurred())
+
+			logger.Debug("API request", "status", 200)
+			Expect(buf.String()).To(ContainSubstring(`msg="API request" status=200`))
+		})
+
+		It("should reject unknown formats", func() {
+			_, err := log.New(&bytes.Buffer{}, slog.LevelInfo, log.Format("xml"))
+			Expect(err).To(MatchError(ContainSubstring("unsupported log format: xml")))
+		})
+	})
+})
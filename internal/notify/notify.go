@@ -0,0 +1,185 @@
+// Package notify sends cluster lifecycle events, such as a finished or failed provision, an
+// expiring lease, or a hibernated cluster, to the Slack channel and HTTP webhook configured
+// under notify in the config. labrat serve and the watch modes send the events they observe.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+)
+
+// defaultTimeout limits each notification request when no HTTP client is given
+const defaultTimeout = 10 * time.Second
+
+// Event is a cluster lifecycle event
+type Event struct {
+	// Type is one of config.NotifyEvents, e.g. failed
+	Type    string    `json:"type"`
+	Cluster string    `json:"cluster"`
+	Hub     string    `json:"hub,omitempty"`
+	Time    time.Time `json:"time"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Notifier sends events to one destination
+type Notifier interface {
+	// Notify sends event
+	Notify(ctx context.Context, event Event) error
+}
+
+// eventIcons prefix the Slack messages of each event type
+var eventIcons = map[string]string{
+	"created":     ":new:",
+	"provisioned": ":white_check_mark:",
+	"ready":       ":large_green_circle:",
+	"failed":      ":x:",
+	"hibernated":  ":zzz:",
+	"resumed":     ":arrow_forward:",
+	"expiring":    ":hourglass_flowing_sand:",
+}
+
+// slackNotifier posts events as messages to a Slack incoming webhook
+type slackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlack creates a Notifier posting to the Slack incoming webhook at url
+func NewSlack(url string, client *http.Client) Notifier {
+	return &slackNotifier{url: url, client: client}
+}
+
+// Notify posts a one line message, e.g. ":x: *my-cluster* provision failed (hub prod)"
+func (s *slackNotifier) Notify(ctx context.Context, event Event) error {
+	text := fmt.Sprintf("%s *%s* %s", eventIcons[event.Type], event.Cluster, valueOrType(event))
+	if event.Hub != "" {
+		text += fmt.Sprintf(" (hub %s)", event.Hub)
+	}
+	return post(ctx, s.client, s.url, nil, map[string]string{"text": strings.TrimSpace(text)})
+}
+
+// webhookNotifier posts events as JSON to an HTTP endpoint
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhook creates a Notifier posting events as JSON to url with headers
+func NewWebhook(url string, headers map[string]string, client *http.Client) Notifier {
+	return &webhookNotifier{url: url, headers: headers, client: client}
+}
+
+// Notify posts the event as a JSON object
+func (w *webhookNotifier) Notify(ctx context.Context, event Event) error {
+	return post(ctx, w.client, w.url, w.headers, event)
+}
+
+// filtered sends only some event types to its notifier
+type filtered struct {
+	Notifier
+	events []string
+}
+
+// Notify sends event if its type is one of the filtered types
+func (f *filtered) Notify(ctx context.Context, event Event) error {
+	if !slices.Contains(f.events, event.Type) {
+		return nil
+	}
+	return f.Notifier.Notify(ctx, event)
+}
+
+// Dispatcher sends events to every configured destination
+type Dispatcher struct {
+	notifiers []namedNotifier
+}
+
+// namedNotifier is a destination with the name its errors are reported with
+type namedNotifier struct {
+	name string
+	Notifier
+}
+
+// New creates a Dispatcher for the destinations of cfg, or nil if none is configured. If
+// client is nil, requests time out after 10 seconds.
+func New(cfg config.NotifyConfig, client *http.Client) *Dispatcher {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+
+	d := &Dispatcher{}
+	if cfg.Slack.WebhookURL != "" {
+		d.add("slack", NewSlack(cfg.Slack.WebhookURL, client), cfg.Slack.Events)
+	}
+	if cfg.Webhook.URL != "" {
+		d.add("webhook", NewWebhook(cfg.Webhook.URL, cfg.Webhook.Headers, client), cfg.Webhook.Events)
+	}
+	return d
+}
+
+// add adds a destination that receives events, or every event if events is empty
+func (d *Dispatcher) add(name string, notifier Notifier, events []string) {
+	if len(events) > 0 {
+		notifier = &filtered{Notifier: notifier, events: events}
+	}
+	d.notifiers = append(d.notifiers, namedNotifier{name: name, Notifier: notifier})
+}
+
+// Notify sends event to every destination, returning the errors of those that failed
+func (d *Dispatcher) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, n := range d.notifiers {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify %s: %w", n.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends body as JSON to url and fails unless the response is 2xx
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// valueOrType returns the message of event, or its type if it has none
+func valueOrType(event Event) string {
+	if event.Message != "" {
+		return event.Message
+	}
+	return event.Type
+}
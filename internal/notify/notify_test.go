@@ -0,0 +1,125 @@
+//go:build test
+
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/notify"
+)
+
+// request is a request received by the test server
+type request struct {
+	path    string
+	headers http.Header
+	body    map[string]interface{}
+}
+
+// receiver records the requests it receives and answers with status
+type receiver struct {
+	mu       sync.Mutex
+	requests []request
+	status   int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	data, _ := io.ReadAll(req.Body)
+	var body map[string]interface{}
+	_ = json.Unmarshal(data, &body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request{path: req.URL.Path, headers: req.Header, body: body})
+	w.WriteHeader(r.status)
+	_, _ = w.Write([]byte("nope"))
+}
+
+var _ = Describe("Dispatcher", func() {
+	var (
+		ctx   context.Context
+		recv  *receiver
+		srv   *httptest.Server
+		event notify.Event
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recv = &receiver{status: http.StatusOK}
+		srv = httptest.NewServer(recv)
+		DeferCleanup(srv.Close)
+		event = notify.Event{
+			Type:    "failed",
+			Cluster: "my-cluster",
+			Hub:     "prod",
+			Time:    time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+			Message: "provision failed",
+		}
+	})
+
+	It("should not create a dispatcher without destinations", func() {
+		Expect(notify.New(config.NotifyConfig{}, nil)).To(BeNil())
+	})
+
+	It("should post a message to Slack", func() {
+		d := notify.New(config.NotifyConfig{Slack: config.SlackNotifyConfig{WebhookURL: srv.URL + "/slack"}}, srv.Client())
+		Expect(d.Notify(ctx, event)).To(Succeed())
+
+		Expect(recv.requests).To(HaveLen(1))
+		Expect(recv.requests[0].path).To(Equal("/slack"))
+		Expect(recv.requests[0].body).To(Equal(map[string]interface{}{"text": ":x: *my-cluster* provision failed (hub prod)"}))
+	})
+
+	It("should post the event as JSON with the configured headers to a webhook", func() {
+		d := notify.New(config.NotifyConfig{Webhook: config.WebhookNotifyConfig{
+			URL:     srv.URL + "/hook",
+			Headers: map[string]string{"Authorization": "Bearer secret"},
+		}}, srv.Client())
+		Expect(d.Notify(ctx, event)).To(Succeed())
+
+		Expect(recv.requests).To(HaveLen(1))
+		Expect(recv.requests[0].path).To(Equal("/hook"))
+		Expect(recv.requests[0].headers.Get("Authorization")).To(Equal("Bearer secret"))
+		Expect(recv.requests[0].headers.Get("Content-Type")).To(Equal("application/json"))
+		Expect(recv.requests[0].body).To(Equal(map[string]interface{}{
+			"type":    "failed",
+			"cluster": "my-cluster",
+			"hub":     "prod",
+			"time":    "2025-03-01T12:00:00Z",
+			"message": "provision failed",
+		}))
+	})
+
+	It("should only send the configured events to each destination", func() {
+		d := notify.New(config.NotifyConfig{
+			Slack:   config.SlackNotifyConfig{WebhookURL: srv.URL + "/slack", Events: []string{"ready"}},
+			Webhook: config.WebhookNotifyConfig{URL: srv.URL + "/hook"},
+		}, srv.Client())
+		Expect(d.Notify(ctx, event)).To(Succeed())
+
+		Expect(recv.requests).To(HaveLen(1))
+		Expect(recv.requests[0].path).To(Equal("/hook"))
+	})
+
+	It("should report the destinations that fail and still notify the others", func() {
+		recv.status = http.StatusForbidden
+		d := notify.New(config.NotifyConfig{
+			Slack:   config.SlackNotifyConfig{WebhookURL: srv.URL + "/slack"},
+			Webhook: config.WebhookNotifyConfig{URL: srv.URL + "/hook"},
+		}, srv.Client())
+
+		err := d.Notify(ctx, event)
+		Expect(err).To(MatchError(ContainSubstring("failed to notify slack: 403 Forbidden: nope")))
+		Expect(err).To(MatchError(ContainSubstring("failed to notify webhook: 403 Forbidden: nope")))
+		Expect(recv.requests).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,154 @@
+// Package plan saves the changes a bulk labrat command would make, so they can be reviewed
+// before labrat apply makes them. A plan names the command, the clusters it was resolved to,
+// and the change of each cluster; applying it runs the command against exactly those
+// clusters, even if a selector would match others by then.
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// APIVersion is the version of the plan format
+	APIVersion = "labrat.io/v1alpha1"
+	// Kind is the kind of plan files
+	Kind = "Plan"
+)
+
+// Plan is a saved bulk operation
+type Plan struct {
+	APIVersion string    `yaml:"apiVersion"`
+	Kind       string    `yaml:"kind"`
+	CreatedAt  time.Time `yaml:"createdAt"`
+	// CreatedBy is user@host of whoever made the plan
+	CreatedBy string `yaml:"createdBy"`
+	// Hub is the name of the hub the plan was made against and must be applied to
+	Hub string `yaml:"hub"`
+	// Command is the command that applies the plan, e.g. "spoke hibernate"
+	Command string `yaml:"command"`
+	// Args are the arguments of the command after the clusters, e.g. the changes of spoke label
+	Args []string `yaml:"args,omitempty"`
+	// Flags are the flags of the command, e.g. overwrite: "true"
+	Flags map[string]string `yaml:"flags,omitempty"`
+	// Changes are the changes of each cluster, in the order they were planned
+	Changes []Change `yaml:"changes"`
+}
+
+// Change is what applying a plan changes on one cluster
+type Change struct {
+	Cluster string `yaml:"cluster"`
+	// Description describes the change, e.g. "powerState: Running → Hibernating"
+	Description string `yaml:"change"`
+}
+
+// New creates a plan of command made by user against hub now
+func New(hub, command, user string) *Plan {
+	return &Plan{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		CreatedBy:  user,
+		Hub:        hub,
+		Command:    command,
+	}
+}
+
+// Add adds the change of a cluster
+func (p *Plan) Add(cluster, description string) {
+	p.Changes = append(p.Changes, Change{Cluster: cluster, Description: description})
+}
+
+// Clusters returns the clusters the plan changes
+func (p *Plan) Clusters() []string {
+	clusters := make([]string, 0, len(p.Changes))
+	for _, change := range p.Changes {
+		clusters = append(clusters, change.Cluster)
+	}
+	return clusters
+}
+
+// Validate checks that the plan is a plan of a supported version with a command and clusters
+func (p *Plan) Validate() error {
+	if p.APIVersion != APIVersion || p.Kind != Kind {
+		return fmt.Errorf("not a labrat plan: expected apiVersion %s and kind %s, got %q and %q", APIVersion, Kind, p.APIVersion, p.Kind)
+	}
+	if p.Command == "" {
+		return errors.New("plan has no command")
+	}
+	if len(p.Changes) == 0 {
+		return errors.New("plan changes no clusters")
+	}
+	for i, change := range p.Changes {
+		if change.Cluster == "" {
+			return fmt.Errorf("change %d of the plan has no cluster", i+1)
+		}
+	}
+	return nil
+}
+
+// Write writes the plan as YAML to out
+func (p *Plan) Write(out io.Writer) error {
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(p); err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	return encoder.Close()
+}
+
+// Save writes the plan to the file at path, or to stdout if path is -
+func (p *Plan) Save(path string) error {
+	if path == "-" {
+		return p.Write(os.Stdout)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create plan file: %w", err)
+	}
+	if err := p.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+	return nil
+}
+
+// Load reads and validates the plan file at path
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+	var p Plan
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// WriteSummary writes what applying the plan does, one cluster per row, to out
+func (p *Plan) WriteSummary(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Plan of labrat %s on hub %s, made by %s at %s\n\n", p.Command, p.Hub, p.CreatedBy, p.CreatedAt.Local().Format(time.RFC1123))
+	fmt.Fprintln(w, "CLUSTER\tCHANGE")
+	for _, change := range p.Changes {
+		fmt.Fprintf(w, "%s\t%s\n", change.Cluster, change.Description)
+	}
+	fmt.Fprintf(w, "\n%d clusters to change\n", len(p.Changes))
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
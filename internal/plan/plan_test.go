@@ -0,0 +1,75 @@
+//go:build test
+
+package plan_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/plan"
+)
+
+var _ = Describe("Plan", func() {
+	var p *plan.Plan
+
+	BeforeEach(func() {
+		p = plan.New("lab-east", "spoke label", "jdoe@laptop")
+		p.Args = []string{"owner=asmith"}
+		p.Flags = map[string]string{"overwrite": "true"}
+		p.Add("partner-a", "owner: jdoe → asmith")
+		p.Add("partner-b", "owner=asmith")
+	})
+
+	It("should list the clusters in planned order", func() {
+		Expect(p.Clusters()).To(Equal([]string{"partner-a", "partner-b"}))
+		Expect(p.Validate()).To(Succeed())
+	})
+
+	It("should round trip through a plan file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "plan.yaml")
+		Expect(p.Save(path)).To(Succeed())
+
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		loaded, err := plan.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.CreatedAt.Equal(p.CreatedAt)).To(BeTrue())
+		loaded.CreatedAt = p.CreatedAt
+		Expect(loaded).To(Equal(p))
+	})
+
+	It("should reject files that are not valid plans", func() {
+		dir := GinkgoT().TempDir()
+		write := func(content string) string {
+			path := filepath.Join(dir, "plan.yaml")
+			Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+			return path
+		}
+
+		_, err := plan.Load(write("apiVersion: v1\nkind: ConfigMap\n"))
+		Expect(err).To(MatchError(ContainSubstring("not a labrat plan")))
+
+		_, err = plan.Load(write("apiVersion: labrat.io/v1alpha1\nkind: Plan\ncommand: spoke resume\n"))
+		Expect(err).To(MatchError("plan changes no clusters"))
+
+		_, err = plan.Load(write("apiVersion: labrat.io/v1alpha1\nkind: Plan\nchanges:\n  - cluster: partner-a\n"))
+		Expect(err).To(MatchError("plan has no command"))
+
+		_, err = plan.Load(filepath.Join(dir, "missing.yaml"))
+		Expect(err).To(MatchError(ContainSubstring("failed to read plan file")))
+	})
+
+	It("should summarize the change of every cluster", func() {
+		var out bytes.Buffer
+		Expect(p.WriteSummary(&out)).To(Succeed())
+
+		Expect(out.String()).To(ContainSubstring("Plan of labrat spoke label on hub lab-east, made by jdoe@laptop"))
+		Expect(out.String()).To(MatchRegexp(`partner-a\s+owner: jdoe → asmith`))
+		Expect(out.String()).To(ContainSubstring("2 clusters to change"))
+	})
+})
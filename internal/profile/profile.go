@@ -0,0 +1,217 @@
+// Package profile records where the time of a command run goes: named phases and the
+// Kubernetes API requests made by every client. It backs the --profile-cpu/--profile-mem
+// flags and the timing breakdown printed at -v=5.
+package profile
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Stats aggregates the durations of one phase or one kind of API request
+type Stats struct {
+	// Key is the phase name, or the request method and resource, e.g. "GET managedclusters"
+	Key   string
+	Count int
+	Total time.Duration
+	Max   time.Duration
+}
+
+// add records one duration
+func (s *Stats) add(d time.Duration) {
+	s.Count++
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+}
+
+// Timings collects phase and API request durations; it is safe for concurrent use
+type Timings struct {
+	mu       sync.Mutex
+	start    time.Time
+	phases   []*Stats
+	requests map[string]*Stats
+}
+
+// NewTimings creates a new Timings starting now
+func NewTimings() *Timings {
+	return &Timings{
+		start:    time.Now(),
+		requests: make(map[string]*Stats),
+	}
+}
+
+// Start starts timing a phase and returns a function that ends it. Phases with the same
+// name, such as connecting to each spoke of a fleet, are aggregated.
+func (t *Timings) Start(name string) func() {
+	start := time.Now()
+	return func() {
+		d := time.Since(start)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for _, p := range t.phases {
+			if p.Key == name {
+				p.add(d)
+				return
+			}
+		}
+		p := &Stats{Key: name}
+		p.add(d)
+		t.phases = append(t.phases, p)
+	}
+}
+
+// Phases returns the phase statistics in the order the phases first ended
+func (t *Timings) Phases() []Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	phases := make([]Stats, 0, len(t.phases))
+	for _, p := range t.phases {
+		phases = append(phases, *p)
+	}
+	return phases
+}
+
+// Requests returns the request statistics, slowest total first
+func (t *Timings) Requests() []Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make([]Stats, 0, len(t.requests))
+	for _, s := range t.requests {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].Key < stats[j].Key
+	})
+	return stats
+}
+
+// observe records one API request
+func (t *Timings) observe(key string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.requests[key]
+	if !ok {
+		s = &Stats{Key: key}
+		t.requests[key] = s
+	}
+	s.add(d)
+}
+
+// WrapTransport returns a round tripper that records the duration of every request sent through rt
+func (t *Timings) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &timingTransport{timings: t, next: rt}
+}
+
+type timingTransport struct {
+	timings *Timings
+	next    http.RoundTripper
+}
+
+// RoundTrip sends the request and records its duration under its method and resource
+func (tt *timingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := tt.next.RoundTrip(req)
+	tt.timings.observe(req.Method+" "+Resource(req.URL.Path), time.Since(start))
+	return resp, err
+}
+
+// Resource extracts the resource type from a Kubernetes API path, so requests for different
+// objects of the same type are aggregated:
+//
+//	/api/v1/namespaces/ns/secrets/name                          -> secrets
+//	/apis/cluster.open-cluster-management.io/v1/managedclusters -> managedclusters
+//
+// Paths that are not resource paths (e.g. /version) are returned unchanged.
+func Resource(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return path
+	}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	if len(parts) == 0 {
+		return path
+	}
+	resource := parts[0]
+	if len(parts) >= 3 {
+		// Subresource, e.g. managedclusters/status
+		resource += "/" + parts[2]
+	}
+	return resource
+}
+
+// Write prints the total run time, the phases, and the API requests
+func (t *Timings) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(tw, "TIMING\tCOUNT\tTOTAL\tMAX\n")
+	fmt.Fprintf(tw, "total\t1\t%s\t\n", round(time.Since(t.start)))
+	for _, p := range t.Phases() {
+		fmt.Fprintf(tw, "phase: %s\t%d\t%s\t%s\n", p.Key, p.Count, round(p.Total), round(p.Max))
+	}
+	for _, r := range t.Requests() {
+		fmt.Fprintf(tw, "api: %s\t%d\t%s\t%s\n", r.Key, r.Count, round(r.Total), round(r.Max))
+	}
+	return tw.Flush()
+}
+
+// round shortens durations to milliseconds for display
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
+
+// StartCPU starts writing a CPU profile to path and returns a function that stops it
+func StartCPU(path string) (func() error, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+	return func() error {
+		pprof.StopCPUProfile()
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write CPU profile: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// WriteHeap writes a heap profile to path after a garbage collection, so it reflects live memory
+func WriteHeap(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create memory profile: %w", err)
+	}
+	defer f.Close()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("failed to write memory profile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+//go:build test
+
+package profile_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/profile"
+)
+
+var _ = Describe("Profile", func() {
+	Describe("Resource", func() {
+		It("should extract namespaced core resources", func() {
+			Expect(profile.Resource("/api/v1/namespaces/spoke-1/secrets/admin-kubeconfig")).To(Equal("secrets"))
+		})
+
+		It("should extract cluster-scoped group resources", func() {
+			Expect(profile.Resource("/apis/cluster.open-cluster-management.io/v1/managedclusters")).To(Equal("managedclusters"))
+			Expect(profile.Resource("/apis/cluster.open-cluster-management.io/v1/managedclusters/spoke-1")).To(Equal("managedclusters"))
+		})
+
+		It("should include subresources", func() {
+			Expect(profile.Resource("/apis/hive.openshift.io/v1/namespaces/spoke-1/clusterdeployments/spoke-1/status")).To(Equal("clusterdeployments/status"))
+		})
+
+		It("should return the namespaces resource for namespace requests", func() {
+			Expect(profile.Resource("/api/v1/namespaces/spoke-1")).To(Equal("namespaces"))
+		})
+
+		It("should return other paths unchanged", func() {
+			Expect(profile.Resource("/version")).To(Equal("/version"))
+		})
+	})
+
+	Describe("Timings", func() {
+		It("should aggregate phases with the same name", func() {
+			timings := profile.NewTimings()
+			timings.Start("connect to spoke")()
+			timings.Start("connect to spoke")()
+			timings.Start("load config")()
+
+			phases := timings.Phases()
+			Expect(phases).To(HaveLen(2))
+			Expect(phases[0].Key).To(Equal("connect to spoke"))
+			Expect(phases[0].Count).To(Equal(2))
+			Expect(phases[1].Key).To(Equal("load config"))
+			Expect(phases[1].Count).To(Equal(1))
+		})
+
+		It("should record requests sent through a wrapped transport", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			timings := profile.NewTimings()
+			client := &http.Client{Transport: timings.WrapTransport(http.DefaultTransport)}
+			for _, name := range []string{"spoke-1", "spoke-2"} {
+				resp, err := client.Get(server.URL + "/apis/cluster.open-cluster-management.io/v1/managedclusters/" + name)
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+			}
+
+			requests := timings.Requests()
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Key).To(Equal("GET managedclusters"))
+			Expect(requests[0].Count).To(Equal(2))
+
+			var out bytes.Buffer
+			Expect(timings.Write(&out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("TIMING"))
+			Expect(out.String()).To(ContainSubstring("api: GET managedclusters"))
+		})
+	})
+})
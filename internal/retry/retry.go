@@ -0,0 +1,104 @@
+// Package retry retries API calls that fail transiently, e.g. while the API server restarts or
+// sheds load, with exponential backoff and jitter. It is applied by the hub and spoke clients
+// through kube.WithRetry.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// DefaultRetries is the number of retries after the first attempt of the labrat commands
+	DefaultRetries = 3
+	// DefaultBackoff is the delay before the first retry, doubled for each further retry
+	DefaultBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff caps the delay between retries
+	DefaultMaxBackoff = 10 * time.Second
+	// DefaultJitter is the fraction of each delay that is randomized
+	DefaultJitter = 0.2
+)
+
+// Policy configures how often and how long apart a failed call is retried. The zero value
+// makes a single attempt.
+type Policy struct {
+	// Retries is the number of retries after the first attempt
+	Retries int
+	// Backoff is the delay before the first retry, doubled for each further retry. Defaults to
+	// DefaultBackoff.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries. Defaults to DefaultMaxBackoff.
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to this fraction, so clients that failed together do
+	// not retry together. Defaults to DefaultJitter; negative values disable jitter.
+	Jitter float64
+	// OnRetry, if set, is called before waiting for a retry
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// Do calls fn until it succeeds, fails with an error that is not transient, or the retries are
+// used up, and returns the last error. Waiting for a retry ends early when ctx is done.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	jitter := p.Jitter
+	if jitter == 0 {
+		jitter = DefaultJitter
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt > p.Retries || !IsTransient(err) {
+			return err
+		}
+
+		delay := min(backoff, maxBackoff)
+		if jitter > 0 {
+			delay += time.Duration(jitter * float64(delay) * (2*rand.Float64() - 1))
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// IsTransient reports whether err is worth retrying: the API server timed out, throttled the
+// request, or was unavailable, or the connection to it broke. Errors of ctx are never
+// transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) || apierrors.IsUnexpectedServerError(err) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
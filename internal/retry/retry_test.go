@@ -0,0 +1,131 @@
+//go:build test
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/retry"
+)
+
+var _ = Describe("Policy", func() {
+	var (
+		ctx         context.Context
+		unavailable error
+		policy      retry.Policy
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		unavailable = apierrors.NewServiceUnavailable("etcd leader changed")
+		policy = retry.Policy{Retries: 3, Backoff: time.Millisecond, Jitter: -1}
+	})
+
+	It("should retry transient errors until the call succeeds", func() {
+		var delays []time.Duration
+		policy.OnRetry = func(_ int, delay time.Duration, _ error) {
+			delays = append(delays, delay)
+		}
+
+		calls := 0
+		err := policy.Do(ctx, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return unavailable
+			}
+			return nil
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(3))
+		Expect(delays).To(Equal([]time.Duration{time.Millisecond, 2 * time.Millisecond}))
+	})
+
+	It("should give up after the retries with the last error", func() {
+		calls := 0
+		err := policy.Do(ctx, func(context.Context) error {
+			calls++
+			return fmt.Errorf("failed to list: %w", unavailable)
+		})
+
+		Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+		Expect(calls).To(Equal(4))
+	})
+
+	It("should not retry errors that are not transient", func() {
+		calls := 0
+		notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "managedclusters"}, "spoke")
+		err := policy.Do(ctx, func(context.Context) error {
+			calls++
+			return notFound
+		})
+
+		Expect(err).To(MatchError(notFound))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should make a single attempt with the zero value", func() {
+		calls := 0
+		err := retry.Policy{}.Do(ctx, func(context.Context) error {
+			calls++
+			return unavailable
+		})
+
+		Expect(err).To(MatchError(unavailable))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should cap the delay and stop waiting when the context is done", func() {
+		ctx, cancel := context.WithCancel(ctx)
+		policy = retry.Policy{Retries: 5, Backoff: time.Hour, MaxBackoff: time.Minute, Jitter: -1}
+		policy.OnRetry = func(_ int, delay time.Duration, _ error) {
+			Expect(delay).To(Equal(time.Minute))
+			cancel()
+		}
+
+		calls := 0
+		err := policy.Do(ctx, func(context.Context) error {
+			calls++
+			return unavailable
+		})
+
+		Expect(err).To(MatchError(unavailable))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should randomize delays within the jitter", func() {
+		policy = retry.Policy{Retries: 20, Backoff: time.Millisecond, MaxBackoff: time.Millisecond, Jitter: 0.5}
+		policy.OnRetry = func(_ int, delay time.Duration, _ error) {
+			Expect(delay).To(BeNumerically("~", time.Millisecond, 500*time.Microsecond))
+		}
+
+		Expect(policy.Do(ctx, func(context.Context) error { return unavailable })).To(MatchError(unavailable))
+	})
+})
+
+var _ = DescribeTable("IsTransient",
+	func(err error, transient bool) {
+		Expect(retry.IsTransient(err)).To(Equal(transient))
+	},
+	Entry("nil", nil, false),
+	Entry("service unavailable", apierrors.NewServiceUnavailable("down"), true),
+	Entry("too many requests", apierrors.NewTooManyRequests("slow down", 1), true),
+	Entry("server timeout", apierrors.NewServerTimeout(schema.GroupResource{Resource: "secrets"}, "get", 1), true),
+	Entry("internal error", apierrors.NewInternalError(errors.New("boom")), true),
+	Entry("wrapped connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true),
+	Entry("unexpected EOF", io.ErrUnexpectedEOF, true),
+	Entry("not found", apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "x"), false),
+	Entry("forbidden", apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "x", errors.New("rbac")), false),
+	Entry("context canceled", fmt.Errorf("list: %w", context.Canceled), false),
+	Entry("deadline exceeded", context.DeadlineExceeded, false),
+)
@@ -0,0 +1,266 @@
+// Package schedule evaluates the power schedules of clusters: cron expressions that hibernate a
+// cluster, e.g. every weekday evening, and resume it, e.g. every weekday morning, in the time
+// zone of the people using it. labrat schedule run enforces them from a CronJob.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// Time zones load from the binary in images without a zoneinfo database
+	_ "time/tzdata"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+// Cron is a standard five field cron expression: minute, hour, day of month, month, and day of
+// week. Fields accept *, values, ranges, lists, and steps, e.g. 0 20 * * 1-5 or */15 8-18 * * *.
+// As in cron, a day matches if either a restricted day of month or a restricted day of week
+// matches.
+type Cron struct {
+	expr                                   string
+	minutes, hours, days, months, weekdays uint64
+	anyDay, anyWeekday                     bool
+}
+
+// cronField is the name and range of values of a field of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+}
+
+// cronFields are the fields of a cron expression in order; 7 is Sunday like 0
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// ParseCron parses a five field cron expression
+func ParseCron(expr string) (*Cron, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression %q: want 5 fields (minute hour day-of-month month day-of-week)", expr)
+	}
+
+	var masks [5]uint64
+	for i, part := range parts {
+		mask, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		masks[i] = mask
+	}
+	if masks[4]&(1<<7) != 0 {
+		masks[4] = masks[4]&^(1<<7) | 1
+	}
+
+	return &Cron{
+		expr:       strings.Join(parts, " "),
+		minutes:    masks[0],
+		hours:      masks[1],
+		days:       masks[2],
+		months:     masks[3],
+		weekdays:   masks[4],
+		anyDay:     strings.HasPrefix(parts[2], "*"),
+		anyWeekday: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseCronField returns the values of a field as a bit mask
+func parseCronField(value string, field cronField) (uint64, error) {
+	var mask uint64
+	for _, item := range strings.Split(value, ",") {
+		valueRange, stepValue, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepValue)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q of %s", stepValue, field.name)
+			}
+			step = n
+		}
+
+		low, high := field.min, field.max
+		if valueRange != "*" {
+			from, to, isRange := strings.Cut(valueRange, "-")
+			var err error
+			if low, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid %s %q", field.name, item)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid %s %q", field.name, item)
+				}
+			} else if hasStep {
+				high = field.max
+			}
+		}
+		if low < field.min || high > field.max || low > high {
+			return 0, fmt.Errorf("%s %q is out of range %d-%d", field.name, item, field.min, field.max)
+		}
+
+		for v := low; v <= high; v += step {
+			mask |= 1 << v
+		}
+	}
+	return mask, nil
+}
+
+// String returns the expression
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// matchesDay reports whether the date of t matches the day, month, and weekday fields
+func (c *Cron) matchesDay(t time.Time) bool {
+	if c.months&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	day := c.days&(1<<t.Day()) != 0
+	weekday := c.weekdays&(1<<int(t.Weekday())) != 0
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// Prev returns the latest minute at or before t that the expression matches in loc, false if
+// it did not match within the year before t
+func (c *Cron) Prev(t time.Time, loc *time.Location) (time.Time, bool) {
+	t = t.In(loc)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+	limit := t.AddDate(-1, 0, -1)
+	for !t.Before(limit) {
+		switch {
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(-time.Minute)
+		case c.hours&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc).Add(-time.Minute)
+		case c.minutes&(1<<t.Minute()) == 0:
+			t = t.Add(-time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Next returns the first minute after t that the expression matches in loc, false if it does
+// not match within the year after t
+func (c *Cron) Next(t time.Time, loc *time.Location) (time.Time, bool) {
+	t = t.In(loc)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.AddDate(1, 0, 1)
+	for t.Before(limit) {
+		switch {
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hours&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minutes&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Policy is the power schedule of a cluster. Either schedule may be nil, e.g. to hibernate a
+// cluster every night and leave resuming it to its users.
+type Policy struct {
+	// Hibernate is when the cluster is hibernated
+	Hibernate *Cron
+	// Resume is when the cluster is resumed
+	Resume *Cron
+	// Location is the time zone the schedules are evaluated in
+	Location *time.Location
+}
+
+// NewPolicy parses the hibernate and resume schedules of a cluster, evaluated in the IANA time
+// zone timezone, e.g. Europe/Berlin, or UTC if empty
+func NewPolicy(hibernate, resume, timezone string) (*Policy, error) {
+	if hibernate == "" && resume == "" {
+		return nil, fmt.Errorf("a hibernate or resume schedule is required")
+	}
+
+	policy := &Policy{Location: time.UTC}
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+		}
+		policy.Location = loc
+	}
+	if hibernate != "" {
+		cron, err := ParseCron(hibernate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hibernate schedule: %w", err)
+		}
+		policy.Hibernate = cron
+	}
+	if resume != "" {
+		cron, err := ParseCron(resume)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resume schedule: %w", err)
+		}
+		policy.Resume = cron
+	}
+	return policy, nil
+}
+
+// Last returns the power state of the schedule that fired last at or before now and when it
+// fired, false if neither fired within the year before now. A resume wins a tie.
+func (p *Policy) Last(now time.Time) (string, time.Time, bool) {
+	return p.pick(now, (*Cron).Prev, func(a, b time.Time) bool { return a.After(b) })
+}
+
+// Next returns the power state of the schedule that fires next after now and when it fires,
+// false if neither fires within the year after now. A resume wins a tie.
+func (p *Policy) Next(now time.Time) (string, time.Time, bool) {
+	return p.pick(now, (*Cron).Next, func(a, b time.Time) bool { return a.Before(b) })
+}
+
+// Due returns the power state of the schedule that fired last at or before now and when it
+// fired, if it fired after applied, the time the schedule was last enforced. Without applied
+// the last schedule is due, so a new schedule applies right away.
+func (p *Policy) Due(now time.Time, applied *time.Time) (string, time.Time, bool) {
+	state, at, ok := p.Last(now)
+	if !ok || (applied != nil && !at.After(*applied)) {
+		return "", time.Time{}, false
+	}
+	return state, at, true
+}
+
+// pick evaluates both schedules with find and returns the one whose time is better
+func (p *Policy) pick(now time.Time, find func(*Cron, time.Time, *time.Location) (time.Time, bool), better func(a, b time.Time) bool) (string, time.Time, bool) {
+	var state string
+	var at time.Time
+	found := false
+	for _, s := range []struct {
+		cron  *Cron
+		state string
+	}{
+		{p.Resume, spoke.PowerStateRunning},
+		{p.Hibernate, spoke.PowerStateHibernating},
+	} {
+		if s.cron == nil {
+			continue
+		}
+		if t, ok := find(s.cron, now, p.Location); ok && (!found || better(t, at)) {
+			state, at, found = s.state, t, true
+		}
+	}
+	return state, at, found
+}
@@ -0,0 +1,143 @@
+//go:build test
+
+package schedule_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/schedule"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+var _ = Describe("Cron", func() {
+	// Wednesday 2026-10-14 12:34 UTC
+	now := time.Date(2026, 10, 14, 12, 34, 56, 0, time.UTC)
+
+	mustParse := func(expr string) *schedule.Cron {
+		cron, err := schedule.ParseCron(expr)
+		Expect(err).NotTo(HaveOccurred())
+		return cron
+	}
+
+	It("should find the previous and next weekday evening", func() {
+		cron := mustParse("0 20 * * 1-5")
+		prev, ok := cron.Prev(now, time.UTC)
+		Expect(ok).To(BeTrue())
+		Expect(prev).To(Equal(time.Date(2026, 10, 13, 20, 0, 0, 0, time.UTC)))
+
+		next, ok := cron.Next(now, time.UTC)
+		Expect(ok).To(BeTrue())
+		Expect(next).To(Equal(time.Date(2026, 10, 14, 20, 0, 0, 0, time.UTC)))
+	})
+
+	It("should skip weekends", func() {
+		saturday := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+		prev, _ := mustParse("0 8 * * 1-5").Prev(saturday, time.UTC)
+		Expect(prev).To(Equal(time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)))
+		next, _ := mustParse("0 8 * * 1-5").Next(saturday, time.UTC)
+		Expect(next).To(Equal(time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)))
+	})
+
+	It("should match the current minute as the previous time but not the next", func() {
+		cron := mustParse("34 12 * * *")
+		prev, _ := cron.Prev(now, time.UTC)
+		Expect(prev).To(Equal(time.Date(2026, 10, 14, 12, 34, 0, 0, time.UTC)))
+		next, _ := cron.Next(now, time.UTC)
+		Expect(next).To(Equal(time.Date(2026, 10, 15, 12, 34, 0, 0, time.UTC)))
+	})
+
+	It("should support steps, lists, and Sunday as 7", func() {
+		next, _ := mustParse("*/15 9,17 * * *").Next(now, time.UTC)
+		Expect(next).To(Equal(time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC)))
+
+		next, _ = mustParse("30 6 * * 7").Next(now, time.UTC)
+		Expect(next).To(Equal(time.Date(2026, 10, 18, 6, 30, 0, 0, time.UTC)))
+	})
+
+	It("should match either a restricted day of month or day of week", func() {
+		next, _ := mustParse("0 0 20 * 1").Next(now, time.UTC)
+		Expect(next).To(Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)))
+		next, _ = mustParse("0 0 20 * *").Next(now, time.UTC)
+		Expect(next).To(Equal(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("should evaluate the expression in a time zone", func() {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+
+		next, _ := mustParse("0 20 * * 1-5").Next(now, berlin)
+		Expect(next.UTC()).To(Equal(time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC)))
+		// Daylight saving time ends on 2026-10-25
+		next, _ = mustParse("0 20 * * 1").Next(time.Date(2026, 10, 24, 0, 0, 0, 0, time.UTC), berlin)
+		Expect(next.UTC()).To(Equal(time.Date(2026, 10, 26, 19, 0, 0, 0, time.UTC)))
+	})
+
+	It("should report expressions that never match", func() {
+		_, ok := mustParse("0 0 31 2 *").Next(now, time.UTC)
+		Expect(ok).To(BeFalse())
+		_, ok = mustParse("0 0 31 2 *").Prev(now, time.UTC)
+		Expect(ok).To(BeFalse())
+	})
+
+	DescribeTable("should reject invalid expressions",
+		func(expr, message string) {
+			_, err := schedule.ParseCron(expr)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("too few fields", "0 20 * *", "want 5 fields"),
+		Entry("out of range", "0 24 * * *", `hour "24" is out of range 0-23`),
+		Entry("reversed range", "0 0 * * 5-1", `day of week "5-1" is out of range`),
+		Entry("not a number", "0 0 * * mon", `invalid day of week "mon"`),
+		Entry("zero step", "*/0 * * * *", `invalid step "0" of minute`),
+	)
+})
+
+var _ = Describe("Policy", func() {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	It("should require a schedule and a valid time zone", func() {
+		_, err := schedule.NewPolicy("", "", "")
+		Expect(err).To(MatchError("a hibernate or resume schedule is required"))
+
+		_, err = schedule.NewPolicy("0 20 * * *", "", "Mars/Olympus")
+		Expect(err).To(MatchError(ContainSubstring(`invalid time zone "Mars/Olympus"`)))
+
+		_, err = schedule.NewPolicy("0 20 * *", "", "")
+		Expect(err).To(MatchError(ContainSubstring("invalid hibernate schedule")))
+	})
+
+	It("should report the last and next schedule to fire", func() {
+		policy, err := schedule.NewPolicy("0 20 * * 1-5", "0 8 * * 1-5", "Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+
+		state, at, ok := policy.Last(now)
+		Expect(ok).To(BeTrue())
+		Expect(state).To(Equal(spoke.PowerStateRunning))
+		Expect(at.UTC()).To(Equal(time.Date(2026, 10, 14, 6, 0, 0, 0, time.UTC)))
+
+		state, at, ok = policy.Next(now)
+		Expect(ok).To(BeTrue())
+		Expect(state).To(Equal(spoke.PowerStateHibernating))
+		Expect(at.UTC()).To(Equal(time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC)))
+	})
+
+	It("should only be due once per firing", func() {
+		policy, err := schedule.NewPolicy("0 20 * * *", "", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		state, at, due := policy.Due(now, nil)
+		Expect(due).To(BeTrue())
+		Expect(state).To(Equal(spoke.PowerStateHibernating))
+		Expect(at).To(Equal(time.Date(2026, 10, 13, 20, 0, 0, 0, time.UTC)))
+
+		_, _, due = policy.Due(now, &at)
+		Expect(due).To(BeFalse())
+
+		_, at, due = policy.Due(now.Add(9*time.Hour), &at)
+		Expect(due).To(BeTrue())
+		Expect(at).To(Equal(time.Date(2026, 10, 14, 20, 0, 0, 0, time.UTC)))
+	})
+})
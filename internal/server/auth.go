@@ -0,0 +1,299 @@
+// Package server implements the labrat HTTP API server: authentication of API clients, the
+// role model that decides which hub operations they may perform, the API handlers, and the
+// stream of cluster lifecycle events.
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+)
+
+// Role is the level of access granted to an API client
+type Role string
+
+const (
+	// RoleViewer may list and inspect clusters
+	RoleViewer Role = "viewer"
+	// RoleOperator may additionally extract kubeconfigs and hibernate or resume clusters
+	RoleOperator Role = "operator"
+	// RoleAdmin may additionally create and delete clusters
+	RoleAdmin Role = "admin"
+)
+
+// roleLevels orders roles from least to most privileged
+var roleLevels = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ParseRole parses a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := roleLevels[role]; !ok {
+		return "", fmt.Errorf("unknown role %q (valid roles: viewer, operator, admin)", name)
+	}
+	return role, nil
+}
+
+// Operation is a class of hub operation exposed by the API
+type Operation string
+
+const (
+	// OperationRead lists clusters and reads cluster details
+	OperationRead Operation = "read"
+	// OperationKubeconfig extracts cluster credentials
+	OperationKubeconfig Operation = "kubeconfig"
+	// OperationPower hibernates and resumes clusters
+	OperationPower Operation = "power"
+	// OperationProvision creates and deletes clusters
+	OperationProvision Operation = "provision"
+)
+
+// operationRoles is the least privileged role allowed to perform each operation
+var operationRoles = map[Operation]Role{
+	OperationRead:       RoleViewer,
+	OperationKubeconfig: RoleOperator,
+	OperationPower:      RoleOperator,
+	OperationProvision:  RoleAdmin,
+}
+
+// Allows reports whether the role may perform op
+func (r Role) Allows(op Operation) bool {
+	required, ok := operationRoles[op]
+	if !ok {
+		return false
+	}
+	return roleLevels[r] >= roleLevels[required]
+}
+
+// Principal is an authenticated API client
+type Principal struct {
+	// Name identifies the client in logs
+	Name string
+	// Role is the access granted to the client
+	Role Role
+}
+
+// ErrUnauthenticated is returned when a request carries no valid credentials
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator identifies the client making an API request
+type Authenticator interface {
+	// Authenticate returns the principal for a bearer token, or an error wrapping
+	// ErrUnauthenticated if the token is not accepted
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+// NewAuthenticator creates an Authenticator from the serve auth configuration, accepting static
+// tokens and, if configured, OIDC ID tokens. At least one method must be configured.
+func NewAuthenticator(ctx context.Context, cfg config.AuthConfig) (Authenticator, error) {
+	var chain authenticatorChain
+
+	if len(cfg.Tokens) > 0 {
+		tokens, err := NewTokenAuthenticator(cfg.Tokens)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, tokens)
+	}
+
+	if cfg.OIDC != nil {
+		oidcAuth, err := NewOIDCAuthenticator(ctx, *cfg.OIDC)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, oidcAuth)
+	}
+
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no authentication configured: set serve.auth.tokens or serve.auth.oidc")
+	}
+	return chain, nil
+}
+
+// authenticatorChain tries each authenticator in turn
+type authenticatorChain []Authenticator
+
+// Authenticate returns the principal from the first authenticator that accepts the token
+func (c authenticatorChain) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	var errs []error
+	for _, a := range c {
+		principal, err := a.Authenticate(ctx, token)
+		if err == nil {
+			return principal, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// tokenAuthenticator accepts static tokens by their SHA-256 hash
+type tokenAuthenticator struct {
+	tokens []hashedToken
+}
+
+type hashedToken struct {
+	hash      []byte
+	principal Principal
+}
+
+// NewTokenAuthenticator creates an Authenticator for static tokens
+func NewTokenAuthenticator(tokens []config.TokenConfig) (Authenticator, error) {
+	a := &tokenAuthenticator{}
+	for i, t := range tokens {
+		if t.Name == "" {
+			return nil, fmt.Errorf("serve.auth.tokens[%d]: name is required", i)
+		}
+		role, err := ParseRole(t.Role)
+		if err != nil {
+			return nil, fmt.Errorf("serve.auth.tokens[%d] (%s): %w", i, t.Name, err)
+		}
+		hash, err := hex.DecodeString(t.SHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("serve.auth.tokens[%d] (%s): sha256 must be a hex-encoded SHA-256 hash", i, t.Name)
+		}
+		a.tokens = append(a.tokens, hashedToken{hash: hash, principal: Principal{Name: t.Name, Role: role}})
+	}
+	return a, nil
+}
+
+// Authenticate compares the token hash against every configured token in constant time
+func (a *tokenAuthenticator) Authenticate(_ context.Context, token string) (*Principal, error) {
+	sum := sha256.Sum256([]byte(token))
+	var match *Principal
+	for i := range a.tokens {
+		if subtle.ConstantTimeCompare(sum[:], a.tokens[i].hash) == 1 {
+			match = &a.tokens[i].principal
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: unknown token", ErrUnauthenticated)
+	}
+	principal := *match
+	return &principal, nil
+}
+
+// oidcAuthenticator accepts ID tokens issued by an OIDC provider and maps groups to roles
+type oidcAuthenticator struct {
+	verifier      *oidc.IDTokenVerifier
+	usernameClaim string
+	groupsClaim   string
+	groupRoles    map[string]Role
+}
+
+// NewOIDCAuthenticator creates an Authenticator for OIDC ID tokens. The provider's discovery
+// document is fetched from the issuer URL.
+func NewOIDCAuthenticator(ctx context.Context, cfg config.OIDCConfig) (Authenticator, error) {
+	if cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return nil, fmt.Errorf("serve.auth.oidc: issuerURL and clientID are required")
+	}
+
+	a := &oidcAuthenticator{
+		usernameClaim: cfg.UsernameClaim,
+		groupsClaim:   cfg.GroupsClaim,
+		groupRoles:    make(map[string]Role),
+	}
+	if a.usernameClaim == "" {
+		a.usernameClaim = "email"
+	}
+	if a.groupsClaim == "" {
+		a.groupsClaim = "groups"
+	}
+	for name, groups := range cfg.Roles {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("serve.auth.oidc.roles: %w", err)
+		}
+		for _, group := range groups {
+			// A group listed under several roles gets the most privileged one
+			if roleLevels[role] > roleLevels[a.groupRoles[group]] {
+				a.groupRoles[group] = role
+			}
+		}
+	}
+
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider %s: %w", cfg.IssuerURL, err)
+	}
+	a.verifier = provider.Verifier(&oidc.Config{ClientID: cfg.ClientID})
+	return a, nil
+}
+
+// Authenticate verifies the ID token and grants the most privileged role of the user's groups
+func (a *oidcAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	idToken, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode claims: %v", ErrUnauthenticated, err)
+	}
+
+	name, _ := claims[a.usernameClaim].(string)
+	if name == "" {
+		name = idToken.Subject
+	}
+
+	var role Role
+	groups, _ := claims[a.groupsClaim].([]interface{})
+	for _, g := range groups {
+		group, _ := g.(string)
+		if granted, ok := a.groupRoles[group]; ok && roleLevels[granted] > roleLevels[role] {
+			role = granted
+		}
+	}
+	if role == "" {
+		return nil, fmt.Errorf("%w: %s is not in any group mapped to a role", ErrUnauthenticated, name)
+	}
+
+	return &Principal{Name: name, Role: role}, nil
+}
+
+// principalKey is the context key for the authenticated principal
+type principalKey struct{}
+
+// PrincipalFrom returns the principal stored in the request context by Authorize
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// Authorize wraps a handler so that it only runs for clients allowed to perform op:
+// requests without a valid bearer token get 401, clients whose role does not allow op get 403
+func Authorize(auth Authenticator, op Operation, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="labrat"`)
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		principal, err := auth.Authenticate(r.Context(), token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="labrat", error="invalid_token"`)
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		if !principal.Role.Allows(op) {
+			http.Error(w, fmt.Sprintf("role %s may not perform %s operations", principal.Role, op), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	})
+}
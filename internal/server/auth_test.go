@@ -0,0 +1,236 @@
+//go:build test
+
+package server_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/server"
+)
+
+// hashToken returns the hex SHA-256 hash stored in the config for token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// fakeIssuer is an OIDC provider serving discovery and JWKS documents and signing ID tokens
+type fakeIssuer struct {
+	server *httptest.Server
+	signer jose.Signer
+}
+
+func newFakeIssuer() *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: key, KeyID: "test"}}, nil)
+	Expect(err).NotTo(HaveOccurred())
+
+	issuer := &fakeIssuer{signer: signer}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer.server.URL,
+			"jwks_uri":                              issuer.server.URL + "/keys",
+			"authorization_endpoint":                issuer.server.URL + "/auth",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"},
+		}})
+	})
+	issuer.server = httptest.NewServer(mux)
+	return issuer
+}
+
+// token signs an ID token for the labrat client with the given extra claims
+func (f *fakeIssuer) token(claims map[string]interface{}) string {
+	payload := map[string]interface{}{
+		"iss": f.server.URL,
+		"aud": "labrat",
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	for k, v := range claims {
+		payload[k] = v
+	}
+	data, err := json.Marshal(payload)
+	Expect(err).NotTo(HaveOccurred())
+	signed, err := f.signer.Sign(data)
+	Expect(err).NotTo(HaveOccurred())
+	raw, err := signed.CompactSerialize()
+	Expect(err).NotTo(HaveOccurred())
+	return raw
+}
+
+var _ = Describe("Roles", func() {
+	DescribeTable("allowed operations",
+		func(role server.Role, op server.Operation, allowed bool) {
+			Expect(role.Allows(op)).To(Equal(allowed))
+		},
+		Entry("viewer reads", server.RoleViewer, server.OperationRead, true),
+		Entry("viewer cannot extract kubeconfigs", server.RoleViewer, server.OperationKubeconfig, false),
+		Entry("operator extracts kubeconfigs", server.RoleOperator, server.OperationKubeconfig, true),
+		Entry("operator changes power state", server.RoleOperator, server.OperationPower, true),
+		Entry("operator cannot provision", server.RoleOperator, server.OperationProvision, false),
+		Entry("admin provisions", server.RoleAdmin, server.OperationProvision, true),
+		Entry("unknown role reads nothing", server.Role("guest"), server.OperationRead, false),
+	)
+
+	It("should reject unknown role names", func() {
+		_, err := server.ParseRole("superuser")
+		Expect(err).To(MatchError(ContainSubstring(`unknown role "superuser"`)))
+	})
+})
+
+var _ = Describe("Authenticator", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Describe("static tokens", func() {
+		var auth server.Authenticator
+
+		BeforeEach(func() {
+			var err error
+			auth, err = server.NewTokenAuthenticator([]config.TokenConfig{
+				{Name: "portal", Role: "viewer", SHA256: hashToken("portal-token")},
+				{Name: "automation", Role: "admin", SHA256: hashToken("automation-token")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should return the principal of a known token", func() {
+			principal, err := auth.Authenticate(ctx, "automation-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*principal).To(Equal(server.Principal{Name: "automation", Role: server.RoleAdmin}))
+		})
+
+		It("should reject unknown tokens", func() {
+			_, err := auth.Authenticate(ctx, "guess")
+			Expect(err).To(MatchError(server.ErrUnauthenticated))
+		})
+
+		It("should reject invalid token configuration", func() {
+			_, err := server.NewTokenAuthenticator([]config.TokenConfig{{Name: "portal", Role: "viewer", SHA256: "abc"}})
+			Expect(err).To(MatchError(ContainSubstring("sha256 must be a hex-encoded SHA-256 hash")))
+
+			_, err = server.NewTokenAuthenticator([]config.TokenConfig{{Name: "portal", Role: "root", SHA256: hashToken("x")}})
+			Expect(err).To(MatchError(ContainSubstring(`unknown role "root"`)))
+		})
+	})
+
+	Describe("OIDC", func() {
+		var (
+			issuer *fakeIssuer
+			auth   server.Authenticator
+		)
+
+		BeforeEach(func() {
+			issuer = newFakeIssuer()
+			DeferCleanup(issuer.server.Close)
+
+			var err error
+			auth, err = server.NewOIDCAuthenticator(ctx, config.OIDCConfig{
+				IssuerURL: issuer.server.URL,
+				ClientID:  "labrat",
+				Roles: map[string][]string{
+					"viewer":   {"partner-labs"},
+					"operator": {"partner-labs-sre"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should grant the most privileged role of the user's groups", func() {
+			principal, err := auth.Authenticate(ctx, issuer.token(map[string]interface{}{
+				"email":  "sre@example.com",
+				"groups": []string{"partner-labs", "partner-labs-sre"},
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*principal).To(Equal(server.Principal{Name: "sre@example.com", Role: server.RoleOperator}))
+		})
+
+		It("should reject users without a mapped group", func() {
+			_, err := auth.Authenticate(ctx, issuer.token(map[string]interface{}{"groups": []string{"other"}}))
+			Expect(err).To(MatchError(ContainSubstring("not in any group mapped to a role")))
+		})
+
+		It("should reject tokens for another client", func() {
+			_, err := auth.Authenticate(ctx, issuer.token(map[string]interface{}{"aud": "other", "groups": []string{"partner-labs"}}))
+			Expect(err).To(MatchError(server.ErrUnauthenticated))
+		})
+	})
+
+	It("should require at least one authentication method", func() {
+		_, err := server.NewAuthenticator(ctx, config.AuthConfig{})
+		Expect(err).To(MatchError(ContainSubstring("no authentication configured")))
+	})
+})
+
+var _ = Describe("Authorize", func() {
+	var auth server.Authenticator
+
+	BeforeEach(func() {
+		var err error
+		auth, err = server.NewAuthenticator(context.Background(), config.AuthConfig{Tokens: []config.TokenConfig{
+			{Name: "portal", Role: "viewer", SHA256: hashToken("portal-token")},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	serve := func(op server.Operation, authorization string) *httptest.ResponseRecorder {
+		handler := server.Authorize(auth, op, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := server.PrincipalFrom(r.Context())
+			Expect(ok).To(BeTrue())
+			_, _ = w.Write([]byte(principal.Name))
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/clusters", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should pass allowed requests through with the principal", func() {
+		rec := serve(server.OperationRead, "Bearer portal-token")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(Equal("portal"))
+	})
+
+	It("should return 401 without a bearer token", func() {
+		rec := serve(server.OperationRead, "")
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(rec.Header().Get("WWW-Authenticate")).To(HavePrefix("Bearer"))
+	})
+
+	It("should return 401 for an invalid token", func() {
+		rec := serve(server.OperationRead, "Bearer wrong")
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should return 403 when the role does not allow the operation", func() {
+		rec := serve(server.OperationKubeconfig, "Bearer portal-token")
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+	})
+})
@@ -0,0 +1,263 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+const (
+	// DefaultEventPollInterval is how often the hub is polled for cluster changes
+	DefaultEventPollInterval = 30 * time.Second
+	// eventBufferSize is the number of events queued per subscriber before events are dropped
+	eventBufferSize = 64
+	// heartbeatInterval keeps idle event streams open through proxies
+	heartbeatInterval = 15 * time.Second
+)
+
+// EventType is a cluster lifecycle transition
+type EventType string
+
+const (
+	// EventCreated is published when a new ClusterDeployment appears
+	EventCreated EventType = "created"
+	// EventProvisioned is published when Hive finishes installing a cluster
+	EventProvisioned EventType = "provisioned"
+	// EventReady is published when a ManagedCluster becomes available
+	EventReady EventType = "ready"
+	// EventHibernated is published when a cluster finishes hibernating
+	EventHibernated EventType = "hibernated"
+	// EventResumed is published when a hibernated cluster is running again
+	EventResumed EventType = "resumed"
+	// EventFailed is published when Hive reports a failed provision
+	EventFailed EventType = "failed"
+	// EventExpiring is published when a cluster's lease is about to end
+	EventExpiring EventType = "expiring"
+)
+
+// Event is a cluster lifecycle event pushed to stream subscribers
+type Event struct {
+	Type    EventType `json:"type"`
+	Cluster string    `json:"cluster"`
+	Time    time.Time `json:"time"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Broker fans events out to subscribers. Subscribers that fall behind lose events
+// rather than blocking publishers.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBroker creates a new Broker
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving published events and a function that ends the subscription
+func (b *Broker) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish sends an event to every subscriber
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// clusterState is the part of a cluster's state that lifecycle events are derived from
+type clusterState struct {
+	deployed        bool
+	installed       bool
+	status          hub.ClusterStatus
+	powerState      string
+	provisionFailed bool
+	// expiring is set once the lease ends within hub.DefaultLeaseWarning, or has ended
+	expiring  bool
+	expiresAt time.Time
+}
+
+// EventSource polls the hub and publishes lifecycle events for clusters whose state changed
+type EventSource struct {
+	managedClusterClient    hub.ManagedClusterClient
+	clusterDeploymentClient hub.ClusterDeploymentClient
+	broker                  *Broker
+	now                     func() time.Time
+
+	// previous is nil until the first poll, which only records a baseline
+	previous map[string]clusterState
+}
+
+// NewEventSource creates a new EventSource publishing to broker
+func NewEventSource(mcClient hub.ManagedClusterClient, cdClient hub.ClusterDeploymentClient, broker *Broker) *EventSource {
+	return &EventSource{
+		managedClusterClient:    mcClient,
+		clusterDeploymentClient: cdClient,
+		broker:                  broker,
+		now:                     time.Now,
+	}
+}
+
+// Run polls the hub every interval until ctx is done. Poll errors are skipped; the next
+// successful poll publishes whatever changed in between.
+func (s *EventSource) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultEventPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = s.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll reads the current cluster state and publishes an event for every transition since the
+// previous poll. The first poll records a baseline without publishing.
+func (s *EventSource) Poll(ctx context.Context) error {
+	managedClusters, err := s.managedClusterClient.List(ctx)
+	if err != nil {
+		return err
+	}
+	deployments, err := s.clusterDeploymentClient.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	current := make(map[string]clusterState, len(deployments)+len(managedClusters))
+	for _, cd := range deployments {
+		state := clusterState{
+			deployed:        true,
+			installed:       cd.Installed,
+			powerState:      cd.PowerState,
+			provisionFailed: cd.ProvisionFailed,
+		}
+		if cd.ExpiresAt != nil {
+			state.expiresAt = *cd.ExpiresAt
+			state.expiring = cd.ExpiresAt.Sub(now) <= hub.DefaultLeaseWarning
+		}
+		current[cd.Name] = state
+	}
+	for _, mc := range managedClusters {
+		state := current[mc.Name]
+		state.status = mc.Status
+		current[mc.Name] = state
+	}
+
+	if s.previous != nil {
+		for name, state := range current {
+			for _, event := range transitions(s.previous[name], state) {
+				event.Cluster = name
+				event.Time = now
+				s.broker.Publish(event)
+			}
+		}
+	}
+	s.previous = current
+	return nil
+}
+
+// transitions returns the lifecycle events between two states of a cluster
+func transitions(before, after clusterState) []Event {
+	var events []Event
+	if after.deployed && !before.deployed {
+		events = append(events, Event{Type: EventCreated, Message: "ClusterDeployment created"})
+	}
+	if after.installed && !before.installed {
+		events = append(events, Event{Type: EventProvisioned, Message: "provision complete"})
+	}
+	if after.provisionFailed && !before.provisionFailed {
+		events = append(events, Event{Type: EventFailed, Message: "provision failed"})
+	}
+	if after.status == hub.StatusReady && before.status != hub.StatusReady {
+		events = append(events, Event{Type: EventReady, Message: "ManagedCluster available"})
+	}
+	if after.powerState == "Hibernating" && before.powerState != "Hibernating" {
+		events = append(events, Event{Type: EventHibernated, Message: "cluster hibernated"})
+	}
+	if after.powerState == "Running" && before.powerState == "Hibernating" {
+		events = append(events, Event{Type: EventResumed, Message: "cluster resumed"})
+	}
+	if after.expiring && !before.expiring {
+		events = append(events, Event{Type: EventExpiring, Message: "lease ends " + after.expiresAt.UTC().Format(time.RFC3339)})
+	}
+	return events
+}
+
+// EventsHandler streams broker events to the client as server-sent events, one
+// "event: <type>" message with a JSON payload per lifecycle event
+func EventsHandler(broker *Broker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		events, unsubscribe := broker.Subscribe()
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	})
+}
@@ -0,0 +1,212 @@
+//go:build test
+
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/server"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+// fakeHub implements the hub cluster clients with in-memory clusters
+type fakeHub struct {
+	managedClusters    []hub.ManagedClusterInfo
+	clusterDeployments []hub.ClusterDeploymentInfo
+}
+
+func (f *fakeHub) List(_ context.Context) ([]hub.ManagedClusterInfo, error) {
+	return f.managedClusters, nil
+}
+
+func (f *fakeHub) Watch(_ context.Context) (<-chan hub.ManagedClusterEvent, error) {
+	return nil, errors.New("watch not supported")
+}
+
+func (f *fakeHub) Get(_ context.Context, _ string) (*hub.ManagedClusterDetail, error) {
+	return nil, errors.New("get not supported")
+}
+
+func (f *fakeHub) Filter(clusters []hub.ManagedClusterInfo, _ hub.ManagedClusterFilter) []hub.ManagedClusterInfo {
+	return clusters
+}
+
+// deployments adapts the fake to hub.ClusterDeploymentClient
+type deployments struct{ *fakeHub }
+
+func (d deployments) Get(_ context.Context, name string) (*hub.ClusterDeploymentInfo, error) {
+	for i := range d.clusterDeployments {
+		if d.clusterDeployments[i].Name == name {
+			return &d.clusterDeployments[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (d deployments) List(_ context.Context) ([]hub.ClusterDeploymentInfo, error) {
+	return d.clusterDeployments, nil
+}
+
+func (d deployments) FindByRequestID(_ context.Context, _ string) (*hub.ClusterDeploymentInfo, error) {
+	return nil, nil
+}
+
+// drain returns the events currently queued on ch, stopping when ch is closed
+func drain(ch <-chan server.Event) []server.Event {
+	var events []server.Event
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// types returns the type and cluster of each event
+func types(events []server.Event) []string {
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		out = append(out, string(e.Type)+" "+e.Cluster)
+	}
+	return out
+}
+
+var _ = Describe("Broker", func() {
+	It("should deliver events to every subscriber until it unsubscribes", func() {
+		broker := server.NewBroker()
+		first, unsubscribeFirst := broker.Subscribe()
+		second, unsubscribeSecond := broker.Subscribe()
+		defer unsubscribeSecond()
+
+		broker.Publish(server.Event{Type: server.EventReady, Cluster: "a"})
+		unsubscribeFirst()
+		broker.Publish(server.Event{Type: server.EventReady, Cluster: "b"})
+
+		Expect(types(drain(first))).To(Equal([]string{"ready a"}))
+		Expect(types(drain(second))).To(Equal([]string{"ready a", "ready b"}))
+	})
+
+	It("should drop events for subscribers that fall behind", func() {
+		broker := server.NewBroker()
+		events, unsubscribe := broker.Subscribe()
+		defer unsubscribe()
+
+		for i := 0; i < 100; i++ {
+			broker.Publish(server.Event{Type: server.EventReady, Cluster: "a"})
+		}
+		Expect(len(drain(events))).To(BeNumerically("<", 100))
+	})
+})
+
+var _ = Describe("EventSource", func() {
+	var (
+		ctx    context.Context
+		fake   *fakeHub
+		broker *server.Broker
+		events <-chan server.Event
+		source *server.EventSource
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = &fakeHub{
+			managedClusters:    []hub.ManagedClusterInfo{{Name: "running", Status: hub.StatusReady}},
+			clusterDeployments: []hub.ClusterDeploymentInfo{{Name: "running", PowerState: "Running", Installed: true}},
+		}
+		broker = server.NewBroker()
+		var unsubscribe func()
+		events, unsubscribe = broker.Subscribe()
+		DeferCleanup(unsubscribe)
+		source = server.NewEventSource(fake, deployments{fake}, broker)
+	})
+
+	It("should not publish events for the initial state", func() {
+		Expect(source.Poll(ctx)).To(Succeed())
+		Expect(drain(events)).To(BeEmpty())
+	})
+
+	It("should publish lifecycle transitions between polls", func() {
+		Expect(source.Poll(ctx)).To(Succeed())
+
+		fake.clusterDeployments = []hub.ClusterDeploymentInfo{
+			{Name: "running", PowerState: "Hibernating", Installed: true},
+			{Name: "new", PowerState: "Unknown"},
+			{Name: "broken", PowerState: "Unknown", ProvisionFailed: true},
+		}
+		Expect(source.Poll(ctx)).To(Succeed())
+		Expect(types(drain(events))).To(ConsistOf("hibernated running", "created new", "created broken", "failed broken"))
+
+		fake.clusterDeployments[1] = hub.ClusterDeploymentInfo{Name: "new", PowerState: "Running", Installed: true}
+		fake.managedClusters = append(fake.managedClusters, hub.ManagedClusterInfo{Name: "new", Status: hub.StatusReady})
+		Expect(source.Poll(ctx)).To(Succeed())
+		Expect(types(drain(events))).To(Equal([]string{"provisioned new", "ready new"}))
+
+		fake.clusterDeployments[0].PowerState = "Running"
+		Expect(source.Poll(ctx)).To(Succeed())
+		Expect(types(drain(events))).To(Equal([]string{"resumed running"}))
+	})
+
+	It("should publish once when a lease enters the warning window", func() {
+		Expect(source.Poll(ctx)).To(Succeed())
+
+		later := time.Now().Add(30 * 24 * time.Hour)
+		fake.clusterDeployments[0].ExpiresAt = &later
+		Expect(source.Poll(ctx)).To(Succeed())
+		Expect(drain(events)).To(BeEmpty())
+
+		soon := time.Now().Add(24 * time.Hour)
+		fake.clusterDeployments[0].ExpiresAt = &soon
+		Expect(source.Poll(ctx)).To(Succeed())
+		published := drain(events)
+		Expect(types(published)).To(Equal([]string{"expiring running"}))
+		Expect(published[0].Message).To(Equal("lease ends " + soon.UTC().Format(time.RFC3339)))
+
+		Expect(source.Poll(ctx)).To(Succeed())
+		Expect(drain(events)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("EventsHandler", func() {
+	It("should stream published events as server-sent events", func() {
+		broker := server.NewBroker()
+		srv := httptest.NewServer(server.EventsHandler(broker))
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		broker.Publish(server.Event{Type: server.EventFailed, Cluster: "broken", Message: "provision failed"})
+
+		reader := bufio.NewReader(resp.Body)
+		line, err := reader.ReadString('\n')
+		Expect(err).NotTo(HaveOccurred())
+		Expect(line).To(Equal("event: failed\n"))
+
+		line, err = reader.ReadString('\n')
+		Expect(err).NotTo(HaveOccurred())
+		var event server.Event
+		Expect(json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event)).To(Succeed())
+		Expect(event.Cluster).To(Equal("broken"))
+		Expect(event.Message).To(Equal("provision failed"))
+	})
+})
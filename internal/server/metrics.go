@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+// DefaultMetricsPollInterval is how often the hub is polled for the cluster metrics
+const DefaultMetricsPollInterval = time.Minute
+
+// installDurationBuckets are the upper bounds in seconds of the install duration histogram;
+// OpenShift installs usually take 30 to 60 minutes
+var installDurationBuckets = []float64{1800, 2400, 3000, 3600, 4500, 5400, 7200, 10800}
+
+// notAvailable is the label value of cluster fields without a value, as in hub summary
+const notAvailable = "N/A"
+
+// Metrics exposes Prometheus metrics of the hub clusters and of the API itself:
+//
+//	labrat_clusters{status,power_state,platform}     clusters on the hub
+//	labrat_cluster_install_duration_seconds          install durations of the installed clusters
+//	labrat_api_errors_total{route,code}              API responses with a 4xx or 5xx status
+//	labrat_hub_poll_errors_total                     failed polls of the hub
+type Metrics struct {
+	managedClusterClient    hub.ManagedClusterClient
+	clusterDeploymentClient hub.ClusterDeploymentClient
+	registry                *prometheus.Registry
+
+	clusters   *prometheus.GaugeVec
+	apiErrors  *prometheus.CounterVec
+	pollErrors prometheus.Counter
+
+	installDurationDesc *prometheus.Desc
+	mu                  sync.Mutex
+	// installDurations are the install durations in seconds of the last poll
+	installDurations []float64
+}
+
+// NewMetrics creates Metrics for the clusters listed by mcClient and cdClient
+func NewMetrics(mcClient hub.ManagedClusterClient, cdClient hub.ClusterDeploymentClient) *Metrics {
+	m := &Metrics{
+		managedClusterClient:    mcClient,
+		clusterDeploymentClient: cdClient,
+		registry:                prometheus.NewRegistry(),
+		clusters: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "labrat_clusters",
+			Help: "Number of clusters on the hub by ManagedCluster status, power state, and platform.",
+		}, []string{"status", "power_state", "platform"}),
+		apiErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "labrat_api_errors_total",
+			Help: "Number of labrat API responses with a 4xx or 5xx status.",
+		}, []string{"route", "code"}),
+		pollErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "labrat_hub_poll_errors_total",
+			Help: "Number of failed polls of the hub for the cluster metrics.",
+		}),
+		installDurationDesc: prometheus.NewDesc(
+			"labrat_cluster_install_duration_seconds",
+			"Install durations of the installed Hive clusters on the hub.",
+			nil, nil,
+		),
+	}
+	m.registry.MustRegister(
+		m.clusters, m.apiErrors, m.pollErrors, installDurationCollector{m},
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Run polls the hub every interval until ctx is done. A failed poll keeps the metrics of the
+// previous one and is counted in labrat_hub_poll_errors_total.
+func (m *Metrics) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultMetricsPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = m.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll lists the clusters of the hub and replaces the cluster metrics
+func (m *Metrics) Poll(ctx context.Context) error {
+	managedClusters, err := m.managedClusterClient.List(ctx)
+	if err != nil {
+		m.pollErrors.Inc()
+		return err
+	}
+	deployments, err := m.clusterDeploymentClient.List(ctx)
+	if err != nil {
+		m.pollErrors.Inc()
+		return err
+	}
+
+	type clusterLabels struct{ status, powerState, platform string }
+	clusters := make(map[string]clusterLabels, len(managedClusters)+len(deployments))
+	var durations []float64
+	for _, cd := range deployments {
+		clusters[cd.Name] = clusterLabels{status: notAvailable, powerState: labelValue(cd.PowerState), platform: labelValue(cd.Platform)}
+		if cd.Installed && cd.InstallStartedAt != nil && cd.InstalledAt != nil && cd.InstalledAt.After(*cd.InstallStartedAt) {
+			durations = append(durations, cd.InstalledAt.Sub(*cd.InstallStartedAt).Seconds())
+		}
+	}
+	for _, mc := range managedClusters {
+		cluster, ok := clusters[mc.Name]
+		if !ok {
+			cluster = clusterLabels{powerState: notAvailable, platform: notAvailable}
+		}
+		cluster.status = labelValue(string(mc.Status))
+		clusters[mc.Name] = cluster
+	}
+
+	m.clusters.Reset()
+	for _, cluster := range clusters {
+		m.clusters.WithLabelValues(cluster.status, cluster.powerState, cluster.platform).Inc()
+	}
+
+	m.mu.Lock()
+	m.installDurations = durations
+	m.mu.Unlock()
+	return nil
+}
+
+// Instrument counts the 4xx and 5xx responses of next by route
+func (m *Metrics) Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if recorder.status < http.StatusBadRequest {
+			return
+		}
+		// The mux sets the pattern of the matched route on the request
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		m.apiErrors.WithLabelValues(route, strconv.Itoa(recorder.status)).Inc()
+	})
+}
+
+// labelValue returns value, or N/A if it is empty
+func labelValue(value string) string {
+	if value == "" {
+		return notAvailable
+	}
+	return value
+}
+
+// installDurationCollector exposes the install durations of the last poll as a histogram
+type installDurationCollector struct {
+	metrics *Metrics
+}
+
+// Describe sends the descriptor of the histogram
+func (c installDurationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.metrics.installDurationDesc
+}
+
+// Collect builds the histogram from the install durations of the last poll
+func (c installDurationCollector) Collect(ch chan<- prometheus.Metric) {
+	c.metrics.mu.Lock()
+	durations := c.metrics.installDurations
+	c.metrics.mu.Unlock()
+
+	// Every bucket is listed, as a histogram only exposes the buckets it is given
+	buckets := make(map[float64]uint64, len(installDurationBuckets))
+	for _, bound := range installDurationBuckets {
+		buckets[bound] = 0
+	}
+	var sum float64
+	for _, duration := range durations {
+		sum += duration
+		for _, bound := range installDurationBuckets {
+			if duration <= bound {
+				buckets[bound]++
+			}
+		}
+	}
+	ch <- prometheus.MustNewConstHistogram(c.metrics.installDurationDesc, uint64(len(durations)), sum, buckets)
+}
+
+// statusRecorder records the status code of a response. It keeps the flushing of event
+// streams working.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush flushes the underlying writer if it supports flushing
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,114 @@
+//go:build test
+
+package server_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/server"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("Metrics", func() {
+	var (
+		fake    *fakeHub
+		metrics *server.Metrics
+		srv     *httptest.Server
+	)
+
+	// scrape returns the metrics served with the viewer token
+	scrape := func() string {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/metrics", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer viewer-token")
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return string(body)
+	}
+
+	BeforeEach(func() {
+		started := time.Date(2026, 1, 10, 8, 0, 0, 0, time.UTC)
+		installed := started.Add(40 * time.Minute)
+		fake = &fakeHub{
+			managedClusters: []hub.ManagedClusterInfo{
+				{Name: "running", Status: hub.StatusReady},
+				{Name: "sleeping", Status: hub.StatusUnknown},
+				{Name: "imported", Status: hub.StatusReady},
+			},
+			clusterDeployments: []hub.ClusterDeploymentInfo{
+				{Name: "running", PowerState: "Running", Platform: "aws", Installed: true, InstallStartedAt: &started, InstalledAt: &installed},
+				{Name: "sleeping", PowerState: "Hibernating", Platform: "aws", Installed: true},
+				{Name: "new", PowerState: "Unknown", Platform: "gcp", InstallStartedAt: &started},
+			},
+		}
+		metrics = server.NewMetrics(fake, deployments{fake})
+
+		auth, err := server.NewTokenAuthenticator([]config.TokenConfig{
+			{Name: "prometheus", Role: "viewer", SHA256: hashToken("viewer-token")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		srv = httptest.NewServer(server.NewHandler(server.Backend{}, auth, server.NewBroker(), metrics, nil))
+		DeferCleanup(srv.Close)
+	})
+
+	It("should count the clusters by status, power state, and platform", func() {
+		Expect(metrics.Poll(context.Background())).To(Succeed())
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`labrat_clusters{platform="aws",power_state="Running",status="Ready"} 1`))
+		Expect(body).To(ContainSubstring(`labrat_clusters{platform="aws",power_state="Hibernating",status="Unknown"} 1`))
+		Expect(body).To(ContainSubstring(`labrat_clusters{platform="gcp",power_state="Unknown",status="N/A"} 1`))
+		Expect(body).To(ContainSubstring(`labrat_clusters{platform="N/A",power_state="N/A",status="Ready"} 1`))
+	})
+
+	It("should drop clusters that are gone at the next poll", func() {
+		Expect(metrics.Poll(context.Background())).To(Succeed())
+		fake.clusterDeployments = fake.clusterDeployments[:2]
+		Expect(metrics.Poll(context.Background())).To(Succeed())
+
+		Expect(scrape()).NotTo(ContainSubstring(`platform="gcp"`))
+	})
+
+	It("should expose the install durations of the installed clusters", func() {
+		Expect(metrics.Poll(context.Background())).To(Succeed())
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`labrat_cluster_install_duration_seconds_bucket{le="1800"} 0`))
+		Expect(body).To(ContainSubstring(`labrat_cluster_install_duration_seconds_bucket{le="2400"} 1`))
+		Expect(body).To(ContainSubstring(`labrat_cluster_install_duration_seconds_sum 2400`))
+		Expect(body).To(ContainSubstring(`labrat_cluster_install_duration_seconds_count 1`))
+	})
+
+	It("should count the API errors by route and status", func() {
+		resp, err := http.Get(srv.URL + "/api/v1/clusters")
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+
+		resp, err = http.Get(srv.URL + "/api/v1/unknown")
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`labrat_api_errors_total{code="401",route="GET /api/v1/clusters"} 1`))
+		Expect(body).To(ContainSubstring(`labrat_api_errors_total{code="404",route="unmatched"} 1`))
+	})
+
+	It("should require a token to scrape the metrics", func() {
+		resp, err := http.Get(srv.URL + "/metrics")
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+	})
+})
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/audit"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+const (
+	// DefaultAddress is the address the API server listens on unless configured otherwise
+	DefaultAddress = ":8080"
+	// clusterEvents is the number of Kubernetes events included in cluster details
+	clusterEvents = 10
+)
+
+// Backend holds the hub and spoke clients that serve the API requests
+type Backend struct {
+	// Clusters lists the managed clusters with their ClusterDeployment details
+	Clusters hub.CombinedClusterClient
+	// Status reads the details of a single cluster
+	Status spoke.StatusReader
+	// Kubeconfigs extracts the admin kubeconfigs of clusters
+	Kubeconfigs spoke.KubeconfigExtractor
+	// Power hibernates and resumes clusters
+	Power spoke.PowerManager
+	// Audit records the kubeconfig and power requests; nil disables the audit records
+	Audit *audit.Logger
+}
+
+// PowerResponse is the body of a successful hibernate or resume request
+type PowerResponse struct {
+	Cluster    string `json:"cluster"`
+	PowerState string `json:"powerState"`
+}
+
+// api implements the handlers of the API routes
+type api struct {
+	backend Backend
+	logger  *slog.Logger
+}
+
+// NewHandler creates the HTTP handler of the labrat API. Every route but /healthz requires a
+// bearer token accepted by auth and a role allowing the route's operation:
+//
+//	GET  /api/v1/clusters                     read: list clusters, optionally ?status=Ready or ?name=<cluster>
+//	GET  /api/v1/clusters/{name}              read: cluster details
+//	GET  /api/v1/clusters/{name}/kubeconfig   kubeconfig: admin kubeconfig of the cluster
+//	POST /api/v1/clusters/{name}/hibernate    power: hibernate the cluster
+//	POST /api/v1/clusters/{name}/resume       power: resume the cluster
+//	GET  /api/v1/events                       read: server-sent cluster lifecycle events
+//	GET  /metrics                             read: Prometheus metrics, unless metrics is nil
+func NewHandler(backend Backend, auth Authenticator, broker *Broker, metrics *Metrics, logger *slog.Logger) http.Handler {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	a := &api{backend: backend, logger: logger}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.Handle("GET /api/v1/clusters", Authorize(auth, OperationRead, http.HandlerFunc(a.listClusters)))
+	mux.Handle("GET /api/v1/clusters/{name}", Authorize(auth, OperationRead, http.HandlerFunc(a.getCluster)))
+	mux.Handle("GET /api/v1/clusters/{name}/kubeconfig", Authorize(auth, OperationKubeconfig, http.HandlerFunc(a.getKubeconfig)))
+	mux.Handle("POST /api/v1/clusters/{name}/hibernate", Authorize(auth, OperationPower, a.setPowerState(spoke.PowerStateHibernating)))
+	mux.Handle("POST /api/v1/clusters/{name}/resume", Authorize(auth, OperationPower, a.setPowerState(spoke.PowerStateRunning)))
+	mux.Handle("GET /api/v1/events", Authorize(auth, OperationRead, EventsHandler(broker)))
+	if metrics == nil {
+		return mux
+	}
+	mux.Handle("GET /metrics", Authorize(auth, OperationRead, metrics.Handler()))
+	return metrics.Instrument(mux)
+}
+
+// listClusters writes the clusters of the hub, filtered by the status and name query parameters
+func (a *api) listClusters(w http.ResponseWriter, r *http.Request) {
+	clusters, err := a.backend.Clusters.ListCombined(r.Context())
+	if err != nil {
+		a.writeError(w, r, err)
+		return
+	}
+
+	status, name := r.URL.Query().Get("status"), r.URL.Query().Get("name")
+	if status != "" || name != "" {
+		filtered := make([]hub.CombinedClusterInfo, 0, len(clusters))
+		for _, cluster := range clusters {
+			if (status == "" || string(cluster.Status) == status) && (name == "" || cluster.Name == name) {
+				filtered = append(filtered, cluster)
+			}
+		}
+		clusters = filtered
+	}
+	if clusters == nil {
+		clusters = []hub.CombinedClusterInfo{}
+	}
+	a.writeJSON(w, http.StatusOK, clusters)
+}
+
+// getCluster writes the status of a cluster, as labrat spoke status -o json prints it
+func (a *api) getCluster(w http.ResponseWriter, r *http.Request) {
+	status, err := a.backend.Status.Read(r.Context(), r.PathValue("name"), clusterEvents)
+	if err != nil {
+		a.writeError(w, r, err)
+		return
+	}
+	a.writeJSON(w, http.StatusOK, status)
+}
+
+// getKubeconfig writes the admin kubeconfig of a cluster. Every extraction is logged and
+// audited with the principal that requested it.
+func (a *api) getKubeconfig(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	kubeconfig, err := a.backend.Kubeconfigs.Extract(r.Context(), name)
+	a.audit(r, name, err)
+	if err != nil {
+		a.writeError(w, r, err)
+		return
+	}
+
+	principal, _ := PrincipalFrom(r.Context())
+	a.logger.Info("kubeconfig extracted", "cluster", name, "principal", principal.Name, "role", principal.Role)
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"-kubeconfig"))
+	_, _ = w.Write(kubeconfig)
+}
+
+// setPowerState returns a handler requesting state for a cluster. Hive changes the power state
+// asynchronously, so the request is answered with 202 Accepted.
+func (a *api) setPowerState(state string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		err := a.backend.Power.SetPowerState(r.Context(), name, state)
+		a.audit(r, name, err)
+		if err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+
+		principal, _ := PrincipalFrom(r.Context())
+		a.logger.Info("power state requested", "cluster", name, "state", state, "principal", principal.Name)
+		a.writeJSON(w, http.StatusAccepted, PowerResponse{Cluster: name, PowerState: state})
+	})
+}
+
+// audit records the request r for cluster with its result in the audit log
+func (a *api) audit(r *http.Request, cluster string, err error) {
+	if a.backend.Audit == nil {
+		return
+	}
+	principal, _ := PrincipalFrom(r.Context())
+	record := audit.Record{
+		User:    principal.Name,
+		Source:  audit.SourceAPI,
+		Action:  r.Pattern,
+		Targets: []string{cluster},
+		Result:  audit.ResultSuccess,
+	}
+	if err != nil {
+		record.Result = audit.ResultFailure
+		record.Error = err.Error()
+	}
+	if logErr := a.backend.Audit.Log(r.Context(), record); logErr != nil {
+		a.logger.Error("failed to write audit record", "action", record.Action, "cluster", cluster, "error", logErr)
+	}
+}
+
+// writeJSON writes v as the JSON body of a response with status code
+func (a *api) writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		a.logger.Error("failed to marshal response", "error", err)
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// writeError answers a failed request with 404 for clusters that do not exist and 500 otherwise
+func (a *api) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if apierrors.IsNotFound(err) || errors.Is(err, spoke.ErrClusterNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	a.logger.Error("request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,214 @@
+//go:build test
+
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/audit"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/config"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/server"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeBackend implements the clients of server.Backend with in-memory clusters
+type fakeBackend struct {
+	clusters    []hub.CombinedClusterInfo
+	powerStates map[string]string
+}
+
+func (f *fakeBackend) ListCombined(_ context.Context) ([]hub.CombinedClusterInfo, error) {
+	return f.clusters, nil
+}
+
+func (f *fakeBackend) Read(_ context.Context, clusterName string, _ int) (*spoke.ClusterStatus, error) {
+	for _, cluster := range f.clusters {
+		if cluster.Name == clusterName {
+			return &spoke.ClusterStatus{Name: clusterName, Managed: true}, nil
+		}
+	}
+	return nil, fmt.Errorf("cluster %s %w", clusterName, spoke.ErrClusterNotFound)
+}
+
+func (f *fakeBackend) Extract(_ context.Context, clusterName string) ([]byte, error) {
+	return []byte("apiVersion: v1\nkind: Config\n# " + clusterName + "\n"), nil
+}
+
+func (f *fakeBackend) ExtractToFile(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (f *fakeBackend) SetPowerState(_ context.Context, clusterName, state string) error {
+	if clusterName == "missing" {
+		gr := schema.GroupResource{Group: "hive.openshift.io", Resource: "clusterdeployments"}
+		return fmt.Errorf("failed to set power state of ClusterDeployment %s: %w", clusterName, apierrors.NewNotFound(gr, clusterName))
+	}
+	f.powerStates[clusterName] = state
+	return nil
+}
+
+var _ = Describe("NewHandler", func() {
+	var (
+		backend  *fakeBackend
+		srv      *httptest.Server
+		auditLog string
+	)
+
+	// do sends a request with the bearer token and returns the response with its body
+	do := func(method, path, token string) (*http.Response, string) {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(body)
+	}
+
+	BeforeEach(func() {
+		backend = &fakeBackend{
+			clusters: []hub.CombinedClusterInfo{
+				{Name: "ready", Status: hub.StatusReady, PowerState: "Running"},
+				{Name: "broken", Status: hub.StatusNotReady, PowerState: "Running"},
+			},
+			powerStates: map[string]string{},
+		}
+		auth, err := server.NewTokenAuthenticator([]config.TokenConfig{
+			{Name: "portal", Role: "viewer", SHA256: hashToken("viewer-token")},
+			{Name: "sre", Role: "operator", SHA256: hashToken("operator-token")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		auditLog = filepath.Join(GinkgoT().TempDir(), "audit.log")
+
+		srv = httptest.NewServer(server.NewHandler(server.Backend{
+			Clusters:    backend,
+			Status:      backend,
+			Kubeconfigs: backend,
+			Power:       backend,
+			Audit:       audit.NewLogger(audit.NewFileSink(auditLog)),
+		}, auth, server.NewBroker(), nil, nil))
+		DeferCleanup(srv.Close)
+	})
+
+	It("should answer health checks without a token", func() {
+		resp, body := do(http.MethodGet, "/healthz", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("ok\n"))
+	})
+
+	It("should reject requests without a valid token", func() {
+		resp, _ := do(http.MethodGet, "/api/v1/clusters", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+
+		resp, _ = do(http.MethodGet, "/api/v1/clusters", "wrong-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should list the clusters, filtered by status and name", func() {
+		resp, body := do(http.MethodGet, "/api/v1/clusters", "viewer-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+		var clusters []hub.CombinedClusterInfo
+		Expect(json.Unmarshal([]byte(body), &clusters)).To(Succeed())
+		Expect(clusters).To(HaveLen(2))
+
+		_, body = do(http.MethodGet, "/api/v1/clusters?status=NotReady", "viewer-token")
+		Expect(json.Unmarshal([]byte(body), &clusters)).To(Succeed())
+		Expect(clusters).To(HaveLen(1))
+		Expect(clusters[0].Name).To(Equal("broken"))
+
+		_, body = do(http.MethodGet, "/api/v1/clusters?status=Unknown", "viewer-token")
+		Expect(body).To(Equal("[]\n"))
+
+		_, body = do(http.MethodGet, "/api/v1/clusters?name=ready", "viewer-token")
+		Expect(json.Unmarshal([]byte(body), &clusters)).To(Succeed())
+		Expect(clusters).To(HaveLen(1))
+		Expect(clusters[0].Name).To(Equal("ready"))
+	})
+
+	It("should return the details of a cluster", func() {
+		resp, body := do(http.MethodGet, "/api/v1/clusters/ready", "viewer-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var status spoke.ClusterStatus
+		Expect(json.Unmarshal([]byte(body), &status)).To(Succeed())
+		Expect(status.Name).To(Equal("ready"))
+
+		resp, _ = do(http.MethodGet, "/api/v1/clusters/missing", "viewer-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("should only return kubeconfigs to operators", func() {
+		resp, _ := do(http.MethodGet, "/api/v1/clusters/ready/kubeconfig", "viewer-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+
+		resp, body := do(http.MethodGet, "/api/v1/clusters/ready/kubeconfig", "operator-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/yaml"))
+		Expect(resp.Header.Get("Cache-Control")).To(Equal("no-store"))
+		Expect(body).To(ContainSubstring("# ready"))
+	})
+
+	It("should hibernate and resume clusters for operators", func() {
+		resp, _ := do(http.MethodPost, "/api/v1/clusters/ready/hibernate", "viewer-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(backend.powerStates).To(BeEmpty())
+
+		resp, body := do(http.MethodPost, "/api/v1/clusters/ready/hibernate", "operator-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(body).To(MatchJSON(`{"cluster": "ready", "powerState": "Hibernating"}`))
+		Expect(backend.powerStates).To(HaveKeyWithValue("ready", spoke.PowerStateHibernating))
+
+		resp, _ = do(http.MethodPost, "/api/v1/clusters/ready/resume", "operator-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(backend.powerStates).To(HaveKeyWithValue("ready", spoke.PowerStateRunning))
+
+		resp, _ = do(http.MethodPost, "/api/v1/clusters/missing/resume", "operator-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("should audit kubeconfig and power requests with the principal", func() {
+		do(http.MethodGet, "/api/v1/clusters/ready/kubeconfig", "operator-token")
+		do(http.MethodPost, "/api/v1/clusters/missing/hibernate", "operator-token")
+		do(http.MethodPost, "/api/v1/clusters/ready/hibernate", "viewer-token")
+
+		data, err := os.ReadFile(auditLog)
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		Expect(lines).To(HaveLen(2))
+
+		var record audit.Record
+		Expect(json.Unmarshal([]byte(lines[0]), &record)).To(Succeed())
+		Expect(record.User).To(Equal("sre"))
+		Expect(record.Source).To(Equal(audit.SourceAPI))
+		Expect(record.Action).To(Equal("GET /api/v1/clusters/{name}/kubeconfig"))
+		Expect(record.Targets).To(Equal([]string{"ready"}))
+		Expect(record.Result).To(Equal(audit.ResultSuccess))
+
+		Expect(json.Unmarshal([]byte(lines[1]), &record)).To(Succeed())
+		Expect(record.Targets).To(Equal([]string{"missing"}))
+		Expect(record.Result).To(Equal(audit.ResultFailure))
+		Expect(record.Error).To(ContainSubstring("not found"))
+	})
+
+	It("should only allow the methods of each route", func() {
+		resp, _ := do(http.MethodGet, "/api/v1/clusters/ready/hibernate", "operator-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
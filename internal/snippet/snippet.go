@@ -0,0 +1,125 @@
+// Package snippet loads install-config snippets: reusable fragments of an openshift-install
+// install-config, e.g. FIPS, proxy, or network CIDR settings a partner needs, stored as YAML
+// files in ~/.labrat/snippets. `labrat spoke create --snippet` merges them into the
+// install-config of the cluster.
+package snippet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDir is the directory install-config snippets are loaded from
+const DefaultDir = "~/.labrat/snippets"
+
+// extensions are the file extensions of snippets, in lookup order
+var extensions = []string{".yaml", ".yml"}
+
+// Snippet is a parsed install-config snippet
+type Snippet struct {
+	// Name is the name the snippet was loaded by
+	Name string
+	// Values are the install-config fields the snippet sets
+	Values map[string]interface{}
+}
+
+// Parse parses data as the snippet name, a YAML mapping of install-config fields
+func Parse(name string, data []byte) (*Snippet, error) {
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse snippet %s: %w", name, err)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("snippet %s is empty", name)
+	}
+	return &Snippet{Name: name, Values: values}, nil
+}
+
+// Loader loads install-config snippets from a directory
+type Loader struct {
+	dir string
+}
+
+// NewLoader creates a Loader reading snippets from dir
+func NewLoader(dir string) *Loader {
+	return &Loader{dir: dir}
+}
+
+// Load loads the snippet name from the directory of the loader, trying the extensions .yaml
+// and .yml. A name containing a path separator is read as a file path.
+func (l *Loader) Load(name string) (*Snippet, error) {
+	if strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snippet: %w", err)
+		}
+		return Parse(name, data)
+	}
+
+	for _, ext := range append([]string{""}, extensions...) {
+		data, err := os.ReadFile(filepath.Join(l.dir, name+ext))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snippet %s: %w", name, err)
+		}
+		return Parse(name, data)
+	}
+
+	available, _ := l.List()
+	if len(available) == 0 {
+		return nil, fmt.Errorf("snippet %s not found: %s has no snippets", name, l.dir)
+	}
+	return nil, fmt.Errorf("snippet %s not found in %s, available: %s", name, l.dir, strings.Join(available, ", "))
+}
+
+// LoadAll loads the snippets names in order, skipping names given more than once
+func (l *Loader) LoadAll(names []string) ([]*Snippet, error) {
+	seen := make(map[string]bool, len(names))
+	var snippets []*Snippet
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		s, err := l.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, s)
+	}
+	return snippets, nil
+}
+
+// List returns the names of the snippets in the directory of the loader, sorted
+func (l *Loader) List() ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippet directory %s: %w", l.dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		for _, ext := range extensions {
+			if name, ok := strings.CutSuffix(entry.Name(), ext); ok {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,94 @@
+//go:build test
+
+package snippet_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/snippet"
+)
+
+const proxySnippet = `proxy:
+  httpProxy: http://proxy.acme.example:3128
+  noProxy: .acme.example
+additionalTrustBundlePolicy: Always
+`
+
+var _ = Describe("Parse", func() {
+	It("should parse the install-config fields of a snippet", func() {
+		s, err := snippet.Parse("proxy-acme", []byte(proxySnippet))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Name).To(Equal("proxy-acme"))
+		Expect(s.Values).To(Equal(map[string]interface{}{
+			"proxy": map[string]interface{}{
+				"httpProxy": "http://proxy.acme.example:3128",
+				"noProxy":   ".acme.example",
+			},
+			"additionalTrustBundlePolicy": "Always",
+		}))
+	})
+
+	It("should reject snippets that are not a mapping", func() {
+		_, err := snippet.Parse("list", []byte("- fips: true\n"))
+		Expect(err).To(MatchError(ContainSubstring("failed to parse snippet list")))
+	})
+
+	It("should reject empty snippets", func() {
+		_, err := snippet.Parse("empty", []byte("# nothing yet\n"))
+		Expect(err).To(MatchError("snippet empty is empty"))
+	})
+})
+
+var _ = Describe("Loader", func() {
+	var (
+		dir    string
+		loader *snippet.Loader
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		loader = snippet.NewLoader(dir)
+		Expect(os.WriteFile(filepath.Join(dir, "fips.yaml"), []byte("fips: true\n"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "proxy-acme.yml"), []byte(proxySnippet), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "README.md"), []byte("notes"), 0600)).To(Succeed())
+	})
+
+	It("should load snippets by name with any extension", func() {
+		s, err := loader.Load("fips")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Values).To(Equal(map[string]interface{}{"fips": true}))
+
+		_, err = loader.Load("proxy-acme")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should load snippets by path", func() {
+		s, err := loader.Load(filepath.Join(dir, "fips.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Name).To(Equal(filepath.Join(dir, "fips.yaml")))
+	})
+
+	It("should load several snippets in order, once each", func() {
+		snippets, err := loader.LoadAll([]string{"proxy-acme", "fips", "proxy-acme"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snippets).To(HaveLen(2))
+		Expect(snippets[0].Name).To(Equal("proxy-acme"))
+		Expect(snippets[1].Name).To(Equal("fips"))
+	})
+
+	It("should list the available snippets when a snippet is missing", func() {
+		Expect(loader.List()).To(Equal([]string{"fips", "proxy-acme"}))
+
+		_, err := loader.LoadAll([]string{"fips", "missing"})
+		Expect(err).To(MatchError(ContainSubstring("available: fips, proxy-acme")))
+	})
+
+	It("should report a missing snippet directory", func() {
+		_, err := snippet.NewLoader(filepath.Join(dir, "missing")).Load("fips")
+		Expect(err).To(MatchError(ContainSubstring("has no snippets")))
+	})
+})
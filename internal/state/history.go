@@ -0,0 +1,115 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultHistoryRetention is how long status transitions are kept without history.retention
+const DefaultHistoryRetention = 7 * 24 * time.Hour
+
+// ObservedStatus is the Available condition of a cluster when labrat last observed it
+type ObservedStatus struct {
+	Available  string    `json:"available"`
+	ObservedAt time.Time `json:"observedAt"`
+}
+
+// StatusTransition is a change of the Available condition of a cluster observed by labrat
+type StatusTransition struct {
+	Hub     string    `json:"hub"`
+	Cluster string    `json:"cluster"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	At      time.Time `json:"at"`
+}
+
+// FlappingCluster is a cluster whose Available condition changed often, reported by Flapping
+type FlappingCluster struct {
+	Cluster string `json:"cluster"`
+	// Transitions is the number of changes of the Available condition in the window
+	Transitions    int       `json:"transitions"`
+	LastTransition time.Time `json:"lastTransition"`
+	// Available is the Available condition labrat observed last
+	Available string `json:"available"`
+}
+
+// ObserveStatus records the Available condition of cluster on hub observed at the given
+// time, and a transition if it differs from the one observed before. It reports whether a
+// transition was recorded; the first observation of a cluster records none.
+func (s *State) ObserveStatus(hub, cluster, available string, at time.Time) bool {
+	if s.Observed == nil {
+		s.Observed = make(map[string]map[string]ObservedStatus)
+	}
+	if s.Observed[hub] == nil {
+		s.Observed[hub] = make(map[string]ObservedStatus)
+	}
+	previous, seen := s.Observed[hub][cluster]
+	if seen && at.Before(previous.ObservedAt) {
+		return false
+	}
+	s.Observed[hub][cluster] = ObservedStatus{Available: available, ObservedAt: at}
+	if !seen || previous.Available == available {
+		return false
+	}
+	s.Transitions = append(s.Transitions, StatusTransition{Hub: hub, Cluster: cluster, From: previous.Available, To: available, At: at})
+	return true
+}
+
+// PruneHistory drops the transitions before the given time and the clusters not observed
+// since, e.g. because they were deleted
+func (s *State) PruneHistory(before time.Time) {
+	transitions := s.Transitions[:0]
+	for _, transition := range s.Transitions {
+		if !transition.At.Before(before) {
+			transitions = append(transitions, transition)
+		}
+	}
+	s.Transitions = transitions
+
+	for hub, clusters := range s.Observed {
+		for cluster, observed := range clusters {
+			if observed.ObservedAt.Before(before) {
+				delete(clusters, cluster)
+			}
+		}
+		if len(clusters) == 0 {
+			delete(s.Observed, hub)
+		}
+	}
+}
+
+// Flapping returns the clusters of hub whose Available condition changed more than
+// threshold times since the given time, the most changed first
+func (s *State) Flapping(hub string, since time.Time, threshold int) []FlappingCluster {
+	byCluster := make(map[string]*FlappingCluster)
+	for _, transition := range s.Transitions {
+		if transition.Hub != hub || transition.At.Before(since) {
+			continue
+		}
+		cluster := byCluster[transition.Cluster]
+		if cluster == nil {
+			cluster = &FlappingCluster{Cluster: transition.Cluster}
+			byCluster[transition.Cluster] = cluster
+		}
+		cluster.Transitions++
+		if transition.At.After(cluster.LastTransition) {
+			cluster.LastTransition = transition.At
+		}
+	}
+
+	flapping := make([]FlappingCluster, 0)
+	for name, cluster := range byCluster {
+		if cluster.Transitions <= threshold {
+			continue
+		}
+		cluster.Available = s.Observed[hub][name].Available
+		flapping = append(flapping, *cluster)
+	}
+	sort.Slice(flapping, func(i, j int) bool {
+		if flapping[i].Transitions != flapping[j].Transitions {
+			return flapping[i].Transitions > flapping[j].Transitions
+		}
+		return flapping[i].Cluster < flapping[j].Cluster
+	})
+	return flapping
+}
@@ -0,0 +1,82 @@
+//go:build test
+
+package state_test
+
+import (
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/state"
+)
+
+var _ = Describe("History", func() {
+	start := time.Date(2026, 2, 2, 8, 0, 0, 0, time.UTC)
+
+	// flap observes cluster alternating between True and False every minute from start
+	flap := func(s *state.State, hub, cluster string, observations int) {
+		for i := 0; i < observations; i++ {
+			available := "True"
+			if i%2 == 1 {
+				available = "False"
+			}
+			s.ObserveStatus(hub, cluster, available, start.Add(time.Duration(i)*time.Minute))
+		}
+	}
+
+	It("should record a transition when the observed status changes", func() {
+		s := &state.State{}
+		Expect(s.ObserveStatus("lab-east", "lab-1", "True", start)).To(BeFalse())
+		Expect(s.ObserveStatus("lab-east", "lab-1", "True", start.Add(time.Minute))).To(BeFalse())
+		Expect(s.ObserveStatus("lab-east", "lab-1", "Unknown", start.Add(2*time.Minute))).To(BeTrue())
+		Expect(s.ObserveStatus("lab-east", "lab-1", "True", start.Add(time.Minute))).To(BeFalse(), "older observations are ignored")
+
+		Expect(s.Transitions).To(Equal([]state.StatusTransition{
+			{Hub: "lab-east", Cluster: "lab-1", From: "True", To: "Unknown", At: start.Add(2 * time.Minute)},
+		}))
+		Expect(s.Observed["lab-east"]["lab-1"].Available).To(Equal("Unknown"))
+	})
+
+	It("should report the clusters with more transitions than the threshold", func() {
+		s := &state.State{}
+		flap(s, "lab-east", "lab-1", 6)
+		flap(s, "lab-east", "lab-2", 4)
+		flap(s, "lab-east", "lab-3", 8)
+		flap(s, "lab-west", "lab-1", 8)
+
+		flapping := s.Flapping("lab-east", start, 3)
+		Expect(flapping).To(Equal([]state.FlappingCluster{
+			{Cluster: "lab-3", Transitions: 7, LastTransition: start.Add(7 * time.Minute), Available: "False"},
+			{Cluster: "lab-1", Transitions: 5, LastTransition: start.Add(5 * time.Minute), Available: "False"},
+		}))
+		Expect(s.Flapping("lab-east", start.Add(4*time.Minute), 3)).To(ConsistOf(
+			state.FlappingCluster{Cluster: "lab-3", Transitions: 4, LastTransition: start.Add(7 * time.Minute), Available: "False"},
+		))
+	})
+
+	It("should prune old transitions and clusters no longer observed", func() {
+		s := &state.State{}
+		flap(s, "lab-east", "lab-1", 6)
+		s.ObserveStatus("lab-east", "lab-2", "True", start)
+
+		s.PruneHistory(start.Add(3 * time.Minute))
+		Expect(s.Transitions).To(HaveLen(3))
+		Expect(s.Observed["lab-east"]).To(HaveKey("lab-1"))
+		Expect(s.Observed["lab-east"]).NotTo(HaveKey("lab-2"))
+
+		s.PruneHistory(start.Add(time.Hour))
+		Expect(s.Transitions).To(BeEmpty())
+		Expect(s.Observed).To(BeEmpty())
+	})
+
+	It("should keep the history in the state file", func() {
+		store := state.NewStore(filepath.Join(GinkgoT().TempDir(), "state.json"))
+		Expect(store.Update(func(s *state.State) { flap(s, "lab-east", "lab-1", 5) })).To(Succeed())
+
+		s, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Transitions).To(HaveLen(4))
+		Expect(s.Flapping("lab-east", start, 3)).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,135 @@
+// Package state records the local files labrat has written, such as extracted spoke
+// kubeconfigs, so later commands can find them again, e.g. to refresh expired credentials.
+// With history.enabled it also records the status transitions of the clusters labrat
+// observes, for labrat hub flapping.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultFile is the state file in the labrat directory
+const DefaultFile = "~/.labrat/state.json"
+
+// SavedKubeconfig is a spoke kubeconfig written by labrat spoke kubeconfig
+type SavedKubeconfig struct {
+	Cluster string `json:"cluster"`
+	Path    string `json:"path"`
+	// Merged marks kubeconfigs merged into a shared kubeconfig as context labrat-<cluster>
+	Merged  bool      `json:"merged,omitempty"`
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// State is the content of the state file
+type State struct {
+	Kubeconfigs []SavedKubeconfig `json:"kubeconfigs,omitempty"`
+	// Observed is the status of each cluster observed last, by hub and cluster name
+	Observed map[string]map[string]ObservedStatus `json:"observed,omitempty"`
+	// Transitions are the recorded status transitions, oldest first
+	Transitions []StatusTransition `json:"transitions,omitempty"`
+}
+
+// RecordKubeconfig adds saved to the state, replacing the entry of the same cluster and path
+func (s *State) RecordKubeconfig(saved SavedKubeconfig) {
+	for i, existing := range s.Kubeconfigs {
+		if existing.Cluster == saved.Cluster && existing.Path == saved.Path {
+			s.Kubeconfigs[i] = saved
+			return
+		}
+	}
+	s.Kubeconfigs = append(s.Kubeconfigs, saved)
+}
+
+// KubeconfigsOf returns the kubeconfigs saved for cluster
+func (s *State) KubeconfigsOf(cluster string) []SavedKubeconfig {
+	var saved []SavedKubeconfig
+	for _, kubeconfig := range s.Kubeconfigs {
+		if kubeconfig.Cluster == cluster {
+			saved = append(saved, kubeconfig)
+		}
+	}
+	return saved
+}
+
+// Clusters returns the clusters with saved kubeconfigs in the order they were first saved
+func (s *State) Clusters() []string {
+	seen := make(map[string]bool)
+	var clusters []string
+	for _, kubeconfig := range s.Kubeconfigs {
+		if !seen[kubeconfig.Cluster] {
+			seen[kubeconfig.Cluster] = true
+			clusters = append(clusters, kubeconfig.Cluster)
+		}
+	}
+	return clusters
+}
+
+// Store reads and writes the state file. The file is readable by the current user only, as
+// it lists the clusters and the kubeconfigs holding their credentials.
+type Store struct {
+	path string
+}
+
+// NewStore creates a Store for the state file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load reads the state; a missing state file is an empty state
+func (s *Store) Load() (*State, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", s.path, err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", s.path, err)
+	}
+	return &state, nil
+}
+
+// Update loads the state, applies fn to it, and writes it back
+func (s *Store) Update(fn func(*State)) error {
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	fn(state)
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create state directory %s: %w", dir, err)
+	}
+
+	// Write to a temporary file first, so a failed write never leaves a partial state file
+	tmp, err := os.CreateTemp(dir, "state-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
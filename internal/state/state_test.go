@@ -0,0 +1,69 @@
+//go:build test
+
+package state_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/state"
+)
+
+var _ = Describe("Store", func() {
+	var (
+		path  string
+		store *state.Store
+	)
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "labrat", "state.json")
+		store = state.NewStore(path)
+	})
+
+	It("should load an empty state without a state file", func() {
+		s, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Kubeconfigs).To(BeEmpty())
+	})
+
+	It("should record saved kubeconfigs per cluster and path", func() {
+		savedAt := time.Date(2026, 1, 31, 14, 5, 9, 0, time.UTC)
+		Expect(store.Update(func(s *state.State) {
+			s.RecordKubeconfig(state.SavedKubeconfig{Cluster: "lab-1", Path: "/tmp/lab-1", SavedAt: savedAt})
+			s.RecordKubeconfig(state.SavedKubeconfig{Cluster: "lab-2", Path: "/home/me/.kube/config", Merged: true, SavedAt: savedAt})
+		})).To(Succeed())
+		Expect(store.Update(func(s *state.State) {
+			s.RecordKubeconfig(state.SavedKubeconfig{Cluster: "lab-1", Path: "/tmp/lab-1", SavedAt: savedAt.Add(time.Hour)})
+			s.RecordKubeconfig(state.SavedKubeconfig{Cluster: "lab-1", Path: "/home/me/.kube/config", Merged: true, SavedAt: savedAt})
+		})).To(Succeed())
+
+		s, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Clusters()).To(Equal([]string{"lab-1", "lab-2"}))
+		saved := s.KubeconfigsOf("lab-1")
+		Expect(saved).To(HaveLen(2))
+		Expect(saved[0].SavedAt).To(Equal(savedAt.Add(time.Hour)))
+		Expect(saved[1].Merged).To(BeTrue())
+	})
+
+	It("should keep the state file private", func() {
+		Expect(store.Update(func(s *state.State) {
+			s.RecordKubeconfig(state.SavedKubeconfig{Cluster: "lab-1", Path: "/tmp/lab-1"})
+		})).To(Succeed())
+
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("should reject a corrupt state file", func() {
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).To(Succeed())
+		Expect(os.WriteFile(path, []byte("{"), 0600)).To(Succeed())
+
+		_, err := store.Load()
+		Expect(err).To(MatchError(ContainSubstring("failed to parse state file")))
+	})
+})
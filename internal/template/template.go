@@ -0,0 +1,205 @@
+// Package template renders cluster templates: Go templates of the hub manifests that provision
+// a spoke cluster, stored as multi-document YAML files in ~/.labrat/templates. They let
+// partner requests that the built-in manifests do not cover, e.g. extra MachinePools or
+// custom networking, be provisioned with `labrat spoke create --template`.
+package template
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	gotemplate "text/template"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultDir is the directory cluster templates are loaded from
+const DefaultDir = "~/.labrat/templates"
+
+// extensions are the file extensions of cluster templates, in lookup order
+var extensions = []string{".yaml.tmpl", ".yaml", ".tmpl"}
+
+// Template is a parsed cluster template
+type Template struct {
+	// Name is the name the template was loaded by
+	Name string
+	tmpl *gotemplate.Template
+}
+
+// Parse parses text as the cluster template name. Variables missing from .Values are empty,
+// so templates can fall back to defaults with the default function.
+func Parse(name, text string) (*Template, error) {
+	tmpl, err := gotemplate.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	return &Template{Name: name, tmpl: tmpl}, nil
+}
+
+// Render executes the template with data and decodes the resulting YAML documents into
+// objects, skipping empty documents. Every object must have an apiVersion, kind, and name.
+func (t *Template) Render(data interface{}) ([]*unstructured.Unstructured, error) {
+	var out bytes.Buffer
+	if err := t.tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", t.Name, err)
+	}
+
+	var objects []*unstructured.Unstructured
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(&out))
+	for i := 1; ; i++ {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document %d of template %s: %w", i, t.Name, err)
+		}
+
+		jsonData, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse document %d of template %s: %w", i, t.Name, err)
+		}
+		if trimmed := bytes.TrimSpace(jsonData); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(jsonData); err != nil {
+			return nil, fmt.Errorf("document %d of template %s is not a Kubernetes object: %w", i, t.Name, err)
+		}
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("document %d of template %s (%s) has no metadata.name", i, t.Name, obj.GetKind())
+		}
+		objects = append(objects, obj)
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("template %s renders no objects", t.Name)
+	}
+	return objects, nil
+}
+
+// Loader loads cluster templates from a directory
+type Loader struct {
+	dir string
+}
+
+// NewLoader creates a Loader reading templates from dir
+func NewLoader(dir string) *Loader {
+	return &Loader{dir: dir}
+}
+
+// Load loads the template name from the directory of the loader, trying the extensions
+// .yaml.tmpl, .yaml, and .tmpl. A name containing a path separator is read as a file path.
+func (l *Loader) Load(name string) (*Template, error) {
+	if strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		return Parse(name, string(data))
+	}
+
+	for _, ext := range append([]string{""}, extensions...) {
+		data, err := os.ReadFile(filepath.Join(l.dir, name+ext))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+		}
+		return Parse(name, string(data))
+	}
+
+	available, _ := l.List()
+	if len(available) == 0 {
+		return nil, fmt.Errorf("template %s not found: %s has no templates", name, l.dir)
+	}
+	return nil, fmt.Errorf("template %s not found in %s, available: %s", name, l.dir, strings.Join(available, ", "))
+}
+
+// List returns the names of the templates in the directory of the loader, sorted
+func (l *Loader) List() ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template directory %s: %w", l.dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		for _, ext := range extensions {
+			if name, ok := strings.CutSuffix(entry.Name(), ext); ok {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ParseValues parses template variables given as key=value pairs, e.g. by --set. Later pairs
+// override earlier ones.
+func ParseValues(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid template variable %q: expected key=value", pair)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// funcs are the functions available to templates in addition to the Go template builtins
+var funcs = gotemplate.FuncMap{
+	// default returns value, or fallback if value is empty: {{ .Values.size | default "3" }}
+	"default": func(fallback, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+	// required fails the rendering with message if value is empty
+	"required": func(message string, value interface{}) (interface{}, error) {
+		if value == nil || value == "" {
+			return nil, errors.New(message)
+		}
+		return value, nil
+	},
+	"quote": func(value interface{}) string {
+		return fmt.Sprintf("%q", fmt.Sprint(value))
+	},
+	"lower":  strings.ToLower,
+	"upper":  strings.ToUpper,
+	"join":   func(sep string, values []string) string { return strings.Join(values, sep) },
+	"b64enc": func(value string) string { return base64.StdEncoding.EncodeToString([]byte(value)) },
+	// indent indents every line of value by spaces, for embedding multi-line values in block scalars
+	"indent": func(spaces int, value string) string {
+		padding := strings.Repeat(" ", spaces)
+		return padding + strings.ReplaceAll(strings.TrimSuffix(value, "\n"), "\n", "\n"+padding)
+	},
+	// toYaml renders value as YAML, e.g. to embed a list of zones
+	"toYaml": func(value interface{}) (string, error) {
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(data), "\n"), nil
+	},
+}
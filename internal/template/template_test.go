@@ -0,0 +1,163 @@
+//go:build test
+
+package template_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/template"
+)
+
+const workersTemplate = `apiVersion: hive.openshift.io/v1
+kind: MachinePool
+metadata:
+  name: {{ .Name }}-{{ .Values.pool | default "gpu" }}
+spec:
+  clusterDeploymentRef:
+    name: {{ .Name }}
+  replicas: {{ required "replicas is required" .Values.replicas }}
+  platform:
+    aws:
+      type: {{ .Values.type | quote }}
+      zones:
+{{ toYaml .Zones | indent 8 }}
+---
+# Documents that render to nothing are skipped
+{{- if .Values.configMap }}
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}-settings
+data:
+  owner: {{ .Values.configMap | upper }}
+{{- end }}
+`
+
+var _ = Describe("Template", func() {
+	type data struct {
+		Name   string
+		Zones  []string
+		Values map[string]string
+	}
+
+	It("should render the YAML documents into objects", func() {
+		tmpl, err := template.Parse("workers", workersTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		objects, err := tmpl.Render(data{
+			Name:   "partner-1234",
+			Zones:  []string{"us-east-2a", "us-east-2b"},
+			Values: map[string]string{"replicas": "2", "type": "g5.2xlarge", "configMap": "acme"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(objects).To(HaveLen(2))
+		Expect(objects[0].GetKind()).To(Equal("MachinePool"))
+		Expect(objects[0].GetName()).To(Equal("partner-1234-gpu"))
+		Expect(objects[0].Object["spec"]).To(HaveKeyWithValue("replicas", int64(2)))
+		Expect(objects[0].Object["spec"]).To(HaveKeyWithValue("platform", map[string]interface{}{
+			"aws": map[string]interface{}{
+				"type":  "g5.2xlarge",
+				"zones": []interface{}{"us-east-2a", "us-east-2b"},
+			},
+		}))
+		Expect(objects[1].GetName()).To(Equal("partner-1234-settings"))
+		Expect(objects[1].Object["data"]).To(Equal(map[string]interface{}{"owner": "ACME"}))
+	})
+
+	It("should skip documents that render to nothing", func() {
+		tmpl, err := template.Parse("workers", workersTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		objects, err := tmpl.Render(data{Name: "partner-1234", Values: map[string]string{"replicas": "2", "pool": "infra"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(HaveLen(1))
+		Expect(objects[0].GetName()).To(Equal("partner-1234-infra"))
+	})
+
+	It("should fail when a required variable is missing", func() {
+		tmpl, err := template.Parse("workers", workersTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = tmpl.Render(data{Name: "partner-1234", Values: map[string]string{}})
+		Expect(err).To(MatchError(ContainSubstring("replicas is required")))
+	})
+
+	It("should reject templates that do not render objects", func() {
+		tmpl, err := template.Parse("empty", "# nothing\n---\n")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tmpl.Render(nil)
+		Expect(err).To(MatchError("template empty renders no objects"))
+
+		tmpl, err = template.Parse("unnamed", "apiVersion: v1\nkind: ConfigMap\n")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tmpl.Render(nil)
+		Expect(err).To(MatchError(ContainSubstring("has no metadata.name")))
+	})
+
+	It("should report syntax errors", func() {
+		_, err := template.Parse("broken", "name: {{ .Name ")
+		Expect(err).To(MatchError(ContainSubstring("failed to parse template broken")))
+	})
+})
+
+var _ = Describe("Loader", func() {
+	var (
+		dir    string
+		loader *template.Loader
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		loader = template.NewLoader(dir)
+		Expect(os.WriteFile(filepath.Join(dir, "standard.yaml.tmpl"), []byte("kind: ConfigMap"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "gpu.yaml"), []byte("kind: ConfigMap"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "README.md"), []byte("notes"), 0600)).To(Succeed())
+	})
+
+	It("should load templates by name with any extension", func() {
+		tmpl, err := loader.Load("standard")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tmpl.Name).To(Equal("standard"))
+
+		_, err = loader.Load("gpu")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should load templates by path", func() {
+		tmpl, err := loader.Load(filepath.Join(dir, "gpu.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tmpl.Name).To(Equal(filepath.Join(dir, "gpu.yaml")))
+	})
+
+	It("should list the available templates when a template is missing", func() {
+		Expect(loader.List()).To(Equal([]string{"gpu", "standard"}))
+
+		_, err := loader.Load("missing")
+		Expect(err).To(MatchError(ContainSubstring("available: gpu, standard")))
+	})
+
+	It("should report a missing template directory", func() {
+		_, err := template.NewLoader(filepath.Join(dir, "missing")).Load("standard")
+		Expect(err).To(MatchError(ContainSubstring("has no templates")))
+	})
+})
+
+var _ = Describe("ParseValues", func() {
+	It("should parse key=value pairs with later pairs winning", func() {
+		values, err := template.ParseValues([]string{"type=g5.2xlarge", "labels=a=b", "type=g5.4xlarge", "empty="})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(Equal(map[string]string{"type": "g5.4xlarge", "labels": "a=b", "empty": ""}))
+	})
+
+	It("should reject pairs without a key", func() {
+		_, err := template.ParseValues([]string{"novalue"})
+		Expect(err).To(MatchError(ContainSubstring(`invalid template variable "novalue"`)))
+		_, err = template.ParseValues([]string{"=value"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,620 @@
+// Package tui implements labrat tui, a terminal UI showing a live, filterable table of the
+// clusters of a hub, with key bindings to hibernate and resume them, extract their admin
+// kubeconfigs, and drill down into their status.
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/audit"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+const (
+	// DefaultRefreshInterval is how often the cluster table is reloaded unless configured otherwise
+	DefaultRefreshInterval = 30 * time.Second
+	// statusEvents is the number of Kubernetes events shown in the cluster detail
+	statusEvents = 10
+)
+
+// Backend holds the hub clients behind the terminal UI
+type Backend struct {
+	// Clusters lists the managed clusters with their ClusterDeployment details
+	Clusters hub.CombinedClusterClient
+	// Status reads the details of a single cluster
+	Status spoke.StatusReader
+	// Kubeconfigs extracts the admin kubeconfigs of clusters
+	Kubeconfigs spoke.KubeconfigExtractor
+	// Power hibernates and resumes clusters
+	Power spoke.PowerManager
+	// Audit records the kubeconfig and power actions; nil disables the audit records
+	Audit *audit.Logger
+	// Hub is the name of the hub recorded in the audit records
+	Hub string
+}
+
+// Options configures the terminal UI
+type Options struct {
+	// RefreshInterval is how often the cluster table is reloaded, DefaultRefreshInterval if zero
+	RefreshInterval time.Duration
+	// KubeconfigPath is the kubeconfig extracted admin kubeconfigs are merged into
+	KubeconfigPath string
+	// KubeconfigSaved is called after a kubeconfig was merged, e.g. to track it for refresh
+	KubeconfigSaved func(cluster, path string) error
+	// WriteStatus renders the detail of a cluster
+	WriteStatus func(w io.Writer, status *spoke.ClusterStatus) error
+}
+
+// view is the screen the terminal UI shows
+type view int
+
+const (
+	viewTable view = iota
+	viewDetail
+)
+
+// Messages delivered to Model.Update by the commands of the terminal UI
+type (
+	clustersMsg struct {
+		clusters []hub.CombinedClusterInfo
+		err      error
+	}
+	detailMsg struct {
+		cluster string
+		text    string
+		err     error
+	}
+	actionMsg struct {
+		message string
+		err     error
+	}
+	tickMsg time.Time
+)
+
+// action is a change of a cluster waiting for confirmation
+type action struct {
+	cluster string
+	state   string
+}
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	helpStyle     = lipgloss.NewStyle().Faint(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// Model is the bubbletea model of the terminal UI
+type Model struct {
+	ctx     context.Context
+	backend Backend
+	opts    Options
+
+	clusters []hub.CombinedClusterInfo
+	visible  []hub.CombinedClusterInfo
+	cursor   int
+	offset   int
+	loaded   bool
+	updated  time.Time
+
+	filter    string
+	filtering bool
+	pending   *action
+
+	view         view
+	detail       string
+	detailName   string
+	detailOffset int
+
+	message string
+	err     error
+
+	width  int
+	height int
+}
+
+// New creates the model of the terminal UI; its commands run with ctx
+func New(ctx context.Context, backend Backend, opts Options) Model {
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = DefaultRefreshInterval
+	}
+	if opts.KubeconfigPath == "" {
+		opts.KubeconfigPath = spoke.DefaultKubeconfigPath()
+	}
+	return Model{ctx: ctx, backend: backend, opts: opts, width: 120, height: 30}
+}
+
+// Run shows the terminal UI until the user quits or ctx is canceled
+func Run(ctx context.Context, backend Backend, opts Options) error {
+	program := tea.NewProgram(New(ctx, backend, opts), tea.WithAltScreen(), tea.WithContext(ctx))
+	if _, err := program.Run(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to run the terminal UI: %w", err)
+	}
+	return nil
+}
+
+// Init loads the clusters and starts the periodic refresh
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.load(), m.tick())
+}
+
+// Update handles key presses and the results of commands
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.clampCursor()
+		return m, nil
+
+	case clustersMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.err = nil
+		m.loaded = true
+		m.updated = time.Now()
+		m.clusters = msg.clusters
+		m.applyFilter()
+		return m, nil
+
+	case detailMsg:
+		if msg.cluster != m.detailName {
+			return m, nil
+		}
+		m.detail = msg.text
+		if msg.err != nil {
+			m.detail = errorStyle.Render(msg.err.Error())
+		}
+		return m, nil
+
+	case actionMsg:
+		m.message, m.err = msg.message, msg.err
+		return m, m.load()
+
+	case tickMsg:
+		return m, tea.Batch(m.load(), m.tick())
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+// handleKey dispatches a key press to the screen or prompt that has the focus
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	switch {
+	case m.pending != nil:
+		return m.handleConfirmKey(msg)
+	case m.filtering:
+		return m.handleFilterKey(msg)
+	case m.view == viewDetail:
+		return m.handleDetailKey(msg)
+	}
+	return m.handleTableKey(msg)
+}
+
+// handleTableKey handles the key bindings of the cluster table
+func (m Model) handleTableKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	case "pgup":
+		m.moveCursor(-m.tableRows())
+	case "pgdown":
+		m.moveCursor(m.tableRows())
+	case "home", "g":
+		m.moveCursor(-len(m.visible))
+	case "end", "G":
+		m.moveCursor(len(m.visible))
+	case "/":
+		m.filtering = true
+	case "esc":
+		m.filter = ""
+		m.applyFilter()
+	case "R", "ctrl+r":
+		m.message = "Refreshing..."
+		return m, m.load()
+	case "enter", "d":
+		if cluster, ok := m.selected(); ok {
+			m.view = viewDetail
+			m.detailName = cluster.Name
+			m.detail = "Loading..."
+			m.detailOffset = 0
+			return m, m.loadDetail(cluster.Name)
+		}
+	case "h":
+		return m.confirm(spoke.PowerStateHibernating)
+	case "r":
+		return m.confirm(spoke.PowerStateRunning)
+	case "c":
+		if cluster, ok := m.selected(); ok {
+			m.message = "Extracting the kubeconfig of " + cluster.Name + "..."
+			return m, m.saveKubeconfig(cluster.Name)
+		}
+	}
+	return m, nil
+}
+
+// handleFilterKey edits the filter while it is being typed
+func (m Model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter = ""
+	case tea.KeyBackspace:
+		if m.filter != "" {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		m.filter += " "
+	case tea.KeyRunes:
+		m.filter += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	m.applyFilter()
+	return m, nil
+}
+
+// handleDetailKey handles the key bindings of the cluster detail
+func (m Model) handleDetailKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		m.view = viewTable
+		m.detailName = ""
+	case "up", "k":
+		m.detailOffset = max(m.detailOffset-1, 0)
+	case "down", "j":
+		m.detailOffset = min(m.detailOffset+1, max(strings.Count(m.detail, "\n")-1, 0))
+	case "R", "ctrl+r":
+		return m, m.loadDetail(m.detailName)
+	}
+	return m, nil
+}
+
+// handleConfirmKey runs the pending power change on y and drops it on any other key
+func (m Model) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pending := m.pending
+	m.pending = nil
+	if msg.String() != "y" {
+		m.message = "Canceled"
+		return m, nil
+	}
+	m.message = fmt.Sprintf("Requesting %s of %s...", pending.state, pending.cluster)
+	return m, m.setPowerState(pending.cluster, pending.state)
+}
+
+// confirm asks to confirm setting the power state of the selected cluster
+func (m Model) confirm(state string) (tea.Model, tea.Cmd) {
+	cluster, ok := m.selected()
+	if !ok {
+		return m, nil
+	}
+	if cluster.PowerState == state {
+		m.message = fmt.Sprintf("%s is already %s", cluster.Name, state)
+		return m, nil
+	}
+	m.pending = &action{cluster: cluster.Name, state: state}
+	return m, nil
+}
+
+// selected returns the cluster under the cursor
+func (m *Model) selected() (hub.CombinedClusterInfo, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.visible) {
+		return hub.CombinedClusterInfo{}, false
+	}
+	return m.visible[m.cursor], true
+}
+
+// moveCursor moves the cursor by delta rows, scrolling the table to keep it visible
+func (m *Model) moveCursor(delta int) {
+	m.cursor += delta
+	m.clampCursor()
+}
+
+// clampCursor keeps the cursor on a visible cluster and within the scrolled rows
+func (m *Model) clampCursor() {
+	m.cursor = max(min(m.cursor, len(m.visible)-1), 0)
+	rows := m.tableRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	m.offset = max(min(m.offset, len(m.visible)-rows), 0)
+}
+
+// applyFilter keeps the clusters matching the filter, keeping the cursor on the selected
+// cluster if it still matches
+func (m *Model) applyFilter() {
+	selected, _ := m.selected()
+	m.visible = m.visible[:0:0]
+	for _, cluster := range m.clusters {
+		if Matches(cluster, m.filter) {
+			m.visible = append(m.visible, cluster)
+		}
+	}
+	m.cursor = 0
+	for i, cluster := range m.visible {
+		if cluster.Name == selected.Name {
+			m.cursor = i
+			break
+		}
+	}
+	m.clampCursor()
+}
+
+// Matches reports whether every word of filter is contained, ignoring case, in the name,
+// status, power state, platform, region, version, or cluster set of a cluster
+func Matches(cluster hub.CombinedClusterInfo, filter string) bool {
+	fields := strings.ToLower(strings.Join([]string{cluster.Name, string(cluster.Status), cluster.PowerState,
+		cluster.Platform, cluster.Region, cluster.Version, cluster.ClusterSet}, " "))
+	for _, word := range strings.Fields(strings.ToLower(filter)) {
+		if !strings.Contains(fields, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// load lists the clusters of the hub
+func (m Model) load() tea.Cmd {
+	return func() tea.Msg {
+		clusters, err := m.backend.Clusters.ListCombined(m.ctx)
+		if err != nil {
+			return clustersMsg{err: fmt.Errorf("failed to list clusters: %w", err)}
+		}
+		return clustersMsg{clusters: clusters}
+	}
+}
+
+// tick schedules the next refresh of the cluster table
+func (m Model) tick() tea.Cmd {
+	return tea.Tick(m.opts.RefreshInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// loadDetail reads and renders the status of a cluster
+func (m Model) loadDetail(clusterName string) tea.Cmd {
+	return func() tea.Msg {
+		status, err := m.backend.Status.Read(m.ctx, clusterName, statusEvents)
+		if err != nil {
+			return detailMsg{cluster: clusterName, err: err}
+		}
+		var buf bytes.Buffer
+		if m.opts.WriteStatus != nil {
+			err = m.opts.WriteStatus(&buf, status)
+		}
+		return detailMsg{cluster: clusterName, text: buf.String(), err: err}
+	}
+}
+
+// setPowerState requests a power state for a cluster and records it in the audit log
+func (m Model) setPowerState(clusterName, state string) tea.Cmd {
+	return func() tea.Msg {
+		err := m.backend.Power.SetPowerState(m.ctx, clusterName, state)
+		verb := "resume"
+		if state == spoke.PowerStateHibernating {
+			verb = "hibernate"
+		}
+		m.audit("labrat spoke "+verb, clusterName, err)
+		if err != nil {
+			return actionMsg{err: err}
+		}
+		return actionMsg{message: fmt.Sprintf("✓ %s requested for %s", state, clusterName)}
+	}
+}
+
+// saveKubeconfig merges the admin kubeconfig of a cluster into the kubeconfig of kubectl and
+// records it in the audit log
+func (m Model) saveKubeconfig(clusterName string) tea.Cmd {
+	return func() tea.Msg {
+		err := m.mergeKubeconfig(clusterName)
+		m.audit("labrat spoke kubeconfig", clusterName, err)
+		if err != nil {
+			return actionMsg{err: err}
+		}
+		return actionMsg{message: fmt.Sprintf("✓ Kubeconfig merged into %s as context %s", m.opts.KubeconfigPath, spoke.ContextName(clusterName))}
+	}
+}
+
+// mergeKubeconfig extracts the admin kubeconfig of a cluster and merges it as its labrat context
+func (m Model) mergeKubeconfig(clusterName string) error {
+	kubeconfig, err := m.backend.Kubeconfigs.Extract(m.ctx, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to extract kubeconfig: %w", err)
+	}
+	if err := spoke.MergeKubeconfig(kubeconfig, clusterName, m.opts.KubeconfigPath, spoke.MergeOptions{}); err != nil {
+		return err
+	}
+	if m.opts.KubeconfigSaved != nil {
+		return m.opts.KubeconfigSaved(clusterName, m.opts.KubeconfigPath)
+	}
+	return nil
+}
+
+// audit records an action on a cluster with its result. A record that cannot be written is
+// dropped, as the terminal UI has no place to report it.
+func (m Model) audit(actionName, clusterName string, err error) {
+	if m.backend.Audit == nil {
+		return
+	}
+	record := audit.Record{
+		Source:  audit.SourceTUI,
+		Action:  actionName,
+		Targets: []string{clusterName},
+		Hub:     m.backend.Hub,
+		Result:  audit.ResultSuccess,
+	}
+	if err != nil {
+		record.Result = audit.ResultFailure
+		record.Error = err.Error()
+	}
+	_ = m.backend.Audit.Log(m.ctx, record)
+}
+
+// View renders the screen
+func (m Model) View() string {
+	if m.view == viewDetail {
+		return m.viewDetail()
+	}
+	return m.viewTable()
+}
+
+// tableRows returns how many clusters fit on the screen below the title and header and
+// above the status and help lines
+func (m Model) tableRows() int {
+	return max(m.height-5, 1)
+}
+
+// tableColumns are the headers of the cluster table
+var tableColumns = []string{"NAME", "STATUS", "POWER", "PLATFORM", "REGION", "VERSION", "NODES", "CLUSTERSET"}
+
+// viewTable renders the cluster table
+func (m Model) viewTable() string {
+	var b strings.Builder
+	title := fmt.Sprintf("labrat — %d/%d clusters", len(m.visible), len(m.clusters))
+	if m.backend.Hub != "" {
+		title = fmt.Sprintf("labrat — hub %s — %d/%d clusters", m.backend.Hub, len(m.visible), len(m.clusters))
+	}
+	if !m.updated.IsZero() {
+		title += " — updated " + m.updated.Format(time.TimeOnly)
+	}
+	b.WriteString(headerStyle.Render(title) + "\n")
+
+	rows := make([][]string, 0, len(m.visible))
+	for _, cluster := range m.visible {
+		rows = append(rows, []string{cluster.Name, string(cluster.Status), valueOrNA(cluster.PowerState),
+			valueOrNA(cluster.Platform), valueOrNA(cluster.Region), valueOrNA(cluster.Version),
+			strconv.Itoa(cluster.NodeCount), valueOrNA(cluster.ClusterSet)})
+	}
+	widths := columnWidths(tableColumns, rows)
+	b.WriteString(headerStyle.Render(m.fit(formatRow(tableColumns, widths))) + "\n")
+
+	end := min(m.offset+m.tableRows(), len(rows))
+	for i := m.offset; i < end; i++ {
+		line := m.fit(formatRow(rows[i], widths))
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	switch {
+	case !m.loaded && m.err == nil:
+		b.WriteString("Loading clusters...\n")
+	case m.loaded && len(m.visible) == 0:
+		b.WriteString("No clusters match\n")
+	}
+	for i := end - m.offset; i < m.tableRows(); i++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.statusLine() + "\n")
+	b.WriteString(helpStyle.Render(m.fit("↑/↓ move  / filter  enter detail  h hibernate  r resume  c kubeconfig  R refresh  q quit")))
+	return b.String()
+}
+
+// statusLine renders the prompt, filter, or the result of the last action
+func (m Model) statusLine() string {
+	switch {
+	case m.pending != nil:
+		return fmt.Sprintf("Set %s to %s? (y/N)", m.pending.cluster, m.pending.state)
+	case m.filtering:
+		return "/" + m.filter + "█"
+	case m.err != nil:
+		return errorStyle.Render(m.fit("⚠️  " + m.err.Error()))
+	case m.filter != "":
+		return m.fit(fmt.Sprintf("Filter: %s (esc to clear)  %s", m.filter, m.message))
+	}
+	return m.fit(m.message)
+}
+
+// viewDetail renders the status of the selected cluster
+func (m Model) viewDetail() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("labrat — "+m.detailName) + "\n\n")
+	lines := strings.Split(strings.TrimRight(m.detail, "\n"), "\n")
+	rows := max(m.height-4, 1)
+	start := min(m.detailOffset, max(len(lines)-1, 0))
+	end := min(start+rows, len(lines))
+	for _, line := range lines[start:end] {
+		b.WriteString(m.fit(line) + "\n")
+	}
+	for i := end - start; i < rows; i++ {
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render(m.fit("↑/↓ scroll  R refresh  esc back")))
+	return b.String()
+}
+
+// fit truncates a line to the width of the terminal
+func (m Model) fit(line string) string {
+	if m.width <= 0 || lipgloss.Width(line) <= m.width {
+		return line
+	}
+	runes := []rune(line)
+	for len(runes) > 0 && lipgloss.Width(string(runes)) > m.width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+// columnWidths returns the width of the widest value of each column
+func columnWidths(header []string, rows [][]string) []int {
+	widths := make([]int, len(header))
+	for i, value := range header {
+		widths[i] = lipgloss.Width(value)
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			widths[i] = max(widths[i], lipgloss.Width(value))
+		}
+	}
+	return widths
+}
+
+// formatRow pads the values of a row to the column widths, three spaces apart like the
+// tables of the CLI
+func formatRow(values []string, widths []int) string {
+	var b strings.Builder
+	for i, value := range values {
+		if i > 0 {
+			b.WriteString("   ")
+		}
+		b.WriteString(value)
+		if i < len(values)-1 {
+			b.WriteString(strings.Repeat(" ", widths[i]-lipgloss.Width(value)))
+		}
+	}
+	return b.String()
+}
+
+// valueOrNA returns value, or N/A if it is empty
+func valueOrNA(value string) string {
+	if value == "" {
+		return "N/A"
+	}
+	return value
+}
@@ -0,0 +1,229 @@
+//go:build test
+
+package tui_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/audit"
+	"github.com/redhat-openshift-partner-labs/labrat/internal/tui"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+// fakeBackend implements the clients of tui.Backend with in-memory clusters
+type fakeBackend struct {
+	clusters    []hub.CombinedClusterInfo
+	powerStates map[string]string
+}
+
+func (f *fakeBackend) ListCombined(_ context.Context) ([]hub.CombinedClusterInfo, error) {
+	return f.clusters, nil
+}
+
+func (f *fakeBackend) Read(_ context.Context, clusterName string, _ int) (*spoke.ClusterStatus, error) {
+	return &spoke.ClusterStatus{Name: clusterName, Platform: "aws"}, nil
+}
+
+func (f *fakeBackend) Extract(_ context.Context, clusterName string) ([]byte, error) {
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://api.%[1]s.example.com:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: admin
+  context:
+    cluster: %[1]s
+    user: admin
+current-context: admin
+`, clusterName)), nil
+}
+
+func (f *fakeBackend) ExtractToFile(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (f *fakeBackend) SetPowerState(_ context.Context, clusterName, state string) error {
+	f.powerStates[clusterName] = state
+	return nil
+}
+
+// recordSink keeps the audit records in memory
+type recordSink struct {
+	records []audit.Record
+}
+
+func (s *recordSink) Write(_ context.Context, record audit.Record) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+var _ = Describe("Model", func() {
+	var (
+		backend *fakeBackend
+		sink    *recordSink
+		model   tea.Model
+		dir     string
+	)
+
+	// update delivers msg to the model and then the messages of the commands it returns.
+	// Commands that do not return at once, such as the periodic refresh, are dropped.
+	var update func(msg tea.Msg)
+	var run func(cmd tea.Cmd)
+	update = func(msg tea.Msg) {
+		var cmd tea.Cmd
+		model, cmd = model.Update(msg)
+		run(cmd)
+	}
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		result := make(chan tea.Msg, 1)
+		go func() { result <- cmd() }()
+		select {
+		case msg := <-result:
+			switch msg := msg.(type) {
+			case tea.BatchMsg:
+				for _, cmd := range msg {
+					run(cmd)
+				}
+			case tea.QuitMsg, nil:
+			default:
+				update(msg)
+			}
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	press := func(keys ...string) {
+		for _, key := range keys {
+			switch key {
+			case "enter":
+				update(tea.KeyMsg{Type: tea.KeyEnter})
+			case "esc":
+				update(tea.KeyMsg{Type: tea.KeyEsc})
+			case "down":
+				update(tea.KeyMsg{Type: tea.KeyDown})
+			default:
+				update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		backend = &fakeBackend{
+			clusters: []hub.CombinedClusterInfo{
+				{Name: "acme-dev", Status: hub.StatusReady, PowerState: "Running", Platform: "AWS", Region: "us-east-1", Version: "4.16.2"},
+				{Name: "acme-prod", Status: hub.StatusReady, PowerState: "Hibernating", Platform: "AWS", Region: "us-east-1", Version: "4.16.2"},
+				{Name: "globex", Status: hub.StatusNotReady, PowerState: "Running", Platform: "GCP", Region: "us-central1", Version: "4.15.30"},
+			},
+			powerStates: map[string]string{},
+		}
+		sink = &recordSink{}
+		dir = GinkgoT().TempDir()
+		model = tui.New(context.Background(), tui.Backend{
+			Clusters:    backend,
+			Status:      backend,
+			Kubeconfigs: backend,
+			Power:       backend,
+			Audit:       audit.NewLogger(sink),
+			Hub:         "lab",
+		}, tui.Options{
+			KubeconfigPath: filepath.Join(dir, "config"),
+			WriteStatus: func(w io.Writer, status *spoke.ClusterStatus) error {
+				_, err := fmt.Fprintf(w, "Cluster: %s\nPlatform: %s\n", status.Name, status.Platform)
+				return err
+			},
+		})
+		update(tea.WindowSizeMsg{Width: 120, Height: 20})
+		run(model.Init())
+	})
+
+	It("should show the clusters of the hub", func() {
+		view := model.View()
+		Expect(view).To(ContainSubstring("hub lab — 3/3 clusters"))
+		Expect(view).To(ContainSubstring("acme-dev"))
+		Expect(view).To(ContainSubstring("globex"))
+		Expect(view).To(ContainSubstring("4.15.30"))
+	})
+
+	It("should filter the clusters while the filter is typed", func() {
+		press("/", "a", "c", "m", "e", " ", "h", "i", "b")
+		Expect(model.View()).To(ContainSubstring("1/3 clusters"))
+		Expect(model.View()).To(ContainSubstring("acme-prod"))
+		Expect(model.View()).NotTo(ContainSubstring("globex"))
+
+		press("enter", "esc")
+		Expect(model.View()).To(ContainSubstring("3/3 clusters"))
+	})
+
+	It("should hibernate the selected cluster after confirmation", func() {
+		press("h", "n")
+		Expect(backend.powerStates).To(BeEmpty())
+		Expect(model.View()).To(ContainSubstring("Canceled"))
+
+		press("h")
+		Expect(model.View()).To(ContainSubstring("Set acme-dev to Hibernating? (y/N)"))
+		press("y")
+		Expect(backend.powerStates).To(Equal(map[string]string{"acme-dev": spoke.PowerStateHibernating}))
+		Expect(sink.records).To(HaveLen(1))
+		Expect(sink.records[0].Source).To(Equal(audit.SourceTUI))
+		Expect(sink.records[0].Action).To(Equal("labrat spoke hibernate"))
+		Expect(sink.records[0].Targets).To(Equal([]string{"acme-dev"}))
+		Expect(sink.records[0].Hub).To(Equal("lab"))
+	})
+
+	It("should not resume a running cluster", func() {
+		press("r")
+		Expect(model.View()).To(ContainSubstring("acme-dev is already Running"))
+
+		press("down", "r", "y")
+		Expect(backend.powerStates).To(Equal(map[string]string{"acme-prod": spoke.PowerStateRunning}))
+	})
+
+	It("should merge the kubeconfig of the selected cluster", func() {
+		press("c")
+		Expect(model.View()).To(ContainSubstring("as context labrat-acme-dev"))
+
+		kubeconfig, err := os.ReadFile(filepath.Join(dir, "config"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(kubeconfig)).To(ContainSubstring("labrat-acme-dev"))
+		Expect(sink.records).To(HaveLen(1))
+		Expect(sink.records[0].Action).To(Equal("labrat spoke kubeconfig"))
+	})
+
+	It("should drill down into the status of the selected cluster", func() {
+		press("down", "down", "enter")
+		Expect(model.View()).To(ContainSubstring("Cluster: globex"))
+		Expect(model.View()).To(ContainSubstring("Platform: aws"))
+
+		press("esc")
+		Expect(model.View()).To(ContainSubstring("3/3 clusters"))
+	})
+})
+
+var _ = Describe("Matches", func() {
+	cluster := hub.CombinedClusterInfo{Name: "acme-dev", Status: hub.StatusReady, Platform: "AWS", Region: "eu-west-1", ClusterSet: "canary"}
+
+	It("should match every word against the cluster fields ignoring case", func() {
+		Expect(tui.Matches(cluster, "")).To(BeTrue())
+		Expect(tui.Matches(cluster, "ACME aws")).To(BeTrue())
+		Expect(tui.Matches(cluster, "canary eu-west")).To(BeTrue())
+		Expect(tui.Matches(cluster, "acme gcp")).To(BeFalse())
+	})
+})
@@ -0,0 +1,209 @@
+// Package acs summarizes workload vulnerabilities reported by the Red Hat Advanced Cluster Security Central API
+package acs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrUnauthorized is returned when Central rejects the API token
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Severities are the ACS vulnerability severities, most severe first
+var Severities = []Severity{SeverityCritical, SeverityImportant, SeverityModerate, SeverityLow}
+
+// Severity is an ACS vulnerability severity
+type Severity string
+
+const (
+	// SeverityCritical is the severity of critical vulnerabilities
+	SeverityCritical Severity = "CRITICAL_VULNERABILITY_SEVERITY"
+	// SeverityImportant is the severity of important vulnerabilities
+	SeverityImportant Severity = "IMPORTANT_VULNERABILITY_SEVERITY"
+	// SeverityModerate is the severity of moderate vulnerabilities
+	SeverityModerate Severity = "MODERATE_VULNERABILITY_SEVERITY"
+	// SeverityLow is the severity of low vulnerabilities
+	SeverityLow Severity = "LOW_VULNERABILITY_SEVERITY"
+)
+
+// String returns the display name of the severity, e.g. Critical
+func (s Severity) String() string {
+	name := strings.TrimSuffix(string(s), "_VULNERABILITY_SEVERITY")
+	if name == "" {
+		return "Unknown"
+	}
+	return name[:1] + strings.ToLower(name[1:])
+}
+
+// SeverityCount counts the distinct CVEs of one severity in a set of workloads
+type SeverityCount struct {
+	Severity string `json:"severity"`
+	// CVEs is the number of distinct CVEs
+	CVEs int `json:"cves"`
+	// Fixable is the number of those CVEs with a fixed version available
+	Fixable int `json:"fixable"`
+	// Deployments is the number of deployments affected by at least one of the CVEs
+	Deployments int `json:"deployments"`
+}
+
+// VulnSummary summarizes the vulnerabilities of the workloads of a cluster
+type VulnSummary struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace,omitempty"`
+	// Deployments and Images count the scanned workloads
+	Deployments int `json:"deployments"`
+	Images      int `json:"images"`
+	// Severities holds one count per severity, most severe first
+	Severities []SeverityCount `json:"severities"`
+}
+
+// Client reads vulnerability data from ACS Central
+type Client interface {
+	// WorkloadVulnerabilities summarizes the vulnerabilities of the deployments of a cluster,
+	// optionally limited to a namespace
+	WorkloadVulnerabilities(ctx context.Context, cluster, namespace string) (*VulnSummary, error)
+}
+
+type client struct {
+	endpoint   string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Client for the Central at endpoint that authenticates with an API token.
+// If httpClient is nil a client with a 5 minute timeout is used, as exports of large clusters are slow.
+func NewClient(endpoint, token string, httpClient *http.Client) Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 5 * time.Minute}
+	}
+	return &client{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// workloadExport is one message of the workload vulnerability export stream
+type workloadExport struct {
+	Result *struct {
+		Deployment struct {
+			ID string `json:"id"`
+		} `json:"deployment"`
+		Images []struct {
+			ID   string `json:"id"`
+			Scan *struct {
+				Components []struct {
+					Vulns []struct {
+						CVE      string   `json:"cve"`
+						Severity Severity `json:"severity"`
+						FixedBy  string   `json:"fixedBy"`
+					} `json:"vulns"`
+				} `json:"components"`
+			} `json:"scan"`
+		} `json:"images"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// WorkloadVulnerabilities streams /v1/export/vuln-mgmt/workloads for the cluster and counts the
+// distinct CVEs of each severity across its deployments
+func (c *client) WorkloadVulnerabilities(ctx context.Context, cluster, namespace string) (*VulnSummary, error) {
+	query := fmt.Sprintf("Cluster:%q", cluster)
+	if namespace != "" {
+		query += fmt.Sprintf("+Namespace:%q", namespace)
+	}
+	exportURL := c.endpoint + "/v1/export/vuln-mgmt/workloads?" + url.Values{"query": {query}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, exportURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", exportURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("%w: Central rejected the API token", ErrUnauthorized)
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("GET %s returned %s: %s", exportURL, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	type cveInfo struct {
+		severity Severity
+		fixable  bool
+	}
+	cves := make(map[string]cveInfo)
+	affected := make(map[Severity]map[string]bool)
+	images := make(map[string]bool)
+	summary := &VulnSummary{Cluster: cluster, Namespace: namespace}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg workloadExport
+		if err := decoder.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode workload export: %w", err)
+		}
+		if msg.Error != nil {
+			return nil, fmt.Errorf("workload export failed: %s", msg.Error.Message)
+		}
+		if msg.Result == nil {
+			continue
+		}
+
+		summary.Deployments++
+		for _, image := range msg.Result.Images {
+			images[image.ID] = true
+			if image.Scan == nil {
+				continue
+			}
+			for _, component := range image.Scan.Components {
+				for _, vuln := range component.Vulns {
+					info := cves[vuln.CVE]
+					info.severity = vuln.Severity
+					info.fixable = info.fixable || vuln.FixedBy != ""
+					cves[vuln.CVE] = info
+
+					if affected[vuln.Severity] == nil {
+						affected[vuln.Severity] = make(map[string]bool)
+					}
+					affected[vuln.Severity][msg.Result.Deployment.ID] = true
+				}
+			}
+		}
+	}
+	summary.Images = len(images)
+
+	for _, severity := range Severities {
+		count := SeverityCount{Severity: severity.String(), Deployments: len(affected[severity])}
+		for _, info := range cves {
+			if info.severity != severity {
+				continue
+			}
+			count.CVEs++
+			if info.fixable {
+				count.Fixable++
+			}
+		}
+		summary.Severities = append(summary.Severities, count)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,110 @@
+//go:build test
+
+package acs_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/acs"
+)
+
+// workload builds one message of the workload export stream with a single image
+func workload(deployment, image string, vulns ...string) string {
+	return fmt.Sprintf(`{"result":{"deployment":{"id":%q},"images":[{"id":%q,"scan":{"components":[{"vulns":[%s]}]}}]}}`,
+		deployment, image, strings.Join(vulns, ","))
+}
+
+// vuln builds a vulnerability of the workload export stream
+func vuln(cve string, severity acs.Severity, fixedBy string) string {
+	return fmt.Sprintf(`{"cve":%q,"severity":%q,"fixedBy":%q}`, cve, string(severity), fixedBy)
+}
+
+var _ = Describe("Severity", func() {
+	It("should have a display name", func() {
+		Expect(acs.SeverityCritical.String()).To(Equal("Critical"))
+		Expect(acs.SeverityImportant.String()).To(Equal("Important"))
+	})
+})
+
+var _ = Describe("Client", func() {
+	var (
+		server   *httptest.Server
+		query    string
+		auth     string
+		status   int
+		messages []string
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		messages = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v1/export/vuln-mgmt/workloads"))
+			query = r.URL.Query().Get("query")
+			auth = r.Header.Get("Authorization")
+			w.WriteHeader(status)
+			for _, m := range messages {
+				fmt.Fprintln(w, m)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should count distinct CVEs by severity across deployments", func() {
+		messages = []string{
+			workload("d1", "img-a",
+				vuln("CVE-2024-0001", acs.SeverityCritical, "1.2.3"),
+				vuln("CVE-2024-0002", acs.SeverityImportant, ""),
+			),
+			workload("d2", "img-a",
+				vuln("CVE-2024-0001", acs.SeverityCritical, "1.2.3"),
+				vuln("CVE-2024-0002", acs.SeverityImportant, ""),
+			),
+			workload("d3", "img-b",
+				vuln("CVE-2024-0003", acs.SeverityImportant, "2.0"),
+				vuln("CVE-2024-0004", acs.SeverityLow, ""),
+			),
+		}
+
+		summary, err := acs.NewClient(server.URL+"/", "token", nil).WorkloadVulnerabilities(context.Background(), "lab-1", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(Equal(`Cluster:"lab-1"`))
+		Expect(auth).To(Equal("Bearer token"))
+
+		Expect(summary.Deployments).To(Equal(3))
+		Expect(summary.Images).To(Equal(2))
+		Expect(summary.Severities).To(Equal([]acs.SeverityCount{
+			{Severity: "Critical", CVEs: 1, Fixable: 1, Deployments: 2},
+			{Severity: "Important", CVEs: 2, Fixable: 1, Deployments: 3},
+			{Severity: "Moderate"},
+			{Severity: "Low", CVEs: 1, Deployments: 1},
+		}))
+	})
+
+	It("should limit the export to a namespace", func() {
+		_, err := acs.NewClient(server.URL, "token", nil).WorkloadVulnerabilities(context.Background(), "lab-1", "partner-app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(Equal(`Cluster:"lab-1"+Namespace:"partner-app"`))
+	})
+
+	It("should report rejected tokens", func() {
+		status = http.StatusUnauthorized
+		_, err := acs.NewClient(server.URL, "bad", nil).WorkloadVulnerabilities(context.Background(), "lab-1", "")
+		Expect(err).To(MatchError(acs.ErrUnauthorized))
+	})
+
+	It("should report errors in the export stream", func() {
+		messages = []string{`{"error":{"message":"export timed out"}}`}
+		_, err := acs.NewClient(server.URL, "token", nil).WorkloadVulnerabilities(context.Background(), "lab-1", "")
+		Expect(err).To(MatchError(ContainSubstring("export timed out")))
+	})
+})
@@ -0,0 +1,87 @@
+// Package check provides a common result model for health and validation checks
+// (hub status, bootstrap validation, spoke health) and renders reports as tables,
+// JSON, or JUnit XML so results can be consumed by humans and CI dashboards alike.
+package check
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status is the outcome of a single check
+type Status string
+
+const (
+	// StatusPass indicates the check succeeded
+	StatusPass Status = "PASS"
+	// StatusWarn indicates the check found a non-fatal problem
+	StatusWarn Status = "WARN"
+	// StatusFail indicates the check failed
+	StatusFail Status = "FAIL"
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	// Name identifies the check
+	Name string `json:"name"`
+	// Status is the outcome of the check
+	Status Status `json:"status"`
+	// Message explains the outcome
+	Message string `json:"message,omitempty"`
+	// Duration is how long the check took
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is a named collection of check results
+type Report struct {
+	// Name identifies the report, used as the JUnit test suite name
+	Name string `json:"name"`
+	// Results holds the individual check results in execution order
+	Results []Result `json:"results"`
+}
+
+// Func performs a check and returns its status and a message
+type Func func() (Status, string)
+
+// Run executes fn, times it, and appends the result to the report
+func (r *Report) Run(name string, fn Func) Result {
+	start := time.Now()
+	status, message := fn()
+	result := Result{
+		Name:     name,
+		Status:   status,
+		Message:  message,
+		Duration: time.Since(start),
+	}
+	r.Results = append(r.Results, result)
+	return result
+}
+
+// Add appends a pre-computed result to the report
+func (r *Report) Add(result Result) {
+	r.Results = append(r.Results, result)
+}
+
+// Count returns the number of results with the given status
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed reports whether any check in the report failed
+func (r *Report) Failed() bool {
+	return r.Count(StatusFail) > 0
+}
+
+// Err returns an error if any check in the report failed
+func (r *Report) Err() error {
+	if failed := r.Count(StatusFail); failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(r.Results))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build test
+
+package check_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+var _ = Describe("Report", func() {
+	var report check.Report
+
+	BeforeEach(func() {
+		report = check.Report{Name: "test"}
+	})
+
+	It("should record results of run checks in order", func() {
+		report.Run("first", func() (check.Status, string) { return check.StatusPass, "ok" })
+		report.Run("second", func() (check.Status, string) { return check.StatusWarn, "hmm" })
+
+		Expect(report.Results).To(HaveLen(2))
+		Expect(report.Results[0].Name).To(Equal("first"))
+		Expect(report.Results[1].Status).To(Equal(check.StatusWarn))
+		Expect(report.Results[1].Message).To(Equal("hmm"))
+	})
+
+	It("should not fail when only warnings are present", func() {
+		report.Add(check.Result{Name: "warn", Status: check.StatusWarn})
+		Expect(report.Failed()).To(BeFalse())
+		Expect(report.Err()).NotTo(HaveOccurred())
+	})
+
+	It("should fail when any check failed", func() {
+		report.Add(check.Result{Name: "ok", Status: check.StatusPass})
+		report.Add(check.Result{Name: "bad", Status: check.StatusFail})
+
+		Expect(report.Failed()).To(BeTrue())
+		Expect(report.Count(check.StatusFail)).To(Equal(1))
+		Expect(report.Err()).To(MatchError("1 of 2 checks failed"))
+	})
+})
@@ -0,0 +1,158 @@
+package check
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// OutputFormat represents the output format of a report
+type OutputFormat string
+
+const (
+	// OutputFormatTable represents table output format
+	OutputFormatTable OutputFormat = "table"
+	// OutputFormatJSON represents JSON output format
+	OutputFormatJSON OutputFormat = "json"
+	// OutputFormatJUnit represents JUnit XML output format
+	OutputFormatJUnit OutputFormat = "junit"
+)
+
+// Writer handles formatting and writing check reports
+type Writer struct {
+	format OutputFormat
+	writer io.Writer
+}
+
+// NewWriter creates a new Writer with the specified format and writer
+func NewWriter(format OutputFormat, writer io.Writer) *Writer {
+	return &Writer{
+		format: format,
+		writer: writer,
+	}
+}
+
+// Write formats and writes the report according to the configured format
+func (w *Writer) Write(report Report) error {
+	switch w.format {
+	case OutputFormatTable:
+		return w.writeTable(report)
+	case OutputFormatJSON:
+		return w.writeJSON(report)
+	case OutputFormatJUnit:
+		return w.writeJUnit(report)
+	default:
+		return fmt.Errorf("unsupported output format: %s", w.format)
+	}
+}
+
+// writeTable writes the report as an aligned table followed by a totals line
+func (w *Writer) writeTable(report Report) error {
+	tw := tabwriter.NewWriter(w.writer, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintf(tw, "CHECK\tSTATUS\tMESSAGE\n")
+	for _, result := range report.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Name, result.Status, result.Message)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w.writer, "\n%d passed, %d warnings, %d failed\n",
+		report.Count(StatusPass), report.Count(StatusWarn), report.Count(StatusFail))
+	return err
+}
+
+// writeJSON writes the report in JSON format
+func (w *Writer) writeJSON(report Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report to JSON: %w", err)
+	}
+
+	if _, err := w.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write JSON output: %w", err)
+	}
+
+	return nil
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes the report as a JUnit XML document.
+// Failed checks become test failures; warnings pass but carry their message in system-out,
+// since JUnit has no notion of a warning.
+func (w *Writer) writeJUnit(report Report) error {
+	suite := junitTestSuite{
+		Name:     report.Name,
+		Tests:    len(report.Results),
+		Failures: report.Count(StatusFail),
+	}
+
+	var total float64
+	for _, result := range report.Results {
+		seconds := result.Duration.Seconds()
+		total += seconds
+
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: report.Name,
+			Time:      fmt.Sprintf("%.3f", seconds),
+		}
+		switch result.Status {
+		case StatusFail:
+			testCase.Failure = &junitFailure{
+				Message: result.Message,
+				Type:    string(StatusFail),
+				Text:    result.Message,
+			}
+		case StatusWarn:
+			testCase.SystemOut = fmt.Sprintf("%s: %s", StatusWarn, result.Message)
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total)
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report to JUnit XML: %w", err)
+	}
+
+	if _, err := io.WriteString(w.writer, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit output: %w", err)
+	}
+	if _, err := w.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write JUnit output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+//go:build test
+
+package check_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		buf    *bytes.Buffer
+		report check.Report
+	)
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		report = check.Report{
+			Name: "labrat.test",
+			Results: []check.Result{
+				{Name: "api", Status: check.StatusPass, Message: "Kubernetes v1.30.0", Duration: 250 * time.Millisecond},
+				{Name: "hive", Status: check.StatusWarn, Message: "not installed"},
+				{Name: "mch", Status: check.StatusFail, Message: "phase Pending", Duration: time.Second},
+			},
+		}
+	})
+
+	Context("with table format", func() {
+		It("should write a row per check and totals", func() {
+			Expect(check.NewWriter(check.OutputFormatTable, buf).Write(report)).To(Succeed())
+
+			output := buf.String()
+			Expect(output).To(ContainSubstring("CHECK"))
+			Expect(output).To(MatchRegexp(`api\s+PASS\s+Kubernetes v1.30.0`))
+			Expect(output).To(MatchRegexp(`mch\s+FAIL\s+phase Pending`))
+			Expect(output).To(ContainSubstring("1 passed, 1 warnings, 1 failed"))
+		})
+	})
+
+	Context("with JSON format", func() {
+		It("should write valid JSON", func() {
+			Expect(check.NewWriter(check.OutputFormatJSON, buf).Write(report)).To(Succeed())
+
+			var decoded check.Report
+			Expect(json.Unmarshal(buf.Bytes(), &decoded)).To(Succeed())
+			Expect(decoded.Name).To(Equal("labrat.test"))
+			Expect(decoded.Results).To(HaveLen(3))
+		})
+	})
+
+	Context("with JUnit format", func() {
+		It("should write a test suite with failures", func() {
+			Expect(check.NewWriter(check.OutputFormatJUnit, buf).Write(report)).To(Succeed())
+
+			output := buf.String()
+			Expect(output).To(HavePrefix(xml.Header))
+			Expect(output).To(ContainSubstring(`<testsuite name="labrat.test" tests="3" failures="1" time="1.250">`))
+			Expect(output).To(ContainSubstring(`<testcase name="api" classname="labrat.test" time="0.250">`))
+			Expect(output).To(ContainSubstring(`<failure message="phase Pending" type="FAIL">phase Pending</failure>`))
+			Expect(output).To(ContainSubstring(`<system-out>WARN: not installed</system-out>`))
+
+			var decoded struct {
+				Suites []struct {
+					Cases []struct {
+						Name string `xml:"name,attr"`
+					} `xml:"testcase"`
+				} `xml:"testsuite"`
+			}
+			Expect(xml.Unmarshal(buf.Bytes(), &decoded)).To(Succeed())
+			Expect(decoded.Suites[0].Cases).To(HaveLen(3))
+		})
+	})
+
+	Context("with unsupported format", func() {
+		It("should return an error", func() {
+			err := check.NewWriter(check.OutputFormat("xml"), buf).Write(report)
+			Expect(err).To(MatchError(ContainSubstring("unsupported output format")))
+		})
+	})
+})
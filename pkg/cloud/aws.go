@@ -0,0 +1,80 @@
+package cloud
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awscredentials "github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// DefaultAWSRegion is used for AWS API calls when no region is configured
+const DefaultAWSRegion = "us-east-1"
+
+// STSAPI is the subset of the AWS STS API used by labrat
+type STSAPI interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// EC2API is the subset of the AWS EC2 API used by labrat
+type EC2API interface {
+	DescribeRegions(ctx context.Context, params *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error)
+	DescribeAvailabilityZones(ctx context.Context, params *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error)
+	DescribeInstanceTypeOfferings(ctx context.Context, params *ec2.DescribeInstanceTypeOfferingsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypeOfferingsOutput, error)
+	DescribeInstanceTypes(ctx context.Context, params *ec2.DescribeInstanceTypesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error)
+	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+	DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+	DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error)
+}
+
+// IAMAPI is the subset of the AWS IAM API used by labrat
+type IAMAPI interface {
+	SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+}
+
+// Route53API is the subset of the AWS Route 53 API used by labrat
+type Route53API interface {
+	ListHostedZonesByName(ctx context.Context, params *route53.ListHostedZonesByNameInput, optFns ...func(*route53.Options)) (*route53.ListHostedZonesByNameOutput, error)
+	ListResourceRecordSets(ctx context.Context, params *route53.ListResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error)
+}
+
+// ServiceQuotasAPI is the subset of the AWS Service Quotas API used by labrat
+type ServiceQuotasAPI interface {
+	GetServiceQuota(ctx context.Context, params *servicequotas.GetServiceQuotaInput, optFns ...func(*servicequotas.Options)) (*servicequotas.GetServiceQuotaOutput, error)
+}
+
+// AWSClients groups the AWS service clients used by labrat
+type AWSClients struct {
+	STS           STSAPI
+	EC2           EC2API
+	IAM           IAMAPI
+	Route53       Route53API
+	ServiceQuotas ServiceQuotasAPI
+}
+
+// AWSClientFactory creates AWS service clients for a region using the given credentials
+type AWSClientFactory func(creds *AWSCredentials, region string) *AWSClients
+
+// NewAWSClients creates AWS service clients for a region using static credentials
+func NewAWSClients(creds *AWSCredentials, region string) *AWSClients {
+	if region == "" {
+		region = DefaultAWSRegion
+	}
+
+	cfg := aws.Config{
+		Region:      region,
+		Credentials: awscredentials.NewStaticCredentialsProvider(creds.AccessKeyID, creds.SecretAccessKey, ""),
+	}
+
+	return &AWSClients{
+		STS:           sts.NewFromConfig(cfg),
+		EC2:           ec2.NewFromConfig(cfg),
+		IAM:           iam.NewFromConfig(cfg),
+		Route53:       route53.NewFromConfig(cfg),
+		ServiceQuotas: servicequotas.NewFromConfig(cfg),
+	}
+}
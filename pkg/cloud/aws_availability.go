@@ -0,0 +1,177 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+// maxInstanceTypeAlternatives caps the number of alternatives suggested for an unavailable instance type
+const maxInstanceTypeAlternatives = 3
+
+// runAWSAvailabilityChecks verifies that the requested zones exist in the region and that each
+// requested instance type is offered in them. AWS does not expose spare capacity, so offerings
+// are the closest signal available before the installer tries to launch instances.
+func runAWSAvailabilityChecks(ctx context.Context, report *check.Report, client EC2API, spec ClusterSpec) {
+	var zones []string
+	result := report.Run("AWS availability zones", func() (check.Status, string) {
+		available, err := awsAvailabilityZones(ctx, client)
+		if err != nil {
+			return check.StatusFail, fmt.Sprintf("failed to list availability zones in %s: %v", spec.Region, err)
+		}
+		if len(available) == 0 {
+			return check.StatusFail, fmt.Sprintf("no availability zones available in %s", spec.Region)
+		}
+
+		if len(spec.Zones) == 0 {
+			zones = available
+			return check.StatusPass, strings.Join(zones, ", ")
+		}
+
+		var unknown []string
+		for _, zone := range spec.Zones {
+			if !slices.Contains(available, zone) {
+				unknown = append(unknown, zone)
+			}
+		}
+		if len(unknown) > 0 {
+			return check.StatusFail, fmt.Sprintf("%s not available in %s (available: %s)",
+				strings.Join(unknown, ", "), spec.Region, strings.Join(available, ", "))
+		}
+		zones = spec.Zones
+		return check.StatusPass, strings.Join(zones, ", ")
+	})
+	if result.Status == check.StatusFail {
+		return
+	}
+
+	instanceTypes := []string{valueOrDefault(spec.ControlPlane.InstanceType, DefaultAWSInstanceType)}
+	for _, pool := range append([]MachinePool{spec.Compute}, spec.AdditionalCompute...) {
+		if instanceType := valueOrDefault(pool.InstanceType, DefaultAWSInstanceType); !slices.Contains(instanceTypes, instanceType) {
+			instanceTypes = append(instanceTypes, instanceType)
+		}
+	}
+
+	offered, err := awsInstanceTypeZones(ctx, client, instanceTypes)
+	for _, instanceType := range instanceTypes {
+		report.Run(fmt.Sprintf("AWS instance type %s", instanceType), func() (check.Status, string) {
+			if err != nil {
+				return check.StatusWarn, fmt.Sprintf("failed to list instance type offerings: %v", err)
+			}
+			return checkAWSInstanceTypeOffered(ctx, client, spec, instanceType, zones, offered[instanceType])
+		})
+	}
+}
+
+// checkAWSInstanceTypeOffered compares the zones offering an instance type with the zones the cluster will use.
+// Explicitly requested zones must all offer the type; otherwise the installer only uses zones that do.
+func checkAWSInstanceTypeOffered(ctx context.Context, client EC2API, spec ClusterSpec, instanceType string, zones, offeredZones []string) (check.Status, string) {
+	var missing []string
+	for _, zone := range zones {
+		if !slices.Contains(offeredZones, zone) {
+			missing = append(missing, zone)
+		}
+	}
+
+	switch {
+	case len(missing) == 0:
+		return check.StatusPass, fmt.Sprintf("offered in %s", strings.Join(zones, ", "))
+	case len(missing) < len(zones) && len(spec.Zones) == 0:
+		return check.StatusWarn, fmt.Sprintf("offered in %d of %d zones (not %s)", len(zones)-len(missing), len(zones), strings.Join(missing, ", "))
+	}
+
+	message := fmt.Sprintf("%s is not offered in %s", instanceType, strings.Join(missing, ", "))
+	if alternatives := awsInstanceTypeAlternatives(ctx, client, instanceType, zones); len(alternatives) > 0 {
+		message += fmt.Sprintf("; alternatives: %s", strings.Join(alternatives, ", "))
+	}
+	return check.StatusFail, message
+}
+
+// awsAvailabilityZones lists the available (non-local, non-wavelength) zones of the client's region
+func awsAvailabilityZones(ctx context.Context, client EC2API) ([]string, error) {
+	out, err := client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{
+		Filters: []ec2types.Filter{
+			{Name: aws.String("zone-type"), Values: []string{"availability-zone"}},
+			{Name: aws.String("state"), Values: []string{"available"}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]string, 0, len(out.AvailabilityZones))
+	for _, zone := range out.AvailabilityZones {
+		zones = append(zones, aws.ToString(zone.ZoneName))
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
+// awsInstanceTypeZones maps each instance type to the zones that offer it
+func awsInstanceTypeZones(ctx context.Context, client EC2API, instanceTypes []string) (map[string][]string, error) {
+	offered := make(map[string][]string, len(instanceTypes))
+	paginator := ec2.NewDescribeInstanceTypeOfferingsPaginator(client, &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: ec2types.LocationTypeAvailabilityZone,
+		Filters: []ec2types.Filter{
+			{Name: aws.String("instance-type"), Values: instanceTypes},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, offering := range page.InstanceTypeOfferings {
+			instanceType := string(offering.InstanceType)
+			offered[instanceType] = append(offered[instanceType], aws.ToString(offering.Location))
+		}
+	}
+	return offered, nil
+}
+
+// awsInstanceTypeAlternatives suggests instance types of the same size from the same family class
+// (e.g. m5.xlarge or m7i.xlarge for m6i.xlarge) that every zone in zones offers, newest first
+func awsInstanceTypeAlternatives(ctx context.Context, client EC2API, instanceType string, zones []string) []string {
+	family, size, ok := strings.Cut(instanceType, ".")
+	if !ok || family == "" {
+		return nil
+	}
+
+	offered := make(map[string]int)
+	paginator := ec2.NewDescribeInstanceTypeOfferingsPaginator(client, &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: ec2types.LocationTypeAvailabilityZone,
+		Filters: []ec2types.Filter{
+			{Name: aws.String("instance-type"), Values: []string{fmt.Sprintf("%c*.%s", family[0], size)}},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil
+		}
+		for _, offering := range page.InstanceTypeOfferings {
+			if slices.Contains(zones, aws.ToString(offering.Location)) {
+				offered[string(offering.InstanceType)]++
+			}
+		}
+	}
+
+	var alternatives []string
+	for candidate, count := range offered {
+		if candidate != instanceType && count >= len(zones) {
+			alternatives = append(alternatives, candidate)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(alternatives)))
+	if len(alternatives) > maxInstanceTypeAlternatives {
+		alternatives = alternatives[:maxInstanceTypeAlternatives]
+	}
+	return alternatives
+}
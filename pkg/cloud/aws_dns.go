@@ -0,0 +1,110 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+// runAWSDNSChecks verifies that the base domain has a public Route 53 hosted zone in the account
+// and that the records the installer creates for the cluster do not already exist
+func runAWSDNSChecks(ctx context.Context, report *check.Report, client Route53API, spec ClusterSpec) {
+	var zoneID string
+	result := report.Run("AWS base domain", func() (check.Status, string) {
+		if spec.BaseDomain == "" {
+			return check.StatusFail, "no base domain set: pass --base-domain or add baseDomain to the credential secret"
+		}
+
+		id, err := awsPublicHostedZone(ctx, client, spec.BaseDomain)
+		if err != nil {
+			return check.StatusFail, fmt.Sprintf("failed to look up hosted zones: %v", err)
+		}
+		if id == "" {
+			return check.StatusFail, fmt.Sprintf("no public Route 53 hosted zone for %s in this account: create one and delegate it with NS records in the parent domain",
+				spec.BaseDomain)
+		}
+		zoneID = id
+		return check.StatusPass, fmt.Sprintf("%s (%s)", spec.BaseDomain, zoneID)
+	})
+	if result.Status == check.StatusFail {
+		return
+	}
+
+	report.Run("AWS cluster DNS records", func() (check.Status, string) {
+		if spec.Name == "" {
+			return check.StatusWarn, "cluster name not set, skipping record check"
+		}
+
+		clusterDomain := fmt.Sprintf("%s.%s", spec.Name, spec.BaseDomain)
+		var existing []string
+		for _, name := range []string{"api." + clusterDomain, "*.apps." + clusterDomain} {
+			found, err := awsRecordExists(ctx, client, zoneID, name)
+			if err != nil {
+				return check.StatusWarn, fmt.Sprintf("failed to list records in %s: %v", zoneID, err)
+			}
+			if found {
+				existing = append(existing, name)
+			}
+		}
+
+		if len(existing) > 0 {
+			return check.StatusFail, fmt.Sprintf("records already exist: %s. A previous cluster named %s may not have been deprovisioned: deprovision it, delete the stale records, or choose another name",
+				strings.Join(existing, ", "), spec.Name)
+		}
+		return check.StatusPass, fmt.Sprintf("api and *.apps records for %s are free", clusterDomain)
+	})
+}
+
+// awsPublicHostedZone returns the ID of the public hosted zone for domain, or "" if there is none
+func awsPublicHostedZone(ctx context.Context, client Route53API, domain string) (string, error) {
+	fqdn := toFQDN(domain)
+	out, err := client.ListHostedZonesByName(ctx, &route53.ListHostedZonesByNameInput{
+		DNSName: aws.String(fqdn),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Zones are returned in name order starting at DNSName, so matches come first
+	for _, zone := range out.HostedZones {
+		if !strings.EqualFold(aws.ToString(zone.Name), fqdn) {
+			break
+		}
+		if zone.Config != nil && zone.Config.PrivateZone {
+			continue
+		}
+		return strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/"), nil
+	}
+	return "", nil
+}
+
+// awsRecordExists reports whether any record set named name exists in the hosted zone
+func awsRecordExists(ctx context.Context, client Route53API, zoneID, name string) (bool, error) {
+	fqdn := toFQDN(name)
+	out, err := client.ListResourceRecordSets(ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(zoneID),
+		StartRecordName: aws.String(fqdn),
+		MaxItems:        aws.Int32(1),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, record := range out.ResourceRecordSets {
+		// Route 53 returns the wildcard label in octal escape form
+		recordName := strings.ReplaceAll(aws.ToString(record.Name), `\052`, "*")
+		if strings.EqualFold(recordName, fqdn) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// toFQDN returns name with a trailing dot
+func toFQDN(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
@@ -0,0 +1,201 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+const (
+	// DefaultAWSInstanceType is the installer's default instance type for control plane and compute machines
+	DefaultAWSInstanceType = "m6i.xlarge"
+
+	// awsBootstrapInstanceType is the temporary bootstrap machine created during install
+	awsBootstrapInstanceType = "m6i.xlarge"
+
+	// Service Quotas codes for the limits an installer-provisioned cluster consumes
+	awsStandardVCPUQuotaCode = "L-1216C47A" // Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances
+	awsElasticIPQuotaCode    = "L-0263D0A3" // EC2-VPC Elastic IPs
+	awsVPCQuotaCode          = "L-F678F1CE" // VPCs per Region
+)
+
+// awsQuota is a Service Quotas limit compared against current usage and the planned footprint
+type awsQuota struct {
+	service string
+	code    string
+	unit    string
+}
+
+// runAWSQuotaChecks compares the region's vCPU, Elastic IP, and VPC quotas against current
+// usage plus what the installer will create for spec:
+//   - vCPUs for the control plane, compute, and temporary bootstrap machines
+//   - one Elastic IP per availability zone for the NAT gateways
+//   - one VPC
+func runAWSQuotaChecks(ctx context.Context, report *check.Report, clients *AWSClients, spec ClusterSpec) {
+	report.Run("AWS vCPU quota", func() (check.Status, string) {
+		required, err := awsRequiredVCPUs(ctx, clients.EC2, spec)
+		if err != nil {
+			return check.StatusFail, err.Error()
+		}
+		used, err := awsStandardVCPUsInUse(ctx, clients.EC2)
+		if err != nil {
+			return check.StatusWarn, fmt.Sprintf("failed to count running vCPUs: %v", err)
+		}
+		return compareAWSQuota(ctx, clients.ServiceQuotas, awsQuota{"ec2", awsStandardVCPUQuotaCode, "vCPUs"}, used, required)
+	})
+
+	report.Run("AWS Elastic IP quota", func() (check.Status, string) {
+		zones := spec.Zones
+		if len(zones) == 0 {
+			available, err := awsAvailabilityZones(ctx, clients.EC2)
+			if err != nil {
+				return check.StatusWarn, fmt.Sprintf("failed to list availability zones: %v", err)
+			}
+			zones = available
+		}
+		addresses, err := clients.EC2.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
+		if err != nil {
+			return check.StatusWarn, fmt.Sprintf("failed to count Elastic IPs: %v", err)
+		}
+		return compareAWSQuota(ctx, clients.ServiceQuotas, awsQuota{"ec2", awsElasticIPQuotaCode, "Elastic IPs"},
+			len(addresses.Addresses), len(zones))
+	})
+
+	report.Run("AWS VPC quota", func() (check.Status, string) {
+		used := 0
+		paginator := ec2.NewDescribeVpcsPaginator(clients.EC2, &ec2.DescribeVpcsInput{})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return check.StatusWarn, fmt.Sprintf("failed to count VPCs: %v", err)
+			}
+			used += len(page.Vpcs)
+		}
+		return compareAWSQuota(ctx, clients.ServiceQuotas, awsQuota{"vpc", awsVPCQuotaCode, "VPCs"}, used, 1)
+	})
+}
+
+// compareAWSQuota fails if used+required exceeds the quota. Quotas that cannot be read are
+// reported as warnings since the credential may lack servicequotas permissions.
+func compareAWSQuota(ctx context.Context, client ServiceQuotasAPI, quota awsQuota, used, required int) (check.Status, string) {
+	limit, err := awsQuotaLimit(ctx, client, quota)
+	if err != nil {
+		return check.StatusWarn, err.Error()
+	}
+
+	available := limit - used
+	if required > available {
+		return check.StatusFail, fmt.Sprintf("insufficient quota: need %d %s, %d of %d available (request an increase of %s in Service Quotas)",
+			required, quota.unit, max(available, 0), limit, quota.code)
+	}
+	return check.StatusPass, fmt.Sprintf("need %d %s, %d of %d available", required, quota.unit, available, limit)
+}
+
+// awsQuotaLimit reads the applied value of a Service Quotas limit
+func awsQuotaLimit(ctx context.Context, client ServiceQuotasAPI, quota awsQuota) (int, error) {
+	out, err := client.GetServiceQuota(ctx, &servicequotas.GetServiceQuotaInput{
+		ServiceCode: aws.String(quota.service),
+		QuotaCode:   aws.String(quota.code),
+	})
+	if err != nil || out.Quota == nil || out.Quota.Value == nil {
+		return 0, fmt.Errorf("unable to read quota %s: %v", quota.code, err)
+	}
+	return int(aws.ToFloat64(out.Quota.Value)), nil
+}
+
+// awsRequiredVCPUs sums the vCPUs of every machine the installer creates for spec
+func awsRequiredVCPUs(ctx context.Context, client EC2API, spec ClusterSpec) (int, error) {
+	pools := []MachinePool{
+		{InstanceType: valueOrDefault(spec.ControlPlane.InstanceType, DefaultAWSInstanceType), Replicas: spec.ControlPlane.Replicas},
+		{InstanceType: valueOrDefault(spec.Compute.InstanceType, DefaultAWSInstanceType), Replicas: spec.Compute.Replicas},
+		{InstanceType: awsBootstrapInstanceType, Replicas: 1},
+	}
+
+	instanceTypes := make([]string, 0, len(pools))
+	for _, pool := range pools {
+		instanceTypes = append(instanceTypes, pool.InstanceType)
+	}
+	vcpus, err := awsInstanceTypeVCPUs(ctx, client, instanceTypes)
+	if err != nil {
+		return 0, err
+	}
+
+	required := 0
+	for _, pool := range pools {
+		if isAWSStandardFamily(pool.InstanceType) {
+			required += vcpus[pool.InstanceType] * pool.Replicas
+		}
+	}
+	return required, nil
+}
+
+// awsInstanceTypeVCPUs looks up the default vCPU count of each instance type
+func awsInstanceTypeVCPUs(ctx context.Context, client EC2API, instanceTypes []string) (map[string]int, error) {
+	input := &ec2.DescribeInstanceTypesInput{}
+	for _, instanceType := range instanceTypes {
+		if !slices.Contains(input.InstanceTypes, ec2types.InstanceType(instanceType)) {
+			input.InstanceTypes = append(input.InstanceTypes, ec2types.InstanceType(instanceType))
+		}
+	}
+
+	out, err := client.DescribeInstanceTypes(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instance types %s: %w", strings.Join(instanceTypes, ", "), err)
+	}
+
+	vcpus := make(map[string]int, len(out.InstanceTypes))
+	for _, info := range out.InstanceTypes {
+		if info.VCpuInfo != nil {
+			vcpus[string(info.InstanceType)] = int(aws.ToInt32(info.VCpuInfo.DefaultVCpus))
+		}
+	}
+	return vcpus, nil
+}
+
+// awsStandardVCPUsInUse counts the vCPUs of pending and running standard-family instances
+func awsStandardVCPUsInUse(ctx context.Context, client EC2API) (int, error) {
+	used := 0
+	paginator := ec2.NewDescribeInstancesPaginator(client, &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{
+			{Name: aws.String("instance-state-name"), Values: []string{"pending", "running"}},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				if !isAWSStandardFamily(string(instance.InstanceType)) || instance.CpuOptions == nil {
+					continue
+				}
+				used += int(aws.ToInt32(instance.CpuOptions.CoreCount) * aws.ToInt32(instance.CpuOptions.ThreadsPerCore))
+			}
+		}
+	}
+	return used, nil
+}
+
+// isAWSStandardFamily reports whether an instance type counts against the standard On-Demand vCPU quota
+func isAWSStandardFamily(instanceType string) bool {
+	if instanceType == "" {
+		return false
+	}
+	return strings.ContainsRune("acdhimrtz", rune(strings.ToLower(instanceType)[0]))
+}
+
+// valueOrDefault returns value, or def if value is empty
+func valueOrDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
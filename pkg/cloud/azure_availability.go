@@ -0,0 +1,108 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+// azureSKUsAPIVersion is the API version of the Microsoft.Compute resource SKUs
+const azureSKUsAPIVersion = "2021-07-01"
+
+// azureSKU is a resource SKU of a location, with the restrictions of the subscription
+type azureSKU struct {
+	ResourceType string `json:"resourceType"`
+	Name         string `json:"name"`
+	Restrictions []struct {
+		// Type is Location when the SKU cannot be used in the location at all, Zone when it
+		// cannot be used in the zones of RestrictionInfo
+		Type            string `json:"type"`
+		ReasonCode      string `json:"reasonCode"`
+		RestrictionInfo struct {
+			Zones []string `json:"zones"`
+		} `json:"restrictionInfo"`
+	} `json:"restrictions"`
+}
+
+// azureSKUs is a page of the resource SKUs of a location
+type azureSKUs struct {
+	Value    []azureSKU `json:"value"`
+	NextLink string     `json:"nextLink"`
+}
+
+// runAzureAvailabilityChecks verifies that each requested VM size is offered in the location
+// and not restricted for the subscription, in the requested zones if any
+func runAzureAvailabilityChecks(ctx context.Context, report *check.Report, client *http.Client, creds *AzureCredentials, spec ClusterSpec) {
+	sizes := []string{valueOrDefault(spec.ControlPlane.InstanceType, DefaultAzureControlPlaneType)}
+	for _, pool := range append([]MachinePool{spec.Compute}, spec.AdditionalCompute...) {
+		if size := valueOrDefault(pool.InstanceType, DefaultAzureComputeType); !slices.Contains(sizes, size) {
+			sizes = append(sizes, size)
+		}
+	}
+
+	skus, err := azureVMSizes(ctx, client, creds.SubscriptionID, spec.Region)
+	for _, size := range sizes {
+		report.Run(fmt.Sprintf("Azure VM size %s", size), func() (check.Status, string) {
+			if err != nil {
+				return check.StatusWarn, fmt.Sprintf("failed to list VM sizes of %s: %v", spec.Region, err)
+			}
+			sku, ok := skus[strings.ToLower(size)]
+			if !ok {
+				return check.StatusFail, fmt.Sprintf("%s is not offered in %s", size, spec.Region)
+			}
+			return checkAzureVMSizeRestrictions(sku, spec)
+		})
+	}
+}
+
+// checkAzureVMSizeRestrictions fails for a VM size the subscription cannot use in the location,
+// or in one of the requested zones
+func checkAzureVMSizeRestrictions(sku azureSKU, spec ClusterSpec) (check.Status, string) {
+	for _, restriction := range sku.Restrictions {
+		switch restriction.Type {
+		case "Location":
+			return check.StatusFail, fmt.Sprintf("%s is restricted for this subscription in %s (%s): request access to it or choose another size",
+				sku.Name, spec.Region, restriction.ReasonCode)
+		case "Zone":
+			var restricted []string
+			for _, zone := range spec.Zones {
+				if slices.Contains(restriction.RestrictionInfo.Zones, zone) {
+					restricted = append(restricted, zone)
+				}
+			}
+			if len(restricted) > 0 {
+				return check.StatusFail, fmt.Sprintf("%s is restricted for this subscription in zones %s of %s (%s)",
+					sku.Name, strings.Join(restricted, ", "), spec.Region, restriction.ReasonCode)
+			}
+			if len(spec.Zones) == 0 {
+				return check.StatusWarn, fmt.Sprintf("restricted in zones %s (%s)", strings.Join(restriction.RestrictionInfo.Zones, ", "), restriction.ReasonCode)
+			}
+		}
+	}
+	return check.StatusPass, fmt.Sprintf("offered in %s", spec.Region)
+}
+
+// azureVMSizes returns the virtual machine SKUs of a location by lower-case name
+func azureVMSizes(ctx context.Context, client *http.Client, subscriptionID, location string) (map[string]azureSKU, error) {
+	skus := make(map[string]azureSKU)
+	endpoint := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Compute/skus?api-version=%s&$filter=%s",
+		azureManagementEndpoint, url.PathEscape(subscriptionID), azureSKUsAPIVersion, url.QueryEscape(fmt.Sprintf("location eq '%s'", location)))
+	for endpoint != "" {
+		var page azureSKUs
+		if err := getJSON(ctx, client, endpoint, &page); err != nil {
+			return nil, err
+		}
+		for _, sku := range page.Value {
+			if sku.ResourceType == "virtualMachines" {
+				skus[strings.ToLower(sku.Name)] = sku
+			}
+		}
+		endpoint = page.NextLink
+	}
+	return skus, nil
+}
@@ -0,0 +1,101 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	// DefaultAzureControlPlaneType is the installer's default VM size for control plane machines
+	DefaultAzureControlPlaneType = "Standard_D8s_v3"
+	// DefaultAzureComputeType is the installer's default VM size for compute machines
+	DefaultAzureComputeType = "Standard_D4s_v3"
+
+	azureManagementEndpoint = "https://management.azure.com"
+	azureLoginEndpoint      = "https://login.microsoftonline.com"
+	azureComputeAPIVersion  = "2023-07-01"
+)
+
+// azureVMSizePattern captures the vCPUs of a VM size, e.g. 8 of Standard_D8s_v3
+var azureVMSizePattern = regexp.MustCompile(`^(?:Standard|Basic)_[A-Za-z]+?(\d+)`)
+
+// azureUsages is the list of compute usages of a subscription in a location
+type azureUsages struct {
+	Value []struct {
+		Name struct {
+			Value string `json:"value"`
+		} `json:"name"`
+		CurrentValue int `json:"currentValue"`
+		Limit        int `json:"limit"`
+	} `json:"value"`
+}
+
+// readAzureCapacity reads the regional vCPU quota of the subscription of the service principal
+// and compares it with the control plane, compute, and bootstrap machines of spec
+func (r *capacityReader) readAzureCapacity(ctx context.Context, creds *AzureCredentials, region string, spec ClusterSpec) (*Capacity, error) {
+	if region == "" {
+		return nil, fmt.Errorf("a region is required to read the quotas of Azure subscriptions")
+	}
+
+	controlPlaneType := valueOrDefault(spec.ControlPlane.InstanceType, DefaultAzureControlPlaneType)
+	pools := []MachinePool{
+		{InstanceType: controlPlaneType, Replicas: spec.ControlPlane.Replicas},
+		{InstanceType: valueOrDefault(spec.Compute.InstanceType, DefaultAzureComputeType), Replicas: spec.Compute.Replicas},
+		{InstanceType: controlPlaneType, Replicas: 1},
+	}
+	vcpus := 0
+	for _, pool := range pools {
+		n, err := azureVMSizeVCPUs(pool.InstanceType)
+		if err != nil {
+			return nil, err
+		}
+		vcpus += n * pool.Replicas
+	}
+
+	var usages azureUsages
+	endpoint := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Compute/locations/%s/usages?api-version=%s",
+		azureManagementEndpoint, url.PathEscape(creds.SubscriptionID), url.PathEscape(region), azureComputeAPIVersion)
+	if err := getJSON(ctx, azureClient(ctx, r.httpClient, creds), endpoint, &usages); err != nil {
+		return nil, fmt.Errorf("failed to read quotas of location %s: %w", region, err)
+	}
+
+	capacity := &Capacity{Provider: ProviderAzure, Account: creds.SubscriptionID, Region: region}
+	for _, usage := range usages.Value {
+		if usage.Name.Value == "cores" {
+			capacity.Quotas = append(capacity.Quotas, Quota{Name: "vCPUs", Used: usage.CurrentValue, Limit: usage.Limit, PerCluster: vcpus})
+		}
+	}
+	return capacity, nil
+}
+
+// azureVMSizeVCPUs returns the vCPUs of a VM size from its name
+func azureVMSizeVCPUs(size string) (int, error) {
+	match := azureVMSizePattern.FindStringSubmatch(size)
+	if match == nil {
+		return 0, fmt.Errorf("cannot tell the vCPUs of Azure VM size %s", size)
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("cannot tell the vCPUs of Azure VM size %s", size)
+	}
+	return n, nil
+}
+
+// azureClient returns an HTTP client authenticated to the Azure Resource Manager API as the
+// service principal of creds, sending its requests through httpClient
+func azureClient(ctx context.Context, httpClient *http.Client, creds *AzureCredentials) *http.Client {
+	config := &clientcredentials.Config{
+		ClientID:     creds.ClientID,
+		ClientSecret: creds.ClientSecret,
+		TokenURL:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", azureLoginEndpoint, url.PathEscape(creds.TenantID)),
+		Scopes:       []string{azureManagementEndpoint + "/.default"},
+	}
+	return config.Client(context.WithValue(ctx, oauth2.HTTPClient, httpClient))
+}
@@ -0,0 +1,124 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+// azureDNSAPIVersion is the API version of the Microsoft.Network DNS zones
+const azureDNSAPIVersion = "2018-05-01"
+
+// azureDNSZones is a page of the DNS zones of a subscription
+type azureDNSZones struct {
+	Value []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Properties struct {
+			ZoneType string `json:"zoneType"`
+		} `json:"properties"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+// azureRecordSets is a page of the record sets of a DNS zone
+type azureRecordSets struct {
+	Value []struct {
+		Name string `json:"name"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+// runAzureDNSChecks verifies that the base domain has a public Azure DNS zone in the
+// subscription and that the records the installer creates for the cluster do not already exist
+func runAzureDNSChecks(ctx context.Context, report *check.Report, client *http.Client, creds *AzureCredentials, spec ClusterSpec) {
+	var zoneID string
+	result := report.Run("Azure base domain", func() (check.Status, string) {
+		if spec.BaseDomain == "" {
+			return check.StatusFail, "no base domain set: pass --base-domain or add baseDomain to the credential secret"
+		}
+
+		id, err := azurePublicDNSZone(ctx, client, creds.SubscriptionID, spec.BaseDomain)
+		if err != nil {
+			return check.StatusFail, fmt.Sprintf("failed to look up DNS zones: %v", err)
+		}
+		if id == "" {
+			return check.StatusFail, fmt.Sprintf("no public Azure DNS zone for %s in subscription %s: create one and delegate it with NS records in the parent domain",
+				spec.BaseDomain, creds.SubscriptionID)
+		}
+		zoneID = id
+		return check.StatusPass, fmt.Sprintf("%s (resource group %s)", spec.BaseDomain, azureResourceGroup(zoneID))
+	})
+	if result.Status == check.StatusFail {
+		return
+	}
+
+	report.Run("Azure cluster DNS records", func() (check.Status, string) {
+		if spec.Name == "" {
+			return check.StatusWarn, "cluster name not set, skipping record check"
+		}
+
+		// Record sets are named relative to the zone; the suffix filter returns those of the cluster
+		endpoint := fmt.Sprintf("%s%s/recordsets?api-version=%s&$recordsetnamesuffix=%s",
+			azureManagementEndpoint, zoneID, azureDNSAPIVersion, url.QueryEscape(spec.Name))
+		wanted := []string{"api." + spec.Name, "*.apps." + spec.Name}
+		var existing []string
+		for endpoint != "" {
+			var page azureRecordSets
+			if err := getJSON(ctx, client, endpoint, &page); err != nil {
+				return check.StatusWarn, fmt.Sprintf("failed to list records of %s: %v", spec.BaseDomain, err)
+			}
+			for _, record := range page.Value {
+				for _, name := range wanted {
+					if strings.EqualFold(record.Name, name) {
+						existing = append(existing, name+"."+spec.BaseDomain)
+					}
+				}
+			}
+			endpoint = page.NextLink
+		}
+
+		clusterDomain := fmt.Sprintf("%s.%s", spec.Name, spec.BaseDomain)
+		if len(existing) > 0 {
+			return check.StatusFail, fmt.Sprintf("records already exist: %s. A previous cluster named %s may not have been deprovisioned: deprovision it, delete the stale records, or choose another name",
+				strings.Join(existing, ", "), spec.Name)
+		}
+		return check.StatusPass, fmt.Sprintf("api and *.apps records for %s are free", clusterDomain)
+	})
+}
+
+// azurePublicDNSZone returns the resource ID of the public DNS zone for domain in the
+// subscription, or "" if there is none
+func azurePublicDNSZone(ctx context.Context, client *http.Client, subscriptionID, domain string) (string, error) {
+	endpoint := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Network/dnszones?api-version=%s",
+		azureManagementEndpoint, url.PathEscape(subscriptionID), azureDNSAPIVersion)
+	for endpoint != "" {
+		var page azureDNSZones
+		if err := getJSON(ctx, client, endpoint, &page); err != nil {
+			return "", err
+		}
+		for _, zone := range page.Value {
+			if strings.EqualFold(zone.Name, strings.TrimSuffix(domain, ".")) && !strings.EqualFold(zone.Properties.ZoneType, "Private") {
+				return zone.ID, nil
+			}
+		}
+		endpoint = page.NextLink
+	}
+	return "", nil
+}
+
+// azureResourceGroup returns the resource group of an Azure resource ID, which the install
+// config needs as the resource group of the base domain
+func azureResourceGroup(resourceID string) string {
+	parts := strings.Split(resourceID, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "resourceGroups") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Quota is a cloud limit that every new cluster consumes part of
+type Quota struct {
+	// Name is the name of the limited resource, e.g. vCPUs
+	Name  string `json:"name"`
+	Used  int    `json:"used"`
+	Limit int    `json:"limit"`
+	// PerCluster is how much of the quota one more cluster needs
+	PerCluster int `json:"perCluster"`
+}
+
+// Clusters returns how many more clusters fit in the quota
+func (q Quota) Clusters() int {
+	if q.PerCluster <= 0 {
+		return -1
+	}
+	return max(q.Limit-q.Used, 0) / q.PerCluster
+}
+
+// Capacity is the quota headroom of a cloud account in a region
+type Capacity struct {
+	Provider string `json:"provider"`
+	// Account is the AWS account ID, GCP project, or Azure subscription
+	Account string  `json:"account"`
+	Region  string  `json:"region"`
+	Quotas  []Quota `json:"quotas"`
+}
+
+// Clusters returns how many more clusters can be provisioned before the first quota runs
+// out, and the name of that quota; -1 if no quota limits the clusters
+func (c Capacity) Clusters() (int, string) {
+	clusters, limitedBy := -1, ""
+	for _, quota := range c.Quotas {
+		if n := quota.Clusters(); n >= 0 && (clusters < 0 || n < clusters) {
+			clusters, limitedBy = n, quota.Name
+		}
+	}
+	return clusters, limitedBy
+}
+
+// CapacityReader reads how many more clusters cloud accounts can host
+type CapacityReader interface {
+	// Read reads the quotas of the account of creds in region, and how much of each a cluster
+	// of spec needs
+	Read(ctx context.Context, creds *Credentials, region string, spec ClusterSpec) (*Capacity, error)
+}
+
+type capacityReader struct {
+	newAWSClients AWSClientFactory
+	httpClient    *http.Client
+}
+
+// NewCapacityReader creates a new CapacityReader. If newAWSClients is nil, NewAWSClients is
+// used; if httpClient is nil, a client with a 30 second timeout calls the GCP and Azure APIs.
+func NewCapacityReader(newAWSClients AWSClientFactory, httpClient *http.Client) CapacityReader {
+	if newAWSClients == nil {
+		newAWSClients = NewAWSClients
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &capacityReader{
+		newAWSClients: newAWSClients,
+		httpClient:    httpClient,
+	}
+}
+
+// Read reads the quotas supported for the provider of creds
+func (r *capacityReader) Read(ctx context.Context, creds *Credentials, region string, spec ClusterSpec) (*Capacity, error) {
+	if err := creds.Validate(); err != nil {
+		return nil, err
+	}
+	if spec.ControlPlane.Replicas <= 0 {
+		spec.ControlPlane.Replicas = DefaultControlPlaneReplicas
+	}
+	if spec.Compute.Replicas <= 0 {
+		spec.Compute.Replicas = DefaultComputeReplicas
+	}
+
+	switch creds.Provider {
+	case ProviderAWS:
+		if region == "" {
+			region = DefaultAWSRegion
+		}
+		return readAWSCapacity(ctx, r.newAWSClients(creds.AWS, region), region, spec)
+	case ProviderGCP:
+		return r.readGCPCapacity(ctx, creds.GCP, region, spec)
+	case ProviderAzure:
+		return r.readAzureCapacity(ctx, creds.Azure, region, spec)
+	default:
+		return nil, fmt.Errorf("quotas of %s accounts cannot be read", creds.Provider)
+	}
+}
+
+// readAWSCapacity reads the vCPU, Elastic IP, and VPC quotas a cluster consumes, like the
+// preflight of spoke create
+func readAWSCapacity(ctx context.Context, clients *AWSClients, region string, spec ClusterSpec) (*Capacity, error) {
+	identity, err := clients.STS.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate: %w", err)
+	}
+	capacity := &Capacity{Provider: ProviderAWS, Account: aws.ToString(identity.Account), Region: region}
+
+	vcpus, err := awsRequiredVCPUs(ctx, clients.EC2, spec)
+	if err != nil {
+		return nil, err
+	}
+	usedVCPUs, err := awsStandardVCPUsInUse(ctx, clients.EC2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count running vCPUs: %w", err)
+	}
+
+	zones := len(spec.Zones)
+	if zones == 0 {
+		available, err := awsAvailabilityZones(ctx, clients.EC2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list availability zones: %w", err)
+		}
+		zones = len(available)
+	}
+	addresses, err := clients.EC2.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count Elastic IPs: %w", err)
+	}
+
+	vpcs := 0
+	paginator := ec2.NewDescribeVpcsPaginator(clients.EC2, &ec2.DescribeVpcsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count VPCs: %w", err)
+		}
+		vpcs += len(page.Vpcs)
+	}
+
+	for _, quota := range []struct {
+		awsQuota
+		used, perCluster int
+	}{
+		{awsQuota{"ec2", awsStandardVCPUQuotaCode, "vCPUs"}, usedVCPUs, vcpus},
+		{awsQuota{"ec2", awsElasticIPQuotaCode, "Elastic IPs"}, len(addresses.Addresses), zones},
+		{awsQuota{"vpc", awsVPCQuotaCode, "VPCs"}, vpcs, 1},
+	} {
+		limit, err := awsQuotaLimit(ctx, clients.ServiceQuotas, quota.awsQuota)
+		if err != nil {
+			return nil, err
+		}
+		capacity.Quotas = append(capacity.Quotas, Quota{Name: quota.unit, Used: quota.used, Limit: limit, PerCluster: quota.perCluster})
+	}
+	return capacity, nil
+}
+
+// getJSON decodes the JSON response of a GET request to out. Failed requests return the
+// message of the error object GCP and Azure respond with.
+func getJSON(ctx context.Context, client *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		message := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			message = apiErr.Error.Message
+		}
+		return fmt.Errorf("%s: %s", resp.Status, message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
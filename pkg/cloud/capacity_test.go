@@ -0,0 +1,225 @@
+//go:build test
+
+package cloud_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+)
+
+// redirectTransport sends every request to the test server, keeping its path and query
+type redirectTransport struct {
+	server *httptest.Server
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, _ := url.Parse(t.server.URL)
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host, req.Host = target.Scheme, target.Host, target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+var _ = Describe("CapacityReader", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should count how many clusters fit before the first quota runs out", func() {
+		capacity := cloud.Capacity{Quotas: []cloud.Quota{
+			{Name: "vCPUs", Used: 56, Limit: 256, PerCluster: 28},
+			{Name: "VPCs", Used: 2, Limit: 5, PerCluster: 1},
+			{Name: "Elastic IPs", Used: 7, Limit: 5, PerCluster: 3},
+		}}
+		Expect(capacity.Quotas[0].Clusters()).To(Equal(7))
+		Expect(capacity.Quotas[2].Clusters()).To(Equal(0))
+
+		clusters, limitedBy := capacity.Clusters()
+		Expect(clusters).To(Equal(0))
+		Expect(limitedBy).To(Equal("Elastic IPs"))
+
+		clusters, _ = cloud.Capacity{}.Clusters()
+		Expect(clusters).To(Equal(-1))
+	})
+
+	Describe("AWS", func() {
+		var (
+			fake  *fakeAWS
+			creds *cloud.Credentials
+		)
+
+		BeforeEach(func() {
+			fake = &fakeAWS{
+				zones:         []string{"us-east-2a", "us-east-2b", "us-east-2c"},
+				instanceTypes: map[string]int32{"m6i.xlarge": 4, "m6i.2xlarge": 8},
+				runningVCPUs:  []int32{4, 4},
+				addresses:     1,
+				vpcs:          2,
+				quotas:        map[string]float64{"L-1216C47A": 256, "L-0263D0A3": 10, "L-F678F1CE": 5},
+			}
+			creds = &cloud.Credentials{
+				Provider: cloud.ProviderAWS,
+				AWS:      &cloud.AWSCredentials{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret"},
+			}
+		})
+
+		It("should read the vCPU, Elastic IP, and VPC quotas", func() {
+			capacity, err := cloud.NewCapacityReader(fake.clients, nil).Read(ctx, creds, "us-east-2", cloud.ClusterSpec{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(capacity.Account).To(Equal("123456789012"))
+			Expect(capacity.Quotas).To(Equal([]cloud.Quota{
+				{Name: "vCPUs", Used: 8, Limit: 256, PerCluster: 28},
+				{Name: "Elastic IPs", Used: 1, Limit: 10, PerCluster: 3},
+				{Name: "VPCs", Used: 2, Limit: 5, PerCluster: 1},
+			}))
+			clusters, limitedBy := capacity.Clusters()
+			Expect(clusters).To(Equal(3))
+			Expect(limitedBy).To(Equal("Elastic IPs"))
+		})
+
+		It("should size clusters by the instance types of spec", func() {
+			spec := cloud.ClusterSpec{Compute: cloud.MachinePool{InstanceType: "m6i.2xlarge", Replicas: 6}, Zones: []string{"us-east-2a"}}
+			capacity, err := cloud.NewCapacityReader(fake.clients, nil).Read(ctx, creds, "us-east-2", spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(capacity.Quotas[0].PerCluster).To(Equal(64))
+			Expect(capacity.Quotas[1].PerCluster).To(Equal(1))
+		})
+
+		It("should fail when a quota cannot be read", func() {
+			delete(fake.quotas, "L-F678F1CE")
+			_, err := cloud.NewCapacityReader(fake.clients, nil).Read(ctx, creds, "us-east-2", cloud.ClusterSpec{})
+			Expect(err).To(MatchError(ContainSubstring("unable to read quota L-F678F1CE")))
+		})
+	})
+
+	Describe("GCP", func() {
+		var (
+			server   *httptest.Server
+			creds    *cloud.Credentials
+			requests []string
+		)
+
+		BeforeEach(func() {
+			requests = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.Path)
+				switch {
+				case r.URL.Path == "/token":
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"access_token":"gcp-token","token_type":"Bearer","expires_in":3600}`))
+				case r.Header.Get("Authorization") != "Bearer gcp-token":
+					w.WriteHeader(http.StatusUnauthorized)
+				case strings.HasSuffix(r.URL.Path, "/regions/us-east1"):
+					_, _ = w.Write([]byte(`{"quotas":[
+						{"metric":"CPUS","limit":72,"usage":12},
+						{"metric":"N2_CPUS","limit":48,"usage":12},
+						{"metric":"C2_CPUS","limit":24,"usage":0},
+						{"metric":"SSD_TOTAL_GB","limit":4096,"usage":896}]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"error":{"code":404,"message":"The resource 'projects/lab/regions/mars1' was not found"}}`))
+				}
+			}))
+			DeferCleanup(server.Close)
+
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).NotTo(HaveOccurred())
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			Expect(err).NotTo(HaveOccurred())
+			creds = &cloud.Credentials{
+				Provider: cloud.ProviderGCP,
+				GCP: &cloud.GCPCredentials{
+					ProjectID:   "lab",
+					ClientEmail: "labrat@lab.iam.gserviceaccount.com",
+					PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+				},
+			}
+		})
+
+		It("should read the CPU, machine family, and SSD quotas of the region", func() {
+			reader := cloud.NewCapacityReader(nil, &http.Client{Transport: redirectTransport{server}})
+			capacity, err := reader.Read(ctx, creds, "us-east1", cloud.ClusterSpec{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requests).To(ContainElement("/compute/v1/projects/lab/regions/us-east1"))
+			Expect(capacity.Account).To(Equal("lab"))
+			Expect(capacity.Quotas).To(Equal([]cloud.Quota{
+				{Name: "vCPUs", Used: 12, Limit: 72, PerCluster: 28},
+				{Name: "N2 vCPUs", Used: 12, Limit: 48, PerCluster: 28},
+				{Name: "SSD GB", Used: 896, Limit: 4096, PerCluster: 896},
+			}))
+			clusters, limitedBy := capacity.Clusters()
+			Expect(clusters).To(Equal(1))
+			Expect(limitedBy).To(Equal("N2 vCPUs"))
+		})
+
+		It("should report the errors of the API", func() {
+			reader := cloud.NewCapacityReader(nil, &http.Client{Transport: redirectTransport{server}})
+			_, err := reader.Read(ctx, creds, "mars1", cloud.ClusterSpec{})
+			Expect(err).To(MatchError(ContainSubstring("404 Not Found: The resource 'projects/lab/regions/mars1' was not found")))
+
+			_, err = reader.Read(ctx, creds, "", cloud.ClusterSpec{})
+			Expect(err).To(MatchError(ContainSubstring("a region is required")))
+		})
+
+		It("should reject machine types without a vCPU count", func() {
+			spec := cloud.ClusterSpec{Compute: cloud.MachinePool{InstanceType: "e2-medium"}}
+			_, err := cloud.NewCapacityReader(nil, nil).Read(ctx, creds, "us-east1", spec)
+			Expect(err).To(MatchError("cannot tell the vCPUs of GCP machine type e2-medium"))
+		})
+	})
+
+	Describe("Azure", func() {
+		It("should read the regional vCPU quota of the subscription", func() {
+			var tokenPath, usagesQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token") {
+					tokenPath = r.URL.Path
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"access_token":"azure-token","token_type":"Bearer","expires_in":3600}`))
+					return
+				}
+				usagesQuery = r.URL.Path + "?" + r.URL.RawQuery
+				_, _ = w.Write([]byte(`{"value":[
+					{"name":{"value":"cores","localizedValue":"Total Regional vCPUs"},"currentValue":40,"limit":200},
+					{"name":{"value":"standardDSv3Family"},"currentValue":40,"limit":100}]}`))
+			}))
+			DeferCleanup(server.Close)
+
+			creds := &cloud.Credentials{
+				Provider: cloud.ProviderAzure,
+				Azure:    &cloud.AzureCredentials{ClientID: "client", ClientSecret: "secret", TenantID: "tenant", SubscriptionID: "sub-1"},
+			}
+			reader := cloud.NewCapacityReader(nil, &http.Client{Transport: redirectTransport{server}})
+			capacity, err := reader.Read(ctx, creds, "eastus", cloud.ClusterSpec{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tokenPath).To(Equal("/tenant/oauth2/v2.0/token"))
+			Expect(usagesQuery).To(Equal("/subscriptions/sub-1/providers/Microsoft.Compute/locations/eastus/usages?api-version=2023-07-01"))
+			Expect(capacity.Account).To(Equal("sub-1"))
+			Expect(capacity.Quotas).To(Equal([]cloud.Quota{{Name: "vCPUs", Used: 40, Limit: 200, PerCluster: 44}}))
+		})
+	})
+
+	It("should refuse providers without quotas", func() {
+		creds := &cloud.Credentials{
+			Provider: cloud.ProviderVSphere,
+			VSphere:  &cloud.VSphereCredentials{VCenter: "vcenter.example.com", Username: "admin", Password: "secret", CACertificate: "cert"},
+		}
+		_, err := cloud.NewCapacityReader(nil, nil).Read(ctx, creds, "", cloud.ClusterSpec{})
+		Expect(err).To(MatchError("quotas of vsphere accounts cannot be read"))
+	})
+})
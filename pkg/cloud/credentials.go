@@ -0,0 +1,454 @@
+// Package cloud provides access to the cloud provider accounts that spoke clusters are provisioned into
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// ProviderAWS identifies Amazon Web Services credentials
+	ProviderAWS = "aws"
+	// ProviderAzure identifies Microsoft Azure credentials
+	ProviderAzure = "azure"
+	// ProviderGCP identifies Google Cloud credentials
+	ProviderGCP = "gcp"
+	// ProviderVSphere identifies VMware vSphere credentials
+	ProviderVSphere = "vsphere"
+
+	// CredentialsTypeLabel is set by the ACM console on credential secrets to record the provider
+	CredentialsTypeLabel = "cluster.open-cluster-management.io/type"
+	// CredentialsLabel marks secrets as credentials in the ACM console
+	CredentialsLabel = "cluster.open-cluster-management.io/credentials"
+)
+
+// credentialTypes are the values of CredentialsTypeLabel the ACM console sets for each provider
+var credentialTypes = map[string]string{
+	ProviderAWS:     "aws",
+	ProviderAzure:   "azr",
+	ProviderGCP:     "gcp",
+	ProviderVSphere: "vmw",
+}
+
+// Credentials holds the contents of an ACM/Hive cloud credential secret
+type Credentials struct {
+	// Name is the secret name
+	Name string
+	// Namespace is the secret namespace
+	Namespace string
+	// Provider is the cloud provider (aws, azure, gcp, vsphere)
+	Provider string
+	// BaseDomain is the default base domain stored with ACM credentials, if any
+	BaseDomain string
+	// PullSecret is the OpenShift pull secret stored with ACM credentials, if any
+	PullSecret []byte
+	// SSHPrivateKey and SSHPublicKey are the node SSH key pair stored with ACM credentials, if any
+	SSHPrivateKey []byte
+	SSHPublicKey  string
+	// AWS holds the access key for AWS credentials
+	AWS *AWSCredentials
+	// Azure holds the service principal for Azure credentials
+	Azure *AzureCredentials
+	// GCP holds the service account key for GCP credentials
+	GCP *GCPCredentials
+	// VSphere holds the vCenter account and placement for vSphere credentials
+	VSphere *VSphereCredentials
+}
+
+// AWSCredentials is an AWS access key pair
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// AzureCredentials is an Azure service principal (osServicePrincipal.json)
+type AzureCredentials struct {
+	ClientID       string `json:"clientId"`
+	ClientSecret   string `json:"clientSecret"`
+	TenantID       string `json:"tenantId"`
+	SubscriptionID string `json:"subscriptionId"`
+}
+
+// GCPCredentials is a GCP service account key (osServiceAccount.json)
+type GCPCredentials struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	// KeyFile is the service account key file the fields were parsed from, stored as is
+	KeyFile []byte `json:"-"`
+}
+
+// VSphereCredentials is a vCenter account with the default placement of clusters, as stored
+// in ACM vSphere credentials
+type VSphereCredentials struct {
+	VCenter  string
+	Username string
+	Password string
+	// CACertificate is the PEM certificate of the vCenter CA
+	CACertificate    string
+	Datacenter       string
+	DefaultDatastore string
+	Cluster          string
+	Folder           string
+}
+
+// CredentialInfo describes a credential secret without its contents
+type CredentialInfo struct {
+	Name       string    `json:"name"`
+	Namespace  string    `json:"namespace"`
+	Provider   string    `json:"provider"`
+	BaseDomain string    `json:"baseDomain,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// CredentialClient provides methods to manage cloud credential secrets on the hub
+type CredentialClient interface {
+	// Get reads and parses the named credential secret
+	Get(ctx context.Context, namespace, name string) (*Credentials, error)
+	// List returns the credential secrets labeled by ACM in namespace, or in every namespace
+	// if namespace is empty, sorted by namespace and name
+	List(ctx context.Context, namespace string) ([]CredentialInfo, error)
+	// Create stores creds as a credential secret in the format of the ACM console, so both
+	// labrat and ACM can provision with it
+	Create(ctx context.Context, creds *Credentials) error
+	// Delete deletes the named credential secret; other secrets are refused
+	Delete(ctx context.Context, namespace, name string) error
+}
+
+type credentialClient struct {
+	coreClient typedcorev1.CoreV1Interface
+	options    kube.Options
+}
+
+// NewCredentialClient creates a new CredentialClient
+func NewCredentialClient(coreClient typedcorev1.CoreV1Interface, options ...kube.Option) CredentialClient {
+	return &credentialClient{
+		coreClient: coreClient,
+		options:    kube.NewOptions(options...),
+	}
+}
+
+// Get reads the named credential secret and parses it according to its provider.
+// The provider is taken from the ACM type label when present, otherwise it is
+// inferred from the keys Hive expects for each provider.
+func (c *credentialClient) Get(ctx context.Context, namespace, name string) (*Credentials, error) {
+	secret, err := c.coreClient.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credential secret %s/%s: %w", namespace, name, err)
+	}
+
+	creds := &Credentials{
+		Name:       secret.Name,
+		Namespace:  secret.Namespace,
+		Provider:   normalizeProvider(secret.Labels[CredentialsTypeLabel]),
+		BaseDomain: string(secret.Data["baseDomain"]),
+		PullSecret: secret.Data["pullSecret"],
+
+		SSHPrivateKey: secret.Data["ssh-privatekey"],
+		SSHPublicKey:  strings.TrimSpace(string(secret.Data["ssh-publickey"])),
+	}
+
+	if creds.Provider == "" {
+		creds.Provider = inferProvider(secret.Data)
+	}
+
+	switch creds.Provider {
+	case ProviderAWS:
+		creds.AWS = &AWSCredentials{
+			AccessKeyID:     strings.TrimSpace(string(secret.Data["aws_access_key_id"])),
+			SecretAccessKey: strings.TrimSpace(string(secret.Data["aws_secret_access_key"])),
+		}
+	case ProviderAzure:
+		creds.Azure = &AzureCredentials{}
+		if data, ok := secret.Data["osServicePrincipal.json"]; ok {
+			if err := json.Unmarshal(data, creds.Azure); err != nil {
+				return nil, fmt.Errorf("failed to parse osServicePrincipal.json: %w", err)
+			}
+		}
+	case ProviderGCP:
+		creds.GCP = &GCPCredentials{}
+		if data, ok := secret.Data["osServiceAccount.json"]; ok {
+			if err := json.Unmarshal(data, creds.GCP); err != nil {
+				return nil, fmt.Errorf("failed to parse osServiceAccount.json: %w", err)
+			}
+			creds.GCP.KeyFile = data
+		}
+	case ProviderVSphere:
+		value := func(key string) string { return strings.TrimSpace(string(secret.Data[key])) }
+		creds.VSphere = &VSphereCredentials{
+			VCenter:          value("vCenter"),
+			Username:         value("username"),
+			Password:         value("password"),
+			CACertificate:    value("cacertificate"),
+			Datacenter:       value("datacenter"),
+			DefaultDatastore: value("defaultDatastore"),
+			Cluster:          value("cluster"),
+			Folder:           value("vsphereFolder"),
+		}
+	default:
+		return nil, fmt.Errorf("secret %s/%s is not a recognized cloud credential", namespace, name)
+	}
+
+	return creds, nil
+}
+
+// List returns the credential secrets labeled with CredentialsTypeLabel
+func (c *credentialClient) List(ctx context.Context, namespace string) ([]CredentialInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list credentials", "namespace", namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var secrets *corev1.SecretList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		secrets, err = c.coreClient.Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: CredentialsTypeLabel})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credential secrets: %w", err)
+	}
+
+	infos := make([]CredentialInfo, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		provider := normalizeProvider(secret.Labels[CredentialsTypeLabel])
+		if provider == "" {
+			provider = inferProvider(secret.Data)
+		}
+		if provider == "" {
+			continue
+		}
+		infos = append(infos, CredentialInfo{
+			Name:       secret.Name,
+			Namespace:  secret.Namespace,
+			Provider:   provider,
+			BaseDomain: string(secret.Data["baseDomain"]),
+			CreatedAt:  secret.CreationTimestamp.Time,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Namespace != infos[j].Namespace {
+			return infos[i].Namespace < infos[j].Namespace
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// Create validates creds and creates their secret, failing if it already exists
+func (c *credentialClient) Create(ctx context.Context, creds *Credentials) error {
+	ctx, cancel, err := c.options.Start(ctx, "create credentials", "namespace", creds.Namespace, "name", creds.Name)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if err := creds.Validate(); err != nil {
+		return fmt.Errorf("invalid %s credentials: %w", creds.Provider, err)
+	}
+	data, err := creds.secretData()
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      creds.Name,
+			Namespace: creds.Namespace,
+			Labels: map[string]string{
+				CredentialsTypeLabel: credentialTypes[creds.Provider],
+				CredentialsLabel:     "",
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+	_, err = c.coreClient.Secrets(creds.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("credential secret %s/%s already exists", creds.Namespace, creds.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create credential secret %s/%s: %w", creds.Namespace, creds.Name, err)
+	}
+	return nil
+}
+
+// Delete deletes the named secret after checking that it is a cloud credential
+func (c *credentialClient) Delete(ctx context.Context, namespace, name string) error {
+	ctx, cancel, err := c.options.Start(ctx, "delete credentials", "namespace", namespace, "name", name)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := c.Get(ctx, namespace, name); err != nil {
+		return err
+	}
+	if err := c.coreClient.Secrets(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("failed to delete credential secret %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// secretData returns the data of the credential secret of c, with the keys the ACM console uses
+func (c *Credentials) secretData() (map[string][]byte, error) {
+	data := map[string][]byte{}
+	switch c.Provider {
+	case ProviderAWS:
+		data["aws_access_key_id"] = []byte(c.AWS.AccessKeyID)
+		data["aws_secret_access_key"] = []byte(c.AWS.SecretAccessKey)
+	case ProviderAzure:
+		principal, err := json.Marshal(c.Azure)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal osServicePrincipal.json: %w", err)
+		}
+		data["osServicePrincipal.json"] = principal
+	case ProviderGCP:
+		key := c.GCP.KeyFile
+		if len(key) == 0 {
+			var err error
+			if key, err = json.Marshal(c.GCP); err != nil {
+				return nil, fmt.Errorf("failed to marshal osServiceAccount.json: %w", err)
+			}
+		}
+		data["osServiceAccount.json"] = key
+	case ProviderVSphere:
+		for key, value := range map[string]string{
+			"vCenter":          c.VSphere.VCenter,
+			"username":         c.VSphere.Username,
+			"password":         c.VSphere.Password,
+			"cacertificate":    c.VSphere.CACertificate,
+			"datacenter":       c.VSphere.Datacenter,
+			"defaultDatastore": c.VSphere.DefaultDatastore,
+			"cluster":          c.VSphere.Cluster,
+			"vsphereFolder":    c.VSphere.Folder,
+		} {
+			if value != "" {
+				data[key] = []byte(value)
+			}
+		}
+	}
+
+	if c.BaseDomain != "" {
+		data["baseDomain"] = []byte(c.BaseDomain)
+	}
+	if len(c.PullSecret) > 0 {
+		data["pullSecret"] = c.PullSecret
+	}
+	if len(c.SSHPrivateKey) > 0 {
+		data["ssh-privatekey"] = c.SSHPrivateKey
+	}
+	if c.SSHPublicKey != "" {
+		data["ssh-publickey"] = []byte(c.SSHPublicKey)
+	}
+	return data, nil
+}
+
+// Validate checks that the credentials contain every field the provider requires
+func (c *Credentials) Validate() error {
+	var missing []string
+
+	switch c.Provider {
+	case ProviderAWS:
+		if c.AWS == nil || c.AWS.AccessKeyID == "" {
+			missing = append(missing, "aws_access_key_id")
+		}
+		if c.AWS == nil || c.AWS.SecretAccessKey == "" {
+			missing = append(missing, "aws_secret_access_key")
+		}
+	case ProviderAzure:
+		azure := c.Azure
+		if azure == nil {
+			azure = &AzureCredentials{}
+		}
+		for field, value := range map[string]string{
+			"clientId":       azure.ClientID,
+			"clientSecret":   azure.ClientSecret,
+			"tenantId":       azure.TenantID,
+			"subscriptionId": azure.SubscriptionID,
+		} {
+			if value == "" {
+				missing = append(missing, "osServicePrincipal.json:"+field)
+			}
+		}
+	case ProviderGCP:
+		gcp := c.GCP
+		if gcp == nil {
+			gcp = &GCPCredentials{}
+		}
+		for field, value := range map[string]string{
+			"project_id":   gcp.ProjectID,
+			"client_email": gcp.ClientEmail,
+			"private_key":  gcp.PrivateKey,
+		} {
+			if value == "" {
+				missing = append(missing, "osServiceAccount.json:"+field)
+			}
+		}
+	case ProviderVSphere:
+		vsphere := c.VSphere
+		if vsphere == nil {
+			vsphere = &VSphereCredentials{}
+		}
+		for field, value := range map[string]string{
+			"vCenter":  vsphere.VCenter,
+			"username": vsphere.Username,
+			"password": vsphere.Password,
+		} {
+			if value == "" {
+				missing = append(missing, field)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported provider %q", c.Provider)
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// normalizeProvider maps ACM credential type label values to provider names
+func normalizeProvider(label string) string {
+	switch strings.ToLower(label) {
+	case "aws":
+		return ProviderAWS
+	case "azr", "azure":
+		return ProviderAzure
+	case "gcp":
+		return ProviderGCP
+	case "vmw", "vsphere":
+		return ProviderVSphere
+	default:
+		return ""
+	}
+}
+
+// inferProvider detects the provider from the keys Hive expects in credential secrets
+func inferProvider(data map[string][]byte) string {
+	switch {
+	case data["aws_access_key_id"] != nil:
+		return ProviderAWS
+	case data["osServicePrincipal.json"] != nil:
+		return ProviderAzure
+	case data["osServiceAccount.json"] != nil:
+		return ProviderGCP
+	case data["vCenter"] != nil:
+		return ProviderVSphere
+	default:
+		return ""
+	}
+}
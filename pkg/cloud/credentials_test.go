@@ -0,0 +1,198 @@
+//go:build test
+
+package cloud_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("CredentialClient", func() {
+	newSecret := func(name string, labels map[string]string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "creds", Labels: labels},
+			Data:       data,
+		}
+	}
+
+	It("should parse ACM AWS credentials", func() {
+		clientset := fake.NewSimpleClientset(newSecret("aws-lab",
+			map[string]string{cloud.CredentialsTypeLabel: "aws"},
+			map[string][]byte{
+				"aws_access_key_id":     []byte("AKIAEXAMPLE\n"),
+				"aws_secret_access_key": []byte("secret"),
+				"baseDomain":            []byte("labs.example.com"),
+				"pullSecret":            []byte(`{"auths":{}}`),
+				"ssh-privatekey":        []byte("PRIVATE KEY"),
+				"ssh-publickey":         []byte("ssh-ed25519 AAAA lab\n"),
+			}))
+
+		creds, err := cloud.NewCredentialClient(clientset.CoreV1()).Get(context.Background(), "creds", "aws-lab")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.Provider).To(Equal(cloud.ProviderAWS))
+		Expect(creds.AWS.AccessKeyID).To(Equal("AKIAEXAMPLE"))
+		Expect(creds.BaseDomain).To(Equal("labs.example.com"))
+		Expect(creds.PullSecret).To(MatchJSON(`{"auths":{}}`))
+		Expect(creds.SSHPrivateKey).To(Equal([]byte("PRIVATE KEY")))
+		Expect(creds.SSHPublicKey).To(Equal("ssh-ed25519 AAAA lab"))
+		Expect(creds.Validate()).To(Succeed())
+	})
+
+	It("should map the ACM azr label and parse the service principal", func() {
+		clientset := fake.NewSimpleClientset(newSecret("azure-lab",
+			map[string]string{cloud.CredentialsTypeLabel: "azr"},
+			map[string][]byte{
+				"osServicePrincipal.json": []byte(`{"clientId":"id","clientSecret":"s","tenantId":"t","subscriptionId":"sub"}`),
+			}))
+
+		creds, err := cloud.NewCredentialClient(clientset.CoreV1()).Get(context.Background(), "creds", "azure-lab")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.Provider).To(Equal(cloud.ProviderAzure))
+		Expect(creds.Azure.SubscriptionID).To(Equal("sub"))
+		Expect(creds.Validate()).To(Succeed())
+	})
+
+	It("should infer the provider of unlabeled Hive secrets", func() {
+		clientset := fake.NewSimpleClientset(newSecret("gcp-lab", nil, map[string][]byte{
+			"osServiceAccount.json": []byte(`{"project_id":"lab-project"}`),
+		}))
+
+		creds, err := cloud.NewCredentialClient(clientset.CoreV1()).Get(context.Background(), "creds", "gcp-lab")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.Provider).To(Equal(cloud.ProviderGCP))
+		Expect(creds.GCP.ProjectID).To(Equal("lab-project"))
+		Expect(creds.Validate()).To(MatchError("missing osServiceAccount.json:client_email, osServiceAccount.json:private_key"))
+	})
+
+	It("should map the ACM vmw label and parse the vCenter account", func() {
+		clientset := fake.NewSimpleClientset(newSecret("vsphere-lab",
+			map[string]string{cloud.CredentialsTypeLabel: "vmw"},
+			map[string][]byte{
+				"vCenter":          []byte("vcenter.lab.example.com"),
+				"username":         []byte("labrat@vsphere.local"),
+				"password":         []byte("secret\n"),
+				"cacertificate":    []byte("CERTIFICATE"),
+				"datacenter":       []byte("dc1"),
+				"defaultDatastore": []byte("ds1"),
+				"cluster":          []byte("cluster1"),
+				"vsphereFolder":    []byte("/dc1/vm/labs"),
+			}))
+
+		creds, err := cloud.NewCredentialClient(clientset.CoreV1()).Get(context.Background(), "creds", "vsphere-lab")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.Provider).To(Equal(cloud.ProviderVSphere))
+		Expect(*creds.VSphere).To(Equal(cloud.VSphereCredentials{
+			VCenter:          "vcenter.lab.example.com",
+			Username:         "labrat@vsphere.local",
+			Password:         "secret",
+			CACertificate:    "CERTIFICATE",
+			Datacenter:       "dc1",
+			DefaultDatastore: "ds1",
+			Cluster:          "cluster1",
+			Folder:           "/dc1/vm/labs",
+		}))
+		Expect(creds.Validate()).To(Succeed())
+	})
+
+	It("should infer vSphere credentials and require the vCenter account", func() {
+		clientset := fake.NewSimpleClientset(newSecret("vsphere-lab", nil, map[string][]byte{
+			"vCenter": []byte("vcenter.lab.example.com"),
+		}))
+
+		creds, err := cloud.NewCredentialClient(clientset.CoreV1()).Get(context.Background(), "creds", "vsphere-lab")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.Provider).To(Equal(cloud.ProviderVSphere))
+		Expect(creds.Validate()).To(MatchError("missing password, username"))
+	})
+
+	It("should reject secrets that are not cloud credentials", func() {
+		clientset := fake.NewSimpleClientset(newSecret("other", nil, map[string][]byte{"token": []byte("x")}))
+
+		_, err := cloud.NewCredentialClient(clientset.CoreV1()).Get(context.Background(), "creds", "other")
+		Expect(err).To(MatchError(ContainSubstring("not a recognized cloud credential")))
+	})
+
+	It("should return an error when the secret does not exist", func() {
+		clientset := fake.NewSimpleClientset()
+
+		_, err := cloud.NewCredentialClient(clientset.CoreV1()).Get(context.Background(), "creds", "missing")
+		Expect(err).To(MatchError(ContainSubstring("failed to get credential secret creds/missing")))
+	})
+
+	It("should list the labeled credential secrets", func() {
+		clientset := fake.NewSimpleClientset(
+			newSecret("gcp-lab", map[string]string{cloud.CredentialsTypeLabel: "gcp"}, nil),
+			newSecret("aws-lab", map[string]string{cloud.CredentialsTypeLabel: "aws"}, map[string][]byte{
+				"baseDomain": []byte("labs.example.com"),
+			}),
+			newSecret("token", nil, map[string][]byte{"token": []byte("x")}),
+		)
+
+		infos, err := cloud.NewCredentialClient(clientset.CoreV1()).List(context.Background(), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(infos).To(HaveLen(2))
+		Expect(infos[0].Name).To(Equal("aws-lab"))
+		Expect(infos[0].Provider).To(Equal(cloud.ProviderAWS))
+		Expect(infos[0].BaseDomain).To(Equal("labs.example.com"))
+		Expect(infos[1].Name).To(Equal("gcp-lab"))
+	})
+
+	It("should create credential secrets in the format of the ACM console", func() {
+		clientset := fake.NewSimpleClientset()
+		client := cloud.NewCredentialClient(clientset.CoreV1())
+		ctx := context.Background()
+
+		key := []byte(`{"type":"service_account","project_id":"lab-project","client_email":"labrat@lab-project.iam.gserviceaccount.com","private_key":"KEY"}`)
+		Expect(client.Create(ctx, &cloud.Credentials{
+			Name:       "gcp-lab",
+			Namespace:  "creds",
+			Provider:   cloud.ProviderGCP,
+			BaseDomain: "labs.example.com",
+			GCP:        &cloud.GCPCredentials{ProjectID: "lab-project", ClientEmail: "labrat", PrivateKey: "KEY", KeyFile: key},
+		})).To(Succeed())
+
+		secret, err := clientset.CoreV1().Secrets("creds").Get(ctx, "gcp-lab", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.Labels).To(HaveKeyWithValue(cloud.CredentialsTypeLabel, "gcp"))
+		Expect(secret.Labels).To(HaveKey(cloud.CredentialsLabel))
+		Expect(secret.Data).To(HaveKeyWithValue("osServiceAccount.json", key))
+		Expect(secret.Data).To(HaveKeyWithValue("baseDomain", []byte("labs.example.com")))
+
+		creds, err := client.Get(ctx, "creds", "gcp-lab")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.Validate()).To(Succeed())
+
+		err = client.Create(ctx, creds)
+		Expect(err).To(MatchError("credential secret creds/gcp-lab already exists"))
+	})
+
+	It("should not create incomplete credentials", func() {
+		clientset := fake.NewSimpleClientset()
+
+		err := cloud.NewCredentialClient(clientset.CoreV1()).Create(context.Background(), &cloud.Credentials{
+			Name: "aws-lab", Namespace: "creds", Provider: cloud.ProviderAWS, AWS: &cloud.AWSCredentials{AccessKeyID: "AKIAEXAMPLE"},
+		})
+		Expect(err).To(MatchError("invalid aws credentials: missing aws_secret_access_key"))
+	})
+
+	It("should delete only cloud credential secrets", func() {
+		clientset := fake.NewSimpleClientset(
+			newSecret("aws-lab", map[string]string{cloud.CredentialsTypeLabel: "aws"}, nil),
+			newSecret("other", nil, map[string][]byte{"token": []byte("x")}),
+		)
+		client := cloud.NewCredentialClient(clientset.CoreV1())
+		ctx := context.Background()
+
+		Expect(client.Delete(ctx, "creds", "aws-lab")).To(Succeed())
+		_, err := clientset.CoreV1().Secrets("creds").Get(ctx, "aws-lab", metav1.GetOptions{})
+		Expect(err).To(HaveOccurred())
+
+		Expect(client.Delete(ctx, "creds", "other")).To(MatchError(ContainSubstring("not a recognized cloud credential")))
+	})
+})
@@ -0,0 +1,187 @@
+//go:build test
+
+package cloud_test
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	r53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	sqtypes "github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+)
+
+// fakeAWS implements the AWS API subsets used by labrat
+type fakeAWS struct {
+	callerARN    string
+	identityErr  error
+	regions      []string
+	deniedAction string
+	simulateErr  error
+	simulatedARN string
+
+	zones         []string
+	instanceTypes map[string]int32
+	runningVCPUs  []int32
+	addresses     int
+	vpcs          int
+	quotas        map[string]float64
+	quotaErr      error
+	offerings     map[string][]string
+	hostedZones   []r53types.HostedZone
+	records       []string
+}
+
+func (f *fakeAWS) GetCallerIdentity(_ context.Context, _ *sts.GetCallerIdentityInput, _ ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	if f.identityErr != nil {
+		return nil, f.identityErr
+	}
+	return &sts.GetCallerIdentityOutput{Arn: aws.String(f.callerARN), Account: aws.String("123456789012")}, nil
+}
+
+func (f *fakeAWS) DescribeRegions(_ context.Context, _ *ec2.DescribeRegionsInput, _ ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error) {
+	out := &ec2.DescribeRegionsOutput{}
+	for _, r := range f.regions {
+		out.Regions = append(out.Regions, ec2types.Region{RegionName: aws.String(r)})
+	}
+	return out, nil
+}
+
+func (f *fakeAWS) SimulatePrincipalPolicy(_ context.Context, in *iam.SimulatePrincipalPolicyInput, _ ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	if f.simulateErr != nil {
+		return nil, f.simulateErr
+	}
+	f.simulatedARN = aws.ToString(in.PolicySourceArn)
+	out := &iam.SimulatePrincipalPolicyOutput{}
+	for _, action := range in.ActionNames {
+		decision := iamtypes.PolicyEvaluationDecisionTypeAllowed
+		if action == f.deniedAction {
+			decision = iamtypes.PolicyEvaluationDecisionTypeImplicitDeny
+		}
+		out.EvaluationResults = append(out.EvaluationResults, iamtypes.EvaluationResult{
+			EvalActionName: aws.String(action),
+			EvalDecision:   decision,
+		})
+	}
+	return out, nil
+}
+
+func (f *fakeAWS) DescribeAvailabilityZones(_ context.Context, _ *ec2.DescribeAvailabilityZonesInput, _ ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	out := &ec2.DescribeAvailabilityZonesOutput{}
+	for _, z := range f.zones {
+		out.AvailabilityZones = append(out.AvailabilityZones, ec2types.AvailabilityZone{ZoneName: aws.String(z)})
+	}
+	return out, nil
+}
+
+func (f *fakeAWS) DescribeInstanceTypeOfferings(_ context.Context, in *ec2.DescribeInstanceTypeOfferingsInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	out := &ec2.DescribeInstanceTypeOfferingsOutput{}
+	for instanceType, zones := range f.offerings {
+		matched := false
+		for _, pattern := range in.Filters[0].Values {
+			if ok, _ := path.Match(pattern, instanceType); ok {
+				matched = true
+			}
+		}
+		if !matched {
+			continue
+		}
+		for _, zone := range zones {
+			out.InstanceTypeOfferings = append(out.InstanceTypeOfferings, ec2types.InstanceTypeOffering{
+				InstanceType: ec2types.InstanceType(instanceType),
+				Location:     aws.String(zone),
+			})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAWS) DescribeInstanceTypes(_ context.Context, in *ec2.DescribeInstanceTypesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	out := &ec2.DescribeInstanceTypesOutput{}
+	for _, t := range in.InstanceTypes {
+		vcpus, ok := f.instanceTypes[string(t)]
+		if !ok {
+			return nil, fmt.Errorf("InvalidInstanceType: %s", t)
+		}
+		out.InstanceTypes = append(out.InstanceTypes, ec2types.InstanceTypeInfo{
+			InstanceType: t,
+			VCpuInfo:     &ec2types.VCpuInfo{DefaultVCpus: aws.Int32(vcpus)},
+		})
+	}
+	return out, nil
+}
+
+func (f *fakeAWS) DescribeInstances(_ context.Context, _ *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	reservation := ec2types.Reservation{}
+	for _, vcpus := range f.runningVCPUs {
+		reservation.Instances = append(reservation.Instances, ec2types.Instance{
+			InstanceType: ec2types.InstanceTypeM6iXlarge,
+			CpuOptions:   &ec2types.CpuOptions{CoreCount: aws.Int32(vcpus / 2), ThreadsPerCore: aws.Int32(2)},
+		})
+	}
+	return &ec2.DescribeInstancesOutput{Reservations: []ec2types.Reservation{reservation}}, nil
+}
+
+func (f *fakeAWS) DescribeAddresses(_ context.Context, _ *ec2.DescribeAddressesInput, _ ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	return &ec2.DescribeAddressesOutput{Addresses: make([]ec2types.Address, f.addresses)}, nil
+}
+
+func (f *fakeAWS) DescribeVpcs(_ context.Context, _ *ec2.DescribeVpcsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	return &ec2.DescribeVpcsOutput{Vpcs: make([]ec2types.Vpc, f.vpcs)}, nil
+}
+
+func (f *fakeAWS) GetServiceQuota(_ context.Context, in *servicequotas.GetServiceQuotaInput, _ ...func(*servicequotas.Options)) (*servicequotas.GetServiceQuotaOutput, error) {
+	if f.quotaErr != nil {
+		return nil, f.quotaErr
+	}
+	value, ok := f.quotas[aws.ToString(in.QuotaCode)]
+	if !ok {
+		return nil, fmt.Errorf("NoSuchResourceException: %s", aws.ToString(in.QuotaCode))
+	}
+	return &servicequotas.GetServiceQuotaOutput{Quota: &sqtypes.ServiceQuota{Value: aws.Float64(value)}}, nil
+}
+
+func (f *fakeAWS) ListHostedZonesByName(_ context.Context, _ *route53.ListHostedZonesByNameInput, _ ...func(*route53.Options)) (*route53.ListHostedZonesByNameOutput, error) {
+	return &route53.ListHostedZonesByNameOutput{HostedZones: f.hostedZones}, nil
+}
+
+func (f *fakeAWS) ListResourceRecordSets(_ context.Context, in *route53.ListResourceRecordSetsInput, _ ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error) {
+	out := &route53.ListResourceRecordSetsOutput{}
+	for _, name := range f.records {
+		if name >= aws.ToString(in.StartRecordName) {
+			out.ResourceRecordSets = append(out.ResourceRecordSets, r53types.ResourceRecordSet{Name: aws.String(name)})
+			break
+		}
+	}
+	return out, nil
+}
+
+// clients returns AWS clients backed by the fake
+func (f *fakeAWS) clients(_ *cloud.AWSCredentials, _ string) *cloud.AWSClients {
+	return &cloud.AWSClients{STS: f, EC2: f, IAM: f, Route53: f, ServiceQuotas: f}
+}
+
+// resultOf returns the result of the named check in report
+func resultOf(report check.Report, name string) check.Result {
+	for _, r := range report.Results {
+		if r.Name == name {
+			return r
+		}
+	}
+	return check.Result{}
+}
+
+// statusOf returns the status of the named check in report
+func statusOf(report check.Report, name string) check.Status {
+	return resultOf(report, name).Status
+}
@@ -0,0 +1,80 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+// gcpAggregatedMachineTypes lists machine types by the zone that offers them, keyed zones/<zone>
+type gcpAggregatedMachineTypes struct {
+	Items map[string]struct {
+		MachineTypes []struct {
+			Name string `json:"name"`
+		} `json:"machineTypes"`
+	} `json:"items"`
+}
+
+// runGCPAvailabilityChecks verifies that each requested machine type is offered in the zones
+// of the region, and in every requested zone if any
+func runGCPAvailabilityChecks(ctx context.Context, report *check.Report, client *http.Client, creds *GCPCredentials, spec ClusterSpec) {
+	machineTypes := []string{valueOrDefault(spec.ControlPlane.InstanceType, DefaultGCPMachineType)}
+	for _, pool := range append([]MachinePool{spec.Compute}, spec.AdditionalCompute...) {
+		if machineType := valueOrDefault(pool.InstanceType, DefaultGCPMachineType); !slices.Contains(machineTypes, machineType) {
+			machineTypes = append(machineTypes, machineType)
+		}
+	}
+
+	for _, machineType := range machineTypes {
+		report.Run(fmt.Sprintf("GCP machine type %s", machineType), func() (check.Status, string) {
+			// Custom machine types are not listed, any zone of a family offering them accepts them
+			if strings.Contains(machineType, "-custom-") {
+				return check.StatusPass, "custom machine type, not listed by Compute Engine"
+			}
+			offered, err := gcpMachineTypeZones(ctx, client, creds.ProjectID, spec.Region, machineType)
+			if err != nil {
+				return check.StatusWarn, fmt.Sprintf("failed to list machine types: %v", err)
+			}
+			if len(offered) == 0 {
+				return check.StatusFail, fmt.Sprintf("%s is not offered in %s", machineType, spec.Region)
+			}
+
+			var missing []string
+			for _, zone := range spec.Zones {
+				if !slices.Contains(offered, zone) {
+					missing = append(missing, zone)
+				}
+			}
+			if len(missing) > 0 {
+				return check.StatusFail, fmt.Sprintf("%s is not offered in %s (offered in %s)", machineType, strings.Join(missing, ", "), strings.Join(offered, ", "))
+			}
+			return check.StatusPass, fmt.Sprintf("offered in %s", strings.Join(offered, ", "))
+		})
+	}
+}
+
+// gcpMachineTypeZones returns the zones of region that offer machineType
+func gcpMachineTypeZones(ctx context.Context, client *http.Client, projectID, region, machineType string) ([]string, error) {
+	var aggregated gcpAggregatedMachineTypes
+	endpoint := fmt.Sprintf("%s/projects/%s/aggregated/machineTypes?filter=%s",
+		gcpComputeEndpoint, url.PathEscape(projectID), url.QueryEscape(fmt.Sprintf("name = %q", machineType)))
+	if err := getJSON(ctx, client, endpoint, &aggregated); err != nil {
+		return nil, err
+	}
+
+	var zones []string
+	for scope, item := range aggregated.Items {
+		zone := strings.TrimPrefix(scope, "zones/")
+		if strings.HasPrefix(zone, region+"-") && len(item.MachineTypes) > 0 {
+			zones = append(zones, zone)
+		}
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
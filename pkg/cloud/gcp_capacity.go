@@ -0,0 +1,116 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	// DefaultGCPMachineType is the installer's default machine type for control plane and compute machines
+	DefaultGCPMachineType = "n2-standard-4"
+
+	gcpComputeEndpoint  = "https://compute.googleapis.com/compute/v1"
+	gcpTokenURL         = "https://oauth2.googleapis.com/token"
+	gcpReadOnlyScope    = "https://www.googleapis.com/auth/compute.readonly"
+	gcpDNSEndpoint      = "https://dns.googleapis.com/dns/v1"
+	gcpDNSReadOnlyScope = "https://www.googleapis.com/auth/ndev.clouddns.readonly"
+
+	// gcpDiskSizeGB is the size of the pd-ssd boot disk the installer gives every machine
+	gcpDiskSizeGB = 128
+)
+
+// gcpRegion is the part of a Compute Engine region resource with its quotas
+type gcpRegion struct {
+	Quotas []struct {
+		Metric string  `json:"metric"`
+		Limit  float64 `json:"limit"`
+		Usage  float64 `json:"usage"`
+	} `json:"quotas"`
+}
+
+// readGCPCapacity reads the regional CPU and SSD quotas of the project of the service account
+// and compares them with the control plane, compute, and bootstrap machines of spec. The
+// CPUs of a machine family with its own quota, e.g. N2_CPUS, count against that quota too.
+func (r *capacityReader) readGCPCapacity(ctx context.Context, creds *GCPCredentials, region string, spec ClusterSpec) (*Capacity, error) {
+	if region == "" {
+		return nil, fmt.Errorf("a region is required to read the quotas of GCP projects")
+	}
+
+	controlPlaneType := valueOrDefault(spec.ControlPlane.InstanceType, DefaultGCPMachineType)
+	pools := []MachinePool{
+		{InstanceType: controlPlaneType, Replicas: spec.ControlPlane.Replicas},
+		{InstanceType: valueOrDefault(spec.Compute.InstanceType, DefaultGCPMachineType), Replicas: spec.Compute.Replicas},
+		{InstanceType: controlPlaneType, Replicas: 1},
+	}
+	vcpus, machines := 0, 0
+	familyVCPUs := make(map[string]int)
+	for _, pool := range pools {
+		n, err := gcpMachineTypeVCPUs(pool.InstanceType)
+		if err != nil {
+			return nil, err
+		}
+		vcpus += n * pool.Replicas
+		machines += pool.Replicas
+		family, _, _ := strings.Cut(pool.InstanceType, "-")
+		familyVCPUs[strings.ToUpper(family)+"_CPUS"] += n * pool.Replicas
+	}
+
+	var regionInfo gcpRegion
+	endpoint := fmt.Sprintf("%s/projects/%s/regions/%s", gcpComputeEndpoint, url.PathEscape(creds.ProjectID), url.PathEscape(region))
+	if err := getJSON(ctx, gcpClient(ctx, r.httpClient, creds, gcpReadOnlyScope), endpoint, &regionInfo); err != nil {
+		return nil, fmt.Errorf("failed to read quotas of region %s: %w", region, err)
+	}
+
+	capacity := &Capacity{Provider: ProviderGCP, Account: creds.ProjectID, Region: region}
+	for _, quota := range regionInfo.Quotas {
+		used, limit := int(quota.Usage), int(quota.Limit)
+		switch {
+		case quota.Metric == "CPUS":
+			capacity.Quotas = append(capacity.Quotas, Quota{Name: "vCPUs", Used: used, Limit: limit, PerCluster: vcpus})
+		case familyVCPUs[quota.Metric] > 0:
+			name := strings.TrimSuffix(quota.Metric, "_CPUS") + " vCPUs"
+			capacity.Quotas = append(capacity.Quotas, Quota{Name: name, Used: used, Limit: limit, PerCluster: familyVCPUs[quota.Metric]})
+		case quota.Metric == "SSD_TOTAL_GB":
+			capacity.Quotas = append(capacity.Quotas, Quota{Name: "SSD GB", Used: used, Limit: limit, PerCluster: gcpDiskSizeGB * machines})
+		}
+	}
+	return capacity, nil
+}
+
+// gcpMachineTypeVCPUs returns the vCPUs of a predefined or custom machine type from its name,
+// e.g. 8 for n2-standard-8 and n2-custom-8-32768
+func gcpMachineTypeVCPUs(machineType string) (int, error) {
+	parts := strings.Split(machineType, "-")
+	for i, part := range parts {
+		if part == "custom" && i+1 < len(parts) {
+			if n, err := strconv.Atoi(parts[i+1]); err == nil && n > 0 {
+				return n, nil
+			}
+		}
+	}
+	for i := len(parts) - 1; i > 0; i-- {
+		if n, err := strconv.Atoi(parts[i]); err == nil && n > 0 {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot tell the vCPUs of GCP machine type %s", machineType)
+}
+
+// gcpClient returns an HTTP client authenticated to the Google Cloud APIs of scopes as the
+// service account of creds, sending its requests through httpClient
+func gcpClient(ctx context.Context, httpClient *http.Client, creds *GCPCredentials, scopes ...string) *http.Client {
+	config := &jwt.Config{
+		Email:      creds.ClientEmail,
+		PrivateKey: []byte(creds.PrivateKey),
+		Scopes:     scopes,
+		TokenURL:   gcpTokenURL,
+	}
+	return config.Client(context.WithValue(ctx, oauth2.HTTPClient, httpClient))
+}
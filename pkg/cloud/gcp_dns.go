@@ -0,0 +1,96 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+// gcpManagedZones is the list of the Cloud DNS managed zones of a DNS name
+type gcpManagedZones struct {
+	ManagedZones []struct {
+		Name       string `json:"name"`
+		DNSName    string `json:"dnsName"`
+		Visibility string `json:"visibility"`
+	} `json:"managedZones"`
+}
+
+// gcpRecordSets is the list of the record sets of a name in a managed zone
+type gcpRecordSets struct {
+	RRSets []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"rrsets"`
+}
+
+// runGCPDNSChecks verifies that the base domain has a public Cloud DNS managed zone in the
+// project and that the records the installer creates for the cluster do not already exist
+func runGCPDNSChecks(ctx context.Context, report *check.Report, client *http.Client, creds *GCPCredentials, spec ClusterSpec) {
+	var zone string
+	result := report.Run("GCP base domain", func() (check.Status, string) {
+		if spec.BaseDomain == "" {
+			return check.StatusFail, "no base domain set: pass --base-domain or add baseDomain to the credential secret"
+		}
+
+		name, err := gcpPublicManagedZone(ctx, client, creds.ProjectID, spec.BaseDomain)
+		if err != nil {
+			return check.StatusFail, fmt.Sprintf("failed to look up managed zones: %v", err)
+		}
+		if name == "" {
+			return check.StatusFail, fmt.Sprintf("no public Cloud DNS managed zone for %s in project %s: create one and delegate it with NS records in the parent domain",
+				spec.BaseDomain, creds.ProjectID)
+		}
+		zone = name
+		return check.StatusPass, fmt.Sprintf("%s (%s)", spec.BaseDomain, zone)
+	})
+	if result.Status == check.StatusFail {
+		return
+	}
+
+	report.Run("GCP cluster DNS records", func() (check.Status, string) {
+		if spec.Name == "" {
+			return check.StatusWarn, "cluster name not set, skipping record check"
+		}
+
+		clusterDomain := fmt.Sprintf("%s.%s", spec.Name, spec.BaseDomain)
+		var existing []string
+		for _, name := range []string{"api." + clusterDomain, "*.apps." + clusterDomain} {
+			var records gcpRecordSets
+			endpoint := fmt.Sprintf("%s/projects/%s/managedZones/%s/rrsets?name=%s",
+				gcpDNSEndpoint, url.PathEscape(creds.ProjectID), url.PathEscape(zone), url.QueryEscape(toFQDN(name)))
+			if err := getJSON(ctx, client, endpoint, &records); err != nil {
+				return check.StatusWarn, fmt.Sprintf("failed to list records in %s: %v", zone, err)
+			}
+			if len(records.RRSets) > 0 {
+				existing = append(existing, name)
+			}
+		}
+
+		if len(existing) > 0 {
+			return check.StatusFail, fmt.Sprintf("records already exist: %s. A previous cluster named %s may not have been deprovisioned: deprovision it, delete the stale records, or choose another name",
+				strings.Join(existing, ", "), spec.Name)
+		}
+		return check.StatusPass, fmt.Sprintf("api and *.apps records for %s are free", clusterDomain)
+	})
+}
+
+// gcpPublicManagedZone returns the name of the public managed zone for domain in the project,
+// or "" if there is none
+func gcpPublicManagedZone(ctx context.Context, client *http.Client, projectID, domain string) (string, error) {
+	var zones gcpManagedZones
+	endpoint := fmt.Sprintf("%s/projects/%s/managedZones?dnsName=%s", gcpDNSEndpoint, url.PathEscape(projectID), url.QueryEscape(toFQDN(domain)))
+	if err := getJSON(ctx, client, endpoint, &zones); err != nil {
+		return "", err
+	}
+	for _, zone := range zones.ManagedZones {
+		// Zones created before visibility was introduced have none and are public
+		if strings.EqualFold(zone.DNSName, toFQDN(domain)) && (zone.Visibility == "" || zone.Visibility == "public") {
+			return zone.Name, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,122 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+const (
+	// PreflightReportName is the report (and JUnit test suite) name used by provisioning preflights
+	PreflightReportName = "labrat.spoke.preflight"
+
+	// DefaultControlPlaneReplicas is the number of control plane machines the installer creates
+	DefaultControlPlaneReplicas = 3
+	// DefaultComputeReplicas is the number of compute machines the installer creates
+	DefaultComputeReplicas = 3
+)
+
+// MachinePool describes a set of identical machines in a planned cluster
+type MachinePool struct {
+	// InstanceType is the provider instance type; empty means the provider default
+	InstanceType string
+	// Replicas is the number of machines
+	Replicas int
+}
+
+// ClusterSpec describes the cloud footprint of a cluster that is about to be provisioned
+type ClusterSpec struct {
+	// Name is the cluster name
+	Name string
+	// Region is the region the cluster is provisioned into
+	Region string
+	// Zones restricts the cluster to specific availability zones; empty means every zone in the region
+	Zones []string
+	// BaseDomain is the DNS domain the cluster's records are created under
+	BaseDomain string
+	// ControlPlane is the control plane machine pool
+	ControlPlane MachinePool
+	// Compute is the default compute machine pool
+	Compute MachinePool
+	// AdditionalCompute are further compute machine pools, e.g. GPU workers
+	AdditionalCompute []MachinePool
+}
+
+// Preflight checks that a cloud account can host a cluster before anything is provisioned
+type Preflight interface {
+	// Run executes the preflight checks for spec using creds and returns their results
+	Run(ctx context.Context, creds *Credentials, spec ClusterSpec) check.Report
+}
+
+type preflight struct {
+	newAWSClients AWSClientFactory
+	httpClient    *http.Client
+}
+
+// NewPreflight creates a new Preflight. If newAWSClients is nil, NewAWSClients is used; if
+// httpClient is nil, a client with a 30 second timeout calls the GCP and Azure APIs.
+func NewPreflight(newAWSClients AWSClientFactory, httpClient *http.Client) Preflight {
+	if newAWSClients == nil {
+		newAWSClients = NewAWSClients
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &preflight{
+		newAWSClients: newAWSClients,
+		httpClient:    httpClient,
+	}
+}
+
+// Run executes the preflight checks supported by the credential's provider
+func (p *preflight) Run(ctx context.Context, creds *Credentials, spec ClusterSpec) check.Report {
+	report := check.Report{Name: PreflightReportName}
+
+	if spec.ControlPlane.Replicas <= 0 {
+		spec.ControlPlane.Replicas = DefaultControlPlaneReplicas
+	}
+
+	switch creds.Provider {
+	case ProviderAWS:
+		if spec.Region == "" {
+			spec.Region = DefaultAWSRegion
+		}
+		clients := p.newAWSClients(creds.AWS, spec.Region)
+		runAWSAvailabilityChecks(ctx, &report, clients.EC2, spec)
+		runAWSQuotaChecks(ctx, &report, clients, spec)
+		runAWSDNSChecks(ctx, &report, clients.Route53, spec)
+	case ProviderAzure:
+		client := azureClient(ctx, p.httpClient, creds.Azure)
+		if regionChecked(&report, "Azure", spec) {
+			runAzureAvailabilityChecks(ctx, &report, client, creds.Azure, spec)
+		}
+		runAzureDNSChecks(ctx, &report, client, creds.Azure, spec)
+	case ProviderGCP:
+		client := gcpClient(ctx, p.httpClient, creds.GCP, gcpReadOnlyScope, gcpDNSReadOnlyScope)
+		if regionChecked(&report, "GCP", spec) {
+			runGCPAvailabilityChecks(ctx, &report, client, creds.GCP, spec)
+		}
+		runGCPDNSChecks(ctx, &report, client, creds.GCP, spec)
+	default:
+		report.Run("Cloud preflight", func() (check.Status, string) {
+			return check.StatusWarn, fmt.Sprintf("preflight checks are not supported for %s yet", creds.Provider)
+		})
+	}
+
+	return report
+}
+
+// regionChecked reports whether spec has a region to check the machine types of the provider
+// in; without one, the missing region is reported as a failure
+func regionChecked(report *check.Report, provider string, spec ClusterSpec) bool {
+	if spec.Region != "" {
+		return true
+	}
+	report.Run(provider+" region", func() (check.Status, string) {
+		return check.StatusFail, "no region set: pass --region or set defaults.spoke.region in the config"
+	})
+	return false
+}
@@ -0,0 +1,440 @@
+//go:build test
+
+package cloud_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	r53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+)
+
+var _ = Describe("Preflight", func() {
+	var (
+		fake      *fakeAWS
+		preflight cloud.Preflight
+		creds     *cloud.Credentials
+		spec      cloud.ClusterSpec
+	)
+
+	BeforeEach(func() {
+		fake = &fakeAWS{
+			zones:         []string{"us-east-2a", "us-east-2b", "us-east-2c"},
+			instanceTypes: map[string]int32{"m6i.xlarge": 4, "m6i.2xlarge": 8, "p4d.24xlarge": 96},
+			offerings: map[string][]string{
+				"m6i.xlarge":   {"us-east-2a", "us-east-2b", "us-east-2c"},
+				"m6i.2xlarge":  {"us-east-2a", "us-east-2b", "us-east-2c"},
+				"p4d.24xlarge": {"us-east-2a", "us-east-2b", "us-east-2c"},
+			},
+			runningVCPUs: []int32{4, 4},
+			addresses:    1,
+			vpcs:         2,
+			hostedZones: []r53types.HostedZone{
+				{Id: aws.String("/hostedzone/ZPRIVATE"), Name: aws.String("labs.example.com."), Config: &r53types.HostedZoneConfig{PrivateZone: true}},
+				{Id: aws.String("/hostedzone/ZPUBLIC"), Name: aws.String("labs.example.com."), Config: &r53types.HostedZoneConfig{}},
+			},
+			records: []string{"api.other-lab.labs.example.com.", "labs.example.com."},
+			quotas: map[string]float64{
+				"L-1216C47A": 64,
+				"L-0263D0A3": 5,
+				"L-F678F1CE": 5,
+			},
+		}
+		preflight = cloud.NewPreflight(fake.clients, nil)
+		creds = &cloud.Credentials{
+			Provider: cloud.ProviderAWS,
+			AWS:      &cloud.AWSCredentials{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret"},
+		}
+		spec = cloud.ClusterSpec{
+			Name:       "partner-lab",
+			Region:     "us-east-2",
+			BaseDomain: "labs.example.com",
+			Compute:    cloud.MachinePool{Replicas: 3},
+		}
+	})
+
+	Describe("AWS quotas", func() {
+		It("should pass when the account has room for the cluster", func() {
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(report.Name).To(Equal(cloud.PreflightReportName))
+			Expect(report.Failed()).To(BeFalse())
+			Expect(resultOf(report, "AWS vCPU quota").Message).To(Equal("need 28 vCPUs, 56 of 64 available"))
+		})
+
+		It("should fail with insufficient vCPU quota", func() {
+			spec.Compute = cloud.MachinePool{InstanceType: "m6i.2xlarge", Replicas: 6}
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "AWS vCPU quota")).To(Equal(check.StatusFail))
+			Expect(resultOf(report, "AWS vCPU quota").Message).To(HavePrefix("insufficient quota: need 64 vCPUs, 56 of 64 available"))
+		})
+
+		It("should not count non-standard instance families against the standard vCPU quota", func() {
+			spec.Compute = cloud.MachinePool{InstanceType: "p4d.24xlarge", Replicas: 2}
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "AWS vCPU quota")).To(Equal(check.StatusPass))
+		})
+
+		It("should fail when there are not enough Elastic IPs for one NAT gateway per zone", func() {
+			fake.addresses = 3
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "AWS Elastic IP quota")).To(Equal(check.StatusFail))
+		})
+
+		It("should fail when the VPC quota is exhausted", func() {
+			fake.vpcs = 5
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "AWS VPC quota")).To(Equal(check.StatusFail))
+			Expect(report.Err()).To(HaveOccurred())
+		})
+
+		It("should warn when quotas cannot be read", func() {
+			fake.quotaErr = errors.New("AccessDeniedException")
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "AWS vCPU quota")).To(Equal(check.StatusWarn))
+			Expect(report.Failed()).To(BeFalse())
+		})
+	})
+
+	Describe("AWS instance type availability", func() {
+		BeforeEach(func() {
+			fake.instanceTypes["m5.xlarge"] = 4
+			fake.instanceTypes["m7i.xlarge"] = 4
+			fake.offerings["m5.xlarge"] = []string{"us-east-2a", "us-east-2b", "us-east-2c"}
+			fake.offerings["m7i.xlarge"] = []string{"us-east-2a", "us-east-2b"}
+		})
+
+		It("should pass when the instance types are offered in every zone", func() {
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "AWS availability zones")).To(Equal(check.StatusPass))
+			Expect(statusOf(report, "AWS instance type m6i.xlarge")).To(Equal(check.StatusPass))
+		})
+
+		It("should check each distinct instance type once", func() {
+			spec.Compute.InstanceType = "m6i.2xlarge"
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "AWS instance type m6i.xlarge")).To(Equal(check.StatusPass))
+			Expect(statusOf(report, "AWS instance type m6i.2xlarge")).To(Equal(check.StatusPass))
+		})
+
+		It("should check the instance types of additional compute pools", func() {
+			fake.offerings["g5.2xlarge"] = []string{"us-east-2a", "us-east-2b"}
+			spec.AdditionalCompute = []cloud.MachinePool{{InstanceType: "g5.2xlarge", Replicas: 1}}
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "AWS instance type m6i.xlarge")).To(Equal(check.StatusPass))
+			Expect(statusOf(report, "AWS instance type g5.2xlarge")).To(Equal(check.StatusWarn))
+		})
+
+		It("should warn when an instance type is missing from some zones", func() {
+			spec.Compute.InstanceType = "m7i.xlarge"
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			result := resultOf(report, "AWS instance type m7i.xlarge")
+			Expect(result.Status).To(Equal(check.StatusWarn))
+			Expect(result.Message).To(Equal("offered in 2 of 3 zones (not us-east-2c)"))
+		})
+
+		It("should fail with alternatives when a requested zone does not offer the type", func() {
+			spec.Zones = []string{"us-east-2b", "us-east-2c"}
+			spec.Compute.InstanceType = "m7i.xlarge"
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			result := resultOf(report, "AWS instance type m7i.xlarge")
+			Expect(result.Status).To(Equal(check.StatusFail))
+			Expect(result.Message).To(Equal("m7i.xlarge is not offered in us-east-2c; alternatives: m6i.xlarge, m5.xlarge"))
+		})
+
+		It("should fail when the instance type is not offered in the region", func() {
+			spec.ControlPlane.InstanceType = "m6x.xlarge"
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			result := resultOf(report, "AWS instance type m6x.xlarge")
+			Expect(result.Status).To(Equal(check.StatusFail))
+			Expect(result.Message).To(ContainSubstring("alternatives: m6i.xlarge, m5.xlarge"))
+		})
+
+		It("should fail when a requested zone does not exist", func() {
+			spec.Zones = []string{"us-east-2d"}
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "AWS availability zones")).To(Equal(check.StatusFail))
+			Expect(resultOf(report, "AWS instance type m6i.xlarge").Name).To(BeEmpty())
+		})
+	})
+
+	Describe("AWS DNS", func() {
+		It("should find the public hosted zone for the base domain", func() {
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(resultOf(report, "AWS base domain").Message).To(Equal("labs.example.com (ZPUBLIC)"))
+			Expect(statusOf(report, "AWS cluster DNS records")).To(Equal(check.StatusPass))
+		})
+
+		It("should fail when the base domain has no public hosted zone", func() {
+			fake.hostedZones = fake.hostedZones[:1]
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(resultOf(report, "AWS base domain").Message).To(ContainSubstring("no public Route 53 hosted zone for labs.example.com"))
+			Expect(resultOf(report, "AWS cluster DNS records").Name).To(BeEmpty())
+		})
+
+		It("should fail when no base domain is set", func() {
+			spec.BaseDomain = ""
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(resultOf(report, "AWS base domain").Message).To(ContainSubstring("--base-domain"))
+		})
+
+		It("should fail when the cluster's records already exist", func() {
+			fake.records = []string{`\052.apps.partner-lab.labs.example.com.`, "api.partner-lab.labs.example.com."}
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			result := resultOf(report, "AWS cluster DNS records")
+			Expect(result.Status).To(Equal(check.StatusFail))
+			Expect(result.Message).To(HavePrefix("records already exist: api.partner-lab.labs.example.com"))
+		})
+	})
+
+	Describe("Azure", func() {
+		var (
+			server      *httptest.Server
+			zones       string
+			recordSets  string
+			restriction string
+		)
+
+		BeforeEach(func() {
+			zones = `{"value":[{"id":"/subscriptions/sub-1/resourceGroups/dns-rg/providers/Microsoft.Network/dnszones/labs.example.com","name":"labs.example.com","properties":{"zoneType":"Public"}}]}`
+			recordSets = `{"value":[{"name":"api.other-lab"}]}`
+			restriction = ""
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token"):
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"access_token":"azure-token","token_type":"Bearer","expires_in":3600}`))
+				case r.URL.Path == "/subscriptions/sub-1/providers/Microsoft.Compute/skus":
+					Expect(r.URL.Query().Get("$filter")).To(Equal("location eq 'eastus'"))
+					_, _ = w.Write([]byte(`{"value":[
+						{"resourceType":"disks","name":"Premium_LRS"},
+						{"resourceType":"virtualMachines","name":"Standard_D8s_v3","restrictions":[]},
+						{"resourceType":"virtualMachines","name":"Standard_D4s_v3","restrictions":[` + restriction + `]}]}`))
+				case r.URL.Path == "/subscriptions/sub-1/providers/Microsoft.Network/dnszones":
+					_, _ = w.Write([]byte(zones))
+				case strings.HasSuffix(r.URL.Path, "/dnszones/labs.example.com/recordsets"):
+					Expect(r.URL.Query().Get("$recordsetnamesuffix")).To(Equal("partner-lab"))
+					_, _ = w.Write([]byte(recordSets))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			DeferCleanup(server.Close)
+
+			preflight = cloud.NewPreflight(nil, &http.Client{Transport: redirectTransport{server}})
+			creds = &cloud.Credentials{
+				Provider: cloud.ProviderAzure,
+				Azure:    &cloud.AzureCredentials{ClientID: "client", ClientSecret: "secret", TenantID: "tenant", SubscriptionID: "sub-1"},
+			}
+			spec.Region = "eastus"
+		})
+
+		It("should pass when the VM sizes are offered and the base domain has a public zone", func() {
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(report.Failed()).To(BeFalse())
+			Expect(statusOf(report, "Azure VM size Standard_D8s_v3")).To(Equal(check.StatusPass))
+			Expect(statusOf(report, "Azure VM size Standard_D4s_v3")).To(Equal(check.StatusPass))
+			Expect(resultOf(report, "Azure base domain").Message).To(Equal("labs.example.com (resource group dns-rg)"))
+			Expect(statusOf(report, "Azure cluster DNS records")).To(Equal(check.StatusPass))
+		})
+
+		It("should fail for VM sizes that are not offered or restricted for the subscription", func() {
+			spec.Compute.InstanceType = "Standard_NC24ads_A100_v4"
+			spec.AdditionalCompute = []cloud.MachinePool{{InstanceType: "Standard_D4s_v3", Replicas: 1}}
+			restriction = `{"type":"Location","values":["eastus"],"reasonCode":"NotAvailableForSubscription"}`
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(resultOf(report, "Azure VM size Standard_NC24ads_A100_v4").Message).To(Equal("Standard_NC24ads_A100_v4 is not offered in eastus"))
+			result := resultOf(report, "Azure VM size Standard_D4s_v3")
+			Expect(result.Status).To(Equal(check.StatusFail))
+			Expect(result.Message).To(ContainSubstring("restricted for this subscription in eastus (NotAvailableForSubscription)"))
+		})
+
+		It("should fail for VM sizes restricted in a requested zone", func() {
+			restriction = `{"type":"Zone","values":["eastus"],"reasonCode":"NotAvailableForSubscription","restrictionInfo":{"zones":["3"]}}`
+			spec.Zones = []string{"1", "3"}
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(resultOf(report, "Azure VM size Standard_D4s_v3").Message).To(HavePrefix("Standard_D4s_v3 is restricted for this subscription in zones 3 of eastus"))
+		})
+
+		It("should fail when the base domain has no public zone", func() {
+			zones = `{"value":[]}`
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(resultOf(report, "Azure base domain").Message).To(ContainSubstring("no public Azure DNS zone for labs.example.com in subscription sub-1"))
+			Expect(resultOf(report, "Azure cluster DNS records").Name).To(BeEmpty())
+		})
+
+		It("should fail when the cluster's records already exist", func() {
+			recordSets = `{"value":[{"name":"*.apps.partner-lab"},{"name":"api.partner-lab"}]}`
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			result := resultOf(report, "Azure cluster DNS records")
+			Expect(result.Status).To(Equal(check.StatusFail))
+			Expect(result.Message).To(HavePrefix("records already exist: *.apps.partner-lab.labs.example.com, api.partner-lab.labs.example.com"))
+		})
+
+		It("should fail without a region", func() {
+			spec.Region = ""
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(statusOf(report, "Azure region")).To(Equal(check.StatusFail))
+			Expect(statusOf(report, "Azure base domain")).To(Equal(check.StatusPass))
+		})
+	})
+
+	Describe("GCP", func() {
+		var (
+			server   *httptest.Server
+			zones    string
+			existing []string
+		)
+
+		BeforeEach(func() {
+			zones = `{"managedZones":[
+				{"name":"labs-private","dnsName":"labs.example.com.","visibility":"private"},
+				{"name":"labs-public","dnsName":"labs.example.com.","visibility":"public"}]}`
+			existing = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/token":
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"access_token":"gcp-token","token_type":"Bearer","expires_in":3600}`))
+				case r.URL.Path == "/compute/v1/projects/lab/aggregated/machineTypes":
+					if r.URL.Query().Get("filter") != `name = "n2-standard-4"` {
+						_, _ = w.Write([]byte(`{"items":{"zones/us-east1-b":{"warning":{"code":"NO_RESULTS_ON_PAGE"}}}}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"items":{
+						"zones/us-east1-c":{"machineTypes":[{"name":"n2-standard-4"}]},
+						"zones/us-east1-b":{"machineTypes":[{"name":"n2-standard-4"}]},
+						"zones/us-east4-a":{"machineTypes":[{"name":"n2-standard-4"}]}}}`))
+				case r.URL.Path == "/dns/v1/projects/lab/managedZones":
+					Expect(r.URL.Query().Get("dnsName")).To(Equal("labs.example.com."))
+					_, _ = w.Write([]byte(zones))
+				case r.URL.Path == "/dns/v1/projects/lab/managedZones/labs-public/rrsets":
+					name := r.URL.Query().Get("name")
+					for _, record := range existing {
+						if record == name {
+							_, _ = w.Write([]byte(`{"rrsets":[{"name":"` + name + `","type":"A"}]}`))
+							return
+						}
+					}
+					_, _ = w.Write([]byte(`{"rrsets":[]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			DeferCleanup(server.Close)
+
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).NotTo(HaveOccurred())
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			Expect(err).NotTo(HaveOccurred())
+			preflight = cloud.NewPreflight(nil, &http.Client{Transport: redirectTransport{server}})
+			creds = &cloud.Credentials{
+				Provider: cloud.ProviderGCP,
+				GCP: &cloud.GCPCredentials{
+					ProjectID:   "lab",
+					ClientEmail: "labrat@lab.iam.gserviceaccount.com",
+					PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+				},
+			}
+			spec.Region = "us-east1"
+		})
+
+		It("should pass when the machine types are offered and the base domain has a public zone", func() {
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(report.Failed()).To(BeFalse())
+			Expect(resultOf(report, "GCP machine type n2-standard-4").Message).To(Equal("offered in us-east1-b, us-east1-c"))
+			Expect(resultOf(report, "GCP base domain").Message).To(Equal("labs.example.com (labs-public)"))
+			Expect(statusOf(report, "GCP cluster DNS records")).To(Equal(check.StatusPass))
+		})
+
+		It("should fail for machine types not offered in the region or a requested zone", func() {
+			spec.Compute.InstanceType = "a3-highgpu-8g"
+			spec.Zones = []string{"us-east1-b", "us-east1-d"}
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(resultOf(report, "GCP machine type a3-highgpu-8g").Message).To(Equal("a3-highgpu-8g is not offered in us-east1"))
+			Expect(resultOf(report, "GCP machine type n2-standard-4").Message).To(Equal("n2-standard-4 is not offered in us-east1-d (offered in us-east1-b, us-east1-c)"))
+		})
+
+		It("should fail when the base domain has no public zone", func() {
+			zones = `{"managedZones":[{"name":"labs-private","dnsName":"labs.example.com.","visibility":"private"}]}`
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			Expect(resultOf(report, "GCP base domain").Message).To(ContainSubstring("no public Cloud DNS managed zone for labs.example.com in project lab"))
+		})
+
+		It("should fail when the cluster's records already exist", func() {
+			existing = []string{"api.partner-lab.labs.example.com."}
+
+			report := preflight.Run(context.Background(), creds, spec)
+
+			result := resultOf(report, "GCP cluster DNS records")
+			Expect(result.Status).To(Equal(check.StatusFail))
+			Expect(result.Message).To(HavePrefix("records already exist: api.partner-lab.labs.example.com."))
+		})
+	})
+
+	It("should warn for providers without preflight support", func() {
+		creds = &cloud.Credentials{Provider: cloud.ProviderVSphere}
+
+		report := preflight.Run(context.Background(), creds, spec)
+
+		Expect(statusOf(report, "Cloud preflight")).To(Equal(check.StatusWarn))
+	})
+})
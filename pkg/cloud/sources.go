@@ -0,0 +1,225 @@
+package cloud
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CredentialSource selects where LoadCredentials reads credentials from. With no field set,
+// the locations the provider's CLI and the OpenShift installer use are tried in order.
+type CredentialSource struct {
+	// FromEnv reads the environment variables of the provider only
+	FromEnv bool
+	// File is an AWS credentials file, an Azure service principal file, or a GCP service
+	// account key file
+	File string
+	// Profile is the profile of the AWS credentials file (default: $AWS_PROFILE, then default)
+	Profile string
+}
+
+// LoadCredentials reads the credentials of provider from source and returns them, without a
+// name or namespace, with a description of where they were read from
+func LoadCredentials(provider string, source CredentialSource) (*Credentials, string, error) {
+	if source.Profile != "" && provider != ProviderAWS {
+		return nil, "", fmt.Errorf("profiles are only supported for aws credentials")
+	}
+	if source.FromEnv && (source.File != "" || source.Profile != "") {
+		return nil, "", fmt.Errorf("environment variables cannot be combined with a file or profile")
+	}
+
+	switch provider {
+	case ProviderAWS:
+		aws, from, err := loadAWSCredentials(source)
+		if err != nil {
+			return nil, "", err
+		}
+		return &Credentials{Provider: ProviderAWS, AWS: aws}, from, nil
+	case ProviderAzure:
+		azure, from, err := loadAzureCredentials(source)
+		if err != nil {
+			return nil, "", err
+		}
+		return &Credentials{Provider: ProviderAzure, Azure: azure}, from, nil
+	case ProviderGCP:
+		gcp, from, err := loadGCPCredentials(source)
+		if err != nil {
+			return nil, "", err
+		}
+		return &Credentials{Provider: ProviderGCP, GCP: gcp}, from, nil
+	default:
+		return nil, "", fmt.Errorf("loading %q credentials is not supported (supported: aws, azure, gcp)", provider)
+	}
+}
+
+// loadAWSCredentials reads an access key from $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY,
+// or from a profile of the AWS CLI credentials file. Temporary credentials are refused, as
+// Hive needs the key for the lifetime of the cluster.
+func loadAWSCredentials(source CredentialSource) (*AWSCredentials, string, error) {
+	if source.FromEnv || source.File == "" && source.Profile == "" && os.Getenv("AWS_ACCESS_KEY_ID") != "" {
+		if os.Getenv("AWS_SESSION_TOKEN") != "" {
+			return nil, "", fmt.Errorf("AWS_SESSION_TOKEN is set: temporary credentials expire and cannot be stored")
+		}
+		creds := &AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		}
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return nil, "", fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must both be set")
+		}
+		return creds, "environment", nil
+	}
+
+	path := source.File
+	if path == "" {
+		path = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := source.Profile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read AWS credentials file: %w", err)
+	}
+	values, ok := iniSection(data, profile)
+	if !ok {
+		return nil, "", fmt.Errorf("profile %s not found in %s", profile, path)
+	}
+	if values["aws_session_token"] != "" {
+		return nil, "", fmt.Errorf("profile %s holds temporary credentials, which expire and cannot be stored", profile)
+	}
+	creds := &AWSCredentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+	}
+	return creds, fmt.Sprintf("profile %s of %s", profile, path), nil
+}
+
+// iniSection returns the key/value pairs of section in an INI file such as ~/.aws/credentials
+func iniSection(data []byte, section string) (map[string]string, bool) {
+	values := map[string]string{}
+	found, inSection := false, false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inSection = strings.TrimSpace(line[1:len(line)-1]) == section
+			found = found || inSection
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && inSection {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values, found
+}
+
+// loadAzureCredentials reads a service principal from the $AZURE_CLIENT_ID, $AZURE_CLIENT_SECRET,
+// $AZURE_TENANT_ID, and $AZURE_SUBSCRIPTION_ID variables of the Azure SDKs, or from the
+// osServicePrincipal.json file of the installer
+func loadAzureCredentials(source CredentialSource) (*AzureCredentials, string, error) {
+	if source.FromEnv || source.File == "" && os.Getenv("AZURE_CLIENT_ID") != "" {
+		return &AzureCredentials{
+			ClientID:       os.Getenv("AZURE_CLIENT_ID"),
+			ClientSecret:   os.Getenv("AZURE_CLIENT_SECRET"),
+			TenantID:       os.Getenv("AZURE_TENANT_ID"),
+			SubscriptionID: os.Getenv("AZURE_SUBSCRIPTION_ID"),
+		}, "environment", nil
+	}
+
+	path := source.File
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		path = filepath.Join(home, ".azure", "osServicePrincipal.json")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read Azure service principal: %w", err)
+	}
+	creds := &AzureCredentials{}
+	if err := json.Unmarshal(data, creds); err != nil {
+		return nil, "", fmt.Errorf("failed to parse Azure service principal %s: %w", path, err)
+	}
+	return creds, path, nil
+}
+
+// loadGCPCredentials reads a service account key from $GOOGLE_APPLICATION_CREDENTIALS, the
+// osServiceAccount.json file of the installer, or the application default credentials of gcloud
+func loadGCPCredentials(source CredentialSource) (*GCPCredentials, string, error) {
+	var candidates []string
+	switch {
+	case source.File != "":
+		candidates = []string{source.File}
+	case source.FromEnv:
+		path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+		if path == "" {
+			return nil, "", fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS is not set")
+		}
+		candidates = []string{path}
+	default:
+		if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+			candidates = append(candidates, path)
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		gcloudConfig := os.Getenv("CLOUDSDK_CONFIG")
+		if gcloudConfig == "" {
+			gcloudConfig = filepath.Join(home, ".config", "gcloud")
+		}
+		candidates = append(candidates,
+			filepath.Join(home, ".gcp", "osServiceAccount.json"),
+			filepath.Join(gcloudConfig, "application_default_credentials.json"),
+		)
+	}
+
+	for i, path := range candidates {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && i < len(candidates)-1 {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read GCP service account key: %w", err)
+		}
+
+		var key struct {
+			Type string `json:"type"`
+		}
+		creds := &GCPCredentials{KeyFile: data}
+		if err := json.Unmarshal(data, &key); err != nil {
+			return nil, "", fmt.Errorf("failed to parse GCP service account key %s: %w", path, err)
+		}
+		if key.Type != "service_account" {
+			return nil, "", fmt.Errorf("%s holds %q credentials, not a service account key", path, key.Type)
+		}
+		if err := json.Unmarshal(data, creds); err != nil {
+			return nil, "", fmt.Errorf("failed to parse GCP service account key %s: %w", path, err)
+		}
+		return creds, path, nil
+	}
+	return nil, "", fmt.Errorf("no GCP service account key found")
+}
@@ -0,0 +1,156 @@
+//go:build test
+
+package cloud_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+)
+
+var _ = Describe("LoadCredentials", func() {
+	var home string
+
+	setEnv := func(key, value string) {
+		previous, set := os.LookupEnv(key)
+		Expect(os.Setenv(key, value)).To(Succeed())
+		DeferCleanup(func() {
+			if set {
+				os.Setenv(key, previous)
+			} else {
+				os.Unsetenv(key)
+			}
+		})
+	}
+
+	writeFile := func(path, content string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		home = GinkgoT().TempDir()
+		setEnv("HOME", home)
+		for _, key := range []string{
+			"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_SHARED_CREDENTIALS_FILE",
+			"AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET", "AZURE_TENANT_ID", "AZURE_SUBSCRIPTION_ID",
+			"GOOGLE_APPLICATION_CREDENTIALS", "CLOUDSDK_CONFIG",
+		} {
+			setEnv(key, "")
+			os.Unsetenv(key)
+		}
+	})
+
+	Describe("aws", func() {
+		It("should prefer the environment", func() {
+			setEnv("AWS_ACCESS_KEY_ID", "AKIAENV")
+			setEnv("AWS_SECRET_ACCESS_KEY", "secret")
+
+			creds, from, err := cloud.LoadCredentials(cloud.ProviderAWS, cloud.CredentialSource{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(from).To(Equal("environment"))
+			Expect(creds.AWS.AccessKeyID).To(Equal("AKIAENV"))
+			Expect(creds.Validate()).To(Succeed())
+		})
+
+		It("should refuse temporary credentials", func() {
+			setEnv("AWS_ACCESS_KEY_ID", "ASIAENV")
+			setEnv("AWS_SECRET_ACCESS_KEY", "secret")
+			setEnv("AWS_SESSION_TOKEN", "token")
+
+			_, _, err := cloud.LoadCredentials(cloud.ProviderAWS, cloud.CredentialSource{FromEnv: true})
+			Expect(err).To(MatchError(ContainSubstring("temporary credentials")))
+		})
+
+		It("should read a profile of the AWS CLI credentials file", func() {
+			writeFile(filepath.Join(home, ".aws", "credentials"), `
+[default]
+aws_access_key_id = AKIADEFAULT
+aws_secret_access_key = default-secret
+
+# partner lab account
+[labs]
+aws_access_key_id=AKIALABS
+aws_secret_access_key=labs-secret
+`)
+
+			creds, from, err := cloud.LoadCredentials(cloud.ProviderAWS, cloud.CredentialSource{Profile: "labs"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(from).To(ContainSubstring("profile labs of"))
+			Expect(*creds.AWS).To(Equal(cloud.AWSCredentials{AccessKeyID: "AKIALABS", SecretAccessKey: "labs-secret"}))
+
+			setEnv("AWS_PROFILE", "default")
+			creds, _, err = cloud.LoadCredentials(cloud.ProviderAWS, cloud.CredentialSource{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(creds.AWS.AccessKeyID).To(Equal("AKIADEFAULT"))
+
+			_, _, err = cloud.LoadCredentials(cloud.ProviderAWS, cloud.CredentialSource{Profile: "missing"})
+			Expect(err).To(MatchError(ContainSubstring("profile missing not found")))
+		})
+	})
+
+	Describe("azure", func() {
+		It("should read the service principal of the environment", func() {
+			setEnv("AZURE_CLIENT_ID", "id")
+			setEnv("AZURE_CLIENT_SECRET", "secret")
+			setEnv("AZURE_TENANT_ID", "tenant")
+			setEnv("AZURE_SUBSCRIPTION_ID", "sub")
+
+			creds, _, err := cloud.LoadCredentials(cloud.ProviderAzure, cloud.CredentialSource{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*creds.Azure).To(Equal(cloud.AzureCredentials{ClientID: "id", ClientSecret: "secret", TenantID: "tenant", SubscriptionID: "sub"}))
+		})
+
+		It("should read the osServicePrincipal.json of the installer", func() {
+			path := filepath.Join(home, ".azure", "osServicePrincipal.json")
+			writeFile(path, `{"clientId":"id","clientSecret":"secret","tenantId":"tenant","subscriptionId":"sub"}`)
+
+			creds, from, err := cloud.LoadCredentials(cloud.ProviderAzure, cloud.CredentialSource{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(from).To(Equal(path))
+			Expect(creds.Validate()).To(Succeed())
+		})
+
+		It("should reject profiles", func() {
+			_, _, err := cloud.LoadCredentials(cloud.ProviderAzure, cloud.CredentialSource{Profile: "labs"})
+			Expect(err).To(MatchError("profiles are only supported for aws credentials"))
+		})
+	})
+
+	Describe("gcp", func() {
+		const key = `{"type":"service_account","project_id":"lab-project","client_email":"labrat@lab-project.iam.gserviceaccount.com","private_key":"KEY","token_uri":"https://oauth2.googleapis.com/token"}`
+
+		It("should read the key of GOOGLE_APPLICATION_CREDENTIALS and keep the file as is", func() {
+			path := filepath.Join(home, "key.json")
+			writeFile(path, key)
+			setEnv("GOOGLE_APPLICATION_CREDENTIALS", path)
+
+			creds, from, err := cloud.LoadCredentials(cloud.ProviderGCP, cloud.CredentialSource{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(from).To(Equal(path))
+			Expect(creds.GCP.ProjectID).To(Equal("lab-project"))
+			Expect(string(creds.GCP.KeyFile)).To(Equal(key))
+			Expect(creds.Validate()).To(Succeed())
+		})
+
+		It("should fall back to the installer and gcloud locations", func() {
+			writeFile(filepath.Join(home, ".config", "gcloud", "application_default_credentials.json"), key)
+
+			creds, from, err := cloud.LoadCredentials(cloud.ProviderGCP, cloud.CredentialSource{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(from).To(HaveSuffix("application_default_credentials.json"))
+			Expect(creds.GCP.ClientEmail).To(Equal("labrat@lab-project.iam.gserviceaccount.com"))
+		})
+
+		It("should refuse user credentials", func() {
+			writeFile(filepath.Join(home, ".config", "gcloud", "application_default_credentials.json"), `{"type":"authorized_user"}`)
+
+			_, _, err := cloud.LoadCredentials(cloud.ProviderGCP, cloud.CredentialSource{})
+			Expect(err).To(MatchError(ContainSubstring(`holds "authorized_user" credentials`)))
+		})
+	})
+})
@@ -0,0 +1,186 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+)
+
+// VerifyReportName is the report (and JUnit test suite) name used by credential verification
+const VerifyReportName = "labrat.credentials.verify"
+
+// hiveAWSActions is a representative sample of the IAM actions the OpenShift installer and
+// Hive need to provision, hibernate, and deprovision a cluster. It is not exhaustive, but a
+// credential missing any of these will certainly fail to install.
+var hiveAWSActions = []string{
+	"ec2:AllocateAddress",
+	"ec2:CreateNatGateway",
+	"ec2:CreateSecurityGroup",
+	"ec2:CreateSubnet",
+	"ec2:CreateVpc",
+	"ec2:DescribeInstances",
+	"ec2:RunInstances",
+	"ec2:StartInstances",
+	"ec2:StopInstances",
+	"ec2:TerminateInstances",
+	"elasticloadbalancing:CreateLoadBalancer",
+	"iam:CreateInstanceProfile",
+	"iam:CreateRole",
+	"iam:PassRole",
+	"route53:ChangeResourceRecordSets",
+	"route53:CreateHostedZone",
+	"s3:CreateBucket",
+	"tag:GetResources",
+}
+
+// VerifyOptions configures credential verification
+type VerifyOptions struct {
+	// Region is the region that spoke clusters will be provisioned into
+	Region string
+}
+
+// Verifier confirms that stored cloud credentials work by making real API calls with them
+type Verifier interface {
+	// Verify checks the credentials and returns the results
+	Verify(ctx context.Context, creds *Credentials, opts VerifyOptions) check.Report
+}
+
+type verifier struct {
+	newAWSClients AWSClientFactory
+}
+
+// NewVerifier creates a new Verifier. If newAWSClients is nil, NewAWSClients is used.
+func NewVerifier(newAWSClients AWSClientFactory) Verifier {
+	if newAWSClients == nil {
+		newAWSClients = NewAWSClients
+	}
+	return &verifier{
+		newAWSClients: newAWSClients,
+	}
+}
+
+// Verify checks that the credential secret is complete and, for AWS, that the key:
+// 1. Authenticates (STS GetCallerIdentity; remaining checks are skipped if this fails)
+// 2. Can list regions, and the target region is enabled
+// 3. Is allowed the IAM actions Hive needs (IAM policy simulation)
+func (v *verifier) Verify(ctx context.Context, creds *Credentials, opts VerifyOptions) check.Report {
+	report := check.Report{Name: VerifyReportName}
+
+	result := report.Run("Credential fields present", func() (check.Status, string) {
+		if err := creds.Validate(); err != nil {
+			return check.StatusFail, err.Error()
+		}
+		return check.StatusPass, fmt.Sprintf("%s credentials %s/%s", creds.Provider, creds.Namespace, creds.Name)
+	})
+	if result.Status == check.StatusFail {
+		return report
+	}
+
+	switch creds.Provider {
+	case ProviderAWS:
+		v.verifyAWS(ctx, &report, creds.AWS, opts)
+	default:
+		report.Run("Cloud API access", func() (check.Status, string) {
+			return check.StatusWarn, fmt.Sprintf("live verification is not supported for %s credentials yet", creds.Provider)
+		})
+	}
+
+	return report
+}
+
+// verifyAWS runs the AWS API checks
+func (v *verifier) verifyAWS(ctx context.Context, report *check.Report, creds *AWSCredentials, opts VerifyOptions) {
+	region := opts.Region
+	if region == "" {
+		region = DefaultAWSRegion
+	}
+	clients := v.newAWSClients(creds, region)
+
+	var callerARN string
+	result := report.Run("AWS caller identity", func() (check.Status, string) {
+		out, err := clients.STS.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return check.StatusFail, fmt.Sprintf("failed to authenticate: %v", err)
+		}
+		callerARN = aws.ToString(out.Arn)
+		return check.StatusPass, fmt.Sprintf("%s (account %s)", callerARN, aws.ToString(out.Account))
+	})
+	if result.Status == check.StatusFail {
+		return
+	}
+
+	report.Run("AWS regions", func() (check.Status, string) {
+		out, err := clients.EC2.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+		if err != nil {
+			return check.StatusFail, fmt.Sprintf("failed to list regions: %v", err)
+		}
+		for _, r := range out.Regions {
+			if aws.ToString(r.RegionName) == region {
+				return check.StatusPass, fmt.Sprintf("%d regions enabled, including %s", len(out.Regions), region)
+			}
+		}
+		return check.StatusFail, fmt.Sprintf("region %s is not enabled for this account", region)
+	})
+
+	report.Run("Hive IAM permissions", func() (check.Status, string) {
+		return checkAWSPermissions(ctx, clients.IAM, callerARN)
+	})
+}
+
+// checkAWSPermissions simulates the caller's IAM policies against the actions Hive needs
+func checkAWSPermissions(ctx context.Context, client IAMAPI, callerARN string) (check.Status, string) {
+	principalARN := iamPrincipalARN(callerARN)
+	if principalARN == "" {
+		return check.StatusWarn, fmt.Sprintf("cannot simulate policies for %s", callerARN)
+	}
+
+	var denied []string
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(client, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principalARN),
+		ActionNames:     hiveAWSActions,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			// The credential may lack iam:SimulatePrincipalPolicy even if it can install clusters
+			return check.StatusWarn, fmt.Sprintf("unable to simulate IAM policies: %v", err)
+		}
+		for _, result := range page.EvaluationResults {
+			if result.EvalDecision != iamtypes.PolicyEvaluationDecisionTypeAllowed {
+				denied = append(denied, aws.ToString(result.EvalActionName))
+			}
+		}
+	}
+
+	if len(denied) > 0 {
+		return check.StatusFail, fmt.Sprintf("denied: %s", strings.Join(denied, ", "))
+	}
+	return check.StatusPass, fmt.Sprintf("%d required actions allowed", len(hiveAWSActions))
+}
+
+// iamPrincipalARN converts a caller ARN into an ARN accepted by IAM policy simulation.
+// Assumed-role sessions are mapped back to their role; root and federated callers cannot be simulated.
+func iamPrincipalARN(callerARN string) string {
+	parts := strings.SplitN(callerARN, ":", 6)
+	if len(parts) != 6 {
+		return ""
+	}
+	partition, service, account, resource := parts[1], parts[2], parts[4], parts[5]
+
+	switch {
+	case service == "iam" && (strings.HasPrefix(resource, "user/") || strings.HasPrefix(resource, "role/")):
+		return callerARN
+	case service == "sts" && strings.HasPrefix(resource, "assumed-role/"):
+		role := strings.SplitN(strings.TrimPrefix(resource, "assumed-role/"), "/", 2)[0]
+		return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, account, role)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,105 @@
+//go:build test
+
+package cloud_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+)
+
+var _ = Describe("Verifier", func() {
+	var (
+		fake     *fakeAWS
+		verifier cloud.Verifier
+		creds    *cloud.Credentials
+	)
+
+	BeforeEach(func() {
+		fake = &fakeAWS{
+			callerARN: "arn:aws:iam::123456789012:user/hive",
+			regions:   []string{"us-east-1", "us-east-2"},
+		}
+		verifier = cloud.NewVerifier(fake.clients)
+		creds = &cloud.Credentials{
+			Name:      "aws-lab",
+			Namespace: "creds",
+			Provider:  cloud.ProviderAWS,
+			AWS:       &cloud.AWSCredentials{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret"},
+		}
+	})
+
+	It("should pass for working AWS credentials", func() {
+		report := verifier.Verify(context.Background(), creds, cloud.VerifyOptions{Region: "us-east-2"})
+
+		Expect(report.Name).To(Equal(cloud.VerifyReportName))
+		Expect(report.Results).To(HaveLen(4))
+		Expect(report.Failed()).To(BeFalse())
+		Expect(fake.simulatedARN).To(Equal("arn:aws:iam::123456789012:user/hive"))
+	})
+
+	It("should stop after a failed caller identity check", func() {
+		fake.identityErr = errors.New("InvalidClientTokenId")
+
+		report := verifier.Verify(context.Background(), creds, cloud.VerifyOptions{})
+
+		Expect(report.Results).To(HaveLen(2))
+		Expect(statusOf(report, "AWS caller identity")).To(Equal(check.StatusFail))
+	})
+
+	It("should fail when the target region is not enabled", func() {
+		report := verifier.Verify(context.Background(), creds, cloud.VerifyOptions{Region: "ap-east-1"})
+
+		Expect(statusOf(report, "AWS regions")).To(Equal(check.StatusFail))
+	})
+
+	It("should report denied Hive actions", func() {
+		fake.deniedAction = "iam:PassRole"
+
+		report := verifier.Verify(context.Background(), creds, cloud.VerifyOptions{})
+
+		Expect(statusOf(report, "Hive IAM permissions")).To(Equal(check.StatusFail))
+		Expect(report.Results[3].Message).To(ContainSubstring("iam:PassRole"))
+	})
+
+	It("should warn when policies cannot be simulated", func() {
+		fake.simulateErr = errors.New("AccessDenied")
+
+		report := verifier.Verify(context.Background(), creds, cloud.VerifyOptions{})
+
+		Expect(statusOf(report, "Hive IAM permissions")).To(Equal(check.StatusWarn))
+		Expect(report.Failed()).To(BeFalse())
+	})
+
+	It("should simulate the role behind an assumed-role session", func() {
+		fake.callerARN = "arn:aws:sts::123456789012:assumed-role/hive-installer/session-1"
+
+		verifier.Verify(context.Background(), creds, cloud.VerifyOptions{})
+
+		Expect(fake.simulatedARN).To(Equal("arn:aws:iam::123456789012:role/hive-installer"))
+	})
+
+	It("should fail incomplete credentials without calling the provider", func() {
+		creds.AWS.SecretAccessKey = ""
+
+		report := verifier.Verify(context.Background(), creds, cloud.VerifyOptions{})
+
+		Expect(report.Results).To(HaveLen(1))
+		Expect(report.Results[0].Message).To(ContainSubstring("aws_secret_access_key"))
+	})
+
+	It("should warn that other providers are not verified live", func() {
+		creds = &cloud.Credentials{
+			Provider: cloud.ProviderAzure,
+			Azure:    &cloud.AzureCredentials{ClientID: "id", ClientSecret: "s", TenantID: "t", SubscriptionID: "sub"},
+		}
+
+		report := verifier.Verify(context.Background(), creds, cloud.VerifyOptions{})
+
+		Expect(statusOf(report, "Cloud API access")).To(Equal(check.StatusWarn))
+	})
+})
@@ -0,0 +1,15 @@
+//go:build test
+
+package cost_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCost(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cost Suite")
+}
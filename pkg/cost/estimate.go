@@ -0,0 +1,99 @@
+package cost
+
+import (
+	"sort"
+)
+
+// Unlabeled is the instance type nodes without an instance type label are counted as; it has
+// no price
+const Unlabeled = "unknown"
+
+// ClusterUsage is what a running cluster runs on
+type ClusterUsage struct {
+	Name string
+	// Partner is the partner the cluster belongs to, empty if unknown
+	Partner  string
+	Platform string
+	Region   string
+	// Nodes counts the nodes of the cluster by instance type
+	Nodes map[string]int
+}
+
+// ClusterCost is the estimated cost of running a cluster
+type ClusterCost struct {
+	Cluster  string         `json:"cluster"`
+	Partner  string         `json:"partner,omitempty"`
+	Platform string         `json:"platform"`
+	Region   string         `json:"region"`
+	Nodes    map[string]int `json:"nodes"`
+	Hourly   float64        `json:"hourly"`
+	Daily    float64        `json:"daily"`
+	Monthly  float64        `json:"monthly"`
+	// Unpriced are the instance types without a price, whose nodes are not in the estimate
+	Unpriced []string `json:"unpriced,omitempty"`
+}
+
+// PartnerCost is the estimated cost of the clusters of a partner
+type PartnerCost struct {
+	// Partner is the partner, empty for the clusters without one
+	Partner  string  `json:"partner"`
+	Clusters int     `json:"clusters"`
+	Daily    float64 `json:"daily"`
+	Monthly  float64 `json:"monthly"`
+	// Incomplete is set when the estimate of a cluster of the partner misses unpriced nodes
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// Estimate prices the nodes of each cluster, sorted by cluster name
+func Estimate(pricing *Pricing, clusters []ClusterUsage) []ClusterCost {
+	costs := make([]ClusterCost, 0, len(clusters))
+	for _, cluster := range clusters {
+		cost := ClusterCost{
+			Cluster:  cluster.Name,
+			Partner:  cluster.Partner,
+			Platform: cluster.Platform,
+			Region:   cluster.Region,
+			Nodes:    cluster.Nodes,
+		}
+		for instanceType, count := range cluster.Nodes {
+			price, ok := pricing.Hourly(cluster.Platform, cluster.Region, instanceType)
+			if !ok {
+				cost.Unpriced = append(cost.Unpriced, instanceType)
+				continue
+			}
+			cost.Hourly += price * float64(count)
+		}
+		sort.Strings(cost.Unpriced)
+		cost.Daily = cost.Hourly * HoursPerDay
+		cost.Monthly = cost.Hourly * HoursPerMonth
+		costs = append(costs, cost)
+	}
+	sort.Slice(costs, func(i, j int) bool { return costs[i].Cluster < costs[j].Cluster })
+	return costs
+}
+
+// ByPartner sums the costs of the clusters of each partner, sorted by monthly cost with the
+// most expensive partner first
+func ByPartner(costs []ClusterCost) []PartnerCost {
+	index := make(map[string]int)
+	var partners []PartnerCost
+	for _, cost := range costs {
+		i, ok := index[cost.Partner]
+		if !ok {
+			i = len(partners)
+			index[cost.Partner] = i
+			partners = append(partners, PartnerCost{Partner: cost.Partner})
+		}
+		partners[i].Clusters++
+		partners[i].Daily += cost.Daily
+		partners[i].Monthly += cost.Monthly
+		partners[i].Incomplete = partners[i].Incomplete || len(cost.Unpriced) > 0
+	}
+	sort.SliceStable(partners, func(i, j int) bool {
+		if partners[i].Monthly != partners[j].Monthly {
+			return partners[i].Monthly > partners[j].Monthly
+		}
+		return partners[i].Partner < partners[j].Partner
+	})
+	return partners
+}
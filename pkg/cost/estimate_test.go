@@ -0,0 +1,56 @@
+//go:build test
+
+package cost_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cost"
+)
+
+var _ = Describe("Estimate", func() {
+	clusters := []cost.ClusterUsage{
+		{Name: "spoke-b", Partner: "acme", Platform: "aws", Region: "us-east-1", Nodes: map[string]int{"m6i.2xlarge": 3, "m6i.xlarge": 2}},
+		{Name: "spoke-a", Partner: "initech", Platform: "gcp", Region: "us-central1", Nodes: map[string]int{"n2-standard-4": 5}},
+		{Name: "spoke-c", Partner: "acme", Platform: "aws", Region: "eu-west-1", Nodes: map[string]int{"m6i.xlarge": 3, "g5.xlarge": 1, cost.Unlabeled: 1}},
+	}
+
+	It("should price the nodes of each cluster sorted by name", func() {
+		costs := cost.Estimate(cost.Bundled(), clusters)
+		Expect(costs).To(HaveLen(3))
+		Expect(costs[0].Cluster).To(Equal("spoke-a"))
+		Expect(costs[1].Cluster).To(Equal("spoke-b"))
+		Expect(costs[2].Cluster).To(Equal("spoke-c"))
+
+		Expect(costs[1].Hourly).To(BeNumerically("~", 3*0.384+2*0.192))
+		Expect(costs[1].Daily).To(BeNumerically("~", (3*0.384+2*0.192)*24))
+		Expect(costs[1].Monthly).To(BeNumerically("~", (3*0.384+2*0.192)*730))
+		Expect(costs[1].Unpriced).To(BeEmpty())
+		Expect(costs[0].Hourly).To(BeNumerically("~", 5*0.1942))
+	})
+
+	It("should leave unpriced nodes out of the estimate", func() {
+		costs := cost.Estimate(cost.Bundled(), clusters)
+		Expect(costs[2].Hourly).To(BeNumerically("~", 3*0.192*1.1))
+		Expect(costs[2].Unpriced).To(Equal([]string{"g5.xlarge", cost.Unlabeled}))
+	})
+
+	It("should sum the costs of each partner with the most expensive first", func() {
+		costs := cost.Estimate(cost.Bundled(), append(clusters, cost.ClusterUsage{Name: "spoke-d", Platform: "aws", Region: "us-east-1"}))
+		partners := cost.ByPartner(costs)
+		Expect(partners).To(HaveLen(3))
+
+		Expect(partners[0].Partner).To(Equal("acme"))
+		Expect(partners[0].Clusters).To(Equal(2))
+		Expect(partners[0].Monthly).To(BeNumerically("~", costs[1].Monthly+costs[2].Monthly))
+		Expect(partners[0].Daily).To(BeNumerically("~", costs[1].Daily+costs[2].Daily))
+		Expect(partners[0].Incomplete).To(BeTrue())
+
+		Expect(partners[1].Partner).To(Equal("initech"))
+		Expect(partners[1].Incomplete).To(BeFalse())
+		Expect(partners[2].Partner).To(BeEmpty())
+		Expect(partners[2].Clusters).To(Equal(1))
+		Expect(partners[2].Monthly).To(BeZero())
+	})
+})
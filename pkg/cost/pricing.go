@@ -0,0 +1,201 @@
+// Package cost estimates what the clusters of the lab cost to run. A pricing table maps the
+// instance types of each platform to an hourly on-demand price and each region to a
+// multiplier of those prices; the nodes of a running cluster are priced with it. The bundled
+// table holds list prices of the instance types OpenShift is commonly installed with and can
+// be overridden or extended with a user-supplied table.
+package cost
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// HoursPerDay is the number of hours a daily cost is estimated for
+	HoursPerDay = 24
+	// HoursPerMonth is the average number of hours in a month, as used by cloud pricing calculators
+	HoursPerMonth = 730
+)
+
+// Pricing is a table of instance prices per platform
+type Pricing struct {
+	// Currency is the currency of the prices, e.g. USD
+	Currency string `yaml:"currency,omitempty" json:"currency,omitempty"`
+	// Platforms are the prices of each platform, keyed by Hive platform name, e.g. aws
+	Platforms map[string]PlatformPricing `yaml:"platforms,omitempty" json:"platforms,omitempty"`
+}
+
+// PlatformPricing is the prices of the instance types of a platform
+type PlatformPricing struct {
+	// InstanceTypes are the hourly on-demand prices of instance types in the reference region
+	InstanceTypes map[string]float64 `yaml:"instanceTypes,omitempty" json:"instanceTypes,omitempty"`
+	// Regions are the multipliers of the prices in regions that differ from the reference
+	// region; regions not listed cost the same
+	Regions map[string]float64 `yaml:"regions,omitempty" json:"regions,omitempty"`
+}
+
+// Bundled returns the bundled pricing table: approximate Linux on-demand list prices in
+// us-east-1, us-central1, and eastus
+func Bundled() *Pricing {
+	return &Pricing{
+		Currency: "USD",
+		Platforms: map[string]PlatformPricing{
+			"aws": {
+				InstanceTypes: map[string]float64{
+					"m5.large":    0.096,
+					"m5.xlarge":   0.192,
+					"m5.2xlarge":  0.384,
+					"m5.4xlarge":  0.768,
+					"m6a.xlarge":  0.1728,
+					"m6a.2xlarge": 0.3456,
+					"m6a.4xlarge": 0.6912,
+					"m6i.large":   0.096,
+					"m6i.xlarge":  0.192,
+					"m6i.2xlarge": 0.384,
+					"m6i.4xlarge": 0.768,
+					"m7i.xlarge":  0.2016,
+					"m7i.2xlarge": 0.4032,
+					"c5.2xlarge":  0.34,
+					"c5.4xlarge":  0.68,
+					"r5.xlarge":   0.252,
+					"r5.2xlarge":  0.504,
+				},
+				Regions: map[string]float64{
+					"us-west-1":      1.17,
+					"ca-central-1":   1.1,
+					"eu-west-1":      1.1,
+					"eu-west-2":      1.16,
+					"eu-central-1":   1.2,
+					"ap-south-1":     1.05,
+					"ap-southeast-1": 1.25,
+					"ap-northeast-1": 1.29,
+					"sa-east-1":      1.59,
+				},
+			},
+			"gcp": {
+				InstanceTypes: map[string]float64{
+					"n1-standard-4":  0.19,
+					"n1-standard-8":  0.38,
+					"n2-standard-2":  0.0971,
+					"n2-standard-4":  0.1942,
+					"n2-standard-8":  0.3885,
+					"n2-standard-16": 0.7769,
+					"n2d-standard-4": 0.169,
+					"n2d-standard-8": 0.338,
+					"e2-standard-4":  0.134,
+					"e2-standard-8":  0.268,
+				},
+				Regions: map[string]float64{
+					"us-east4":     1.13,
+					"us-west2":     1.2,
+					"europe-west1": 1.1,
+					"europe-west3": 1.29,
+					"europe-west4": 1.1,
+					"asia-east1":   1.16,
+				},
+			},
+			"azure": {
+				InstanceTypes: map[string]float64{
+					"Standard_D4s_v3":  0.192,
+					"Standard_D8s_v3":  0.384,
+					"Standard_D16s_v3": 0.768,
+					"Standard_D4s_v5":  0.192,
+					"Standard_D8s_v5":  0.384,
+					"Standard_E8s_v3":  0.504,
+				},
+				Regions: map[string]float64{
+					"centralus":   1.1,
+					"northeurope": 1.05,
+					"westeurope":  1.13,
+					"uksouth":     1.13,
+				},
+			},
+		},
+	}
+}
+
+// LoadPricing reads a pricing table from a YAML file and merges it over the bundled table:
+// its prices and multipliers replace or extend those of the bundled table. An empty path
+// returns the bundled table.
+func LoadPricing(path string) (*Pricing, error) {
+	pricing := Bundled()
+	if path == "" {
+		return pricing, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing file: %w", err)
+	}
+	var custom Pricing
+	if err := yaml.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing file %s: %w", path, err)
+	}
+	if err := custom.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pricing file %s: %w", path, err)
+	}
+	pricing.Merge(&custom)
+	return pricing, nil
+}
+
+// Validate checks that prices are not negative and multipliers are positive
+func (p *Pricing) Validate() error {
+	for platform, prices := range p.Platforms {
+		for instanceType, price := range prices.InstanceTypes {
+			if price < 0 {
+				return fmt.Errorf("price of %s instance type %s is negative", platform, instanceType)
+			}
+		}
+		for region, multiplier := range prices.Regions {
+			if multiplier <= 0 {
+				return fmt.Errorf("multiplier of %s region %s must be positive", platform, region)
+			}
+		}
+	}
+	return nil
+}
+
+// Merge replaces or adds the currency, prices, and multipliers set in other
+func (p *Pricing) Merge(other *Pricing) {
+	if other.Currency != "" {
+		p.Currency = other.Currency
+	}
+	if p.Platforms == nil {
+		p.Platforms = make(map[string]PlatformPricing)
+	}
+	for platform, prices := range other.Platforms {
+		merged := p.Platforms[platform]
+		if merged.InstanceTypes == nil {
+			merged.InstanceTypes = make(map[string]float64)
+		}
+		if merged.Regions == nil {
+			merged.Regions = make(map[string]float64)
+		}
+		for instanceType, price := range prices.InstanceTypes {
+			merged.InstanceTypes[instanceType] = price
+		}
+		for region, multiplier := range prices.Regions {
+			merged.Regions[region] = multiplier
+		}
+		p.Platforms[platform] = merged
+	}
+}
+
+// Hourly returns the hourly price of an instance type of platform in region, false if the
+// table has no price for it
+func (p *Pricing) Hourly(platform, region, instanceType string) (float64, bool) {
+	prices, ok := p.Platforms[platform]
+	if !ok {
+		return 0, false
+	}
+	price, ok := prices.InstanceTypes[instanceType]
+	if !ok {
+		return 0, false
+	}
+	if multiplier, ok := prices.Regions[region]; ok {
+		price *= multiplier
+	}
+	return price, true
+}
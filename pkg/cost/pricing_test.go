@@ -0,0 +1,88 @@
+//go:build test
+
+package cost_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cost"
+)
+
+var _ = Describe("Pricing", func() {
+	writePricing := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "pricing.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		return path
+	}
+
+	It("should price instance types in the reference region", func() {
+		price, ok := cost.Bundled().Hourly("aws", "us-east-1", "m6i.xlarge")
+		Expect(ok).To(BeTrue())
+		Expect(price).To(BeNumerically("~", 0.192))
+	})
+
+	It("should apply the multiplier of the region", func() {
+		price, ok := cost.Bundled().Hourly("aws", "eu-west-1", "m6i.xlarge")
+		Expect(ok).To(BeTrue())
+		Expect(price).To(BeNumerically("~", 0.192*1.1))
+	})
+
+	It("should report instance types and platforms without a price", func() {
+		_, ok := cost.Bundled().Hourly("aws", "us-east-1", "p4d.24xlarge")
+		Expect(ok).To(BeFalse())
+		_, ok = cost.Bundled().Hourly("vsphere", "", "m6i.xlarge")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should return the bundled table without a pricing file", func() {
+		pricing, err := cost.LoadPricing("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pricing).To(Equal(cost.Bundled()))
+	})
+
+	It("should merge a pricing file over the bundled table", func() {
+		pricing, err := cost.LoadPricing(writePricing(`currency: EUR
+platforms:
+  aws:
+    instanceTypes:
+      m6i.xlarge: 0.15
+      g5.xlarge: 1.006
+    regions:
+      eu-west-1: 1.0
+  vsphere:
+    instanceTypes:
+      vm-16x64: 0.05
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pricing.Currency).To(Equal("EUR"))
+
+		price, _ := pricing.Hourly("aws", "eu-west-1", "m6i.xlarge")
+		Expect(price).To(BeNumerically("~", 0.15))
+		price, _ = pricing.Hourly("aws", "us-east-1", "g5.xlarge")
+		Expect(price).To(BeNumerically("~", 1.006))
+		price, _ = pricing.Hourly("aws", "us-east-1", "m5.xlarge")
+		Expect(price).To(BeNumerically("~", 0.192))
+		price, _ = pricing.Hourly("vsphere", "", "vm-16x64")
+		Expect(price).To(BeNumerically("~", 0.05))
+	})
+
+	It("should reject negative prices and non-positive multipliers", func() {
+		_, err := cost.LoadPricing(writePricing("platforms:\n  aws:\n    instanceTypes:\n      m6i.xlarge: -1\n"))
+		Expect(err).To(MatchError(ContainSubstring("price of aws instance type m6i.xlarge is negative")))
+
+		_, err = cost.LoadPricing(writePricing("platforms:\n  gcp:\n    regions:\n      us-east4: 0\n"))
+		Expect(err).To(MatchError(ContainSubstring("multiplier of gcp region us-east4 must be positive")))
+	})
+
+	It("should return an error for unreadable and malformed files", func() {
+		_, err := cost.LoadPricing(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+		Expect(err).To(MatchError(ContainSubstring("failed to read pricing file")))
+
+		_, err = cost.LoadPricing(writePricing("platforms: [aws]\n"))
+		Expect(err).To(MatchError(ContainSubstring("failed to parse pricing file")))
+	})
+})
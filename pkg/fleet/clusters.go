@@ -0,0 +1,24 @@
+package fleet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+// Clusters lists the names of the managed clusters on a hub that match filter, for use as
+// the clusters of a Runner
+func Clusters(ctx context.Context, client hub.ManagedClusterClient, filter hub.ManagedClusterFilter) ([]string, error) {
+	clusters, err := client.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed clusters: %w", err)
+	}
+
+	clusters = client.Filter(clusters, filter)
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,61 @@
+//go:build test
+
+package fleet_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+// stubClusterClient returns a fixed list of managed clusters
+type stubClusterClient struct {
+	clusters []hub.ManagedClusterInfo
+	err      error
+}
+
+func (s *stubClusterClient) List(_ context.Context) ([]hub.ManagedClusterInfo, error) {
+	return s.clusters, s.err
+}
+
+func (s *stubClusterClient) Watch(_ context.Context) (<-chan hub.ManagedClusterEvent, error) {
+	return nil, errors.New("watch not supported")
+}
+
+func (s *stubClusterClient) Get(_ context.Context, _ string) (*hub.ManagedClusterDetail, error) {
+	return nil, errors.New("get not supported")
+}
+
+func (s *stubClusterClient) Filter(clusters []hub.ManagedClusterInfo, filter hub.ManagedClusterFilter) []hub.ManagedClusterInfo {
+	return hub.NewManagedClusterClient(nil).Filter(clusters, filter)
+}
+
+var _ = Describe("Clusters", func() {
+	client := &stubClusterClient{clusters: []hub.ManagedClusterInfo{
+		{Name: "ready-1", Status: hub.StatusReady},
+		{Name: "broken", Status: hub.StatusNotReady},
+		{Name: "ready-2", Status: hub.StatusReady},
+	}}
+
+	It("should return the names of the clusters matching the filter", func() {
+		names, err := fleet.Clusters(context.Background(), client, hub.ManagedClusterFilter{Status: hub.StatusReady})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"ready-1", "ready-2"}))
+	})
+
+	It("should return every cluster without a filter", func() {
+		names, err := fleet.Clusters(context.Background(), client, hub.ManagedClusterFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(HaveLen(3))
+	})
+
+	It("should return list errors", func() {
+		_, err := fleet.Clusters(context.Background(), &stubClusterClient{err: errors.New("forbidden")}, hub.ManagedClusterFilter{})
+		Expect(err).To(MatchError(ContainSubstring("failed to list managed clusters: forbidden")))
+	})
+})
@@ -0,0 +1,253 @@
+// Package fleet runs per-cluster operations across the labrat-managed fleet with
+// bounded concurrency. When the hub API server starts throttling requests (HTTP 429),
+// the effective concurrency is reduced and throttled operations are retried after a
+// backoff, so fleet-wide actions do not overwhelm the hub. Each cluster gets its own
+// Result, and Summarize and Errors aggregate the failures of a run.
+package fleet
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// DefaultConcurrency is the number of clusters processed in parallel when not overridden
+	DefaultConcurrency = 5
+	// DefaultMaxThrottleRetries is how many times a throttled operation is retried
+	DefaultMaxThrottleRetries = 5
+	// DefaultInitialBackoff is the delay before retrying the first throttled attempt
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff caps the delay between throttled retries
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// ErrSkipped is recorded for clusters that were not processed because an earlier
+// operation failed while FailFast was set
+var ErrSkipped = errors.New("skipped after an earlier failure")
+
+// Func is an operation executed against a single cluster
+type Func func(ctx context.Context, clusterName string) error
+
+// Options controls how a run is executed
+type Options struct {
+	// Concurrency is the maximum number of operations in flight at once
+	Concurrency int
+	// MaxThrottleRetries is how many times an operation is retried when throttled
+	MaxThrottleRetries int
+	// InitialBackoff is the delay before the first retry of a throttled operation
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries of a throttled operation
+	MaxBackoff time.Duration
+	// FailFast stops scheduling new operations after the first failure.
+	// Operations already in flight are allowed to finish.
+	FailFast bool
+	// Logger receives the throttle retries of operations; nil discards them
+	Logger *slog.Logger
+}
+
+// Result holds the outcome of an operation on a single cluster
+type Result struct {
+	// Cluster is the name of the cluster the operation ran against
+	Cluster string
+	// Err is the error returned by the operation, nil on success
+	Err error
+	// Attempts is the number of times the operation was invoked
+	Attempts int
+	// Duration is the total time spent on the cluster, including backoff
+	Duration time.Duration
+}
+
+// withDefaults returns a copy of the options with zero values replaced by defaults
+func (o Options) withDefaults() Options {
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultConcurrency
+	}
+	if o.MaxThrottleRetries < 0 {
+		o.MaxThrottleRetries = 0
+	} else if o.MaxThrottleRetries == 0 {
+		o.MaxThrottleRetries = DefaultMaxThrottleRetries
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = DefaultInitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = DefaultMaxBackoff
+	}
+	return o
+}
+
+// Runner executes an operation across many clusters. A Runner holds no state between
+// runs and is safe for concurrent use.
+type Runner struct {
+	opts Options
+}
+
+// NewRunner creates a new Runner; zero options are replaced by the defaults
+func NewRunner(opts Options) *Runner {
+	return &Runner{opts: opts.withDefaults()}
+}
+
+// Run executes fn for every cluster with at most Options.Concurrency operations in flight.
+// Results are returned in the same order as clusters. A cancelled context stops
+// scheduling new operations; clusters that never ran carry the context error,
+// or ErrSkipped when scheduling stopped because of FailFast.
+func (r *Runner) Run(ctx context.Context, clusters []string, fn Func) []Result {
+	opts := r.opts
+	results := make([]Result, len(clusters))
+
+	// schedCtx only gates scheduling; in-flight operations keep the caller's context
+	schedCtx, stopScheduling := context.WithCancel(ctx)
+	defer stopScheduling()
+
+	lim := newLimiter(schedCtx, opts.Concurrency)
+	defer lim.close()
+
+	var onFailure func()
+	if opts.FailFast {
+		onFailure = stopScheduling
+	}
+
+	var wg sync.WaitGroup
+	for i, name := range clusters {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = runOne(ctx, lim, opts, name, fn, onFailure)
+		}(i, name)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runOne executes fn for a single cluster, retrying with backoff while it is throttled.
+// onFailure, if set, is called when the operation fails for good, before its slot is
+// released, so no waiting operation can take the slot and start after the failure.
+func runOne(ctx context.Context, lim *limiter, opts Options, name string, fn Func, onFailure func()) Result {
+	start := time.Now()
+	result := Result{Cluster: name}
+	backoff := opts.InitialBackoff
+
+	for {
+		if err := lim.acquire(); err != nil {
+			result.Err = err
+			if ctx.Err() == nil {
+				// Scheduling was stopped by FailFast rather than by the caller
+				result.Err = ErrSkipped
+			}
+			break
+		}
+		result.Attempts++
+		err := fn(ctx, name)
+		throttled := IsThrottled(err)
+		done := !throttled || result.Attempts > opts.MaxThrottleRetries
+		if done && err != nil && onFailure != nil {
+			onFailure()
+		}
+		lim.release(throttled)
+
+		if done {
+			result.Err = err
+			break
+		}
+
+		// Prefer the server's Retry-After hint over our own backoff
+		delay := backoff
+		if seconds, ok := apierrors.SuggestsClientDelay(err); ok && seconds > 0 {
+			delay = time.Duration(seconds) * time.Second
+		}
+		if opts.Logger != nil {
+			opts.Logger.InfoContext(ctx, "operation throttled, retrying", "cluster", name, "attempt", result.Attempts, "delay", delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			result.Err = err
+			break
+		}
+		backoff = min(backoff*2, opts.MaxBackoff)
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+// IsThrottled reports whether err indicates the API server is rate limiting requests
+func IsThrottled(err error) bool {
+	return err != nil && apierrors.IsTooManyRequests(err)
+}
+
+// sleep waits for the given duration or until the context is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// limiter is an adaptive concurrency limit. It halves the number of allowed
+// in-flight operations whenever one is throttled and grows it back by one for
+// every successful operation, up to the configured maximum.
+type limiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	ctx      context.Context
+	limit    int
+	maxLimit int
+	inflight int
+	stop     func() bool
+}
+
+func newLimiter(ctx context.Context, maxInFlight int) *limiter {
+	l := &limiter{
+		ctx:      ctx,
+		limit:    maxInFlight,
+		maxLimit: maxInFlight,
+	}
+	l.cond = sync.NewCond(&l.mu)
+	// Wake up waiters when the context is cancelled so they can bail out
+	l.stop = context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.cond.Broadcast()
+	})
+	return l
+}
+
+// acquire blocks until an operation may start or the context is cancelled
+func (l *limiter) acquire() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inflight >= l.limit && l.ctx.Err() == nil {
+		l.cond.Wait()
+	}
+	if err := l.ctx.Err(); err != nil {
+		return err
+	}
+	l.inflight++
+	return nil
+}
+
+// release marks an operation as finished and adjusts the limit
+func (l *limiter) release(throttled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	if throttled {
+		l.limit = max(1, l.limit/2)
+	} else if l.limit < l.maxLimit {
+		l.limit++
+	}
+	l.cond.Broadcast()
+}
+
+func (l *limiter) close() {
+	l.stop()
+}
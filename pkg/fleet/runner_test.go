@@ -0,0 +1,173 @@
+//go:build test
+
+package fleet_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+)
+
+var _ = Describe("Runner", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should run the operation for every cluster and keep input order", func() {
+		clusters := []string{"a", "b", "c", "d"}
+
+		results := fleet.NewRunner(fleet.Options{Concurrency: 2}).Run(ctx, clusters, func(_ context.Context, name string) error {
+			if name == "c" {
+				return errors.New("boom")
+			}
+			return nil
+		})
+
+		Expect(results).To(HaveLen(4))
+		for i, result := range results {
+			Expect(result.Cluster).To(Equal(clusters[i]))
+			Expect(result.Attempts).To(Equal(1))
+		}
+		Expect(results[2].Err).To(MatchError("boom"))
+		Expect(results[0].Err).NotTo(HaveOccurred())
+	})
+
+	It("should never exceed the configured concurrency", func() {
+		var inFlight, peak int32
+		clusters := make([]string, 20)
+		for i := range clusters {
+			clusters[i] = "cluster"
+		}
+
+		fleet.NewRunner(fleet.Options{Concurrency: 3}).Run(ctx, clusters, func(_ context.Context, _ string) error {
+			current := atomic.AddInt32(&inFlight, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			return nil
+		})
+
+		Expect(atomic.LoadInt32(&peak)).To(BeNumerically("<=", 3))
+		Expect(atomic.LoadInt32(&peak)).To(BeNumerically(">", 0))
+	})
+
+	It("should retry throttled operations with backoff", func() {
+		var mu sync.Mutex
+		calls := map[string]int{}
+
+		opts := fleet.Options{
+			Concurrency:    2,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     2 * time.Millisecond,
+		}
+		results := fleet.NewRunner(opts).Run(ctx, []string{"throttled"}, func(_ context.Context, name string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls[name]++
+			if calls[name] < 3 {
+				return apierrors.NewTooManyRequests("slow down", 0)
+			}
+			return nil
+		})
+
+		Expect(results[0].Err).NotTo(HaveOccurred())
+		Expect(results[0].Attempts).To(Equal(3))
+	})
+
+	It("should log throttle retries", func() {
+		var buf bytes.Buffer
+		opts := fleet.Options{
+			MaxThrottleRetries: 1,
+			InitialBackoff:     time.Millisecond,
+			Logger:             slog.New(slog.NewTextHandler(&buf, nil)),
+		}
+		fleet.NewRunner(opts).Run(ctx, []string{"busy"}, func(_ context.Context, _ string) error {
+			return apierrors.NewTooManyRequests("slow down", 0)
+		})
+
+		Expect(buf.String()).To(ContainSubstring(`msg="operation throttled, retrying" cluster=busy attempt=1`))
+	})
+
+	It("should give up after the maximum number of throttle retries", func() {
+		opts := fleet.Options{
+			MaxThrottleRetries: 2,
+			InitialBackoff:     time.Millisecond,
+			MaxBackoff:         time.Millisecond,
+		}
+		results := fleet.NewRunner(opts).Run(ctx, []string{"busy"}, func(_ context.Context, _ string) error {
+			return apierrors.NewTooManyRequests("slow down", 0)
+		})
+
+		Expect(fleet.IsThrottled(results[0].Err)).To(BeTrue())
+		Expect(results[0].Attempts).To(Equal(3))
+	})
+
+	It("should not run operations after the context is cancelled", func() {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		var calls int32
+		results := fleet.NewRunner(fleet.Options{}).Run(cancelled, []string{"a", "b"}, func(_ context.Context, _ string) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		})
+
+		Expect(atomic.LoadInt32(&calls)).To(BeZero())
+		Expect(results[0].Err).To(MatchError(context.Canceled))
+		Expect(results[1].Err).To(MatchError(context.Canceled))
+	})
+})
+
+var _ = Describe("IsThrottled", func() {
+	It("should detect 429 responses", func() {
+		Expect(fleet.IsThrottled(apierrors.NewTooManyRequests("slow down", 1))).To(BeTrue())
+	})
+
+	It("should ignore other errors", func() {
+		Expect(fleet.IsThrottled(nil)).To(BeFalse())
+		Expect(fleet.IsThrottled(errors.New("boom"))).To(BeFalse())
+	})
+})
+
+var _ = Describe("FailFast", func() {
+	It("should skip clusters that were not started after a failure", func() {
+		clusters := []string{"first", "second", "third", "fourth"}
+
+		// Every operation fails, so whichever cluster takes the only slot first must be the
+		// only one to run; repeat to catch a waiting cluster taking the slot it releases
+		for range 50 {
+			results := fleet.NewRunner(fleet.Options{Concurrency: 1, FailFast: true}).Run(context.Background(), clusters,
+				func(context.Context, string) error {
+					return errors.New("boom")
+				})
+
+			summary := fleet.Summarize(results)
+			Expect(summary.Failed).To(Equal(1))
+			Expect(summary.Skipped).To(Equal(3))
+			for _, result := range results {
+				if result.Attempts == 0 {
+					Expect(result.Err).To(MatchError(fleet.ErrSkipped))
+				} else {
+					Expect(result.Err).To(MatchError("boom"))
+				}
+			}
+		}
+	})
+})
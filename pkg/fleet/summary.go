@@ -0,0 +1,110 @@
+package fleet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Summary counts the outcomes of a fleet run
+type Summary struct {
+	// Total is the number of clusters in the run
+	Total int
+	// Succeeded is the number of clusters whose operation returned no error
+	Succeeded int
+	// Failed is the number of clusters whose operation returned an error
+	Failed int
+	// Skipped is the number of clusters never processed because of FailFast
+	Skipped int
+}
+
+// Summarize counts successes, failures, and skipped clusters in results
+func Summarize(results []Result) Summary {
+	summary := Summary{Total: len(results)}
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+			summary.Succeeded++
+		case errors.Is(result.Err, ErrSkipped):
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+	}
+	return summary
+}
+
+// Err returns an error describing the failures in the run, or nil if every cluster succeeded
+func (s Summary) Err() error {
+	if s.Failed == 0 && s.Skipped == 0 {
+		return nil
+	}
+	if s.Skipped > 0 {
+		return fmt.Errorf("%d of %d clusters failed, %d skipped", s.Failed, s.Total, s.Skipped)
+	}
+	return fmt.Errorf("%d of %d clusters failed", s.Failed, s.Total)
+}
+
+// ClusterError is the error of the operation on a single cluster
+type ClusterError struct {
+	Cluster string
+	Err     error
+}
+
+// Error returns the cluster name followed by the error
+func (e *ClusterError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Cluster, e.Err)
+}
+
+// Unwrap returns the error of the operation
+func (e *ClusterError) Unwrap() error {
+	return e.Err
+}
+
+// Errors joins the errors of the failed and skipped clusters in results into a single error
+// of *ClusterError values, or returns nil if every cluster succeeded
+func Errors(results []Result) error {
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, &ClusterError{Cluster: result.Cluster, Err: result.Err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WriteSummary writes a per-cluster result table followed by a totals line
+func WriteSummary(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintf(tw, "CLUSTER\tRESULT\tATTEMPTS\tDURATION\tERROR\n")
+	for _, result := range results {
+		outcome := "Succeeded"
+		message := ""
+		switch {
+		case errors.Is(result.Err, ErrSkipped):
+			outcome = "Skipped"
+		case result.Err != nil:
+			outcome = "Failed"
+			message = result.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n",
+			result.Cluster,
+			outcome,
+			result.Attempts,
+			result.Duration.Round(time.Millisecond),
+			message,
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	summary := Summarize(results)
+	_, err := fmt.Fprintf(w, "\n%d total, %d succeeded, %d failed, %d skipped\n",
+		summary.Total, summary.Succeeded, summary.Failed, summary.Skipped)
+	return err
+}
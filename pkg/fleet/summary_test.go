@@ -0,0 +1,62 @@
+//go:build test
+
+package fleet_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/fleet"
+)
+
+var _ = Describe("Summary", func() {
+	var results []fleet.Result
+
+	BeforeEach(func() {
+		results = []fleet.Result{
+			{Cluster: "ok", Attempts: 1, Duration: 1500 * time.Microsecond},
+			{Cluster: "broken", Err: errors.New("connection refused"), Attempts: 1},
+			{Cluster: "later", Err: fleet.ErrSkipped},
+		}
+	})
+
+	It("should count each outcome", func() {
+		summary := fleet.Summarize(results)
+		Expect(summary).To(Equal(fleet.Summary{Total: 3, Succeeded: 1, Failed: 1, Skipped: 1}))
+	})
+
+	It("should return an error when any cluster failed or was skipped", func() {
+		Expect(fleet.Summarize(results).Err()).To(MatchError("1 of 3 clusters failed, 1 skipped"))
+		Expect(fleet.Summarize(results[:2]).Err()).To(MatchError("1 of 2 clusters failed"))
+		Expect(fleet.Summarize(results[:1]).Err()).NotTo(HaveOccurred())
+	})
+
+	It("should join the errors of failed and skipped clusters", func() {
+		err := fleet.Errors(results)
+		Expect(err).To(MatchError(ContainSubstring("broken: connection refused")))
+		Expect(err).To(MatchError(ContainSubstring("later: skipped after an earlier failure")))
+		Expect(errors.Is(err, fleet.ErrSkipped)).To(BeTrue())
+
+		var clusterErr *fleet.ClusterError
+		Expect(errors.As(err, &clusterErr)).To(BeTrue())
+		Expect(clusterErr.Cluster).To(Equal("broken"))
+
+		Expect(fleet.Errors(results[:1])).NotTo(HaveOccurred())
+	})
+
+	It("should write a table with one row per cluster and a totals line", func() {
+		var buf bytes.Buffer
+		Expect(fleet.WriteSummary(&buf, results)).To(Succeed())
+
+		output := buf.String()
+		Expect(output).To(ContainSubstring("CLUSTER"))
+		Expect(output).To(MatchRegexp(`ok\s+Succeeded\s+1\s+2ms`))
+		Expect(output).To(MatchRegexp(`broken\s+Failed\s+1\s+0s\s+connection refused`))
+		Expect(output).To(MatchRegexp(`later\s+Skipped`))
+		Expect(output).To(ContainSubstring("3 total, 1 succeeded, 1 failed, 1 skipped"))
+	})
+})
@@ -0,0 +1,15 @@
+//go:build test
+
+package hive_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHive(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hive Suite")
+}
@@ -0,0 +1,195 @@
+// Package hive holds typed mirrors of the hive.openshift.io/v1 API resources labrat reads, so
+// ClusterDeployments and MachinePools are decoded with their schema instead of walked as
+// unstructured maps. Only the fields labrat uses are mirrored; the JSON names match the
+// Hive API, and fields of other types fail the decoding instead of being dropped.
+package hive
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// ClusterDeploymentGVR identifies Hive ClusterDeployment resources
+	ClusterDeploymentGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+	// MachinePoolGVR identifies Hive MachinePool resources
+	MachinePoolGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "machinepools"}
+)
+
+const (
+	// ClusterPlatformLabel is set by Hive on ClusterDeployments to their cloud platform
+	ClusterPlatformLabel = "hive.openshift.io/cluster-platform"
+	// ClusterRegionLabel is set by Hive on ClusterDeployments to their cloud region
+	ClusterRegionLabel = "hive.openshift.io/cluster-region"
+
+	// ProvisionFailedCondition is true when the last install attempt of a cluster failed
+	ProvisionFailedCondition = "ProvisionFailed"
+)
+
+// ClusterDeployment is a cluster provisioned or adopted by Hive
+type ClusterDeployment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterDeploymentSpec   `json:"spec,omitempty"`
+	Status ClusterDeploymentStatus `json:"status,omitempty"`
+}
+
+// ClusterDeploymentSpec is the desired state of a ClusterDeployment
+type ClusterDeploymentSpec struct {
+	ClusterName     string                `json:"clusterName"`
+	BaseDomain      string                `json:"baseDomain"`
+	Platform        Platform              `json:"platform"`
+	ClusterMetadata *ClusterMetadata      `json:"clusterMetadata,omitempty"`
+	Installed       bool                  `json:"installed"`
+	ClusterPoolRef  *ClusterPoolReference `json:"clusterPoolRef,omitempty"`
+	PowerState      string                `json:"powerState,omitempty"`
+	HibernateAfter  *metav1.Duration      `json:"hibernateAfter,omitempty"`
+}
+
+// Platform is the cloud platform of a ClusterDeployment; one of the fields is set
+type Platform struct {
+	AWS   *RegionPlatform `json:"aws,omitempty"`
+	Azure *RegionPlatform `json:"azure,omitempty"`
+	GCP   *RegionPlatform `json:"gcp,omitempty"`
+}
+
+// RegionPlatform is the region of the platform of a ClusterDeployment
+type RegionPlatform struct {
+	Region string `json:"region"`
+}
+
+// Region returns the region of the platform that is set, empty if none is
+func (p Platform) Region() string {
+	for _, platform := range []*RegionPlatform{p.AWS, p.Azure, p.GCP} {
+		if platform != nil {
+			return platform.Region
+		}
+	}
+	return ""
+}
+
+// ClusterMetadata identifies an installed cluster and the secrets of its admin credentials
+type ClusterMetadata struct {
+	ClusterID                string                       `json:"clusterID"`
+	InfraID                  string                       `json:"infraID"`
+	AdminKubeconfigSecretRef corev1.LocalObjectReference  `json:"adminKubeconfigSecretRef"`
+	AdminPasswordSecretRef   *corev1.LocalObjectReference `json:"adminPasswordSecretRef,omitempty"`
+}
+
+// ClusterPoolReference references the ClusterPool a ClusterDeployment was created by
+type ClusterPoolReference struct {
+	Namespace string `json:"namespace"`
+	PoolName  string `json:"poolName"`
+	ClaimName string `json:"claimName,omitempty"`
+}
+
+// ClusterDeploymentStatus is the observed state of a ClusterDeployment
+type ClusterDeploymentStatus struct {
+	APIURL                  string                       `json:"apiURL,omitempty"`
+	WebConsoleURL           string                       `json:"webConsoleURL,omitempty"`
+	InstallVersion          *string                      `json:"installVersion,omitempty"`
+	InstallRestarts         int                          `json:"installRestarts,omitempty"`
+	Conditions              []ClusterDeploymentCondition `json:"conditions,omitempty"`
+	InstallStartedTimestamp *metav1.Time                 `json:"installStartedTimestamp,omitempty"`
+	InstalledTimestamp      *metav1.Time                 `json:"installedTimestamp,omitempty"`
+	ProvisionRef            *corev1.LocalObjectReference `json:"provisionRef,omitempty"`
+	PowerState              string                       `json:"powerState,omitempty"`
+}
+
+// ClusterDeploymentCondition is a status condition of a ClusterDeployment
+type ClusterDeploymentCondition struct {
+	Type               string                 `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	LastProbeTime      metav1.Time            `json:"lastProbeTime,omitempty"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+}
+
+// Condition returns the condition of type conditionType, nil if the ClusterDeployment has none
+func (s ClusterDeploymentStatus) Condition(conditionType string) *ClusterDeploymentCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// MachinePool is a set of worker machines of a ClusterDeployment
+type MachinePool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachinePoolSpec   `json:"spec,omitempty"`
+	Status MachinePoolStatus `json:"status,omitempty"`
+}
+
+// MachinePoolSpec is the desired state of a MachinePool
+type MachinePoolSpec struct {
+	ClusterDeploymentRef corev1.LocalObjectReference `json:"clusterDeploymentRef"`
+	Name                 string                      `json:"name"`
+	Replicas             *int64                      `json:"replicas,omitempty"`
+	Autoscaling          *MachinePoolAutoscaling     `json:"autoscaling,omitempty"`
+	Platform             MachinePoolPlatform         `json:"platform"`
+	Labels               map[string]string           `json:"labels,omitempty"`
+	Taints               []corev1.Taint              `json:"taints,omitempty"`
+}
+
+// MachinePoolAutoscaling bounds the replicas of an autoscaled MachinePool
+type MachinePoolAutoscaling struct {
+	MinReplicas int32 `json:"minReplicas"`
+	MaxReplicas int32 `json:"maxReplicas"`
+}
+
+// MachinePoolPlatform is the cloud platform of the machines of a MachinePool; one of the
+// fields is set
+type MachinePoolPlatform struct {
+	AWS      *InstancePlatform `json:"aws,omitempty"`
+	Azure    *InstancePlatform `json:"azure,omitempty"`
+	GCP      *InstancePlatform `json:"gcp,omitempty"`
+	IBMCloud *InstancePlatform `json:"ibmcloud,omitempty"`
+}
+
+// InstancePlatform is the instance type of the machines of a MachinePool
+type InstancePlatform struct {
+	InstanceType string `json:"type"`
+}
+
+// InstanceType returns the instance type of the platform that is set, empty if none is
+func (p MachinePoolPlatform) InstanceType() string {
+	for _, platform := range []*InstancePlatform{p.AWS, p.Azure, p.GCP, p.IBMCloud} {
+		if platform != nil {
+			return platform.InstanceType
+		}
+	}
+	return ""
+}
+
+// MachinePoolStatus is the observed state of a MachinePool
+type MachinePoolStatus struct {
+	Replicas int32 `json:"replicas,omitempty"`
+}
+
+// ClusterDeploymentFromUnstructured decodes the content of an unstructured ClusterDeployment
+func ClusterDeploymentFromUnstructured(obj map[string]interface{}) (*ClusterDeployment, error) {
+	cd := &ClusterDeployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, cd); err != nil {
+		return nil, fmt.Errorf("failed to decode ClusterDeployment: %w", err)
+	}
+	return cd, nil
+}
+
+// MachinePoolFromUnstructured decodes the content of an unstructured MachinePool
+func MachinePoolFromUnstructured(obj map[string]interface{}) (*MachinePool, error) {
+	pool := &MachinePool{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, pool); err != nil {
+		return nil, fmt.Errorf("failed to decode MachinePool: %w", err)
+	}
+	return pool, nil
+}
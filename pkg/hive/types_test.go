@@ -0,0 +1,74 @@
+//go:build test
+
+package hive_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hive"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+var _ = Describe("Hive types", func() {
+	Describe("ClusterDeploymentFromUnstructured", func() {
+		It("should decode the fixtures", func() {
+			objects, err := kube.LoadManifestFile("../../test/fixtures/clusterdeployment_running.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objects).To(HaveLen(1))
+
+			cd, err := hive.ClusterDeploymentFromUnstructured(objects[0].Object)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cd.Name).To(Equal("test-cluster-running"))
+			Expect(cd.Labels).To(HaveKeyWithValue(hive.ClusterRegionLabel, "us-east-1"))
+			Expect(cd.Spec.Installed).To(BeTrue())
+			Expect(cd.Spec.Platform.Region()).To(Equal("us-east-1"))
+			Expect(cd.Spec.ClusterMetadata.ClusterID).To(Equal("abc123-test-cluster-id"))
+			Expect(cd.Spec.ClusterMetadata.AdminKubeconfigSecretRef.Name).To(Equal("test-cluster-running-admin-kubeconfig"))
+			Expect(*cd.Status.InstallVersion).To(Equal("4.20.6"))
+			Expect(cd.Status.PowerState).To(Equal("Running"))
+
+			provisioned := cd.Status.Condition("Provisioned")
+			Expect(provisioned).NotTo(BeNil())
+			Expect(provisioned.Status).To(Equal(corev1.ConditionTrue))
+			Expect(cd.Status.Condition(hive.ProvisionFailedCondition)).To(BeNil())
+		})
+
+		It("should fail on fields of the wrong type", func() {
+			_, err := hive.ClusterDeploymentFromUnstructured(map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "partner-a"},
+				"status":   map[string]interface{}{"installedTimestamp": "yesterday"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("failed to decode ClusterDeployment")))
+		})
+	})
+
+	Describe("MachinePoolFromUnstructured", func() {
+		It("should decode the replicas, autoscaling, and instance type", func() {
+			pool, err := hive.MachinePoolFromUnstructured(map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "partner-a-worker", "namespace": "partner-a"},
+				"spec": map[string]interface{}{
+					"clusterDeploymentRef": map[string]interface{}{"name": "partner-a"},
+					"name":                 "worker",
+					"autoscaling":          map[string]interface{}{"minReplicas": int64(2), "maxReplicas": int64(6)},
+					"platform":             map[string]interface{}{"gcp": map[string]interface{}{"type": "n2-standard-8"}},
+				},
+				"status": map[string]interface{}{"replicas": int64(4)},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pool.Spec.ClusterDeploymentRef.Name).To(Equal("partner-a"))
+			Expect(pool.Spec.Replicas).To(BeNil())
+			Expect(pool.Spec.Autoscaling.MaxReplicas).To(Equal(int32(6)))
+			Expect(pool.Spec.Platform.InstanceType()).To(Equal("n2-standard-8"))
+			Expect(pool.Status.Replicas).To(Equal(int32(4)))
+		})
+
+		It("should fail on fields of the wrong type", func() {
+			_, err := hive.MachinePoolFromUnstructured(map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": "three"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("failed to decode MachinePool")))
+		})
+	})
+})
@@ -0,0 +1,185 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hive"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// AccessReportName is the report (and JUnit test suite) name used by hub access checks
+const AccessReportName = "labrat.hub.can-i"
+
+var (
+	// namespaceGVR, secretGVR, and configMapGVR identify the core resources labrat manages
+	namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	secretGVR    = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	// eventGVR identifies the events Hive and ACM record in the cluster namespaces
+	eventGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+	// machinePoolGVR identifies the Hive MachinePools of spoke clusters
+	machinePoolGVR = hive.MachinePoolGVR
+	// csrGVR identifies the CertificateSigningRequests klusterlets create to register
+	csrGVR = schema.GroupVersionResource{Group: "certificates.k8s.io", Version: "v1", Resource: "certificatesigningrequests"}
+	// signerGVR identifies the signers whose CertificateSigningRequests can be approved
+	signerGVR = schema.GroupVersionResource{Group: "certificates.k8s.io", Version: "v1", Resource: "signers"}
+)
+
+// Permission is an access to the hub API that labrat commands need
+type Permission struct {
+	Verb     string `json:"verb"`
+	Group    string `json:"group,omitempty"`
+	Resource string `json:"resource"`
+	// Namespace limits the access to one namespace; empty means every namespace, or the
+	// resource is cluster-scoped
+	Namespace string `json:"namespace,omitempty"`
+	// Commands are the labrat commands that need the access
+	Commands []string `json:"commands"`
+}
+
+// String describes the access like kubectl auth can-i, e.g. "patch clusterdeployments.hive.openshift.io in lab-1"
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	if p.Namespace != "" {
+		return fmt.Sprintf("%s %s in %s", p.Verb, resource, p.Namespace)
+	}
+	return fmt.Sprintf("%s %s", p.Verb, resource)
+}
+
+// HubPermissions returns the accesses labrat commands need on a hub whose ACM namespace is
+// namespace. The resources of clusters live in a namespace per cluster; they are checked in
+// clusterNamespace, or in every namespace if it is empty.
+func HubPermissions(namespace, clusterNamespace string) []Permission {
+	permission := func(verb string, gvr schema.GroupVersionResource, ns string, commands ...string) Permission {
+		return Permission{Verb: verb, Group: gvr.Group, Resource: gvr.Resource, Namespace: ns, Commands: commands}
+	}
+	return []Permission{
+		permission("list", managedClusterGVR, "", "hub managedclusters", "hub summary", "hub costs", "hub export", "hub diff", "hub addons status", "serve", "tui"),
+		permission("watch", managedClusterGVR, "", "hub managedclusters --watch", "serve", "tui"),
+		permission("get", managedClusterGVR, "", "hub managedclusters get"),
+		permission("list", clusterDeploymentGVR, "", "hub clusterdeployments", "hub summary", "hub capacity", "hub costs", "schedule list", "schedule run", "hub sshkeys list", "hub export", "hub diff", "hub managedclusters --watch", "serve", "tui"),
+		permission("watch", clusterDeploymentGVR, "", "hub managedclusters --watch", "serve", "tui"),
+		permission("list", machinePoolGVR, "", "hub capacity"),
+		permission("list", machinePoolGVR, clusterNamespace, "spoke scale", "spoke machinepools"),
+		permission("patch", machinePoolGVR, clusterNamespace, "spoke scale", "spoke machinepools autoscale"),
+		permission("list", managedClusterInfoGVR, "", "hub managedclusters --wide", "hub costs", "hub export", "hub diff", "serve", "tui"),
+		permission("watch", managedClusterInfoGVR, "", "serve", "tui"),
+		permission("list", managedClusterAddOnGVR, "", "hub addons status"),
+		permission("list", clusterManagementAddOnGVR, "", "hub addons status"),
+		permission("list", policyGVR, "", "hub policies"),
+		permission("list", clusterCuratorGVR, "", "hub upgrade-check"),
+		permission("create", clusterCuratorGVR, clusterNamespace, "spoke upgrade"),
+		permission("patch", clusterCuratorGVR, clusterNamespace, "spoke upgrade"),
+		permission("list", eventGVR, clusterNamespace, "spoke status", "spoke events"),
+		permission("watch", eventGVR, clusterNamespace, "spoke events --follow"),
+		permission("list", eventGVR, "", "hub events watch"),
+		permission("watch", eventGVR, "", "hub events watch"),
+		permission("get", secretGVR, clusterNamespace, "spoke kubeconfig", "spoke credentials", "spoke exec", "hub upgrade-check", "spoke upgrade --wait", "spoke events --spoke", "spoke ssh", "hub sshkeys", "tui"),
+		permission("patch", clusterDeploymentGVR, clusterNamespace, "spoke hibernate", "spoke resume", "spoke lease", "spoke pause-reconcile", "spoke resume-reconcile", "spoke label --cluster-deployment", "schedule set", "schedule clear", "schedule run", "tui"),
+		permission("create", namespaceGVR, "", "spoke create"),
+		permission("create", clusterDeploymentGVR, clusterNamespace, "spoke create"),
+		permission("delete", clusterDeploymentGVR, clusterNamespace, "spoke delete"),
+		permission("create", secretGVR, clusterNamespace, "hub sshkeys create"),
+		permission("update", secretGVR, clusterNamespace, "hub sshkeys rotate"),
+		permission("create", managedClusterGVR, "", "spoke create", "hub import"),
+		permission("delete", managedClusterGVR, "", "spoke delete", "spoke detach"),
+		permission("create", managedClusterAddOnGVR, clusterNamespace, "spoke addons enable"),
+		permission("create", managedClusterSetGVR, "", "hub clustersets create"),
+		permission("patch", managedClusterGVR, "", "hub clustersets add", "hub clustersets remove", "spoke label", "spoke annotate", "spoke pause-reconcile", "spoke resume-reconcile"),
+		permission("list", clusterImageSetGVR, "", "spoke create", "hub imagesets list"),
+		permission("create", clusterImageSetGVR, "", "hub imagesets create"),
+		permission("delete", clusterImageSetGVR, "", "hub imagesets delete"),
+		permission("list", clusterPoolGVR, "", "hub imagesets delete"),
+		permission("list", csrGVR, "", "spoke csr list", "spoke csr approve"),
+		permission("update", csrGVR, "", "spoke csr approve"),
+		permission("approve", signerGVR, "", "spoke csr approve"),
+		permission("create", clusterClaimGVR, namespace, "pool claim"),
+		permission("list", secretGVR, namespace, "hub credentials list"),
+		permission("get", secretGVR, namespace, "hub capacity"),
+		permission("create", secretGVR, namespace, "hub credentials create"),
+		permission("update", configMapGVR, namespace, "spoke create --request-id", "audit.configMap"),
+	}
+}
+
+// AccessChecker reviews whether the current identity has the accesses labrat needs
+type AccessChecker interface {
+	// Check reviews every permission with a SelfSubjectAccessReview and reports each one that
+	// is denied as failed
+	Check(ctx context.Context, permissions []Permission) check.Report
+}
+
+type accessChecker struct {
+	client  authorizationv1client.SelfSubjectAccessReviewsGetter
+	options kube.Options
+}
+
+// NewAccessChecker creates a new AccessChecker
+func NewAccessChecker(client authorizationv1client.SelfSubjectAccessReviewsGetter, options ...kube.Option) AccessChecker {
+	return &accessChecker{
+		client:  client,
+		options: kube.NewOptions(options...),
+	}
+}
+
+// Check reviews every permission, so all missing accesses are reported at once instead of
+// failing midway through a command
+func (a *accessChecker) Check(ctx context.Context, permissions []Permission) check.Report {
+	report := check.Report{Name: AccessReportName}
+	ctx, cancel, err := a.options.Start(ctx, "review access", "permissions", len(permissions))
+	if err != nil {
+		report.Add(check.Result{Name: "Rate limit", Status: check.StatusFail, Message: err.Error()})
+		return report
+	}
+	defer cancel()
+
+	for _, permission := range permissions {
+		report.Run(permission.String(), func() (check.Status, string) {
+			return a.review(ctx, permission)
+		})
+	}
+	return report
+}
+
+// review asks the API server whether the current identity is allowed permission
+func (a *accessChecker) review(ctx context.Context, permission Permission) (check.Status, string) {
+	neededBy := "needed by " + strings.Join(permission.Commands, ", ")
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:      permission.Verb,
+				Group:     permission.Group,
+				Resource:  permission.Resource,
+				Namespace: permission.Namespace,
+			},
+		},
+	}
+
+	var result *authorizationv1.SelfSubjectAccessReview
+	err := a.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = a.client.SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		return err
+	})
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to review access: %v", err)
+	}
+
+	if !result.Status.Allowed {
+		if result.Status.Reason != "" {
+			return check.StatusFail, fmt.Sprintf("denied: %s; %s", result.Status.Reason, neededBy)
+		}
+		return check.StatusFail, "denied; " + neededBy
+	}
+	return check.StatusPass, "allowed; " + neededBy
+}
@@ -0,0 +1,99 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("AccessChecker", func() {
+	var (
+		clientset *k8sFake.Clientset
+		reviewed  []authorizationv1.ResourceAttributes
+	)
+
+	// allow answers the access reviews, allowing the verbs in allowed
+	allow := func(allowed ...string) {
+		clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			attributes := review.Spec.ResourceAttributes
+			reviewed = append(reviewed, *attributes)
+			for _, verb := range allowed {
+				if attributes.Verb == verb {
+					review.Status.Allowed = true
+					return true, review, nil
+				}
+			}
+			review.Status.Reason = "RBAC: access denied"
+			return true, review, nil
+		})
+	}
+
+	BeforeEach(func() {
+		clientset = k8sFake.NewSimpleClientset()
+		reviewed = nil
+	})
+
+	It("should report every permission that is denied", func() {
+		allow("list", "get")
+		report := hub.NewAccessChecker(clientset.AuthorizationV1()).Check(context.Background(), []hub.Permission{
+			{Verb: "list", Group: "cluster.open-cluster-management.io", Resource: "managedclusters", Commands: []string{"hub managedclusters"}},
+			{Verb: "patch", Group: "hive.openshift.io", Resource: "clusterdeployments", Namespace: "lab-1", Commands: []string{"spoke hibernate", "spoke resume"}},
+			{Verb: "get", Resource: "secrets", Namespace: "lab-1", Commands: []string{"spoke kubeconfig"}},
+		})
+
+		Expect(report.Name).To(Equal(hub.AccessReportName))
+		Expect(report.Results).To(HaveLen(3))
+		Expect(report.Results[0].Name).To(Equal("list managedclusters.cluster.open-cluster-management.io"))
+		Expect(report.Results[0].Status).To(Equal(check.StatusPass))
+		Expect(report.Results[1].Name).To(Equal("patch clusterdeployments.hive.openshift.io in lab-1"))
+		Expect(report.Results[1].Status).To(Equal(check.StatusFail))
+		Expect(report.Results[1].Message).To(Equal("denied: RBAC: access denied; needed by spoke hibernate, spoke resume"))
+		Expect(report.Results[2].Name).To(Equal("get secrets in lab-1"))
+		Expect(report.Results[2].Status).To(Equal(check.StatusPass))
+		Expect(report.Err()).To(MatchError("1 of 3 checks failed"))
+
+		Expect(reviewed[1]).To(Equal(authorizationv1.ResourceAttributes{
+			Verb: "patch", Group: "hive.openshift.io", Resource: "clusterdeployments", Namespace: "lab-1",
+		}))
+	})
+
+	It("should fail permissions that cannot be reviewed", func() {
+		clientset.PrependReactor("create", "selfsubjectaccessreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(authorizationv1.Resource("selfsubjectaccessreviews"), "", nil)
+		})
+		report := hub.NewAccessChecker(clientset.AuthorizationV1()).Check(context.Background(), []hub.Permission{
+			{Verb: "list", Resource: "secrets"},
+		})
+
+		Expect(report.Results[0].Status).To(Equal(check.StatusFail))
+		Expect(report.Results[0].Message).To(ContainSubstring("failed to review access"))
+	})
+
+	It("should check the cluster resources in the given cluster namespace", func() {
+		allow()
+		permissions := hub.HubPermissions("open-cluster-management", "lab-1")
+		hub.NewAccessChecker(clientset.AuthorizationV1()).Check(context.Background(), permissions)
+
+		Expect(reviewed).To(HaveLen(len(permissions)))
+		Expect(reviewed).To(ContainElement(authorizationv1.ResourceAttributes{
+			Verb: "get", Resource: "secrets", Namespace: "lab-1",
+		}))
+		Expect(reviewed).To(ContainElement(authorizationv1.ResourceAttributes{
+			Verb: "create", Group: "hive.openshift.io", Resource: "clusterclaims", Namespace: "open-cluster-management",
+		}))
+		Expect(reviewed).To(ContainElement(authorizationv1.ResourceAttributes{
+			Verb: "list", Group: "cluster.open-cluster-management.io", Resource: "managedclusters",
+		}))
+	})
+})
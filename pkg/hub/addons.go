@@ -0,0 +1,303 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// DefaultAddOnInstallNamespace is the namespace on the spoke add-on agents are installed into
+const DefaultAddOnInstallNamespace = "open-cluster-management-agent-addon"
+
+// Add-on statuses derived from the conditions of a ManagedClusterAddOn
+const (
+	// AddOnStatusAvailable indicates the add-on agent is running on the spoke
+	AddOnStatusAvailable = "Available"
+	// AddOnStatusDegraded indicates the add-on agent is running but reports problems
+	AddOnStatusDegraded = "Degraded"
+	// AddOnStatusProgressing indicates the add-on agent is being installed or upgraded
+	AddOnStatusProgressing = "Progressing"
+	// AddOnStatusUnavailable indicates the add-on agent is not running
+	AddOnStatusUnavailable = "Unavailable"
+	// AddOnStatusUnknown indicates the add-on has not reported its status yet
+	AddOnStatusUnknown = "Unknown"
+	// AddOnStatusMissing indicates the add-on is installed on the hub but not enabled on the
+	// cluster
+	AddOnStatusMissing = "Missing"
+)
+
+// managedClusterAddOnGVR identifies the OCM ManagedClusterAddOn resources in cluster namespaces
+var managedClusterAddOnGVR = schema.GroupVersionResource{
+	Group:    "addon.open-cluster-management.io",
+	Version:  "v1alpha1",
+	Resource: "managedclusteraddons",
+}
+
+// clusterManagementAddOnGVR identifies the cluster-scoped OCM ClusterManagementAddOn resources,
+// one per add-on installed on the hub
+var clusterManagementAddOnGVR = schema.GroupVersionResource{
+	Group:    "addon.open-cluster-management.io",
+	Version:  "v1alpha1",
+	Resource: "clustermanagementaddons",
+}
+
+// AddOnInfo contains the state of an add-on of a managed cluster
+type AddOnInfo struct {
+	// Name is the add-on name, e.g. search-collector
+	Name string
+	// InstallNamespace is the namespace on the spoke the add-on agent runs in
+	InstallNamespace string
+	// Status is Available, Degraded, Progressing, Unavailable, or Unknown
+	Status string
+	// Message is the message of the condition the status was derived from
+	Message string
+}
+
+// ManagedClusterAddOnClient provides operations for the ACM add-ons of managed clusters
+type ManagedClusterAddOnClient interface {
+	// List retrieves the add-ons enabled on a cluster, sorted by name
+	List(ctx context.Context, cluster string) ([]AddOnInfo, error)
+	// ListAll retrieves the add-ons enabled on every cluster by cluster name, each sorted by name
+	ListAll(ctx context.Context) (map[string][]AddOnInfo, error)
+	// Available lists the names of the add-ons installed on the hub, sorted by name
+	Available(ctx context.Context) ([]string, error)
+	// Enable creates the ManagedClusterAddOn of an add-on installed on the hub in the cluster
+	// namespace. It returns false if the add-on was already enabled.
+	Enable(ctx context.Context, cluster, addon string) (bool, error)
+}
+
+type managedClusterAddOnClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewManagedClusterAddOnClient creates a new ManagedClusterAddOnClient
+func NewManagedClusterAddOnClient(dynamicClient dynamic.Interface, options ...kube.Option) ManagedClusterAddOnClient {
+	return &managedClusterAddOnClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// List retrieves the ManagedClusterAddOns in the namespace of the cluster
+func (c *managedClusterAddOnClient) List(ctx context.Context, cluster string) ([]AddOnInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list ManagedClusterAddOns", "cluster", cluster)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	list, err := c.dynamicClient.Resource(managedClusterAddOnGVR).Namespace(cluster).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ManagedClusterAddOns of %s: %w", cluster, err)
+	}
+
+	addons := make([]AddOnInfo, 0, len(list.Items))
+	for _, item := range list.Items {
+		addons = append(addons, parseManagedClusterAddOn(&item))
+	}
+	sort.Slice(addons, func(i, j int) bool { return addons[i].Name < addons[j].Name })
+	return addons, nil
+}
+
+// ListAll lists the ManagedClusterAddOns in all namespaces and groups them by namespace, which
+// is named after the cluster
+func (c *managedClusterAddOnClient) ListAll(ctx context.Context) (map[string][]AddOnInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list all ManagedClusterAddOns")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = c.dynamicClient.Resource(managedClusterAddOnGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ManagedClusterAddOns: %w", err)
+	}
+
+	addons := make(map[string][]AddOnInfo)
+	for _, item := range list.Items {
+		addons[item.GetNamespace()] = append(addons[item.GetNamespace()], parseManagedClusterAddOn(&item))
+	}
+	for _, clusterAddOns := range addons {
+		sort.Slice(clusterAddOns, func(i, j int) bool { return clusterAddOns[i].Name < clusterAddOns[j].Name })
+	}
+	return addons, nil
+}
+
+// Available lists the ClusterManagementAddOns of the hub
+func (c *managedClusterAddOnClient) Available(ctx context.Context) ([]string, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list ClusterManagementAddOns")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	list, err := c.dynamicClient.Resource(clusterManagementAddOnGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterManagementAddOns: %w", err)
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Enable creates the ManagedClusterAddOn named after the add-on in the cluster namespace, which
+// makes the add-on manager deploy the agent to the spoke
+func (c *managedClusterAddOnClient) Enable(ctx context.Context, cluster, addon string) (bool, error) {
+	ctx, cancel, err := c.options.Start(ctx, "enable ManagedClusterAddOn", "cluster", cluster, "addon", addon)
+	if err != nil {
+		return false, err
+	}
+	defer cancel()
+
+	_, err = c.dynamicClient.Resource(clusterManagementAddOnGVR).Get(ctx, addon, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("add-on %s is not installed on the hub", addon)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get ClusterManagementAddOn %s: %w", addon, err)
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": managedClusterAddOnGVR.GroupVersion().String(),
+		"kind":       "ManagedClusterAddOn",
+		"metadata": map[string]interface{}{
+			"name":      addon,
+			"namespace": cluster,
+		},
+		"spec": map[string]interface{}{
+			"installNamespace": DefaultAddOnInstallNamespace,
+		},
+	}}
+
+	_, err = c.dynamicClient.Resource(managedClusterAddOnGVR).Namespace(cluster).Create(ctx, obj, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create ManagedClusterAddOn %s/%s: %w", cluster, addon, err)
+	}
+	return true, nil
+}
+
+// parseManagedClusterAddOn extracts AddOnInfo from an unstructured ManagedClusterAddOn.
+// Degraded and Progressing take precedence over Available, as they explain why an add-on
+// is not (yet) working as expected.
+func parseManagedClusterAddOn(obj *unstructured.Unstructured) AddOnInfo {
+	info := AddOnInfo{Name: obj.GetName(), Status: AddOnStatusUnknown}
+	info.InstallNamespace, _, _ = unstructured.NestedString(obj.Object, "spec", "installNamespace")
+
+	conditions := map[string]map[string]interface{}{}
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range items {
+		if condition, ok := item.(map[string]interface{}); ok {
+			conditionType, _ := condition["type"].(string)
+			conditions[conditionType] = condition
+		}
+	}
+
+	hasStatus := func(conditionType string, want metav1.ConditionStatus) bool {
+		status, _ := conditions[conditionType]["status"].(string)
+		return status == string(want)
+	}
+	message := func(conditionType string) string {
+		msg, _ := conditions[conditionType]["message"].(string)
+		return msg
+	}
+
+	switch {
+	case hasStatus("Degraded", metav1.ConditionTrue):
+		info.Status, info.Message = AddOnStatusDegraded, message("Degraded")
+	case hasStatus("Progressing", metav1.ConditionTrue):
+		info.Status, info.Message = AddOnStatusProgressing, message("Progressing")
+	case hasStatus("Available", metav1.ConditionTrue):
+		info.Status, info.Message = AddOnStatusAvailable, message("Available")
+	case hasStatus("Available", metav1.ConditionFalse):
+		info.Status, info.Message = AddOnStatusUnavailable, message("Available")
+	}
+	return info
+}
+
+// AddOnMatrix is the status of every add-on on every cluster
+type AddOnMatrix struct {
+	// AddOns are the names of the add-ons, sorted
+	AddOns []string `json:"addons"`
+	// Clusters holds a row per cluster, sorted by cluster name
+	Clusters []AddOnMatrixRow `json:"clusters"`
+}
+
+// AddOnMatrixRow is the status of the add-ons of one cluster
+type AddOnMatrixRow struct {
+	// Cluster is the name of the cluster
+	Cluster string `json:"cluster"`
+	// Status maps each add-on to its status, Missing if it is not enabled on the cluster
+	Status map[string]string `json:"status"`
+}
+
+// NewAddOnMatrix builds the status matrix of clusters and add-ons from the add-ons enabled on
+// each cluster. Its columns are the add-ons in names, or, if names is empty, every add-on in
+// available or enabled on a cluster.
+func NewAddOnMatrix(clusters, available, names []string, enabled map[string][]AddOnInfo) AddOnMatrix {
+	columns := names
+	if len(columns) == 0 {
+		seen := make(map[string]bool)
+		for _, name := range available {
+			seen[name] = true
+		}
+		for _, addons := range enabled {
+			for _, addon := range addons {
+				seen[addon.Name] = true
+			}
+		}
+		for name := range seen {
+			columns = append(columns, name)
+		}
+	}
+	columns = append([]string(nil), columns...)
+	sort.Strings(columns)
+
+	rows := append([]string(nil), clusters...)
+	sort.Strings(rows)
+
+	matrix := AddOnMatrix{AddOns: columns, Clusters: make([]AddOnMatrixRow, 0, len(rows))}
+	for _, cluster := range rows {
+		row := AddOnMatrixRow{Cluster: cluster, Status: make(map[string]string, len(columns))}
+		for _, name := range columns {
+			row.Status[name] = AddOnStatusMissing
+		}
+		for _, addon := range enabled[cluster] {
+			if _, ok := row.Status[addon.Name]; ok {
+				row.Status[addon.Name] = addon.Status
+			}
+		}
+		matrix.Clusters = append(matrix.Clusters, row)
+	}
+	return matrix
+}
+
+// Healthy reports whether every add-on of the row is available
+func (r AddOnMatrixRow) Healthy() bool {
+	for _, status := range r.Status {
+		if status != AddOnStatusAvailable {
+			return false
+		}
+	}
+	return true
+}
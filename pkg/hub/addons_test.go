@@ -0,0 +1,197 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ManagedClusterAddOnClient", func() {
+	var (
+		ctx         context.Context
+		addOnGVR    schema.GroupVersionResource
+		clusterMgmt func(name string) *unstructured.Unstructured
+		addOn       func(cluster, name string, conditions ...interface{}) *unstructured.Unstructured
+		condition   func(conditionType, status, message string) interface{}
+		newClient   func(objects ...runtime.Object) *fake.FakeDynamicClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		addOnGVR = schema.GroupVersionResource{Group: "addon.open-cluster-management.io", Version: "v1alpha1", Resource: "managedclusteraddons"}
+
+		clusterMgmt = func(name string) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "addon.open-cluster-management.io/v1alpha1",
+				"kind":       "ClusterManagementAddOn",
+				"metadata":   map[string]interface{}{"name": name},
+			}}
+		}
+		addOn = func(cluster, name string, conditions ...interface{}) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "addon.open-cluster-management.io/v1alpha1",
+				"kind":       "ManagedClusterAddOn",
+				"metadata":   map[string]interface{}{"name": name, "namespace": cluster},
+				"spec":       map[string]interface{}{"installNamespace": hub.DefaultAddOnInstallNamespace},
+				"status":     map[string]interface{}{"conditions": conditions},
+			}}
+		}
+		condition = func(conditionType, status, message string) interface{} {
+			return map[string]interface{}{"type": conditionType, "status": status, "message": message}
+		}
+		newClient = func(objects ...runtime.Object) *fake.FakeDynamicClient {
+			return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				addOnGVR: "ManagedClusterAddOnList",
+				{Group: "addon.open-cluster-management.io", Version: "v1alpha1", Resource: "clustermanagementaddons"}: "ClusterManagementAddOnList",
+			}, objects...)
+		}
+	})
+
+	Describe("List", func() {
+		It("should list the add-ons of a cluster sorted by name with their status", func() {
+			client := hub.NewManagedClusterAddOnClient(newClient(
+				addOn("cluster-a", "search-collector", condition("Available", "True", "search-collector add-on is available.")),
+				addOn("cluster-a", "config-policy-controller",
+					condition("Available", "True", "available"),
+					condition("Degraded", "True", "policy controller is crash looping")),
+				addOn("cluster-a", "observability-controller", condition("Progressing", "True", "installing")),
+				addOn("cluster-a", "work-manager", condition("Available", "False", "lease not updated")),
+				addOn("cluster-a", "cluster-proxy"),
+				addOn("cluster-b", "search-collector"),
+			))
+
+			addons, err := client.List(ctx, "cluster-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addons).To(Equal([]hub.AddOnInfo{
+				{Name: "cluster-proxy", InstallNamespace: hub.DefaultAddOnInstallNamespace, Status: hub.AddOnStatusUnknown},
+				{Name: "config-policy-controller", InstallNamespace: hub.DefaultAddOnInstallNamespace, Status: hub.AddOnStatusDegraded, Message: "policy controller is crash looping"},
+				{Name: "observability-controller", InstallNamespace: hub.DefaultAddOnInstallNamespace, Status: hub.AddOnStatusProgressing, Message: "installing"},
+				{Name: "search-collector", InstallNamespace: hub.DefaultAddOnInstallNamespace, Status: hub.AddOnStatusAvailable, Message: "search-collector add-on is available."},
+				{Name: "work-manager", InstallNamespace: hub.DefaultAddOnInstallNamespace, Status: hub.AddOnStatusUnavailable, Message: "lease not updated"},
+			}))
+		})
+
+		It("should return an empty list for a cluster without add-ons", func() {
+			addons, err := hub.NewManagedClusterAddOnClient(newClient()).List(ctx, "cluster-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addons).To(BeEmpty())
+		})
+	})
+
+	Describe("ListAll", func() {
+		It("should group the add-ons of every cluster by cluster", func() {
+			client := hub.NewManagedClusterAddOnClient(newClient(
+				addOn("cluster-a", "work-manager", condition("Available", "True", "")),
+				addOn("cluster-a", "search-collector", condition("Degraded", "True", "crash looping")),
+				addOn("cluster-b", "work-manager"),
+			))
+
+			addons, err := client.ListAll(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addons).To(HaveLen(2))
+			Expect(addons["cluster-a"]).To(Equal([]hub.AddOnInfo{
+				{Name: "search-collector", InstallNamespace: hub.DefaultAddOnInstallNamespace, Status: hub.AddOnStatusDegraded, Message: "crash looping"},
+				{Name: "work-manager", InstallNamespace: hub.DefaultAddOnInstallNamespace, Status: hub.AddOnStatusAvailable},
+			}))
+			Expect(addons["cluster-b"]).To(Equal([]hub.AddOnInfo{
+				{Name: "work-manager", InstallNamespace: hub.DefaultAddOnInstallNamespace, Status: hub.AddOnStatusUnknown},
+			}))
+		})
+	})
+
+	Describe("Available", func() {
+		It("should list the add-ons installed on the hub sorted by name", func() {
+			client := hub.NewManagedClusterAddOnClient(newClient(clusterMgmt("search-collector"), clusterMgmt("application-manager")))
+
+			names, err := client.Available(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"application-manager", "search-collector"}))
+		})
+	})
+
+	Describe("Enable", func() {
+		It("should create the ManagedClusterAddOn in the cluster namespace", func() {
+			dynamicClient := newClient(clusterMgmt("search-collector"))
+
+			created, err := hub.NewManagedClusterAddOnClient(dynamicClient).Enable(ctx, "cluster-a", "search-collector")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+
+			obj, err := dynamicClient.Resource(addOnGVR).Namespace("cluster-a").Get(ctx, "search-collector", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			installNamespace, _, _ := unstructured.NestedString(obj.Object, "spec", "installNamespace")
+			Expect(installNamespace).To(Equal(hub.DefaultAddOnInstallNamespace))
+		})
+
+		It("should report an add-on that is already enabled", func() {
+			client := hub.NewManagedClusterAddOnClient(newClient(clusterMgmt("search-collector"), addOn("cluster-a", "search-collector")))
+
+			created, err := client.Enable(ctx, "cluster-a", "search-collector")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeFalse())
+		})
+
+		It("should reject an add-on that is not installed on the hub", func() {
+			_, err := hub.NewManagedClusterAddOnClient(newClient()).Enable(ctx, "cluster-a", "made-up")
+			Expect(err).To(MatchError(ContainSubstring("add-on made-up is not installed on the hub")))
+		})
+	})
+})
+
+var _ = Describe("NewAddOnMatrix", func() {
+	enabled := map[string][]hub.AddOnInfo{
+		"cluster-a": {
+			{Name: "search-collector", Status: hub.AddOnStatusAvailable},
+			{Name: "work-manager", Status: hub.AddOnStatusAvailable},
+		},
+		"cluster-b": {
+			{Name: "observability-controller", Status: hub.AddOnStatusDegraded},
+			{Name: "work-manager", Status: hub.AddOnStatusAvailable},
+		},
+		"deleted": {{Name: "work-manager", Status: hub.AddOnStatusUnknown}},
+	}
+
+	It("should mark the add-ons that are not enabled on a cluster as missing", func() {
+		matrix := hub.NewAddOnMatrix([]string{"cluster-b", "cluster-a", "cluster-c"}, []string{"work-manager", "search-collector"}, nil, enabled)
+
+		Expect(matrix.AddOns).To(Equal([]string{"observability-controller", "search-collector", "work-manager"}))
+		Expect(matrix.Clusters).To(Equal([]hub.AddOnMatrixRow{
+			{Cluster: "cluster-a", Status: map[string]string{
+				"observability-controller": hub.AddOnStatusMissing,
+				"search-collector":         hub.AddOnStatusAvailable,
+				"work-manager":             hub.AddOnStatusAvailable,
+			}},
+			{Cluster: "cluster-b", Status: map[string]string{
+				"observability-controller": hub.AddOnStatusDegraded,
+				"search-collector":         hub.AddOnStatusMissing,
+				"work-manager":             hub.AddOnStatusAvailable,
+			}},
+			{Cluster: "cluster-c", Status: map[string]string{
+				"observability-controller": hub.AddOnStatusMissing,
+				"search-collector":         hub.AddOnStatusMissing,
+				"work-manager":             hub.AddOnStatusMissing,
+			}},
+		}))
+		Expect(matrix.Clusters[0].Healthy()).To(BeFalse())
+	})
+
+	It("should only include the named add-ons", func() {
+		matrix := hub.NewAddOnMatrix([]string{"cluster-a", "cluster-b"}, nil, []string{"work-manager", "search-collector"}, enabled)
+
+		Expect(matrix.AddOns).To(Equal([]string{"search-collector", "work-manager"}))
+		Expect(matrix.Clusters[0].Healthy()).To(BeTrue())
+		Expect(matrix.Clusters[1].Status).To(Equal(map[string]string{
+			"search-collector": hub.AddOnStatusMissing,
+			"work-manager":     hub.AddOnStatusAvailable,
+		}))
+	})
+})
@@ -0,0 +1,57 @@
+package hub
+
+import (
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// Backend is a source of the cluster data of a hub. The clients of a Backend answer the same
+// questions whether they read the API server, an informer cache, fixture files, or the API of
+// labrat serve, so tools can embed labrat's logic with the data source that suits them.
+type Backend interface {
+	// ManagedClusters returns the client of the ManagedClusters of the hub
+	ManagedClusters() ManagedClusterClient
+	// ClusterDeployments returns the client of the Hive ClusterDeployments of the hub
+	ClusterDeployments() ClusterDeploymentClient
+	// ClusterInfos returns the client of the ManagedClusterInfos of the hub
+	ClusterInfos() ClusterInfoClient
+	// Clusters returns the client combining the ManagedClusters with their ClusterDeployments
+	// and ManagedClusterInfos
+	Clusters() CombinedClusterClient
+}
+
+// clientBackend is a Backend of existing clients
+type clientBackend struct {
+	managedClusters    ManagedClusterClient
+	clusterDeployments ClusterDeploymentClient
+	clusterInfos       ClusterInfoClient
+}
+
+func (b *clientBackend) ManagedClusters() ManagedClusterClient       { return b.managedClusters }
+func (b *clientBackend) ClusterDeployments() ClusterDeploymentClient { return b.clusterDeployments }
+func (b *clientBackend) ClusterInfos() ClusterInfoClient             { return b.clusterInfos }
+
+func (b *clientBackend) Clusters() CombinedClusterClient {
+	return NewCombinedClusterClient(b.managedClusters, b.clusterDeployments, b.clusterInfos)
+}
+
+// NewDynamicBackend creates a Backend reading the API server of the hub through dynamicClient
+func NewDynamicBackend(dynamicClient dynamic.Interface, options ...kube.Option) Backend {
+	return &clientBackend{
+		managedClusters:    NewManagedClusterClient(dynamicClient, options...),
+		clusterDeployments: NewClusterDeploymentClient(dynamicClient, options...),
+		clusterInfos:       NewClusterInfoClient(dynamicClient, options...),
+	}
+}
+
+// NewInformerBackend creates a Backend reading informerCache, which must have been created
+// with NewInformerCache with ClusterInfos. Lookups the cache cannot answer, such as
+// ManagedClusterClient.Get, go to the API server through dynamicClient.
+func NewInformerBackend(dynamicClient dynamic.Interface, informerCache kube.InformerCache, options ...kube.Option) Backend {
+	return &clientBackend{
+		managedClusters:    NewInformerManagedClusterClient(NewManagedClusterClient(dynamicClient, options...), informerCache),
+		clusterDeployments: NewInformerClusterDeploymentClient(informerCache),
+		clusterInfos:       NewInformerClusterInfoClient(informerCache),
+	}
+}
@@ -0,0 +1,61 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/kubefile"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("Backend", func() {
+	Describe("NewDynamicBackend", func() {
+		var (
+			ctx     context.Context
+			backend hub.Backend
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			client, err := kubefile.NewClient("../../test/fixtures")
+			Expect(err).NotTo(HaveOccurred())
+			backend = hub.NewDynamicBackend(client.GetDynamicClient())
+		})
+
+		It("should list the managed clusters of the fixtures", func() {
+			clusters, err := backend.ManagedClusters().List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusters).To(HaveLen(2))
+			Expect(clusters[0].Name).To(Equal("cluster-notready"))
+			Expect(clusters[0].Status).To(Equal(hub.StatusNotReady))
+			Expect(clusters[1].Name).To(Equal("cluster-ready"))
+			Expect(clusters[1].Status).To(Equal(hub.StatusReady))
+		})
+
+		It("should get the cluster deployments of the fixtures", func() {
+			deployment, err := backend.ClusterDeployments().Get(ctx, "test-cluster-hibernating")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployment.PowerState).To(Equal("Hibernating"))
+
+			deployments, err := backend.ClusterDeployments().List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployments).To(HaveLen(2))
+		})
+
+		It("should combine the clusters without ClusterInfos", func() {
+			clusters, err := backend.Clusters().ListCombined(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusters).To(HaveLen(2))
+			Expect(clusters[1].Name).To(Equal("cluster-ready"))
+			Expect(clusters[1].PowerState).To(Equal("N/A"))
+
+			infos, err := backend.ClusterInfos().List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(infos).To(BeEmpty())
+		})
+	})
+})
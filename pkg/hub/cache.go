@@ -0,0 +1,129 @@
+package hub
+
+import (
+	"context"
+)
+
+// ListCache stores list results between invocations, e.g. on disk. Lookups that fail are
+// treated as misses, so a broken cache only costs the API calls it would have saved.
+type ListCache interface {
+	// Get decodes the entry stored under key into v and reports whether a valid entry was found
+	Get(key string, v interface{}) bool
+	// Set stores v under key
+	Set(key string, v interface{}) error
+}
+
+// cachedManagedClusterClient answers List from a ListCache and delegates everything else
+type cachedManagedClusterClient struct {
+	ManagedClusterClient
+	cache ListCache
+	key   string
+}
+
+// NewCachedManagedClusterClient wraps client so List results are stored in cache under key,
+// e.g. the API server of the hub, and reused until the cache expires them. Watch always
+// reads from the hub.
+func NewCachedManagedClusterClient(client ManagedClusterClient, cache ListCache, key string) ManagedClusterClient {
+	return &cachedManagedClusterClient{ManagedClusterClient: client, cache: cache, key: key + "/managedclusters"}
+}
+
+// List returns the cached clusters, or lists them from the hub and caches them
+func (c *cachedManagedClusterClient) List(ctx context.Context) ([]ManagedClusterInfo, error) {
+	var clusters []ManagedClusterInfo
+	if c.cache.Get(c.key, &clusters) {
+		return clusters, nil
+	}
+
+	clusters, err := c.ManagedClusterClient.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Failing to cache only makes the next listing slower
+	_ = c.cache.Set(c.key, clusters)
+	return clusters, nil
+}
+
+// cachedClusterDeploymentClient answers List from a ListCache and delegates everything else
+type cachedClusterDeploymentClient struct {
+	ClusterDeploymentClient
+	cache ListCache
+	key   string
+}
+
+// NewCachedClusterDeploymentClient wraps client so List results are stored in cache under
+// key and reused until the cache expires them. Get and FindByRequestID always read from the
+// hub.
+func NewCachedClusterDeploymentClient(client ClusterDeploymentClient, cache ListCache, key string) ClusterDeploymentClient {
+	return &cachedClusterDeploymentClient{ClusterDeploymentClient: client, cache: cache, key: key + "/clusterdeployments"}
+}
+
+// List returns the cached ClusterDeployments, or lists them from the hub and caches them.
+// Lists with ClusterDeployments that cannot be decoded are returned but not cached.
+func (c *cachedClusterDeploymentClient) List(ctx context.Context) ([]ClusterDeploymentInfo, error) {
+	var deployments []ClusterDeploymentInfo
+	if c.cache.Get(c.key, &deployments) {
+		return deployments, nil
+	}
+
+	deployments, err := c.ClusterDeploymentClient.List(ctx)
+	if err != nil {
+		return deployments, err
+	}
+	_ = c.cache.Set(c.key, deployments)
+	return deployments, nil
+}
+
+// cachedCombinedClusterClient answers ListCombined from a ListCache
+type cachedCombinedClusterClient struct {
+	client CombinedClusterClient
+	cache  ListCache
+	key    string
+}
+
+// NewCachedCombinedClusterClient wraps client so ListCombined results, which take one
+// ClusterDeployment and ManagedClusterInfo lookup per cluster, are stored in cache under key
+// and reused until the cache expires them
+func NewCachedCombinedClusterClient(client CombinedClusterClient, cache ListCache, key string) CombinedClusterClient {
+	return &cachedCombinedClusterClient{client: client, cache: cache, key: key + "/combined"}
+}
+
+// ListCombined returns the cached clusters, or lists them from the hub and caches them
+func (c *cachedCombinedClusterClient) ListCombined(ctx context.Context) ([]CombinedClusterInfo, error) {
+	var clusters []CombinedClusterInfo
+	if c.cache.Get(c.key, &clusters) {
+		return clusters, nil
+	}
+
+	clusters, err := c.client.ListCombined(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.cache.Set(c.key, clusters)
+	return clusters, nil
+}
+
+// cachedBackend is a Backend whose ManagedCluster, ClusterDeployment, and combined lists are
+// answered from a ListCache
+type cachedBackend struct {
+	Backend
+	cache ListCache
+	key   string
+}
+
+// NewCachedBackend wraps backend so the lists of its ManagedClusters, ClusterDeployments, and
+// combined clusters are stored in cache under key, as the cached clients of this file do
+func NewCachedBackend(backend Backend, cache ListCache, key string) Backend {
+	return &cachedBackend{Backend: backend, cache: cache, key: key}
+}
+
+func (b *cachedBackend) ManagedClusters() ManagedClusterClient {
+	return NewCachedManagedClusterClient(b.Backend.ManagedClusters(), b.cache, b.key)
+}
+
+func (b *cachedBackend) ClusterDeployments() ClusterDeploymentClient {
+	return NewCachedClusterDeploymentClient(b.Backend.ClusterDeployments(), b.cache, b.key)
+}
+
+func (b *cachedBackend) Clusters() CombinedClusterClient {
+	return NewCachedCombinedClusterClient(b.Backend.Clusters(), b.cache, b.key)
+}
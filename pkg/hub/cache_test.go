@@ -0,0 +1,134 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/cache"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("Cached clients", func() {
+	var (
+		ctx       context.Context
+		listCache *cache.Cache
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		listCache = cache.New(GinkgoT().TempDir(), time.Minute)
+	})
+
+	Describe("NewCachedManagedClusterClient", func() {
+		var inner *countingManagedClusterClient
+
+		BeforeEach(func() {
+			inner = &countingManagedClusterClient{}
+			inner.managedClusters = []hub.ManagedClusterInfo{{Name: "spoke", Status: hub.StatusReady, Available: "True"}}
+		})
+
+		It("should list from the hub once and then from the cache", func() {
+			client := hub.NewCachedManagedClusterClient(inner, listCache, "https://api.hub-a.example.com:6443")
+
+			first, err := client.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := client.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(second).To(Equal(first))
+			Expect(inner.lists).To(Equal(1))
+		})
+
+		It("should keep the lists of different hubs apart", func() {
+			_, err := hub.NewCachedManagedClusterClient(inner, listCache, "https://api.hub-a.example.com:6443").List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = hub.NewCachedManagedClusterClient(inner, listCache, "https://api.hub-b.example.com:6443").List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(inner.lists).To(Equal(2))
+		})
+
+		It("should not cache failed lists", func() {
+			inner.err = errors.New("connection refused")
+			client := hub.NewCachedManagedClusterClient(inner, listCache, "https://api.hub-a.example.com:6443")
+
+			_, err := client.List(ctx)
+			Expect(err).To(MatchError("connection refused"))
+
+			inner.err = nil
+			clusters, err := client.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusters).To(HaveLen(1))
+			Expect(inner.lists).To(Equal(2))
+		})
+	})
+
+	Describe("NewCachedCombinedClusterClient", func() {
+		It("should list from the hub once and then from the cache", func() {
+			inner := &countingManagedClusterClient{}
+			inner.managedClusters = []hub.ManagedClusterInfo{{Name: "spoke", Status: hub.StatusReady}}
+			cdClient := newMockClusterDeploymentClientForCombined()
+			cdClient.clusterDeployments = map[string]*hub.ClusterDeploymentInfo{
+				"spoke": {Name: "spoke", Namespace: "spoke", Platform: "aws", PowerState: "Running"},
+			}
+			client := hub.NewCachedCombinedClusterClient(hub.NewCombinedClusterClient(inner, cdClient, nil), listCache, "https://api.hub-a.example.com:6443")
+
+			first, err := client.ListCombined(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := client.ListCombined(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(second).To(HaveLen(1))
+			Expect(second[0].Platform).To(Equal("aws"))
+			Expect(second).To(Equal(first))
+			Expect(inner.lists).To(Equal(1))
+		})
+	})
+
+	Describe("NewCachedBackend", func() {
+		It("should share the cached lists between the clients of the backend", func() {
+			inner := &countingManagedClusterClient{}
+			inner.managedClusters = []hub.ManagedClusterInfo{{Name: "spoke", Status: hub.StatusReady}}
+			backend := hub.NewCachedBackend(&managedClustersBackend{managedClusters: inner}, listCache, "https://api.hub-a.example.com:6443")
+
+			first, err := backend.ManagedClusters().List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := backend.ManagedClusters().List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(second).To(Equal(first))
+			Expect(inner.lists).To(Equal(1))
+		})
+	})
+})
+
+// countingManagedClusterClient counts the lists it answers
+type countingManagedClusterClient struct {
+	mockManagedClusterClientForCombined
+	lists int
+	err   error
+}
+
+func (m *countingManagedClusterClient) List(ctx context.Context) ([]hub.ManagedClusterInfo, error) {
+	m.lists++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.mockManagedClusterClientForCombined.List(ctx)
+}
+
+// managedClustersBackend is a Backend serving only its ManagedClusterClient
+type managedClustersBackend struct {
+	hub.Backend
+	managedClusters hub.ManagedClusterClient
+}
+
+func (b *managedClustersBackend) ManagedClusters() hub.ManagedClusterClient {
+	return b.managedClusters
+}
@@ -0,0 +1,83 @@
+package hub
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RegionUsage aggregates the clusters of a platform in a region
+type RegionUsage struct {
+	Platform    string `json:"platform"`
+	Region      string `json:"region"`
+	Clusters    int    `json:"clusters"`
+	Running     int    `json:"running"`
+	Hibernating int    `json:"hibernating"`
+	// Workers counts the worker machines of the clusters by instance type
+	Workers map[string]int64 `json:"workers,omitempty"`
+}
+
+// SummarizeUsage aggregates deployments per platform and region, sorted by platform and then
+// region. workers maps cluster names to their worker machines by instance type, e.g. from
+// their MachinePools.
+func SummarizeUsage(deployments []ClusterDeploymentInfo, workers map[string]map[string]int64) []RegionUsage {
+	byRegion := make(map[[2]string]*RegionUsage)
+	for _, deployment := range deployments {
+		key := [2]string{valueOrNA(deployment.Platform), valueOrNA(deployment.Region)}
+		usage, ok := byRegion[key]
+		if !ok {
+			usage = &RegionUsage{Platform: key[0], Region: key[1]}
+			byRegion[key] = usage
+		}
+
+		usage.Clusters++
+		switch deployment.PowerState {
+		case "Running":
+			usage.Running++
+		case "Hibernating":
+			usage.Hibernating++
+		}
+		for instanceType, machines := range workers[deployment.Name] {
+			if usage.Workers == nil {
+				usage.Workers = make(map[string]int64)
+			}
+			usage.Workers[valueOrNA(instanceType)] += machines
+		}
+	}
+
+	usages := make([]RegionUsage, 0, len(byRegion))
+	for _, usage := range byRegion {
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Platform != usages[j].Platform {
+			return usages[i].Platform < usages[j].Platform
+		}
+		return usages[i].Region < usages[j].Region
+	})
+	return usages
+}
+
+// FormatWorkers formats worker counts as e.g. "m6i.xlarge×24, m6i.2xlarge×6", most machines
+// first
+func FormatWorkers(workers map[string]int64) string {
+	types := make([]string, 0, len(workers))
+	for instanceType := range workers {
+		types = append(types, instanceType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if workers[types[i]] != workers[types[j]] {
+			return workers[types[i]] > workers[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	parts := make([]string, 0, len(types))
+	for _, instanceType := range types {
+		parts = append(parts, fmt.Sprintf("%s×%d", instanceType, workers[instanceType]))
+	}
+	if len(parts) == 0 {
+		return "N/A"
+	}
+	return strings.Join(parts, ", ")
+}
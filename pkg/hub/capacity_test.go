@@ -0,0 +1,37 @@
+//go:build test
+
+package hub_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("SummarizeUsage", func() {
+	deployments := []hub.ClusterDeploymentInfo{
+		{Name: "partner-a", Platform: "aws", Region: "us-east-1", PowerState: "Running"},
+		{Name: "partner-b", Platform: "aws", Region: "us-east-1", PowerState: "Hibernating"},
+		{Name: "partner-c", Platform: "aws", Region: "us-east-1", PowerState: "Unknown"},
+		{Name: "partner-d", Platform: "gcp", Region: "us-east1", PowerState: "Running"},
+		{Name: "baremetal", PowerState: "Running"},
+	}
+
+	It("should count the clusters and workers of each platform and region", func() {
+		workers := map[string]map[string]int64{
+			"partner-a": {"m6i.xlarge": 3},
+			"partner-b": {"m6i.xlarge": 3, "m6i.2xlarge": 2},
+		}
+
+		Expect(hub.SummarizeUsage(deployments, workers)).To(Equal([]hub.RegionUsage{
+			{Platform: "N/A", Region: "N/A", Clusters: 1, Running: 1},
+			{Platform: "aws", Region: "us-east-1", Clusters: 3, Running: 1, Hibernating: 1, Workers: map[string]int64{"m6i.xlarge": 6, "m6i.2xlarge": 2}},
+			{Platform: "gcp", Region: "us-east1", Clusters: 1, Running: 1},
+		}))
+	})
+
+	It("should format workers with the most common instance type first", func() {
+		Expect(hub.FormatWorkers(map[string]int64{"m6i.2xlarge": 2, "m6i.xlarge": 6, "c6i.xlarge": 2})).To(Equal("m6i.xlarge×6, c6i.xlarge×2, m6i.2xlarge×2"))
+		Expect(hub.FormatWorkers(nil)).To(Equal("N/A"))
+	})
+})
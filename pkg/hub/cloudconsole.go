@@ -0,0 +1,50 @@
+package hub
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// CloudConsoleOptions provides account information that is not recorded on the ClusterDeployment
+// but is needed to build console links for some platforms
+type CloudConsoleOptions struct {
+	// AzureSubscriptionID is the subscription containing the cluster's resource group
+	AzureSubscriptionID string
+	// GCPProjectID is the project containing the cluster's resources
+	GCPProjectID string
+}
+
+// CloudConsoleURL builds a deep link to the cloud provider console showing the cluster's
+// resources, filtered by its infrastructure ID:
+//   - AWS: EC2 instances tagged kubernetes.io/cluster/<infraID>=owned
+//   - Azure: the <infraID>-rg resource group created by the installer
+//   - GCP: Compute Engine instances labeled kubernetes-io-cluster-<infraID>=owned
+func (c *ClusterDeploymentInfo) CloudConsoleURL(opts CloudConsoleOptions) (string, error) {
+	if c.InfraID == "" {
+		return "", fmt.Errorf("cluster %s has no infraID (is it installed?)", c.Name)
+	}
+
+	switch c.Platform {
+	case "aws":
+		if c.Region == "" {
+			return "", fmt.Errorf("cluster %s has no region", c.Name)
+		}
+		return fmt.Sprintf("https://%[1]s.console.aws.amazon.com/ec2/home?region=%[1]s#Instances:tag:kubernetes.io/cluster/%[2]s=owned",
+			c.Region, c.InfraID), nil
+	case "azure":
+		if opts.AzureSubscriptionID == "" {
+			return "", fmt.Errorf("an Azure subscription ID is required to link to cluster %s", c.Name)
+		}
+		return fmt.Sprintf("https://portal.azure.com/#@/resource/subscriptions/%s/resourceGroups/%s-rg/overview",
+			url.PathEscape(opts.AzureSubscriptionID), url.PathEscape(c.InfraID)), nil
+	case "gcp":
+		if opts.GCPProjectID == "" {
+			return "", fmt.Errorf("a GCP project ID is required to link to cluster %s", c.Name)
+		}
+		filter := fmt.Sprintf("labels.kubernetes-io-cluster-%s:owned", c.InfraID)
+		return fmt.Sprintf("https://console.cloud.google.com/compute/instances?project=%s&q=%s",
+			url.QueryEscape(opts.GCPProjectID), url.QueryEscape(filter)), nil
+	default:
+		return "", fmt.Errorf("cloud console links are not supported for platform %q", c.Platform)
+	}
+}
@@ -0,0 +1,68 @@
+//go:build test
+
+package hub_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("CloudConsoleURL", func() {
+	var info *hub.ClusterDeploymentInfo
+
+	BeforeEach(func() {
+		info = &hub.ClusterDeploymentInfo{
+			Name:    "my-cluster",
+			Region:  "us-east-2",
+			InfraID: "my-cluster-abc12",
+		}
+	})
+
+	It("should link to EC2 instances tagged with the infra ID on AWS", func() {
+		info.Platform = "aws"
+
+		url, err := info.CloudConsoleURL(hub.CloudConsoleOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(url).To(Equal("https://us-east-2.console.aws.amazon.com/ec2/home?region=us-east-2#Instances:tag:kubernetes.io/cluster/my-cluster-abc12=owned"))
+	})
+
+	It("should link to the installer resource group on Azure", func() {
+		info.Platform = "azure"
+
+		url, err := info.CloudConsoleURL(hub.CloudConsoleOptions{AzureSubscriptionID: "sub-1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(url).To(Equal("https://portal.azure.com/#@/resource/subscriptions/sub-1/resourceGroups/my-cluster-abc12-rg/overview"))
+	})
+
+	It("should require a subscription on Azure", func() {
+		info.Platform = "azure"
+
+		_, err := info.CloudConsoleURL(hub.CloudConsoleOptions{})
+		Expect(err).To(MatchError(ContainSubstring("subscription")))
+	})
+
+	It("should link to labeled instances in the project on GCP", func() {
+		info.Platform = "gcp"
+
+		url, err := info.CloudConsoleURL(hub.CloudConsoleOptions{GCPProjectID: "lab-project"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(url).To(HavePrefix("https://console.cloud.google.com/compute/instances?project=lab-project&q="))
+		Expect(url).To(ContainSubstring("kubernetes-io-cluster-my-cluster-abc12"))
+	})
+
+	It("should fail when the cluster has no infra ID", func() {
+		info.Platform = "aws"
+		info.InfraID = ""
+
+		_, err := info.CloudConsoleURL(hub.CloudConsoleOptions{})
+		Expect(err).To(MatchError(ContainSubstring("no infraID")))
+	})
+
+	It("should fail for unsupported platforms", func() {
+		info.Platform = "baremetal"
+
+		_, err := info.CloudConsoleURL(hub.CloudConsoleOptions{})
+		Expect(err).To(MatchError(ContainSubstring("not supported")))
+	})
+})
@@ -2,41 +2,66 @@ package hub
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hive"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
 )
 
+// RequestIDLabel records the partner request a ClusterDeployment was provisioned for
+const RequestIDLabel = "labrat.openshift-partner-labs.io/request-id"
+
+// clusterDeploymentGVR identifies Hive ClusterDeployment resources
+var clusterDeploymentGVR = hive.ClusterDeploymentGVR
+
 // ClusterDeploymentClient provides operations for interacting with Hive ClusterDeployment resources
 type ClusterDeploymentClient interface {
 	// Get retrieves a ClusterDeployment by name from the namespace with the same name
 	Get(ctx context.Context, name string) (*ClusterDeploymentInfo, error)
+	// List retrieves all ClusterDeployments in all namespaces. ClusterDeployments that cannot be
+	// decoded are reported in a *ClusterDeploymentDecodeError returned along with the others.
+	List(ctx context.Context) ([]ClusterDeploymentInfo, error)
+	// FindByRequestID retrieves the ClusterDeployment labeled with a request ID, or nil if there is none
+	FindByRequestID(ctx context.Context, requestID string) (*ClusterDeploymentInfo, error)
 }
 
 type clusterDeploymentClient struct {
 	dynamicClient dynamic.Interface
+	options       kube.Options
 }
 
 // NewClusterDeploymentClient creates a new ClusterDeploymentClient
-func NewClusterDeploymentClient(dynamicClient dynamic.Interface) ClusterDeploymentClient {
+func NewClusterDeploymentClient(dynamicClient dynamic.Interface, options ...kube.Option) ClusterDeploymentClient {
 	return &clusterDeploymentClient{
 		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
 	}
 }
 
 // Get retrieves a ClusterDeployment from the namespace matching the cluster name
 func (c *clusterDeploymentClient) Get(ctx context.Context, name string) (*ClusterDeploymentInfo, error) {
-	// Define the GVR for ClusterDeployment
-	gvr := schema.GroupVersionResource{
-		Group:    "hive.openshift.io",
-		Version:  "v1",
-		Resource: "clusterdeployments",
+	ctx, cancel, err := c.options.Start(ctx, "get ClusterDeployment", "name", name)
+	if err != nil {
+		return nil, err
 	}
+	defer cancel()
 
 	// Get the ClusterDeployment from namespace=name
-	unstructuredCD, err := c.dynamicClient.Resource(gvr).Namespace(name).Get(ctx, name, metav1.GetOptions{})
+	var unstructuredCD *unstructured.Unstructured
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		unstructuredCD, err = c.dynamicClient.Resource(clusterDeploymentGVR).Namespace(name).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ClusterDeployment %s: %w", name, err)
 	}
@@ -50,75 +75,190 @@ func (c *clusterDeploymentClient) Get(ctx context.Context, name string) (*Cluste
 	return info, nil
 }
 
-// parseClusterDeployment extracts ClusterDeploymentInfo from an unstructured object
-func parseClusterDeployment(obj map[string]interface{}) (*ClusterDeploymentInfo, error) {
-	info := &ClusterDeploymentInfo{}
+// List retrieves all ClusterDeployments in all namespaces
+func (c *clusterDeploymentClient) List(ctx context.Context) ([]ClusterDeploymentInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list ClusterDeployments")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = c.dynamicClient.Resource(clusterDeploymentGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterDeployments: %w", err)
+	}
 
-	// Extract metadata
-	metadata, ok := obj["metadata"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("metadata not found or invalid")
+	deployments := make([]ClusterDeploymentInfo, 0, len(list.Items))
+	var failed []error
+	for i := range list.Items {
+		if info := parseListedClusterDeployment(&list.Items[i], &failed); info != nil {
+			deployments = append(deployments, *info)
+		}
 	}
 
-	if name, ok := metadata["name"].(string); ok {
-		info.Name = name
+	return deployments, decodeError(failed)
+}
+
+// FindByRequestID lists ClusterDeployments in all namespaces labeled with the request ID.
+// A request maps to at most one cluster, so multiple matches are reported as an error.
+func (c *clusterDeploymentClient) FindByRequestID(ctx context.Context, requestID string) (*ClusterDeploymentInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "find ClusterDeployment by request ID", "requestID", requestID)
+	if err != nil {
+		return nil, err
 	}
+	defer cancel()
 
-	if namespace, ok := metadata["namespace"].(string); ok {
-		info.Namespace = namespace
+	selector := labels.SelectorFromSet(labels.Set{RequestIDLabel: requestID})
+	var list *unstructured.UnstructuredList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = c.dynamicClient.Resource(clusterDeploymentGVR).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterDeployments for request %s: %w", requestID, err)
 	}
 
-	// Extract labels for platform and region
-	if labels, ok := metadata["labels"].(map[string]interface{}); ok {
-		if platform, ok := labels["hive.openshift.io/cluster-platform"].(string); ok {
-			info.Platform = platform
+	switch len(list.Items) {
+	case 0:
+		return nil, nil
+	case 1:
+		info, err := parseClusterDeployment(list.Items[0].Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ClusterDeployment %s: %w", list.Items[0].GetName(), err)
 		}
-		if region, ok := labels["hive.openshift.io/cluster-region"].(string); ok {
-			info.Region = region
+		return info, nil
+	default:
+		names := make([]string, 0, len(list.Items))
+		for _, item := range list.Items {
+			names = append(names, item.GetNamespace()+"/"+item.GetName())
 		}
+		return nil, fmt.Errorf("request %s maps to multiple ClusterDeployments: %s", requestID, strings.Join(names, ", "))
 	}
+}
 
-	// Extract spec fields
-	if spec, ok := obj["spec"].(map[string]interface{}); ok {
-		// Power state from spec
-		if powerState, ok := spec["powerState"].(string); ok {
-			info.PowerState = powerState
-		}
+// ClusterDeploymentDecodeError reports the ClusterDeployments of a list that cannot be decoded.
+// Lists return it along with the ClusterDeployments that can, so listings shown to users can
+// warn and go on, while callers deciding what no longer exists, such as GarbageCollector, fail.
+type ClusterDeploymentDecodeError struct {
+	// Errs holds the decoding error of each ClusterDeployment, naming it
+	Errs []error
+}
 
-		// Installed status
-		if installed, ok := spec["installed"].(bool); ok {
-			info.Installed = installed
-		}
+func (e *ClusterDeploymentDecodeError) Error() string {
+	return fmt.Sprintf("failed to decode %d ClusterDeployment(s): %v", len(e.Errs), errors.Join(e.Errs...))
+}
 
-		// Extract kubeconfig secret reference from clusterMetadata
-		if clusterMetadata, ok := spec["clusterMetadata"].(map[string]interface{}); ok {
-			if adminKubeconfigRef, ok := clusterMetadata["adminKubeconfigSecretRef"].(map[string]interface{}); ok {
-				if name, ok := adminKubeconfigRef["name"].(string); ok {
-					info.KubeconfigSecretName = name
-					// Secret is in the same namespace as the ClusterDeployment
-					info.KubeconfigSecretNS = info.Namespace
-				}
-			}
-		}
+func (e *ClusterDeploymentDecodeError) Unwrap() []error {
+	return e.Errs
+}
+
+// IsClusterDeploymentDecodeError reports whether err is only a ClusterDeploymentDecodeError,
+// i.e. the list it was returned with is complete except for the undecodable objects
+func IsClusterDeploymentDecodeError(err error) bool {
+	var decodeErr *ClusterDeploymentDecodeError
+	return errors.As(err, &decodeErr)
+}
+
+// parseListedClusterDeployment parses a ClusterDeployment of a list. One that cannot be parsed
+// is skipped with nil and its error added to failed, for decodeError.
+func parseListedClusterDeployment(obj *unstructured.Unstructured, failed *[]error) *ClusterDeploymentInfo {
+	info, err := parseClusterDeployment(obj.Object)
+	if err != nil {
+		*failed = append(*failed, fmt.Errorf("ClusterDeployment %s/%s: %w", obj.GetNamespace(), obj.GetName(), err))
+		return nil
 	}
+	return info
+}
 
-	// Extract status fields
-	if status, ok := obj["status"].(map[string]interface{}); ok {
-		if apiURL, ok := status["apiURL"].(string); ok {
-			info.APIUrl = apiURL
-		}
+// decodeError returns the ClusterDeploymentDecodeError of failed, nil if it is empty
+func decodeError(failed []error) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return &ClusterDeploymentDecodeError{Errs: failed}
+}
+
+// parseClusterDeployment extracts ClusterDeploymentInfo from an unstructured object. Fields
+// of the wrong type fail the parsing; the region falls back to the platform of the spec for
+// ClusterDeployments without the region label of Hive.
+func parseClusterDeployment(obj map[string]interface{}) (*ClusterDeploymentInfo, error) {
+	cd, err := hive.ClusterDeploymentFromUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ClusterDeploymentInfo{
+		Name:       cd.Name,
+		Namespace:  cd.Namespace,
+		Platform:   cd.Labels[hive.ClusterPlatformLabel],
+		Region:     cd.Labels[hive.ClusterRegionLabel],
+		RequestID:  cd.Labels[RequestIDLabel],
+		PowerState: cd.Spec.PowerState,
+		Installed:  cd.Spec.Installed,
+		APIUrl:     cd.Status.APIURL,
+		ConsoleURL: cd.Status.WebConsoleURL,
+	}
+	if info.Region == "" {
+		info.Region = cd.Spec.Platform.Region()
+	}
 
-		if consoleURL, ok := status["webConsoleURL"].(string); ok {
-			info.ConsoleURL = consoleURL
+	// A lease that cannot be parsed is ignored rather than failing the whole list
+	if expiry, ok := cd.Annotations[LeaseExpiryAnnotation]; ok {
+		if expiresAt, err := time.Parse(time.RFC3339, expiry); err == nil {
+			info.ExpiresAt = &expiresAt
 		}
+	}
+
+	if cd.Spec.ClusterPoolRef != nil {
+		info.ClusterPool = cd.Spec.ClusterPoolRef.PoolName
+	}
 
-		if version, ok := status["installVersion"].(string); ok {
-			info.Version = version
+	if metadata := cd.Spec.ClusterMetadata; metadata != nil {
+		info.ClusterID = metadata.ClusterID
+		info.InfraID = metadata.InfraID
+		if metadata.AdminKubeconfigSecretRef.Name != "" {
+			info.KubeconfigSecretName = metadata.AdminKubeconfigSecretRef.Name
+			// Secret is in the same namespace as the ClusterDeployment
+			info.KubeconfigSecretNS = info.Namespace
+		}
+		if metadata.AdminPasswordSecretRef != nil {
+			info.AdminPasswordSecretName = metadata.AdminPasswordSecretRef.Name
 		}
+	}
+
+	if cd.Status.InstallVersion != nil {
+		info.Version = *cd.Status.InstallVersion
+	}
+	if started := cd.Status.InstallStartedTimestamp; started != nil {
+		startedAt := started.UTC()
+		info.InstallStartedAt = &startedAt
+	}
+	if installed := cd.Status.InstalledTimestamp; installed != nil {
+		installedAt := installed.UTC()
+		info.InstalledAt = &installedAt
+	}
+
+	// Power state from status (takes precedence over spec)
+	if cd.Status.PowerState != "" {
+		info.PowerState = cd.Status.PowerState
+	}
 
-		// Power state from status (takes precedence over spec)
-		if powerState, ok := status["powerState"].(string); ok {
-			info.PowerState = powerState
+	for _, condition := range cd.Status.Conditions {
+		info.Conditions = append(info.Conditions, ClusterDeploymentCondition{
+			Type:               condition.Type,
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime.UTC(),
+		})
+		if condition.Type == hive.ProvisionFailedCondition && condition.Status == corev1.ConditionTrue {
+			info.ProvisionFailed = true
 		}
 	}
 
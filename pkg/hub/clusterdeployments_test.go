@@ -5,11 +5,13 @@ package hub_test
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
@@ -49,9 +51,83 @@ var _ = Describe("ClusterDeploymentClient", func() {
 				Expect(info.ConsoleURL).To(Equal("https://console.test-cluster-running.example.com"))
 				Expect(info.KubeconfigSecretName).To(Equal("test-cluster-running-admin-kubeconfig"))
 				Expect(info.KubeconfigSecretNS).To(Equal("test-cluster-running"))
+				Expect(info.AdminPasswordSecretName).To(Equal("test-cluster-running-admin-password"))
 				Expect(info.Platform).To(Equal("aws"))
 				Expect(info.Region).To(Equal("us-east-1"))
 				Expect(info.Version).To(Equal("4.20.6"))
+				Expect(info.InfraID).To(Equal("test-cluster-running-x7k2p"))
+				Expect(info.RequestID).To(Equal("1234"))
+				Expect(info.ProvisionFailed).To(BeFalse())
+				Expect(info.ClusterPool).To(BeEmpty())
+				Expect(info.ClusterID).To(Equal("abc123-test-cluster-id"))
+				Expect(info.Conditions).To(Equal([]hub.ClusterDeploymentCondition{
+					{Type: "Provisioned", Status: "True", Reason: "Provisioned", Message: "Cluster is provisioned"},
+				}))
+			})
+
+			It("should fall back to the region of the platform without the region label", func() {
+				cd, err := helpers.LoadClusterDeploymentFromFile("../../test/fixtures/clusterdeployment_running.yaml")
+				Expect(err).NotTo(HaveOccurred())
+				unstructured.RemoveNestedField(cd.Object, "metadata", "labels", "hive.openshift.io/cluster-region")
+				Expect(unstructured.SetNestedField(cd.Object, "us-west-2", "spec", "platform", "aws", "region")).To(Succeed())
+
+				mockDynamicClient.clusterDeployments["test-cluster-running"] = cd
+
+				info, err := client.Get(context.Background(), "test-cluster-running")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Region).To(Equal("us-west-2"))
+			})
+
+			It("should fail on fields of the wrong type instead of dropping them", func() {
+				cd, err := helpers.LoadClusterDeploymentFromFile("../../test/fixtures/clusterdeployment_running.yaml")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(unstructured.SetNestedField(cd.Object, "yes", "spec", "installed")).To(Succeed())
+
+				mockDynamicClient.clusterDeployments["test-cluster-running"] = cd
+
+				_, err = client.Get(context.Background(), "test-cluster-running")
+				Expect(err).To(MatchError(ContainSubstring("failed to parse ClusterDeployment test-cluster-running: failed to decode ClusterDeployment")))
+			})
+
+			It("should report the ClusterPool of a pool-created cluster", func() {
+				cd, err := helpers.LoadClusterDeploymentFromFile("../../test/fixtures/clusterdeployment_running.yaml")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(unstructured.SetNestedField(cd.Object, "partner-pool", "spec", "clusterPoolRef", "poolName")).To(Succeed())
+
+				mockDynamicClient.clusterDeployments["test-cluster-running"] = cd
+
+				info, err := client.Get(context.Background(), "test-cluster-running")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.ClusterPool).To(Equal("partner-pool"))
+			})
+
+			It("should report a failed provision", func() {
+				cd, err := helpers.LoadClusterDeploymentFromFile("../../test/fixtures/clusterdeployment_running.yaml")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(unstructured.SetNestedSlice(cd.Object, []interface{}{
+					map[string]interface{}{"type": "ProvisionFailed", "status": "True", "reason": "InstallFailed"},
+				}, "status", "conditions")).To(Succeed())
+
+				mockDynamicClient.clusterDeployments["test-cluster-running"] = cd
+
+				info, err := client.Get(context.Background(), "test-cluster-running")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.ProvisionFailed).To(BeTrue())
+			})
+
+			It("should report when the install started and completed", func() {
+				cd, err := helpers.LoadClusterDeploymentFromFile("../../test/fixtures/clusterdeployment_running.yaml")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(unstructured.SetNestedField(cd.Object, "2026-01-10T08:00:00Z", "status", "installStartedTimestamp")).To(Succeed())
+				Expect(unstructured.SetNestedField(cd.Object, "2026-01-10T08:42:30Z", "status", "installedTimestamp")).To(Succeed())
+
+				mockDynamicClient.clusterDeployments["test-cluster-running"] = cd
+
+				info, err := client.Get(context.Background(), "test-cluster-running")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.InstallStartedAt).NotTo(BeNil())
+				Expect(info.InstalledAt).NotTo(BeNil())
+				Expect(info.InstalledAt.Sub(*info.InstallStartedAt)).To(Equal(42*time.Minute + 30*time.Second))
 			})
 
 			It("should return ClusterDeployment info for a hibernating cluster", func() {
@@ -78,6 +154,85 @@ var _ = Describe("ClusterDeploymentClient", func() {
 			})
 		})
 	})
+
+	Describe("List", func() {
+		It("should return ClusterDeployments from all namespaces", func() {
+			for name, file := range map[string]string{
+				"test-cluster-running":     "../../test/fixtures/clusterdeployment_running.yaml",
+				"test-cluster-hibernating": "../../test/fixtures/clusterdeployment_hibernating.yaml",
+			} {
+				cd, err := helpers.LoadClusterDeploymentFromFile(file)
+				Expect(err).NotTo(HaveOccurred())
+				mockDynamicClient.clusterDeployments[name] = cd
+			}
+
+			deployments, err := client.List(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			names := make([]string, 0, len(deployments))
+			for _, cd := range deployments {
+				names = append(names, cd.Name)
+			}
+			Expect(names).To(ConsistOf("test-cluster-running", "test-cluster-hibernating"))
+		})
+
+		It("should report a ClusterDeployment that cannot be decoded along with the others", func() {
+			for name, file := range map[string]string{
+				"test-cluster-running":     "../../test/fixtures/clusterdeployment_running.yaml",
+				"test-cluster-hibernating": "../../test/fixtures/clusterdeployment_hibernating.yaml",
+			} {
+				cd, err := helpers.LoadClusterDeploymentFromFile(file)
+				Expect(err).NotTo(HaveOccurred())
+				mockDynamicClient.clusterDeployments[name] = cd
+			}
+			Expect(unstructured.SetNestedField(mockDynamicClient.clusterDeployments["test-cluster-running"].Object, "yes", "spec", "installed")).To(Succeed())
+
+			deployments, err := client.List(context.Background())
+			Expect(hub.IsClusterDeploymentDecodeError(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("test-cluster-running"))
+			Expect(deployments).To(HaveLen(1))
+			Expect(deployments[0].Name).To(Equal("test-cluster-hibernating"))
+		})
+
+		It("should return an empty list when there are no ClusterDeployments", func() {
+			deployments, err := client.List(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployments).To(BeEmpty())
+		})
+	})
+
+	Describe("FindByRequestID", func() {
+		BeforeEach(func() {
+			for name, file := range map[string]string{
+				"test-cluster-running":     "../../test/fixtures/clusterdeployment_running.yaml",
+				"test-cluster-hibernating": "../../test/fixtures/clusterdeployment_hibernating.yaml",
+			} {
+				cd, err := helpers.LoadClusterDeploymentFromFile(file)
+				Expect(err).NotTo(HaveOccurred())
+				mockDynamicClient.clusterDeployments[name] = cd
+			}
+		})
+
+		It("should return the ClusterDeployment labeled with the request ID", func() {
+			info, err := client.FindByRequestID(context.Background(), "1234")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info).NotTo(BeNil())
+			Expect(info.Name).To(Equal("test-cluster-running"))
+		})
+
+		It("should return nil when no ClusterDeployment has the request ID", func() {
+			info, err := client.FindByRequestID(context.Background(), "5678")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info).To(BeNil())
+		})
+
+		It("should return an error when several ClusterDeployments share the request ID", func() {
+			mockDynamicClient.clusterDeployments["test-cluster-hibernating"].SetLabels(map[string]string{hub.RequestIDLabel: "1234"})
+
+			_, err := client.FindByRequestID(context.Background(), "1234")
+			Expect(err).To(MatchError(ContainSubstring("maps to multiple ClusterDeployments")))
+		})
+	})
 })
 
 // Minimal mock for ClusterDeployment testing
@@ -135,7 +290,17 @@ func (m *mockNamespaceableResourceForCD) Get(ctx context.Context, name string, o
 }
 
 func (m *mockNamespaceableResourceForCD) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
-	return nil, nil
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	list := &unstructured.UnstructuredList{}
+	for _, cd := range m.client.clusterDeployments {
+		if selector.Matches(labels.Set(cd.GetLabels())) {
+			list.Items = append(list.Items, *cd)
+		}
+	}
+	return list, nil
 }
 
 func (m *mockNamespaceableResourceForCD) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
@@ -0,0 +1,142 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// regionLabel is the well-known node label holding the cloud region
+	regionLabel = "topology.kubernetes.io/region"
+	// instanceTypeLabel is the well-known node label holding the cloud instance type
+	instanceTypeLabel = "node.kubernetes.io/instance-type"
+)
+
+// managedClusterInfoGVR identifies the ManagedClusterInfo resources maintained by ACM
+var managedClusterInfoGVR = schema.GroupVersionResource{
+	Group:    "internal.open-cluster-management.io",
+	Version:  "v1beta1",
+	Resource: "managedclusterinfos",
+}
+
+// cloudVendorPlatforms maps ManagedClusterInfo cloud vendors to Hive platform names
+var cloudVendorPlatforms = map[string]string{
+	"Amazon":    "aws",
+	"Azure":     "azure",
+	"Google":    "gcp",
+	"VSphere":   "vsphere",
+	"OpenStack": "openstack",
+	"IBM":       "ibmcloud",
+	"BareMetal": "baremetal",
+}
+
+// ClusterInfoClient provides operations for reading ManagedClusterInfo resources
+type ClusterInfoClient interface {
+	// Get retrieves the ManagedClusterInfo from the namespace with the same name as the cluster
+	Get(ctx context.Context, name string) (*ClusterAgentInfo, error)
+	// List retrieves the ManagedClusterInfos of every cluster, sorted by name
+	List(ctx context.Context) ([]ClusterAgentInfo, error)
+}
+
+type clusterInfoClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewClusterInfoClient creates a new ClusterInfoClient
+func NewClusterInfoClient(dynamicClient dynamic.Interface, options ...kube.Option) ClusterInfoClient {
+	return &clusterInfoClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Get retrieves the ManagedClusterInfo for a cluster from namespace=name
+func (c *clusterInfoClient) Get(ctx context.Context, name string) (*ClusterAgentInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "get ManagedClusterInfo", "name", name)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	obj, err := c.dynamicClient.Resource(managedClusterInfoGVR).Namespace(name).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ManagedClusterInfo %s: %w", name, err)
+	}
+
+	return parseManagedClusterInfo(obj), nil
+}
+
+// List retrieves the ManagedClusterInfos from the namespaces of all clusters
+func (c *clusterInfoClient) List(ctx context.Context) ([]ClusterAgentInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list ManagedClusterInfos")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = c.dynamicClient.Resource(managedClusterInfoGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ManagedClusterInfos: %w", err)
+	}
+
+	infos := make([]ClusterAgentInfo, 0, len(list.Items))
+	for i := range list.Items {
+		infos = append(infos, *parseManagedClusterInfo(&list.Items[i]))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// parseManagedClusterInfo extracts ClusterAgentInfo from an unstructured ManagedClusterInfo
+func parseManagedClusterInfo(obj *unstructured.Unstructured) *ClusterAgentInfo {
+	info := &ClusterAgentInfo{Name: obj.GetName()}
+
+	info.KubernetesVersion, _, _ = unstructured.NestedString(obj.Object, "status", "version")
+	info.CloudVendor, _, _ = unstructured.NestedString(obj.Object, "status", "cloudVendor")
+	info.ConsoleURL, _, _ = unstructured.NestedString(obj.Object, "status", "consoleURL")
+	info.OpenShiftVersion, _, _ = unstructured.NestedString(obj.Object, "status", "distributionInfo", "ocp", "version")
+
+	nodes, _, _ := unstructured.NestedSlice(obj.Object, "status", "nodeList")
+	info.NodeCount = len(nodes)
+	for _, node := range nodes {
+		nodeMap, ok := node.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if region, _, _ := unstructured.NestedString(nodeMap, "labels", regionLabel); region != "" && info.Region == "" {
+			info.Region = region
+		}
+		if instanceType, _, _ := unstructured.NestedString(nodeMap, "labels", instanceTypeLabel); instanceType != "" {
+			if info.InstanceTypes == nil {
+				info.InstanceTypes = make(map[string]int)
+			}
+			info.InstanceTypes[instanceType]++
+		}
+	}
+
+	return info
+}
+
+// Platform returns the Hive platform name for the cluster's cloud vendor,
+// or the lower-cased vendor if there is no known mapping
+func (i *ClusterAgentInfo) Platform() string {
+	if platform, ok := cloudVendorPlatforms[i.CloudVendor]; ok {
+		return platform
+	}
+	return strings.ToLower(i.CloudVendor)
+}
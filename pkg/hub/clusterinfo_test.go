@@ -0,0 +1,101 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ClusterInfoClient", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	newClusterInfo := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "internal.open-cluster-management.io/v1beta1",
+			"kind":       "ManagedClusterInfo",
+			"metadata":   map[string]interface{}{"name": name, "namespace": name},
+			"status": map[string]interface{}{
+				"version":     "v1.29.8+f10c92d",
+				"cloudVendor": "Amazon",
+				"consoleURL":  "https://console-openshift-console.apps.imported.example.com",
+				"distributionInfo": map[string]interface{}{
+					"ocp": map[string]interface{}{"version": "4.16.12"},
+				},
+				"nodeList": []interface{}{
+					map[string]interface{}{
+						"name": "master-0",
+						"labels": map[string]interface{}{
+							"topology.kubernetes.io/region":    "eu-west-1",
+							"node.kubernetes.io/instance-type": "m6i.2xlarge",
+						},
+					},
+					map[string]interface{}{
+						"name":   "worker-0",
+						"labels": map[string]interface{}{"node.kubernetes.io/instance-type": "m6i.xlarge"},
+					},
+					map[string]interface{}{
+						"name":   "worker-1",
+						"labels": map[string]interface{}{"node.kubernetes.io/instance-type": "m6i.xlarge"},
+					},
+					map[string]interface{}{"name": "worker-2"},
+				},
+			},
+		}}
+	}
+
+	It("should parse the ManagedClusterInfo status", func() {
+		client := hub.NewClusterInfoClient(fake.NewSimpleDynamicClient(runtime.NewScheme(), newClusterInfo("imported")))
+
+		info, err := client.Get(ctx, "imported")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Name).To(Equal("imported"))
+		Expect(info.NodeCount).To(Equal(4))
+		Expect(info.KubernetesVersion).To(Equal("v1.29.8+f10c92d"))
+		Expect(info.OpenShiftVersion).To(Equal("4.16.12"))
+		Expect(info.CloudVendor).To(Equal("Amazon"))
+		Expect(info.Platform()).To(Equal("aws"))
+		Expect(info.Region).To(Equal("eu-west-1"))
+		Expect(info.InstanceTypes).To(Equal(map[string]int{"m6i.2xlarge": 1, "m6i.xlarge": 2}))
+		Expect(info.ConsoleURL).To(Equal("https://console-openshift-console.apps.imported.example.com"))
+	})
+
+	It("should return an error when the ManagedClusterInfo does not exist", func() {
+		client := hub.NewClusterInfoClient(fake.NewSimpleDynamicClient(runtime.NewScheme()))
+
+		_, err := client.Get(ctx, "missing")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not found"))
+	})
+
+	It("should list the ManagedClusterInfos of all clusters sorted by name", func() {
+		client := hub.NewClusterInfoClient(fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				{Group: "internal.open-cluster-management.io", Version: "v1beta1", Resource: "managedclusterinfos"}: "ManagedClusterInfoList",
+			},
+			newClusterInfo("spoke-b"), newClusterInfo("spoke-a")))
+
+		infos, err := client.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(infos).To(HaveLen(2))
+		Expect(infos[0].Name).To(Equal("spoke-a"))
+		Expect(infos[1].Name).To(Equal("spoke-b"))
+		Expect(infos[1].InstanceTypes).To(HaveKeyWithValue("m6i.xlarge", 2))
+	})
+
+	It("should lower-case unknown cloud vendors", func() {
+		info := &hub.ClusterAgentInfo{CloudVendor: "Nutanix"}
+		Expect(info.Platform()).To(Equal("nutanix"))
+	})
+})
@@ -4,8 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 )
 
+// combinedConcurrency bounds the ClusterDeployment and ManagedClusterInfo fetches in flight
+// during ListCombined
+const combinedConcurrency = 16
+
 // CombinedClusterClient provides operations that combine ManagedCluster and ClusterDeployment data
 type CombinedClusterClient interface {
 	// ListCombined fetches all ManagedClusters and enriches them with ClusterDeployment data
@@ -15,22 +20,30 @@ type CombinedClusterClient interface {
 type combinedClusterClient struct {
 	managedClusterClient    ManagedClusterClient
 	clusterDeploymentClient ClusterDeploymentClient
+	clusterInfoClient       ClusterInfoClient
 }
 
-// NewCombinedClusterClient creates a new CombinedClusterClient
+// NewCombinedClusterClient creates a new CombinedClusterClient.
+// infoClient is optional; when set, ManagedClusterInfo data is used to add node count and
+// Kubernetes version and to fill in fields that are missing for non-Hive (imported) clusters.
 func NewCombinedClusterClient(
 	mcClient ManagedClusterClient,
 	cdClient ClusterDeploymentClient,
+	infoClient ClusterInfoClient,
 ) CombinedClusterClient {
 	return &combinedClusterClient{
 		managedClusterClient:    mcClient,
 		clusterDeploymentClient: cdClient,
+		clusterInfoClient:       infoClient,
 	}
 }
 
 // ListCombined fetches all ManagedClusters and enriches them with ClusterDeployment data
 // If a ClusterDeployment is not found for a ManagedCluster, it still includes the ManagedCluster
-// data with default/N/A values for ClusterDeployment fields
+// data with default/N/A values for ClusterDeployment fields.
+// The ClusterDeployments and ManagedClusterInfos are fetched by up to combinedConcurrency workers,
+// so hubs with hundreds of clusters are listed in a few round trips. The clusters keep the order
+// of the ManagedCluster list.
 func (c *combinedClusterClient) ListCombined(ctx context.Context) ([]CombinedClusterInfo, error) {
 	// First, list all ManagedClusters
 	managedClusters, err := c.managedClusterClient.List(ctx)
@@ -38,56 +51,120 @@ func (c *combinedClusterClient) ListCombined(ctx context.Context) ([]CombinedClu
 		return nil, fmt.Errorf("failed to list managed clusters: %w", err)
 	}
 
-	// For each ManagedCluster, try to fetch the corresponding ClusterDeployment
-	combined := make([]CombinedClusterInfo, 0, len(managedClusters))
-	for _, mc := range managedClusters {
-		info := CombinedClusterInfo{
-			Name:      mc.Name,
-			Status:    mc.Status,
-			Available: mc.Available,
-			Message:   mc.Message,
+	combined := make([]CombinedClusterInfo, len(managedClusters))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(combinedConcurrency, len(managedClusters)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				combined[i] = c.combine(ctx, managedClusters[i])
+			}
+		}()
+	}
+
+feed:
+	for i := range managedClusters {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
 		}
+	}
+	close(indexes)
+	wg.Wait()
 
-		// Try to get ClusterDeployment data
-		// ClusterDeployment is in namespace=cluster-name with name=cluster-name
-		cd, err := c.clusterDeploymentClient.Get(ctx, mc.Name)
-		if err != nil {
-			// If ClusterDeployment not found (e.g., non-Hive cluster), use N/A values
-			if isNotFoundError(err) {
-				info.PowerState = "N/A"
-				info.Platform = "N/A"
-				info.Region = "N/A"
-				info.Version = "N/A"
-				info.APIUrl = ""
-				info.ConsoleURL = ""
-				info.KubeconfigSecret = ""
-			} else {
-				// For other errors, log but continue
-				// In a real implementation, we might want to log this
-				info.PowerState = "Unknown"
-				info.Platform = "Unknown"
-				info.Region = "Unknown"
-				info.Version = "Unknown"
-			}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list combined clusters: %w", err)
+	}
+	return combined, nil
+}
+
+// combine enriches a ManagedCluster with the data of its ClusterDeployment and ManagedClusterInfo
+func (c *combinedClusterClient) combine(ctx context.Context, mc ManagedClusterInfo) CombinedClusterInfo {
+	info := CombinedClusterInfo{
+		Name:        mc.Name,
+		Status:      mc.Status,
+		Available:   mc.Available,
+		Message:     mc.Message,
+		ClusterSet:  mc.ClusterSet,
+		Maintenance: mc.Maintenance,
+	}
+
+	// Try to get ClusterDeployment data
+	// ClusterDeployment is in namespace=cluster-name with name=cluster-name
+	cd, err := c.clusterDeploymentClient.Get(ctx, mc.Name)
+	if err != nil {
+		// If ClusterDeployment not found (e.g., non-Hive cluster), use N/A values
+		if isNotFoundError(err) {
+			info.PowerState = "N/A"
+			info.Platform = "N/A"
+			info.Region = "N/A"
+			info.Version = "N/A"
+			info.APIUrl = ""
+			info.ConsoleURL = ""
+			info.KubeconfigSecret = ""
 		} else {
-			// Merge ClusterDeployment data
-			info.PowerState = cd.PowerState
-			info.Platform = cd.Platform
-			info.Region = cd.Region
-			info.Version = cd.Version
-			info.APIUrl = cd.APIUrl
-			info.ConsoleURL = cd.ConsoleURL
-
-			// Format kubeconfig secret as namespace/name
-			if cd.KubeconfigSecretName != "" {
-				info.KubeconfigSecret = fmt.Sprintf("%s/%s", cd.KubeconfigSecretNS, cd.KubeconfigSecretName)
-			}
+			// For other errors, log but continue
+			// In a real implementation, we might want to log this
+			info.PowerState = "Unknown"
+			info.Platform = "Unknown"
+			info.Region = "Unknown"
+			info.Version = "Unknown"
 		}
+	} else {
+		// Merge ClusterDeployment data
+		info.PowerState = cd.PowerState
+		info.Platform = cd.Platform
+		info.Region = cd.Region
+		info.Version = cd.Version
+		info.APIUrl = cd.APIUrl
+		info.ConsoleURL = cd.ConsoleURL
 
-		combined = append(combined, info)
+		// Only AWS links can be built from ClusterDeployment data alone
+		if consoleURL, err := cd.CloudConsoleURL(CloudConsoleOptions{}); err == nil {
+			info.CloudConsoleURL = consoleURL
+		}
+
+		// Format kubeconfig secret as namespace/name
+		if cd.KubeconfigSecretName != "" {
+			info.KubeconfigSecret = fmt.Sprintf("%s/%s", cd.KubeconfigSecretNS, cd.KubeconfigSecretName)
+		}
 	}
 
-	return combined, nil
+	// Enrich with ManagedClusterInfo data reported by the klusterlet
+	if c.clusterInfoClient != nil {
+		if agentInfo, err := c.clusterInfoClient.Get(ctx, mc.Name); err == nil {
+			mergeClusterAgentInfo(&info, agentInfo)
+		}
+	}
+
+	return info
+}
+
+// mergeClusterAgentInfo adds ManagedClusterInfo data to a combined cluster. ClusterDeployment
+// values take precedence; agent values only replace fields that are empty, N/A, or Unknown.
+func mergeClusterAgentInfo(info *CombinedClusterInfo, agentInfo *ClusterAgentInfo) {
+	info.NodeCount = agentInfo.NodeCount
+	info.KubernetesVersion = agentInfo.KubernetesVersion
+	info.Cloud = agentInfo.CloudVendor
+
+	fillMissing(&info.Platform, agentInfo.Platform())
+	fillMissing(&info.Region, agentInfo.Region)
+	fillMissing(&info.Version, agentInfo.OpenShiftVersion)
+	fillMissing(&info.ConsoleURL, agentInfo.ConsoleURL)
+}
+
+// fillMissing replaces a placeholder field value with value, if value is known
+func fillMissing(field *string, value string) {
+	if value == "" {
+		return
+	}
+	switch *field {
+	case "", "N/A", "Unknown":
+		*field = value
+	}
 }
 
 // isNotFoundError checks if an error is a "not found" error
@@ -4,6 +4,10 @@ package hub_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -22,7 +26,7 @@ var _ = Describe("CombinedClusterClient", func() {
 	BeforeEach(func() {
 		mockMCClient = newMockManagedClusterClientForCombined()
 		mockCDClient = newMockClusterDeploymentClientForCombined()
-		client = hub.NewCombinedClusterClient(mockMCClient, mockCDClient)
+		client = hub.NewCombinedClusterClient(mockMCClient, mockCDClient, nil)
 	})
 
 	Describe("ListCombined", func() {
@@ -58,6 +62,7 @@ var _ = Describe("CombinedClusterClient", func() {
 						Platform:             "aws",
 						Region:               "us-east-1",
 						Version:              "4.20.6",
+						InfraID:              "test-cluster-running-x7k2p",
 					},
 				}
 
@@ -76,6 +81,7 @@ var _ = Describe("CombinedClusterClient", func() {
 				Expect(cluster.APIUrl).To(Equal("https://api.test-cluster-running.example.com:6443"))
 				Expect(cluster.ConsoleURL).To(Equal("https://console.test-cluster-running.example.com"))
 				Expect(cluster.KubeconfigSecret).To(Equal("test-cluster-running/test-cluster-running-admin-kubeconfig"))
+				Expect(cluster.CloudConsoleURL).To(ContainSubstring("tag:kubernetes.io/cluster/test-cluster-running-x7k2p=owned"))
 			})
 		})
 
@@ -106,6 +112,96 @@ var _ = Describe("CombinedClusterClient", func() {
 			})
 		})
 
+		Context("when ManagedClusterInfo is available", func() {
+			It("should fill in missing fields for imported clusters", func() {
+				mockMCClient.managedClusters = []hub.ManagedClusterInfo{
+					{Name: "imported", Status: hub.StatusReady, Available: "True"},
+				}
+				mockCDClient.clusterDeployments = map[string]*hub.ClusterDeploymentInfo{}
+				infoClient := &mockClusterInfoClient{infos: map[string]*hub.ClusterAgentInfo{
+					"imported": {
+						Name:              "imported",
+						NodeCount:         6,
+						KubernetesVersion: "v1.29.8",
+						OpenShiftVersion:  "4.16.12",
+						CloudVendor:       "Azure",
+						Region:            "westeurope",
+						ConsoleURL:        "https://console.apps.imported.example.com",
+					},
+				}}
+				client = hub.NewCombinedClusterClient(mockMCClient, mockCDClient, infoClient)
+
+				combined, err := client.ListCombined(context.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(combined).To(HaveLen(1))
+
+				cluster := combined[0]
+				Expect(cluster.PowerState).To(Equal("N/A"))
+				Expect(cluster.Platform).To(Equal("azure"))
+				Expect(cluster.Region).To(Equal("westeurope"))
+				Expect(cluster.Version).To(Equal("4.16.12"))
+				Expect(cluster.ConsoleURL).To(Equal("https://console.apps.imported.example.com"))
+				Expect(cluster.NodeCount).To(Equal(6))
+				Expect(cluster.KubernetesVersion).To(Equal("v1.29.8"))
+				Expect(cluster.Cloud).To(Equal("Azure"))
+			})
+
+			It("should keep ClusterDeployment values when both are present", func() {
+				mockMCClient.managedClusters = []hub.ManagedClusterInfo{
+					{Name: "hive", Status: hub.StatusReady, Available: "True"},
+				}
+				mockCDClient.clusterDeployments = map[string]*hub.ClusterDeploymentInfo{
+					"hive": {Name: "hive", PowerState: "Running", Platform: "aws", Region: "us-east-1", Version: "4.17.0"},
+				}
+				infoClient := &mockClusterInfoClient{infos: map[string]*hub.ClusterAgentInfo{
+					"hive": {Name: "hive", NodeCount: 3, CloudVendor: "Amazon", Region: "us-east-2", OpenShiftVersion: "4.16.0"},
+				}}
+				client = hub.NewCombinedClusterClient(mockMCClient, mockCDClient, infoClient)
+
+				combined, err := client.ListCombined(context.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(combined[0].Region).To(Equal("us-east-1"))
+				Expect(combined[0].Version).To(Equal("4.17.0"))
+				Expect(combined[0].NodeCount).To(Equal(3))
+			})
+		})
+
+		Context("when the hub has many clusters", func() {
+			It("should fetch ClusterDeployments concurrently and keep the ManagedCluster order", func() {
+				slowCDClient := &slowClusterDeploymentClient{mockClusterDeploymentClientForCombined: mockCDClient, delay: 10 * time.Millisecond}
+				for i := range 100 {
+					name := fmt.Sprintf("cluster-%03d", i)
+					mockMCClient.managedClusters = append(mockMCClient.managedClusters, hub.ManagedClusterInfo{Name: name, Status: hub.StatusReady})
+					mockCDClient.clusterDeployments[name] = &hub.ClusterDeploymentInfo{Name: name, PowerState: "Running"}
+				}
+				client = hub.NewCombinedClusterClient(mockMCClient, slowCDClient, nil)
+
+				start := time.Now()
+				combined, err := client.ListCombined(context.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+
+				Expect(combined).To(HaveLen(100))
+				for i, cluster := range combined {
+					Expect(cluster.Name).To(Equal(fmt.Sprintf("cluster-%03d", i)))
+					Expect(cluster.PowerState).To(Equal("Running"))
+				}
+				Expect(slowCDClient.maxInFlight.Load()).To(BeNumerically(">", 1))
+				Expect(slowCDClient.maxInFlight.Load()).To(BeNumerically("<=", 16))
+			})
+
+			It("should stop fetching when the context is cancelled", func() {
+				for i := range 100 {
+					mockMCClient.managedClusters = append(mockMCClient.managedClusters, hub.ManagedClusterInfo{Name: fmt.Sprintf("cluster-%03d", i)})
+				}
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := client.ListCombined(ctx)
+				Expect(err).To(MatchError(context.Canceled))
+			})
+		})
+
 		Context("when no managed clusters exist", func() {
 			It("should return empty list", func() {
 				mockMCClient.managedClusters = []hub.ManagedClusterInfo{}
@@ -134,6 +230,14 @@ func (m *mockManagedClusterClientForCombined) List(ctx context.Context) ([]hub.M
 	return m.managedClusters, nil
 }
 
+func (m *mockManagedClusterClientForCombined) Watch(_ context.Context) (<-chan hub.ManagedClusterEvent, error) {
+	return nil, errors.New("watch not supported")
+}
+
+func (m *mockManagedClusterClientForCombined) Get(_ context.Context, _ string) (*hub.ManagedClusterDetail, error) {
+	return nil, errors.New("get not supported")
+}
+
 func (m *mockManagedClusterClientForCombined) Filter(clusters []hub.ManagedClusterInfo, filter hub.ManagedClusterFilter) []hub.ManagedClusterInfo {
 	return clusters
 }
@@ -156,6 +260,44 @@ func (m *mockClusterDeploymentClientForCombined) Get(ctx context.Context, name s
 	return nil, &clusterDeploymentNotFoundError{name: name}
 }
 
+func (m *mockClusterDeploymentClientForCombined) List(ctx context.Context) ([]hub.ClusterDeploymentInfo, error) {
+	deployments := make([]hub.ClusterDeploymentInfo, 0, len(m.clusterDeployments))
+	for _, cd := range m.clusterDeployments {
+		deployments = append(deployments, *cd)
+	}
+	return deployments, nil
+}
+
+func (m *mockClusterDeploymentClientForCombined) FindByRequestID(ctx context.Context, requestID string) (*hub.ClusterDeploymentInfo, error) {
+	for _, cd := range m.clusterDeployments {
+		if cd.RequestID == requestID {
+			return cd, nil
+		}
+	}
+	return nil, nil
+}
+
+// slowClusterDeploymentClient delays every Get and records the most Gets in flight at once
+type slowClusterDeploymentClient struct {
+	*mockClusterDeploymentClientForCombined
+	delay       time.Duration
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (m *slowClusterDeploymentClient) Get(ctx context.Context, name string) (*hub.ClusterDeploymentInfo, error) {
+	current := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+	for {
+		seen := m.maxInFlight.Load()
+		if current <= seen || m.maxInFlight.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+	time.Sleep(m.delay)
+	return m.mockClusterDeploymentClientForCombined.Get(ctx, name)
+}
+
 type clusterDeploymentNotFoundError struct {
 	name string
 }
@@ -163,3 +305,22 @@ type clusterDeploymentNotFoundError struct {
 func (e *clusterDeploymentNotFoundError) Error() string {
 	return "clusterdeployment.hive.openshift.io \"" + e.name + "\" not found"
 }
+
+type mockClusterInfoClient struct {
+	infos map[string]*hub.ClusterAgentInfo
+}
+
+func (m *mockClusterInfoClient) Get(ctx context.Context, name string) (*hub.ClusterAgentInfo, error) {
+	if info, ok := m.infos[name]; ok {
+		return info, nil
+	}
+	return nil, &clusterDeploymentNotFoundError{name: name}
+}
+
+func (m *mockClusterInfoClient) List(ctx context.Context) ([]hub.ClusterAgentInfo, error) {
+	infos := make([]hub.ClusterAgentInfo, 0, len(m.infos))
+	for _, info := range m.infos {
+		infos = append(infos, *info)
+	}
+	return infos, nil
+}
@@ -0,0 +1,218 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// managedClusterSetGVR identifies the cluster-scoped OCM ManagedClusterSet resources
+var managedClusterSetGVR = schema.GroupVersionResource{
+	Group:    "cluster.open-cluster-management.io",
+	Version:  "v1beta2",
+	Resource: "managedclustersets",
+}
+
+const (
+	// ClusterSetLabel is the ManagedCluster label naming the ManagedClusterSet the cluster belongs to
+	ClusterSetLabel = "cluster.open-cluster-management.io/clusterset"
+	// ClusterSetSelectorExclusive is the selector type of sets whose members are the clusters
+	// labeled with ClusterSetLabel, the only sets clusters can be added to
+	ClusterSetSelectorExclusive = "ExclusiveClusterSetLabel"
+)
+
+// ManagedClusterSetInfo contains information about a ManagedClusterSet
+type ManagedClusterSetInfo struct {
+	// Name is the ManagedClusterSet name
+	Name string
+	// SelectorType is ExclusiveClusterSetLabel or LabelSelector
+	SelectorType string
+	// Clusters are the names of the member clusters, for ExclusiveClusterSetLabel sets only
+	Clusters []string
+}
+
+// ManagedClusterSetClient provides operations for grouping managed clusters in ManagedClusterSets
+type ManagedClusterSetClient interface {
+	// List retrieves the ManagedClusterSets with their member clusters, ordered by name
+	List(ctx context.Context) ([]ManagedClusterSetInfo, error)
+	// Create creates a ManagedClusterSet whose members are the clusters labeled with
+	// ClusterSetLabel; it reports false if the set already exists
+	Create(ctx context.Context, name string) (bool, error)
+	// AddCluster moves a managed cluster into a ManagedClusterSet
+	AddCluster(ctx context.Context, set, cluster string) error
+	// RemoveCluster removes a managed cluster from a ManagedClusterSet
+	RemoveCluster(ctx context.Context, set, cluster string) error
+}
+
+type managedClusterSetClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewManagedClusterSetClient creates a new ManagedClusterSetClient
+func NewManagedClusterSetClient(dynamicClient dynamic.Interface, options ...kube.Option) ManagedClusterSetClient {
+	return &managedClusterSetClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// List retrieves the ManagedClusterSets and the ManagedClusters, and assigns every cluster to
+// the set named by its ClusterSetLabel
+func (c *managedClusterSetClient) List(ctx context.Context) ([]ManagedClusterSetInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list ManagedClusterSets")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var sets, clusters *unstructured.UnstructuredList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		sets, err = c.dynamicClient.Resource(managedClusterSetGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ManagedClusterSets: %w", err)
+	}
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		clusters, err = c.dynamicClient.Resource(managedClusterGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed clusters: %w", err)
+	}
+
+	members := make(map[string][]string)
+	for _, item := range clusters.Items {
+		if set := item.GetLabels()[ClusterSetLabel]; set != "" {
+			members[set] = append(members[set], item.GetName())
+		}
+	}
+
+	result := make([]ManagedClusterSetInfo, 0, len(sets.Items))
+	for _, item := range sets.Items {
+		info := ManagedClusterSetInfo{Name: item.GetName(), SelectorType: selectorType(&item), Clusters: []string{}}
+		if info.SelectorType == ClusterSetSelectorExclusive {
+			info.Clusters = members[info.Name]
+			sort.Strings(info.Clusters)
+		}
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// Create creates the ManagedClusterSet, labeled as managed by labrat
+func (c *managedClusterSetClient) Create(ctx context.Context, name string) (bool, error) {
+	ctx, cancel, err := c.options.Start(ctx, "create ManagedClusterSet", "name", name)
+	if err != nil {
+		return false, err
+	}
+	defer cancel()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": managedClusterSetGVR.GroupVersion().String(),
+		"kind":       "ManagedClusterSet",
+		"metadata": map[string]interface{}{
+			"name": name,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "labrat",
+			},
+		},
+		"spec": map[string]interface{}{
+			"clusterSelector": map[string]interface{}{
+				"selectorType": ClusterSetSelectorExclusive,
+			},
+		},
+	}}
+
+	_, err = c.dynamicClient.Resource(managedClusterSetGVR).Create(ctx, obj, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create ManagedClusterSet %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// AddCluster labels the ManagedCluster with the set, which replaces its previous set
+func (c *managedClusterSetClient) AddCluster(ctx context.Context, set, cluster string) error {
+	ctx, cancel, err := c.options.Start(ctx, "add cluster to ManagedClusterSet", "set", set, "cluster", cluster)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	obj, err := c.dynamicClient.Resource(managedClusterSetGVR).Get(ctx, set, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("ManagedClusterSet %s does not exist; create it with labrat hub clustersets create %s", set, set)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ManagedClusterSet %s: %w", set, err)
+	}
+	if selectorType(obj) != ClusterSetSelectorExclusive {
+		return fmt.Errorf("ManagedClusterSet %s selects its clusters by label selector; clusters cannot be added to it", set)
+	}
+
+	return c.patchLabel(ctx, cluster, set)
+}
+
+// RemoveCluster removes the set label from the ManagedCluster, which must be in the set
+func (c *managedClusterSetClient) RemoveCluster(ctx context.Context, set, cluster string) error {
+	ctx, cancel, err := c.options.Start(ctx, "remove cluster from ManagedClusterSet", "set", set, "cluster", cluster)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	obj, err := c.dynamicClient.Resource(managedClusterGVR).Get(ctx, cluster, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get managed cluster %s: %w", cluster, err)
+	}
+	if current := obj.GetLabels()[ClusterSetLabel]; current != set {
+		return fmt.Errorf("managed cluster %s is not in ManagedClusterSet %s", cluster, set)
+	}
+
+	return c.patchLabel(ctx, cluster, nil)
+}
+
+// patchLabel sets the ClusterSetLabel of the ManagedCluster to value, or removes it if value is nil
+func (c *managedClusterSetClient) patchLabel(ctx context.Context, cluster string, value interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{ClusterSetLabel: value},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cluster set patch: %w", err)
+	}
+
+	_, err = c.dynamicClient.Resource(managedClusterGVR).Patch(ctx, cluster, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update cluster set of managed cluster %s: %w", cluster, err)
+	}
+	return nil
+}
+
+// selectorType returns the selector type of a ManagedClusterSet, which defaults to
+// ExclusiveClusterSetLabel
+func selectorType(obj *unstructured.Unstructured) string {
+	selector, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterSelector", "selectorType")
+	if selector == "" {
+		return ClusterSetSelectorExclusive
+	}
+	return selector
+}
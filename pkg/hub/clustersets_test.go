@@ -0,0 +1,143 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ManagedClusterSetClient", func() {
+	var (
+		ctx           context.Context
+		setGVR        schema.GroupVersionResource
+		mcGVR         schema.GroupVersionResource
+		dynamicClient *dynamicfake.FakeDynamicClient
+		sets          hub.ManagedClusterSetClient
+	)
+
+	clusterSet := func(name, selectorType string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cluster.open-cluster-management.io/v1beta2",
+			"kind":       "ManagedClusterSet",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec": map[string]interface{}{
+				"clusterSelector": map[string]interface{}{"selectorType": selectorType},
+			},
+		}}
+	}
+
+	managedCluster := func(name, set string) *unstructured.Unstructured {
+		labels := map[string]interface{}{"vendor": "OpenShift"}
+		if set != "" {
+			labels[hub.ClusterSetLabel] = set
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cluster.open-cluster-management.io/v1",
+			"kind":       "ManagedCluster",
+			"metadata":   map[string]interface{}{"name": name, "labels": labels},
+		}}
+	}
+
+	clusterSetOf := func(name string) string {
+		mc, err := dynamicClient.Resource(mcGVR).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return mc.GetLabels()[hub.ClusterSetLabel]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		setGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1beta2", Resource: "managedclustersets"}
+		mcGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{setGVR: "ManagedClusterSetList", mcGVR: "ManagedClusterList"},
+			clusterSet("default", hub.ClusterSetSelectorExclusive),
+			clusterSet("global", "LabelSelector"),
+			clusterSet("acme", hub.ClusterSetSelectorExclusive),
+			managedCluster("acme-test", "acme"),
+			managedCluster("acme-dev", "acme"),
+			managedCluster("lab-1", "default"),
+			managedCluster("lab-2", ""),
+		)
+		sets = hub.NewManagedClusterSetClient(dynamicClient)
+	})
+
+	Describe("List", func() {
+		It("should list the sets by name with their members", func() {
+			result, err := sets.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal([]hub.ManagedClusterSetInfo{
+				{Name: "acme", SelectorType: hub.ClusterSetSelectorExclusive, Clusters: []string{"acme-dev", "acme-test"}},
+				{Name: "default", SelectorType: hub.ClusterSetSelectorExclusive, Clusters: []string{"lab-1"}},
+				{Name: "global", SelectorType: "LabelSelector", Clusters: []string{}},
+			}))
+		})
+	})
+
+	Describe("Create", func() {
+		It("should create an exclusive set managed by labrat", func() {
+			created, err := sets.Create(ctx, "initech")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+
+			set, err := dynamicClient.Resource(setGVR).Get(ctx, "initech", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(set.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "labrat"))
+			selectorType, _, _ := unstructured.NestedString(set.Object, "spec", "clusterSelector", "selectorType")
+			Expect(selectorType).To(Equal(hub.ClusterSetSelectorExclusive))
+		})
+
+		It("should report an existing set", func() {
+			created, err := sets.Create(ctx, "acme")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeFalse())
+		})
+	})
+
+	Describe("AddCluster", func() {
+		It("should label the cluster with the set", func() {
+			Expect(sets.AddCluster(ctx, "acme", "lab-2")).To(Succeed())
+			Expect(clusterSetOf("lab-2")).To(Equal("acme"))
+		})
+
+		It("should move a cluster from another set", func() {
+			Expect(sets.AddCluster(ctx, "acme", "lab-1")).To(Succeed())
+			Expect(clusterSetOf("lab-1")).To(Equal("acme"))
+		})
+
+		It("should reject a set that does not exist", func() {
+			err := sets.AddCluster(ctx, "initech", "lab-2")
+			Expect(err).To(MatchError(ContainSubstring("ManagedClusterSet initech does not exist")))
+		})
+
+		It("should reject a set selecting its clusters by label selector", func() {
+			err := sets.AddCluster(ctx, "global", "lab-2")
+			Expect(err).To(MatchError(ContainSubstring("clusters cannot be added")))
+		})
+	})
+
+	Describe("RemoveCluster", func() {
+		It("should remove the set label from the cluster", func() {
+			Expect(sets.RemoveCluster(ctx, "acme", "acme-dev")).To(Succeed())
+
+			mc, err := dynamicClient.Resource(mcGVR).Get(ctx, "acme-dev", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mc.GetLabels()).NotTo(HaveKey(hub.ClusterSetLabel))
+			Expect(mc.GetLabels()).To(HaveKeyWithValue("vendor", "OpenShift"))
+		})
+
+		It("should reject a cluster that is not in the set", func() {
+			err := sets.RemoveCluster(ctx, "acme", "lab-1")
+			Expect(err).To(MatchError("managed cluster lab-1 is not in ManagedClusterSet acme"))
+			Expect(clusterSetOf("lab-1")).To(Equal("default"))
+		})
+	})
+})
@@ -0,0 +1,194 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// clusterCuratorGVR identifies the ACM ClusterCurator resources, which live in the namespace
+// of their cluster and drive its upgrades
+var clusterCuratorGVR = schema.GroupVersionResource{
+	Group:    "cluster.open-cluster-management.io",
+	Version:  "v1beta1",
+	Resource: "clustercurators",
+}
+
+const (
+	// CurationUpgrade is the desiredCuration that makes a ClusterCurator upgrade its cluster
+	CurationUpgrade = "upgrade"
+	// curatorJobCondition is the condition a ClusterCurator reports the result of its job in
+	curatorJobCondition = "clustercurator-job"
+	// curatorJobFailed is the reason of curatorJobCondition when the job failed
+	curatorJobFailed = "Job_failed"
+)
+
+// ClusterCuratorInfo contains the upgrade a ClusterCurator requests for its cluster
+type ClusterCuratorInfo struct {
+	// Cluster is the name of the cluster, i.e. the namespace of the ClusterCurator
+	Cluster string
+	// DesiredUpdate is the version the cluster is to be upgraded to, empty if none is requested
+	DesiredUpdate string
+	// Channel is the update channel the cluster is to be switched to, empty to keep its channel
+	Channel string
+	// Failure is the message of the failed curation job, empty unless the job failed
+	Failure string `json:",omitempty"`
+	// FailedAt is when the curation job failed, nil unless it failed
+	FailedAt *time.Time `json:",omitempty"`
+}
+
+// ClusterCuratorClient provides operations for reading ACM ClusterCurators and requesting upgrades with them
+type ClusterCuratorClient interface {
+	// List retrieves the ClusterCurators of all clusters; a hub without the ClusterCurator
+	// API has none
+	List(ctx context.Context) ([]ClusterCuratorInfo, error)
+	// Get retrieves the ClusterCurator of a cluster
+	Get(ctx context.Context, cluster string) (*ClusterCuratorInfo, error)
+	// Upgrade requests an upgrade of a cluster to version, switching it to channel if it is
+	// not empty. It creates the ClusterCurator of the cluster if there is none, which it reports.
+	Upgrade(ctx context.Context, cluster, version, channel string) (bool, error)
+}
+
+type clusterCuratorClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewClusterCuratorClient creates a new ClusterCuratorClient
+func NewClusterCuratorClient(dynamicClient dynamic.Interface, options ...kube.Option) ClusterCuratorClient {
+	return &clusterCuratorClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// List lists the ClusterCurators in all namespaces
+func (c *clusterCuratorClient) List(ctx context.Context) ([]ClusterCuratorInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list ClusterCurators")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = c.dynamicClient.Resource(clusterCuratorGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterCurators: %w", err)
+	}
+
+	curators := make([]ClusterCuratorInfo, 0, len(list.Items))
+	for _, item := range list.Items {
+		curators = append(curators, parseClusterCurator(&item))
+	}
+	return curators, nil
+}
+
+// Get retrieves the ClusterCurator named after the cluster from its namespace
+func (c *clusterCuratorClient) Get(ctx context.Context, cluster string) (*ClusterCuratorInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "get ClusterCurator", "cluster", cluster)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var obj *unstructured.Unstructured
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		obj, err = c.dynamicClient.Resource(clusterCuratorGVR).Namespace(cluster).Get(ctx, cluster, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterCurator %s: %w", cluster, err)
+	}
+	info := parseClusterCurator(obj)
+	return &info, nil
+}
+
+// Upgrade sets the upgrade curation of the ClusterCurator of the cluster, which makes the
+// cluster-curator controller run the upgrade job
+func (c *clusterCuratorClient) Upgrade(ctx context.Context, cluster, version, channel string) (bool, error) {
+	ctx, cancel, err := c.options.Start(ctx, "upgrade with ClusterCurator", "cluster", cluster, "version", version)
+	if err != nil {
+		return false, err
+	}
+	defer cancel()
+
+	upgrade := map[string]interface{}{"desiredUpdate": version}
+	if channel != "" {
+		upgrade["channel"] = channel
+	}
+	spec := map[string]interface{}{
+		"desiredCuration": CurationUpgrade,
+		"upgrade":         upgrade,
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": clusterCuratorGVR.GroupVersion().String(),
+		"kind":       "ClusterCurator",
+		"metadata": map[string]interface{}{
+			"name":      cluster,
+			"namespace": cluster,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "labrat",
+			},
+		},
+		"spec": spec,
+	}}
+	_, err = c.dynamicClient.Resource(clusterCuratorGVR).Namespace(cluster).Create(ctx, obj, metav1.CreateOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return false, fmt.Errorf("failed to create ClusterCurator %s: %w", cluster, err)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal ClusterCurator patch: %w", err)
+	}
+	_, err = c.dynamicClient.Resource(clusterCuratorGVR).Namespace(cluster).Patch(ctx, cluster, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to update ClusterCurator %s: %w", cluster, err)
+	}
+	return false, nil
+}
+
+// parseClusterCurator extracts ClusterCuratorInfo from an unstructured ClusterCurator
+func parseClusterCurator(obj *unstructured.Unstructured) ClusterCuratorInfo {
+	info := ClusterCuratorInfo{Cluster: obj.GetNamespace()}
+	info.DesiredUpdate, _, _ = unstructured.NestedString(obj.Object, "spec", "upgrade", "desiredUpdate")
+	info.Channel, _, _ = unstructured.NestedString(obj.Object, "spec", "upgrade", "channel")
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if !ok || conditionMap["type"] != curatorJobCondition {
+			continue
+		}
+		if conditionMap["reason"] == curatorJobFailed {
+			info.Failure, _, _ = unstructured.NestedString(conditionMap, "message")
+			transition, _, _ := unstructured.NestedString(conditionMap, "lastTransitionTime")
+			if failedAt, err := time.Parse(time.RFC3339, transition); err == nil {
+				info.FailedAt = &failedAt
+			}
+		}
+	}
+	return info
+}
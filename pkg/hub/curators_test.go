@@ -0,0 +1,113 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("ClusterCuratorClient", func() {
+	var curatorGVR schema.GroupVersionResource
+
+	curator := func(cluster string, upgrade map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cluster.open-cluster-management.io/v1beta1",
+			"kind":       "ClusterCurator",
+			"metadata":   map[string]interface{}{"name": cluster, "namespace": cluster},
+			"spec":       map[string]interface{}{"upgrade": upgrade},
+		}}
+	}
+
+	BeforeEach(func() {
+		curatorGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1beta1", Resource: "clustercurators"}
+	})
+
+	It("should list the upgrades requested per cluster", func() {
+		dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{curatorGVR: "ClusterCuratorList"},
+			curator("lab-1", map[string]interface{}{"desiredUpdate": "4.16.2", "channel": "stable-4.16"}),
+			curator("lab-2", nil),
+		)
+
+		curators, err := hub.NewClusterCuratorClient(dynamicClient).List(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(curators).To(ConsistOf(
+			hub.ClusterCuratorInfo{Cluster: "lab-1", DesiredUpdate: "4.16.2", Channel: "stable-4.16"},
+			hub.ClusterCuratorInfo{Cluster: "lab-2"},
+		))
+	})
+
+	It("should list none on a hub without the ClusterCurator API", func() {
+		dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{curatorGVR: "ClusterCuratorList"})
+		dynamicClient.PrependReactor("list", "clustercurators", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(curatorGVR.GroupResource(), "")
+		})
+
+		curators, err := hub.NewClusterCuratorClient(dynamicClient).List(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(curators).To(BeEmpty())
+	})
+
+	It("should report a failed curation job", func() {
+		failed := curator("lab-1", map[string]interface{}{"desiredUpdate": "4.16.2"})
+		failed.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{
+				"type":               "clustercurator-job",
+				"status":             "True",
+				"reason":             "Job_failed",
+				"message":            "curator-job-x4k2p DesiredCuration: upgrade Failed",
+				"lastTransitionTime": "2026-03-02T10:15:00Z",
+			}},
+		}
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), failed)
+
+		info, err := hub.NewClusterCuratorClient(dynamicClient).Get(context.Background(), "lab-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.DesiredUpdate).To(Equal("4.16.2"))
+		Expect(info.Failure).To(ContainSubstring("Failed"))
+		Expect(info.FailedAt).NotTo(BeNil())
+		Expect(*info.FailedAt).To(Equal(time.Date(2026, 3, 2, 10, 15, 0, 0, time.UTC)))
+	})
+
+	It("should create a labrat-managed ClusterCurator to upgrade a cluster", func() {
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+		created, err := hub.NewClusterCuratorClient(dynamicClient).Upgrade(context.Background(), "lab-1", "4.16.2", "stable-4.16")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeTrue())
+
+		obj, err := dynamicClient.Resource(curatorGVR).Namespace("lab-1").Get(context.Background(), "lab-1", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "labrat"))
+		Expect(obj.Object["spec"]).To(Equal(map[string]interface{}{
+			"desiredCuration": "upgrade",
+			"upgrade":         map[string]interface{}{"desiredUpdate": "4.16.2", "channel": "stable-4.16"},
+		}))
+	})
+
+	It("should update an existing ClusterCurator to upgrade a cluster", func() {
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+			curator("lab-1", map[string]interface{}{"desiredUpdate": "4.15.30", "channel": "stable-4.15"}))
+
+		created, err := hub.NewClusterCuratorClient(dynamicClient).Upgrade(context.Background(), "lab-1", "4.15.33", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeFalse())
+
+		info, err := hub.NewClusterCuratorClient(dynamicClient).Get(context.Background(), "lab-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*info).To(Equal(hub.ClusterCuratorInfo{Cluster: "lab-1", DesiredUpdate: "4.15.33", Channel: "stable-4.15"}))
+	})
+})
@@ -0,0 +1,155 @@
+package hub
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// versionOperators are the comparison operators of a VersionConstraint, longest first so
+// <= is not read as <
+var versionOperators = []string{"<=", ">=", "!=", "<", ">", "="}
+
+// VersionConstraint is a comma-separated list of version comparisons that must all hold, e.g.
+// "<4.16" or ">=4.14, <4.16". A partial version stands for every version of its line: 4.15
+// matches 4.15.3, <4.15 matches versions before the 4.15 line, and <=4.15 includes it.
+type VersionConstraint string
+
+// Validate checks the syntax of the constraint
+func (c VersionConstraint) Validate() error {
+	_, err := c.parse()
+	return err
+}
+
+// Match reports whether version satisfies every comparison of the constraint. Unknown
+// versions and invalid constraints never match.
+func (c VersionConstraint) Match(version string) bool {
+	if isMissing(version) {
+		return false
+	}
+	comparisons, err := c.parse()
+	if err != nil {
+		return false
+	}
+	for _, comparison := range comparisons {
+		if !comparison.match(version) {
+			return false
+		}
+	}
+	return true
+}
+
+// versionComparison is one operator and version of a VersionConstraint
+type versionComparison struct {
+	operator string
+	version  string
+}
+
+// parse splits the constraint into its comparisons
+func (c VersionConstraint) parse() ([]versionComparison, error) {
+	var comparisons []versionComparison
+	for _, part := range strings.Split(string(c), ",") {
+		part = strings.TrimSpace(part)
+		comparison := versionComparison{operator: "="}
+		for _, operator := range versionOperators {
+			if rest, ok := strings.CutPrefix(part, operator); ok {
+				comparison = versionComparison{operator: operator, version: strings.TrimSpace(rest)}
+				break
+			}
+		}
+		if comparison.version == "" {
+			comparison.version = part
+		}
+		comparison.version = strings.TrimPrefix(comparison.version, "v")
+
+		segments := strings.Split(comparison.version, ".")
+		for _, segment := range segments {
+			if _, err := strconv.Atoi(segment); err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %q is not a version like 4.15 or 4.15.3", c, comparison.version)
+			}
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons, nil
+}
+
+// match compares version with the comparison version, considering only as many segments as
+// the comparison version has
+func (v versionComparison) match(version string) bool {
+	depth := strings.Count(v.version, ".") + 1
+	segments := strings.FieldsFunc(version, isVersionSeparator)
+	if len(segments) > depth {
+		segments = segments[:depth]
+	}
+	cmp := CompareVersions(strings.Join(segments, "."), v.version)
+
+	switch v.operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// Validate checks the name pattern, the label selector, and the version constraint of the filter
+func (f ManagedClusterFilter) Validate() error {
+	if _, err := path.Match(f.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern %q: %w", f.Name, err)
+	}
+	if _, err := labels.Parse(f.Selector); err != nil {
+		return fmt.Errorf("invalid label selector %q: %w", f.Selector, err)
+	}
+	if f.Version != "" {
+		return f.Version.Validate()
+	}
+	return nil
+}
+
+// RequiresCombined reports whether the filter has criteria that are only known for
+// CombinedClusterInfo, i.e. that come from the ClusterDeployment of a cluster
+func (f ManagedClusterFilter) RequiresCombined() bool {
+	return f.Platform != "" || f.Region != "" || f.Version != "" || f.PowerState != ""
+}
+
+// Matches reports whether a managed cluster passes the filter. Criteria that require
+// ClusterDeployment data are ignored; an invalid label selector matches no cluster.
+func (f ManagedClusterFilter) Matches(cluster ManagedClusterInfo) bool {
+	if f.Selector != "" {
+		selector, err := labels.Parse(f.Selector)
+		if err != nil || !selector.Matches(labels.Set(cluster.Labels)) {
+			return false
+		}
+	}
+	return f.matchesManaged(cluster.Name, cluster.Status, cluster.ClusterSet)
+}
+
+// MatchesCombined reports whether a cluster passes every criterion of the filter except the
+// label selector
+func (f ManagedClusterFilter) MatchesCombined(cluster CombinedClusterInfo) bool {
+	return f.matchesManaged(cluster.Name, cluster.Status, cluster.ClusterSet) &&
+		(f.Platform == "" || strings.EqualFold(cluster.Platform, f.Platform)) &&
+		(f.Region == "" || strings.EqualFold(cluster.Region, f.Region)) &&
+		(f.PowerState == "" || strings.EqualFold(cluster.PowerState, f.PowerState)) &&
+		(f.Version == "" || f.Version.Match(cluster.Version))
+}
+
+// matchesManaged checks the criteria known from the ManagedCluster
+func (f ManagedClusterFilter) matchesManaged(name string, status ClusterStatus, clusterSet string) bool {
+	if f.Name != "" {
+		if matched, _ := path.Match(f.Name, name); !matched {
+			return false
+		}
+	}
+	return (f.Status == "" || status == f.Status) && (f.ClusterSet == "" || clusterSet == f.ClusterSet)
+}
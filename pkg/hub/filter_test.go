@@ -0,0 +1,98 @@
+//go:build test
+
+package hub_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("VersionConstraint", func() {
+	DescribeTable("Match",
+		func(constraint, version string, expected bool) {
+			Expect(hub.VersionConstraint(constraint).Match(version)).To(Equal(expected))
+		},
+		Entry("less than a minor version", "<4.15", "4.14.38", true),
+		Entry("less than excludes the line itself", "<4.15", "4.15.0", false),
+		Entry("versions are compared numerically", "<4.15", "4.9.1", true),
+		Entry("less or equal includes the whole line", "<=4.15", "4.15.21", true),
+		Entry("greater than excludes the whole line", ">4.15", "4.15.21", false),
+		Entry("greater than a patch version", ">4.15.2", "4.15.3", true),
+		Entry("a bare minor version matches its line", "4.15", "4.15.3", true),
+		Entry("a bare minor version does not match other lines", "4.15", "4.16.0", false),
+		Entry("not equal", "!=4.15", "4.16.1", true),
+		Entry("ranges must all hold", ">=4.14, <4.16", "4.15.9", true),
+		Entry("ranges exclude versions outside", ">=4.14, <4.16", "4.16.0", false),
+		Entry("a leading v is ignored", "<v4.16", "4.15.1", true),
+		Entry("unknown versions never match", "<4.16", "N/A", false),
+		Entry("pre-releases are compared by their version", "4.16", "4.16.0-rc.2", true),
+	)
+
+	It("should reject invalid constraints", func() {
+		Expect(hub.VersionConstraint("<4.16").Validate()).To(Succeed())
+		Expect(hub.VersionConstraint("<four").Validate()).To(MatchError(ContainSubstring(`"four" is not a version`)))
+		Expect(hub.VersionConstraint(">=4.14,").Validate()).To(HaveOccurred())
+		Expect(hub.VersionConstraint("~4.14").Match("4.14.1")).To(BeFalse())
+	})
+})
+
+var _ = Describe("ManagedClusterFilter", func() {
+	clusters := []hub.CombinedClusterInfo{
+		{Name: "partner-a", Status: hub.StatusReady, Platform: "aws", Region: "us-east-1", Version: "4.14.38", PowerState: "Running"},
+		{Name: "partner-b", Status: hub.StatusReady, Platform: "aws", Region: "us-east-1", Version: "4.16.2", PowerState: "Running"},
+		{Name: "partner-c", Status: hub.StatusNotReady, Platform: "aws", Region: "eu-west-1", Version: "4.14.12", PowerState: "Hibernating"},
+		{Name: "internal-a", Status: hub.StatusReady, Platform: "gcp", Region: "us-east1", Version: "4.14.38", PowerState: "Running"},
+		{Name: "imported", Status: hub.StatusReady, Platform: "N/A", Region: "N/A", Version: "N/A", PowerState: "N/A"},
+	}
+
+	names := func(filter hub.ManagedClusterFilter) []string {
+		var matched []string
+		for _, cluster := range clusters {
+			if filter.MatchesCombined(cluster) {
+				matched = append(matched, cluster.Name)
+			}
+		}
+		return matched
+	}
+
+	It("should combine every criterion", func() {
+		filter := hub.ManagedClusterFilter{Platform: "AWS", Region: "us-east-1", Version: "<4.15", Name: "partner-*"}
+		Expect(names(filter)).To(Equal([]string{"partner-a"}))
+	})
+
+	It("should filter by power state and status", func() {
+		Expect(names(hub.ManagedClusterFilter{PowerState: "hibernating"})).To(Equal([]string{"partner-c"}))
+		Expect(names(hub.ManagedClusterFilter{Status: hub.StatusReady, Version: "4.14"})).To(Equal([]string{"partner-a", "internal-a"}))
+	})
+
+	It("should match every cluster without criteria", func() {
+		Expect(names(hub.ManagedClusterFilter{})).To(HaveLen(len(clusters)))
+	})
+
+	It("should ignore ClusterDeployment criteria for managed clusters", func() {
+		filter := hub.ManagedClusterFilter{Name: "partner-?", Platform: "gcp"}
+		Expect(filter.RequiresCombined()).To(BeTrue())
+		Expect(filter.Matches(hub.ManagedClusterInfo{Name: "partner-a"})).To(BeTrue())
+		Expect(filter.Matches(hub.ManagedClusterInfo{Name: "internal-a"})).To(BeFalse())
+		Expect(hub.ManagedClusterFilter{Name: "partner-*"}.RequiresCombined()).To(BeFalse())
+	})
+
+	It("should filter managed clusters by label selector", func() {
+		acme := hub.ManagedClusterInfo{Name: "partner-a", Labels: map[string]string{"partner": "acme", "owner": "jdoe"}}
+		other := hub.ManagedClusterInfo{Name: "partner-b", Labels: map[string]string{"partner": "globex"}}
+
+		filter := hub.ManagedClusterFilter{Selector: "partner=acme,owner"}
+		Expect(filter.Matches(acme)).To(BeTrue())
+		Expect(filter.Matches(other)).To(BeFalse())
+		Expect(hub.ManagedClusterFilter{Selector: "partner!=acme"}.Matches(other)).To(BeTrue())
+		Expect(hub.ManagedClusterFilter{Selector: "partner in ("}.Matches(acme)).To(BeFalse())
+	})
+
+	It("should validate the name pattern, label selector, and version", func() {
+		Expect(hub.ManagedClusterFilter{Name: "partner-[", Version: "<4.16"}.Validate()).To(MatchError(ContainSubstring("invalid name pattern")))
+		Expect(hub.ManagedClusterFilter{Selector: "partner in ("}.Validate()).To(MatchError(ContainSubstring("invalid label selector")))
+		Expect(hub.ManagedClusterFilter{Version: "latest"}.Validate()).To(HaveOccurred())
+		Expect(hub.ManagedClusterFilter{Name: "partner-*", Version: "<4.16"}.Validate()).To(Succeed())
+	})
+})
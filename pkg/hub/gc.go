@@ -0,0 +1,220 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// ManagedClusterNamespaceLabel marks the namespace ACM creates for a managed cluster
+	ManagedClusterNamespaceLabel = "cluster.open-cluster-management.io/managedCluster"
+	// ClusterDeploymentNameLabel marks Hive resources created for a ClusterDeployment
+	ClusterDeploymentNameLabel = "hive.openshift.io/cluster-deployment-name"
+	// SecretTypeLabel marks the type of secrets created by Hive
+	SecretTypeLabel = "hive.openshift.io/secret-type"
+)
+
+// dnsZoneGVR identifies the Hive DNSZone resources created for managed DNS
+var dnsZoneGVR = schema.GroupVersionResource{
+	Group:    "hive.openshift.io",
+	Version:  "v1",
+	Resource: "dnszones",
+}
+
+// GarbageKind is the kind of a leftover hub resource
+type GarbageKind string
+
+const (
+	// GarbageDNSZone is a Hive DNSZone whose ClusterDeployment is gone
+	GarbageDNSZone GarbageKind = "DNSZone"
+	// GarbageSecret is an admin kubeconfig or password secret whose ClusterDeployment is gone
+	GarbageSecret GarbageKind = "Secret"
+	// GarbageNamespace is a cluster namespace with neither a ClusterDeployment nor a ManagedCluster
+	GarbageNamespace GarbageKind = "Namespace"
+)
+
+// Garbage is a hub resource left behind after a cluster was deprovisioned
+type Garbage struct {
+	// Kind is the resource kind
+	Kind GarbageKind `json:"kind"`
+	// Name is the resource name
+	Name string `json:"name"`
+	// Namespace is the resource namespace, empty for namespaces
+	Namespace string `json:"namespace,omitempty"`
+	// Reason explains why the resource is considered garbage
+	Reason string `json:"reason"`
+}
+
+// GarbageCollector finds and deletes hub resources left behind by deprovisioned clusters
+type GarbageCollector interface {
+	// Find returns leftover resources, DNSZones and secrets before namespaces
+	Find(ctx context.Context) ([]Garbage, error)
+	// Delete deletes the given resources, ignoring those that are already gone
+	Delete(ctx context.Context, items []Garbage) error
+}
+
+type garbageCollector struct {
+	coreClient              corev1client.CoreV1Interface
+	dynamicClient           dynamic.Interface
+	managedClusterClient    ManagedClusterClient
+	clusterDeploymentClient ClusterDeploymentClient
+	options                 kube.Options
+}
+
+// NewGarbageCollector creates a new GarbageCollector
+func NewGarbageCollector(coreClient corev1client.CoreV1Interface, dynamicClient dynamic.Interface, options ...kube.Option) GarbageCollector {
+	return &garbageCollector{
+		coreClient:              coreClient,
+		dynamicClient:           dynamicClient,
+		managedClusterClient:    NewManagedClusterClient(dynamicClient, options...),
+		clusterDeploymentClient: NewClusterDeploymentClient(dynamicClient, options...),
+		options:                 kube.NewOptions(options...),
+	}
+}
+
+// Find compares cluster-scoped leftovers with the live clusters:
+// 1. Cluster namespaces (labeled by ACM) with neither a ClusterDeployment nor a ManagedCluster
+// 2. Hive kubeconfig and kubeadmin secrets whose ClusterDeployment is gone
+// 3. Hive DNSZones whose ClusterDeployment is gone
+// Secrets and DNSZones in namespaces that are themselves garbage are not listed separately.
+func (g *garbageCollector) Find(ctx context.Context) ([]Garbage, error) {
+	ctx, cancel, err := g.options.Start(ctx, "find garbage")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	deployments, err := g.clusterDeploymentClient.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	managedClusters, err := g.managedClusterClient.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed := make(map[string]bool, len(deployments))
+	deployedNamespaces := make(map[string]bool, len(deployments))
+	for _, cd := range deployments {
+		deployed[cd.Namespace+"/"+cd.Name] = true
+		deployedNamespaces[cd.Namespace] = true
+	}
+	managed := make(map[string]bool, len(managedClusters))
+	for _, mc := range managedClusters {
+		managed[mc.Name] = true
+	}
+
+	var garbage []Garbage
+
+	namespaces, err := g.coreClient.Namespaces().List(ctx, metav1.ListOptions{LabelSelector: ManagedClusterNamespaceLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster namespaces: %w", err)
+	}
+	garbageNamespaces := make(map[string]bool)
+	for _, ns := range namespaces.Items {
+		if ns.Status.Phase == corev1.NamespaceTerminating || deployedNamespaces[ns.Name] || managed[ns.Labels[ManagedClusterNamespaceLabel]] {
+			continue
+		}
+		garbageNamespaces[ns.Name] = true
+		garbage = append(garbage, Garbage{
+			Kind:   GarbageNamespace,
+			Name:   ns.Name,
+			Reason: fmt.Sprintf("no ClusterDeployment or ManagedCluster %s", ns.Labels[ManagedClusterNamespaceLabel]),
+		})
+	}
+
+	secrets, err := g.coreClient.Secrets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: SecretTypeLabel + " in (kubeconfig,kubeadmincreds)," + ClusterDeploymentNameLabel,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Hive secrets: %w", err)
+	}
+	for _, secret := range secrets.Items {
+		cdName := secret.Labels[ClusterDeploymentNameLabel]
+		if garbageNamespaces[secret.Namespace] || deployed[secret.Namespace+"/"+cdName] {
+			continue
+		}
+		garbage = append(garbage, Garbage{
+			Kind:      GarbageSecret,
+			Name:      secret.Name,
+			Namespace: secret.Namespace,
+			Reason:    fmt.Sprintf("ClusterDeployment %s no longer exists", cdName),
+		})
+	}
+
+	zones, err := g.dynamicClient.Resource(dnsZoneGVR).List(ctx, metav1.ListOptions{LabelSelector: ClusterDeploymentNameLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DNSZones: %w", err)
+	}
+	for _, zone := range zones.Items {
+		cdName := zone.GetLabels()[ClusterDeploymentNameLabel]
+		if garbageNamespaces[zone.GetNamespace()] || deployed[zone.GetNamespace()+"/"+cdName] {
+			continue
+		}
+		garbage = append(garbage, Garbage{
+			Kind:      GarbageDNSZone,
+			Name:      zone.GetName(),
+			Namespace: zone.GetNamespace(),
+			Reason:    fmt.Sprintf("ClusterDeployment %s no longer exists", cdName),
+		})
+	}
+
+	order := map[GarbageKind]int{GarbageDNSZone: 0, GarbageSecret: 1, GarbageNamespace: 2}
+	sort.SliceStable(garbage, func(i, j int) bool {
+		if garbage[i].Kind != garbage[j].Kind {
+			return order[garbage[i].Kind] < order[garbage[j].Kind]
+		}
+		if garbage[i].Namespace != garbage[j].Namespace {
+			return garbage[i].Namespace < garbage[j].Namespace
+		}
+		return garbage[i].Name < garbage[j].Name
+	})
+	return garbage, nil
+}
+
+// Delete deletes every item, continuing past failures, and returns the combined errors
+func (g *garbageCollector) Delete(ctx context.Context, items []Garbage) error {
+	ctx, cancel, err := g.options.Start(ctx, "delete garbage", "items", len(items))
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var errs []error
+	for _, item := range items {
+		var err error
+		switch item.Kind {
+		case GarbageDNSZone:
+			err = g.dynamicClient.Resource(dnsZoneGVR).Namespace(item.Namespace).Delete(ctx, item.Name, metav1.DeleteOptions{})
+		case GarbageSecret:
+			err = g.coreClient.Secrets(item.Namespace).Delete(ctx, item.Name, metav1.DeleteOptions{})
+		case GarbageNamespace:
+			err = g.coreClient.Namespaces().Delete(ctx, item.Name, metav1.DeleteOptions{})
+		default:
+			err = fmt.Errorf("unsupported kind %q", item.Kind)
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s: %w", item.Kind, item.qualifiedName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// qualifiedName returns namespace/name, or name for cluster-scoped resources
+func (g Garbage) qualifiedName() string {
+	if g.Namespace == "" {
+		return g.Name
+	}
+	return g.Namespace + "/" + g.Name
+}
@@ -0,0 +1,130 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hive"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("GarbageCollector", func() {
+	var (
+		ctx           context.Context
+		clientset     *fake.Clientset
+		dynamicClient *dynamicfake.FakeDynamicClient
+		collector     hub.GarbageCollector
+	)
+
+	hiveObject := func(kind, namespace, name string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace, "labels": labels},
+		}}
+	}
+	managedCluster := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cluster.open-cluster-management.io/v1",
+			"kind":       "ManagedCluster",
+			"metadata":   map[string]interface{}{"name": name},
+		}}
+	}
+	clusterNamespace := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{hub.ManagedClusterNamespaceLabel: name},
+		}}
+	}
+	hiveSecret := func(namespace, name, secretType, cdName string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{hub.SecretTypeLabel: secretType, hub.ClusterDeploymentNameLabel: cdName},
+		}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clientset = fake.NewSimpleClientset(
+			clusterNamespace("live"),
+			clusterNamespace("imported"),
+			clusterNamespace("gone"),
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unrelated"}},
+			hiveSecret("live", "live-admin-kubeconfig", "kubeconfig", "live"),
+			hiveSecret("live", "old-admin-password", "kubeadmincreds", "old"),
+			hiveSecret("gone", "gone-admin-kubeconfig", "kubeconfig", "gone"),
+		)
+		dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}:               "ClusterDeploymentList",
+				{Group: "hive.openshift.io", Version: "v1", Resource: "dnszones"}:                         "DNSZoneList",
+				{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}: "ManagedClusterList",
+			},
+			hiveObject("ClusterDeployment", "live", "live", nil),
+			managedCluster("live"),
+			managedCluster("imported"),
+			hiveObject("DNSZone", "live", "live-zone", map[string]interface{}{hub.ClusterDeploymentNameLabel: "live"}),
+			hiveObject("DNSZone", "live", "old-zone", map[string]interface{}{hub.ClusterDeploymentNameLabel: "old"}),
+			hiveObject("DNSZone", "gone", "gone-zone", map[string]interface{}{hub.ClusterDeploymentNameLabel: "gone"}),
+		)
+		collector = hub.NewGarbageCollector(clientset.CoreV1(), dynamicClient)
+	})
+
+	Describe("Find", func() {
+		It("should report leftovers of deprovisioned clusters only", func() {
+			garbage, err := collector.Find(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(garbage).To(Equal([]hub.Garbage{
+				{Kind: hub.GarbageDNSZone, Name: "old-zone", Namespace: "live", Reason: "ClusterDeployment old no longer exists"},
+				{Kind: hub.GarbageSecret, Name: "old-admin-password", Namespace: "live", Reason: "ClusterDeployment old no longer exists"},
+				{Kind: hub.GarbageNamespace, Name: "gone", Reason: "no ClusterDeployment or ManagedCluster gone"},
+			}))
+		})
+
+		It("should fail when a ClusterDeployment cannot be decoded", func() {
+			cd := hiveObject("ClusterDeployment", "gone", "gone", nil)
+			Expect(unstructured.SetNestedField(cd.Object, "yes", "spec", "installed")).To(Succeed())
+			_, err := dynamicClient.Resource(hive.ClusterDeploymentGVR).Namespace("gone").Create(ctx, cd, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			garbage, err := collector.Find(ctx)
+			Expect(hub.IsClusterDeploymentDecodeError(err)).To(BeTrue())
+			Expect(garbage).To(BeEmpty())
+		})
+	})
+
+	Describe("Delete", func() {
+		It("should delete every item", func() {
+			garbage, err := collector.Find(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(collector.Delete(ctx, garbage)).To(Succeed())
+
+			_, err = clientset.CoreV1().Namespaces().Get(ctx, "gone", metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			_, err = clientset.CoreV1().Secrets("live").Get(ctx, "old-admin-password", metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			_, err = dynamicClient.Resource(schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "dnszones"}).
+				Namespace("live").Get(ctx, "old-zone", metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			_, err = clientset.CoreV1().Secrets("live").Get(ctx, "live-admin-kubeconfig", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should ignore items that are already gone", func() {
+			Expect(collector.Delete(ctx, []hub.Garbage{{Kind: hub.GarbageSecret, Name: "missing", Namespace: "live"}})).To(Succeed())
+		})
+	})
+})
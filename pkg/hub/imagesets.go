@@ -0,0 +1,161 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// clusterImageSetGVR identifies the cluster-scoped Hive ClusterImageSet resources
+var clusterImageSetGVR = schema.GroupVersionResource{
+	Group:    "hive.openshift.io",
+	Version:  "v1",
+	Resource: "clusterimagesets",
+}
+
+// ClusterImageSetInfo contains the release image offered by a ClusterImageSet
+type ClusterImageSetInfo struct {
+	// Name is the ClusterImageSet name
+	Name string
+	// ReleaseImage is the OpenShift release image pull spec
+	ReleaseImage string
+	// Labels are the labels of the ClusterImageSet, e.g. visible=true to offer it in the ACM
+	// console
+	Labels map[string]string `json:",omitempty"`
+}
+
+// ClusterImageSetClient provides operations for Hive ClusterImageSets, which offer OpenShift
+// releases for provisioning
+type ClusterImageSetClient interface {
+	// Get retrieves a ClusterImageSet by name
+	Get(ctx context.Context, name string) (*ClusterImageSetInfo, error)
+	// List retrieves all ClusterImageSets, sorted by name
+	List(ctx context.Context) ([]ClusterImageSetInfo, error)
+	// Create creates a ClusterImageSet offering releaseImage
+	Create(ctx context.Context, name, releaseImage string, labels map[string]string) error
+	// Delete deletes a ClusterImageSet
+	Delete(ctx context.Context, name string) error
+}
+
+type clusterImageSetClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewClusterImageSetClient creates a new ClusterImageSetClient
+func NewClusterImageSetClient(dynamicClient dynamic.Interface, options ...kube.Option) ClusterImageSetClient {
+	return &clusterImageSetClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Get retrieves a ClusterImageSet by name
+func (c *clusterImageSetClient) Get(ctx context.Context, name string) (*ClusterImageSetInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "get ClusterImageSet", "name", name)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	obj, err := c.dynamicClient.Resource(clusterImageSetGVR).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterImageSet %s: %w", name, err)
+	}
+
+	info := parseClusterImageSet(obj)
+	if info.ReleaseImage == "" {
+		return nil, fmt.Errorf("ClusterImageSet %s has no spec.releaseImage", name)
+	}
+	return &info, nil
+}
+
+// List retrieves all ClusterImageSets
+func (c *clusterImageSetClient) List(ctx context.Context) ([]ClusterImageSetInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list ClusterImageSets")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = c.dynamicClient.Resource(clusterImageSetGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterImageSets: %w", err)
+	}
+
+	imageSets := make([]ClusterImageSetInfo, 0, len(list.Items))
+	for i := range list.Items {
+		imageSets = append(imageSets, parseClusterImageSet(&list.Items[i]))
+	}
+	sort.Slice(imageSets, func(i, j int) bool { return imageSets[i].Name < imageSets[j].Name })
+	return imageSets, nil
+}
+
+// Create creates the cluster-scoped ClusterImageSet name with spec.releaseImage
+func (c *clusterImageSetClient) Create(ctx context.Context, name, releaseImage string, labels map[string]string) error {
+	ctx, cancel, err := c.options.Start(ctx, "create ClusterImageSet", "name", name, "releaseImage", releaseImage)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": clusterImageSetGVR.GroupVersion().String(),
+		"kind":       "ClusterImageSet",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       map[string]interface{}{"releaseImage": releaseImage},
+	}}
+	if len(labels) > 0 {
+		obj.SetLabels(labels)
+	}
+
+	_, err = c.dynamicClient.Resource(clusterImageSetGVR).Create(ctx, obj, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("ClusterImageSet %s already exists", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create ClusterImageSet %s: %w", name, err)
+	}
+	return nil
+}
+
+// Delete deletes the ClusterImageSet name
+func (c *clusterImageSetClient) Delete(ctx context.Context, name string) error {
+	ctx, cancel, err := c.options.Start(ctx, "delete ClusterImageSet", "name", name)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	err = c.dynamicClient.Resource(clusterImageSetGVR).Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("ClusterImageSet %s not found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete ClusterImageSet %s: %w", name, err)
+	}
+	return nil
+}
+
+// parseClusterImageSet extracts ClusterImageSetInfo from an unstructured ClusterImageSet
+func parseClusterImageSet(obj *unstructured.Unstructured) ClusterImageSetInfo {
+	releaseImage, _, _ := unstructured.NestedString(obj.Object, "spec", "releaseImage")
+	return ClusterImageSetInfo{
+		Name:         obj.GetName(),
+		ReleaseImage: releaseImage,
+		Labels:       obj.GetLabels(),
+	}
+}
@@ -0,0 +1,94 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ClusterImageSetClient", func() {
+	newImageSet := func(name, releaseImage string) *unstructured.Unstructured {
+		spec := map[string]interface{}{}
+		if releaseImage != "" {
+			spec["releaseImage"] = releaseImage
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterImageSet",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       spec,
+		}}
+	}
+
+	It("should return the release image of a ClusterImageSet", func() {
+		dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(),
+			newImageSet("img4.16.12-x86-64", "quay.io/openshift-release-dev/ocp-release:4.16.12-x86_64"))
+
+		info, err := hub.NewClusterImageSetClient(dynamicClient).Get(context.Background(), "img4.16.12-x86-64")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Name).To(Equal("img4.16.12-x86-64"))
+		Expect(info.ReleaseImage).To(Equal("quay.io/openshift-release-dev/ocp-release:4.16.12-x86_64"))
+	})
+
+	It("should return an error when the ClusterImageSet has no release image", func() {
+		dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), newImageSet("broken", ""))
+
+		_, err := hub.NewClusterImageSetClient(dynamicClient).Get(context.Background(), "broken")
+		Expect(err).To(MatchError(ContainSubstring("has no spec.releaseImage")))
+	})
+
+	It("should return an error when the ClusterImageSet does not exist", func() {
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{})
+
+		_, err := hub.NewClusterImageSetClient(dynamicClient).Get(context.Background(), "missing")
+		Expect(err).To(MatchError(ContainSubstring("failed to get ClusterImageSet missing")))
+	})
+
+	Describe("List, Create, and Delete", func() {
+		var (
+			ctx           context.Context
+			dynamicClient *fake.FakeDynamicClient
+			client        hub.ClusterImageSetClient
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				{Group: "hive.openshift.io", Version: "v1", Resource: "clusterimagesets"}: "ClusterImageSetList",
+			}, newImageSet("img4.16.12-x86-64", "quay.io/openshift-release-dev/ocp-release:4.16.12-x86_64"))
+			client = hub.NewClusterImageSetClient(dynamicClient)
+		})
+
+		It("should create ClusterImageSets with labels and list them sorted by name", func() {
+			Expect(client.Create(ctx, "img4.15.30-x86-64", "quay.io/openshift-release-dev/ocp-release:4.15.30-x86_64",
+				map[string]string{"visible": "true"})).To(Succeed())
+
+			imageSets, err := client.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imageSets).To(Equal([]hub.ClusterImageSetInfo{
+				{Name: "img4.15.30-x86-64", ReleaseImage: "quay.io/openshift-release-dev/ocp-release:4.15.30-x86_64", Labels: map[string]string{"visible": "true"}},
+				{Name: "img4.16.12-x86-64", ReleaseImage: "quay.io/openshift-release-dev/ocp-release:4.16.12-x86_64"},
+			}))
+		})
+
+		It("should not replace a ClusterImageSet that exists", func() {
+			err := client.Create(ctx, "img4.16.12-x86-64", "quay.io/openshift-release-dev/ocp-release:4.16.13-x86_64", nil)
+			Expect(err).To(MatchError("ClusterImageSet img4.16.12-x86-64 already exists"))
+		})
+
+		It("should delete a ClusterImageSet", func() {
+			Expect(client.Delete(ctx, "img4.16.12-x86-64")).To(Succeed())
+			Expect(client.List(ctx)).To(BeEmpty())
+
+			Expect(client.Delete(ctx, "img4.16.12-x86-64")).To(MatchError("ClusterImageSet img4.16.12-x86-64 not found"))
+		})
+	})
+})
@@ -0,0 +1,196 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// DefaultImportTimeout bounds how long Manifests waits for ACM to generate the import secret
+	DefaultImportTimeout = 2 * time.Minute
+
+	// importSecretSuffix is appended to the cluster name to form the name of the import secret
+	importSecretSuffix = "-import"
+	// importCRDsKey is the key of the import secret holding the Klusterlet CRD
+	importCRDsKey = "crds.yaml"
+	// importManifestKey is the key of the import secret holding the klusterlet deployment
+	importManifestKey = "import.yaml"
+)
+
+// klusterletAddonConfigGVR identifies the ACM KlusterletAddonConfig resources in cluster namespaces
+var klusterletAddonConfigGVR = schema.GroupVersionResource{
+	Group:    "agent.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "klusterletaddonconfigs",
+}
+
+// importLabels are set on imported ManagedClusters so ACM detects their cloud and vendor
+var importLabels = map[string]string{
+	"cloud":  "auto-detect",
+	"vendor": "auto-detect",
+}
+
+// klusterletAddOns are the add-ons enabled by the KlusterletAddonConfig of an imported cluster
+var klusterletAddOns = []string{"applicationManager", "certPolicyController", "policyController", "searchCollector"}
+
+// ImportOptions controls how long Manifests waits for the import secret
+type ImportOptions struct {
+	// Timeout bounds how long Manifests waits for the import secret
+	Timeout time.Duration
+	// PollInterval is how often the import secret is checked
+	PollInterval time.Duration
+}
+
+// ImportManifests are the manifests that install the klusterlet on a cluster being imported
+type ImportManifests struct {
+	// CRDs holds the Klusterlet CRD, which must be applied first
+	CRDs []byte
+	// Import holds the klusterlet operator, its bootstrap hub kubeconfig, and the Klusterlet
+	Import []byte
+}
+
+// YAML returns the CRDs and the import manifests as a single multi-document YAML
+func (m *ImportManifests) YAML() []byte {
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimRight(m.CRDs, "\n"))
+	buf.WriteString("\n---\n")
+	buf.Write(bytes.TrimRight(m.Import, "\n"))
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// Importer attaches existing clusters to the hub
+type Importer interface {
+	// Create creates the cluster namespace, the ManagedCluster, and the KlusterletAddonConfig of
+	// a cluster to import, with labels added to the ManagedCluster. Objects that already exist
+	// are left as they are, so an interrupted import can be resumed.
+	Create(ctx context.Context, name string, labels map[string]string) error
+	// Manifests waits for ACM to generate the import secret of a cluster and returns its manifests
+	Manifests(ctx context.Context, name string) (*ImportManifests, error)
+}
+
+type importer struct {
+	coreClient    corev1client.CoreV1Interface
+	dynamicClient dynamic.Interface
+	opts          ImportOptions
+	options       kube.Options
+}
+
+// NewImporter creates a new Importer using clients connected to the hub
+func NewImporter(coreClient corev1client.CoreV1Interface, dynamicClient dynamic.Interface, opts ImportOptions, options ...kube.Option) Importer {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultImportTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	return &importer{
+		coreClient:    coreClient,
+		dynamicClient: dynamicClient,
+		opts:          opts,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Create creates the objects ACM needs to generate the import manifests of a cluster
+func (i *importer) Create(ctx context.Context, name string, labels map[string]string) error {
+	ctx, cancel, err := i.options.Start(ctx, "create cluster import", "name", name)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	_, err = i.coreClient.Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s: %w", name, err)
+	}
+
+	clusterLabels := map[string]interface{}{}
+	for key, value := range importLabels {
+		clusterLabels[key] = value
+	}
+	for key, value := range labels {
+		clusterLabels[key] = value
+	}
+	managedCluster := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": managedClusterGVR.GroupVersion().String(),
+		"kind":       "ManagedCluster",
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": clusterLabels,
+		},
+		"spec": map[string]interface{}{
+			"hubAcceptsClient": true,
+		},
+	}}
+	_, err = i.dynamicClient.Resource(managedClusterGVR).Create(ctx, managedCluster, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create ManagedCluster %s: %w", name, err)
+	}
+
+	addOns := map[string]interface{}{}
+	for _, addOn := range klusterletAddOns {
+		addOns[addOn] = map[string]interface{}{"enabled": true}
+	}
+	addonConfig := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": klusterletAddonConfigGVR.GroupVersion().String(),
+		"kind":       "KlusterletAddonConfig",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": name,
+		},
+		"spec": addOns,
+	}}
+	_, err = i.dynamicClient.Resource(klusterletAddonConfigGVR).Namespace(name).Create(ctx, addonConfig, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create KlusterletAddonConfig %s: %w", name, err)
+	}
+	return nil
+}
+
+// Manifests polls the import secret <name>-import in the cluster namespace until it holds
+// both manifests
+func (i *importer) Manifests(ctx context.Context, name string) (*ImportManifests, error) {
+	ctx, cancel, err := i.options.Start(ctx, "get import manifests", "name", name)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	secretName := name + importSecretSuffix
+	var manifests *ImportManifests
+	pollErr := wait.PollUntilContextTimeout(ctx, i.opts.PollInterval, i.opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		secret, err := i.coreClient.Secrets(name).Get(ctx, secretName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to get import secret %s/%s: %w", name, secretName, err)
+		}
+		crds, manifest := secret.Data[importCRDsKey], secret.Data[importManifestKey]
+		if len(crds) == 0 || len(manifest) == 0 {
+			return false, nil
+		}
+		manifests = &ImportManifests{CRDs: crds, Import: manifest}
+		return true, nil
+	})
+	if pollErr != nil {
+		return nil, fmt.Errorf("import secret %s/%s was not generated: %w", name, secretName, pollErr)
+	}
+	return manifests, nil
+}
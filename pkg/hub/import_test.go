@@ -0,0 +1,104 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Importer", func() {
+	var (
+		ctx             context.Context
+		managedGVR      schema.GroupVersionResource
+		addonConfigGVR  schema.GroupVersionResource
+		dynamicClient   *fake.FakeDynamicClient
+		coreClient      *k8sFake.Clientset
+		fastPollOptions hub.ImportOptions
+		importSecretFor func(name string, data map[string][]byte) *corev1.Secret
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		managedGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		addonConfigGVR = schema.GroupVersionResource{Group: "agent.open-cluster-management.io", Version: "v1", Resource: "klusterletaddonconfigs"}
+		dynamicClient = fake.NewSimpleDynamicClient(runtime.NewScheme())
+		coreClient = k8sFake.NewSimpleClientset()
+		fastPollOptions = hub.ImportOptions{Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+		importSecretFor = func(name string, data map[string][]byte) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name + "-import", Namespace: name},
+				Data:       data,
+			}
+		}
+	})
+
+	Describe("Create", func() {
+		It("should create the namespace, ManagedCluster, and KlusterletAddonConfig", func() {
+			importer := hub.NewImporter(coreClient.CoreV1(), dynamicClient, hub.ImportOptions{})
+
+			Expect(importer.Create(ctx, "partner-cluster", map[string]string{"partner": "acme"})).To(Succeed())
+
+			_, err := coreClient.CoreV1().Namespaces().Get(ctx, "partner-cluster", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			mc, err := dynamicClient.Resource(managedGVR).Get(ctx, "partner-cluster", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mc.GetLabels()).To(Equal(map[string]string{"cloud": "auto-detect", "vendor": "auto-detect", "partner": "acme"}))
+			accepts, _, _ := unstructured.NestedBool(mc.Object, "spec", "hubAcceptsClient")
+			Expect(accepts).To(BeTrue())
+
+			kac, err := dynamicClient.Resource(addonConfigGVR).Namespace("partner-cluster").Get(ctx, "partner-cluster", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			enabled, _, _ := unstructured.NestedBool(kac.Object, "spec", "searchCollector", "enabled")
+			Expect(enabled).To(BeTrue())
+		})
+
+		It("should leave existing objects in place", func() {
+			importer := hub.NewImporter(coreClient.CoreV1(), dynamicClient, hub.ImportOptions{})
+
+			Expect(importer.Create(ctx, "partner-cluster", nil)).To(Succeed())
+			Expect(importer.Create(ctx, "partner-cluster", nil)).To(Succeed())
+		})
+	})
+
+	Describe("Manifests", func() {
+		It("should return the manifests of the import secret", func() {
+			coreClient = k8sFake.NewSimpleClientset(importSecretFor("partner-cluster", map[string][]byte{
+				"crds.yaml":   []byte("kind: CustomResourceDefinition\n"),
+				"import.yaml": []byte("kind: Klusterlet\n"),
+			}))
+
+			manifests, err := hub.NewImporter(coreClient.CoreV1(), dynamicClient, fastPollOptions).Manifests(ctx, "partner-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifests.CRDs)).To(Equal("kind: CustomResourceDefinition\n"))
+			Expect(string(manifests.Import)).To(Equal("kind: Klusterlet\n"))
+			Expect(string(manifests.YAML())).To(Equal("kind: CustomResourceDefinition\n---\nkind: Klusterlet\n"))
+		})
+
+		It("should time out when the import secret is not generated", func() {
+			_, err := hub.NewImporter(coreClient.CoreV1(), dynamicClient, fastPollOptions).Manifests(ctx, "partner-cluster")
+			Expect(err).To(MatchError(ContainSubstring("import secret partner-cluster/partner-cluster-import was not generated")))
+		})
+
+		It("should wait until the import secret holds both manifests", func() {
+			coreClient = k8sFake.NewSimpleClientset(importSecretFor("partner-cluster", map[string][]byte{
+				"crds.yaml": []byte("kind: CustomResourceDefinition\n"),
+			}))
+
+			_, err := hub.NewImporter(coreClient.CoreV1(), dynamicClient, fastPollOptions).Manifests(ctx, "partner-cluster")
+			Expect(err).To(MatchError(ContainSubstring("was not generated")))
+		})
+	})
+})
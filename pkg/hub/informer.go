@@ -0,0 +1,239 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// NewInformerCache creates a kube.InformerCache of the ManagedClusters and ClusterDeployments
+// of the hub, and of the ManagedClusterInfos if withClusterInfo is set, for the informer
+// clients of this package
+func NewInformerCache(dynamicClient dynamic.Interface, resync time.Duration, withClusterInfo bool, options ...kube.Option) kube.InformerCache {
+	gvrs := []schema.GroupVersionResource{managedClusterGVR, clusterDeploymentGVR}
+	if withClusterInfo {
+		gvrs = append(gvrs, managedClusterInfoGVR)
+	}
+	return kube.NewInformerCache(dynamicClient, resync, gvrs, options...)
+}
+
+// informerManagedClusterClient answers List and Watch from a kube.InformerCache and delegates
+// everything else
+type informerManagedClusterClient struct {
+	ManagedClusterClient
+	cache kube.InformerCache
+}
+
+// NewInformerManagedClusterClient wraps client so List and Watch read from informerCache,
+// which must have been created with NewInformerCache
+func NewInformerManagedClusterClient(client ManagedClusterClient, informerCache kube.InformerCache) ManagedClusterClient {
+	return &informerManagedClusterClient{ManagedClusterClient: client, cache: informerCache}
+}
+
+// List returns the cached clusters, sorted by name like the API server lists them
+func (c *informerManagedClusterClient) List(_ context.Context) ([]ManagedClusterInfo, error) {
+	objects, err := c.cache.List(managedClusterGVR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed clusters: %w", err)
+	}
+	clusters := make([]ManagedClusterInfo, 0, len(objects))
+	for _, obj := range objects {
+		info, err := toManagedClusterInfo(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, info)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+	return clusters, nil
+}
+
+// Watch streams the changes the informer sees, starting with the cached clusters as Added
+// events. Resyncs, which redeliver unchanged clusters, are not sent. The channel is closed
+// when ctx is done or after an Error event.
+func (c *informerManagedClusterClient) Watch(ctx context.Context) (<-chan ManagedClusterEvent, error) {
+	events := make(chan ManagedClusterEvent)
+	var (
+		mu     sync.Mutex
+		closed bool
+	)
+	// closeEvents must be called with mu held
+	closeEvents := func() {
+		if !closed {
+			closed = true
+			close(events)
+		}
+	}
+	send := func(eventType watch.EventType, obj interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		cluster, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return
+		}
+		info, err := toManagedClusterInfo(cluster.Object)
+		if err != nil {
+			sendEvent(ctx, events, ManagedClusterEvent{Type: watch.Error, Err: err})
+			closeEvents()
+			return
+		}
+		if !sendEvent(ctx, events, ManagedClusterEvent{Type: eventType, Cluster: info}) {
+			closeEvents()
+		}
+	}
+
+	remove, err := c.cache.AddEventHandler(managedClusterGVR, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { send(watch.Added, obj) },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldCluster, oldOK := oldObj.(*unstructured.Unstructured)
+			newCluster, newOK := newObj.(*unstructured.Unstructured)
+			if oldOK && newOK && oldCluster.GetResourceVersion() == newCluster.GetResourceVersion() {
+				return
+			}
+			send(watch.Modified, newObj)
+		},
+		DeleteFunc: func(obj interface{}) { send(watch.Deleted, obj) },
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch managed clusters: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		remove()
+		mu.Lock()
+		defer mu.Unlock()
+		closeEvents()
+	}()
+	return events, nil
+}
+
+// informerClusterDeploymentClient answers every lookup from a kube.InformerCache
+type informerClusterDeploymentClient struct {
+	cache kube.InformerCache
+}
+
+// NewInformerClusterDeploymentClient creates a ClusterDeploymentClient reading from
+// informerCache, which must have been created with NewInformerCache
+func NewInformerClusterDeploymentClient(informerCache kube.InformerCache) ClusterDeploymentClient {
+	return &informerClusterDeploymentClient{cache: informerCache}
+}
+
+// Get returns the cached ClusterDeployment in the namespace matching the cluster name
+func (c *informerClusterDeploymentClient) Get(_ context.Context, name string) (*ClusterDeploymentInfo, error) {
+	obj, err := c.cache.Get(clusterDeploymentGVR, name, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterDeployment %s: %w", name, err)
+	}
+	info, err := parseClusterDeployment(obj.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ClusterDeployment %s: %w", name, err)
+	}
+	return info, nil
+}
+
+// List returns the cached ClusterDeployments, sorted by namespace and name like the API
+// server lists them
+func (c *informerClusterDeploymentClient) List(_ context.Context) ([]ClusterDeploymentInfo, error) {
+	objects, err := c.cache.List(clusterDeploymentGVR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterDeployments: %w", err)
+	}
+	deployments := make([]ClusterDeploymentInfo, 0, len(objects))
+	var failed []error
+	for _, obj := range objects {
+		if info := parseListedClusterDeployment(obj, &failed); info != nil {
+			deployments = append(deployments, *info)
+		}
+	}
+	sort.Slice(deployments, func(i, j int) bool {
+		if deployments[i].Namespace != deployments[j].Namespace {
+			return deployments[i].Namespace < deployments[j].Namespace
+		}
+		return deployments[i].Name < deployments[j].Name
+	})
+	return deployments, decodeError(failed)
+}
+
+// FindByRequestID returns the cached ClusterDeployment labeled with the request ID, or nil
+// if there is none. Like the API client, multiple matches are reported as an error.
+func (c *informerClusterDeploymentClient) FindByRequestID(_ context.Context, requestID string) (*ClusterDeploymentInfo, error) {
+	objects, err := c.cache.List(clusterDeploymentGVR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterDeployments for request %s: %w", requestID, err)
+	}
+	var matches []*unstructured.Unstructured
+	for _, obj := range objects {
+		if obj.GetLabels()[RequestIDLabel] == requestID {
+			matches = append(matches, obj)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		info, err := parseClusterDeployment(matches[0].Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ClusterDeployment %s: %w", matches[0].GetName(), err)
+		}
+		return info, nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, obj := range matches {
+			names = append(names, obj.GetNamespace()+"/"+obj.GetName())
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("request %s maps to multiple ClusterDeployments: %s", requestID, strings.Join(names, ", "))
+	}
+}
+
+// informerClusterInfoClient answers every lookup from a kube.InformerCache
+type informerClusterInfoClient struct {
+	cache kube.InformerCache
+}
+
+// NewInformerClusterInfoClient creates a ClusterInfoClient reading from informerCache, which
+// must have been created with NewInformerCache with withClusterInfo set
+func NewInformerClusterInfoClient(informerCache kube.InformerCache) ClusterInfoClient {
+	return &informerClusterInfoClient{cache: informerCache}
+}
+
+// Get returns the cached ManagedClusterInfo of a cluster
+func (c *informerClusterInfoClient) Get(_ context.Context, name string) (*ClusterAgentInfo, error) {
+	obj, err := c.cache.Get(managedClusterInfoGVR, name, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ManagedClusterInfo %s: %w", name, err)
+	}
+	return parseManagedClusterInfo(obj), nil
+}
+
+// List returns the cached ManagedClusterInfos, sorted by name
+func (c *informerClusterInfoClient) List(_ context.Context) ([]ClusterAgentInfo, error) {
+	objects, err := c.cache.List(managedClusterInfoGVR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ManagedClusterInfos: %w", err)
+	}
+	infos := make([]ClusterAgentInfo, 0, len(objects))
+	for _, obj := range objects {
+		infos = append(infos, *parseManagedClusterInfo(obj))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
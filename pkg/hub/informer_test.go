@@ -0,0 +1,205 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+var _ = Describe("Informer clients", func() {
+	var (
+		ctx           context.Context
+		cancel        context.CancelFunc
+		fakeDynamic   *fake.FakeDynamicClient
+		informerCache kube.InformerCache
+		mcGVR         schema.GroupVersionResource
+	)
+
+	managedCluster := func(name, available string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cluster.open-cluster-management.io/v1",
+			"kind":       "ManagedCluster",
+			"metadata":   map[string]interface{}{"name": name, "resourceVersion": "1"},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{
+					"type": "ManagedClusterConditionAvailable", "status": available,
+					"reason": "Test", "message": "", "lastTransitionTime": "2026-01-01T00:00:00Z",
+				}},
+			},
+		}}
+	}
+
+	clusterDeployment := func(name, requestID string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterDeployment",
+			"metadata":   map[string]interface{}{"name": name, "namespace": name},
+			"spec":       map[string]interface{}{"installed": true},
+		}}
+		if requestID != "" {
+			obj.SetLabels(map[string]string{hub.RequestIDLabel: requestID})
+		}
+		return obj
+	}
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		mcGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		fakeDynamic = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				mcGVR: "ManagedClusterList",
+				{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}:                         "ClusterDeploymentList",
+				{Group: "internal.open-cluster-management.io", Version: "v1beta1", Resource: "managedclusterinfos"}: "ManagedClusterInfoList",
+			},
+			managedCluster("spoke-b", "True"),
+			managedCluster("spoke-a", "False"),
+			clusterDeployment("spoke-b", "1234"),
+			clusterDeployment("spoke-a", "5678"),
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "internal.open-cluster-management.io/v1beta1",
+				"kind":       "ManagedClusterInfo",
+				"metadata":   map[string]interface{}{"name": "spoke-b", "namespace": "spoke-b"},
+				"status":     map[string]interface{}{"version": "v1.29.5", "cloudVendor": "Amazon"},
+			}},
+		)
+		informerCache = hub.NewInformerCache(fakeDynamic, kube.DefaultResyncPeriod, true)
+		informerCache.Start(ctx)
+		Expect(informerCache.WaitForSync(ctx)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	Describe("NewInformerManagedClusterClient", func() {
+		var client hub.ManagedClusterClient
+
+		BeforeEach(func() {
+			client = hub.NewInformerManagedClusterClient(hub.NewManagedClusterClient(fakeDynamic), informerCache)
+		})
+
+		It("should list the cached clusters sorted by name", func() {
+			clusters, err := client.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusters).To(HaveLen(2))
+			Expect(clusters[0].Name).To(Equal("spoke-a"))
+			Expect(clusters[0].Status).To(Equal(hub.StatusNotReady))
+			Expect(clusters[1].Name).To(Equal("spoke-b"))
+			Expect(clusters[1].Status).To(Equal(hub.StatusReady))
+		})
+
+		It("should stream the cached clusters and then their changes", func() {
+			events, err := client.Watch(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			var added []string
+			for range 2 {
+				var event hub.ManagedClusterEvent
+				Eventually(events).Should(Receive(&event))
+				Expect(event.Type).To(Equal(watch.Added))
+				added = append(added, event.Cluster.Name)
+			}
+			Expect(added).To(ConsistOf("spoke-a", "spoke-b"))
+
+			updated := managedCluster("spoke-a", "True")
+			updated.SetResourceVersion("2")
+			_, err = fakeDynamic.Resource(mcGVR).Update(ctx, updated, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			var event hub.ManagedClusterEvent
+			Eventually(events).Should(Receive(&event))
+			Expect(event.Type).To(Equal(watch.Modified))
+			Expect(event.Cluster.Status).To(Equal(hub.StatusReady))
+
+			Expect(fakeDynamic.Resource(mcGVR).Delete(ctx, "spoke-b", metav1.DeleteOptions{})).To(Succeed())
+			Eventually(events).Should(Receive(&event))
+			Expect(event.Type).To(Equal(watch.Deleted))
+			Expect(event.Cluster.Name).To(Equal("spoke-b"))
+		})
+
+		It("should close the events when the context is done", func() {
+			watchCtx, stop := context.WithCancel(ctx)
+			events, err := client.Watch(watchCtx)
+			Expect(err).NotTo(HaveOccurred())
+			stop()
+			Eventually(events).Should(BeClosed())
+		})
+	})
+
+	Describe("NewInformerClusterDeploymentClient", func() {
+		var client hub.ClusterDeploymentClient
+
+		BeforeEach(func() {
+			client = hub.NewInformerClusterDeploymentClient(informerCache)
+		})
+
+		It("should get and list the cached ClusterDeployments", func() {
+			deployment, err := client.Get(ctx, "spoke-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployment.Namespace).To(Equal("spoke-a"))
+			Expect(deployment.Installed).To(BeTrue())
+
+			deployments, err := client.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployments).To(HaveLen(2))
+			Expect(deployments[0].Name).To(Equal("spoke-a"))
+			Expect(deployments[1].Name).To(Equal("spoke-b"))
+		})
+
+		It("should report missing ClusterDeployments as not found", func() {
+			_, err := client.Get(ctx, "imported")
+			Expect(err).To(MatchError(`failed to get ClusterDeployment imported: clusterdeployments.hive.openshift.io "imported" not found`))
+		})
+
+		It("should find ClusterDeployments by request ID", func() {
+			deployment, err := client.FindByRequestID(ctx, "1234")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployment.Name).To(Equal("spoke-b"))
+
+			deployment, err = client.FindByRequestID(ctx, "9999")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployment).To(BeNil())
+		})
+	})
+
+	Describe("NewInformerClusterInfoClient", func() {
+		It("should get and list the cached ManagedClusterInfos", func() {
+			client := hub.NewInformerClusterInfoClient(informerCache)
+
+			info, err := client.Get(ctx, "spoke-b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.KubernetesVersion).To(Equal("v1.29.5"))
+
+			infos, err := client.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(infos).To(HaveLen(1))
+
+			_, err = client.Get(ctx, "spoke-a")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("should combine the cached clusters without calling the API", func() {
+		actions := len(fakeDynamic.Actions())
+		combined, err := hub.NewCombinedClusterClient(
+			hub.NewInformerManagedClusterClient(hub.NewManagedClusterClient(fakeDynamic), informerCache),
+			hub.NewInformerClusterDeploymentClient(informerCache),
+			hub.NewInformerClusterInfoClient(informerCache),
+		).ListCombined(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(combined).To(HaveLen(2))
+		Expect(combined[1].KubernetesVersion).To(Equal("v1.29.5"))
+		Expect(fakeDynamic.Actions()).To(HaveLen(actions))
+	})
+})
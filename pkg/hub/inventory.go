@@ -0,0 +1,279 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// InventoryAPIVersion is the version of the inventory format
+	InventoryAPIVersion = "labrat.io/v1alpha1"
+	// InventoryKind is the kind of inventory files
+	InventoryKind = "Inventory"
+)
+
+// Inventory is a snapshot of the clusters of a hub, saved by labrat hub export and compared
+// with the live hub by labrat hub diff
+type Inventory struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// Hub is the name of the hub the snapshot was taken of
+	Hub string `json:"hub"`
+	// Clusters are the clusters of the hub, sorted by name
+	Clusters []InventoryCluster `json:"clusters"`
+}
+
+// InventoryCluster is a cluster of an Inventory: the combined cluster info with the labels
+// of its ManagedCluster and its lease
+type InventoryCluster struct {
+	CombinedClusterInfo
+	// Labels are the labels of the ManagedCluster
+	Labels map[string]string `json:",omitempty"`
+	// LeaseExpiresAt is the end of the lease of the cluster, nil without a lease
+	LeaseExpiresAt *time.Time `json:",omitempty"`
+}
+
+// NewInventory creates a snapshot of hub taken now from its combined clusters, its managed
+// clusters, which carry the labels, and its leases
+func NewInventory(hub string, clusters []CombinedClusterInfo, managed []ManagedClusterInfo, leases []LeaseInfo) *Inventory {
+	labels := make(map[string]map[string]string, len(managed))
+	for _, cluster := range managed {
+		labels[cluster.Name] = cluster.Labels
+	}
+	leaseEnds := make(map[string]time.Time, len(leases))
+	for _, lease := range leases {
+		leaseEnds[lease.Cluster] = lease.ExpiresAt.UTC()
+	}
+
+	inventory := &Inventory{
+		APIVersion: InventoryAPIVersion,
+		Kind:       InventoryKind,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		Hub:        hub,
+		Clusters:   make([]InventoryCluster, 0, len(clusters)),
+	}
+	for _, cluster := range clusters {
+		entry := InventoryCluster{CombinedClusterInfo: cluster, Labels: labels[cluster.Name]}
+		if expiresAt, ok := leaseEnds[cluster.Name]; ok {
+			entry.LeaseExpiresAt = &expiresAt
+		}
+		inventory.Clusters = append(inventory.Clusters, entry)
+	}
+	sort.Slice(inventory.Clusters, func(i, j int) bool { return inventory.Clusters[i].Name < inventory.Clusters[j].Name })
+	return inventory
+}
+
+// Validate checks that the inventory is an inventory of a supported version
+func (i *Inventory) Validate() error {
+	if i.APIVersion != InventoryAPIVersion || i.Kind != InventoryKind {
+		return fmt.Errorf("not a labrat inventory: expected apiVersion %s and kind %s, got %q and %q", InventoryAPIVersion, InventoryKind, i.APIVersion, i.Kind)
+	}
+	for n, cluster := range i.Clusters {
+		if cluster.Name == "" {
+			return fmt.Errorf("cluster %d of the inventory has no name", n+1)
+		}
+	}
+	return nil
+}
+
+// Write writes the inventory as indented JSON to out
+func (i *Inventory) Write(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(i); err != nil {
+		return fmt.Errorf("failed to encode inventory: %w", err)
+	}
+	return nil
+}
+
+// Save writes the inventory to the file at path, or to stdout if path is -
+func (i *Inventory) Save(path string) error {
+	if path == "-" {
+		return i.Write(os.Stdout)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create inventory file: %w", err)
+	}
+	if err := i.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write inventory file: %w", err)
+	}
+	return nil
+}
+
+// LoadInventory reads and validates the inventory file at path
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory file: %w", err)
+	}
+	var inventory Inventory
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory file: %w", err)
+	}
+	if err := inventory.Validate(); err != nil {
+		return nil, err
+	}
+	return &inventory, nil
+}
+
+// InventoryDiff lists how the clusters of a hub changed between two inventories
+type InventoryDiff struct {
+	// Added are the clusters only in the newer inventory
+	Added []InventoryCluster
+	// Removed are the clusters only in the older inventory
+	Removed []InventoryCluster
+	// Changed are the clusters in both whose compared fields differ
+	Changed []ClusterChange
+}
+
+// ClusterChange is how the compared fields of a cluster changed
+type ClusterChange struct {
+	Name    string
+	Changes []FieldChange
+}
+
+// FieldChange is the old and new value of a field; empty values are absent
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Empty reports whether the inventories have the same clusters with the same compared fields
+func (d InventoryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// inventoryFields are the fields DiffInventories compares. Fields that change on their own,
+// such as the status message, are left out so weekly reports show what was changed.
+var inventoryFields = []struct {
+	name  string
+	value func(InventoryCluster) string
+}{
+	{"status", func(c InventoryCluster) string { return string(c.Status) }},
+	{"powerState", func(c InventoryCluster) string { return c.PowerState }},
+	{"platform", func(c InventoryCluster) string { return c.Platform }},
+	{"region", func(c InventoryCluster) string { return c.Region }},
+	{"version", func(c InventoryCluster) string { return c.Version }},
+	{"nodes", func(c InventoryCluster) string {
+		if c.NodeCount == 0 {
+			return ""
+		}
+		return strconv.Itoa(c.NodeCount)
+	}},
+	{"clusterSet", func(c InventoryCluster) string { return c.ClusterSet }},
+	{"lease", func(c InventoryCluster) string {
+		if c.LeaseExpiresAt == nil {
+			return ""
+		}
+		return c.LeaseExpiresAt.UTC().Format(time.RFC3339)
+	}},
+}
+
+// DiffInventories compares the clusters of older and newer by name. Changed clusters list
+// the compared fields that differ, then each label that was added, removed, or changed as
+// label <key>.
+func DiffInventories(older, newer *Inventory) InventoryDiff {
+	olderByName := make(map[string]InventoryCluster, len(older.Clusters))
+	for _, cluster := range older.Clusters {
+		olderByName[cluster.Name] = cluster
+	}
+	newerNames := make(map[string]bool, len(newer.Clusters))
+
+	var diff InventoryDiff
+	for _, cluster := range newer.Clusters {
+		newerNames[cluster.Name] = true
+		previous, ok := olderByName[cluster.Name]
+		if !ok {
+			diff.Added = append(diff.Added, cluster)
+			continue
+		}
+		if changes := diffClusters(previous, cluster); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, ClusterChange{Name: cluster.Name, Changes: changes})
+		}
+	}
+	for _, cluster := range older.Clusters {
+		if !newerNames[cluster.Name] {
+			diff.Removed = append(diff.Removed, cluster)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Name < diff.Added[j].Name })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Name < diff.Removed[j].Name })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+	return diff
+}
+
+// diffClusters returns the compared fields and labels that differ between two snapshots of
+// a cluster
+func diffClusters(older, newer InventoryCluster) []FieldChange {
+	var changes []FieldChange
+	for _, field := range inventoryFields {
+		if oldValue, newValue := field.value(older), field.value(newer); oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field.name, Old: oldValue, New: newValue})
+		}
+	}
+
+	keys := slices.Collect(maps.Keys(older.Labels))
+	for key := range newer.Labels {
+		if _, ok := older.Labels[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		oldValue, hadLabel := older.Labels[key]
+		newValue, hasLabel := newer.Labels[key]
+		if hadLabel != hasLabel || oldValue != newValue {
+			changes = append(changes, FieldChange{Field: "label " + key, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+// WriteInventoryDiff writes diff to out, one row per added or removed cluster and per
+// changed field, then the number of clusters of each kind of change
+func WriteInventoryDiff(out io.Writer, diff InventoryDiff) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CHANGE\tCLUSTER\tDETAILS")
+	describe := func(cluster InventoryCluster) string {
+		return fmt.Sprintf("%s, %s %s, %s", valueOrNA(string(cluster.Status)), valueOrNA(cluster.Platform),
+			valueOrNA(cluster.Region), valueOrNA(cluster.Version))
+	}
+	for _, cluster := range diff.Added {
+		fmt.Fprintf(w, "added\t%s\t%s\n", cluster.Name, describe(cluster))
+	}
+	for _, cluster := range diff.Removed {
+		fmt.Fprintf(w, "removed\t%s\t%s\n", cluster.Name, describe(cluster))
+	}
+	orNone := func(value string) string {
+		if value == "" {
+			return "(none)"
+		}
+		return value
+	}
+	for _, cluster := range diff.Changed {
+		for _, change := range cluster.Changes {
+			fmt.Fprintf(w, "changed\t%s\t%s: %s → %s\n", cluster.Name, change.Field, orNone(change.Old), orNone(change.New))
+		}
+	}
+	fmt.Fprintf(w, "\n%d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
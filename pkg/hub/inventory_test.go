@@ -0,0 +1,107 @@
+//go:build test
+
+package hub_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("Inventory", func() {
+	leaseEnd := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	newInventory := func() *hub.Inventory {
+		return hub.NewInventory("lab-east",
+			[]hub.CombinedClusterInfo{
+				{Name: "partner-b", Status: hub.StatusReady, Platform: "aws", Region: "us-east-1", Version: "4.15.9", PowerState: "Running", NodeCount: 6},
+				{Name: "partner-a", Status: hub.StatusReady, Platform: "gcp", Region: "us-central1", Version: "4.16.3", PowerState: "Hibernating"},
+			},
+			[]hub.ManagedClusterInfo{
+				{Name: "partner-a", Labels: map[string]string{"env": "dev"}},
+				{Name: "partner-b", Labels: map[string]string{"env": "prod", "partner": "acme"}},
+			},
+			[]hub.LeaseInfo{{Cluster: "partner-b", ExpiresAt: leaseEnd}},
+		)
+	}
+
+	It("should snapshot the clusters with their labels and leases, sorted by name", func() {
+		inventory := newInventory()
+		Expect(inventory.Hub).To(Equal("lab-east"))
+		Expect(inventory.Clusters).To(HaveLen(2))
+		Expect(inventory.Clusters[0].Name).To(Equal("partner-a"))
+		Expect(inventory.Clusters[0].Labels).To(Equal(map[string]string{"env": "dev"}))
+		Expect(inventory.Clusters[0].LeaseExpiresAt).To(BeNil())
+		Expect(*inventory.Clusters[1].LeaseExpiresAt).To(Equal(leaseEnd))
+		Expect(inventory.Validate()).To(Succeed())
+	})
+
+	It("should save and load an inventory", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "inventory.json")
+		inventory := newInventory()
+		Expect(inventory.Save(path)).To(Succeed())
+
+		loaded, err := hub.LoadInventory(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.CreatedAt.Equal(inventory.CreatedAt)).To(BeTrue())
+		Expect(loaded.Clusters).To(HaveLen(2))
+		Expect(loaded.Clusters[1].Version).To(Equal("4.15.9"))
+		Expect(loaded.Clusters[1].Labels).To(HaveKeyWithValue("partner", "acme"))
+		Expect(hub.DiffInventories(inventory, loaded).Empty()).To(BeTrue())
+	})
+
+	It("should reject files that are not inventories", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "plan.json")
+		Expect(os.WriteFile(path, []byte(`{"apiVersion": "labrat.io/v1alpha1", "kind": "Plan"}`), 0600)).To(Succeed())
+
+		_, err := hub.LoadInventory(path)
+		Expect(err).To(MatchError(`not a labrat inventory: expected apiVersion labrat.io/v1alpha1 and kind Inventory, got "labrat.io/v1alpha1" and "Plan"`))
+	})
+
+	Describe("DiffInventories", func() {
+		It("should report added, removed, and changed clusters", func() {
+			older := newInventory()
+			newer := newInventory()
+			newer.Clusters[0].Version = "4.16.5"
+			newer.Clusters[0].Labels = map[string]string{"env": "prod", "partner": "initech"}
+			newer.Clusters[1] = hub.InventoryCluster{CombinedClusterInfo: hub.CombinedClusterInfo{Name: "partner-c", Status: hub.StatusNotReady}}
+
+			diff := hub.DiffInventories(older, newer)
+			Expect(diff.Added).To(HaveLen(1))
+			Expect(diff.Added[0].Name).To(Equal("partner-c"))
+			Expect(diff.Removed).To(HaveLen(1))
+			Expect(diff.Removed[0].Name).To(Equal("partner-b"))
+			Expect(diff.Changed).To(Equal([]hub.ClusterChange{{
+				Name: "partner-a",
+				Changes: []hub.FieldChange{
+					{Field: "version", Old: "4.16.3", New: "4.16.5"},
+					{Field: "label env", Old: "dev", New: "prod"},
+					{Field: "label partner", Old: "", New: "initech"},
+				},
+			}}))
+
+			var out bytes.Buffer
+			Expect(hub.WriteInventoryDiff(&out, diff)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("added     partner-c   NotReady, N/A N/A, N/A"))
+			Expect(out.String()).To(ContainSubstring("removed   partner-b   Ready, aws us-east-1, 4.15.9"))
+			Expect(out.String()).To(ContainSubstring("changed   partner-a   label partner: (none) → initech"))
+			Expect(out.String()).To(ContainSubstring("1 added, 1 removed, 1 changed"))
+		})
+
+		It("should report lease changes", func() {
+			older := newInventory()
+			newer := newInventory()
+			extended := leaseEnd.Add(7 * 24 * time.Hour)
+			newer.Clusters[1].LeaseExpiresAt = &extended
+
+			diff := hub.DiffInventories(older, newer)
+			Expect(diff.Changed).To(HaveLen(1))
+			Expect(diff.Changed[0].Changes).To(ConsistOf(hub.FieldChange{Field: "lease", Old: "2026-03-01T12:00:00Z", New: "2026-03-08T12:00:00Z"}))
+		})
+	})
+})
@@ -0,0 +1,178 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// LeaseExpiryAnnotation records the end of a cluster's lease on its ClusterDeployment, as an
+	// RFC 3339 timestamp
+	LeaseExpiryAnnotation = "labrat.openshift-partner-labs.io/expires-at"
+
+	// DefaultLeaseWarning is how long before the end of a lease the cluster is reported as expiring
+	DefaultLeaseWarning = 72 * time.Hour
+)
+
+// Lease states reported by LeaseInfo.State
+const (
+	// LeaseStateActive means the lease ends after the warning window
+	LeaseStateActive = "Active"
+	// LeaseStateExpiring means the lease ends within the warning window
+	LeaseStateExpiring = "Expiring"
+	// LeaseStateExpired means the lease has ended
+	LeaseStateExpired = "Expired"
+)
+
+// LeaseInfo is the lease of a cluster
+type LeaseInfo struct {
+	// Cluster is the name of the ClusterDeployment
+	Cluster string
+	// ExpiresAt is the end of the lease
+	ExpiresAt time.Time
+	// PowerState is the power state of the cluster, so expired clusters that still run stand out
+	PowerState string
+}
+
+// Expired reports whether the lease has ended at now
+func (l LeaseInfo) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// State returns the lease state at now, with warning as the expiring window
+func (l LeaseInfo) State(now time.Time, warning time.Duration) string {
+	switch {
+	case l.Expired(now):
+		return LeaseStateExpired
+	case l.ExpiresAt.Sub(now) <= warning:
+		return LeaseStateExpiring
+	default:
+		return LeaseStateActive
+	}
+}
+
+// LeaseClient manages the lifetime of clusters through an annotation on their ClusterDeployment
+type LeaseClient interface {
+	// Set sets the end of the lease of a cluster, replacing any existing lease
+	Set(ctx context.Context, cluster string, expiresAt time.Time) error
+	// Clear removes the lease of a cluster, so it never expires
+	Clear(ctx context.Context, cluster string) error
+	// List retrieves the clusters with a lease, ordered by the end of their lease
+	List(ctx context.Context) ([]LeaseInfo, error)
+}
+
+type leaseClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewLeaseClient creates a new LeaseClient
+func NewLeaseClient(dynamicClient dynamic.Interface, options ...kube.Option) LeaseClient {
+	return &leaseClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Set annotates the ClusterDeployment in namespace=cluster with the end of the lease
+func (l *leaseClient) Set(ctx context.Context, cluster string, expiresAt time.Time) error {
+	ctx, cancel, err := l.options.Start(ctx, "set lease", "cluster", cluster, "expiresAt", expiresAt)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return l.patchAnnotation(ctx, cluster, expiresAt.UTC().Format(time.RFC3339))
+}
+
+// Clear removes the lease annotation from the ClusterDeployment in namespace=cluster
+func (l *leaseClient) Clear(ctx context.Context, cluster string) error {
+	ctx, cancel, err := l.options.Start(ctx, "clear lease", "cluster", cluster)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return l.patchAnnotation(ctx, cluster, nil)
+}
+
+// patchAnnotation sets the lease annotation to value, or removes it if value is nil
+func (l *leaseClient) patchAnnotation(ctx context.Context, cluster string, value interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{LeaseExpiryAnnotation: value},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal lease patch: %w", err)
+	}
+
+	_, err = l.dynamicClient.Resource(clusterDeploymentGVR).Namespace(cluster).Patch(ctx, cluster, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update lease of ClusterDeployment %s: %w", cluster, err)
+	}
+	return nil
+}
+
+// List lists the ClusterDeployments in all namespaces and returns those with a lease
+func (l *leaseClient) List(ctx context.Context) ([]LeaseInfo, error) {
+	ctx, cancel, err := l.options.Start(ctx, "list leases")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	list, err := l.dynamicClient.Resource(clusterDeploymentGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterDeployments: %w", err)
+	}
+
+	leases := make([]LeaseInfo, 0)
+	var failed []error
+	for i := range list.Items {
+		cd := parseListedClusterDeployment(&list.Items[i], &failed)
+		if cd == nil || cd.ExpiresAt == nil {
+			continue
+		}
+		leases = append(leases, LeaseInfo{Cluster: cd.Name, ExpiresAt: *cd.ExpiresAt, PowerState: cd.PowerState})
+	}
+	sort.SliceStable(leases, func(i, j int) bool { return leases[i].ExpiresAt.Before(leases[j].ExpiresAt) })
+	return leases, decodeError(failed)
+}
+
+// ParseLeaseDuration parses a lease duration. Besides Go durations such as 36h, whole days and
+// weeks are accepted as 14d and 2w.
+func ParseLeaseDuration(value string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(value, "d"), strings.HasSuffix(value, "w"):
+		unit := 24 * time.Hour
+		if strings.HasSuffix(value, "w") {
+			unit *= 7
+		}
+		var n int
+		n, err = strconv.Atoi(value[:len(value)-1])
+		d = time.Duration(n) * unit
+	default:
+		d, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid lease duration %q (e.g. 14d, 2w, 36h)", value)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("lease duration must be positive, got %s", value)
+	}
+	return d, nil
+}
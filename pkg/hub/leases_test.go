@@ -0,0 +1,118 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("LeaseClient", func() {
+	var (
+		ctx           context.Context
+		cdGVR         schema.GroupVersionResource
+		dynamicClient *dynamicfake.FakeDynamicClient
+		leases        hub.LeaseClient
+	)
+
+	clusterDeployment := func(name string, annotations map[string]interface{}, powerState string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterDeployment",
+			"metadata":   map[string]interface{}{"name": name, "namespace": name, "annotations": annotations},
+			"spec":       map[string]interface{}{"powerState": powerState},
+		}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cdGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+		dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{cdGVR: "ClusterDeploymentList"},
+			clusterDeployment("late", map[string]interface{}{hub.LeaseExpiryAnnotation: "2026-03-01T00:00:00Z"}, "Running"),
+			clusterDeployment("early", map[string]interface{}{hub.LeaseExpiryAnnotation: "2026-01-01T00:00:00Z"}, "Hibernating"),
+			clusterDeployment("forever", nil, "Running"),
+			clusterDeployment("garbled", map[string]interface{}{hub.LeaseExpiryAnnotation: "next week"}, "Running"),
+		)
+		leases = hub.NewLeaseClient(dynamicClient)
+	})
+
+	Describe("List", func() {
+		It("should list the clusters with a valid lease ordered by expiry", func() {
+			result, err := leases.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal([]hub.LeaseInfo{
+				{Cluster: "early", ExpiresAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), PowerState: "Hibernating"},
+				{Cluster: "late", ExpiresAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), PowerState: "Running"},
+			}))
+		})
+	})
+
+	Describe("Set", func() {
+		It("should annotate the ClusterDeployment with the expiry in UTC", func() {
+			expiresAt := time.Date(2026, 5, 1, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+			Expect(leases.Set(ctx, "forever", expiresAt)).To(Succeed())
+
+			cd, err := dynamicClient.Resource(cdGVR).Namespace("forever").Get(ctx, "forever", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cd.GetAnnotations()).To(HaveKeyWithValue(hub.LeaseExpiryAnnotation, "2026-05-01T12:30:00Z"))
+
+			info, err := hub.NewClusterDeploymentClient(dynamicClient).Get(ctx, "forever")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.ExpiresAt).NotTo(BeNil())
+			Expect(info.ExpiresAt.Equal(expiresAt)).To(BeTrue())
+		})
+
+		It("should fail for a cluster without a ClusterDeployment", func() {
+			err := leases.Set(ctx, "missing", time.Now())
+			Expect(err).To(MatchError(ContainSubstring("failed to update lease of ClusterDeployment missing")))
+		})
+	})
+
+	Describe("Clear", func() {
+		It("should remove the lease annotation", func() {
+			Expect(leases.Clear(ctx, "early")).To(Succeed())
+
+			cd, err := dynamicClient.Resource(cdGVR).Namespace("early").Get(ctx, "early", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cd.GetAnnotations()).NotTo(HaveKey(hub.LeaseExpiryAnnotation))
+		})
+	})
+})
+
+var _ = Describe("LeaseInfo", func() {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	It("should report the lease state relative to the warning window", func() {
+		Expect(hub.LeaseInfo{ExpiresAt: now.Add(-time.Minute)}.State(now, hub.DefaultLeaseWarning)).To(Equal(hub.LeaseStateExpired))
+		Expect(hub.LeaseInfo{ExpiresAt: now}.State(now, hub.DefaultLeaseWarning)).To(Equal(hub.LeaseStateExpired))
+		Expect(hub.LeaseInfo{ExpiresAt: now.Add(24 * time.Hour)}.State(now, hub.DefaultLeaseWarning)).To(Equal(hub.LeaseStateExpiring))
+		Expect(hub.LeaseInfo{ExpiresAt: now.Add(7 * 24 * time.Hour)}.State(now, hub.DefaultLeaseWarning)).To(Equal(hub.LeaseStateActive))
+	})
+})
+
+var _ = Describe("ParseLeaseDuration", func() {
+	It("should accept days, weeks, and Go durations", func() {
+		Expect(hub.ParseLeaseDuration("14d")).To(Equal(14 * 24 * time.Hour))
+		Expect(hub.ParseLeaseDuration("2w")).To(Equal(14 * 24 * time.Hour))
+		Expect(hub.ParseLeaseDuration("36h")).To(Equal(36 * time.Hour))
+	})
+
+	It("should reject invalid and non-positive durations", func() {
+		_, err := hub.ParseLeaseDuration("fortnight")
+		Expect(err).To(MatchError(ContainSubstring(`invalid lease duration "fortnight"`)))
+		_, err = hub.ParseLeaseDuration("0d")
+		Expect(err).To(MatchError(ContainSubstring("lease duration must be positive")))
+		_, err = hub.ParseLeaseDuration("-3h")
+		Expect(err).To(MatchError(ContainSubstring("lease duration must be positive")))
+	})
+})
@@ -0,0 +1,155 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// MaintenanceLabel marks a ManagedCluster as in maintenance, e.g. while a partner's cluster
+	// is changed by hand; select the clusters in maintenance with MaintenanceLabel=true
+	MaintenanceLabel = "labrat.openshift-partner-labs.io/maintenance"
+	// MaintenanceReasonAnnotation records why a ManagedCluster is in maintenance
+	MaintenanceReasonAnnotation = "labrat.openshift-partner-labs.io/maintenance-reason"
+	// ReconcilePauseAnnotation stops Hive from reconciling a ClusterDeployment while it is "true"
+	ReconcilePauseAnnotation = "hive.openshift.io/reconcile-pause"
+)
+
+// MaintenanceChange reports which resources of a cluster a MaintenanceClient changed
+type MaintenanceChange struct {
+	// ClusterDeployment is set if the cluster has a ClusterDeployment, whose reconciliation
+	// was paused or resumed; imported clusters have none
+	ClusterDeployment bool
+	// ManagedCluster is set if the cluster has a ManagedCluster, which was labeled or unlabeled
+	ManagedCluster bool
+}
+
+// MaintenanceClient puts clusters in maintenance, so manual changes are not undone by Hive
+type MaintenanceClient interface {
+	// Start pauses the reconciliation of the ClusterDeployment of a cluster and labels its
+	// ManagedCluster with MaintenanceLabel and reason
+	Start(ctx context.Context, cluster, reason string) (MaintenanceChange, error)
+	// End resumes the reconciliation of the ClusterDeployment of a cluster and removes the
+	// maintenance label and reason of its ManagedCluster
+	End(ctx context.Context, cluster string) (MaintenanceChange, error)
+}
+
+type maintenanceClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewMaintenanceClient creates a new MaintenanceClient
+func NewMaintenanceClient(dynamicClient dynamic.Interface, options ...kube.Option) MaintenanceClient {
+	return &maintenanceClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Start annotates the ClusterDeployment in namespace=cluster with ReconcilePauseAnnotation,
+// then labels the ManagedCluster. Either resource may be missing, but not both.
+func (m *maintenanceClient) Start(ctx context.Context, cluster, reason string) (MaintenanceChange, error) {
+	ctx, cancel, err := m.options.Start(ctx, "start maintenance", "cluster", cluster)
+	if err != nil {
+		return MaintenanceChange{}, err
+	}
+	defer cancel()
+
+	// a null value removes the reason of an earlier maintenance
+	var reasonValue interface{}
+	if reason != "" {
+		reasonValue = reason
+	}
+	return m.patch(ctx, cluster, "true",
+		map[string]interface{}{
+			"labels":      map[string]interface{}{MaintenanceLabel: "true"},
+			"annotations": map[string]interface{}{MaintenanceReasonAnnotation: reasonValue},
+		})
+}
+
+// End removes ReconcilePauseAnnotation from the ClusterDeployment in namespace=cluster, then
+// the maintenance label and reason from the ManagedCluster
+func (m *maintenanceClient) End(ctx context.Context, cluster string) (MaintenanceChange, error) {
+	ctx, cancel, err := m.options.Start(ctx, "end maintenance", "cluster", cluster)
+	if err != nil {
+		return MaintenanceChange{}, err
+	}
+	defer cancel()
+
+	return m.patch(ctx, cluster, nil,
+		map[string]interface{}{
+			"labels":      map[string]interface{}{MaintenanceLabel: nil},
+			"annotations": map[string]interface{}{MaintenanceReasonAnnotation: nil},
+		})
+}
+
+// patch sets ReconcilePauseAnnotation of the ClusterDeployment of cluster to pause, nil to
+// remove it, and merges the metadata of the ManagedCluster, skipping the resources that do
+// not exist. If the ManagedCluster cannot be patched, the annotation is restored so Hive is
+// not left paused, or resumed, without the ManagedCluster showing it.
+func (m *maintenanceClient) patch(ctx context.Context, cluster string, pause interface{}, managedClusterMetadata map[string]interface{}) (MaintenanceChange, error) {
+	var change MaintenanceChange
+
+	// previous is the annotation to restore, nil if it was not set
+	var previous interface{}
+	deployment, err := m.dynamicClient.Resource(clusterDeploymentGVR).Namespace(cluster).Get(ctx, cluster, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		if value, ok := deployment.GetAnnotations()[ReconcilePauseAnnotation]; ok {
+			previous = value
+		}
+		if err := m.patchPause(ctx, cluster, pause); err != nil {
+			return change, err
+		}
+		change.ClusterDeployment = true
+	case !apierrors.IsNotFound(err):
+		return change, fmt.Errorf("failed to get ClusterDeployment %s: %w", cluster, err)
+	}
+
+	managedClusterPatch, err := json.Marshal(map[string]interface{}{"metadata": managedClusterMetadata})
+	if err != nil {
+		return change, fmt.Errorf("failed to marshal ManagedCluster patch: %w", err)
+	}
+	_, err = m.dynamicClient.Resource(managedClusterGVR).Patch(ctx, cluster, types.MergePatchType, managedClusterPatch, metav1.PatchOptions{})
+	switch {
+	case err == nil:
+		change.ManagedCluster = true
+	case !apierrors.IsNotFound(err):
+		if change.ClusterDeployment {
+			if restoreErr := m.patchPause(ctx, cluster, previous); restoreErr != nil {
+				return change, fmt.Errorf("failed to patch ManagedCluster %s: %w (restoring the ClusterDeployment also failed: %v)", cluster, err, restoreErr)
+			}
+		}
+		return MaintenanceChange{}, fmt.Errorf("failed to patch ManagedCluster %s: %w", cluster, err)
+	}
+
+	if !change.ClusterDeployment && !change.ManagedCluster {
+		return change, fmt.Errorf("cluster %s not found: it has neither a ClusterDeployment nor a ManagedCluster", cluster)
+	}
+	return change, nil
+}
+
+// patchPause sets ReconcilePauseAnnotation of the ClusterDeployment of cluster to value, or
+// removes it for nil
+func (m *maintenanceClient) patchPause(ctx context.Context, cluster string, value interface{}) error {
+	deploymentPatch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{ReconcilePauseAnnotation: value}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ClusterDeployment patch: %w", err)
+	}
+	_, err = m.dynamicClient.Resource(clusterDeploymentGVR).Namespace(cluster).Patch(ctx, cluster, types.MergePatchType, deploymentPatch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to patch ClusterDeployment %s: %w", cluster, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("MaintenanceClient", func() {
+	var (
+		ctx           context.Context
+		cdGVR         schema.GroupVersionResource
+		mcGVR         schema.GroupVersionResource
+		dynamicClient *dynamicfake.FakeDynamicClient
+		maintenance   hub.MaintenanceClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cdGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+		mcGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		dynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "hive.openshift.io/v1",
+				"kind":       "ClusterDeployment",
+				"metadata":   map[string]interface{}{"name": "partner-a", "namespace": "partner-a"},
+			}},
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "cluster.open-cluster-management.io/v1",
+				"kind":       "ManagedCluster",
+				"metadata":   map[string]interface{}{"name": "partner-a", "labels": map[string]interface{}{"env": "lab"}},
+			}},
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "cluster.open-cluster-management.io/v1",
+				"kind":       "ManagedCluster",
+				"metadata":   map[string]interface{}{"name": "imported"},
+			}},
+		)
+		maintenance = hub.NewMaintenanceClient(dynamicClient)
+	})
+
+	Describe("Start", func() {
+		It("should pause the ClusterDeployment and label the ManagedCluster", func() {
+			change, err := maintenance.Start(ctx, "partner-a", "manual network change")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(change).To(Equal(hub.MaintenanceChange{ClusterDeployment: true, ManagedCluster: true}))
+
+			cd, err := dynamicClient.Resource(cdGVR).Namespace("partner-a").Get(ctx, "partner-a", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cd.GetAnnotations()).To(HaveKeyWithValue(hub.ReconcilePauseAnnotation, "true"))
+
+			mc, err := dynamicClient.Resource(mcGVR).Get(ctx, "partner-a", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mc.GetLabels()).To(Equal(map[string]string{"env": "lab", hub.MaintenanceLabel: "true"}))
+			Expect(mc.GetAnnotations()).To(HaveKeyWithValue(hub.MaintenanceReasonAnnotation, "manual network change"))
+		})
+
+		It("should only label the ManagedCluster of imported clusters", func() {
+			change, err := maintenance.Start(ctx, "imported", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(change).To(Equal(hub.MaintenanceChange{ManagedCluster: true}))
+
+			mc, err := dynamicClient.Resource(mcGVR).Get(ctx, "imported", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mc.GetLabels()).To(HaveKeyWithValue(hub.MaintenanceLabel, "true"))
+			Expect(mc.GetAnnotations()).NotTo(HaveKey(hub.MaintenanceReasonAnnotation))
+		})
+
+		It("should resume the ClusterDeployment if the ManagedCluster cannot be labeled", func() {
+			dynamicClient.PrependReactor("patch", "managedclusters", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("etcd unavailable")
+			})
+
+			change, err := maintenance.Start(ctx, "partner-a", "")
+			Expect(err).To(MatchError(ContainSubstring("failed to patch ManagedCluster partner-a: etcd unavailable")))
+			Expect(change).To(Equal(hub.MaintenanceChange{}))
+
+			cd, err := dynamicClient.Resource(cdGVR).Namespace("partner-a").Get(ctx, "partner-a", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cd.GetAnnotations()).NotTo(HaveKey(hub.ReconcilePauseAnnotation))
+		})
+
+		It("should fail for unknown clusters", func() {
+			_, err := maintenance.Start(ctx, "missing", "")
+			Expect(err).To(MatchError(ContainSubstring("cluster missing not found")))
+		})
+	})
+
+	Describe("End", func() {
+		It("should resume the ClusterDeployment and unlabel the ManagedCluster", func() {
+			_, err := maintenance.Start(ctx, "partner-a", "manual network change")
+			Expect(err).NotTo(HaveOccurred())
+
+			change, err := maintenance.End(ctx, "partner-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(change).To(Equal(hub.MaintenanceChange{ClusterDeployment: true, ManagedCluster: true}))
+
+			cd, err := dynamicClient.Resource(cdGVR).Namespace("partner-a").Get(ctx, "partner-a", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cd.GetAnnotations()).NotTo(HaveKey(hub.ReconcilePauseAnnotation))
+
+			mc, err := dynamicClient.Resource(mcGVR).Get(ctx, "partner-a", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mc.GetLabels()).To(Equal(map[string]string{"env": "lab"}))
+			Expect(mc.GetAnnotations()).NotTo(HaveKey(hub.MaintenanceReasonAnnotation))
+		})
+
+		It("should keep the ClusterDeployment paused if the ManagedCluster cannot be unlabeled", func() {
+			_, err := maintenance.Start(ctx, "partner-a", "manual network change")
+			Expect(err).NotTo(HaveOccurred())
+			dynamicClient.PrependReactor("patch", "managedclusters", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("etcd unavailable")
+			})
+
+			_, err = maintenance.End(ctx, "partner-a")
+			Expect(err).To(MatchError(ContainSubstring("etcd unavailable")))
+
+			cd, err := dynamicClient.Resource(cdGVR).Namespace("partner-a").Get(ctx, "partner-a", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cd.GetAnnotations()).To(HaveKeyWithValue(hub.ReconcilePauseAnnotation, "true"))
+		})
+	})
+})
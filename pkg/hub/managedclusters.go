@@ -3,14 +3,27 @@ package hub
 import (
 	"context"
 	"fmt"
+	"net/http"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
 )
 
+// managedClusterGVR identifies OCM ManagedCluster resources
+var managedClusterGVR = schema.GroupVersionResource{
+	Group:    "cluster.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "managedclusters",
+}
+
 const (
 	// UnreachableTaintKey is the taint key for unreachable clusters
 	UnreachableTaintKey = "cluster.open-cluster-management.io/unreachable"
@@ -20,32 +33,42 @@ const (
 type ManagedClusterClient interface {
 	// List retrieves all managed clusters from the hub
 	List(ctx context.Context) ([]ManagedClusterInfo, error)
+	// Get retrieves the full state of a managed cluster
+	Get(ctx context.Context, name string) (*ManagedClusterDetail, error)
+	// Watch streams changes of the managed clusters, starting with the existing ones
+	Watch(ctx context.Context) (<-chan ManagedClusterEvent, error)
 	// Filter filters clusters based on the provided criteria
 	Filter(clusters []ManagedClusterInfo, filter ManagedClusterFilter) []ManagedClusterInfo
 }
 
 type managedClusterClient struct {
 	dynamicClient dynamic.Interface
+	options       kube.Options
 }
 
 // NewManagedClusterClient creates a new ManagedClusterClient
-func NewManagedClusterClient(dynamicClient dynamic.Interface) ManagedClusterClient {
+func NewManagedClusterClient(dynamicClient dynamic.Interface, options ...kube.Option) ManagedClusterClient {
 	return &managedClusterClient{
 		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
 	}
 }
 
 // List retrieves all managed clusters from the hub and returns their information
 func (m *managedClusterClient) List(ctx context.Context) ([]ManagedClusterInfo, error) {
-	// Define the GVR for ManagedCluster
-	gvr := schema.GroupVersionResource{
-		Group:    "cluster.open-cluster-management.io",
-		Version:  "v1",
-		Resource: "managedclusters",
+	ctx, cancel, err := m.options.Start(ctx, "list ManagedClusters")
+	if err != nil {
+		return nil, err
 	}
+	defer cancel()
 
 	// List all ManagedCluster resources
-	unstructuredList, err := m.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+	var unstructuredList *unstructured.UnstructuredList
+	err = m.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		unstructuredList, err = m.dynamicClient.Resource(managedClusterGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list managed clusters: %w", err)
 	}
@@ -53,38 +76,207 @@ func (m *managedClusterClient) List(ctx context.Context) ([]ManagedClusterInfo,
 	var clusters []ManagedClusterInfo
 
 	for _, item := range unstructuredList.Items {
-		// Convert unstructured to ManagedCluster
-		var cluster clusterv1.ManagedCluster
-		err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &cluster)
+		info, err := toManagedClusterInfo(item.Object)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert unstructured to ManagedCluster: %w", err)
+			return nil, err
 		}
+		clusters = append(clusters, info)
+	}
+
+	return clusters, nil
+}
+
+// Get retrieves a ManagedCluster with its conditions, resources, and cluster claims
+func (m *managedClusterClient) Get(ctx context.Context, name string) (*ManagedClusterDetail, error) {
+	ctx, cancel, err := m.options.Start(ctx, "get ManagedCluster", "name", name)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var obj *unstructured.Unstructured
+	err = m.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		obj, err = m.dynamicClient.Resource(managedClusterGVR).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed cluster %s: %w", name, err)
+	}
 
-		// Extract cluster information
-		info := ManagedClusterInfo{
-			Name:   cluster.Name,
-			Status: deriveStatus(&cluster),
+	var cluster clusterv1.ManagedCluster
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &cluster); err != nil {
+		return nil, fmt.Errorf("failed to convert unstructured to ManagedCluster: %w", err)
+	}
+	info, err := toManagedClusterInfo(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &ManagedClusterDetail{
+		ManagedClusterInfo: info,
+		CreatedAt:          cluster.CreationTimestamp.Time,
+		HubAcceptsClient:   cluster.Spec.HubAcceptsClient,
+		KubernetesVersion:  cluster.Status.Version.Kubernetes,
+		Capacity:           resourceQuantities(cluster.Status.Capacity),
+		Allocatable:        resourceQuantities(cluster.Status.Allocatable),
+	}
+	for _, taint := range cluster.Spec.Taints {
+		detail.Taints = append(detail.Taints, fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect))
+	}
+	for _, condition := range cluster.Status.Conditions {
+		detail.Conditions = append(detail.Conditions, ManagedClusterCondition{
+			Type:               condition.Type,
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime.Time,
+		})
+	}
+	for _, claim := range cluster.Status.ClusterClaims {
+		if detail.Claims == nil {
+			detail.Claims = make(map[string]string, len(cluster.Status.ClusterClaims))
 		}
+		detail.Claims[claim.Name] = claim.Value
+	}
+	return detail, nil
+}
 
-		// Get available condition
-		info.Available, info.Message = getAvailableCondition(&cluster)
+// resourceQuantities converts the resources of a ManagedCluster to their quantities as strings
+func resourceQuantities(resources clusterv1.ResourceList) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	quantities := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		quantities[string(name)] = quantity.String()
+	}
+	return quantities
+}
 
-		clusters = append(clusters, info)
+// Watch streams changes of the managed clusters of the hub. Like `kubectl get --watch`, the
+// existing clusters are sent first as Added events. When the server ends the watch it is
+// re-established, which sends the existing clusters again. The channel is closed when ctx is
+// done or after an Error event.
+func (m *managedClusterClient) Watch(ctx context.Context) (<-chan ManagedClusterEvent, error) {
+	// The watch outlives the operation timeout, so only the rate limit and logging of Start apply
+	_, cancel, err := m.options.Start(ctx, "watch ManagedClusters")
+	if err != nil {
+		return nil, err
 	}
+	cancel()
 
-	return clusters, nil
+	resource := m.dynamicClient.Resource(managedClusterGVR)
+	w, err := resource.Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch managed clusters: %w", err)
+	}
+
+	events := make(chan ManagedClusterEvent)
+	go func() {
+		defer close(events)
+		for {
+			if !m.forwardEvents(ctx, w, events) || ctx.Err() != nil {
+				return
+			}
+			w, err = resource.Watch(ctx, metav1.ListOptions{})
+			if err != nil {
+				sendEvent(ctx, events, ManagedClusterEvent{Type: watch.Error, Err: fmt.Errorf("failed to watch managed clusters: %w", err)})
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// forwardEvents converts the events of w until it ends, ctx is done, or it fails. It reports
+// whether the watch ended normally and can be re-established.
+func (m *managedClusterClient) forwardEvents(ctx context.Context, w watch.Interface, events chan<- ManagedClusterEvent) bool {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return true
+			}
+			if e.Type == watch.Error {
+				if status, isStatus := e.Object.(*metav1.Status); isStatus && status.Code == http.StatusGone {
+					// The resource version expired, start over with a fresh watch
+					return true
+				}
+				sendEvent(ctx, events, ManagedClusterEvent{Type: watch.Error, Err: fmt.Errorf("watch of managed clusters failed: %v", e.Object)})
+				return false
+			}
+			if e.Type != watch.Added && e.Type != watch.Modified && e.Type != watch.Deleted {
+				continue
+			}
+			obj, isUnstructured := e.Object.(*unstructured.Unstructured)
+			if !isUnstructured {
+				continue
+			}
+			info, err := toManagedClusterInfo(obj.Object)
+			if err != nil {
+				sendEvent(ctx, events, ManagedClusterEvent{Type: watch.Error, Err: err})
+				return false
+			}
+			if !sendEvent(ctx, events, ManagedClusterEvent{Type: e.Type, Cluster: info}) {
+				return false
+			}
+		}
+	}
+}
+
+// sendEvent sends event unless ctx is done first, reporting whether it was sent
+func sendEvent(ctx context.Context, events chan<- ManagedClusterEvent, event ManagedClusterEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// toManagedClusterInfo extracts the cluster information of an unstructured ManagedCluster
+func toManagedClusterInfo(obj map[string]interface{}) (ManagedClusterInfo, error) {
+	// Convert unstructured to ManagedCluster
+	var cluster clusterv1.ManagedCluster
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &cluster); err != nil {
+		return ManagedClusterInfo{}, fmt.Errorf("failed to convert unstructured to ManagedCluster: %w", err)
+	}
+
+	// Extract cluster information
+	info := ManagedClusterInfo{
+		Name:        cluster.Name,
+		Status:      deriveStatus(&cluster),
+		ClusterSet:  cluster.Labels[ClusterSetLabel],
+		Maintenance: cluster.Labels[MaintenanceLabel] == "true",
+		Labels:      cluster.Labels,
+	}
+
+	// Get available condition
+	info.Available, info.Message = getAvailableCondition(&cluster)
+	info.Joined = meta.IsStatusConditionTrue(cluster.Status.Conditions, clusterv1.ManagedClusterConditionJoined)
+
+	return info, nil
 }
 
 // Filter filters the list of clusters based on the provided filter criteria
 func (m *managedClusterClient) Filter(clusters []ManagedClusterInfo, filter ManagedClusterFilter) []ManagedClusterInfo {
-	// If no status filter is specified, return all clusters
-	if filter.Status == "" {
+	return filterManagedClusters(clusters, filter)
+}
+
+// filterManagedClusters returns the clusters matching filter, or all clusters for the zero filter
+func filterManagedClusters(clusters []ManagedClusterInfo, filter ManagedClusterFilter) []ManagedClusterInfo {
+	// If no filter is specified, return all clusters
+	if filter == (ManagedClusterFilter{}) {
 		return clusters
 	}
 
 	var filtered []ManagedClusterInfo
 	for _, cluster := range clusters {
-		if cluster.Status == filter.Status {
+		if filter.Matches(cluster) {
 			filtered = append(filtered, cluster)
 		}
 	}
@@ -4,10 +4,14 @@ package hub_test
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -15,6 +19,9 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/flowcontrol"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 )
 
@@ -116,7 +123,8 @@ var _ = Describe("ManagedClusterClient", func() {
 			BeforeEach(func() {
 				readyCluster := clusterv1.ManagedCluster{
 					ObjectMeta: metav1.ObjectMeta{
-						Name: "cluster-ready",
+						Name:   "cluster-ready",
+						Labels: map[string]string{"partner": "acme"},
 					},
 					Status: clusterv1.ManagedClusterStatus{
 						Conditions: []metav1.Condition{
@@ -125,6 +133,10 @@ var _ = Describe("ManagedClusterClient", func() {
 								Status:  metav1.ConditionTrue,
 								Message: "Cluster is available",
 							},
+							{
+								Type:   clusterv1.ManagedClusterConditionJoined,
+								Status: metav1.ConditionTrue,
+							},
 						},
 					},
 				}
@@ -177,9 +189,12 @@ var _ = Describe("ManagedClusterClient", func() {
 
 				Expect(clusterMap["cluster-ready"].Status).To(Equal(hub.StatusReady))
 				Expect(clusterMap["cluster-ready"].Available).To(Equal("True"))
+				Expect(clusterMap["cluster-ready"].Joined).To(BeTrue())
+				Expect(clusterMap["cluster-ready"].Labels).To(Equal(map[string]string{"partner": "acme"}))
 
 				Expect(clusterMap["cluster-notready"].Status).To(Equal(hub.StatusNotReady))
 				Expect(clusterMap["cluster-notready"].Available).To(Equal("False"))
+				Expect(clusterMap["cluster-notready"].Joined).To(BeFalse())
 
 				Expect(clusterMap["cluster-unknown"].Status).To(Equal(hub.StatusUnknown))
 				Expect(clusterMap["cluster-unknown"].Available).To(Equal("Unknown"))
@@ -252,8 +267,8 @@ var _ = Describe("ManagedClusterClient", func() {
 
 		BeforeEach(func() {
 			clusters = []hub.ManagedClusterInfo{
-				{Name: "cluster-1", Status: hub.StatusReady, Available: "True"},
-				{Name: "cluster-2", Status: hub.StatusNotReady, Available: "False"},
+				{Name: "cluster-1", Status: hub.StatusReady, Available: "True", ClusterSet: "acme"},
+				{Name: "cluster-2", Status: hub.StatusNotReady, Available: "False", ClusterSet: "acme"},
 				{Name: "cluster-3", Status: hub.StatusReady, Available: "True"},
 				{Name: "cluster-4", Status: hub.StatusUnknown, Available: "Unknown"},
 				{Name: "cluster-5", Status: hub.StatusNotReady, Available: "False"},
@@ -292,6 +307,21 @@ var _ = Describe("ManagedClusterClient", func() {
 			})
 		})
 
+		Context("filtering by cluster set", func() {
+			It("should return only the clusters of the set", func() {
+				filtered := client.Filter(clusters, hub.ManagedClusterFilter{ClusterSet: "acme"})
+				Expect(filtered).To(HaveLen(2))
+				Expect(filtered[0].Name).To(Equal("cluster-1"))
+				Expect(filtered[1].Name).To(Equal("cluster-2"))
+			})
+
+			It("should combine the cluster set with the status", func() {
+				filtered := client.Filter(clusters, hub.ManagedClusterFilter{Status: hub.StatusReady, ClusterSet: "acme"})
+				Expect(filtered).To(HaveLen(1))
+				Expect(filtered[0].Name).To(Equal("cluster-1"))
+			})
+		})
+
 		Context("with empty filter", func() {
 			It("should return all clusters", func() {
 				filter := hub.ManagedClusterFilter{}
@@ -309,3 +339,198 @@ var _ = Describe("ManagedClusterClient", func() {
 		})
 	})
 })
+
+// countingRateLimiter counts the operations that waited for it
+type countingRateLimiter struct {
+	waits int
+}
+
+func (c *countingRateLimiter) TryAccept() bool { return true }
+func (c *countingRateLimiter) Accept()         { c.waits++ }
+func (c *countingRateLimiter) Stop()           {}
+func (c *countingRateLimiter) QPS() float32    { return 0 }
+
+func (c *countingRateLimiter) Wait(context.Context) error {
+	c.waits++
+	return nil
+}
+
+var _ = Describe("ManagedClusterClient options", func() {
+	It("should wait for the rate limiter before listing", func() {
+		limiter := &countingRateLimiter{}
+		client := hub.NewManagedClusterClient(&mockDynamicClient{}, kube.WithRateLimiter(limiter))
+
+		_, err := client.List(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.List(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(limiter.waits).To(Equal(2))
+	})
+
+	It("should not list when the rate limiter fails", func() {
+		client := hub.NewManagedClusterClient(&mockDynamicClient{}, kube.WithRateLimiter(flowcontrol.NewFakeNeverRateLimiter()))
+
+		_, err := client.List(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("can not be accept")))
+	})
+
+	It("should retry a list that fails transiently with WithRetry", func() {
+		gvr := schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		fakeDynamic := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			gvr: "ManagedClusterList",
+		})
+		failures := 2
+		fakeDynamic.PrependReactor("list", "managedclusters", func(k8stesting.Action) (bool, runtime.Object, error) {
+			if failures > 0 {
+				failures--
+				return true, nil, apierrors.NewServiceUnavailable("API server restarting")
+			}
+			return false, nil, nil
+		})
+
+		_, err := hub.NewManagedClusterClient(fakeDynamic).List(context.Background())
+		Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+
+		clusters, err := hub.NewManagedClusterClient(fakeDynamic, kube.WithRetry(3, time.Millisecond)).List(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clusters).To(BeEmpty())
+		Expect(failures).To(BeZero())
+	})
+})
+
+var _ = Describe("ManagedClusterClient Watch", func() {
+	var (
+		ctx         context.Context
+		cancel      context.CancelFunc
+		fakeDynamic *fake.FakeDynamicClient
+		gvr         schema.GroupVersionResource
+	)
+
+	newCluster := func(available metav1.ConditionStatus) *unstructured.Unstructured {
+		cluster := &clusterv1.ManagedCluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "cluster.open-cluster-management.io/v1", Kind: "ManagedCluster"},
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-1"},
+			Status: clusterv1.ManagedClusterStatus{
+				Conditions: []metav1.Condition{{Type: clusterv1.ManagedClusterConditionAvailable, Status: available}},
+			},
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		return &unstructured.Unstructured{Object: obj}
+	}
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		gvr = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		fakeDynamic = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			gvr: "ManagedClusterList",
+		})
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should stream cluster changes with their derived status", func() {
+		events, err := hub.NewManagedClusterClient(fakeDynamic).Watch(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = fakeDynamic.Resource(gvr).Create(ctx, newCluster(metav1.ConditionTrue), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		var event hub.ManagedClusterEvent
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Added))
+		Expect(event.Cluster.Name).To(Equal("cluster-1"))
+		Expect(event.Cluster.Status).To(Equal(hub.StatusReady))
+
+		_, err = fakeDynamic.Resource(gvr).Update(ctx, newCluster(metav1.ConditionFalse), metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Modified))
+		Expect(event.Cluster.Status).To(Equal(hub.StatusNotReady))
+
+		Expect(fakeDynamic.Resource(gvr).Delete(ctx, "cluster-1", metav1.DeleteOptions{})).To(Succeed())
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Deleted))
+	})
+
+	It("should close the channel when the context is done", func() {
+		events, err := hub.NewManagedClusterClient(fakeDynamic).Watch(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		cancel()
+		Eventually(events).Should(BeClosed())
+	})
+})
+
+var _ = Describe("ManagedClusterClient Get", func() {
+	var (
+		ctx         context.Context
+		fakeDynamic *fake.FakeDynamicClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		transition := metav1.NewTime(time.Date(2026, 1, 12, 9, 58, 0, 0, time.UTC))
+		cluster := &clusterv1.ManagedCluster{
+			TypeMeta: metav1.TypeMeta{APIVersion: "cluster.open-cluster-management.io/v1", Kind: "ManagedCluster"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "partner-a",
+				Labels: map[string]string{hub.ClusterSetLabel: "partners", hub.MaintenanceLabel: "true", "env": "prod"},
+			},
+			Spec: clusterv1.ManagedClusterSpec{
+				HubAcceptsClient: true,
+				Taints:           []clusterv1.Taint{{Key: hub.UnreachableTaintKey, Effect: clusterv1.TaintEffectNoSelect}},
+			},
+			Status: clusterv1.ManagedClusterStatus{
+				Conditions: []metav1.Condition{
+					{Type: clusterv1.ManagedClusterConditionJoined, Status: metav1.ConditionTrue, Reason: "ManagedClusterJoined", LastTransitionTime: transition},
+					{Type: clusterv1.ManagedClusterConditionAvailable, Status: metav1.ConditionUnknown, Reason: "ManagedClusterLeaseUpdateStopped", Message: "Registration agent stopped updating its lease.", LastTransitionTime: transition},
+				},
+				Capacity: clusterv1.ResourceList{
+					clusterv1.ResourceCPU:    resource.MustParse("24"),
+					clusterv1.ResourceMemory: resource.MustParse("96Gi"),
+				},
+				Allocatable: clusterv1.ResourceList{clusterv1.ResourceCPU: resource.MustParse("21500m")},
+				Version:     clusterv1.ManagedClusterVersion{Kubernetes: "v1.29.5"},
+				ClusterClaims: []clusterv1.ManagedClusterClaim{
+					{Name: "id.openshift.io", Value: "0f3c5c0e-6d8b-4a55-9a3b-2f1f2f6a1a3e"},
+					{Name: "version.openshift.io", Value: "4.16.3"},
+				},
+			},
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		fakeDynamic = fake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{Object: obj})
+	})
+
+	It("should return the conditions, resources, and claims of the cluster", func() {
+		detail, err := hub.NewManagedClusterClient(fakeDynamic).Get(ctx, "partner-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Name).To(Equal("partner-a"))
+		Expect(detail.Status).To(Equal(hub.StatusNotReady))
+		Expect(detail.ClusterSet).To(Equal("partners"))
+		Expect(detail.Maintenance).To(BeTrue())
+		Expect(detail.Joined).To(BeTrue())
+		Expect(detail.HubAcceptsClient).To(BeTrue())
+		Expect(detail.KubernetesVersion).To(Equal("v1.29.5"))
+		Expect(detail.Taints).To(Equal([]string{hub.UnreachableTaintKey + "=:NoSelect"}))
+		Expect(detail.Conditions).To(HaveLen(2))
+		available := detail.Conditions[1]
+		Expect(available.Type).To(Equal(clusterv1.ManagedClusterConditionAvailable))
+		Expect(available.Status).To(Equal("Unknown"))
+		Expect(available.Reason).To(Equal("ManagedClusterLeaseUpdateStopped"))
+		Expect(available.Message).To(Equal("Registration agent stopped updating its lease."))
+		Expect(available.LastTransitionTime.Equal(time.Date(2026, 1, 12, 9, 58, 0, 0, time.UTC))).To(BeTrue())
+		Expect(detail.Capacity).To(Equal(map[string]string{"cpu": "24", "memory": "96Gi"}))
+		Expect(detail.Allocatable).To(Equal(map[string]string{"cpu": "21500m"}))
+		Expect(detail.Claims).To(HaveKeyWithValue("id.openshift.io", "0f3c5c0e-6d8b-4a55-9a3b-2f1f2f6a1a3e"))
+		Expect(detail.Claims).To(HaveKeyWithValue("version.openshift.io", "4.16.3"))
+	})
+
+	It("should fail for a cluster that does not exist", func() {
+		_, err := hub.NewManagedClusterClient(fakeDynamic).Get(ctx, "partner-z")
+		Expect(err).To(HaveOccurred())
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
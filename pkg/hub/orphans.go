@@ -0,0 +1,118 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// OrphanKind describes how a cluster's hub resources are mismatched
+type OrphanKind string
+
+const (
+	// OrphanClusterDeployment is a ClusterDeployment without a matching ManagedCluster
+	OrphanClusterDeployment OrphanKind = "ClusterDeploymentWithoutManagedCluster"
+	// OrphanManagedCluster is a ManagedCluster without a ClusterDeployment that was never imported
+	OrphanManagedCluster OrphanKind = "ManagedClusterWithoutDeployment"
+)
+
+// Orphan is a hub resource whose counterpart is missing
+type Orphan struct {
+	// Kind describes the mismatch
+	Kind OrphanKind `json:"kind"`
+	// Name is the cluster name
+	Name string `json:"name"`
+	// Namespace is the ClusterDeployment namespace, empty for ManagedClusters
+	Namespace string `json:"namespace,omitempty"`
+	// RequestID is the partner request the ClusterDeployment was provisioned for, if known
+	RequestID string `json:"requestID,omitempty"`
+	// Detail explains the state of the orphaned resource
+	Detail string `json:"detail"`
+}
+
+// OrphanDetector finds mismatches between ManagedClusters and ClusterDeployments
+type OrphanDetector interface {
+	// Detect returns orphaned ClusterDeployments and ManagedClusters, sorted by kind and name
+	Detect(ctx context.Context) ([]Orphan, error)
+}
+
+type orphanDetector struct {
+	managedClusterClient    ManagedClusterClient
+	clusterDeploymentClient ClusterDeploymentClient
+}
+
+// NewOrphanDetector creates a new OrphanDetector
+func NewOrphanDetector(mcClient ManagedClusterClient, cdClient ClusterDeploymentClient) OrphanDetector {
+	return &orphanDetector{
+		managedClusterClient:    mcClient,
+		clusterDeploymentClient: cdClient,
+	}
+}
+
+// Detect lists both resources and matches them by cluster name:
+// 1. A ClusterDeployment without a ManagedCluster is still billed but invisible to ACM
+// 2. A ManagedCluster without a ClusterDeployment is expected for imported clusters, so it
+// is only reported if its klusterlet never joined the hub
+func (d *orphanDetector) Detect(ctx context.Context) ([]Orphan, error) {
+	managedClusters, err := d.managedClusterClient.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed clusters: %w", err)
+	}
+	deployments, err := d.clusterDeploymentClient.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster deployments: %w", err)
+	}
+
+	managed := make(map[string]bool, len(managedClusters))
+	for _, mc := range managedClusters {
+		managed[mc.Name] = true
+	}
+	deployed := make(map[string]bool, len(deployments))
+	for _, cd := range deployments {
+		deployed[cd.Name] = true
+	}
+
+	orphans := make([]Orphan, 0)
+	for _, cd := range deployments {
+		if managed[cd.Name] {
+			continue
+		}
+		detail := fmt.Sprintf("power state %s", valueOrUnknown(cd.PowerState))
+		if !cd.Installed {
+			detail = "installation not complete"
+		}
+		orphans = append(orphans, Orphan{
+			Kind:      OrphanClusterDeployment,
+			Name:      cd.Name,
+			Namespace: cd.Namespace,
+			RequestID: cd.RequestID,
+			Detail:    detail,
+		})
+	}
+	for _, mc := range managedClusters {
+		if deployed[mc.Name] || mc.Joined {
+			continue
+		}
+		orphans = append(orphans, Orphan{
+			Kind:   OrphanManagedCluster,
+			Name:   mc.Name,
+			Detail: fmt.Sprintf("klusterlet never joined (status %s)", mc.Status),
+		})
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Kind != orphans[j].Kind {
+			return orphans[i].Kind < orphans[j].Kind
+		}
+		return orphans[i].Name < orphans[j].Name
+	})
+	return orphans, nil
+}
+
+// valueOrUnknown returns value, or "Unknown" if it is empty
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "Unknown"
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("OrphanDetector", func() {
+	var (
+		mockMCClient *mockManagedClusterClientForCombined
+		mockCDClient *mockClusterDeploymentClientForCombined
+		detector     hub.OrphanDetector
+	)
+
+	BeforeEach(func() {
+		mockMCClient = newMockManagedClusterClientForCombined()
+		mockCDClient = newMockClusterDeploymentClientForCombined()
+		detector = hub.NewOrphanDetector(mockMCClient, mockCDClient)
+	})
+
+	It("should report nothing when every ClusterDeployment has a ManagedCluster", func() {
+		mockMCClient.managedClusters = []hub.ManagedClusterInfo{{Name: "partner-lab", Status: hub.StatusReady, Joined: true}}
+		mockCDClient.clusterDeployments["partner-lab"] = &hub.ClusterDeploymentInfo{Name: "partner-lab", Namespace: "partner-lab", Installed: true}
+
+		orphans, err := detector.Detect(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphans).To(BeEmpty())
+	})
+
+	It("should report ClusterDeployments without a ManagedCluster", func() {
+		mockCDClient.clusterDeployments["leftover"] = &hub.ClusterDeploymentInfo{
+			Name:       "leftover",
+			Namespace:  "leftover",
+			RequestID:  "1234",
+			Installed:  true,
+			PowerState: "Running",
+		}
+		mockCDClient.clusterDeployments["installing"] = &hub.ClusterDeploymentInfo{Name: "installing", Namespace: "installing"}
+
+		orphans, err := detector.Detect(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphans).To(Equal([]hub.Orphan{
+			{Kind: hub.OrphanClusterDeployment, Name: "installing", Namespace: "installing", Detail: "installation not complete"},
+			{Kind: hub.OrphanClusterDeployment, Name: "leftover", Namespace: "leftover", RequestID: "1234", Detail: "power state Running"},
+		}))
+	})
+
+	It("should report ManagedClusters that were neither provisioned nor imported", func() {
+		mockMCClient.managedClusters = []hub.ManagedClusterInfo{
+			{Name: "imported", Status: hub.StatusReady, Joined: true},
+			{Name: "never-joined", Status: hub.StatusUnknown},
+		}
+
+		orphans, err := detector.Detect(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphans).To(Equal([]hub.Orphan{
+			{Kind: hub.OrphanManagedCluster, Name: "never-joined", Detail: "klusterlet never joined (status Unknown)"},
+		}))
+	})
+})
@@ -1,10 +1,17 @@
 package hub
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
+	"slices"
+	"strconv"
 	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/yaml"
 )
 
 // OutputFormat represents the output format type
@@ -15,29 +22,69 @@ const (
 	OutputFormatTable OutputFormat = "table"
 	// OutputFormatJSON represents JSON output format
 	OutputFormatJSON OutputFormat = "json"
+	// OutputFormatYAML represents YAML output format, using the same field names as JSON
+	OutputFormatYAML OutputFormat = "yaml"
+	// OutputFormatCSV represents comma-separated values with a header row, e.g. for spreadsheets
+	OutputFormatCSV OutputFormat = "csv"
+	// OutputFormatTSV represents tab-separated values with a header row
+	OutputFormatTSV OutputFormat = "tsv"
 )
 
+// watchColumnWidth is the minimum column width of watch tables. Rows are written one at a
+// time, so columns are aligned by width instead of by the widest value.
+const watchColumnWidth = 20
+
 // OutputWriter handles formatting and writing cluster information
 type OutputWriter struct {
 	format OutputFormat
 	writer io.Writer
+	// template is the template of the jsonpath and go-template formats
+	template string
+	// watched holds the last written state of each cluster in incremental-row mode
+	watched map[string]ManagedClusterInfo
+	// watchHeader records whether the header of the watch table was written
+	watchHeader bool
+	// sort orders the clusters of Write and WriteCombined
+	sort SortOptions
 }
 
-// NewOutputWriter creates a new OutputWriter with the specified format and writer
+// NewOutputWriter creates a new OutputWriter with the specified format and writer. The
+// template formats take their template after an equals sign, e.g. jsonpath={.items[*].Name}.
 func NewOutputWriter(format OutputFormat, writer io.Writer) *OutputWriter {
+	format, template := ParseOutputFormat(string(format))
 	return &OutputWriter{
-		format: format,
-		writer: writer,
+		format:   format,
+		template: template,
+		writer:   writer,
 	}
 }
 
+// SetSort orders the clusters written by Write and WriteCombined. Watch events are written
+// in the order they happen.
+func (o *OutputWriter) SetSort(opts SortOptions) {
+	o.sort = opts
+}
+
 // Write formats and writes the cluster information according to the configured format
 func (o *OutputWriter) Write(clusters []ManagedClusterInfo) error {
+	if o.sort.Field != "" {
+		clusters = slices.Clone(clusters)
+		if err := SortManagedClusters(clusters, o.sort); err != nil {
+			return err
+		}
+	}
+
 	switch o.format {
 	case OutputFormatTable:
 		return o.writeTable(clusters)
 	case OutputFormatJSON:
 		return o.writeJSON(clusters)
+	case OutputFormatYAML:
+		return o.writeYAML(clusters)
+	case OutputFormatCSV, OutputFormatTSV:
+		return o.writeRecords(managedClusterRecords(clusters))
+	case OutputFormatJSONPath, OutputFormatJSONPathFile, OutputFormatGoTemplate, OutputFormatGoTemplateFile:
+		return WriteTemplate(o.writer, o.format, o.template, clusters)
 	default:
 		return fmt.Errorf("unsupported output format: %s", o.format)
 	}
@@ -48,14 +95,26 @@ func (o *OutputWriter) writeTable(clusters []ManagedClusterInfo) error {
 	// Create tabwriter for column alignment
 	w := tabwriter.NewWriter(o.writer, 0, 0, 3, ' ', 0)
 
+	// Multi-hub results get a leading HUB column
+	hubColumn := false
+	for _, cluster := range clusters {
+		hubColumn = hubColumn || cluster.Hub != ""
+	}
+
 	// Write header
+	if hubColumn {
+		fmt.Fprintf(w, "HUB\t")
+	}
 	fmt.Fprintf(w, "NAME\tSTATUS\tAVAILABLE\n")
 
 	// Write cluster rows
 	for _, cluster := range clusters {
+		if hubColumn {
+			fmt.Fprintf(w, "%s\t", cluster.Hub)
+		}
 		fmt.Fprintf(w, "%s\t%s\t%s\n",
 			cluster.Name,
-			cluster.Status,
+			formatStatus(cluster.Status, cluster.Maintenance),
 			cluster.Available,
 		)
 	}
@@ -90,11 +149,24 @@ func (o *OutputWriter) writeJSON(clusters []ManagedClusterInfo) error {
 // WriteCombined formats and writes combined cluster information according to the configured format
 // The wide parameter controls whether to show additional columns in table format
 func (o *OutputWriter) WriteCombined(clusters []CombinedClusterInfo, wide bool) error {
+	if o.sort.Field != "" {
+		clusters = slices.Clone(clusters)
+		if err := SortCombinedClusters(clusters, o.sort); err != nil {
+			return err
+		}
+	}
+
 	switch o.format {
 	case OutputFormatTable:
 		return o.writeCombinedTable(clusters, wide)
 	case OutputFormatJSON:
 		return o.writeCombinedJSON(clusters)
+	case OutputFormatYAML:
+		return o.writeYAML(clusters)
+	case OutputFormatCSV, OutputFormatTSV:
+		return o.writeRecords(combinedClusterRecords(clusters))
+	case OutputFormatJSONPath, OutputFormatJSONPathFile, OutputFormatGoTemplate, OutputFormatGoTemplateFile:
+		return WriteTemplate(o.writer, o.format, o.template, clusters)
 	default:
 		return fmt.Errorf("unsupported output format: %s", o.format)
 	}
@@ -105,29 +177,44 @@ func (o *OutputWriter) writeCombinedTable(clusters []CombinedClusterInfo, wide b
 	// Create tabwriter for column alignment
 	w := tabwriter.NewWriter(o.writer, 0, 0, 3, ' ', 0)
 
+	// Multi-hub results get a leading HUB column
+	hubColumn := false
+	for _, cluster := range clusters {
+		hubColumn = hubColumn || cluster.Hub != ""
+	}
+
 	// Write header based on wide flag
+	if hubColumn {
+		fmt.Fprintf(w, "HUB\t")
+	}
 	if wide {
-		fmt.Fprintf(w, "NAME\tSTATUS\tPOWER\tPLATFORM\tREGION\tVERSION\tAVAILABLE\n")
+		fmt.Fprintf(w, "NAME\tSTATUS\tPOWER\tPLATFORM\tREGION\tVERSION\tNODES\tKUBERNETES\tAVAILABLE\tCONSOLE\n")
 	} else {
 		fmt.Fprintf(w, "NAME\tSTATUS\tAVAILABLE\n")
 	}
 
 	// Write cluster rows
 	for _, cluster := range clusters {
+		if hubColumn {
+			fmt.Fprintf(w, "%s\t", cluster.Hub)
+		}
 		if wide {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 				cluster.Name,
-				cluster.Status,
+				formatStatus(cluster.Status, cluster.Maintenance),
 				cluster.PowerState,
 				cluster.Platform,
 				cluster.Region,
 				cluster.Version,
+				formatNodeCount(cluster.NodeCount),
+				valueOrNA(cluster.KubernetesVersion),
 				cluster.Available,
+				valueOrNA(cluster.ConsoleURL),
 			)
 		} else {
 			fmt.Fprintf(w, "%s\t%s\t%s\n",
 				cluster.Name,
-				cluster.Status,
+				formatStatus(cluster.Status, cluster.Maintenance),
 				cluster.Available,
 			)
 		}
@@ -137,6 +224,31 @@ func (o *OutputWriter) writeCombinedTable(clusters []CombinedClusterInfo, wide b
 	return w.Flush()
 }
 
+// formatStatus renders the status of a cluster in table output, marking clusters in
+// maintenance like kubectl marks cordoned nodes, e.g. Ready,Maintenance
+func formatStatus(status ClusterStatus, maintenance bool) string {
+	if maintenance {
+		return string(status) + ",Maintenance"
+	}
+	return string(status)
+}
+
+// formatNodeCount renders a node count, using N/A when no nodes were reported
+func formatNodeCount(count int) string {
+	if count == 0 {
+		return "N/A"
+	}
+	return strconv.Itoa(count)
+}
+
+// valueOrNA renders an empty value as N/A in table output
+func valueOrNA(value string) string {
+	if value == "" {
+		return "N/A"
+	}
+	return value
+}
+
 // writeCombinedJSON writes combined cluster information in JSON format
 func (o *OutputWriter) writeCombinedJSON(clusters []CombinedClusterInfo) error {
 	// Use MarshalIndent for pretty-printed JSON with 2-space indentation
@@ -159,3 +271,159 @@ func (o *OutputWriter) writeCombinedJSON(clusters []CombinedClusterInfo) error {
 
 	return nil
 }
+
+// writeYAML writes cluster information in YAML format
+func (o *OutputWriter) writeYAML(clusters interface{}) error {
+	data, err := yaml.Marshal(clusters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal clusters to YAML: %w", err)
+	}
+
+	if _, err := o.writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write YAML output: %w", err)
+	}
+	return nil
+}
+
+// writeRecords writes a header row and one row per cluster as CSV or TSV. Fields containing the
+// separator, quotes, or line breaks are quoted.
+func (o *OutputWriter) writeRecords(records [][]string) error {
+	w := csv.NewWriter(o.writer)
+	if o.format == OutputFormatTSV {
+		w.Comma = '\t'
+	}
+	if err := w.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write %s output: %w", o.format, err)
+	}
+	return nil
+}
+
+// managedClusterRecords converts clusters to a header and rows, using the JSON field names as
+// column names. Multi-hub results get a leading Hub column.
+func managedClusterRecords(clusters []ManagedClusterInfo) [][]string {
+	hubColumn := false
+	for _, cluster := range clusters {
+		hubColumn = hubColumn || cluster.Hub != ""
+	}
+
+	header := []string{"Name", "Status", "Available", "Joined", "Message"}
+	if hubColumn {
+		header = append([]string{"Hub"}, header...)
+	}
+	records := [][]string{header}
+	for _, cluster := range clusters {
+		row := []string{cluster.Name, string(cluster.Status), cluster.Available, strconv.FormatBool(cluster.Joined), cluster.Message}
+		if hubColumn {
+			row = append([]string{cluster.Hub}, row...)
+		}
+		records = append(records, row)
+	}
+	return records
+}
+
+// combinedClusterRecords converts combined clusters to a header and rows with every field,
+// using the JSON field names as column names. Multi-hub results get a leading Hub column.
+func combinedClusterRecords(clusters []CombinedClusterInfo) [][]string {
+	hubColumn := false
+	for _, cluster := range clusters {
+		hubColumn = hubColumn || cluster.Hub != ""
+	}
+
+	header := []string{
+		"Name", "Status", "PowerState", "Platform", "Region", "Version", "NodeCount", "KubernetesVersion",
+		"Cloud", "Available", "APIUrl", "ConsoleURL", "CloudConsoleURL", "KubeconfigSecret", "Message",
+	}
+	if hubColumn {
+		header = append([]string{"Hub"}, header...)
+	}
+	records := [][]string{header}
+	for _, cluster := range clusters {
+		row := []string{
+			cluster.Name,
+			string(cluster.Status),
+			cluster.PowerState,
+			cluster.Platform,
+			cluster.Region,
+			cluster.Version,
+			strconv.Itoa(cluster.NodeCount),
+			cluster.KubernetesVersion,
+			cluster.Cloud,
+			cluster.Available,
+			cluster.APIUrl,
+			cluster.ConsoleURL,
+			cluster.CloudConsoleURL,
+			cluster.KubeconfigSecret,
+			cluster.Message,
+		}
+		if hubColumn {
+			row = append([]string{cluster.Hub}, row...)
+		}
+		records = append(records, row)
+	}
+	return records
+}
+
+// sameRow reports whether two states of a cluster are written the same; labels are not written
+func sameRow(a, b ManagedClusterInfo) bool {
+	a.Labels, b.Labels = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// WriteEvent writes a watch event in incremental-row mode: tables get one row per event, with
+// the header before the first row, JSON gets one object per line, and YAML one document per
+// event. Events that do not change a cluster since its last written row are skipped, so a
+// re-established watch does not repeat the clusters.
+func (o *OutputWriter) WriteEvent(event ManagedClusterEvent) error {
+	if event.Type == watch.Error {
+		return fmt.Errorf("cannot write %s event: %w", event.Type, event.Err)
+	}
+
+	last, seen := o.watched[event.Cluster.Name]
+	if event.Type != watch.Deleted && seen && sameRow(last, event.Cluster) {
+		return nil
+	}
+	if o.watched == nil {
+		o.watched = make(map[string]ManagedClusterInfo)
+	}
+	if event.Type == watch.Deleted {
+		delete(o.watched, event.Cluster.Name)
+	} else {
+		o.watched[event.Cluster.Name] = event.Cluster
+	}
+
+	switch o.format {
+	case OutputFormatTable:
+		w := tabwriter.NewWriter(o.writer, watchColumnWidth, 0, 3, ' ', 0)
+		if !o.watchHeader {
+			fmt.Fprintf(w, "EVENT\tNAME\tSTATUS\tAVAILABLE\n")
+			o.watchHeader = true
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			event.Type,
+			event.Cluster.Name,
+			formatStatus(event.Cluster.Status, event.Cluster.Maintenance),
+			event.Cluster.Available,
+		)
+		return w.Flush()
+	case OutputFormatJSON:
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event to JSON: %w", err)
+		}
+		if _, err := fmt.Fprintln(o.writer, string(data)); err != nil {
+			return fmt.Errorf("failed to write JSON output: %w", err)
+		}
+		return nil
+	case OutputFormatYAML:
+		data, err := yaml.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event to YAML: %w", err)
+		}
+		if _, err := fmt.Fprintf(o.writer, "---\n%s", data); err != nil {
+			return fmt.Errorf("failed to write YAML output: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format: %s", o.format)
+	}
+}
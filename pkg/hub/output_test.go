@@ -4,12 +4,16 @@ package hub_test
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/yaml"
 )
 
 var _ = Describe("OutputWriter", func() {
@@ -78,6 +82,41 @@ var _ = Describe("OutputWriter", func() {
 			})
 		})
 
+		Context("with clusters from multiple hubs", func() {
+			It("should add a leading HUB column", func() {
+				err := writer.Write([]hub.ManagedClusterInfo{
+					{Name: "cluster-east-1", Status: hub.StatusReady, Available: "True", Hub: "production"},
+					{Name: "cluster-test", Status: hub.StatusReady, Available: "True", Hub: "staging"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+				Expect(lines).To(HaveLen(3))
+				Expect(strings.Fields(lines[0])).To(Equal([]string{"HUB", "NAME", "STATUS", "AVAILABLE"}))
+				Expect(strings.Fields(lines[2])).To(Equal([]string{"staging", "cluster-test", "Ready", "True"}))
+			})
+
+			It("should not add a HUB column for single-hub results", func() {
+				err := writer.Write(clusters)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).NotTo(ContainSubstring("HUB"))
+			})
+		})
+
+		Context("with clusters in maintenance", func() {
+			It("should mark the status of the clusters in maintenance", func() {
+				err := writer.Write([]hub.ManagedClusterInfo{
+					{Name: "cluster-east-1", Status: hub.StatusReady, Available: "True", Maintenance: true},
+					{Name: "cluster-west-1", Status: hub.StatusReady, Available: "True"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+				Expect(strings.Fields(lines[1])).To(Equal([]string{"cluster-east-1", "Ready,Maintenance", "True"}))
+				Expect(strings.Fields(lines[2])).To(Equal([]string{"cluster-west-1", "Ready", "True"}))
+			})
+		})
+
 		Context("with empty cluster list", func() {
 			It("should display only headers", func() {
 				err := writer.Write([]hub.ManagedClusterInfo{})
@@ -213,6 +252,155 @@ var _ = Describe("OutputWriter", func() {
 		})
 	})
 
+	Describe("YAML Output", func() {
+		BeforeEach(func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatYAML, buffer)
+		})
+
+		It("should format clusters as YAML with the JSON field names", func() {
+			err := writer.Write(clusters)
+			Expect(err).NotTo(HaveOccurred())
+
+			var result []hub.ManagedClusterInfo
+			err = yaml.Unmarshal(buffer.Bytes(), &result)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(clusters))
+			Expect(buffer.String()).To(HavePrefix("- "))
+		})
+
+		It("should format an empty cluster list as an empty YAML list", func() {
+			err := writer.Write([]hub.ManagedClusterInfo{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.TrimSpace(buffer.String())).To(Equal("[]"))
+		})
+
+		It("should format combined clusters as YAML", func() {
+			combined := []hub.CombinedClusterInfo{
+				{Name: "cluster-east-1", Status: hub.StatusReady, Available: "True", PowerState: "Running", Platform: "AWS"},
+			}
+			err := writer.WriteCombined(combined, true)
+			Expect(err).NotTo(HaveOccurred())
+
+			var result []hub.CombinedClusterInfo
+			err = yaml.Unmarshal(buffer.Bytes(), &result)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(combined))
+		})
+	})
+
+	Describe("CSV and TSV Output", func() {
+		It("should write a header row and quote fields with separators and quotes", func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatCSV, buffer)
+			clusters[0].Message = `lease "renewed", healthy`
+
+			err := writer.Write(clusters)
+			Expect(err).NotTo(HaveOccurred())
+
+			records, err := csv.NewReader(buffer).ReadAll()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(HaveLen(4))
+			Expect(records[0]).To(Equal([]string{"Name", "Status", "Available", "Joined", "Message"}))
+			Expect(records[1]).To(Equal([]string{"cluster-east-1", "Ready", "True", "false", `lease "renewed", healthy`}))
+		})
+
+		It("should write tab-separated values", func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatTSV, buffer)
+
+			err := writer.Write(clusters[:1])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.String()).To(Equal("Name\tStatus\tAvailable\tJoined\tMessage\ncluster-east-1\tReady\tTrue\tfalse\tCluster is healthy\n"))
+		})
+
+		It("should write only the header for an empty cluster list", func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatCSV, buffer)
+
+			err := writer.Write([]hub.ManagedClusterInfo{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.String()).To(Equal("Name,Status,Available,Joined,Message\n"))
+		})
+
+		It("should write every field of combined clusters with a Hub column for multi-hub results", func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatCSV, buffer)
+			combined := []hub.CombinedClusterInfo{
+				{Name: "cluster-east-1", Status: hub.StatusReady, PowerState: "Running", Platform: "aws", Region: "us-east-1",
+					Version: "4.16.12", NodeCount: 6, Available: "True", ConsoleURL: "https://console.example.com", Hub: "hub-a"},
+			}
+
+			err := writer.WriteCombined(combined, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			records, err := csv.NewReader(buffer).ReadAll()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(HaveLen(2))
+			Expect(records[0][:8]).To(Equal([]string{"Hub", "Name", "Status", "PowerState", "Platform", "Region", "Version", "NodeCount"}))
+			Expect(records[1][:8]).To(Equal([]string{"hub-a", "cluster-east-1", "Ready", "Running", "aws", "us-east-1", "4.16.12", "6"}))
+			Expect(records[1]).To(ContainElement("https://console.example.com"))
+		})
+	})
+
+	Describe("WriteEvent", func() {
+		var (
+			ready    hub.ManagedClusterInfo
+			notReady hub.ManagedClusterInfo
+		)
+
+		BeforeEach(func() {
+			ready = hub.ManagedClusterInfo{Name: "cluster-east-1", Status: hub.StatusReady, Available: "True"}
+			notReady = hub.ManagedClusterInfo{Name: "cluster-east-1", Status: hub.StatusNotReady, Available: "False"}
+		})
+
+		It("should write a table header once and one row per change", func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatTable, buffer)
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Added, Cluster: ready})).To(Succeed())
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Modified, Cluster: notReady})).To(Succeed())
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Deleted, Cluster: notReady})).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+			Expect(lines).To(HaveLen(4))
+			Expect(lines[0]).To(HavePrefix("EVENT"))
+			Expect(strings.Fields(lines[1])).To(Equal([]string{"ADDED", "cluster-east-1", "Ready", "True"}))
+			Expect(strings.Fields(lines[2])).To(Equal([]string{"MODIFIED", "cluster-east-1", "NotReady", "False"}))
+			Expect(strings.Fields(lines[3])).To(Equal([]string{"DELETED", "cluster-east-1", "NotReady", "False"}))
+			Expect(strings.Index(lines[1], "Ready")).To(Equal(strings.Index(lines[2], "NotReady")))
+		})
+
+		It("should skip events that do not change a cluster", func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatTable, buffer)
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Added, Cluster: ready})).To(Succeed())
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Added, Cluster: ready})).To(Succeed())
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Modified, Cluster: ready})).To(Succeed())
+
+			Expect(strings.Split(strings.TrimSpace(buffer.String()), "\n")).To(HaveLen(2))
+		})
+
+		It("should write one JSON object per line", func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatJSON, buffer)
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Added, Cluster: ready})).To(Succeed())
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Modified, Cluster: notReady})).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			var event hub.ManagedClusterEvent
+			Expect(json.Unmarshal([]byte(lines[1]), &event)).To(Succeed())
+			Expect(event.Type).To(Equal(watch.Modified))
+			Expect(event.Cluster).To(Equal(notReady))
+		})
+
+		It("should write one YAML document per event", func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatYAML, buffer)
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Added, Cluster: ready})).To(Succeed())
+			Expect(writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Modified, Cluster: notReady})).To(Succeed())
+
+			Expect(strings.Count(buffer.String(), "---\n")).To(Equal(2))
+		})
+
+		It("should reject error events", func() {
+			writer = hub.NewOutputWriter(hub.OutputFormatTable, buffer)
+			err := writer.WriteEvent(hub.ManagedClusterEvent{Type: watch.Error, Err: errors.New("connection lost")})
+			Expect(err).To(MatchError(ContainSubstring("connection lost")))
+		})
+	})
+
 	Describe("NewOutputWriter", func() {
 		It("should create a writer with table format", func() {
 			writer := hub.NewOutputWriter(hub.OutputFormatTable, buffer)
@@ -318,7 +506,10 @@ var _ = Describe("OutputWriter", func() {
 				Expect(lines[0]).To(ContainSubstring("PLATFORM"))
 				Expect(lines[0]).To(ContainSubstring("REGION"))
 				Expect(lines[0]).To(ContainSubstring("VERSION"))
+				Expect(lines[0]).To(ContainSubstring("NODES"))
+				Expect(lines[0]).To(ContainSubstring("KUBERNETES"))
 				Expect(lines[0]).To(ContainSubstring("AVAILABLE"))
+				Expect(lines[0]).To(ContainSubstring("CONSOLE"))
 
 				// Check that all clusters are present
 				Expect(output).To(ContainSubstring("cluster-east-1"))
@@ -347,6 +538,18 @@ var _ = Describe("OutputWriter", func() {
 				Expect(strings.Contains(output, "N/A")).To(BeTrue())
 			})
 
+			It("should add a leading HUB column for multi-hub results", func() {
+				for i := range combinedClusters {
+					combinedClusters[i].Hub = "production"
+				}
+				err := writer.WriteCombined(combinedClusters, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+				Expect(strings.Fields(lines[0])[0]).To(Equal("HUB"))
+				Expect(strings.Fields(lines[1])[:2]).To(Equal([]string{"production", "cluster-east-1"}))
+			})
+
 			It("should align columns properly in wide mode", func() {
 				err := writer.WriteCombined(combinedClusters, true)
 				Expect(err).NotTo(HaveOccurred())
@@ -0,0 +1,180 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// Compliance states reported by the ACM governance framework
+const (
+	// ComplianceCompliant indicates the cluster satisfies the policy
+	ComplianceCompliant = "Compliant"
+	// ComplianceNonCompliant indicates the cluster violates the policy
+	ComplianceNonCompliant = "NonCompliant"
+	// CompliancePending indicates the policy waits for a dependency before it is evaluated
+	CompliancePending = "Pending"
+	// ComplianceUnknown indicates the policy has not been evaluated on the cluster yet
+	ComplianceUnknown = "Unknown"
+)
+
+// RootPolicyLabel is set on the copies of a policy the policy propagator replicates into
+// cluster namespaces, pointing to the root policy as <namespace>.<name>
+const RootPolicyLabel = "policy.open-cluster-management.io/root-policy"
+
+// policyGVR identifies ACM governance Policy resources
+var policyGVR = schema.GroupVersionResource{
+	Group:    "policy.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "policies",
+}
+
+// PolicyInfo contains the state of an ACM Policy across the clusters it is placed on
+type PolicyInfo struct {
+	// Name is the name of the root policy
+	Name string
+	// Namespace is the namespace of the root policy on the hub
+	Namespace string
+	// RemediationAction is inform or enforce
+	RemediationAction string
+	// Disabled indicates the policy is not propagated to clusters
+	Disabled bool
+	// Compliant is the aggregated compliance of the policy, NonCompliant if any cluster violates it
+	Compliant string
+	// Clusters is the compliance of the policy per cluster, sorted by cluster name
+	Clusters []PolicyClusterStatus
+}
+
+// PolicyClusterStatus is the compliance of a policy on one cluster
+type PolicyClusterStatus struct {
+	// Cluster is the name of the managed cluster
+	Cluster string
+	// Compliant is Compliant, NonCompliant, Pending, or Unknown
+	Compliant string
+}
+
+// PolicyFilter defines criteria for filtering policies
+type PolicyFilter struct {
+	// Cluster restricts policies to their status on this cluster
+	Cluster string
+	// NonCompliantOnly restricts policies to the clusters violating them
+	NonCompliantOnly bool
+}
+
+// PolicyClient provides operations for ACM governance policies
+type PolicyClient interface {
+	// List retrieves the root policies of the hub, sorted by namespace and name
+	List(ctx context.Context) ([]PolicyInfo, error)
+	// Filter restricts the per-cluster statuses of policies to those matching filter and drops
+	// policies without a matching cluster
+	Filter(policies []PolicyInfo, filter PolicyFilter) []PolicyInfo
+}
+
+type policyClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewPolicyClient creates a new PolicyClient
+func NewPolicyClient(dynamicClient dynamic.Interface, options ...kube.Option) PolicyClient {
+	return &policyClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// List lists the Policies in all namespaces, skipping the copies replicated into cluster
+// namespaces, as their status is already aggregated on the root policy
+func (c *policyClient) List(ctx context.Context) ([]PolicyInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list Policies")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	list, err := c.dynamicClient.Resource(policyGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Policies: %w", err)
+	}
+
+	policies := make([]PolicyInfo, 0, len(list.Items))
+	for _, item := range list.Items {
+		if _, replicated := item.GetLabels()[RootPolicyLabel]; replicated {
+			continue
+		}
+		policies = append(policies, parsePolicy(&item))
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].Namespace != policies[j].Namespace {
+			return policies[i].Namespace < policies[j].Namespace
+		}
+		return policies[i].Name < policies[j].Name
+	})
+	return policies, nil
+}
+
+// Filter filters the per-cluster statuses of each policy
+func (c *policyClient) Filter(policies []PolicyInfo, filter PolicyFilter) []PolicyInfo {
+	if filter.Cluster == "" && !filter.NonCompliantOnly {
+		return policies
+	}
+
+	var filtered []PolicyInfo
+	for _, policy := range policies {
+		var clusters []PolicyClusterStatus
+		for _, status := range policy.Clusters {
+			if filter.Cluster != "" && status.Cluster != filter.Cluster {
+				continue
+			}
+			if filter.NonCompliantOnly && status.Compliant != ComplianceNonCompliant {
+				continue
+			}
+			clusters = append(clusters, status)
+		}
+		if len(clusters) > 0 {
+			policy.Clusters = clusters
+			filtered = append(filtered, policy)
+		}
+	}
+	return filtered
+}
+
+// parsePolicy extracts PolicyInfo from an unstructured root Policy
+func parsePolicy(obj *unstructured.Unstructured) PolicyInfo {
+	info := PolicyInfo{
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Compliant: ComplianceUnknown,
+		Clusters:  []PolicyClusterStatus{},
+	}
+	info.RemediationAction, _, _ = unstructured.NestedString(obj.Object, "spec", "remediationAction")
+	info.Disabled, _, _ = unstructured.NestedBool(obj.Object, "spec", "disabled")
+	if compliant, _, _ := unstructured.NestedString(obj.Object, "status", "compliant"); compliant != "" {
+		info.Compliant = compliant
+	}
+
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "status")
+	for _, item := range items {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		status := PolicyClusterStatus{Compliant: ComplianceUnknown}
+		status.Cluster, _ = entry["clustername"].(string)
+		if compliant, _ := entry["compliant"].(string); compliant != "" {
+			status.Compliant = compliant
+		}
+		if status.Cluster != "" {
+			info.Clusters = append(info.Clusters, status)
+		}
+	}
+	sort.Slice(info.Clusters, func(i, j int) bool { return info.Clusters[i].Cluster < info.Clusters[j].Cluster })
+	return info
+}
@@ -0,0 +1,115 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("PolicyClient", func() {
+	var (
+		ctx       context.Context
+		policies  hub.PolicyClient
+		policy    func(namespace, name string, labels map[string]interface{}, spec, status map[string]interface{}) *unstructured.Unstructured
+		clusterOf func(cluster, compliant string) interface{}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		policy = func(namespace, name string, labels map[string]interface{}, spec, status map[string]interface{}) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "policy.open-cluster-management.io/v1",
+				"kind":       "Policy",
+				"metadata":   map[string]interface{}{"name": name, "namespace": namespace, "labels": labels},
+				"spec":       spec,
+				"status":     status,
+			}}
+		}
+		clusterOf = func(cluster, compliant string) interface{} {
+			return map[string]interface{}{"clustername": cluster, "clusternamespace": cluster, "compliant": compliant}
+		}
+
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				{Group: "policy.open-cluster-management.io", Version: "v1", Resource: "policies"}: "PolicyList",
+			},
+			policy("policies", "etcd-encryption", nil,
+				map[string]interface{}{"remediationAction": "inform"},
+				map[string]interface{}{
+					"compliant": "NonCompliant",
+					"status":    []interface{}{clusterOf("cluster-b", "NonCompliant"), clusterOf("cluster-a", "Compliant")},
+				}),
+			policy("cluster-a", "policies.etcd-encryption", map[string]interface{}{hub.RootPolicyLabel: "policies.etcd-encryption"},
+				map[string]interface{}{"remediationAction": "inform"},
+				map[string]interface{}{"compliant": "Compliant"}),
+			policy("acm-policies", "oauth", nil,
+				map[string]interface{}{"remediationAction": "enforce", "disabled": true},
+				map[string]interface{}{"status": []interface{}{clusterOf("cluster-a", "")}}),
+		)
+		policies = hub.NewPolicyClient(dynamicClient)
+	})
+
+	Describe("List", func() {
+		It("should list root policies with their per-cluster compliance", func() {
+			result, err := policies.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal([]hub.PolicyInfo{
+				{
+					Name:              "oauth",
+					Namespace:         "acm-policies",
+					RemediationAction: "enforce",
+					Disabled:          true,
+					Compliant:         hub.ComplianceUnknown,
+					Clusters:          []hub.PolicyClusterStatus{{Cluster: "cluster-a", Compliant: hub.ComplianceUnknown}},
+				},
+				{
+					Name:              "etcd-encryption",
+					Namespace:         "policies",
+					RemediationAction: "inform",
+					Compliant:         hub.ComplianceNonCompliant,
+					Clusters: []hub.PolicyClusterStatus{
+						{Cluster: "cluster-a", Compliant: hub.ComplianceCompliant},
+						{Cluster: "cluster-b", Compliant: hub.ComplianceNonCompliant},
+					},
+				},
+			}))
+		})
+	})
+
+	Describe("Filter", func() {
+		It("should restrict policies to a cluster", func() {
+			result, err := policies.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			filtered := policies.Filter(result, hub.PolicyFilter{Cluster: "cluster-b"})
+			Expect(filtered).To(HaveLen(1))
+			Expect(filtered[0].Name).To(Equal("etcd-encryption"))
+			Expect(filtered[0].Clusters).To(Equal([]hub.PolicyClusterStatus{{Cluster: "cluster-b", Compliant: hub.ComplianceNonCompliant}}))
+		})
+
+		It("should restrict policies to non-compliant clusters", func() {
+			result, err := policies.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(policies.Filter(result, hub.PolicyFilter{Cluster: "cluster-a", NonCompliantOnly: true})).To(BeEmpty())
+			filtered := policies.Filter(result, hub.PolicyFilter{NonCompliantOnly: true})
+			Expect(filtered).To(HaveLen(1))
+			Expect(filtered[0].Clusters).To(HaveLen(1))
+			Expect(filtered[0].Clusters[0].Cluster).To(Equal("cluster-b"))
+		})
+
+		It("should return every policy without criteria", func() {
+			result, err := policies.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policies.Filter(result, hub.PolicyFilter{})).To(Equal(result))
+		})
+	})
+})
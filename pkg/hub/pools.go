@@ -0,0 +1,341 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// PartnerAnnotation records the partner a ClusterClaim was made for
+const PartnerAnnotation = "labrat.openshift-partner-labs.io/partner"
+
+// Claim states reported by ClaimInfo.Status
+const (
+	// ClaimPending means Hive has not assigned a cluster to the claim yet
+	ClaimPending = "Pending"
+	// ClaimAssigned means a cluster is assigned but not running yet, e.g. while it resumes
+	ClaimAssigned = "Assigned"
+	// ClaimRunning means the claimed cluster is running and ready to hand over
+	ClaimRunning = "Running"
+)
+
+var (
+	// clusterPoolGVR identifies Hive ClusterPool resources
+	clusterPoolGVR = schema.GroupVersionResource{
+		Group:    "hive.openshift.io",
+		Version:  "v1",
+		Resource: "clusterpools",
+	}
+	// clusterClaimGVR identifies Hive ClusterClaim resources
+	clusterClaimGVR = schema.GroupVersionResource{
+		Group:    "hive.openshift.io",
+		Version:  "v1",
+		Resource: "clusterclaims",
+	}
+
+	// invalidNameChars matches the characters a partner name cannot contribute to a claim name
+	invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// ClusterPoolInfo contains the size and state of a Hive ClusterPool
+type ClusterPoolInfo struct {
+	// Name is the name of the pool
+	Name string
+	// Namespace is the namespace of the pool on the hub
+	Namespace string
+	// Size is the number of unclaimed clusters the pool keeps
+	Size int64
+	// MaxSize caps the clusters of the pool, claimed or not, 0 if unlimited
+	MaxSize int64
+	// Ready is the number of unclaimed clusters ready to be claimed
+	Ready int64
+	// Standby is the number of unclaimed clusters still installing
+	Standby int64
+	// ImageSet is the ClusterImageSet the clusters of the pool are installed from
+	ImageSet string
+	// Platform is the cloud platform of the pool (aws, azure, gcp, ...)
+	Platform string
+	// Region is the cloud region of the pool
+	Region string
+}
+
+// ClaimInfo contains the state of a Hive ClusterClaim
+type ClaimInfo struct {
+	// Name is the name of the claim
+	Name string
+	// Namespace is the namespace of the claim, which is the namespace of its pool
+	Namespace string
+	// Pool is the name of the ClusterPool the claim is made against
+	Pool string
+	// Partner is the partner the claim was made for
+	Partner string
+	// Cluster is the namespace of the ClusterDeployment assigned to the claim, empty while pending
+	Cluster string
+	// Status is Pending, Assigned, or Running
+	Status string
+	// Lifetime is how long the claimed cluster lives before Hive deletes it, empty if unlimited
+	Lifetime string
+	// CreatedAt is when the claim was made
+	CreatedAt time.Time
+}
+
+// ClaimRequest describes a ClusterClaim to create
+type ClaimRequest struct {
+	// Pool is the name of the ClusterPool to claim from
+	Pool string
+	// Namespace is the namespace of the pool; if empty the pool is looked up by name
+	Namespace string
+	// Partner is the partner the cluster is claimed for
+	Partner string
+	// Lifetime is how long the claimed cluster lives before Hive deletes it, 0 for unlimited
+	Lifetime time.Duration
+}
+
+// ClusterPoolClient provides operations for Hive ClusterPools and ClusterClaims
+type ClusterPoolClient interface {
+	// List retrieves the ClusterPools of the hub, sorted by namespace and name
+	List(ctx context.Context) ([]ClusterPoolInfo, error)
+	// ListClaims retrieves the ClusterClaims of the hub, sorted by namespace and name
+	ListClaims(ctx context.Context) ([]ClaimInfo, error)
+	// Claim creates a ClusterClaim against a pool and returns it
+	Claim(ctx context.Context, request ClaimRequest) (*ClaimInfo, error)
+	// Release deletes a ClusterClaim, after which Hive deletes the claimed cluster. If namespace
+	// is empty the claim is looked up by name.
+	Release(ctx context.Context, claim, namespace string) error
+}
+
+type clusterPoolClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewClusterPoolClient creates a new ClusterPoolClient
+func NewClusterPoolClient(dynamicClient dynamic.Interface, options ...kube.Option) ClusterPoolClient {
+	return &clusterPoolClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// List lists the ClusterPools in all namespaces
+func (c *clusterPoolClient) List(ctx context.Context) ([]ClusterPoolInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list ClusterPools")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = c.dynamicClient.Resource(clusterPoolGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterPools: %w", err)
+	}
+
+	pools := make([]ClusterPoolInfo, 0, len(list.Items))
+	for i := range list.Items {
+		pools = append(pools, parseClusterPool(&list.Items[i]))
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		if pools[i].Namespace != pools[j].Namespace {
+			return pools[i].Namespace < pools[j].Namespace
+		}
+		return pools[i].Name < pools[j].Name
+	})
+	return pools, nil
+}
+
+// ListClaims lists the ClusterClaims in all namespaces
+func (c *clusterPoolClient) ListClaims(ctx context.Context) ([]ClaimInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "list ClusterClaims")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = c.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = c.dynamicClient.Resource(clusterClaimGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterClaims: %w", err)
+	}
+
+	claims := make([]ClaimInfo, 0, len(list.Items))
+	for i := range list.Items {
+		claims = append(claims, parseClusterClaim(&list.Items[i]))
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		if claims[i].Namespace != claims[j].Namespace {
+			return claims[i].Namespace < claims[j].Namespace
+		}
+		return claims[i].Name < claims[j].Name
+	})
+	return claims, nil
+}
+
+// Claim creates a ClusterClaim named after the pool and partner in the namespace of the pool
+func (c *clusterPoolClient) Claim(ctx context.Context, request ClaimRequest) (*ClaimInfo, error) {
+	ctx, cancel, err := c.options.Start(ctx, "claim cluster", "pool", request.Pool, "partner", request.Partner)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	if request.Partner == "" {
+		return nil, fmt.Errorf("partner is required to claim a cluster")
+	}
+
+	namespace := request.Namespace
+	if namespace == "" {
+		pools, err := c.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, pool := range pools {
+			if pool.Name != request.Pool {
+				continue
+			}
+			if namespace != "" {
+				return nil, fmt.Errorf("ClusterPool %s exists in namespaces %s and %s, select one with a namespace", request.Pool, namespace, pool.Namespace)
+			}
+			namespace = pool.Namespace
+		}
+		if namespace == "" {
+			return nil, fmt.Errorf("ClusterPool %s not found", request.Pool)
+		}
+	}
+
+	spec := map[string]interface{}{"clusterPoolName": request.Pool}
+	if request.Lifetime > 0 {
+		spec["lifetime"] = request.Lifetime.String()
+	}
+	claim := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "hive.openshift.io/v1",
+		"kind":       "ClusterClaim",
+		"metadata": map[string]interface{}{
+			"name":        claimName(request.Pool, request.Partner),
+			"namespace":   namespace,
+			"annotations": map[string]interface{}{PartnerAnnotation: request.Partner},
+		},
+		"spec": spec,
+	}}
+
+	created, err := c.dynamicClient.Resource(clusterClaimGVR).Namespace(namespace).Create(ctx, claim, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ClusterClaim for pool %s: %w", request.Pool, err)
+	}
+	info := parseClusterClaim(created)
+	return &info, nil
+}
+
+// Release deletes the ClusterClaim
+func (c *clusterPoolClient) Release(ctx context.Context, claim, namespace string) error {
+	ctx, cancel, err := c.options.Start(ctx, "release cluster", "claim", claim)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if namespace == "" {
+		claims, err := c.ListClaims(ctx)
+		if err != nil {
+			return err
+		}
+		for _, info := range claims {
+			if info.Name != claim {
+				continue
+			}
+			if namespace != "" {
+				return fmt.Errorf("ClusterClaim %s exists in namespaces %s and %s, select one with a namespace", claim, namespace, info.Namespace)
+			}
+			namespace = info.Namespace
+		}
+		if namespace == "" {
+			return fmt.Errorf("ClusterClaim %s not found", claim)
+		}
+	}
+
+	if err := c.dynamicClient.Resource(clusterClaimGVR).Namespace(namespace).Delete(ctx, claim, metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("failed to delete ClusterClaim %s/%s: %w", namespace, claim, err)
+	}
+	return nil
+}
+
+// claimName returns a unique claim name made of the pool, the partner reduced to the characters
+// valid in names, and a random suffix
+func claimName(pool, partner string) string {
+	name := pool
+	if sanitized := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(partner), "-"), "-"); sanitized != "" {
+		name += "-" + sanitized
+	}
+	return name + "-" + utilrand.String(5)
+}
+
+// parseClusterPool extracts ClusterPoolInfo from an unstructured ClusterPool
+func parseClusterPool(obj *unstructured.Unstructured) ClusterPoolInfo {
+	info := ClusterPoolInfo{
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+	}
+	info.Size, _, _ = unstructured.NestedInt64(obj.Object, "spec", "size")
+	info.MaxSize, _, _ = unstructured.NestedInt64(obj.Object, "spec", "maxSize")
+	info.Ready, _, _ = unstructured.NestedInt64(obj.Object, "status", "ready")
+	info.Standby, _, _ = unstructured.NestedInt64(obj.Object, "status", "standby")
+	info.ImageSet, _, _ = unstructured.NestedString(obj.Object, "spec", "imageSetRef", "name")
+
+	// spec.platform has a single key naming the platform, e.g. {"aws": {"region": "us-east-1"}}
+	platforms, _, _ := unstructured.NestedMap(obj.Object, "spec", "platform")
+	for platform, config := range platforms {
+		info.Platform = platform
+		if config, ok := config.(map[string]interface{}); ok {
+			info.Region, _, _ = unstructured.NestedString(config, "region")
+		}
+	}
+	return info
+}
+
+// parseClusterClaim extracts ClaimInfo from an unstructured ClusterClaim
+func parseClusterClaim(obj *unstructured.Unstructured) ClaimInfo {
+	info := ClaimInfo{
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Partner:   obj.GetAnnotations()[PartnerAnnotation],
+		Status:    ClaimPending,
+		CreatedAt: obj.GetCreationTimestamp().Time,
+	}
+	info.Pool, _, _ = unstructured.NestedString(obj.Object, "spec", "clusterPoolName")
+	info.Cluster, _, _ = unstructured.NestedString(obj.Object, "spec", "namespace")
+	info.Lifetime, _, _ = unstructured.NestedString(obj.Object, "spec", "lifetime")
+
+	if info.Cluster != "" {
+		info.Status = ClaimAssigned
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "ClusterRunning" && condition["status"] == "True" {
+			info.Status = ClaimRunning
+		}
+	}
+	return info
+}
@@ -0,0 +1,169 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ClusterPoolClient", func() {
+	var (
+		ctx           context.Context
+		dynamicClient *fake.FakeDynamicClient
+		pools         hub.ClusterPoolClient
+		claimGVR      = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterclaims"}
+	)
+
+	pool := func(namespace, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterPool",
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+			"spec": map[string]interface{}{
+				"size":        int64(3),
+				"maxSize":     int64(10),
+				"imageSetRef": map[string]interface{}{"name": "img4.16.10-x86-64"},
+				"platform":    map[string]interface{}{"aws": map[string]interface{}{"region": "us-east-2"}},
+			},
+			"status": map[string]interface{}{"ready": int64(2), "standby": int64(1)},
+		}}
+	}
+
+	claim := func(namespace, name, poolName string, spec map[string]interface{}, conditions ...interface{}) *unstructured.Unstructured {
+		spec["clusterPoolName"] = poolName
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterClaim",
+			"metadata": map[string]interface{}{
+				"name":        name,
+				"namespace":   namespace,
+				"annotations": map[string]interface{}{hub.PartnerAnnotation: "Acme Corp"},
+			},
+			"spec":   spec,
+			"status": map[string]interface{}{"conditions": conditions},
+		}}
+	}
+
+	newClient := func(objects ...runtime.Object) {
+		dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				{Group: "hive.openshift.io", Version: "v1", Resource: "clusterpools"}: "ClusterPoolList",
+				claimGVR: "ClusterClaimList",
+			}, objects...)
+		pools = hub.NewClusterPoolClient(dynamicClient)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		newClient(pool("pools", "aws-small"), pool("labs", "aws-large"))
+	})
+
+	Describe("List", func() {
+		It("should list pools with their size, state, image set, and platform", func() {
+			list, err := pools.List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(list).To(HaveLen(2))
+			Expect(list[0].Namespace).To(Equal("labs"))
+			Expect(list[1]).To(Equal(hub.ClusterPoolInfo{
+				Name:      "aws-small",
+				Namespace: "pools",
+				Size:      3,
+				MaxSize:   10,
+				Ready:     2,
+				Standby:   1,
+				ImageSet:  "img4.16.10-x86-64",
+				Platform:  "aws",
+				Region:    "us-east-2",
+			}))
+		})
+	})
+
+	Describe("ListClaims", func() {
+		It("should report pending, assigned, and running claims", func() {
+			newClient(
+				claim("pools", "aws-small-acme-a", "aws-small", map[string]interface{}{}),
+				claim("pools", "aws-small-acme-b", "aws-small", map[string]interface{}{"namespace": "aws-small-x7k2p"}),
+				claim("pools", "aws-small-acme-c", "aws-small", map[string]interface{}{"namespace": "aws-small-q9w4z", "lifetime": "168h0m0s"},
+					map[string]interface{}{"type": "ClusterRunning", "status": "True"}),
+			)
+
+			claims, err := pools.ListClaims(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(claims).To(HaveLen(3))
+			Expect(claims[0].Status).To(Equal(hub.ClaimPending))
+			Expect(claims[0].Partner).To(Equal("Acme Corp"))
+			Expect(claims[0].Pool).To(Equal("aws-small"))
+			Expect(claims[1].Status).To(Equal(hub.ClaimAssigned))
+			Expect(claims[1].Cluster).To(Equal("aws-small-x7k2p"))
+			Expect(claims[2].Status).To(Equal(hub.ClaimRunning))
+			Expect(claims[2].Lifetime).To(Equal("168h0m0s"))
+		})
+	})
+
+	Describe("Claim", func() {
+		It("should create a claim in the namespace of the pool", func() {
+			info, err := pools.Claim(ctx, hub.ClaimRequest{Pool: "aws-small", Partner: "Acme Corp", Lifetime: 7 * 24 * time.Hour})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(info.Name).To(MatchRegexp(`^aws-small-acme-corp-[a-z0-9]{5}$`))
+			Expect(info.Namespace).To(Equal("pools"))
+			Expect(info.Status).To(Equal(hub.ClaimPending))
+
+			created, err := dynamicClient.Resource(claimGVR).Namespace("pools").Get(ctx, info.Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.GetAnnotations()).To(HaveKeyWithValue(hub.PartnerAnnotation, "Acme Corp"))
+			Expect(created.Object["spec"]).To(Equal(map[string]interface{}{"clusterPoolName": "aws-small", "lifetime": "168h0m0s"}))
+		})
+
+		It("should use the given namespace without looking up the pool", func() {
+			info, err := pools.Claim(ctx, hub.ClaimRequest{Pool: "gcp", Namespace: "other", Partner: "acme"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Namespace).To(Equal("other"))
+		})
+
+		It("should fail for unknown and ambiguous pools", func() {
+			_, err := pools.Claim(ctx, hub.ClaimRequest{Pool: "missing", Partner: "acme"})
+			Expect(err).To(MatchError("ClusterPool missing not found"))
+
+			newClient(pool("pools", "aws-small"), pool("labs", "aws-small"))
+			_, err = pools.Claim(ctx, hub.ClaimRequest{Pool: "aws-small", Partner: "acme"})
+			Expect(err).To(MatchError(ContainSubstring("select one with a namespace")))
+		})
+
+		It("should require a partner", func() {
+			_, err := pools.Claim(ctx, hub.ClaimRequest{Pool: "aws-small"})
+			Expect(err).To(MatchError(ContainSubstring("partner is required")))
+		})
+	})
+
+	Describe("Release", func() {
+		BeforeEach(func() {
+			newClient(claim("pools", "aws-small-acme-a", "aws-small", map[string]interface{}{}))
+		})
+
+		It("should delete the claim looked up by name", func() {
+			Expect(pools.Release(ctx, "aws-small-acme-a", "")).To(Succeed())
+
+			claims, err := pools.ListClaims(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims).To(BeEmpty())
+		})
+
+		It("should fail for unknown claims", func() {
+			Expect(pools.Release(ctx, "missing", "")).To(MatchError("ClusterClaim missing not found"))
+			Expect(pools.Release(ctx, "missing", "pools")).To(MatchError(ContainSubstring("failed to delete ClusterClaim pools/missing")))
+		})
+	})
+})
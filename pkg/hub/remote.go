@@ -0,0 +1,370 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// RemoteWatchInterval is how often the managed clusters of a remote backend are listed to
+// stream their changes, as the labrat API has no watch of them
+const RemoteWatchInterval = 30 * time.Second
+
+// remoteBackend is a Backend of the clusters served by the API of labrat serve
+type remoteBackend struct {
+	endpoint   string
+	token      string
+	httpClient *http.Client
+	options    kube.Options
+}
+
+// NewRemoteBackend creates a Backend reading the clusters of the hub served by labrat serve at
+// endpoint, e.g. https://labrat.example.com, authenticating with the bearer token. A nil
+// httpClient uses http.DefaultClient.
+//
+// The API serves the combined clusters only, so the other clients derive their data from
+// them: ClusterDeployments are the clusters with a power state, without the fields the API
+// does not serve, and ManagedClusterClient.Watch polls every RemoteWatchInterval.
+// ClusterDeploymentClient.FindByRequestID is not supported.
+func NewRemoteBackend(endpoint, token string, httpClient *http.Client, options ...kube.Option) Backend {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &remoteBackend{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		token:      token,
+		httpClient: httpClient,
+		options:    kube.NewOptions(options...),
+	}
+}
+
+func (b *remoteBackend) ManagedClusters() ManagedClusterClient {
+	return &remoteManagedClusterClient{backend: b}
+}
+
+func (b *remoteBackend) ClusterDeployments() ClusterDeploymentClient {
+	return &remoteClusterDeploymentClient{backend: b}
+}
+
+func (b *remoteBackend) ClusterInfos() ClusterInfoClient {
+	return &remoteClusterInfoClient{backend: b}
+}
+
+func (b *remoteBackend) Clusters() CombinedClusterClient {
+	return b
+}
+
+// ListCombined lists the clusters of the hub from the API
+func (b *remoteBackend) ListCombined(ctx context.Context) ([]CombinedClusterInfo, error) {
+	ctx, cancel, err := b.options.Start(ctx, "list clusters", "endpoint", b.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var clusters []CombinedClusterInfo
+	err = b.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		clusters, err = b.listClusters(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters from %s: %w", b.endpoint, err)
+	}
+	return clusters, nil
+}
+
+// listClusters requests the clusters matching query from the API once
+func (b *remoteBackend) listClusters(ctx context.Context, query url.Values) ([]CombinedClusterInfo, error) {
+	endpoint := b.endpoint + "/api/v1/clusters"
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("labrat API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var clusters []CombinedClusterInfo
+	if err := json.NewDecoder(resp.Body).Decode(&clusters); err != nil {
+		return nil, fmt.Errorf("failed to decode clusters: %w", err)
+	}
+	return clusters, nil
+}
+
+// findCluster returns the combined cluster named name, or a NotFound error of resource. Only
+// that cluster is requested; servers predating the name filter return them all.
+func (b *remoteBackend) findCluster(ctx context.Context, name string, resource schema.GroupResource) (*CombinedClusterInfo, error) {
+	ctx, cancel, err := b.options.Start(ctx, "get cluster", "endpoint", b.endpoint, "cluster", name)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var clusters []CombinedClusterInfo
+	err = b.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		clusters, err = b.listClusters(ctx, url.Values{"name": {name}})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster from %s: %w", b.endpoint, err)
+	}
+	for i := range clusters {
+		if clusters[i].Name == name {
+			return &clusters[i], nil
+		}
+	}
+	return nil, apierrors.NewNotFound(resource, name)
+}
+
+// remoteManagedClusterClient is the ManagedClusterClient of a remoteBackend
+type remoteManagedClusterClient struct {
+	backend *remoteBackend
+}
+
+// List returns the managed clusters of the API
+func (c *remoteManagedClusterClient) List(ctx context.Context) ([]ManagedClusterInfo, error) {
+	combined, err := c.backend.ListCombined(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clusters := make([]ManagedClusterInfo, 0, len(combined))
+	for _, cluster := range combined {
+		clusters = append(clusters, remoteManagedCluster(cluster))
+	}
+	return clusters, nil
+}
+
+// Get returns the fields of a managed cluster the API serves; conditions, taints, and
+// resources are not served
+func (c *remoteManagedClusterClient) Get(ctx context.Context, name string) (*ManagedClusterDetail, error) {
+	cluster, err := c.backend.findCluster(ctx, name, managedClusterGVR.GroupResource())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed cluster %s: %w", name, err)
+	}
+	return &ManagedClusterDetail{
+		ManagedClusterInfo: remoteManagedCluster(*cluster),
+		KubernetesVersion:  cluster.KubernetesVersion,
+	}, nil
+}
+
+// Watch lists the clusters every RemoteWatchInterval and streams the differences, starting with the
+// existing clusters as Added events. The channel is closed when ctx is done or after an
+// Error event.
+func (c *remoteManagedClusterClient) Watch(ctx context.Context) (<-chan ManagedClusterEvent, error) {
+	clusters, err := c.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch managed clusters: %w", err)
+	}
+
+	events := make(chan ManagedClusterEvent)
+	go func() {
+		defer close(events)
+		known := make(map[string]ManagedClusterInfo, len(clusters))
+		for _, cluster := range clusters {
+			known[cluster.Name] = cluster
+			if !sendEvent(ctx, events, ManagedClusterEvent{Type: watch.Added, Cluster: cluster}) {
+				return
+			}
+		}
+
+		ticker := time.NewTicker(RemoteWatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			clusters, err := c.List(ctx)
+			if err != nil {
+				sendEvent(ctx, events, ManagedClusterEvent{Type: watch.Error, Err: err})
+				return
+			}
+			listed := make(map[string]bool, len(clusters))
+			for _, cluster := range clusters {
+				listed[cluster.Name] = true
+				previous, ok := known[cluster.Name]
+				known[cluster.Name] = cluster
+				event := ManagedClusterEvent{Type: watch.Added, Cluster: cluster}
+				if ok {
+					if reflect.DeepEqual(previous, cluster) {
+						continue
+					}
+					event.Type = watch.Modified
+				}
+				if !sendEvent(ctx, events, event) {
+					return
+				}
+			}
+			for name, cluster := range known {
+				if listed[name] {
+					continue
+				}
+				delete(known, name)
+				if !sendEvent(ctx, events, ManagedClusterEvent{Type: watch.Deleted, Cluster: cluster}) {
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// Filter filters the list of clusters based on the provided filter criteria
+func (c *remoteManagedClusterClient) Filter(clusters []ManagedClusterInfo, filter ManagedClusterFilter) []ManagedClusterInfo {
+	return filterManagedClusters(clusters, filter)
+}
+
+// remoteManagedCluster returns the ManagedCluster fields of a combined cluster. The API does
+// not serve the Joined condition, so clusters that report availability count as joined.
+func remoteManagedCluster(cluster CombinedClusterInfo) ManagedClusterInfo {
+	return ManagedClusterInfo{
+		Name:        cluster.Name,
+		Status:      cluster.Status,
+		Available:   cluster.Available,
+		Message:     cluster.Message,
+		Joined:      cluster.Available != "",
+		ClusterSet:  cluster.ClusterSet,
+		Maintenance: cluster.Maintenance,
+		Hub:         cluster.Hub,
+	}
+}
+
+// remoteClusterDeploymentClient is the ClusterDeploymentClient of a remoteBackend
+type remoteClusterDeploymentClient struct {
+	backend *remoteBackend
+}
+
+// Get returns the ClusterDeployment of a cluster, NotFound for clusters without one
+func (c *remoteClusterDeploymentClient) Get(ctx context.Context, name string) (*ClusterDeploymentInfo, error) {
+	cluster, err := c.backend.findCluster(ctx, name, clusterDeploymentGVR.GroupResource())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster deployment %s: %w", name, err)
+	}
+	deployment, ok := remoteClusterDeployment(*cluster)
+	if !ok {
+		return nil, fmt.Errorf("failed to get cluster deployment %s: %w", name, apierrors.NewNotFound(clusterDeploymentGVR.GroupResource(), name))
+	}
+	return deployment, nil
+}
+
+// List returns the ClusterDeployments of the clusters of the API
+func (c *remoteClusterDeploymentClient) List(ctx context.Context) ([]ClusterDeploymentInfo, error) {
+	combined, err := c.backend.ListCombined(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var deployments []ClusterDeploymentInfo
+	for _, cluster := range combined {
+		if deployment, ok := remoteClusterDeployment(cluster); ok {
+			deployments = append(deployments, *deployment)
+		}
+	}
+	return deployments, nil
+}
+
+// FindByRequestID is not supported, as the API does not serve the request IDs of clusters
+func (c *remoteClusterDeploymentClient) FindByRequestID(_ context.Context, requestID string) (*ClusterDeploymentInfo, error) {
+	return nil, fmt.Errorf("failed to find cluster deployment of request %s: the labrat API does not serve request IDs", requestID)
+}
+
+// remoteClusterDeployment returns the ClusterDeployment fields of a combined cluster, and
+// false for clusters without a ClusterDeployment, whose power state is N/A, or Unknown if it
+// could not be read
+func remoteClusterDeployment(cluster CombinedClusterInfo) (*ClusterDeploymentInfo, bool) {
+	if cluster.PowerState == "N/A" || cluster.PowerState == "Unknown" {
+		return nil, false
+	}
+	deployment := &ClusterDeploymentInfo{
+		Name:       cluster.Name,
+		Namespace:  cluster.Name,
+		PowerState: cluster.PowerState,
+		Installed:  cluster.APIUrl != "",
+		APIUrl:     cluster.APIUrl,
+		ConsoleURL: cluster.ConsoleURL,
+		Platform:   cluster.Platform,
+		Region:     cluster.Region,
+		Version:    cluster.Version,
+	}
+	if namespace, name, ok := strings.Cut(cluster.KubeconfigSecret, "/"); ok {
+		deployment.KubeconfigSecretNS = namespace
+		deployment.KubeconfigSecretName = name
+	}
+	return deployment, true
+}
+
+// remoteClusterInfoClient is the ClusterInfoClient of a remoteBackend
+type remoteClusterInfoClient struct {
+	backend *remoteBackend
+}
+
+// Get returns the ManagedClusterInfo of a cluster, NotFound for clusters that report none
+func (c *remoteClusterInfoClient) Get(ctx context.Context, name string) (*ClusterAgentInfo, error) {
+	cluster, err := c.backend.findCluster(ctx, name, managedClusterInfoGVR.GroupResource())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed cluster info %s: %w", name, err)
+	}
+	info, ok := remoteClusterInfo(*cluster)
+	if !ok {
+		return nil, fmt.Errorf("failed to get managed cluster info %s: %w", name, apierrors.NewNotFound(managedClusterInfoGVR.GroupResource(), name))
+	}
+	return info, nil
+}
+
+// List returns the ManagedClusterInfos of the clusters of the API, sorted by name
+func (c *remoteClusterInfoClient) List(ctx context.Context) ([]ClusterAgentInfo, error) {
+	combined, err := c.backend.ListCombined(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var infos []ClusterAgentInfo
+	for _, cluster := range combined {
+		if info, ok := remoteClusterInfo(cluster); ok {
+			infos = append(infos, *info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// remoteClusterInfo returns the ManagedClusterInfo fields of a combined cluster, and false
+// for clusters whose klusterlet reported none
+func remoteClusterInfo(cluster CombinedClusterInfo) (*ClusterAgentInfo, bool) {
+	if cluster.NodeCount == 0 && cluster.KubernetesVersion == "" {
+		return nil, false
+	}
+	return &ClusterAgentInfo{
+		Name:              cluster.Name,
+		NodeCount:         cluster.NodeCount,
+		KubernetesVersion: cluster.KubernetesVersion,
+		CloudVendor:       cluster.Cloud,
+		ConsoleURL:        cluster.ConsoleURL,
+	}, true
+}
@@ -0,0 +1,133 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("NewRemoteBackend", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		backend  hub.Backend
+		clusters []hub.CombinedClusterInfo
+		queries  []string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clusters = []hub.CombinedClusterInfo{
+			{
+				Name: "partner-a", Status: hub.StatusReady, Available: "True", PowerState: "Running",
+				Platform: "aws", Region: "us-east-1", Version: "4.16.3", APIUrl: "https://api.partner-a.example.com:6443",
+				KubeconfigSecret: "partner-a/partner-a-admin-kubeconfig", NodeCount: 6, KubernetesVersion: "v1.29.5", Cloud: "Amazon",
+			},
+			{Name: "imported", Status: hub.StatusUnknown, PowerState: "N/A", Platform: "N/A", ClusterSet: "partners"},
+		}
+		queries = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+			Expect(r.URL.Path).To(Equal("/api/v1/clusters"))
+			queries = append(queries, r.URL.RawQuery)
+			matching := []hub.CombinedClusterInfo{}
+			for _, cluster := range clusters {
+				if name := r.URL.Query().Get("name"); name == "" || cluster.Name == name {
+					matching = append(matching, cluster)
+				}
+			}
+			Expect(json.NewEncoder(w).Encode(matching)).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+		backend = hub.NewRemoteBackend(server.URL+"/", "secret", nil)
+	})
+
+	It("should list the combined clusters of the API", func() {
+		combined, err := backend.Clusters().ListCombined(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(combined).To(Equal(clusters))
+	})
+
+	It("should derive the managed clusters", func() {
+		managed, err := backend.ManagedClusters().List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(managed).To(Equal([]hub.ManagedClusterInfo{
+			{Name: "partner-a", Status: hub.StatusReady, Available: "True", Joined: true},
+			{Name: "imported", Status: hub.StatusUnknown, ClusterSet: "partners"},
+		}))
+
+		detail, err := backend.ManagedClusters().Get(ctx, "partner-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.KubernetesVersion).To(Equal("v1.29.5"))
+
+		_, err = backend.ManagedClusters().Get(ctx, "missing")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should request only the cluster it gets", func() {
+		_, err := backend.ClusterDeployments().Get(ctx, "partner-a")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = backend.ClusterInfos().Get(ctx, "partner-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queries).To(Equal([]string{"name=partner-a", "name=partner-a"}))
+	})
+
+	It("should derive the cluster deployments of the clusters with a power state", func() {
+		deployments, err := backend.ClusterDeployments().List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployments).To(HaveLen(1))
+		Expect(deployments[0].Name).To(Equal("partner-a"))
+		Expect(deployments[0].Installed).To(BeTrue())
+		Expect(deployments[0].KubeconfigSecretNS).To(Equal("partner-a"))
+		Expect(deployments[0].KubeconfigSecretName).To(Equal("partner-a-admin-kubeconfig"))
+
+		_, err = backend.ClusterDeployments().Get(ctx, "imported")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		_, err = backend.ClusterDeployments().FindByRequestID(ctx, "1234")
+		Expect(err).To(MatchError(ContainSubstring("does not serve request IDs")))
+	})
+
+	It("should derive the cluster infos of the clusters reporting them", func() {
+		infos, err := backend.ClusterInfos().List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(infos).To(Equal([]hub.ClusterAgentInfo{{Name: "partner-a", NodeCount: 6, KubernetesVersion: "v1.29.5", CloudVendor: "Amazon"}}))
+	})
+
+	It("should start a watch with the existing clusters", func() {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events, err := backend.ManagedClusters().Watch(watchCtx)
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		for range clusters {
+			event := <-events
+			Expect(event.Type).To(Equal(watch.Added))
+			names = append(names, event.Cluster.Name)
+		}
+		Expect(names).To(Equal([]string{"partner-a", "imported"}))
+
+		cancel()
+		Eventually(events).Should(BeClosed())
+	})
+
+	It("should report the errors of the API", func() {
+		backend = hub.NewRemoteBackend(server.URL, "wrong", nil)
+		_, err := backend.Clusters().ListCombined(ctx)
+		Expect(err).To(MatchError(ContainSubstring("labrat API returned 401 Unauthorized: invalid token")))
+	})
+})
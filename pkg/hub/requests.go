@@ -0,0 +1,198 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// RequestIDAnnotation records the partner request on resources created for it
+	RequestIDAnnotation = "labrat.openshift-partner-labs.io/request-id"
+	// RequestIndexConfigMap is the hub ConfigMap mapping request IDs to cluster names
+	RequestIndexConfigMap = "labrat-request-index"
+)
+
+// RequestIndex persists the mapping between partner request IDs and cluster names
+type RequestIndex interface {
+	// Record maps a request ID to a cluster name
+	Record(ctx context.Context, requestID, clusterName string) error
+	// Lookup returns the cluster name recorded for a request ID, or "" if there is none
+	Lookup(ctx context.Context, requestID string) (string, error)
+}
+
+type requestIndex struct {
+	coreClient corev1client.CoreV1Interface
+	namespace  string
+	options    kube.Options
+}
+
+// NewRequestIndex creates a new RequestIndex stored in a ConfigMap in the hub namespace
+func NewRequestIndex(coreClient corev1client.CoreV1Interface, namespace string, options ...kube.Option) RequestIndex {
+	return &requestIndex{
+		coreClient: coreClient,
+		namespace:  namespace,
+		options:    kube.NewOptions(options...),
+	}
+}
+
+// Record maps a request ID to a cluster name, creating the index ConfigMap if needed
+func (r *requestIndex) Record(ctx context.Context, requestID, clusterName string) error {
+	ctx, cancel, err := r.options.Start(ctx, "record request", "requestID", requestID, "cluster", clusterName)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMaps := r.coreClient.ConfigMaps(r.namespace)
+
+		cm, err := configMaps.Get(ctx, RequestIndexConfigMap, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      RequestIndexConfigMap,
+					Namespace: r.namespace,
+					Labels:    map[string]string{"app.kubernetes.io/managed-by": "labrat"},
+				},
+				Data: map[string]string{requestID: clusterName},
+			}, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// Created concurrently; retry the update path
+				return apierrors.NewConflict(corev1.Resource("configmaps"), RequestIndexConfigMap, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if cm.Data[requestID] == clusterName {
+			return nil
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[requestID] = clusterName
+
+		_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record request %s in %s/%s: %w", requestID, r.namespace, RequestIndexConfigMap, err)
+	}
+	return nil
+}
+
+// Lookup returns the cluster name recorded for a request ID
+func (r *requestIndex) Lookup(ctx context.Context, requestID string) (string, error) {
+	ctx, cancel, err := r.options.Start(ctx, "look up request", "requestID", requestID)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	cm, err := r.coreClient.ConfigMaps(r.namespace).Get(ctx, RequestIndexConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s/%s: %w", r.namespace, RequestIndexConfigMap, err)
+	}
+	return cm.Data[requestID], nil
+}
+
+// RequestInfo describes the cluster provisioned for a partner request
+type RequestInfo struct {
+	RequestID  string `json:"requestID"`
+	Cluster    string `json:"cluster"`
+	Status     string `json:"status"`
+	PowerState string `json:"powerState"`
+	Installed  bool   `json:"installed"`
+	APIURL     string `json:"apiURL,omitempty"`
+	ConsoleURL string `json:"consoleURL,omitempty"`
+}
+
+// RequestResolver finds the cluster provisioned for a partner request
+type RequestResolver interface {
+	// Resolve returns the cluster for a request ID
+	Resolve(ctx context.Context, requestID string) (*RequestInfo, error)
+}
+
+type requestResolver struct {
+	index    RequestIndex
+	cdClient ClusterDeploymentClient
+	mcClient ManagedClusterClient
+}
+
+// NewRequestResolver creates a new RequestResolver
+func NewRequestResolver(index RequestIndex, cdClient ClusterDeploymentClient, mcClient ManagedClusterClient) RequestResolver {
+	return &requestResolver{
+		index:    index,
+		cdClient: cdClient,
+		mcClient: mcClient,
+	}
+}
+
+// Resolve finds the cluster for a request ID:
+// 1. Look up the cluster name in the request index
+// 2. Fall back to the ClusterDeployment request-id label, repairing the index if it matches
+// 3. Combine the ClusterDeployment with the ManagedCluster status
+func (r *requestResolver) Resolve(ctx context.Context, requestID string) (*RequestInfo, error) {
+	clusterName, err := r.index.Lookup(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var cd *ClusterDeploymentInfo
+	if clusterName != "" {
+		cd, err = r.cdClient.Get(ctx, clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("request %s is indexed to cluster %s: %w", requestID, clusterName, err)
+		}
+	} else {
+		cd, err = r.cdClient.FindByRequestID(ctx, requestID)
+		if err != nil {
+			return nil, err
+		}
+		if cd == nil {
+			return nil, fmt.Errorf("no cluster found for request %s", requestID)
+		}
+		if err := r.index.Record(ctx, requestID, cd.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	info := &RequestInfo{
+		RequestID:  requestID,
+		Cluster:    cd.Name,
+		Status:     "Provisioning",
+		PowerState: cd.PowerState,
+		Installed:  cd.Installed,
+		APIURL:     cd.APIUrl,
+		ConsoleURL: cd.ConsoleURL,
+	}
+
+	if cd.Installed {
+		info.Status = "NotImported"
+		clusters, err := r.mcClient.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, mc := range clusters {
+			if mc.Name == cd.Name {
+				info.Status = string(mc.Status)
+				break
+			}
+		}
+	}
+
+	return info, nil
+}
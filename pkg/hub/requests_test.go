@@ -0,0 +1,130 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("RequestIndex", func() {
+	var (
+		ctx       context.Context
+		clientset *fake.Clientset
+		index     hub.RequestIndex
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clientset = fake.NewSimpleClientset()
+		index = hub.NewRequestIndex(clientset.CoreV1(), "open-cluster-management")
+	})
+
+	It("should return an empty name before anything is recorded", func() {
+		name, err := index.Lookup(ctx, "1234")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(BeEmpty())
+	})
+
+	It("should create the index ConfigMap on first record", func() {
+		Expect(index.Record(ctx, "1234", "partner-lab")).To(Succeed())
+
+		cm, err := clientset.CoreV1().ConfigMaps("open-cluster-management").Get(ctx, hub.RequestIndexConfigMap, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Data).To(HaveKeyWithValue("1234", "partner-lab"))
+	})
+
+	It("should add and update entries in an existing index", func() {
+		Expect(index.Record(ctx, "1234", "partner-lab")).To(Succeed())
+		Expect(index.Record(ctx, "5678", "other-lab")).To(Succeed())
+		Expect(index.Record(ctx, "1234", "partner-lab-2")).To(Succeed())
+
+		name, err := index.Lookup(ctx, "1234")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("partner-lab-2"))
+
+		name, err = index.Lookup(ctx, "5678")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("other-lab"))
+	})
+})
+
+var _ = Describe("RequestResolver", func() {
+	var (
+		ctx          context.Context
+		index        hub.RequestIndex
+		mockMCClient *mockManagedClusterClientForCombined
+		mockCDClient *mockClusterDeploymentClientForCombined
+		resolver     hub.RequestResolver
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		index = hub.NewRequestIndex(fake.NewSimpleClientset().CoreV1(), "open-cluster-management")
+		mockMCClient = newMockManagedClusterClientForCombined()
+		mockCDClient = newMockClusterDeploymentClientForCombined()
+		mockCDClient.clusterDeployments["partner-lab"] = &hub.ClusterDeploymentInfo{
+			Name:       "partner-lab",
+			Namespace:  "partner-lab",
+			RequestID:  "1234",
+			Installed:  true,
+			PowerState: "Running",
+			APIUrl:     "https://api.partner-lab.example.com:6443",
+			ConsoleURL: "https://console.partner-lab.example.com",
+		}
+		mockMCClient.managedClusters = []hub.ManagedClusterInfo{{Name: "partner-lab", Status: hub.StatusReady}}
+		resolver = hub.NewRequestResolver(index, mockCDClient, mockMCClient)
+	})
+
+	It("should resolve an indexed request", func() {
+		Expect(index.Record(ctx, "1234", "partner-lab")).To(Succeed())
+
+		info, err := resolver.Resolve(ctx, "1234")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*info).To(Equal(hub.RequestInfo{
+			RequestID:  "1234",
+			Cluster:    "partner-lab",
+			Status:     "Ready",
+			PowerState: "Running",
+			Installed:  true,
+			APIURL:     "https://api.partner-lab.example.com:6443",
+			ConsoleURL: "https://console.partner-lab.example.com",
+		}))
+	})
+
+	It("should fall back to the request-id label and repair the index", func() {
+		info, err := resolver.Resolve(ctx, "1234")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Cluster).To(Equal("partner-lab"))
+
+		name, err := index.Lookup(ctx, "1234")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("partner-lab"))
+	})
+
+	It("should report clusters that are still provisioning", func() {
+		mockCDClient.clusterDeployments["partner-lab"].Installed = false
+
+		info, err := resolver.Resolve(ctx, "1234")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Status).To(Equal("Provisioning"))
+	})
+
+	It("should report installed clusters that are not imported", func() {
+		mockMCClient.managedClusters = nil
+
+		info, err := resolver.Resolve(ctx, "1234")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Status).To(Equal("NotImported"))
+	})
+
+	It("should return an error for unknown requests", func() {
+		_, err := resolver.Resolve(ctx, "9999")
+		Expect(err).To(MatchError("no cluster found for request 9999"))
+	})
+})
@@ -0,0 +1,193 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// Power schedule annotations on ClusterDeployments
+const (
+	// HibernateScheduleAnnotation holds the cron expression of when a cluster is hibernated
+	HibernateScheduleAnnotation = "labrat.openshift-partner-labs.io/hibernate-schedule"
+	// ResumeScheduleAnnotation holds the cron expression of when a cluster is resumed
+	ResumeScheduleAnnotation = "labrat.openshift-partner-labs.io/resume-schedule"
+	// ScheduleTimezoneAnnotation holds the IANA time zone the schedules are evaluated in
+	ScheduleTimezoneAnnotation = "labrat.openshift-partner-labs.io/schedule-timezone"
+	// ScheduleAppliedAnnotation records when the schedule that was last enforced fired, as an
+	// RFC 3339 timestamp
+	ScheduleAppliedAnnotation = "labrat.openshift-partner-labs.io/schedule-applied-at"
+)
+
+// ScheduleInfo is the power schedule of a cluster
+type ScheduleInfo struct {
+	// Cluster is the name of the ClusterDeployment
+	Cluster string
+	// Hibernate is the cron expression of when the cluster is hibernated, empty if never
+	Hibernate string `json:",omitempty"`
+	// Resume is the cron expression of when the cluster is resumed, empty if never
+	Resume string `json:",omitempty"`
+	// Timezone is the time zone of the schedules, empty for UTC
+	Timezone string `json:",omitempty"`
+	// AppliedAt is when the schedule that was last enforced fired, nil if none was
+	AppliedAt *time.Time `json:",omitempty"`
+	// PowerState is the power state of the cluster
+	PowerState string
+	// Installed indicates whether the cluster installation is complete
+	Installed bool
+}
+
+// ScheduleClient manages the power schedules of clusters through annotations on their
+// ClusterDeployment
+type ScheduleClient interface {
+	// Set sets the schedule of a cluster, replacing any existing schedule
+	Set(ctx context.Context, cluster, hibernate, resume, timezone string) error
+	// Clear removes the schedule of a cluster
+	Clear(ctx context.Context, cluster string) error
+	// MarkApplied records that the schedule of a cluster that fired at firedAt was enforced
+	MarkApplied(ctx context.Context, cluster string, firedAt time.Time) error
+	// List retrieves the clusters with a schedule, sorted by name
+	List(ctx context.Context) ([]ScheduleInfo, error)
+}
+
+type scheduleClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewScheduleClient creates a new ScheduleClient
+func NewScheduleClient(dynamicClient dynamic.Interface, options ...kube.Option) ScheduleClient {
+	return &scheduleClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Set annotates the ClusterDeployment in namespace=cluster with the schedules and clears when
+// a schedule was last enforced
+func (s *scheduleClient) Set(ctx context.Context, cluster, hibernate, resume, timezone string) error {
+	ctx, cancel, err := s.options.Start(ctx, "set schedule", "cluster", cluster, "hibernate", hibernate, "resume", resume, "timezone", timezone)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return s.patchAnnotations(ctx, cluster, map[string]interface{}{
+		HibernateScheduleAnnotation: valueOrNil(hibernate),
+		ResumeScheduleAnnotation:    valueOrNil(resume),
+		ScheduleTimezoneAnnotation:  valueOrNil(timezone),
+		ScheduleAppliedAnnotation:   nil,
+	})
+}
+
+// Clear removes the schedule annotations from the ClusterDeployment in namespace=cluster
+func (s *scheduleClient) Clear(ctx context.Context, cluster string) error {
+	ctx, cancel, err := s.options.Start(ctx, "clear schedule", "cluster", cluster)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return s.patchAnnotations(ctx, cluster, map[string]interface{}{
+		HibernateScheduleAnnotation: nil,
+		ResumeScheduleAnnotation:    nil,
+		ScheduleTimezoneAnnotation:  nil,
+		ScheduleAppliedAnnotation:   nil,
+	})
+}
+
+// MarkApplied annotates the ClusterDeployment in namespace=cluster with firedAt
+func (s *scheduleClient) MarkApplied(ctx context.Context, cluster string, firedAt time.Time) error {
+	ctx, cancel, err := s.options.Start(ctx, "mark schedule applied", "cluster", cluster, "firedAt", firedAt)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return s.patchAnnotations(ctx, cluster, map[string]interface{}{
+		ScheduleAppliedAnnotation: firedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// patchAnnotations merges annotations into the ClusterDeployment of cluster; nil values
+// remove the annotation
+func (s *scheduleClient) patchAnnotations(ctx context.Context, cluster string, annotations map[string]interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule patch: %w", err)
+	}
+
+	_, err = s.dynamicClient.Resource(clusterDeploymentGVR).Namespace(cluster).Patch(ctx, cluster, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update schedule of ClusterDeployment %s: %w", cluster, err)
+	}
+	return nil
+}
+
+// List lists the ClusterDeployments in all namespaces and returns those with a schedule
+func (s *scheduleClient) List(ctx context.Context) ([]ScheduleInfo, error) {
+	ctx, cancel, err := s.options.Start(ctx, "list schedules")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = s.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = s.dynamicClient.Resource(clusterDeploymentGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterDeployments: %w", err)
+	}
+
+	schedules := make([]ScheduleInfo, 0)
+	var failed []error
+	for _, item := range list.Items {
+		annotations := item.GetAnnotations()
+		hibernate, resume := annotations[HibernateScheduleAnnotation], annotations[ResumeScheduleAnnotation]
+		if hibernate == "" && resume == "" {
+			continue
+		}
+		cd := parseListedClusterDeployment(&item, &failed)
+		if cd == nil {
+			continue
+		}
+
+		info := ScheduleInfo{
+			Cluster:    cd.Name,
+			Hibernate:  hibernate,
+			Resume:     resume,
+			Timezone:   annotations[ScheduleTimezoneAnnotation],
+			PowerState: cd.PowerState,
+			Installed:  cd.Installed,
+		}
+		// An applied time that cannot be parsed counts as never applied
+		if appliedAt, err := time.Parse(time.RFC3339, annotations[ScheduleAppliedAnnotation]); err == nil {
+			info.AppliedAt = &appliedAt
+		}
+		schedules = append(schedules, info)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].Cluster < schedules[j].Cluster })
+	return schedules, decodeError(failed)
+}
+
+// valueOrNil returns value, or nil to remove an annotation if value is empty
+func valueOrNil(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
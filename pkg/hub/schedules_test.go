@@ -0,0 +1,101 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ScheduleClient", func() {
+	var (
+		ctx           context.Context
+		cdGVR         schema.GroupVersionResource
+		dynamicClient *dynamicfake.FakeDynamicClient
+		schedules     hub.ScheduleClient
+	)
+
+	clusterDeployment := func(name string, annotations map[string]interface{}, powerState string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterDeployment",
+			"metadata":   map[string]interface{}{"name": name, "namespace": name, "annotations": annotations},
+			"spec":       map[string]interface{}{"powerState": powerState, "installed": true},
+		}}
+	}
+
+	annotationsOf := func(name string) map[string]string {
+		cd, err := dynamicClient.Resource(cdGVR).Namespace(name).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return cd.GetAnnotations()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cdGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+		dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{cdGVR: "ClusterDeploymentList"},
+			clusterDeployment("nightly", map[string]interface{}{
+				hub.HibernateScheduleAnnotation: "0 20 * * 1-5",
+				hub.ResumeScheduleAnnotation:    "0 8 * * 1-5",
+				hub.ScheduleTimezoneAnnotation:  "Europe/Berlin",
+				hub.ScheduleAppliedAnnotation:   "2026-10-14T06:00:00Z",
+			}, "Running"),
+			clusterDeployment("bedtime", map[string]interface{}{
+				hub.HibernateScheduleAnnotation: "0 22 * * *",
+				hub.ScheduleAppliedAnnotation:   "yesterday",
+			}, "Hibernating"),
+			clusterDeployment("always-on", nil, "Running"),
+		)
+		schedules = hub.NewScheduleClient(dynamicClient)
+	})
+
+	It("should list the clusters with a schedule sorted by name", func() {
+		appliedAt := time.Date(2026, 10, 14, 6, 0, 0, 0, time.UTC)
+		result, err := schedules.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal([]hub.ScheduleInfo{
+			{Cluster: "bedtime", Hibernate: "0 22 * * *", PowerState: "Hibernating", Installed: true},
+			{Cluster: "nightly", Hibernate: "0 20 * * 1-5", Resume: "0 8 * * 1-5", Timezone: "Europe/Berlin", AppliedAt: &appliedAt, PowerState: "Running", Installed: true},
+		}))
+	})
+
+	It("should replace the schedule and forget when it was applied", func() {
+		Expect(schedules.Set(ctx, "nightly", "0 19 * * *", "", "America/New_York")).To(Succeed())
+
+		annotations := annotationsOf("nightly")
+		Expect(annotations).To(HaveKeyWithValue(hub.HibernateScheduleAnnotation, "0 19 * * *"))
+		Expect(annotations).To(HaveKeyWithValue(hub.ScheduleTimezoneAnnotation, "America/New_York"))
+		Expect(annotations).NotTo(HaveKey(hub.ResumeScheduleAnnotation))
+		Expect(annotations).NotTo(HaveKey(hub.ScheduleAppliedAnnotation))
+	})
+
+	It("should record when the enforced schedule fired in UTC", func() {
+		firedAt := time.Date(2026, 10, 14, 22, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+		Expect(schedules.MarkApplied(ctx, "bedtime", firedAt)).To(Succeed())
+		Expect(annotationsOf("bedtime")).To(HaveKeyWithValue(hub.ScheduleAppliedAnnotation, "2026-10-14T20:00:00Z"))
+	})
+
+	It("should clear the schedule", func() {
+		Expect(schedules.Clear(ctx, "nightly")).To(Succeed())
+		Expect(annotationsOf("nightly")).To(BeEmpty())
+
+		result, err := schedules.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(HaveLen(1))
+	})
+
+	It("should fail for a cluster without a ClusterDeployment", func() {
+		err := schedules.Set(ctx, "missing", "0 20 * * *", "", "")
+		Expect(err).To(MatchError(ContainSubstring("failed to update schedule of ClusterDeployment missing")))
+	})
+})
@@ -0,0 +1,187 @@
+package hub
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortField is a cluster field list output can be sorted by
+type SortField string
+
+const (
+	// SortByName sorts clusters by name
+	SortByName SortField = "name"
+	// SortByStatus sorts clusters by status: Ready, NotReady, then Unknown
+	SortByStatus SortField = "status"
+	// SortByVersion sorts clusters by OpenShift version, comparing version numbers numerically
+	SortByVersion SortField = "version"
+	// SortByRegion sorts clusters by cloud region
+	SortByRegion SortField = "region"
+	// SortByPower sorts clusters by power state
+	SortByPower SortField = "power"
+)
+
+// SortFields lists the supported sort fields in the order they are documented
+var SortFields = []SortField{SortByName, SortByStatus, SortByVersion, SortByRegion, SortByPower}
+
+// statusOrder ranks cluster statuses so healthy clusters are listed first
+var statusOrder = map[ClusterStatus]int{
+	StatusReady:    0,
+	StatusNotReady: 1,
+	StatusUnknown:  2,
+}
+
+// SortOptions orders list output. The zero value keeps the API order.
+type SortOptions struct {
+	// Field is the field to sort by; empty keeps the API order
+	Field SortField
+	// Descending reverses the order. Clusters without a value for Field are listed last either way.
+	Descending bool
+}
+
+// ParseSortBy parses a sort specification of the form <field>[:asc|:desc], e.g. version:desc
+func ParseSortBy(spec string) (SortOptions, error) {
+	if spec == "" {
+		return SortOptions{}, nil
+	}
+
+	field, direction, _ := strings.Cut(spec, ":")
+	opts := SortOptions{Field: SortField(strings.ToLower(field))}
+	switch strings.ToLower(direction) {
+	case "", "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		return SortOptions{}, fmt.Errorf("unsupported sort direction %q (supported: asc, desc)", direction)
+	}
+
+	for _, supported := range SortFields {
+		if opts.Field == supported {
+			return opts, nil
+		}
+	}
+	return SortOptions{}, fmt.Errorf("unsupported sort field %q (supported: %s)", field, joinSortFields())
+}
+
+// RequiresCombined reports whether the field is only known for CombinedClusterInfo, i.e. it
+// comes from the ClusterDeployment or ManagedClusterInfo of a cluster
+func (f SortField) RequiresCombined() bool {
+	return f == SortByVersion || f == SortByRegion || f == SortByPower
+}
+
+// SortManagedClusters sorts clusters in place. Only name and status are known for
+// ManagedClusterInfo, other fields return an error.
+func SortManagedClusters(clusters []ManagedClusterInfo, opts SortOptions) error {
+	if opts.Field == "" {
+		return nil
+	}
+	if opts.Field.RequiresCombined() {
+		return fmt.Errorf("managed clusters cannot be sorted by %s without ClusterDeployment data", opts.Field)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		var cmp int
+		if opts.Field == SortByStatus {
+			cmp = statusOrder[a.Status] - statusOrder[b.Status]
+		}
+		return lessClusters(cmp, opts.Descending, a.Hub+"/"+a.Name, b.Hub+"/"+b.Name)
+	})
+	return nil
+}
+
+// SortCombinedClusters sorts clusters in place
+func SortCombinedClusters(clusters []CombinedClusterInfo, opts SortOptions) error {
+	if opts.Field == "" {
+		return nil
+	}
+
+	var value func(c CombinedClusterInfo) string
+	switch opts.Field {
+	case SortByName, SortByStatus:
+	case SortByVersion:
+		value = func(c CombinedClusterInfo) string { return c.Version }
+	case SortByRegion:
+		value = func(c CombinedClusterInfo) string { return c.Region }
+	case SortByPower:
+		value = func(c CombinedClusterInfo) string { return c.PowerState }
+	default:
+		return fmt.Errorf("unsupported sort field %q (supported: %s)", opts.Field, joinSortFields())
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		var cmp int
+		switch {
+		case opts.Field == SortByStatus:
+			cmp = statusOrder[a.Status] - statusOrder[b.Status]
+		case value != nil:
+			va, vb := value(a), value(b)
+			// Clusters without a value are listed last, whatever the direction
+			if missingA, missingB := isMissing(va), isMissing(vb); missingA != missingB {
+				return missingB
+			}
+			if opts.Field == SortByVersion {
+				cmp = CompareVersions(va, vb)
+			} else {
+				cmp = strings.Compare(va, vb)
+			}
+		}
+		return lessClusters(cmp, opts.Descending, a.Hub+"/"+a.Name, b.Hub+"/"+b.Name)
+	})
+	return nil
+}
+
+// lessClusters orders two clusters by the comparison of their sort field, then by name. The
+// direction applies to both, so descending output is the exact reverse of ascending output.
+func lessClusters(cmp int, descending bool, nameA, nameB string) bool {
+	if cmp == 0 {
+		cmp = strings.Compare(nameA, nameB)
+	}
+	if descending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// isMissing reports whether a field value is a placeholder for unknown data
+func isMissing(value string) bool {
+	return value == "" || value == "N/A" || value == "Unknown"
+}
+
+// CompareVersions compares dotted versions such as 4.16.12 numerically segment by segment, so
+// 4.9 sorts before 4.16. Non-numeric segments, e.g. of pre-releases, are compared as strings.
+func CompareVersions(a, b string) int {
+	partsA := strings.FieldsFunc(a, isVersionSeparator)
+	partsB := strings.FieldsFunc(b, isVersionSeparator)
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numA, errA := strconv.Atoi(partsA[i])
+		numB, errB := strconv.Atoi(partsB[i])
+		var cmp int
+		if errA == nil && errB == nil {
+			cmp = numA - numB
+		} else {
+			cmp = strings.Compare(partsA[i], partsB[i])
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return len(partsA) - len(partsB)
+}
+
+// isVersionSeparator splits versions into their numeric and pre-release segments
+func isVersionSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '+'
+}
+
+// joinSortFields lists the supported sort fields for error messages
+func joinSortFields() string {
+	names := make([]string, 0, len(SortFields))
+	for _, field := range SortFields {
+		names = append(names, string(field))
+	}
+	return strings.Join(names, ", ")
+}
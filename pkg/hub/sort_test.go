@@ -0,0 +1,114 @@
+//go:build test
+
+package hub_test
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("Sorting", func() {
+	names := func(clusters []hub.CombinedClusterInfo) []string {
+		result := make([]string, 0, len(clusters))
+		for _, cluster := range clusters {
+			result = append(result, cluster.Name)
+		}
+		return result
+	}
+
+	var combined []hub.CombinedClusterInfo
+
+	BeforeEach(func() {
+		combined = []hub.CombinedClusterInfo{
+			{Name: "delta", Status: hub.StatusUnknown, Version: "N/A", Region: "N/A", PowerState: "N/A"},
+			{Name: "alpha", Status: hub.StatusNotReady, Version: "4.16.12", Region: "us-east-1", PowerState: "Hibernating"},
+			{Name: "charlie", Status: hub.StatusReady, Version: "4.9.59", Region: "eu-west-1", PowerState: "Running"},
+			{Name: "bravo", Status: hub.StatusReady, Version: "4.16.2", Region: "us-east-1", PowerState: "Running"},
+		}
+	})
+
+	Describe("ParseSortBy", func() {
+		It("should parse a field with an optional direction", func() {
+			Expect(hub.ParseSortBy("")).To(Equal(hub.SortOptions{}))
+			Expect(hub.ParseSortBy("name")).To(Equal(hub.SortOptions{Field: hub.SortByName}))
+			Expect(hub.ParseSortBy("Version:desc")).To(Equal(hub.SortOptions{Field: hub.SortByVersion, Descending: true}))
+			Expect(hub.ParseSortBy("power:asc")).To(Equal(hub.SortOptions{Field: hub.SortByPower}))
+		})
+
+		It("should reject unknown fields and directions", func() {
+			_, err := hub.ParseSortBy("age")
+			Expect(err).To(MatchError(ContainSubstring(`unsupported sort field "age" (supported: name, status, version, region, power)`)))
+			_, err = hub.ParseSortBy("name:up")
+			Expect(err).To(MatchError(ContainSubstring(`unsupported sort direction "up"`)))
+		})
+	})
+
+	Describe("SortCombinedClusters", func() {
+		It("should sort by name", func() {
+			Expect(hub.SortCombinedClusters(combined, hub.SortOptions{Field: hub.SortByName})).To(Succeed())
+			Expect(names(combined)).To(Equal([]string{"alpha", "bravo", "charlie", "delta"}))
+		})
+
+		It("should sort by status with ties broken by name", func() {
+			Expect(hub.SortCombinedClusters(combined, hub.SortOptions{Field: hub.SortByStatus})).To(Succeed())
+			Expect(names(combined)).To(Equal([]string{"bravo", "charlie", "alpha", "delta"}))
+		})
+
+		It("should compare versions numerically", func() {
+			Expect(hub.SortCombinedClusters(combined, hub.SortOptions{Field: hub.SortByVersion})).To(Succeed())
+			Expect(names(combined)).To(Equal([]string{"charlie", "bravo", "alpha", "delta"}))
+		})
+
+		It("should list clusters without a value last when descending", func() {
+			Expect(hub.SortCombinedClusters(combined, hub.SortOptions{Field: hub.SortByVersion, Descending: true})).To(Succeed())
+			Expect(names(combined)).To(Equal([]string{"alpha", "bravo", "charlie", "delta"}))
+		})
+
+		It("should sort by region and power state", func() {
+			Expect(hub.SortCombinedClusters(combined, hub.SortOptions{Field: hub.SortByRegion})).To(Succeed())
+			Expect(names(combined)).To(Equal([]string{"charlie", "alpha", "bravo", "delta"}))
+
+			Expect(hub.SortCombinedClusters(combined, hub.SortOptions{Field: hub.SortByPower, Descending: true})).To(Succeed())
+			Expect(names(combined)).To(Equal([]string{"charlie", "bravo", "alpha", "delta"}))
+		})
+	})
+
+	Describe("SortManagedClusters", func() {
+		It("should sort by status", func() {
+			clusters := []hub.ManagedClusterInfo{
+				{Name: "b", Status: hub.StatusUnknown},
+				{Name: "a", Status: hub.StatusNotReady},
+				{Name: "c", Status: hub.StatusReady},
+			}
+			Expect(hub.SortManagedClusters(clusters, hub.SortOptions{Field: hub.SortByStatus})).To(Succeed())
+			Expect(clusters[0].Name).To(Equal("c"))
+			Expect(clusters[2].Name).To(Equal("b"))
+		})
+
+		It("should reject fields only known from ClusterDeployments", func() {
+			err := hub.SortManagedClusters([]hub.ManagedClusterInfo{{Name: "a"}}, hub.SortOptions{Field: hub.SortByRegion})
+			Expect(err).To(MatchError(ContainSubstring("cannot be sorted by region")))
+		})
+	})
+
+	Describe("OutputWriter", func() {
+		It("should write sorted clusters without reordering the caller's slice", func() {
+			buffer := new(bytes.Buffer)
+			writer := hub.NewOutputWriter(hub.OutputFormatTable, buffer)
+			writer.SetSort(hub.SortOptions{Field: hub.SortByName, Descending: true})
+
+			Expect(writer.WriteCombined(combined, false)).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+			Expect(lines).To(HaveLen(5))
+			Expect(lines[1]).To(HavePrefix("delta"))
+			Expect(lines[4]).To(HavePrefix("alpha"))
+			Expect(combined[0].Name).To(Equal("delta"))
+			Expect(combined[1].Name).To(Equal("alpha"))
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// StatusReportName is the report (and JUnit test suite) name used by hub status checks
+const StatusReportName = "labrat.hub.status"
+
+// multiClusterHubGVR identifies the ACM MultiClusterHub operator resource
+var multiClusterHubGVR = schema.GroupVersionResource{
+	Group:    "operator.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "multiclusterhubs",
+}
+
+// StatusChecker runs health checks against the ACM hub
+type StatusChecker interface {
+	// Check runs all hub health checks and returns their results
+	Check(ctx context.Context) check.Report
+}
+
+type statusChecker struct {
+	discoveryClient      discovery.DiscoveryInterface
+	dynamicClient        dynamic.Interface
+	managedClusterClient ManagedClusterClient
+	namespace            string
+}
+
+// NewStatusChecker creates a new StatusChecker.
+// The namespace is where the MultiClusterHub resource is expected (typically open-cluster-management).
+func NewStatusChecker(
+	discoveryClient discovery.DiscoveryInterface,
+	dynamicClient dynamic.Interface,
+	namespace string,
+	options ...kube.Option,
+) StatusChecker {
+	return &statusChecker{
+		discoveryClient:      discoveryClient,
+		dynamicClient:        dynamicClient,
+		managedClusterClient: NewManagedClusterClient(dynamicClient, options...),
+		namespace:            namespace,
+	}
+}
+
+// Check runs the hub health checks in order:
+// 1. API server reachability (remaining checks are skipped if this fails)
+// 2. ManagedCluster API availability
+// 3. Hive ClusterDeployment API availability
+// 4. MultiClusterHub phase
+// 5. Availability of the managed clusters
+func (s *statusChecker) Check(ctx context.Context) check.Report {
+	report := check.Report{Name: StatusReportName}
+
+	result := report.Run("API server reachable", s.checkAPIServer)
+	if result.Status == check.StatusFail {
+		return report
+	}
+
+	report.Run("ManagedCluster API available", func() (check.Status, string) {
+		return s.checkAPIResource("cluster.open-cluster-management.io/v1", "managedclusters", check.StatusFail)
+	})
+	report.Run("Hive ClusterDeployment API available", func() (check.Status, string) {
+		return s.checkAPIResource("hive.openshift.io/v1", "clusterdeployments", check.StatusWarn)
+	})
+	report.Run("MultiClusterHub running", func() (check.Status, string) {
+		return s.checkMultiClusterHub(ctx)
+	})
+	report.Run("Managed clusters available", func() (check.Status, string) {
+		return s.checkManagedClusters(ctx)
+	})
+
+	return report
+}
+
+// checkAPIServer verifies the hub API server responds to a version request
+func (s *statusChecker) checkAPIServer() (check.Status, string) {
+	version, err := s.discoveryClient.ServerVersion()
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to reach API server: %v", err)
+	}
+	return check.StatusPass, fmt.Sprintf("Kubernetes %s", version.GitVersion)
+}
+
+// checkAPIResource verifies a resource is served in the given group version.
+// missingStatus is reported when the resource is not served.
+func (s *statusChecker) checkAPIResource(groupVersion, resource string, missingStatus check.Status) (check.Status, string) {
+	resources, err := s.discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return missingStatus, fmt.Sprintf("%s not served: %v", groupVersion, err)
+	}
+
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			return check.StatusPass, fmt.Sprintf("%s served by %s", resource, groupVersion)
+		}
+	}
+
+	return missingStatus, fmt.Sprintf("%s not found in %s", resource, groupVersion)
+}
+
+// checkMultiClusterHub verifies the MultiClusterHub in the ACM namespace reports phase Running
+func (s *statusChecker) checkMultiClusterHub(ctx context.Context) (check.Status, string) {
+	list, err := s.dynamicClient.Resource(multiClusterHubGVR).Namespace(s.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return check.StatusWarn, fmt.Sprintf("failed to list MultiClusterHub resources: %v", err)
+	}
+
+	if len(list.Items) == 0 {
+		return check.StatusWarn, fmt.Sprintf("no MultiClusterHub found in namespace %s", s.namespace)
+	}
+
+	mch := list.Items[0]
+	phase, _, _ := unstructured.NestedString(mch.Object, "status", "phase")
+	if phase != "Running" {
+		return check.StatusFail, fmt.Sprintf("MultiClusterHub %s is in phase %q", mch.GetName(), phase)
+	}
+
+	version, _, _ := unstructured.NestedString(mch.Object, "status", "currentVersion")
+	if version != "" {
+		return check.StatusPass, fmt.Sprintf("MultiClusterHub %s is Running (ACM %s)", mch.GetName(), version)
+	}
+	return check.StatusPass, fmt.Sprintf("MultiClusterHub %s is Running", mch.GetName())
+}
+
+// checkManagedClusters verifies every managed cluster reports Ready
+func (s *statusChecker) checkManagedClusters(ctx context.Context) (check.Status, string) {
+	clusters, err := s.managedClusterClient.List(ctx)
+	if err != nil {
+		return check.StatusFail, err.Error()
+	}
+
+	if len(clusters) == 0 {
+		return check.StatusWarn, "no managed clusters registered"
+	}
+
+	notReady := 0
+	for _, cluster := range clusters {
+		if cluster.Status != StatusReady {
+			notReady++
+		}
+	}
+
+	if notReady > 0 {
+		return check.StatusWarn, fmt.Sprintf("%d of %d managed clusters are not ready", notReady, len(clusters))
+	}
+	return check.StatusPass, fmt.Sprintf("all %d managed clusters are ready", len(clusters))
+}
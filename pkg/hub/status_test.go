@@ -0,0 +1,111 @@
+//go:build test
+
+package hub_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/test/helpers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("StatusChecker", func() {
+	var (
+		discovery *fakediscovery.FakeDiscovery
+		listKinds map[schema.GroupVersionResource]string
+		objects   []runtime.Object
+	)
+
+	newMCH := func(phase string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "operator.open-cluster-management.io/v1",
+			"kind":       "MultiClusterHub",
+			"metadata":   map[string]interface{}{"name": "multiclusterhub", "namespace": "open-cluster-management"},
+			"status":     map[string]interface{}{"phase": phase, "currentVersion": "2.12.0"},
+		}}
+	}
+
+	runCheck := func() check.Report {
+		dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+		return hub.NewStatusChecker(discovery, dyn, "open-cluster-management").Check(context.Background())
+	}
+
+	statusOf := func(report check.Report, name string) check.Status {
+		for _, result := range report.Results {
+			if result.Name == name {
+				return result.Status
+			}
+		}
+		return ""
+	}
+
+	BeforeEach(func() {
+		discovery = k8sFake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+		discovery.FakedServerVersion = &version.Info{GitVersion: "v1.30.4"}
+		discovery.Resources = []*metav1.APIResourceList{
+			{
+				GroupVersion: "cluster.open-cluster-management.io/v1",
+				APIResources: []metav1.APIResource{{Name: "managedclusters"}},
+			},
+			{
+				GroupVersion: "hive.openshift.io/v1",
+				APIResources: []metav1.APIResource{{Name: "clusterdeployments"}},
+			},
+		}
+		listKinds = map[schema.GroupVersionResource]string{
+			{Group: "operator.open-cluster-management.io", Version: "v1", Resource: "multiclusterhubs"}: "MultiClusterHubList",
+			{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}:   "ManagedClusterList",
+		}
+
+		cluster := helpers.CreateTestManagedCluster("cluster-1", "True")
+		cluster.SetGroupVersionKind(schema.GroupVersionKind{Group: "cluster.open-cluster-management.io", Version: "v1", Kind: "ManagedCluster"})
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		objects = []runtime.Object{newMCH("Running"), &unstructured.Unstructured{Object: obj}}
+	})
+
+	It("should pass all checks on a healthy hub", func() {
+		report := runCheck()
+		Expect(report.Name).To(Equal(hub.StatusReportName))
+		Expect(report.Results).To(HaveLen(5))
+		for _, result := range report.Results {
+			Expect(result.Status).To(Equal(check.StatusPass), result.Name+": "+result.Message)
+		}
+	})
+
+	It("should warn when Hive is not installed", func() {
+		discovery.Resources = discovery.Resources[:1]
+		report := runCheck()
+		Expect(statusOf(report, "Hive ClusterDeployment API available")).To(Equal(check.StatusWarn))
+		Expect(report.Failed()).To(BeFalse())
+	})
+
+	It("should fail when the MultiClusterHub is not running", func() {
+		objects[0] = newMCH("Installing")
+		report := runCheck()
+		Expect(statusOf(report, "MultiClusterHub running")).To(Equal(check.StatusFail))
+		Expect(report.Failed()).To(BeTrue())
+	})
+
+	It("should warn when managed clusters are not ready", func() {
+		cluster := helpers.CreateTestManagedCluster("cluster-2", "False")
+		cluster.SetGroupVersionKind(schema.GroupVersionKind{Group: "cluster.open-cluster-management.io", Version: "v1", Kind: "ManagedCluster"})
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		objects = append(objects, &unstructured.Unstructured{Object: obj})
+
+		report := runCheck()
+		Expect(statusOf(report, "Managed clusters available")).To(Equal(check.StatusWarn))
+	})
+})
@@ -0,0 +1,121 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+)
+
+// Summary aggregates the clusters of one or more hubs into counts per field value
+type Summary struct {
+	// Total is the number of clusters
+	Total int
+	// Hub counts clusters per hub, set only for multi-hub queries
+	Hub []SummaryCount `json:",omitempty"`
+	// Status counts clusters per ManagedCluster status
+	Status []SummaryCount
+	// Platform counts clusters per cloud platform
+	Platform []SummaryCount
+	// Region counts clusters per cloud region
+	Region []SummaryCount
+	// Version counts clusters per OpenShift version
+	Version []SummaryCount
+	// PowerState counts clusters per power state
+	PowerState []SummaryCount
+}
+
+// SummaryCount is the number of clusters with a field value
+type SummaryCount struct {
+	Value string
+	Count int
+}
+
+// Summarize counts clusters per status, platform, region, version, and power state. Counts are
+// ordered by the number of clusters, most first, then by value.
+func Summarize(clusters []CombinedClusterInfo) Summary {
+	summary := Summary{Total: len(clusters)}
+
+	count := func(value func(c CombinedClusterInfo) string) []SummaryCount {
+		counts := make(map[string]int)
+		for _, cluster := range clusters {
+			counts[valueOrNA(value(cluster))]++
+		}
+		result := make([]SummaryCount, 0, len(counts))
+		for v, n := range counts {
+			result = append(result, SummaryCount{Value: v, Count: n})
+		}
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].Count != result[j].Count {
+				return result[i].Count > result[j].Count
+			}
+			return result[i].Value < result[j].Value
+		})
+		return result
+	}
+
+	for _, cluster := range clusters {
+		if cluster.Hub != "" {
+			summary.Hub = count(func(c CombinedClusterInfo) string { return c.Hub })
+			break
+		}
+	}
+	summary.Status = count(func(c CombinedClusterInfo) string { return string(c.Status) })
+	summary.Platform = count(func(c CombinedClusterInfo) string { return c.Platform })
+	summary.Region = count(func(c CombinedClusterInfo) string { return c.Region })
+	summary.Version = count(func(c CombinedClusterInfo) string { return c.Version })
+	summary.PowerState = count(func(c CombinedClusterInfo) string { return c.PowerState })
+	return summary
+}
+
+// WriteSummary formats and writes a cluster summary according to the configured format. CSV
+// and TSV are not supported, as a summary is not a list of records.
+func (o *OutputWriter) WriteSummary(summary Summary) error {
+	switch o.format {
+	case OutputFormatTable:
+		return o.writeSummaryTable(summary)
+	case OutputFormatJSON:
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal summary to JSON: %w", err)
+		}
+		if _, err := fmt.Fprintln(o.writer, string(data)); err != nil {
+			return fmt.Errorf("failed to write JSON output: %w", err)
+		}
+		return nil
+	case OutputFormatYAML:
+		return o.writeYAML(summary)
+	case OutputFormatJSONPath, OutputFormatJSONPathFile, OutputFormatGoTemplate, OutputFormatGoTemplateFile:
+		return WriteTemplate(o.writer, o.format, o.template, summary)
+	default:
+		return fmt.Errorf("unsupported output format: %s", o.format)
+	}
+}
+
+// writeSummaryTable writes the total followed by one section per field
+func (o *OutputWriter) writeSummaryTable(summary Summary) error {
+	w := tabwriter.NewWriter(o.writer, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "TOTAL\t%d\n", summary.Total)
+
+	sections := []struct {
+		title  string
+		counts []SummaryCount
+	}{
+		{"HUB", summary.Hub},
+		{"STATUS", summary.Status},
+		{"PLATFORM", summary.Platform},
+		{"REGION", summary.Region},
+		{"VERSION", summary.Version},
+		{"POWER", summary.PowerState},
+	}
+	for _, section := range sections {
+		if len(section.counts) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\tCLUSTERS\n", section.title)
+		for _, c := range section.counts {
+			fmt.Fprintf(w, "%s\t%d\n", c.Value, c.Count)
+		}
+	}
+	return w.Flush()
+}
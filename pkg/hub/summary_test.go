@@ -0,0 +1,94 @@
+//go:build test
+
+package hub_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("Summarize", func() {
+	var clusters []hub.CombinedClusterInfo
+
+	BeforeEach(func() {
+		clusters = []hub.CombinedClusterInfo{
+			{Name: "a", Status: hub.StatusReady, Platform: "aws", Region: "us-east-1", Version: "4.16.12", PowerState: "Running"},
+			{Name: "b", Status: hub.StatusReady, Platform: "aws", Region: "us-east-2", Version: "4.16.12", PowerState: "Running"},
+			{Name: "c", Status: hub.StatusNotReady, Platform: "gcp", Region: "us-east1", Version: "4.15.3", PowerState: "Hibernating"},
+			{Name: "d", Status: hub.StatusUnknown},
+		}
+	})
+
+	It("should count clusters per field value, most common first", func() {
+		summary := hub.Summarize(clusters)
+
+		Expect(summary.Total).To(Equal(4))
+		Expect(summary.Hub).To(BeEmpty())
+		Expect(summary.Status).To(Equal([]hub.SummaryCount{
+			{Value: "Ready", Count: 2}, {Value: "NotReady", Count: 1}, {Value: "Unknown", Count: 1},
+		}))
+		Expect(summary.Platform).To(Equal([]hub.SummaryCount{
+			{Value: "aws", Count: 2}, {Value: "N/A", Count: 1}, {Value: "gcp", Count: 1},
+		}))
+		Expect(summary.Version).To(Equal([]hub.SummaryCount{
+			{Value: "4.16.12", Count: 2}, {Value: "4.15.3", Count: 1}, {Value: "N/A", Count: 1},
+		}))
+		Expect(summary.Region).To(HaveLen(4))
+		Expect(summary.PowerState).To(Equal([]hub.SummaryCount{
+			{Value: "Running", Count: 2}, {Value: "Hibernating", Count: 1}, {Value: "N/A", Count: 1},
+		}))
+	})
+
+	It("should count clusters per hub for multi-hub queries", func() {
+		clusters[0].Hub, clusters[1].Hub, clusters[2].Hub, clusters[3].Hub = "prod", "prod", "staging", "prod"
+
+		Expect(hub.Summarize(clusters).Hub).To(Equal([]hub.SummaryCount{
+			{Value: "prod", Count: 3}, {Value: "staging", Count: 1},
+		}))
+	})
+
+	It("should summarize no clusters", func() {
+		summary := hub.Summarize(nil)
+		Expect(summary.Total).To(BeZero())
+		Expect(summary.Status).To(BeEmpty())
+	})
+})
+
+var _ = Describe("OutputWriter.WriteSummary", func() {
+	var summary hub.Summary
+
+	BeforeEach(func() {
+		summary = hub.Summarize([]hub.CombinedClusterInfo{
+			{Name: "a", Status: hub.StatusReady, Platform: "aws", Region: "us-east-1", Version: "4.16.12", PowerState: "Running"},
+		})
+	})
+
+	It("should write a section per field as a table", func() {
+		buffer := new(bytes.Buffer)
+		Expect(hub.NewOutputWriter(hub.OutputFormatTable, buffer).WriteSummary(summary)).To(Succeed())
+
+		output := buffer.String()
+		Expect(output).To(HavePrefix("TOTAL   1\n"))
+		Expect(output).To(ContainSubstring("STATUS   CLUSTERS\nReady    1\n"))
+		Expect(output).To(ContainSubstring("POWER     CLUSTERS\nRunning   1\n"))
+		Expect(output).NotTo(ContainSubstring("HUB"))
+	})
+
+	It("should write JSON", func() {
+		buffer := new(bytes.Buffer)
+		Expect(hub.NewOutputWriter(hub.OutputFormatJSON, buffer).WriteSummary(summary)).To(Succeed())
+
+		var decoded hub.Summary
+		Expect(json.Unmarshal(buffer.Bytes(), &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(summary))
+	})
+
+	It("should reject record formats", func() {
+		err := hub.NewOutputWriter(hub.OutputFormatCSV, new(bytes.Buffer)).WriteSummary(summary)
+		Expect(err).To(MatchError("unsupported output format: csv"))
+	})
+})
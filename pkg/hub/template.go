@@ -0,0 +1,124 @@
+package hub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Template output formats, given like kubectl as the format, an equals sign, and the template,
+// e.g. jsonpath={.items[*].Name}
+const (
+	// OutputFormatJSONPath renders the output with a kubectl JSONPath expression
+	OutputFormatJSONPath OutputFormat = "jsonpath"
+	// OutputFormatJSONPathFile renders the output with the JSONPath expression of a file
+	OutputFormatJSONPathFile OutputFormat = "jsonpath-file"
+	// OutputFormatGoTemplate renders the output with a Go template
+	OutputFormatGoTemplate OutputFormat = "go-template"
+	// OutputFormatGoTemplateFile renders the output with the Go template of a file
+	OutputFormatGoTemplateFile OutputFormat = "go-template-file"
+)
+
+// templateFormats are the output formats that take a template
+var templateFormats = []OutputFormat{OutputFormatJSONPath, OutputFormatJSONPathFile, OutputFormatGoTemplate, OutputFormatGoTemplateFile}
+
+// ParseOutputFormat splits the value of an --output flag into its format and, for the template
+// formats, the template, e.g. jsonpath={.items[*].Name} into jsonpath and {.items[*].Name}
+func ParseOutputFormat(value string) (OutputFormat, string) {
+	name, template, found := strings.Cut(value, "=")
+	format := OutputFormat(name)
+	if !found || !format.IsTemplate() {
+		return OutputFormat(value), ""
+	}
+	return format, template
+}
+
+// IsTemplate reports whether the format renders the output with a template
+func (f OutputFormat) IsTemplate() bool {
+	for _, format := range templateFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTemplate renders data with a template of format and writes the result to w. The
+// template sees data as -o json would print it; lists are wrapped in an object with an items
+// field, as kubectl lists, so {.items[*].name} selects the names of a list.
+func WriteTemplate(w io.Writer, format OutputFormat, text string, data interface{}) error {
+	if format == OutputFormatJSONPathFile || format == OutputFormatGoTemplateFile {
+		content, err := os.ReadFile(text)
+		if err != nil {
+			return fmt.Errorf("failed to read %s template: %w", format, err)
+		}
+		text = string(content)
+	}
+	if text == "" {
+		return fmt.Errorf("output format %s requires a template, e.g. -o %s=...", format, format)
+	}
+
+	generic, err := toGeneric(data)
+	if err != nil {
+		return err
+	}
+
+	var rendered bytes.Buffer
+	switch format {
+	case OutputFormatJSONPath, OutputFormatJSONPathFile:
+		// Like kubectl, a bare expression such as .items[*].name is wrapped in braces
+		if !strings.Contains(text, "{") {
+			text = "{" + text + "}"
+		}
+		parser := jsonpath.New("output").AllowMissingKeys(true)
+		if err := parser.Parse(text); err != nil {
+			return fmt.Errorf("failed to parse jsonpath template %q: %w", text, err)
+		}
+		if err := parser.Execute(&rendered, generic); err != nil {
+			return fmt.Errorf("failed to execute jsonpath template %q: %w", text, err)
+		}
+	case OutputFormatGoTemplate, OutputFormatGoTemplateFile:
+		tmpl, err := template.New("output").Parse(text)
+		if err != nil {
+			return fmt.Errorf("failed to parse go-template: %w", err)
+		}
+		if err := tmpl.Execute(&rendered, generic); err != nil {
+			return fmt.Errorf("failed to execute go-template: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+
+	if _, err := w.Write(rendered.Bytes()); err != nil {
+		return fmt.Errorf("failed to write %s output: %w", format, err)
+	}
+	return nil
+}
+
+// toGeneric converts data to the maps and slices of its JSON encoding, wrapping lists in an
+// object with an items field
+func toGeneric(data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal output: %w", err)
+	}
+
+	if value := reflect.ValueOf(data); value.Kind() == reflect.Slice {
+		if generic == nil {
+			generic = []interface{}{}
+		}
+		return map[string]interface{}{"items": generic}, nil
+	}
+	return generic, nil
+}
@@ -0,0 +1,107 @@
+//go:build test
+
+package hub_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+)
+
+var _ = Describe("Template output", func() {
+	var (
+		buf      *bytes.Buffer
+		clusters []hub.ManagedClusterInfo
+	)
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		clusters = []hub.ManagedClusterInfo{
+			{Name: "cluster-a", Status: hub.StatusReady, Available: "True"},
+			{Name: "cluster-b", Status: hub.StatusNotReady, Available: "False"},
+		}
+	})
+
+	Describe("ParseOutputFormat", func() {
+		It("should split the template from the template formats", func() {
+			format, template := hub.ParseOutputFormat("jsonpath={.items[?(@.Status==\"Ready\")].Name}")
+			Expect(format).To(Equal(hub.OutputFormatJSONPath))
+			Expect(template).To(Equal("{.items[?(@.Status==\"Ready\")].Name}"))
+
+			format, template = hub.ParseOutputFormat("go-template-file=clusters.tmpl")
+			Expect(format).To(Equal(hub.OutputFormatGoTemplateFile))
+			Expect(template).To(Equal("clusters.tmpl"))
+		})
+
+		It("should leave the other formats as they are", func() {
+			format, template := hub.ParseOutputFormat("json")
+			Expect(format).To(Equal(hub.OutputFormatJSON))
+			Expect(template).To(BeEmpty())
+
+			format, _ = hub.ParseOutputFormat("json=x")
+			Expect(format).To(Equal(hub.OutputFormat("json=x")))
+			Expect(format.IsTemplate()).To(BeFalse())
+		})
+	})
+
+	Describe("WriteTemplate", func() {
+		It("should select fields of a list with jsonpath", func() {
+			Expect(hub.WriteTemplate(buf, hub.OutputFormatJSONPath, "{.items[*].Name}", clusters)).To(Succeed())
+			Expect(buf.String()).To(Equal("cluster-a cluster-b"))
+		})
+
+		It("should wrap a bare jsonpath expression in braces", func() {
+			Expect(hub.WriteTemplate(buf, hub.OutputFormatJSONPath, ".items[0].Status", clusters)).To(Succeed())
+			Expect(buf.String()).To(Equal("Ready"))
+		})
+
+		It("should render a go-template", func() {
+			err := hub.WriteTemplate(buf, hub.OutputFormatGoTemplate, `{{range .items}}{{.Name}}={{.Available}}{{"\n"}}{{end}}`, clusters)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal("cluster-a=True\ncluster-b=False\n"))
+		})
+
+		It("should render objects without wrapping them", func() {
+			Expect(hub.WriteTemplate(buf, hub.OutputFormatJSONPath, "{.Total}", hub.Summarize(nil))).To(Succeed())
+			Expect(buf.String()).To(Equal("0"))
+		})
+
+		It("should read the template of a file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "names.tmpl")
+			Expect(os.WriteFile(path, []byte("{{len .items}}"), 0o600)).To(Succeed())
+
+			Expect(hub.WriteTemplate(buf, hub.OutputFormatGoTemplateFile, path, clusters)).To(Succeed())
+			Expect(buf.String()).To(Equal("2"))
+		})
+
+		It("should fail without a template", func() {
+			err := hub.WriteTemplate(buf, hub.OutputFormatJSONPath, "", clusters)
+			Expect(err).To(MatchError("output format jsonpath requires a template, e.g. -o jsonpath=..."))
+		})
+
+		It("should fail for an invalid template", func() {
+			Expect(hub.WriteTemplate(buf, hub.OutputFormatJSONPath, "{.items[", clusters)).
+				To(MatchError(ContainSubstring("failed to parse jsonpath template")))
+			Expect(hub.WriteTemplate(buf, hub.OutputFormatGoTemplate, "{{.Name", clusters)).
+				To(MatchError(ContainSubstring("failed to parse go-template")))
+		})
+	})
+
+	Describe("OutputWriter", func() {
+		It("should render clusters with the template of the format", func() {
+			writer := hub.NewOutputWriter("jsonpath={.items[1].Name}", buf)
+			Expect(writer.Write(clusters)).To(Succeed())
+			Expect(buf.String()).To(Equal("cluster-b"))
+		})
+
+		It("should render an empty list", func() {
+			writer := hub.NewOutputWriter("go-template={{len .items}}", buf)
+			Expect(writer.Write(nil)).To(Succeed())
+			Expect(buf.String()).To(Equal("0"))
+		})
+	})
+})
@@ -3,6 +3,12 @@
 // and output formatting for managed cluster information.
 package hub
 
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/watch"
+)
+
 // ClusterStatus represents the overall status of a managed cluster
 type ClusterStatus string
 
@@ -25,12 +31,76 @@ type ManagedClusterInfo struct {
 	Available string
 	// Message provides additional context about the cluster status
 	Message string
+	// Joined indicates whether the klusterlet has ever joined the hub, i.e. the cluster was imported
+	Joined bool
+	// ClusterSet is the ManagedClusterSet the cluster belongs to, empty if none
+	ClusterSet string `json:",omitempty"`
+	// Maintenance indicates the cluster is in maintenance, labeled with MaintenanceLabel
+	Maintenance bool `json:",omitempty"`
+	// Labels are the labels of the ManagedCluster
+	Labels map[string]string `json:",omitempty"`
+	// Hub is the name of the hub the cluster was listed from, set only for multi-hub queries
+	Hub string `json:",omitempty"`
+}
+
+// ManagedClusterCondition is a status condition of a ManagedCluster
+type ManagedClusterCondition struct {
+	Type               string
+	Status             string
+	Reason             string `json:",omitempty"`
+	Message            string `json:",omitempty"`
+	LastTransitionTime time.Time
+}
+
+// ManagedClusterDetail is the full state of a ManagedCluster, returned by ManagedClusterClient.Get
+type ManagedClusterDetail struct {
+	ManagedClusterInfo
+	// CreatedAt is when the ManagedCluster was created
+	CreatedAt time.Time
+	// HubAcceptsClient reports whether the hub accepts the klusterlet of the cluster
+	HubAcceptsClient bool
+	// KubernetesVersion is the Kubernetes version reported by the klusterlet
+	KubernetesVersion string `json:",omitempty"`
+	// Taints are the taints of the ManagedCluster as key=value:effect
+	Taints []string `json:",omitempty"`
+	// Conditions are all conditions of the ManagedCluster
+	Conditions []ManagedClusterCondition `json:",omitempty"`
+	// Capacity is the total of each resource of the cluster, e.g. cpu and memory
+	Capacity map[string]string `json:",omitempty"`
+	// Allocatable is the amount of each resource available for workloads
+	Allocatable map[string]string `json:",omitempty"`
+	// Claims are the ClusterClaims reported by the klusterlet, e.g. id.openshift.io with the
+	// cluster ID and version.openshift.io with the OpenShift version
+	Claims map[string]string `json:",omitempty"`
+}
+
+// ManagedClusterEvent is a change of a managed cluster streamed by ManagedClusterClient.Watch
+type ManagedClusterEvent struct {
+	// Type is Added, Modified, Deleted, or Error
+	Type watch.EventType
+	// Cluster is the cluster after the change, or before it was deleted
+	Cluster ManagedClusterInfo
+	// Err is set for Error events, after which the watch ends
+	Err error `json:"-"`
 }
 
 // ManagedClusterFilter defines criteria for filtering managed clusters
 type ManagedClusterFilter struct {
 	// Status filters clusters by their overall status
 	Status ClusterStatus
+	// ClusterSet filters clusters by the ManagedClusterSet they belong to
+	ClusterSet string
+	// Name filters clusters by a glob pattern of their name, e.g. partner-*
+	Name string
+	// Selector filters clusters by a label selector of their ManagedCluster, e.g. partner=acme;
+	// it only applies to ManagedClusterInfo
+	Selector string
+	// Platform, Region, Version, and PowerState filter clusters by their ClusterDeployment
+	// data, so they only apply to CombinedClusterInfo
+	Platform   string
+	Region     string
+	Version    VersionConstraint
+	PowerState string
 }
 
 // ClusterDeploymentInfo contains information from a Hive ClusterDeployment resource
@@ -51,12 +121,66 @@ type ClusterDeploymentInfo struct {
 	KubeconfigSecretName string
 	// KubeconfigSecretNS is the namespace of the kubeconfig secret
 	KubeconfigSecretNS string
+	// AdminPasswordSecretName is the name of the secret holding the kubeadmin credentials, in
+	// the namespace of the ClusterDeployment
+	AdminPasswordSecretName string
 	// Platform is the cloud platform (AWS, Azure, GCP, etc.)
 	Platform string
 	// Region is the cloud region
 	Region string
 	// Version is the OpenShift version
 	Version string
+	// InfraID is the infrastructure ID used to name and tag the cluster's cloud resources
+	InfraID string
+	// RequestID is the partner request the cluster was provisioned for
+	RequestID string
+	// ProvisionFailed indicates Hive reported the ProvisionFailed condition
+	ProvisionFailed bool
+	// ClusterPool is the Hive ClusterPool the cluster was created by, empty for clusters
+	// provisioned directly
+	ClusterPool string
+	// ExpiresAt is the end of the cluster's lease from LeaseExpiryAnnotation, nil without a lease
+	ExpiresAt *time.Time `json:",omitempty"`
+	// InstallStartedAt is when Hive started the first install attempt, nil before
+	InstallStartedAt *time.Time `json:",omitempty"`
+	// InstalledAt is when the install completed, nil until the cluster is installed
+	InstalledAt *time.Time `json:",omitempty"`
+	// ClusterID is the ID of the installed cluster, empty before
+	ClusterID string `json:",omitempty"`
+	// Conditions are the status conditions reported by Hive
+	Conditions []ClusterDeploymentCondition `json:",omitempty"`
+}
+
+// ClusterDeploymentCondition is a status condition of a ClusterDeployment
+type ClusterDeploymentCondition struct {
+	Type               string
+	Status             string
+	Reason             string `json:",omitempty"`
+	Message            string `json:",omitempty"`
+	LastTransitionTime time.Time
+}
+
+// ClusterAgentInfo contains information reported by the klusterlet through the
+// internal.open-cluster-management.io ManagedClusterInfo resource. Unlike ClusterDeployment
+// data, it is available for imported (non-Hive) clusters as well.
+type ClusterAgentInfo struct {
+	// Name is the name of the managed cluster
+	Name string
+	// NodeCount is the number of nodes reported by the cluster
+	NodeCount int
+	// KubernetesVersion is the Kubernetes version of the cluster
+	KubernetesVersion string
+	// OpenShiftVersion is the OpenShift version, empty for non-OpenShift clusters
+	OpenShiftVersion string
+	// CloudVendor is the cloud vendor reported by the klusterlet (Amazon, Azure, Google, ...)
+	CloudVendor string
+	// Region is the region label of the cluster nodes
+	Region string
+	// InstanceTypes counts the nodes of the cluster by their instance type label; nodes
+	// without the label are not counted
+	InstanceTypes map[string]int `json:",omitempty"`
+	// ConsoleURL is the console URL reported by the cluster
+	ConsoleURL string
 }
 
 // CombinedClusterInfo merges information from both ManagedCluster and ClusterDeployment
@@ -83,4 +207,18 @@ type CombinedClusterInfo struct {
 	KubeconfigSecret string
 	// Message provides additional context about the cluster status
 	Message string
+	// NodeCount is the number of nodes from ManagedClusterInfo
+	NodeCount int
+	// KubernetesVersion is the Kubernetes version from ManagedClusterInfo
+	KubernetesVersion string
+	// Cloud is the cloud vendor from ManagedClusterInfo
+	Cloud string
+	// CloudConsoleURL links to the cluster's resources in the cloud provider console
+	CloudConsoleURL string
+	// ClusterSet is the ManagedClusterSet the cluster belongs to from ManagedCluster, empty if none
+	ClusterSet string `json:",omitempty"`
+	// Maintenance indicates the cluster is in maintenance from ManagedCluster
+	Maintenance bool `json:",omitempty"`
+	// Hub is the name of the hub the cluster was listed from, set only for multi-hub queries
+	Hub string `json:",omitempty"`
 }
@@ -0,0 +1,156 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// crdEstablishTimeout bounds how long Apply waits for the kind of an object to be served,
+	// e.g. after its CRD was applied earlier in the same manifests
+	crdEstablishTimeout = time.Minute
+	// crdEstablishInterval is how often the REST mapping of a kind not served yet is retried
+	crdEstablishInterval = 2 * time.Second
+)
+
+// ManifestApplier creates or updates the objects of YAML manifests on a cluster
+type ManifestApplier interface {
+	// Apply creates the objects of multi-document YAML manifests, in order, and updates the
+	// ones that already exist
+	Apply(ctx context.Context, manifests []byte) error
+}
+
+type manifestApplier struct {
+	dynamicClient dynamic.Interface
+	mapper        meta.RESTMapper
+	options       Options
+}
+
+// NewManifestApplier creates a new ManifestApplier. The mapper resolves the resource of each
+// object, e.g. the one returned by Client.GetRESTMapper.
+func NewManifestApplier(dynamicClient dynamic.Interface, mapper meta.RESTMapper, options ...Option) ManifestApplier {
+	return &manifestApplier{
+		dynamicClient: dynamicClient,
+		mapper:        mapper,
+		options:       NewOptions(options...),
+	}
+}
+
+// Apply decodes the manifests and creates or updates each object
+func (a *manifestApplier) Apply(ctx context.Context, manifests []byte) error {
+	ctx, cancel, err := a.options.Start(ctx, "apply manifests")
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	objects, err := DecodeManifests(manifests)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if err := a.applyObject(ctx, obj); err != nil {
+			return fmt.Errorf("failed to apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// applyObject creates obj, or replaces the existing object of the same name
+func (a *manifestApplier) applyObject(ctx context.Context, obj *unstructured.Unstructured) error {
+	mapping, err := a.restMapping(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	var resource dynamic.ResourceInterface = a.dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		resource = a.dynamicClient.Resource(mapping.Resource).Namespace(namespace)
+	}
+
+	_, err = resource.Create(ctx, obj, metav1.CreateOptions{})
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	_, err = resource.Update(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+// restMapping resolves the resource of obj. Kinds that are not served yet are retried after
+// resetting the mapper, as their CRD may have just been created.
+func (a *manifestApplier) restMapping(ctx context.Context, obj *unstructured.Unstructured) (*meta.RESTMapping, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	resettable, ok := a.mapper.(meta.ResettableRESTMapper)
+	if !meta.IsNoMatchError(err) || !ok {
+		return mapping, err
+	}
+
+	pollErr := wait.PollUntilContextTimeout(ctx, crdEstablishInterval, crdEstablishTimeout, false, func(context.Context) (bool, error) {
+		resettable.Reset()
+		mapping, err = a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return true, err
+	})
+	if pollErr != nil && err == nil {
+		err = pollErr
+	}
+	return mapping, err
+}
+
+// DecodeManifests decodes multi-document YAML or JSON manifests into objects, skipping
+// empty documents
+func DecodeManifests(manifests []byte) ([]*unstructured.Unstructured, error) {
+	return decodeManifests(manifests, false)
+}
+
+// decodeManifests decodes manifests like DecodeManifests. With skipNonObjects, documents
+// without apiVersion and kind are skipped instead of failing the decoding.
+func decodeManifests(manifests []byte, skipNonObjects bool) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifests), 4096)
+	var objects []*unstructured.Unstructured
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("failed to decode manifests: %w", err)
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: doc}
+		if skipNonObjects && obj.GetAPIVersion() == "" && obj.GetKind() == "" {
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("failed to decode manifests: object without kind or name")
+		}
+		objects = append(objects, obj)
+	}
+}
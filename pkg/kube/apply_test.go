@@ -0,0 +1,108 @@
+//go:build test
+
+package kube_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+var _ = Describe("ManifestApplier", func() {
+	var (
+		ctx          context.Context
+		namespaceGVR schema.GroupVersionResource
+		configMapGVR schema.GroupVersionResource
+		mapper       *meta.DefaultRESTMapper
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+		configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		mapper = meta.NewDefaultRESTMapper(nil)
+		mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+		mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	})
+
+	manifests := []byte(`---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: open-cluster-management-agent
+---
+# comments and empty documents are skipped
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: open-cluster-management-agent
+data:
+  mode: Default
+`)
+
+	It("should create the objects of the manifests", func() {
+		dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+		Expect(kube.NewManifestApplier(dynamicClient, mapper).Apply(ctx, manifests)).To(Succeed())
+
+		_, err := dynamicClient.Resource(namespaceGVR).Get(ctx, "open-cluster-management-agent", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		cm, err := dynamicClient.Resource(configMapGVR).Namespace("open-cluster-management-agent").Get(ctx, "settings", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Object["data"]).To(HaveKeyWithValue("mode", "Default"))
+	})
+
+	It("should update objects that already exist", func() {
+		existing := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "settings", "namespace": "open-cluster-management-agent"},
+			"data":       map[string]interface{}{"mode": "Hosted"},
+		}}
+		dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), existing)
+
+		Expect(kube.NewManifestApplier(dynamicClient, mapper).Apply(ctx, manifests)).To(Succeed())
+
+		cm, err := dynamicClient.Resource(configMapGVR).Namespace("open-cluster-management-agent").Get(ctx, "settings", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Object["data"]).To(HaveKeyWithValue("mode", "Default"))
+	})
+
+	It("should fail on a kind the cluster does not serve", func() {
+		dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+		err := kube.NewManifestApplier(dynamicClient, mapper).Apply(ctx, []byte(`
+apiVersion: operator.open-cluster-management.io/v1
+kind: Klusterlet
+metadata:
+  name: klusterlet
+`))
+		Expect(err).To(MatchError(ContainSubstring("failed to apply Klusterlet klusterlet")))
+	})
+
+	Describe("DecodeManifests", func() {
+		It("should decode every non-empty document", func() {
+			objects, err := kube.DecodeManifests(manifests)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objects).To(HaveLen(2))
+			Expect(objects[0].GetKind()).To(Equal("Namespace"))
+			Expect(objects[1].GetName()).To(Equal("settings"))
+		})
+
+		It("should reject objects without a kind", func() {
+			_, err := kube.DecodeManifests([]byte("metadata:\n  name: nameless-kind\n"))
+			Expect(err).To(MatchError(ContainSubstring("object without kind or name")))
+		})
+	})
+})
@@ -5,14 +5,29 @@ package kube
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// transportWrapper wraps the transport of every client created after SetTransportWrapper
+var transportWrapper func(http.RoundTripper) http.RoundTripper
+
+// SetTransportWrapper installs fn to wrap the HTTP transport of every client created afterwards,
+// e.g. to instrument API requests. Pass nil to remove it.
+func SetTransportWrapper(fn func(http.RoundTripper) http.RoundTripper) {
+	transportWrapper = fn
+}
+
 // Client provides access to Kubernetes API via dynamic and core clients
 type Client struct {
 	config  *rest.Config
@@ -21,10 +36,22 @@ type Client struct {
 }
 
 // NewClient creates a new Kubernetes client from the specified kubeconfig file
-// If context is empty, the current context from the kubeconfig will be used
-func NewClient(kubeconfigPath string, context string) (*Client, error) {
+// If context is empty, the current context from the kubeconfig will be used.
+// If kubeconfigPath is empty, it is resolved with ResolveKubeconfig, falling back to the
+// in-cluster config when labrat runs in a pod.
+func NewClient(kubeconfigPath string, context string, opts ...Option) (*Client, error) {
 	if kubeconfigPath == "" {
-		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+		kubeconfigPath = ResolveKubeconfig()
+	}
+	if kubeconfigPath == "" {
+		if context != "" {
+			return nil, fmt.Errorf("context %q requires a kubeconfig, none found in $KUBECONFIG or ~/.kube/config", context)
+		}
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("no kubeconfig found in $KUBECONFIG or ~/.kube/config and not running in a cluster: %w", err)
+		}
+		return newClientForConfig(config, NewOptions(opts...))
 	}
 
 	// Check if kubeconfig file exists
@@ -56,6 +83,67 @@ func NewClient(kubeconfigPath string, context string) (*Client, error) {
 		return nil, fmt.Errorf("failed to build client config: %w", err)
 	}
 
+	return newClientForConfig(config, NewOptions(opts...))
+}
+
+// ResolveKubeconfig returns the kubeconfig used when none is configured: the first path of
+// $KUBECONFIG, or ~/.kube/config if it exists. It returns an empty path when neither applies.
+func ResolveKubeconfig() string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		for _, path := range filepath.SplitList(env) {
+			if path != "" {
+				return path
+			}
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(home, ".kube", "config")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// NewClientFromKubeconfig creates a new Kubernetes client from raw kubeconfig content,
+// such as an admin kubeconfig extracted for a spoke cluster. The current context is used.
+func NewClientFromKubeconfig(kubeconfig []byte, opts ...Option) (*Client, error) {
+	if len(kubeconfig) == 0 {
+		return nil, fmt.Errorf("kubeconfig cannot be empty")
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build client config: %w", err)
+	}
+
+	return newClientForConfig(config, NewOptions(opts...))
+}
+
+// newClientForConfig creates the dynamic and core clients for a rest.Config
+func newClientForConfig(config *rest.Config, opts Options) (*Client, error) {
+	if opts.Timeout > 0 {
+		config.Timeout = opts.Timeout
+	}
+	if opts.RateLimiter != nil {
+		config.RateLimiter = opts.RateLimiter
+	}
+	if opts.RequestTimeout > 0 {
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &requestTimeoutTransport{timeout: opts.RequestTimeout, next: rt}
+		})
+	}
+	if opts.Logger != nil {
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &loggingTransport{logger: opts.Logger, next: rt}
+		})
+	}
+	if transportWrapper != nil {
+		config.Wrap(transportWrapper)
+	}
+
 	// Create dynamic client
 	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
@@ -75,12 +163,48 @@ func NewClient(kubeconfigPath string, context string) (*Client, error) {
 	}, nil
 }
 
+// NewClientForInterfaces creates a Client of existing dynamic and core clients, e.g. clients
+// served in memory, whose Host is host
+func NewClientForInterfaces(host string, dynamicClient dynamic.Interface, coreClient kubernetes.Interface) *Client {
+	return &Client{
+		config:  &rest.Config{Host: host},
+		dynamic: dynamicClient,
+		core:    coreClient,
+	}
+}
+
 // GetDynamicClient returns the dynamic client interface for accessing Kubernetes resources
 func (c *Client) GetDynamicClient() dynamic.Interface {
 	return c.dynamic
 }
 
+// Host returns the URL of the API server the client connects to
+func (c *Client) Host() string {
+	return c.config.Host
+}
+
 // GetCoreClient returns the core Kubernetes client interface for accessing standard resources
 func (c *Client) GetCoreClient() kubernetes.Interface {
 	return c.core
 }
+
+// GetRESTMapper returns a REST mapper that resolves kinds to resources through API discovery.
+// Discovery results are cached until the mapper is reset.
+func (c *Client) GetRESTMapper() meta.ResettableRESTMapper {
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(c.core.Discovery()))
+}
+
+// Contexts returns the context names of a kubeconfig file, sorted, and its current context
+func Contexts(kubeconfigPath string) ([]string, string, error) {
+	kubeconfig, err := clientcmd.LoadFromFile(kubeconfigPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	names := make([]string, 0, len(kubeconfig.Contexts))
+	for name := range kubeconfig.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, kubeconfig.CurrentContext, nil
+}
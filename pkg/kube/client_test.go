@@ -108,11 +108,81 @@ users:
 		})
 
 		Context("with empty kubeconfig path", func() {
-			It("should return an error", func() {
+			BeforeEach(func() {
+				setEnv := func(key, value string) {
+					previous, set := os.LookupEnv(key)
+					Expect(os.Setenv(key, value)).To(Succeed())
+					DeferCleanup(func() {
+						if set {
+							os.Setenv(key, previous)
+						} else {
+							os.Unsetenv(key)
+						}
+					})
+				}
+				setEnv("KUBECONFIG", "")
+				setEnv("HOME", tempDir)
+				setEnv("KUBERNETES_SERVICE_HOST", "")
+			})
+
+			It("should return an error outside a cluster without a kubeconfig", func() {
 				client, err := kube.NewClient("", "")
 				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("not running in a cluster"))
 				Expect(client).To(BeNil())
 			})
+
+			It("should use the first path of $KUBECONFIG", func() {
+				os.Setenv("KUBECONFIG", validKubeconfig+string(os.PathListSeparator)+"/other/kubeconfig")
+				Expect(kube.ResolveKubeconfig()).To(Equal(validKubeconfig))
+
+				client, err := kube.NewClient("", "another-context")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client).NotTo(BeNil())
+			})
+
+			It("should use ~/.kube/config when it exists", func() {
+				Expect(kube.ResolveKubeconfig()).To(BeEmpty())
+
+				kubeDir := filepath.Join(tempDir, ".kube")
+				Expect(os.MkdirAll(kubeDir, 0700)).To(Succeed())
+				Expect(os.Rename(validKubeconfig, filepath.Join(kubeDir, "config"))).To(Succeed())
+				Expect(kube.ResolveKubeconfig()).To(Equal(filepath.Join(kubeDir, "config")))
+
+				client, err := kube.NewClient("", "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client).NotTo(BeNil())
+			})
+
+			It("should reject a context without a kubeconfig", func() {
+				_, err := kube.NewClient("", "test-context")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("requires a kubeconfig"))
+			})
+		})
+	})
+
+	Describe("NewClientFromKubeconfig", func() {
+		It("should create a client from kubeconfig content", func() {
+			content, err := os.ReadFile(validKubeconfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			client, err := kube.NewClientFromKubeconfig(content)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.GetDynamicClient()).NotTo(BeNil())
+			Expect(client.GetCoreClient()).NotTo(BeNil())
+		})
+
+		It("should return an error for empty content", func() {
+			client, err := kube.NewClientFromKubeconfig(nil)
+			Expect(err).To(HaveOccurred())
+			Expect(client).To(BeNil())
+		})
+
+		It("should return an error for malformed content", func() {
+			client, err := kube.NewClientFromKubeconfig([]byte("invalid: yaml: content: ["))
+			Expect(err).To(HaveOccurred())
+			Expect(client).To(BeNil())
 		})
 	})
 
@@ -125,4 +195,18 @@ users:
 			Expect(dynamicClient).NotTo(BeNil())
 		})
 	})
+
+	Describe("Contexts", func() {
+		It("should list the contexts sorted with the current context", func() {
+			names, current, err := kube.Contexts(validKubeconfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"another-context", "test-context"}))
+			Expect(current).To(Equal("test-context"))
+		})
+
+		It("should return an error for a missing kubeconfig", func() {
+			_, _, err := kube.Contexts(filepath.Join(tempDir, "missing"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
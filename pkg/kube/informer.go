@@ -0,0 +1,152 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultResyncPeriod is how often an InformerCache redelivers every cached object to its
+// event handlers as an update, so handlers that missed a change catch up
+const DefaultResyncPeriod = 10 * time.Minute
+
+// InformerCache keeps in-memory copies of resources of a cluster, kept current by shared
+// informers that list each resource once and then watch it. Long-running commands read from
+// it instead of listing the resources again and again. The returned objects are shared by
+// every reader and must not be modified.
+type InformerCache interface {
+	// Start starts the informers; they stop when ctx is done
+	Start(ctx context.Context)
+	// WaitForSync waits until every informer has listed its resource, or fails when ctx is done first
+	WaitForSync(ctx context.Context) error
+	// List returns the cached objects of a resource
+	List(gvr schema.GroupVersionResource) ([]*unstructured.Unstructured, error)
+	// Get returns a cached object of a resource; namespace is empty for cluster-scoped resources
+	Get(gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error)
+	// AddEventHandler calls handler for every cached object of a resource and every later
+	// change of the resource, until remove is called
+	AddEventHandler(gvr schema.GroupVersionResource, handler cache.ResourceEventHandler) (remove func(), err error)
+}
+
+type informerCache struct {
+	factory   dynamicinformer.DynamicSharedInformerFactory
+	informers map[schema.GroupVersionResource]informers.GenericInformer
+	options   Options
+}
+
+// NewInformerCache creates an InformerCache of the resources gvrs, redelivering the cached
+// objects to event handlers every resync; zero disables resyncs. Failed watches are retried
+// by the informers and logged as warnings.
+func NewInformerCache(dynamicClient dynamic.Interface, resync time.Duration, gvrs []schema.GroupVersionResource, options ...Option) InformerCache {
+	c := &informerCache{
+		factory:   dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, resync),
+		informers: make(map[schema.GroupVersionResource]informers.GenericInformer, len(gvrs)),
+		options:   NewOptions(options...),
+	}
+	for _, gvr := range gvrs {
+		informer := c.factory.ForResource(gvr)
+		// The default handler logs through klog, which would write over the terminal UI
+		_ = informer.Informer().SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+			if c.options.Logger != nil {
+				c.options.Logger.Warn("watch of cached resource failed, retrying", "resource", gvr.Resource, "error", err)
+			}
+		})
+		c.informers[gvr] = informer
+	}
+	return c
+}
+
+// Start starts the informers of every resource
+func (c *informerCache) Start(ctx context.Context) {
+	if c.options.Logger != nil {
+		c.options.Logger.DebugContext(ctx, "start informers", "resources", len(c.informers))
+	}
+	c.factory.Start(ctx.Done())
+}
+
+// WaitForSync waits for the initial list of every informer and names the resources that
+// were not listed in time
+func (c *informerCache) WaitForSync(ctx context.Context) error {
+	var unsynced []string
+	for gvr, synced := range c.factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			unsynced = append(unsynced, gvr.Resource)
+		}
+	}
+	if len(unsynced) > 0 {
+		sort.Strings(unsynced)
+		return fmt.Errorf("failed to sync the cache of %s: %w", strings.Join(unsynced, ", "), context.Cause(ctx))
+	}
+	return nil
+}
+
+// List returns the objects in the store of the informer of gvr
+func (c *informerCache) List(gvr schema.GroupVersionResource) ([]*unstructured.Unstructured, error) {
+	informer, err := c.informer(gvr)
+	if err != nil {
+		return nil, err
+	}
+	items := informer.Informer().GetStore().List()
+	objects := make([]*unstructured.Unstructured, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(*unstructured.Unstructured); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// Get returns an object from the store of the informer of gvr, or a NotFound error like the API server
+func (c *informerCache) Get(gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	informer, err := c.informer(gvr)
+	if err != nil {
+		return nil, err
+	}
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	item, exists, err := informer.Informer().GetStore().GetByKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the cache of %s: %w", gvr.Resource, err)
+	}
+	obj, ok := item.(*unstructured.Unstructured)
+	if !exists || !ok {
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+	}
+	return obj, nil
+}
+
+// AddEventHandler registers handler with the informer of gvr
+func (c *informerCache) AddEventHandler(gvr schema.GroupVersionResource, handler cache.ResourceEventHandler) (func(), error) {
+	informer, err := c.informer(gvr)
+	if err != nil {
+		return nil, err
+	}
+	registration, err := informer.Informer().AddEventHandler(handler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch the cache of %s: %w", gvr.Resource, err)
+	}
+	return func() {
+		_ = informer.Informer().RemoveEventHandler(registration)
+	}, nil
+}
+
+// informer returns the informer of gvr, which must be one of the resources of the cache
+func (c *informerCache) informer(gvr schema.GroupVersionResource) (informers.GenericInformer, error) {
+	informer, ok := c.informers[gvr]
+	if !ok {
+		return nil, fmt.Errorf("%s are not cached", gvr.Resource)
+	}
+	return informer, nil
+}
@@ -0,0 +1,140 @@
+//go:build test
+
+package kube_test
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+var _ = Describe("InformerCache", func() {
+	var (
+		ctx           context.Context
+		cancel        context.CancelFunc
+		fakeDynamic   *fake.FakeDynamicClient
+		informerCache kube.InformerCache
+		clusterGVR    schema.GroupVersionResource
+		deploymentGVR schema.GroupVersionResource
+	)
+
+	newObject := func(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		return obj
+	}
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		clusterGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		deploymentGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+		fakeDynamic = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				clusterGVR:    "ManagedClusterList",
+				deploymentGVR: "ClusterDeploymentList",
+			},
+			newObject("cluster.open-cluster-management.io/v1", "ManagedCluster", "", "cluster-a"),
+			newObject("hive.openshift.io/v1", "ClusterDeployment", "cluster-a", "cluster-a"),
+		)
+		informerCache = kube.NewInformerCache(fakeDynamic, kube.DefaultResyncPeriod, []schema.GroupVersionResource{clusterGVR, deploymentGVR})
+		informerCache.Start(ctx)
+		Expect(informerCache.WaitForSync(ctx)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should list and get the cached objects", func() {
+		clusters, err := informerCache.List(clusterGVR)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clusters).To(HaveLen(1))
+		Expect(clusters[0].GetName()).To(Equal("cluster-a"))
+
+		deployment, err := informerCache.Get(deploymentGVR, "cluster-a", "cluster-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployment.GetKind()).To(Equal("ClusterDeployment"))
+
+		cluster, err := informerCache.Get(clusterGVR, "", "cluster-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.GetName()).To(Equal("cluster-a"))
+	})
+
+	It("should report missing objects as not found", func() {
+		_, err := informerCache.Get(deploymentGVR, "cluster-b", "cluster-b")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(err).To(MatchError(`clusterdeployments.hive.openshift.io "cluster-b" not found`))
+	})
+
+	It("should follow changes of the resources", func() {
+		_, err := fakeDynamic.Resource(clusterGVR).Create(ctx,
+			newObject("cluster.open-cluster-management.io/v1", "ManagedCluster", "", "cluster-b"), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() ([]*unstructured.Unstructured, error) {
+			return informerCache.List(clusterGVR)
+		}).Should(HaveLen(2))
+	})
+
+	It("should send the cached objects and their changes to event handlers until removed", func() {
+		var (
+			mu     sync.Mutex
+			events []string
+		)
+		record := func(event string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}
+		recorded := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string(nil), events...)
+		}
+
+		remove, err := informerCache.AddEventHandler(clusterGVR, cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				record("added " + obj.(*unstructured.Unstructured).GetName())
+			},
+			DeleteFunc: func(obj interface{}) {
+				record("deleted " + obj.(*unstructured.Unstructured).GetName())
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(recorded).Should(Equal([]string{"added cluster-a"}))
+
+		Expect(fakeDynamic.Resource(clusterGVR).Delete(ctx, "cluster-a", metav1.DeleteOptions{})).To(Succeed())
+		Eventually(recorded).Should(Equal([]string{"added cluster-a", "deleted cluster-a"}))
+
+		remove()
+		_, err = fakeDynamic.Resource(clusterGVR).Create(ctx,
+			newObject("cluster.open-cluster-management.io/v1", "ManagedCluster", "", "cluster-b"), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() ([]*unstructured.Unstructured, error) {
+			return informerCache.List(clusterGVR)
+		}).Should(HaveLen(1))
+		Consistently(recorded, "100ms").Should(HaveLen(2))
+	})
+
+	It("should reject resources that are not cached", func() {
+		secretGVR := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+		_, err := informerCache.List(secretGVR)
+		Expect(err).To(MatchError("secrets are not cached"))
+		_, err = informerCache.AddEventHandler(secretGVR, cache.ResourceEventHandlerFuncs{})
+		Expect(err).To(MatchError("secrets are not cached"))
+	})
+})
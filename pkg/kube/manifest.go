@@ -0,0 +1,67 @@
+package kube
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Backend creates the Clients of the hubs labrat manages
+type Backend interface {
+	// NewClient creates a Client of the cluster of a kubeconfig and context
+	NewClient(kubeconfigPath, context string, opts ...Option) (*Client, error)
+}
+
+// APIBackend connects to the API server of the kubeconfig with NewClient
+type APIBackend struct{}
+
+// NewClient creates a Client of the API server of a kubeconfig and context
+func (APIBackend) NewClient(kubeconfigPath, context string, opts ...Option) (*Client, error) {
+	return NewClient(kubeconfigPath, context, opts...)
+}
+
+// LoadManifestDir decodes the objects of the YAML and JSON files in dir and its
+// subdirectories, in lexical order, skipping documents that are not Kubernetes objects
+func LoadManifestDir(dir string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		fileObjects, err := LoadManifestFile(path)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, fileObjects...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifests from %s: %w", dir, err)
+	}
+	return objects, nil
+}
+
+// LoadManifestFile decodes the objects of a YAML or JSON file, skipping documents that are
+// not Kubernetes objects
+func LoadManifestFile(path string) ([]*unstructured.Unstructured, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	objects, err := decodeManifests(data, true)
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %w", path, err)
+	}
+	return objects, nil
+}
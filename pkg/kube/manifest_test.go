@@ -0,0 +1,41 @@
+//go:build test
+
+package kube_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+var _ = Describe("Manifest files", func() {
+	Describe("LoadManifestDir", func() {
+		It("should load the objects of the fixtures and skip other documents", func() {
+			objects, err := kube.LoadManifestDir("../../test/fixtures")
+			Expect(err).NotTo(HaveOccurred())
+
+			var kinds []string
+			for _, obj := range objects {
+				kinds = append(kinds, obj.GetKind()+"/"+obj.GetName())
+			}
+			Expect(kinds).To(ConsistOf(
+				"ClusterDeployment/test-cluster-hibernating",
+				"ClusterDeployment/test-cluster-running",
+				"ManagedCluster/cluster-notready",
+				"ManagedCluster/cluster-ready",
+			))
+		})
+
+		It("should report the file of a malformed document", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("kind: [\n"), 0600)).To(Succeed())
+
+			_, err := kube.LoadManifestDir(dir)
+			Expect(err).To(MatchError(ContainSubstring("broken.yaml")))
+		})
+	})
+})
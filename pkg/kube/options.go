@@ -0,0 +1,187 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/retry"
+)
+
+// DefaultRequestTimeout is the request timeout labrat gives its clients, so an API server
+// that stops answering fails the request instead of hanging the command
+const DefaultRequestTimeout = time.Minute
+
+// Option configures a Client, or a hub or spoke client built on one. The same options are
+// accepted by the constructors of pkg/kube, pkg/hub, and pkg/spoke, so labrat can be embedded
+// as a library with the caller's logging, timeouts, and API budget.
+type Option func(*Options)
+
+// Options holds the settings applied by Option values. The zero value applies no timeout,
+// no rate limit, and no retries, and discards logs.
+type Options struct {
+	// Timeout bounds each API request of a Client, and each operation of a hub or spoke client
+	Timeout time.Duration
+	// RequestTimeout bounds each API request of a Client except watches and streams, such as
+	// followed logs and exec sessions, which run as long as their context allows
+	RequestTimeout time.Duration
+	// Logger receives debug logs of API requests and operations
+	Logger *slog.Logger
+	// RateLimiter is waited on before each API request of a Client, and each operation of a
+	// hub or spoke client
+	RateLimiter flowcontrol.RateLimiter
+	// Retries is the number of times hub and spoke clients retry an API call that failed
+	// transiently, e.g. because the API server timed out or was unavailable
+	Retries int
+	// RetryBackoff is the delay before the first retry, doubled for each further retry
+	RetryBackoff time.Duration
+}
+
+// WithTimeout bounds API requests and operations to d
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// WithRequestTimeout bounds API requests to d, leaving watches and streams unbounded.
+// Unlike WithTimeout it does not limit operations, which may wait far longer than any
+// single request.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.RequestTimeout = d
+	}
+}
+
+// WithLogger sends debug logs of API requests and operations to logger
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// WithRateLimiter limits the rate of API requests and operations, e.g. with
+// flowcontrol.NewTokenBucketRateLimiter
+func WithRateLimiter(limiter flowcontrol.RateLimiter) Option {
+	return func(o *Options) {
+		o.RateLimiter = limiter
+	}
+}
+
+// WithRetry retries API calls of hub and spoke clients that fail transiently up to retries
+// times, waiting backoff before the first retry and twice as long before each further one.
+// A zero backoff uses retry.DefaultBackoff.
+func WithRetry(retries int, backoff time.Duration) Option {
+	return func(o *Options) {
+		o.Retries = retries
+		o.RetryBackoff = backoff
+	}
+}
+
+// NewOptions applies opts to the zero Options
+func NewOptions(opts ...Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Start begins an operation of a hub or spoke client: it waits for the rate limiter, applies
+// the timeout to ctx, and logs the operation. The returned cancel function must be called
+// when the operation ends. If ctx is done before the rate limiter allows the operation, or
+// would be by then, Start fails and the operation must not run.
+func (o Options) Start(ctx context.Context, operation string, args ...any) (context.Context, context.CancelFunc, error) {
+	if o.RateLimiter != nil {
+		if err := o.RateLimiter.Wait(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to %s: %w", operation, err)
+		}
+	}
+	if o.Logger != nil {
+		o.Logger.DebugContext(ctx, operation, args...)
+	}
+	if o.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, cancel, nil
+}
+
+// Retry calls fn, retrying it with backoff while it fails transiently as configured by
+// WithRetry. Retries are logged at info level.
+func (o Options) Retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	policy := retry.Policy{Retries: o.Retries, Backoff: o.RetryBackoff}
+	if o.Logger != nil {
+		policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+			o.Logger.InfoContext(ctx, "API call failed, retrying", "attempt", attempt, "delay", delay, "error", err)
+		}
+	}
+	return policy.Do(ctx, fn)
+}
+
+// loggingTransport logs every API request sent through it at debug level
+type loggingTransport struct {
+	logger *slog.Logger
+	next   http.RoundTripper
+}
+
+// RoundTrip sends the request and logs its method, URL, status, and duration
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	attrs := []any{"method", req.Method, "url", req.URL.String(), "duration", time.Since(start)}
+	if err != nil {
+		t.logger.DebugContext(req.Context(), "API request failed", append(attrs, "error", err)...)
+		return resp, err
+	}
+	t.logger.DebugContext(req.Context(), "API request", append(attrs, "status", resp.StatusCode)...)
+	return resp, err
+}
+
+// requestTimeoutTransport bounds every request sent through it except watches and streams
+type requestTimeoutTransport struct {
+	timeout time.Duration
+	next    http.RoundTripper
+}
+
+// RoundTrip sends the request with a deadline that lasts until its response body is closed
+func (t *requestTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isStreamingRequest(req) {
+		return t.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// isStreamingRequest reports whether req watches a resource, follows a log, or upgrades the
+// connection for exec, attach, or port forwarding
+func isStreamingRequest(req *http.Request) bool {
+	query := req.URL.Query()
+	return query.Get("watch") == "true" || query.Get("watch") == "1" || query.Get("follow") == "true" ||
+		req.Header.Get("Upgrade") != ""
+}
+
+// cancelOnCloseBody releases the deadline of a request when its response body is closed
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels its request context
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,180 @@
+//go:build test
+
+package kube_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+var _ = Describe("Options", func() {
+	It("should apply options in order", func() {
+		limiter := flowcontrol.NewFakeAlwaysRateLimiter()
+		opts := kube.NewOptions(kube.WithTimeout(time.Second), kube.WithRateLimiter(limiter), kube.WithTimeout(time.Minute))
+		Expect(opts.Timeout).To(Equal(time.Minute))
+		Expect(opts.RateLimiter).To(BeIdenticalTo(limiter))
+		Expect(opts.Logger).To(BeNil())
+	})
+
+	Describe("Start", func() {
+		It("should apply the timeout to the context", func() {
+			ctx, cancel, err := kube.NewOptions(kube.WithTimeout(time.Minute)).Start(context.Background(), "list clusters")
+			Expect(err).NotTo(HaveOccurred())
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(time.Until(deadline)).To(BeNumerically("~", time.Minute, time.Second))
+		})
+
+		It("should not set a deadline without a timeout", func() {
+			ctx, cancel, err := kube.NewOptions().Start(context.Background(), "list clusters")
+			Expect(err).NotTo(HaveOccurred())
+			defer cancel()
+
+			_, ok := ctx.Deadline()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should wait for the rate limiter and log the operation", func() {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			limiter := flowcontrol.NewFakeAlwaysRateLimiter()
+
+			_, cancel, err := kube.NewOptions(kube.WithRateLimiter(limiter), kube.WithLogger(logger)).
+				Start(context.Background(), "get cluster", "name", "spoke-1")
+			Expect(err).NotTo(HaveOccurred())
+			defer cancel()
+
+			Expect(logs.String()).To(ContainSubstring(`msg="get cluster" name=spoke-1`))
+		})
+
+		It("should fail when the rate limiter does not allow the operation", func() {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			limiter := flowcontrol.NewFakeNeverRateLimiter()
+
+			_, _, err := kube.NewOptions(kube.WithRateLimiter(limiter), kube.WithLogger(logger)).
+				Start(context.Background(), "get cluster", "name", "spoke-1")
+			Expect(err).To(MatchError("failed to get cluster: can not be accept"))
+			Expect(logs.String()).To(BeEmpty())
+		})
+	})
+
+	Describe("Retry", func() {
+		It("should retry transient failures and log each retry", func() {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo}))
+			opts := kube.NewOptions(kube.WithRetry(2, time.Millisecond), kube.WithLogger(logger))
+			Expect(opts.Retries).To(Equal(2))
+			Expect(opts.RetryBackoff).To(Equal(time.Millisecond))
+
+			calls := 0
+			err := opts.Retry(context.Background(), func(context.Context) error {
+				calls++
+				return apierrors.NewServiceUnavailable("API server restarting")
+			})
+			Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+			Expect(calls).To(Equal(3))
+			Expect(logs.String()).To(ContainSubstring(`msg="API call failed, retrying" attempt=2`))
+		})
+
+		It("should make a single attempt without WithRetry", func() {
+			calls := 0
+			err := kube.NewOptions().Retry(context.Background(), func(context.Context) error {
+				calls++
+				return apierrors.NewServiceUnavailable("API server restarting")
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(calls).To(Equal(1))
+		})
+	})
+
+	Describe("NewClientFromKubeconfig", func() {
+		It("should log API requests with WithLogger", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"major":"1","minor":"31","gitVersion":"v1.31.0"}`)
+			}))
+			defer server.Close()
+
+			kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: %s
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: test-context
+current-context: test-context
+users:
+- name: test-user
+  user:
+    token: test-token
+`, server.URL)
+
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			client, err := kube.NewClientFromKubeconfig([]byte(kubeconfig), kube.WithLogger(logger), kube.WithTimeout(10*time.Second))
+			Expect(err).NotTo(HaveOccurred())
+
+			version, err := client.GetCoreClient().Discovery().ServerVersion()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version.GitVersion).To(Equal("v1.31.0"))
+			Expect(logs.String()).To(ContainSubstring(`msg="API request" method=GET`))
+			Expect(logs.String()).To(ContainSubstring("status=200"))
+		})
+
+		It("should bound requests but not watches with WithRequestTimeout", func() {
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("watch") != "true" {
+					<-release
+				}
+				time.Sleep(200 * time.Millisecond)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"kind":"ConfigMapList","apiVersion":"v1","metadata":{},"items":[]}`)
+			}))
+			defer server.Close()
+			defer close(release)
+
+			client, err := kube.NewClientFromKubeconfig([]byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: %s
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+  name: test-context
+current-context: test-context
+`, server.URL)), kube.WithRequestTimeout(100*time.Millisecond))
+			Expect(err).NotTo(HaveOccurred())
+			configMaps := client.GetCoreClient().CoreV1().ConfigMaps("default")
+
+			_, err = configMaps.List(context.Background(), metav1.ListOptions{})
+			Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+
+			watcher, err := configMaps.Watch(context.Background(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			watcher.Stop()
+		})
+	})
+})
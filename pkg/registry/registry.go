@@ -0,0 +1,257 @@
+// Package registry checks container images against their registries using OpenShift pull secrets
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnauthorized is returned when the registry rejects the pull secret
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound is returned when the image does not exist in the registry
+	ErrNotFound = errors.New("not found")
+)
+
+// manifestMediaTypes are accepted when checking a manifest; release images are manifest lists
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// PullSecret is a parsed .dockerconfigjson pull secret
+type PullSecret struct {
+	Auths map[string]AuthEntry `json:"auths"`
+}
+
+// AuthEntry holds the credentials for one registry
+type AuthEntry struct {
+	// Auth is base64(username:password)
+	Auth string `json:"auth"`
+}
+
+// ParsePullSecret parses a .dockerconfigjson pull secret
+func ParsePullSecret(data []byte) (*PullSecret, error) {
+	var secret PullSecret
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return nil, fmt.Errorf("failed to parse pull secret: %w", err)
+	}
+	if len(secret.Auths) == 0 {
+		return nil, fmt.Errorf("pull secret has no auths")
+	}
+	return &secret, nil
+}
+
+// basicAuth returns the credentials for host, if the pull secret has any
+func (p *PullSecret) basicAuth(host string) (string, string, bool) {
+	if p == nil {
+		return "", "", false
+	}
+	entry, ok := p.Auths[host]
+	if !ok {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+// Reference is a parsed image reference
+type Reference struct {
+	// Registry is the registry host
+	Registry string
+	// Repository is the repository path within the registry
+	Repository string
+	// Reference is the tag or digest
+	Reference string
+}
+
+// ParseReference parses an image reference of the form registry/repository[:tag|@digest].
+// The registry must be explicit, as it always is for OpenShift release images.
+func ParseReference(image string) (Reference, error) {
+	host, rest, ok := strings.Cut(image, "/")
+	if !ok || !strings.ContainsAny(host, ".:") {
+		return Reference{}, fmt.Errorf("image %q must include a registry host", image)
+	}
+
+	ref := Reference{Registry: host, Reference: "latest"}
+	if repo, digest, ok := strings.Cut(rest, "@"); ok {
+		ref.Repository, ref.Reference = repo, digest
+	} else if i := strings.LastIndex(rest, ":"); i > 0 {
+		ref.Repository, ref.Reference = rest[:i], rest[i+1:]
+	} else {
+		ref.Repository = rest
+	}
+
+	if ref.Repository == "" || ref.Reference == "" {
+		return Reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+	return ref, nil
+}
+
+// Client checks images against their registries
+type Client interface {
+	// Head confirms image can be pulled and returns its digest
+	Head(ctx context.Context, image string) (string, error)
+}
+
+type client struct {
+	pullSecret *PullSecret
+	httpClient *http.Client
+}
+
+// NewClient creates a new Client that authenticates with pullSecret. If httpClient is nil a
+// client with a 30 second timeout is used.
+func NewClient(pullSecret *PullSecret, httpClient *http.Client) Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &client{
+		pullSecret: pullSecret,
+		httpClient: httpClient,
+	}
+}
+
+// Head sends a HEAD request for the image manifest, following the registry's auth challenge:
+// 1. Request the manifest anonymously
+// 2. On 401, obtain a bearer token from the challenge realm using the pull secret (or retry with basic auth)
+// 3. Request the manifest again with the token
+func (c *client) Head(ctx context.Context, image string) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, ref.Reference)
+
+	resp, err := c.headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		authorization, err := c.authorize(ctx, ref, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", err
+		}
+		resp, err = c.headManifest(ctx, manifestURL, authorization)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get("Docker-Content-Digest"), nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", fmt.Errorf("%w: %s rejected the pull secret for %s", ErrUnauthorized, ref.Registry, ref.Repository)
+	case http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrNotFound, image)
+	default:
+		return "", fmt.Errorf("HEAD %s returned %s", manifestURL, resp.Status)
+	}
+}
+
+// headManifest sends a HEAD request for a manifest with an optional Authorization header
+func (c *client) headManifest(ctx context.Context, manifestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HEAD %s: %w", manifestURL, err)
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// authorize answers a WWW-Authenticate challenge and returns the Authorization header to retry with
+func (c *client) authorize(ctx context.Context, ref Reference, challenge string) (string, error) {
+	username, password, hasCreds := c.pullSecret.basicAuth(ref.Registry)
+
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if !hasCreds {
+			return "", fmt.Errorf("%w: pull secret has no credentials for %s", ErrUnauthorized, ref.Registry)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("unsupported auth challenge from %s: %q", ref.Registry, challenge)
+	}
+
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil || tokenURL.Host == "" {
+		return "", fmt.Errorf("invalid token realm in challenge from %s: %q", ref.Registry, challenge)
+	}
+	query := tokenURL.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.Repository))
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	if hasCreds {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get registry token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return "", fmt.Errorf("%w: %s rejected the pull secret (it may have expired)", ErrUnauthorized, ref.Registry)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request to %s returned %s", tokenURL.Host, resp.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode registry token: %w", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return "Bearer " + token.Token, nil
+}
+
+// parseChallenge splits a WWW-Authenticate header into its lowercased scheme and parameters
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := make(map[string]string)
+	for _, part := range strings.Split(rest, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			params[strings.ToLower(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return strings.ToLower(scheme), params
+}
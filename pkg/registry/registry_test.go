@@ -0,0 +1,130 @@
+//go:build test
+
+package registry_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/registry"
+)
+
+var _ = Describe("ParseReference", func() {
+	It("should parse tags", func() {
+		ref, err := registry.ParseReference("quay.io/openshift-release-dev/ocp-release:4.16.12-x86_64")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref).To(Equal(registry.Reference{
+			Registry:   "quay.io",
+			Repository: "openshift-release-dev/ocp-release",
+			Reference:  "4.16.12-x86_64",
+		}))
+	})
+
+	It("should parse digests and registry ports", func() {
+		ref, err := registry.ParseReference("mirror.local:5000/ocp/release@sha256:abc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref.Registry).To(Equal("mirror.local:5000"))
+		Expect(ref.Repository).To(Equal("ocp/release"))
+		Expect(ref.Reference).To(Equal("sha256:abc"))
+	})
+
+	It("should require a registry host", func() {
+		_, err := registry.ParseReference("openshift/release:4.16")
+		Expect(err).To(MatchError(ContainSubstring("must include a registry host")))
+	})
+})
+
+var _ = Describe("Client", func() {
+	const (
+		repository = "openshift-release-dev/ocp-release"
+		tag        = "4.16.12-x86_64"
+		digest     = "sha256:0123456789abcdef"
+		token      = "registry-token"
+	)
+
+	var (
+		server     *httptest.Server
+		host       string
+		pullSecret *registry.PullSecret
+		tokenAuth  string
+	)
+
+	BeforeEach(func() {
+		tokenAuth = ""
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v2/auth", func(w http.ResponseWriter, r *http.Request) {
+			tokenAuth = r.Header.Get("Authorization")
+			if r.URL.Query().Get("scope") != fmt.Sprintf("repository:%s:pull", repository) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "labrat" || pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"token":%q}`, token)
+		})
+		mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodHead))
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="https://%s/v2/auth",service="test-registry"`, r.Host))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Path != fmt.Sprintf("/v2/%s/manifests/%s", repository, tag) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+		})
+		server = httptest.NewTLSServer(mux)
+		host = strings.TrimPrefix(server.URL, "https://")
+
+		var err error
+		pullSecret, err = registry.ParsePullSecret([]byte(fmt.Sprintf(`{"auths":{%q:{"auth":%q}}}`,
+			host, base64.StdEncoding.EncodeToString([]byte("labrat:secret")))))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should return the digest of a pullable image", func() {
+		client := registry.NewClient(pullSecret, server.Client())
+
+		got, err := client.Head(context.Background(), fmt.Sprintf("%s/%s:%s", host, repository, tag))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(digest))
+		Expect(tokenAuth).To(HavePrefix("Basic "))
+	})
+
+	It("should report images that do not exist", func() {
+		client := registry.NewClient(pullSecret, server.Client())
+
+		_, err := client.Head(context.Background(), fmt.Sprintf("%s/%s:4.16.99-x86_64", host, repository))
+		Expect(err).To(MatchError(registry.ErrNotFound))
+	})
+
+	It("should report pull secrets rejected by the registry", func() {
+		expired, err := registry.ParsePullSecret([]byte(fmt.Sprintf(`{"auths":{%q:{"auth":%q}}}`,
+			host, base64.StdEncoding.EncodeToString([]byte("labrat:expired")))))
+		Expect(err).NotTo(HaveOccurred())
+		client := registry.NewClient(expired, server.Client())
+
+		_, err = client.Head(context.Background(), fmt.Sprintf("%s/%s:%s", host, repository, tag))
+		Expect(err).To(MatchError(registry.ErrUnauthorized))
+	})
+
+	It("should reject pull secrets without auths", func() {
+		_, err := registry.ParsePullSecret([]byte(`{"auths":{}}`))
+		Expect(err).To(MatchError("pull secret has no auths"))
+	})
+})
@@ -0,0 +1,117 @@
+// Package release resolves OpenShift releases with the public release controller of OpenShift
+// CI, e.g. the latest stable 4.16 release image to offer in a ClusterImageSet
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultArchitecture is the architecture releases are resolved for by default
+const DefaultArchitecture = "amd64"
+
+// minorVersionPattern matches an OpenShift minor version such as 4.16
+var minorVersionPattern = regexp.MustCompile(`^4\.(\d+)$`)
+
+// Release is an OpenShift release published by the release controller
+type Release struct {
+	// Version is the release version, e.g. 4.16.12
+	Version string `json:"name"`
+	// PullSpec is the release image, e.g. quay.io/openshift-release-dev/ocp-release:4.16.12-x86_64
+	PullSpec string `json:"pullSpec"`
+}
+
+// ControllerURL returns the URL of the release controller of arch, e.g. amd64 or arm64
+func ControllerURL(arch string) string {
+	return fmt.Sprintf("https://%s.ocp.releases.ci.openshift.org", arch)
+}
+
+// Client looks up releases on a release controller
+type Client interface {
+	// LatestStable returns the newest stable release of a minor version, e.g. 4.16
+	LatestStable(ctx context.Context, minor string) (*Release, error)
+}
+
+type client struct {
+	baseURL    string
+	arch       string
+	httpClient *http.Client
+}
+
+// NewClient creates a Client for the releases of arch on the release controller at baseURL,
+// ControllerURL(arch) if empty. If httpClient is nil, requests time out after 30 seconds.
+func NewClient(baseURL, arch string, httpClient *http.Client) Client {
+	if arch == "" {
+		arch = DefaultArchitecture
+	}
+	if baseURL == "" {
+		baseURL = ControllerURL(arch)
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &client{baseURL: strings.TrimSuffix(baseURL, "/"), arch: arch, httpClient: httpClient}
+}
+
+// LatestStable asks the release controller for the latest release of the stable stream within
+// the minor version
+func (c *client) LatestStable(ctx context.Context, minor string) (*Release, error) {
+	match := minorVersionPattern.FindStringSubmatch(minor)
+	if match == nil {
+		return nil, fmt.Errorf("invalid minor version %q: want 4.x, e.g. 4.16", minor)
+	}
+	next, _ := strconv.Atoi(match[1])
+
+	stream := "4-stable"
+	if c.arch != DefaultArchitecture {
+		stream += "-" + c.arch
+	}
+	query := url.Values{"in": {fmt.Sprintf(">%s.0-0 <4.%d.0-0", minor, next+1)}}
+	endpoint := fmt.Sprintf("%s/api/v1/releasestream/%s/latest?%s", c.baseURL, stream, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the release controller: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no stable %s release found for %s", minor, c.arch)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("release controller returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.Version == "" || release.PullSpec == "" {
+		return nil, fmt.Errorf("release controller returned no stable %s release", minor)
+	}
+	return &release, nil
+}
+
+// ImageSetName returns the ClusterImageSet name ACM gives a release, e.g. img4.16.12-x86-64
+func ImageSetName(release Release) string {
+	arch := "x86-64"
+	if _, tag, ok := strings.Cut(release.PullSpec, ":"); ok {
+		if _, suffix, ok := strings.Cut(tag, "-"); ok {
+			arch = strings.ReplaceAll(suffix, "_", "-")
+		}
+	}
+	return fmt.Sprintf("img%s-%s", release.Version, arch)
+}
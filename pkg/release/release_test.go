@@ -0,0 +1,83 @@
+//go:build test
+
+package release_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/release"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		requests []*http.Request
+		status   int
+		body     string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requests = nil
+		status = http.StatusOK
+		body = `{"name":"4.16.12","phase":"Accepted","pullSpec":"quay.io/openshift-release-dev/ocp-release:4.16.12-x86_64"}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("should resolve the latest stable release of a minor version", func() {
+		rel, err := release.NewClient(server.URL, "", server.Client()).LatestStable(ctx, "4.16")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rel).To(Equal(&release.Release{Version: "4.16.12", PullSpec: "quay.io/openshift-release-dev/ocp-release:4.16.12-x86_64"}))
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.Path).To(Equal("/api/v1/releasestream/4-stable/latest"))
+		Expect(requests[0].URL.Query().Get("in")).To(Equal(">4.16.0-0 <4.17.0-0"))
+	})
+
+	It("should query the stream of other architectures", func() {
+		_, err := release.NewClient(server.URL, "arm64", server.Client()).LatestStable(ctx, "4.9")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests[0].URL.Path).To(Equal("/api/v1/releasestream/4-stable-arm64/latest"))
+		Expect(requests[0].URL.Query().Get("in")).To(Equal(">4.9.0-0 <4.10.0-0"))
+	})
+
+	It("should reject versions that are not a minor version", func() {
+		for _, minor := range []string{"4", "4.16.1", "5.1", "latest"} {
+			_, err := release.NewClient(server.URL, "", server.Client()).LatestStable(ctx, minor)
+			Expect(err).To(MatchError(ContainSubstring("invalid minor version")))
+		}
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("should report minor versions without a stable release", func() {
+		status = http.StatusNotFound
+		_, err := release.NewClient(server.URL, "", server.Client()).LatestStable(ctx, "4.99")
+		Expect(err).To(MatchError("no stable 4.99 release found for amd64"))
+	})
+
+	It("should report failed requests", func() {
+		status = http.StatusBadGateway
+		body = "upstream unavailable"
+		_, err := release.NewClient(server.URL, "", server.Client()).LatestStable(ctx, "4.16")
+		Expect(err).To(MatchError("release controller returned 502 Bad Gateway: upstream unavailable"))
+	})
+})
+
+var _ = Describe("ImageSetName", func() {
+	It("should name ClusterImageSets like ACM", func() {
+		Expect(release.ImageSetName(release.Release{Version: "4.16.12", PullSpec: "quay.io/openshift-release-dev/ocp-release:4.16.12-x86_64"})).To(Equal("img4.16.12-x86-64"))
+		Expect(release.ImageSetName(release.Release{Version: "4.16.12", PullSpec: "quay.io/openshift-release-dev/ocp-release:4.16.12-aarch64"})).To(Equal("img4.16.12-aarch64"))
+		Expect(release.ImageSetName(release.Release{Version: "4.16.12", PullSpec: "quay.io/openshift-release-dev/ocp-release:4.16.12-multi"})).To(Equal("img4.16.12-multi"))
+	})
+})
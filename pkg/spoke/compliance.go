@@ -0,0 +1,290 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// ComplianceNamespace is the spoke namespace the Compliance Operator and its scans run in
+	ComplianceNamespace = "openshift-compliance"
+	// ComplianceChannel is the OLM channel the Compliance Operator is subscribed to
+	ComplianceChannel = "stable"
+	// CompliancePhaseDone is the phase of a ComplianceSuite whose scans have finished
+	CompliancePhaseDone = "DONE"
+	// DefaultComplianceTimeout bounds how long a scan may take to install and complete
+	DefaultComplianceTimeout = 30 * time.Minute
+
+	// complianceSuiteLabel is set by the operator on scans and check results of a suite
+	complianceSuiteLabel = "compliance.openshift.io/suite"
+	// complianceRescanAnnotation asks the operator to run a finished scan again
+	complianceRescanAnnotation = "compliance.openshift.io/rescan"
+)
+
+var (
+	// scanSettingBindingGVR identifies ScanSettingBindings, which bind profiles to scan settings
+	scanSettingBindingGVR = schema.GroupVersionResource{Group: "compliance.openshift.io", Version: "v1alpha1", Resource: "scansettingbindings"}
+	// complianceSuiteGVR identifies ComplianceSuites, created by the operator for each binding
+	complianceSuiteGVR = schema.GroupVersionResource{Group: "compliance.openshift.io", Version: "v1alpha1", Resource: "compliancesuites"}
+	// complianceScanGVR identifies ComplianceScans, one per profile of a suite
+	complianceScanGVR = schema.GroupVersionResource{Group: "compliance.openshift.io", Version: "v1alpha1", Resource: "compliancescans"}
+	// complianceCheckResultGVR identifies the per-rule results of a scan
+	complianceCheckResultGVR = schema.GroupVersionResource{Group: "compliance.openshift.io", Version: "v1alpha1", Resource: "compliancecheckresults"}
+)
+
+// ComplianceProfile expands a short profile name such as cis to the platform profile ocp4-cis.
+// Names that already carry an ocp4- or rhcos4- prefix are returned unchanged.
+func ComplianceProfile(name string) string {
+	if strings.HasPrefix(name, "ocp4-") || strings.HasPrefix(name, "rhcos4-") {
+		return name
+	}
+	return "ocp4-" + name
+}
+
+// complianceBindingName is the ScanSettingBinding (and ComplianceSuite) labrat creates for a profile
+func complianceBindingName(profile string) string {
+	return "labrat-" + profile
+}
+
+// ComplianceSummary is the outcome of the latest scan of a profile on a spoke
+type ComplianceSummary struct {
+	// Profile is the scanned profile, e.g. ocp4-cis
+	Profile string `json:"profile"`
+	// Phase is the ComplianceSuite phase (PENDING, LAUNCHING, RUNNING, AGGREGATING, DONE)
+	Phase string `json:"phase"`
+	// Result is the ComplianceSuite result (COMPLIANT, NON-COMPLIANT, ERROR, INCONSISTENT)
+	Result string `json:"result,omitempty"`
+	// Pass, Fail, Manual, and Errors count check results by status
+	Pass   int `json:"pass"`
+	Fail   int `json:"fail"`
+	Manual int `json:"manual"`
+	Errors int `json:"errors"`
+	// FailedHigh lists the failed checks of high severity
+	FailedHigh []string `json:"failedHigh,omitempty"`
+	// Finished is when the last scan of the suite finished, zero while a scan is running
+	Finished time.Time `json:"finished,omitzero"`
+}
+
+// ComplianceOptions configures how long scans are waited for
+type ComplianceOptions struct {
+	// Timeout bounds how long the operator may take to install and a scan may take to complete
+	Timeout time.Duration
+	// PollInterval is how often the scan status is checked
+	PollInterval time.Duration
+}
+
+// ComplianceScanner runs Compliance Operator scans on a spoke cluster
+type ComplianceScanner interface {
+	// Scan installs the Compliance Operator if needed and starts a scan of the profile
+	Scan(ctx context.Context, profile string) error
+	// Wait waits for a scan of the profile started at or after since to finish and returns its summary
+	Wait(ctx context.Context, profile string, since time.Time) (*ComplianceSummary, error)
+	// Summary returns the summary of the latest scan of the profile
+	Summary(ctx context.Context, profile string) (*ComplianceSummary, error)
+}
+
+type complianceScanner struct {
+	dynamicClient dynamic.Interface
+	opts          ComplianceOptions
+	options       kube.Options
+}
+
+// NewComplianceScanner creates a new ComplianceScanner using a dynamic client connected to the spoke cluster
+func NewComplianceScanner(dynamicClient dynamic.Interface, opts ComplianceOptions, options ...kube.Option) ComplianceScanner {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultComplianceTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 10 * time.Second
+	}
+	return &complianceScanner{
+		dynamicClient: dynamicClient,
+		opts:          opts,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Scan subscribes to the Compliance Operator and binds the profile to the default ScanSetting.
+// The binding is retried until the operator has installed its CRDs. If the profile was scanned
+// before, its scans are annotated to run again instead.
+func (c *complianceScanner) Scan(ctx context.Context, profile string) error {
+	ctx, cancel, err := c.options.Start(ctx, "start compliance scan", "profile", profile)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if err := installOperator(ctx, c.dynamicClient, ComplianceNamespace, "compliance-operator", ComplianceChannel); err != nil {
+		return fmt.Errorf("failed to install the Compliance Operator: %w", err)
+	}
+
+	name := complianceBindingName(profile)
+	bindings := c.dynamicClient.Resource(scanSettingBindingGVR).Namespace(ComplianceNamespace)
+
+	_, err = bindings.Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return c.rescan(ctx, name)
+	}
+	if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("failed to get ScanSettingBinding %s: %w", name, err)
+	}
+
+	binding := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "compliance.openshift.io/v1alpha1",
+		"kind":       "ScanSettingBinding",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": ComplianceNamespace,
+			"labels":    map[string]interface{}{"app.kubernetes.io/managed-by": "labrat"},
+		},
+		"profiles": []interface{}{
+			map[string]interface{}{"name": profile, "kind": "Profile", "apiGroup": "compliance.openshift.io/v1alpha1"},
+		},
+		"settingsRef": map[string]interface{}{"name": "default", "kind": "ScanSetting", "apiGroup": "compliance.openshift.io/v1alpha1"},
+	}}
+
+	var lastErr error
+	pollErr := wait.PollUntilContextTimeout(ctx, c.opts.PollInterval, c.opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		_, lastErr = bindings.Create(ctx, binding, metav1.CreateOptions{})
+		switch {
+		case lastErr == nil, apierrors.IsAlreadyExists(lastErr):
+			return true, nil
+		case apierrors.IsNotFound(lastErr), meta.IsNoMatchError(lastErr):
+			// The operator has not installed the ScanSettingBinding CRD yet
+			return false, nil
+		default:
+			return false, lastErr
+		}
+	})
+	if pollErr != nil {
+		if lastErr != nil {
+			return fmt.Errorf("failed to create ScanSettingBinding %s: %w", name, lastErr)
+		}
+		return fmt.Errorf("failed to create ScanSettingBinding %s: %w", name, pollErr)
+	}
+	return nil
+}
+
+// rescan annotates the scans of a suite so the operator runs them again
+func (c *complianceScanner) rescan(ctx context.Context, suite string) error {
+	scans := c.dynamicClient.Resource(complianceScanGVR).Namespace(ComplianceNamespace)
+	selector := labels.SelectorFromSet(labels.Set{complianceSuiteLabel: suite})
+
+	list, err := scans.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("failed to list ComplianceScans of %s: %w", suite, err)
+	}
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:""}}}`, complianceRescanAnnotation))
+	for _, scan := range list.Items {
+		if _, err := scans.Patch(ctx, scan.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to rescan ComplianceScan %s: %w", scan.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// Wait polls the suite of the profile until its phase is DONE and its scans finished after since,
+// so the result of a previous run is not mistaken for that of a rescan
+func (c *complianceScanner) Wait(ctx context.Context, profile string, since time.Time) (*ComplianceSummary, error) {
+	ctx, cancel, err := c.options.Start(ctx, "wait for compliance scan", "profile", profile)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	since = since.Truncate(time.Second)
+	var summary *ComplianceSummary
+	pollErr := wait.PollUntilContextTimeout(ctx, c.opts.PollInterval, c.opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		summary, err = c.Summary(ctx, profile)
+		if apierrors.IsNotFound(err) {
+			// The operator has not created the suite for the binding yet
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return summary.Phase == CompliancePhaseDone && !summary.Finished.Before(since), nil
+	})
+	if pollErr != nil {
+		return summary, fmt.Errorf("scan of %s did not finish: %w", profile, pollErr)
+	}
+	return summary, nil
+}
+
+// Summary reads the suite status of the profile and counts its check results
+func (c *complianceScanner) Summary(ctx context.Context, profile string) (*ComplianceSummary, error) {
+	ctx, cancel, err := c.options.Start(ctx, "summarize compliance scan", "profile", profile)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	name := complianceBindingName(profile)
+
+	suite, err := c.dynamicClient.Resource(complianceSuiteGVR).Namespace(ComplianceNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ComplianceSuite %s: %w", name, err)
+	}
+
+	summary := &ComplianceSummary{Profile: profile}
+	summary.Phase, _, _ = unstructured.NestedString(suite.Object, "status", "phase")
+	summary.Result, _, _ = unstructured.NestedString(suite.Object, "status", "result")
+
+	selector := labels.SelectorFromSet(labels.Set{complianceSuiteLabel: name})
+	scans, err := c.dynamicClient.Resource(complianceScanGVR).Namespace(ComplianceNamespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ComplianceScans of %s: %w", name, err)
+	}
+	for _, scan := range scans.Items {
+		endTimestamp, _, _ := unstructured.NestedString(scan.Object, "status", "endTimestamp")
+		finished, err := time.Parse(time.RFC3339, endTimestamp)
+		if err != nil {
+			// Still running
+			summary.Finished = time.Time{}
+			break
+		}
+		if finished.After(summary.Finished) {
+			summary.Finished = finished
+		}
+	}
+
+	results, err := c.dynamicClient.Resource(complianceCheckResultGVR).Namespace(ComplianceNamespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ComplianceCheckResults of %s: %w", name, err)
+	}
+
+	for _, result := range results.Items {
+		status, _, _ := unstructured.NestedString(result.Object, "status")
+		switch status {
+		case "PASS":
+			summary.Pass++
+		case "FAIL":
+			summary.Fail++
+			if severity, _, _ := unstructured.NestedString(result.Object, "severity"); severity == "high" {
+				summary.FailedHigh = append(summary.FailedHigh, result.GetName())
+			}
+		case "MANUAL":
+			summary.Manual++
+		case "ERROR", "INCONSISTENT":
+			summary.Errors++
+		}
+	}
+
+	return summary, nil
+}
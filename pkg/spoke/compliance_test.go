@@ -0,0 +1,147 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ComplianceProfile", func() {
+	It("should expand short profile names to platform profiles", func() {
+		Expect(spoke.ComplianceProfile("cis")).To(Equal("ocp4-cis"))
+		Expect(spoke.ComplianceProfile("moderate")).To(Equal("ocp4-moderate"))
+	})
+
+	It("should keep fully qualified profile names", func() {
+		Expect(spoke.ComplianceProfile("ocp4-cis-node")).To(Equal("ocp4-cis-node"))
+		Expect(spoke.ComplianceProfile("rhcos4-e8")).To(Equal("rhcos4-e8"))
+	})
+})
+
+var _ = Describe("ComplianceScanner", func() {
+	const ns = spoke.ComplianceNamespace
+
+	var (
+		ctx         context.Context
+		fakeDynamic *fake.FakeDynamicClient
+		scanner     spoke.ComplianceScanner
+		group       = "compliance.openshift.io"
+		bindingGVR  = schema.GroupVersionResource{Group: group, Version: "v1alpha1", Resource: "scansettingbindings"}
+		scanGVR     = schema.GroupVersionResource{Group: group, Version: "v1alpha1", Resource: "compliancescans"}
+		subGVR      = schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "subscriptions"}
+		finishedAt  = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	object := func(kind, name string, labels map[string]interface{}, fields map[string]interface{}) *unstructured.Unstructured {
+		obj := map[string]interface{}{
+			"apiVersion": group + "/v1alpha1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name, "namespace": ns, "labels": labels},
+		}
+		for k, v := range fields {
+			obj[k] = v
+		}
+		return &unstructured.Unstructured{Object: obj}
+	}
+
+	suiteLabel := map[string]interface{}{"compliance.openshift.io/suite": "labrat-ocp4-cis"}
+
+	checkResult := func(name, status, severity string) *unstructured.Unstructured {
+		return object("ComplianceCheckResult", name, suiteLabel, map[string]interface{}{"status": status, "severity": severity})
+	}
+
+	newScanner := func(objects ...runtime.Object) {
+		fakeDynamic = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			scanGVR: "ComplianceScanList",
+			{Group: group, Version: "v1alpha1", Resource: "compliancecheckresults"}: "ComplianceCheckResultList",
+		}, objects...)
+		scanner = spoke.NewComplianceScanner(fakeDynamic, spoke.ComplianceOptions{Timeout: 100 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+	}
+
+	finishedSuite := func() []runtime.Object {
+		return []runtime.Object{
+			object("ComplianceSuite", "labrat-ocp4-cis", nil, map[string]interface{}{
+				"status": map[string]interface{}{"phase": "DONE", "result": "NON-COMPLIANT"},
+			}),
+			object("ComplianceScan", "ocp4-cis", suiteLabel, map[string]interface{}{
+				"status": map[string]interface{}{"phase": "DONE", "endTimestamp": finishedAt.Format(time.RFC3339)},
+			}),
+			checkResult("ocp4-cis-audit-log-forwarding-enabled", "FAIL", "medium"),
+			checkResult("ocp4-cis-api-server-encryption-provider-cipher", "FAIL", "high"),
+			checkResult("ocp4-cis-rbac-limit-cluster-admin", "MANUAL", "medium"),
+			checkResult("ocp4-cis-api-server-anonymous-auth", "PASS", "medium"),
+			checkResult("ocp4-cis-etcd-unique-ca", "PASS", "medium"),
+			checkResult("ocp4-cis-kubelet-configure-tls-cipher-suites", "ERROR", "medium"),
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should install the operator and bind the profile on a new cluster", func() {
+		newScanner()
+		Expect(scanner.Scan(ctx, "ocp4-cis")).To(Succeed())
+
+		sub, err := fakeDynamic.Resource(subGVR).Namespace(ns).Get(ctx, "compliance-operator", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		channel, _, _ := unstructured.NestedString(sub.Object, "spec", "channel")
+		Expect(channel).To(Equal(spoke.ComplianceChannel))
+
+		binding, err := fakeDynamic.Resource(bindingGVR).Namespace(ns).Get(ctx, "labrat-ocp4-cis", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		profiles, _, _ := unstructured.NestedSlice(binding.Object, "profiles")
+		Expect(profiles).To(HaveLen(1))
+		Expect(profiles[0]).To(HaveKeyWithValue("name", "ocp4-cis"))
+	})
+
+	It("should rescan a profile that was scanned before", func() {
+		objects := append(finishedSuite(), object("ScanSettingBinding", "labrat-ocp4-cis", nil, nil))
+		newScanner(objects...)
+		Expect(scanner.Scan(ctx, "ocp4-cis")).To(Succeed())
+
+		scan, err := fakeDynamic.Resource(scanGVR).Namespace(ns).Get(ctx, "ocp4-cis", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scan.GetAnnotations()).To(HaveKey("compliance.openshift.io/rescan"))
+	})
+
+	It("should summarize the check results of the latest scan", func() {
+		newScanner(finishedSuite()...)
+		summary, err := scanner.Summary(ctx, "ocp4-cis")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*summary).To(Equal(spoke.ComplianceSummary{
+			Profile:    "ocp4-cis",
+			Phase:      "DONE",
+			Result:     "NON-COMPLIANT",
+			Pass:       2,
+			Fail:       2,
+			Manual:     1,
+			Errors:     1,
+			FailedHigh: []string{"ocp4-cis-api-server-encryption-provider-cipher"},
+			Finished:   finishedAt,
+		}))
+	})
+
+	It("should return once a scan started after since has finished", func() {
+		newScanner(finishedSuite()...)
+		summary, err := scanner.Wait(ctx, "ocp4-cis", finishedAt.Add(-time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Result).To(Equal("NON-COMPLIANT"))
+	})
+
+	It("should not mistake the result of a previous scan for a rescan", func() {
+		newScanner(finishedSuite()...)
+		_, err := scanner.Wait(ctx, "ocp4-cis", finishedAt.Add(time.Minute))
+		Expect(err).To(MatchError(ContainSubstring("scan of ocp4-cis did not finish")))
+	})
+})
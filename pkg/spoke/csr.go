@@ -0,0 +1,173 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// KlusterletCSRLabel holds the name of the cluster on the CSRs its klusterlet creates on the
+	// hub to register
+	KlusterletCSRLabel = "open-cluster-management.io/cluster-name"
+
+	// CSRPending is the status of CSRs that are neither approved nor denied
+	CSRPending = "Pending"
+	// CSRApproved is the status of approved CSRs
+	CSRApproved = "Approved"
+	// CSRDenied is the status of denied CSRs
+	CSRDenied = "Denied"
+	// CSRFailed is the status of approved CSRs the signer failed to sign
+	CSRFailed = "Failed"
+
+	// csrApprovalReason is the reason of the Approved condition labrat adds
+	csrApprovalReason = "LabratApprove"
+)
+
+// nodeSigners are the signers of the client and serving certificates of kubelets
+var nodeSigners = map[string]bool{
+	certificatesv1.KubeAPIServerClientKubeletSignerName: true,
+	certificatesv1.KubeletServingSignerName:             true,
+}
+
+// CSRInfo is a CertificateSigningRequest
+type CSRInfo struct {
+	// Name is the name of the CSR
+	Name string `json:"name"`
+	// Requestor is the user that created the CSR
+	Requestor string `json:"requestor"`
+	// SignerName is the signer the CSR asks to sign it
+	SignerName string `json:"signerName"`
+	// Status is Pending, Approved, Denied, or Failed
+	Status string `json:"status"`
+	// CreatedAt is when the CSR was created
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Pending reports whether the CSR is neither approved nor denied
+func (c CSRInfo) Pending() bool {
+	return c.Status == CSRPending
+}
+
+// Node reports whether the CSR asks for a kubelet client or serving certificate
+func (c CSRInfo) Node() bool {
+	return nodeSigners[c.SignerName]
+}
+
+// CSRManager lists and approves the CertificateSigningRequests of a cluster
+type CSRManager interface {
+	// List returns the CSRs matching labelSelector, oldest first
+	List(ctx context.Context, labelSelector string) ([]CSRInfo, error)
+	// Approve approves a pending CSR
+	Approve(ctx context.Context, name string) error
+}
+
+type csrManager struct {
+	coreClient kubernetes.Interface
+	options    kube.Options
+}
+
+// NewCSRManager creates a new CSRManager using a client connected to the hub or a spoke
+func NewCSRManager(coreClient kubernetes.Interface, options ...kube.Option) CSRManager {
+	return &csrManager{
+		coreClient: coreClient,
+		options:    kube.NewOptions(options...),
+	}
+}
+
+// KlusterletCSRSelector selects the CSRs the klusterlet of cluster creates on the hub
+func KlusterletCSRSelector(cluster string) string {
+	return KlusterletCSRLabel + "=" + cluster
+}
+
+// List returns the CSRs matching labelSelector, oldest first
+func (m *csrManager) List(ctx context.Context, labelSelector string) ([]CSRInfo, error) {
+	ctx, cancel, err := m.options.Start(ctx, "list certificate signing requests", "selector", labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *certificatesv1.CertificateSigningRequestList
+	err = m.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = m.coreClient.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list certificate signing requests: %w", err)
+	}
+
+	csrs := make([]CSRInfo, 0, len(list.Items))
+	for _, csr := range list.Items {
+		csrs = append(csrs, CSRInfo{
+			Name:       csr.Name,
+			Requestor:  csr.Spec.Username,
+			SignerName: csr.Spec.SignerName,
+			Status:     csrStatus(csr),
+			CreatedAt:  csr.CreationTimestamp.Time,
+		})
+	}
+	sort.SliceStable(csrs, func(i, j int) bool {
+		if !csrs[i].CreatedAt.Equal(csrs[j].CreatedAt) {
+			return csrs[i].CreatedAt.Before(csrs[j].CreatedAt)
+		}
+		return csrs[i].Name < csrs[j].Name
+	})
+	return csrs, nil
+}
+
+// Approve adds an Approved condition to a CSR, like oc adm certificate approve. CSRs that were
+// already approved or denied are not changed.
+func (m *csrManager) Approve(ctx context.Context, name string) error {
+	ctx, cancel, err := m.options.Start(ctx, "approve certificate signing request", "csr", name)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	csrs := m.coreClient.CertificatesV1().CertificateSigningRequests()
+	csr, err := csrs.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get certificate signing request %s: %w", name, err)
+	}
+	if status := csrStatus(*csr); status != CSRPending {
+		return fmt.Errorf("certificate signing request %s is already %s", name, status)
+	}
+
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:           certificatesv1.CertificateApproved,
+		Status:         corev1.ConditionTrue,
+		Reason:         csrApprovalReason,
+		Message:        "Approved by labrat",
+		LastUpdateTime: metav1.Now(),
+	})
+	if _, err := csrs.UpdateApproval(ctx, name, csr, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to approve certificate signing request %s: %w", name, err)
+	}
+	return nil
+}
+
+// csrStatus returns the status of a CSR from its conditions
+func csrStatus(csr certificatesv1.CertificateSigningRequest) string {
+	status := CSRPending
+	for _, condition := range csr.Status.Conditions {
+		switch condition.Type {
+		case certificatesv1.CertificateDenied:
+			return CSRDenied
+		case certificatesv1.CertificateFailed:
+			return CSRFailed
+		case certificatesv1.CertificateApproved:
+			status = CSRApproved
+		}
+	}
+	return status
+}
@@ -0,0 +1,109 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+var _ = Describe("CSRManager", func() {
+	var (
+		ctx       context.Context
+		fakeK8s   *k8sFake.Clientset
+		manager   spoke.CSRManager
+		createdAt time.Time
+	)
+
+	csr := func(name, cluster, signer string, age time.Duration, conditions ...certificatesv1.RequestConditionType) *certificatesv1.CertificateSigningRequest {
+		obj := &certificatesv1.CertificateSigningRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(createdAt.Add(-age)),
+			},
+			Spec: certificatesv1.CertificateSigningRequestSpec{
+				SignerName: signer,
+				Username:   "system:serviceaccount:open-cluster-management-agent:klusterlet",
+			},
+		}
+		if cluster != "" {
+			obj.Labels = map[string]string{spoke.KlusterletCSRLabel: cluster}
+		}
+		for _, conditionType := range conditions {
+			obj.Status.Conditions = append(obj.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+				Type:   conditionType,
+				Status: corev1.ConditionTrue,
+			})
+		}
+		return obj
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		createdAt = time.Now().Truncate(time.Second)
+		fakeK8s = k8sFake.NewSimpleClientset(
+			csr("partner-a-abcde", "partner-a", certificatesv1.KubeAPIServerClientSignerName, time.Minute),
+			csr("partner-a-fghij", "partner-a", certificatesv1.KubeAPIServerClientSignerName, time.Hour, certificatesv1.CertificateApproved),
+			csr("partner-a-klmno", "partner-a", certificatesv1.KubeAPIServerClientSignerName, 2*time.Hour, certificatesv1.CertificateDenied),
+			csr("partner-b-abcde", "partner-b", certificatesv1.KubeAPIServerClientSignerName, time.Minute),
+			csr("csr-node", "", certificatesv1.KubeAPIServerClientKubeletSignerName, time.Minute),
+		)
+		manager = spoke.NewCSRManager(fakeK8s)
+	})
+
+	It("should list the CSRs of a klusterlet oldest first with their status", func() {
+		csrs, err := manager.List(ctx, spoke.KlusterletCSRSelector("partner-a"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(csrs).To(HaveLen(3))
+		Expect(csrs[0].Name).To(Equal("partner-a-klmno"))
+		Expect(csrs[0].Status).To(Equal(spoke.CSRDenied))
+		Expect(csrs[1].Status).To(Equal(spoke.CSRApproved))
+		Expect(csrs[2].Name).To(Equal("partner-a-abcde"))
+		Expect(csrs[2].Pending()).To(BeTrue())
+		Expect(csrs[2].Requestor).To(Equal("system:serviceaccount:open-cluster-management-agent:klusterlet"))
+		Expect(csrs[2].Node()).To(BeFalse())
+	})
+
+	It("should recognize node CSRs", func() {
+		csrs, err := manager.List(ctx, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		var nodes []string
+		for _, c := range csrs {
+			if c.Node() {
+				nodes = append(nodes, c.Name)
+			}
+		}
+		Expect(nodes).To(Equal([]string{"csr-node"}))
+	})
+
+	It("should approve a pending CSR", func() {
+		Expect(manager.Approve(ctx, "partner-a-abcde")).To(Succeed())
+
+		approved, err := fakeK8s.CertificatesV1().CertificateSigningRequests().Get(ctx, "partner-a-abcde", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(approved.Status.Conditions).To(HaveLen(1))
+		Expect(approved.Status.Conditions[0].Type).To(Equal(certificatesv1.CertificateApproved))
+		Expect(approved.Status.Conditions[0].Status).To(Equal(corev1.ConditionTrue))
+		Expect(approved.Status.Conditions[0].Reason).To(Equal("LabratApprove"))
+	})
+
+	It("should not approve CSRs that were approved or denied", func() {
+		Expect(manager.Approve(ctx, "partner-a-fghij")).To(MatchError("certificate signing request partner-a-fghij is already Approved"))
+		Expect(manager.Approve(ctx, "partner-a-klmno")).To(MatchError("certificate signing request partner-a-klmno is already Denied"))
+	})
+
+	It("should fail for CSRs that do not exist", func() {
+		Expect(manager.Approve(ctx, "missing")).To(MatchError(ContainSubstring("failed to get certificate signing request missing")))
+	})
+})
@@ -0,0 +1,192 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// DefaultDeprovisionTimeout bounds how long Wait waits for Hive to destroy a cluster
+	DefaultDeprovisionTimeout = 60 * time.Minute
+
+	// DeprovisionPhasePending means the ClusterDeployment is deleted but Hive has not started the deprovision yet
+	DeprovisionPhasePending = "Pending"
+	// DeprovisionPhaseRunning means the Hive uninstall job is destroying the cloud resources
+	DeprovisionPhaseRunning = "Deprovisioning"
+	// DeprovisionPhaseBlocked means Hive reported a condition that keeps the deprovision from making progress
+	DeprovisionPhaseBlocked = "Blocked"
+	// DeprovisionPhaseCompleted means the cloud resources are destroyed and the ClusterDeployment is gone
+	DeprovisionPhaseCompleted = "Completed"
+)
+
+// clusterDeprovisionGVR identifies the Hive ClusterDeprovision created for a deleted ClusterDeployment
+var clusterDeprovisionGVR = schema.GroupVersionResource{
+	Group:    "hive.openshift.io",
+	Version:  "v1",
+	Resource: "clusterdeprovisions",
+}
+
+// deprovisionBlockingConditions are the ClusterDeprovision conditions that stall the uninstall job
+var deprovisionBlockingConditions = []string{"AuthenticationFailure", "DeprovisionLaunchError"}
+
+// DeprovisionOptions controls how long Wait waits for a deprovision
+type DeprovisionOptions struct {
+	// Timeout bounds how long Wait waits for the deprovision to complete
+	Timeout time.Duration
+	// PollInterval is how often the deprovision status is checked
+	PollInterval time.Duration
+}
+
+// DeprovisionStatus is the progress of the deprovision of a cluster
+type DeprovisionStatus struct {
+	Cluster string `json:"cluster"`
+	// Phase is one of the DeprovisionPhase constants
+	Phase string `json:"phase"`
+	// Message explains the phase
+	Message string `json:"message,omitempty"`
+}
+
+// Deprovisioner tears down spoke clusters through Hive
+type Deprovisioner interface {
+	// Delete deletes the ClusterDeployment of a cluster, which makes Hive destroy its cloud
+	// resources. With detach the ManagedCluster is deleted first, so ACM detaches the cluster.
+	Delete(ctx context.Context, clusterName string, detach bool) error
+	// Status reads the progress of the deprovision of a cluster
+	Status(ctx context.Context, clusterName string) (*DeprovisionStatus, error)
+	// Wait polls the deprovision until it completes, calling progress whenever the status changes
+	Wait(ctx context.Context, clusterName string, progress func(DeprovisionStatus)) error
+}
+
+type deprovisioner struct {
+	dynamicClient dynamic.Interface
+	opts          DeprovisionOptions
+	options       kube.Options
+}
+
+// NewDeprovisioner creates a new Deprovisioner using a dynamic client connected to the hub
+func NewDeprovisioner(dynamicClient dynamic.Interface, opts DeprovisionOptions, options ...kube.Option) Deprovisioner {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultDeprovisionTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 15 * time.Second
+	}
+	return &deprovisioner{
+		dynamicClient: dynamicClient,
+		opts:          opts,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Delete deletes the ManagedCluster (with detach) and the ClusterDeployment in namespace=clusterName.
+// Resources that are already gone are ignored, so an interrupted delete can be repeated.
+func (d *deprovisioner) Delete(ctx context.Context, clusterName string, detach bool) error {
+	ctx, cancel, err := d.options.Start(ctx, "delete cluster", "cluster", clusterName, "detach", detach)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if detach {
+		err := d.dynamicClient.Resource(provisionGVRs["ManagedCluster"]).Delete(ctx, clusterName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ManagedCluster %s: %w", clusterName, err)
+		}
+	}
+
+	err = d.dynamicClient.Resource(clusterDeploymentGVR).Namespace(clusterName).Delete(ctx, clusterName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete ClusterDeployment %s: %w", clusterName, err)
+	}
+	return nil
+}
+
+// Status derives the deprovision phase from the ClusterDeployment and its ClusterDeprovision
+func (d *deprovisioner) Status(ctx context.Context, clusterName string) (*DeprovisionStatus, error) {
+	ctx, cancel, err := d.options.Start(ctx, "read deprovision status", "cluster", clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	status := &DeprovisionStatus{Cluster: clusterName}
+
+	cd, err := d.dynamicClient.Resource(clusterDeploymentGVR).Namespace(clusterName).Get(ctx, clusterName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		status.Phase = DeprovisionPhaseCompleted
+		status.Message = "ClusterDeployment is gone"
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterDeployment %s: %w", clusterName, err)
+	}
+	if cd.GetDeletionTimestamp() == nil {
+		return nil, fmt.Errorf("ClusterDeployment %s is not being deleted", clusterName)
+	}
+
+	deprovision, err := d.dynamicClient.Resource(clusterDeprovisionGVR).Namespace(clusterName).Get(ctx, clusterName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		status.Phase = DeprovisionPhasePending
+		status.Message = "waiting for Hive to start the deprovision"
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterDeprovision %s: %w", clusterName, err)
+	}
+
+	if completed, _, _ := unstructured.NestedBool(deprovision.Object, "status", "completed"); completed {
+		status.Phase = DeprovisionPhaseRunning
+		status.Message = "cloud resources destroyed, removing the ClusterDeployment"
+		return status, nil
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(deprovision.Object, "status", "conditions")
+	for _, blocking := range deprovisionBlockingConditions {
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["type"] != blocking || condition["status"] != "True" {
+				continue
+			}
+			status.Phase = DeprovisionPhaseBlocked
+			status.Message = fmt.Sprintf("%s: %v", blocking, condition["message"])
+			return status, nil
+		}
+	}
+
+	status.Phase = DeprovisionPhaseRunning
+	status.Message = "uninstall job is destroying the cloud resources"
+	return status, nil
+}
+
+// Wait polls Status until the ClusterDeployment is gone. A blocked deprovision is reported
+// through progress but waited on, as Hive keeps retrying it.
+func (d *deprovisioner) Wait(ctx context.Context, clusterName string, progress func(DeprovisionStatus)) error {
+	var last DeprovisionStatus
+	pollErr := wait.PollUntilContextTimeout(ctx, d.opts.PollInterval, d.opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		status, err := d.Status(ctx, clusterName)
+		if err != nil {
+			return false, err
+		}
+		if *status != last {
+			last = *status
+			if progress != nil {
+				progress(last)
+			}
+		}
+		return status.Phase == DeprovisionPhaseCompleted, nil
+	})
+	if pollErr != nil {
+		return fmt.Errorf("deprovision of %s did not complete (last phase %s): %w", clusterName, last.Phase, pollErr)
+	}
+	return nil
+}
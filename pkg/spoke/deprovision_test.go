@@ -0,0 +1,166 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("Deprovisioner", func() {
+	var (
+		ctx        context.Context
+		cdGVR      schema.GroupVersionResource
+		mcGVR      schema.GroupVersionResource
+		deleting   metav1.Time
+		newCD      func(deleted bool) *unstructured.Unstructured
+		newDeprov  func(status map[string]interface{}) *unstructured.Unstructured
+		newMC      func() *unstructured.Unstructured
+		newDeleter func(objects ...runtime.Object) (*fake.FakeDynamicClient, spoke.Deprovisioner)
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cdGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+		mcGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		deleting = metav1.NewTime(time.Now())
+
+		newCD = func(deleted bool) *unstructured.Unstructured {
+			cd := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "hive.openshift.io/v1",
+				"kind":       "ClusterDeployment",
+				"metadata":   map[string]interface{}{"name": "test-cluster", "namespace": "test-cluster"},
+			}}
+			if deleted {
+				cd.SetDeletionTimestamp(&deleting)
+			}
+			return cd
+		}
+		newDeprov = func(status map[string]interface{}) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "hive.openshift.io/v1",
+				"kind":       "ClusterDeprovision",
+				"metadata":   map[string]interface{}{"name": "test-cluster", "namespace": "test-cluster"},
+				"status":     status,
+			}}
+		}
+		newMC = func() *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "cluster.open-cluster-management.io/v1",
+				"kind":       "ManagedCluster",
+				"metadata":   map[string]interface{}{"name": "test-cluster"},
+			}}
+		}
+		newDeleter = func(objects ...runtime.Object) (*fake.FakeDynamicClient, spoke.Deprovisioner) {
+			fakeDynamic := fake.NewSimpleDynamicClient(runtime.NewScheme(), objects...)
+			return fakeDynamic, spoke.NewDeprovisioner(fakeDynamic, spoke.DeprovisionOptions{
+				Timeout:      time.Second,
+				PollInterval: 10 * time.Millisecond,
+			})
+		}
+	})
+
+	Describe("Delete", func() {
+		It("should delete the ManagedCluster and ClusterDeployment when detaching", func() {
+			fakeDynamic, d := newDeleter(newCD(false), newMC())
+			Expect(d.Delete(ctx, "test-cluster", true)).To(Succeed())
+
+			_, err := fakeDynamic.Resource(cdGVR).Namespace("test-cluster").Get(ctx, "test-cluster", metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			_, err = fakeDynamic.Resource(mcGVR).Get(ctx, "test-cluster", metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep the ManagedCluster without detach", func() {
+			fakeDynamic, d := newDeleter(newCD(false), newMC())
+			Expect(d.Delete(ctx, "test-cluster", false)).To(Succeed())
+
+			_, err := fakeDynamic.Resource(mcGVR).Get(ctx, "test-cluster", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should ignore resources that are already gone", func() {
+			_, d := newDeleter(newMC())
+			Expect(d.Delete(ctx, "test-cluster", true)).To(Succeed())
+		})
+	})
+
+	Describe("Status", func() {
+		It("should report Completed once the ClusterDeployment is gone", func() {
+			_, d := newDeleter()
+			status, err := d.Status(ctx, "test-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Phase).To(Equal(spoke.DeprovisionPhaseCompleted))
+		})
+
+		It("should fail when the ClusterDeployment is not being deleted", func() {
+			_, d := newDeleter(newCD(false))
+			_, err := d.Status(ctx, "test-cluster")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not being deleted"))
+		})
+
+		It("should report Pending before Hive creates the ClusterDeprovision", func() {
+			_, d := newDeleter(newCD(true))
+			status, err := d.Status(ctx, "test-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Phase).To(Equal(spoke.DeprovisionPhasePending))
+		})
+
+		It("should report Deprovisioning while the uninstall job runs", func() {
+			_, d := newDeleter(newCD(true), newDeprov(map[string]interface{}{}))
+			status, err := d.Status(ctx, "test-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Phase).To(Equal(spoke.DeprovisionPhaseRunning))
+		})
+
+		It("should report Blocked on an authentication failure", func() {
+			_, d := newDeleter(newCD(true), newDeprov(map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "AuthenticationFailure", "status": "True", "message": "invalid credentials"},
+				},
+			}))
+			status, err := d.Status(ctx, "test-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Phase).To(Equal(spoke.DeprovisionPhaseBlocked))
+			Expect(status.Message).To(ContainSubstring("invalid credentials"))
+		})
+	})
+
+	Describe("Wait", func() {
+		It("should report progress until the ClusterDeployment is gone", func() {
+			fakeDynamic, d := newDeleter(newCD(true), newDeprov(map[string]interface{}{}))
+			var phases []string
+			err := d.Wait(ctx, "test-cluster", func(status spoke.DeprovisionStatus) {
+				phases = append(phases, status.Phase)
+				if status.Phase != spoke.DeprovisionPhaseRunning {
+					return
+				}
+				Expect(fakeDynamic.Resource(cdGVR).Namespace("test-cluster").Delete(ctx, "test-cluster", metav1.DeleteOptions{})).To(Succeed())
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phases).To(Equal([]string{spoke.DeprovisionPhaseRunning, spoke.DeprovisionPhaseCompleted}))
+		})
+
+		It("should time out while the deprovision is blocked", func() {
+			_, d := newDeleter(newCD(true), newDeprov(map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "DeprovisionLaunchError", "status": "True", "message": "launch failed"},
+				},
+			}))
+			err := d.Wait(ctx, "test-cluster", nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("last phase Blocked"))
+		})
+	})
+})
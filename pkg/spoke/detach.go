@@ -0,0 +1,147 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// DefaultKlusterletRemovalTimeout bounds how long Remove waits for the klusterlet operator to clean up
+	DefaultKlusterletRemovalTimeout = 5 * time.Minute
+
+	// klusterletName is the name of the Klusterlet created by the ACM import manifests
+	klusterletName = "klusterlet"
+)
+
+// klusterletGVR identifies the cluster-scoped Klusterlet resource on a spoke
+var klusterletGVR = schema.GroupVersionResource{
+	Group:    "operator.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "klusterlets",
+}
+
+// klusterletNamespaces are the spoke namespaces of the klusterlet operator, agents, and add-on agents
+var klusterletNamespaces = []string{"open-cluster-management-agent", "open-cluster-management-agent-addon"}
+
+// Detacher removes clusters from ACM without destroying them
+type Detacher interface {
+	// Detach deletes the ManagedCluster of a cluster. The ClusterDeployment and the cloud
+	// resources of the cluster are left in place.
+	Detach(ctx context.Context, clusterName string) error
+}
+
+type detacher struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewDetacher creates a new Detacher using a dynamic client connected to the hub
+func NewDetacher(dynamicClient dynamic.Interface, options ...kube.Option) Detacher {
+	return &detacher{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Detach deletes the ManagedCluster named after the cluster
+func (d *detacher) Detach(ctx context.Context, clusterName string) error {
+	ctx, cancel, err := d.options.Start(ctx, "detach cluster", "cluster", clusterName)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	err = d.dynamicClient.Resource(provisionGVRs["ManagedCluster"]).Delete(ctx, clusterName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("cluster %s is not managed by ACM", clusterName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete ManagedCluster %s: %w", clusterName, err)
+	}
+	return nil
+}
+
+// KlusterletOptions controls how long Remove waits for the klusterlet to be cleaned up
+type KlusterletOptions struct {
+	// Timeout bounds how long Remove waits for the Klusterlet to be deleted
+	Timeout time.Duration
+	// PollInterval is how often the Klusterlet is checked
+	PollInterval time.Duration
+}
+
+// KlusterletRemover uninstalls the ACM agents from a spoke
+type KlusterletRemover interface {
+	// Remove deletes the Klusterlet, waits for the klusterlet operator to remove the agents,
+	// and deletes the klusterlet namespaces
+	Remove(ctx context.Context) error
+}
+
+type klusterletRemover struct {
+	dynamicClient dynamic.Interface
+	opts          KlusterletOptions
+	options       kube.Options
+}
+
+// NewKlusterletRemover creates a new KlusterletRemover using a dynamic client connected to the spoke
+func NewKlusterletRemover(dynamicClient dynamic.Interface, opts KlusterletOptions, options ...kube.Option) KlusterletRemover {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultKlusterletRemovalTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	return &klusterletRemover{
+		dynamicClient: dynamicClient,
+		opts:          opts,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Remove deletes the Klusterlet and the klusterlet namespaces. Resources that are already gone
+// are ignored, so an interrupted removal can be repeated.
+func (r *klusterletRemover) Remove(ctx context.Context) error {
+	ctx, cancel, err := r.options.Start(ctx, "remove klusterlet")
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	klusterlets := r.dynamicClient.Resource(klusterletGVR)
+	err = klusterlets.Delete(ctx, klusterletName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Klusterlet: %w", err)
+	}
+
+	// The klusterlet operator removes the agents before it releases the Klusterlet's finalizer,
+	// so its namespace must stay until the Klusterlet is gone
+	pollErr := wait.PollUntilContextTimeout(ctx, r.opts.PollInterval, r.opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		_, err := klusterlets.Get(ctx, klusterletName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to get Klusterlet: %w", err)
+		}
+		return false, nil
+	})
+	if pollErr != nil {
+		return fmt.Errorf("klusterlet was not removed: %w", pollErr)
+	}
+
+	for _, namespace := range klusterletNamespaces {
+		err := r.dynamicClient.Resource(provisionGVRs["Namespace"]).Delete(ctx, namespace, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete namespace %s: %w", namespace, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Detacher", func() {
+	var (
+		ctx   context.Context
+		cdGVR schema.GroupVersionResource
+		mcGVR schema.GroupVersionResource
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cdGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+		mcGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+	})
+
+	It("should delete the ManagedCluster and keep the ClusterDeployment", func() {
+		dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(),
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "cluster.open-cluster-management.io/v1",
+				"kind":       "ManagedCluster",
+				"metadata":   map[string]interface{}{"name": "test-cluster"},
+			}},
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "hive.openshift.io/v1",
+				"kind":       "ClusterDeployment",
+				"metadata":   map[string]interface{}{"name": "test-cluster", "namespace": "test-cluster"},
+			}},
+		)
+
+		Expect(spoke.NewDetacher(dynamicClient).Detach(ctx, "test-cluster")).To(Succeed())
+
+		_, err := dynamicClient.Resource(mcGVR).Get(ctx, "test-cluster", metav1.GetOptions{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		_, err = dynamicClient.Resource(cdGVR).Namespace("test-cluster").Get(ctx, "test-cluster", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should fail for a cluster that is not managed by ACM", func() {
+		dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{})
+
+		err := spoke.NewDetacher(dynamicClient).Detach(ctx, "test-cluster")
+		Expect(err).To(MatchError("cluster test-cluster is not managed by ACM"))
+	})
+})
+
+var _ = Describe("KlusterletRemover", func() {
+	var (
+		ctx           context.Context
+		klusterletGVR schema.GroupVersionResource
+		namespaceGVR  schema.GroupVersionResource
+		newClient     func() *fake.FakeDynamicClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		klusterletGVR = schema.GroupVersionResource{Group: "operator.open-cluster-management.io", Version: "v1", Resource: "klusterlets"}
+		namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+		newNamespace := func(name string) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": name},
+			}}
+		}
+		newClient = func() *fake.FakeDynamicClient {
+			return fake.NewSimpleDynamicClient(runtime.NewScheme(),
+				&unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "operator.open-cluster-management.io/v1",
+					"kind":       "Klusterlet",
+					"metadata":   map[string]interface{}{"name": "klusterlet"},
+				}},
+				newNamespace("open-cluster-management-agent"),
+				newNamespace("open-cluster-management-agent-addon"),
+				newNamespace("openshift-monitoring"),
+			)
+		}
+	})
+
+	It("should delete the Klusterlet and the klusterlet namespaces", func() {
+		dynamicClient := newClient()
+
+		Expect(spoke.NewKlusterletRemover(dynamicClient, spoke.KlusterletOptions{}).Remove(ctx)).To(Succeed())
+
+		_, err := dynamicClient.Resource(klusterletGVR).Get(ctx, "klusterlet", metav1.GetOptions{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		namespaces, err := dynamicClient.Resource(namespaceGVR).List(ctx, metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(namespaces.Items).To(HaveLen(1))
+		Expect(namespaces.Items[0].GetName()).To(Equal("openshift-monitoring"))
+	})
+
+	It("should keep the namespaces while the Klusterlet is being finalized", func() {
+		dynamicClient := newClient()
+		// The Klusterlet stays behind, as if its finalizer was never released
+		dynamicClient.PrependReactor("delete", "klusterlets", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, nil
+		})
+
+		remover := spoke.NewKlusterletRemover(dynamicClient, spoke.KlusterletOptions{Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+		Expect(remover.Remove(ctx)).To(MatchError(ContainSubstring("klusterlet was not removed")))
+
+		_, err := dynamicClient.Resource(namespaceGVR).Get(ctx, "open-cluster-management-agent", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,204 @@
+package spoke
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// OADPNamespace is the spoke namespace the OADP operator and Velero run in
+	OADPNamespace = "openshift-adp"
+	// DRManifestWorkName is the ManifestWork that carries the OADP setup to a spoke
+	DRManifestWorkName = "labrat-oadp"
+	// OADPChannel is the OLM channel the OADP operator is subscribed to
+	OADPChannel = "stable-1.4"
+
+	// oadpCredentialsSecret holds the AWS credentials Velero uses for the backup storage location
+	oadpCredentialsSecret = "cloud-credentials"
+)
+
+// manifestWorkGVR identifies OCM ManifestWork resources
+var manifestWorkGVR = schema.GroupVersionResource{
+	Group:    "work.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "manifestworks",
+}
+
+// BackupStorage describes the S3 bucket Velero stores spoke backups in
+type BackupStorage struct {
+	// Bucket is the S3 bucket name
+	Bucket string
+	// Prefix is the key prefix inside the bucket, if any
+	Prefix string
+	// Region is the AWS region of the bucket
+	Region string
+	// AccessKeyID and SecretAccessKey grant Velero access to the bucket
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// ParseBucketURL splits an s3://bucket/prefix URL into bucket and prefix
+func ParseBucketURL(bucketURL string) (string, string, error) {
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid bucket URL %q: %w", bucketURL, err)
+	}
+	if u.Scheme != "s3" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid bucket URL %q: expected s3://<bucket>[/<prefix>]", bucketURL)
+	}
+	return u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+// DRManager configures disaster recovery on spoke clusters from the hub
+type DRManager interface {
+	// Enable installs the OADP operator on a spoke and configures its backup storage location
+	Enable(ctx context.Context, clusterName string, storage BackupStorage) error
+}
+
+type drManager struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewDRManager creates a new DRManager
+func NewDRManager(dynamicClient dynamic.Interface, options ...kube.Option) DRManager {
+	return &drManager{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Enable creates or updates the OADP ManifestWork in the cluster namespace on the hub.
+// The work agent on the spoke applies the manifests and retries the DataProtectionApplication
+// until the operator has installed its CRD.
+func (d *drManager) Enable(ctx context.Context, clusterName string, storage BackupStorage) error {
+	ctx, cancel, err := d.options.Start(ctx, "enable DR", "cluster", clusterName, "bucket", storage.Bucket)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if storage.Bucket == "" {
+		return fmt.Errorf("backup storage bucket is required")
+	}
+	if storage.Region == "" {
+		return fmt.Errorf("backup storage region is required")
+	}
+	if storage.AccessKeyID == "" || storage.SecretAccessKey == "" {
+		return fmt.Errorf("backup storage credentials are required")
+	}
+
+	work := oadpManifestWork(clusterName, storage)
+	works := d.dynamicClient.Resource(manifestWorkGVR).Namespace(clusterName)
+
+	existing, err := works.Get(ctx, DRManifestWorkName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := works.Create(ctx, work, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create ManifestWork %s/%s: %w", clusterName, DRManifestWorkName, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ManifestWork %s/%s: %w", clusterName, DRManifestWorkName, err)
+	}
+
+	work.SetResourceVersion(existing.GetResourceVersion())
+	if _, err := works.Update(ctx, work, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update ManifestWork %s/%s: %w", clusterName, DRManifestWorkName, err)
+	}
+	return nil
+}
+
+// oadpManifestWork builds the ManifestWork installing OADP and a Velero backup storage location
+func oadpManifestWork(clusterName string, storage BackupStorage) *unstructured.Unstructured {
+	credentials := fmt.Sprintf("[default]\naws_access_key_id=%s\naws_secret_access_key=%s\n",
+		storage.AccessKeyID, storage.SecretAccessKey)
+
+	objectStorage := map[string]interface{}{
+		"bucket": storage.Bucket,
+	}
+	if storage.Prefix != "" {
+		objectStorage["prefix"] = storage.Prefix
+	}
+
+	manifests := append(operatorManifests(OADPNamespace, "redhat-oadp-operator", OADPChannel),
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":      oadpCredentialsSecret,
+				"namespace": OADPNamespace,
+			},
+			"type": "Opaque",
+			"data": map[string]interface{}{
+				"cloud": base64.StdEncoding.EncodeToString([]byte(credentials)),
+			},
+		},
+		map[string]interface{}{
+			"apiVersion": "oadp.openshift.io/v1alpha1",
+			"kind":       "DataProtectionApplication",
+			"metadata": map[string]interface{}{
+				"name":      "labrat",
+				"namespace": OADPNamespace,
+			},
+			"spec": map[string]interface{}{
+				"configuration": map[string]interface{}{
+					"velero": map[string]interface{}{
+						"defaultPlugins": []interface{}{"openshift", "aws"},
+					},
+					"nodeAgent": map[string]interface{}{
+						"enable":       true,
+						"uploaderType": "kopia",
+					},
+				},
+				"backupLocations": []interface{}{
+					map[string]interface{}{
+						"velero": map[string]interface{}{
+							"provider":      "aws",
+							"default":       true,
+							"objectStorage": objectStorage,
+							"config": map[string]interface{}{
+								"region":  storage.Region,
+								"profile": "default",
+							},
+							"credential": map[string]interface{}{
+								"name": oadpCredentialsSecret,
+								"key":  "cloud",
+							},
+						},
+					},
+				},
+			},
+		},
+	)
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "work.open-cluster-management.io/v1",
+			"kind":       "ManifestWork",
+			"metadata": map[string]interface{}{
+				"name":      DRManifestWorkName,
+				"namespace": clusterName,
+				"labels": map[string]interface{}{
+					"app.kubernetes.io/managed-by": "labrat",
+				},
+			},
+			"spec": map[string]interface{}{
+				"workload": map[string]interface{}{
+					"manifests": manifests,
+				},
+			},
+		},
+	}
+}
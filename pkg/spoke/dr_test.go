@@ -0,0 +1,136 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ParseBucketURL", func() {
+	It("should split the bucket and prefix", func() {
+		bucket, prefix, err := spoke.ParseBucketURL("s3://lab-backups/spokes/my-cluster/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bucket).To(Equal("lab-backups"))
+		Expect(prefix).To(Equal("spokes/my-cluster"))
+	})
+
+	It("should allow a bucket without prefix", func() {
+		bucket, prefix, err := spoke.ParseBucketURL("s3://lab-backups")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bucket).To(Equal("lab-backups"))
+		Expect(prefix).To(BeEmpty())
+	})
+
+	It("should reject URLs that are not S3 buckets", func() {
+		_, _, err := spoke.ParseBucketURL("https://lab-backups.s3.amazonaws.com")
+		Expect(err).To(MatchError(ContainSubstring("expected s3://<bucket>[/<prefix>]")))
+
+		_, _, err = spoke.ParseBucketURL("lab-backups")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("DRManager", func() {
+	var (
+		ctx         context.Context
+		fakeDynamic *fake.FakeDynamicClient
+		dr          spoke.DRManager
+		gvr         schema.GroupVersionResource
+		storage     spoke.BackupStorage
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		gvr = schema.GroupVersionResource{Group: "work.open-cluster-management.io", Version: "v1", Resource: "manifestworks"}
+		fakeDynamic = fake.NewSimpleDynamicClient(runtime.NewScheme())
+		dr = spoke.NewDRManager(fakeDynamic)
+		storage = spoke.BackupStorage{
+			Bucket:          "lab-backups",
+			Prefix:          "spokes",
+			Region:          "us-east-2",
+			AccessKeyID:     "AKIAEXAMPLE",
+			SecretAccessKey: "secret",
+		}
+	})
+
+	getManifests := func() []interface{} {
+		work, err := fakeDynamic.Resource(gvr).Namespace("test-cluster").Get(ctx, spoke.DRManifestWorkName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		manifests, found, err := unstructured.NestedSlice(work.Object, "spec", "workload", "manifests")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		return manifests
+	}
+
+	findManifest := func(manifests []interface{}, kind string) map[string]interface{} {
+		for _, m := range manifests {
+			obj := m.(map[string]interface{})
+			if obj["kind"] == kind {
+				return obj
+			}
+		}
+		Fail("manifest " + kind + " not found")
+		return nil
+	}
+
+	It("should create a ManifestWork installing OADP in the cluster namespace", func() {
+		Expect(dr.Enable(ctx, "test-cluster", storage)).To(Succeed())
+
+		manifests := getManifests()
+		kinds := []string{}
+		for _, m := range manifests {
+			kinds = append(kinds, m.(map[string]interface{})["kind"].(string))
+		}
+		Expect(kinds).To(Equal([]string{"Namespace", "OperatorGroup", "Subscription", "Secret", "DataProtectionApplication"}))
+
+		subscription := findManifest(manifests, "Subscription")
+		channel, _, _ := unstructured.NestedString(subscription, "spec", "channel")
+		Expect(channel).To(Equal(spoke.OADPChannel))
+
+		secret := findManifest(manifests, "Secret")
+		encoded, _, _ := unstructured.NestedString(secret, "data", "cloud")
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(decoded)).To(ContainSubstring("aws_access_key_id=AKIAEXAMPLE"))
+
+		dpa := findManifest(manifests, "DataProtectionApplication")
+		locations, _, _ := unstructured.NestedSlice(dpa, "spec", "backupLocations")
+		Expect(locations).To(HaveLen(1))
+		velero := locations[0].(map[string]interface{})["velero"].(map[string]interface{})
+		Expect(velero["objectStorage"]).To(Equal(map[string]interface{}{"bucket": "lab-backups", "prefix": "spokes"}))
+		region, _, _ := unstructured.NestedString(velero, "config", "region")
+		Expect(region).To(Equal("us-east-2"))
+	})
+
+	It("should update an existing ManifestWork", func() {
+		Expect(dr.Enable(ctx, "test-cluster", storage)).To(Succeed())
+
+		storage.Bucket = "other-backups"
+		storage.Prefix = ""
+		Expect(dr.Enable(ctx, "test-cluster", storage)).To(Succeed())
+
+		dpa := findManifest(getManifests(), "DataProtectionApplication")
+		locations, _, _ := unstructured.NestedSlice(dpa, "spec", "backupLocations")
+		velero := locations[0].(map[string]interface{})["velero"].(map[string]interface{})
+		Expect(velero["objectStorage"]).To(Equal(map[string]interface{}{"bucket": "other-backups"}))
+	})
+
+	It("should reject incomplete backup storage", func() {
+		storage.Region = ""
+		Expect(dr.Enable(ctx, "test-cluster", storage)).To(MatchError(ContainSubstring("region is required")))
+
+		storage.Region = "us-east-2"
+		storage.SecretAccessKey = ""
+		Expect(dr.Enable(ctx, "test-cluster", storage)).To(MatchError(ContainSubstring("credentials are required")))
+	})
+})
@@ -0,0 +1,237 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// EventSourceHub marks events of the cluster namespace on the hub, recorded by Hive and ACM
+	EventSourceHub = "hub"
+	// EventSourceSpoke marks events recorded on the spoke cluster itself
+	EventSourceSpoke = "spoke"
+)
+
+// ClusterEvent is an event about a spoke cluster, recorded on the hub or on the spoke
+type ClusterEvent struct {
+	// Source is EventSourceHub or EventSourceSpoke
+	Source    string `json:"source"`
+	Namespace string `json:"namespace"`
+	StatusEvent
+}
+
+// EventOptions selects the events an EventReader returns
+type EventOptions struct {
+	// Since drops the events last seen before it; zero keeps every event
+	Since time.Time
+	// Types keeps only the events of these types, e.g. Warning; empty keeps every type
+	Types []string
+	// Reasons keeps only the events with these reasons, compared case-insensitively; empty
+	// keeps every reason
+	Reasons []string
+	// Kinds keeps only the events about objects of these kinds, e.g. ClusterDeployment; empty
+	// keeps every kind
+	Kinds []string
+}
+
+// matches reports whether event is selected by the options
+func (o EventOptions) matches(event ClusterEvent) bool {
+	if !o.Since.IsZero() && event.LastSeen.Before(o.Since) {
+		return false
+	}
+	if len(o.Types) > 0 && !slices.Contains(o.Types, event.Type) {
+		return false
+	}
+	if len(o.Reasons) > 0 && !slices.ContainsFunc(o.Reasons, func(reason string) bool { return strings.EqualFold(reason, event.Reason) }) {
+		return false
+	}
+	kind, _, _ := strings.Cut(event.Object, "/")
+	return len(o.Kinds) == 0 || slices.Contains(o.Kinds, kind)
+}
+
+// EventReader reads the events of a namespace, or of every namespace if it is empty, from
+// the hub or a spoke cluster
+type EventReader interface {
+	// List returns the events matching opts, oldest first
+	List(ctx context.Context, namespace string, opts EventOptions) ([]ClusterEvent, error)
+	// Follow calls emit with the events matching opts, first the existing ones oldest first,
+	// then every event that is recorded or repeated, until ctx is done
+	Follow(ctx context.Context, namespace string, opts EventOptions, emit func(ClusterEvent)) error
+}
+
+type eventReader struct {
+	coreClient kubernetes.Interface
+	source     string
+	options    kube.Options
+}
+
+// NewEventReader creates a new EventReader for the cluster coreClient is connected to; source
+// is EventSourceHub or EventSourceSpoke and is set on the events read
+func NewEventReader(coreClient kubernetes.Interface, source string, options ...kube.Option) EventReader {
+	return &eventReader{
+		coreClient: coreClient,
+		source:     source,
+		options:    kube.NewOptions(options...),
+	}
+}
+
+// List lists the events and sorts them by when they were last seen
+func (r *eventReader) List(ctx context.Context, namespace string, opts EventOptions) ([]ClusterEvent, error) {
+	list, err := r.list(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]ClusterEvent, 0, len(list.Items))
+	for i := range list.Items {
+		if event := r.toClusterEvent(&list.Items[i]); opts.matches(event) {
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeen.Before(events[j].LastSeen) })
+	return events, nil
+}
+
+// Follow lists the events, then watches them from the resource version of the list. The
+// watch is re-established when the server ends it, and the events are listed again when
+// its resource version expired; events already emitted are only emitted again when they
+// are repeated.
+func (r *eventReader) Follow(ctx context.Context, namespace string, opts EventOptions, emit func(ClusterEvent)) error {
+	lastSeen := make(map[types.UID]time.Time)
+	emitNew := func(event *corev1.Event) {
+		eventTime := eventLastSeen(event)
+		if previous, seen := lastSeen[event.UID]; seen && !eventTime.After(previous) {
+			return
+		}
+		lastSeen[event.UID] = eventTime
+		if clusterEvent := r.toClusterEvent(event); opts.matches(clusterEvent) {
+			emit(clusterEvent)
+		}
+	}
+
+	for {
+		list, err := r.list(ctx, namespace)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		sort.SliceStable(list.Items, func(i, j int) bool {
+			return eventLastSeen(&list.Items[i]).Before(eventLastSeen(&list.Items[j]))
+		})
+		for i := range list.Items {
+			emitNew(&list.Items[i])
+		}
+
+		resourceVersion := list.ResourceVersion
+		for {
+			// The watch outlives the operation timeout, so only the rate limit and logging of Start apply
+			_, cancel, err := r.options.Start(ctx, "watch events", "namespace", namespace)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			cancel()
+			w, err := r.coreClient.CoreV1().Events(namespace).Watch(ctx, metav1.ListOptions{ResourceVersion: resourceVersion})
+			if ctx.Err() != nil {
+				return nil
+			}
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to watch events of %s: %w", namespaceOrAll(namespace), err)
+			}
+			expired, err := forwardEvents(ctx, w, emitNew, &resourceVersion)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("watch of events of %s failed: %w", namespaceOrAll(namespace), err)
+			}
+			if expired {
+				break
+			}
+		}
+	}
+}
+
+// forwardEvents passes the events of w to emit until w ends, ctx is done, or it fails,
+// recording the resource version of each event. It reports whether the resource version
+// expired, so that the events must be listed again.
+func forwardEvents(ctx context.Context, w watch.Interface, emit func(*corev1.Event), resourceVersion *string) (bool, error) {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			if e.Type == watch.Error {
+				if status, isStatus := e.Object.(*metav1.Status); isStatus && status.Code == http.StatusGone {
+					return true, nil
+				}
+				return false, fmt.Errorf("%v", e.Object)
+			}
+			event, isEvent := e.Object.(*corev1.Event)
+			if !isEvent {
+				continue
+			}
+			*resourceVersion = event.ResourceVersion
+			if e.Type == watch.Added || e.Type == watch.Modified {
+				emit(event)
+			}
+		}
+	}
+}
+
+// list lists the events of namespace
+func (r *eventReader) list(ctx context.Context, namespace string) (*corev1.EventList, error) {
+	ctx, cancel, err := r.options.Start(ctx, "list events", "namespace", namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *corev1.EventList
+	err = r.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = r.coreClient.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events of %s: %w", namespaceOrAll(namespace), err)
+	}
+	return list, nil
+}
+
+// toClusterEvent converts an event read from the cluster of r
+func (r *eventReader) toClusterEvent(event *corev1.Event) ClusterEvent {
+	return ClusterEvent{Source: r.source, Namespace: event.Namespace, StatusEvent: toStatusEvent(event)}
+}
+
+// namespaceOrAll names a namespace in messages, where the empty namespace is every namespace
+func namespaceOrAll(namespace string) string {
+	if namespace == "" {
+		return "all namespaces"
+	}
+	return namespace
+}
@@ -0,0 +1,162 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("EventReader", func() {
+	var now time.Time
+
+	event := func(name, eventType, reason string, age time.Duration, count int32) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "lab-1", UID: types.UID(name)},
+			InvolvedObject: corev1.ObjectReference{Kind: "ClusterDeployment", Name: "lab-1"},
+			Type:           eventType,
+			Reason:         reason,
+			Message:        reason + " message",
+			Count:          count,
+			LastTimestamp:  metav1.NewTime(now.Add(-age)),
+		}
+	}
+
+	BeforeEach(func() {
+		now = time.Now().Truncate(time.Second)
+	})
+
+	It("should list the events of a namespace oldest first", func() {
+		coreClient := k8sFake.NewSimpleClientset(
+			event("provision-failed", corev1.EventTypeWarning, "ProvisionFailed", time.Minute, 3),
+			event("provisioning", corev1.EventTypeNormal, "Provisioning", time.Hour, 1),
+		)
+
+		events, err := spoke.NewEventReader(coreClient, spoke.EventSourceHub).List(context.Background(), "lab-1", spoke.EventOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Reason).To(Equal("Provisioning"))
+		Expect(events[1]).To(Equal(spoke.ClusterEvent{
+			Source:    spoke.EventSourceHub,
+			Namespace: "lab-1",
+			StatusEvent: spoke.StatusEvent{
+				Type:     corev1.EventTypeWarning,
+				Reason:   "ProvisionFailed",
+				Object:   "ClusterDeployment/lab-1",
+				Message:  "ProvisionFailed message",
+				Count:    3,
+				LastSeen: now.Add(-time.Minute),
+			},
+		}))
+	})
+
+	It("should filter the events by age and type", func() {
+		coreClient := k8sFake.NewSimpleClientset(
+			event("old-warning", corev1.EventTypeWarning, "BackOff", 2*time.Hour, 1),
+			event("new-warning", corev1.EventTypeWarning, "ProvisionFailed", time.Minute, 1),
+			event("new-normal", corev1.EventTypeNormal, "Provisioning", time.Minute, 1),
+		)
+
+		events, err := spoke.NewEventReader(coreClient, spoke.EventSourceSpoke).List(context.Background(), "", spoke.EventOptions{
+			Since: now.Add(-time.Hour),
+			Types: []string{corev1.EventTypeWarning},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Reason).To(Equal("ProvisionFailed"))
+		Expect(events[0].Source).To(Equal(spoke.EventSourceSpoke))
+	})
+
+	It("should filter the events by reason and object kind", func() {
+		managedClusterEvent := event("available", corev1.EventTypeNormal, "AvailableCheck", time.Minute, 1)
+		managedClusterEvent.InvolvedObject = corev1.ObjectReference{Kind: "ManagedCluster", Name: "lab-1"}
+		podEvent := event("backoff", corev1.EventTypeWarning, "BackOff", time.Minute, 1)
+		podEvent.InvolvedObject = corev1.ObjectReference{Kind: "Pod", Name: "lab-1-0-provision"}
+		coreClient := k8sFake.NewSimpleClientset(
+			managedClusterEvent,
+			podEvent,
+			event("provision-failed", corev1.EventTypeWarning, "ProvisionFailed", time.Minute, 1),
+			event("provisioning", corev1.EventTypeNormal, "Provisioning", time.Minute, 1),
+		)
+		reader := spoke.NewEventReader(coreClient, spoke.EventSourceHub)
+
+		events, err := reader.List(context.Background(), "", spoke.EventOptions{
+			Kinds: []string{"ClusterDeployment", "ManagedCluster"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+
+		events, err = reader.List(context.Background(), "", spoke.EventOptions{
+			Reasons: []string{"provisionfailed", "BackOff"},
+			Kinds:   []string{"ClusterDeployment", "ManagedCluster"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Object).To(Equal("ClusterDeployment/lab-1"))
+	})
+
+	It("should follow new and repeated events", func() {
+		existing := event("provisioning", corev1.EventTypeNormal, "Provisioning", time.Hour, 1)
+		coreClient := k8sFake.NewSimpleClientset(existing)
+		watcher := watch.NewFake()
+		coreClient.PrependWatchReactor("events", func(k8stesting.Action) (bool, watch.Interface, error) {
+			return true, watcher, nil
+		})
+
+		var (
+			mu      sync.Mutex
+			reasons []string
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan error, 1)
+		go func() {
+			done <- spoke.NewEventReader(coreClient, spoke.EventSourceHub).Follow(ctx, "lab-1", spoke.EventOptions{}, func(e spoke.ClusterEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				reasons = append(reasons, e.Reason)
+			})
+		}()
+		collected := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string(nil), reasons...)
+		}
+
+		Eventually(collected).Should(Equal([]string{"Provisioning"}))
+
+		// The existing event is not emitted again until it is repeated
+		watcher.Modify(existing)
+		watcher.Add(event("provision-failed", corev1.EventTypeWarning, "ProvisionFailed", 0, 1))
+		repeated := existing.DeepCopy()
+		repeated.Count = 2
+		repeated.LastTimestamp = metav1.NewTime(now)
+		watcher.Modify(repeated)
+		Eventually(collected).Should(Equal([]string{"Provisioning", "ProvisionFailed", "Provisioning"}))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("should report a failing list", func() {
+		coreClient := k8sFake.NewSimpleClientset()
+		coreClient.PrependReactor("list", "events", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, context.DeadlineExceeded
+		})
+
+		_, err := spoke.NewEventReader(coreClient, spoke.EventSourceHub).List(context.Background(), "lab-1", spoke.EventOptions{})
+		Expect(err).To(MatchError(ContainSubstring("failed to list events of lab-1")))
+	})
+})
@@ -0,0 +1,340 @@
+package spoke
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// HealthReportName is the report (and JUnit test suite) name used by health checks
+	HealthReportName = "labrat.spoke.health"
+	// DefaultCertWarning is how long before it expires a platform certificate is reported.
+	// OpenShift rotates its short-lived certificates well before this.
+	DefaultCertWarning = 7 * 24 * time.Hour
+
+	// healthListLimit is the number of names a check message lists before summarizing
+	healthListLimit = 3
+)
+
+var (
+	// clusterOperatorGVR identifies the OpenShift ClusterOperator resource
+	clusterOperatorGVR = schema.GroupVersionResource{
+		Group:    "config.openshift.io",
+		Version:  "v1",
+		Resource: "clusteroperators",
+	}
+	// machineConfigPoolGVR identifies the MachineConfigPools of the machine config operator
+	machineConfigPoolGVR = schema.GroupVersionResource{
+		Group:    "machineconfiguration.openshift.io",
+		Version:  "v1",
+		Resource: "machineconfigpools",
+	}
+)
+
+// platformNamespacePrefixes are the prefixes of the namespaces whose TLS secrets are checked
+// for expiring certificates
+var platformNamespacePrefixes = []string{"openshift-", "kube-"}
+
+// HealthOptions configures a health check run
+type HealthOptions struct {
+	// CertWarning is how long before it expires a certificate is reported as a warning
+	CertWarning time.Duration
+	// Now returns the current time, time.Now by default
+	Now func() time.Time
+}
+
+// HealthChecker checks the health of the platform of a spoke cluster
+type HealthChecker interface {
+	// Run executes the health checks and returns their results
+	Run(ctx context.Context) check.Report
+}
+
+type healthChecker struct {
+	coreClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
+	opts          HealthOptions
+	options       kube.Options
+}
+
+// NewHealthChecker creates a new HealthChecker using clients connected to the spoke cluster
+func NewHealthChecker(coreClient kubernetes.Interface, dynamicClient dynamic.Interface, opts HealthOptions, options ...kube.Option) HealthChecker {
+	if opts.CertWarning <= 0 {
+		opts.CertWarning = DefaultCertWarning
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return &healthChecker{
+		coreClient:    coreClient,
+		dynamicClient: dynamicClient,
+		opts:          opts,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Run executes the health checks:
+// 1. ClusterOperators are available and not degraded
+// 2. Nodes are ready
+// 3. MachineConfigPools are not degraded
+// 4. No CertificateSigningRequests are pending
+// 5. The certificates of the platform TLS secrets are not expired or about to expire
+func (h *healthChecker) Run(ctx context.Context) check.Report {
+	report := check.Report{Name: HealthReportName}
+	ctx, cancel, err := h.options.Start(ctx, "run health check")
+	if err != nil {
+		report.Add(check.Result{Name: "Rate limit", Status: check.StatusFail, Message: err.Error()})
+		return report
+	}
+	defer cancel()
+
+	report.Run("Cluster operators", func() (check.Status, string) { return h.checkClusterOperators(ctx) })
+	report.Run("Node readiness", func() (check.Status, string) { return h.checkNodes(ctx) })
+	report.Run("Machine config pools", func() (check.Status, string) { return h.checkMachineConfigPools(ctx) })
+	report.Run("Pending CSRs", func() (check.Status, string) { return h.checkCSRs(ctx) })
+	report.Run("Certificate expiration", func() (check.Status, string) { return h.checkCertificates(ctx) })
+	return report
+}
+
+// list lists the cluster-scoped resources of gvr
+func (h *healthChecker) list(ctx context.Context, gvr schema.GroupVersionResource) (*unstructured.UnstructuredList, error) {
+	var list *unstructured.UnstructuredList
+	err := h.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = h.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	return list, err
+}
+
+// checkClusterOperators fails if an operator is degraded or unavailable and warns while one
+// is progressing
+func (h *healthChecker) checkClusterOperators(ctx context.Context) (check.Status, string) {
+	list, err := h.list(ctx, clusterOperatorGVR)
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to list cluster operators: %v", err)
+	}
+	if len(list.Items) == 0 {
+		return check.StatusFail, "no cluster operators found"
+	}
+
+	var degraded, unavailable, progressing []string
+	for _, item := range list.Items {
+		conditions := conditionStatuses(item.Object)
+		if conditions["Degraded"] == string(metav1.ConditionTrue) {
+			degraded = append(degraded, item.GetName())
+		}
+		if conditions["Available"] != string(metav1.ConditionTrue) {
+			unavailable = append(unavailable, item.GetName())
+		}
+		if conditions["Progressing"] == string(metav1.ConditionTrue) {
+			progressing = append(progressing, item.GetName())
+		}
+	}
+
+	var problems []string
+	if len(degraded) > 0 {
+		problems = append(problems, "degraded: "+summarizeNames(degraded))
+	}
+	if len(unavailable) > 0 {
+		problems = append(problems, "unavailable: "+summarizeNames(unavailable))
+	}
+	if len(problems) > 0 {
+		return check.StatusFail, strings.Join(problems, "; ")
+	}
+	if len(progressing) > 0 {
+		return check.StatusWarn, "progressing: " + summarizeNames(progressing)
+	}
+	return check.StatusPass, fmt.Sprintf("%d operators available", len(list.Items))
+}
+
+// checkNodes fails if a node is not ready and warns if one is cordoned
+func (h *healthChecker) checkNodes(ctx context.Context) (check.Status, string) {
+	var list *corev1.NodeList
+	err := h.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = h.coreClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to list nodes: %v", err)
+	}
+	if len(list.Items) == 0 {
+		return check.StatusFail, "no nodes found"
+	}
+
+	nodes := make([]NodeInfo, 0, len(list.Items))
+	for i := range list.Items {
+		nodes = append(nodes, parseNode(&list.Items[i]))
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	var notReady, cordoned []string
+	for _, node := range nodes {
+		if !node.Ready() {
+			notReady = append(notReady, node.Name)
+		}
+		if strings.HasSuffix(node.Status, NodeSchedulingDisabled) {
+			cordoned = append(cordoned, node.Name)
+		}
+	}
+	switch {
+	case len(notReady) > 0:
+		return check.StatusFail, fmt.Sprintf("%d of %d nodes not ready: %s", len(notReady), len(nodes), summarizeNames(notReady))
+	case len(cordoned) > 0:
+		return check.StatusWarn, fmt.Sprintf("%d of %d nodes cordoned: %s", len(cordoned), len(nodes), summarizeNames(cordoned))
+	}
+	return check.StatusPass, fmt.Sprintf("%d nodes ready", len(nodes))
+}
+
+// checkMachineConfigPools fails if a pool is degraded and warns while one is updating
+func (h *healthChecker) checkMachineConfigPools(ctx context.Context) (check.Status, string) {
+	list, err := h.list(ctx, machineConfigPoolGVR)
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to list machine config pools: %v", err)
+	}
+
+	var degraded, updating []string
+	for _, item := range list.Items {
+		conditions := conditionStatuses(item.Object)
+		if conditions["Degraded"] == string(metav1.ConditionTrue) {
+			degraded = append(degraded, item.GetName())
+		} else if conditions["Updating"] == string(metav1.ConditionTrue) {
+			updating = append(updating, item.GetName())
+		}
+	}
+	switch {
+	case len(degraded) > 0:
+		return check.StatusFail, "degraded: " + summarizeNames(degraded)
+	case len(updating) > 0:
+		return check.StatusWarn, "updating: " + summarizeNames(updating)
+	}
+	return check.StatusPass, fmt.Sprintf("%d pools up to date", len(list.Items))
+}
+
+// checkCSRs warns if CertificateSigningRequests are neither approved nor denied, e.g. those of
+// new nodes or of a klusterlet that cannot register
+func (h *healthChecker) checkCSRs(ctx context.Context) (check.Status, string) {
+	var list *certificatesv1.CertificateSigningRequestList
+	err := h.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = h.coreClient.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to list certificate signing requests: %v", err)
+	}
+
+	var pending []string
+	for _, csr := range list.Items {
+		if csrStatus(csr) == CSRPending {
+			pending = append(pending, csr.Name)
+		}
+	}
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		return check.StatusWarn, fmt.Sprintf("%d pending: %s", len(pending), summarizeNames(pending))
+	}
+	return check.StatusPass, "none pending"
+}
+
+// checkCertificates fails if a certificate of a TLS secret in a platform namespace expired
+// and warns if one expires within the warning period
+func (h *healthChecker) checkCertificates(ctx context.Context) (check.Status, string) {
+	var list *corev1.SecretList
+	err := h.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = h.coreClient.CoreV1().Secrets("").List(ctx, metav1.ListOptions{FieldSelector: "type=" + string(corev1.SecretTypeTLS)})
+		return err
+	})
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to list TLS secrets: %v", err)
+	}
+
+	now := h.opts.Now()
+	var expired, expiring []string
+	checked := 0
+	for _, secret := range list.Items {
+		if secret.Type != corev1.SecretTypeTLS || !platformNamespace(secret.Namespace) {
+			continue
+		}
+		notAfter, ok := certificateExpiry(secret.Data[corev1.TLSCertKey])
+		if !ok {
+			continue
+		}
+		checked++
+		name := secret.Namespace + "/" + secret.Name
+		switch {
+		case !notAfter.After(now):
+			expired = append(expired, name)
+		case notAfter.Sub(now) <= h.opts.CertWarning:
+			expiring = append(expiring, fmt.Sprintf("%s (%s)", name, notAfter.UTC().Format(time.RFC3339)))
+		}
+	}
+	sort.Strings(expired)
+	sort.Strings(expiring)
+	switch {
+	case len(expired) > 0:
+		return check.StatusFail, fmt.Sprintf("%d expired: %s", len(expired), summarizeNames(expired))
+	case len(expiring) > 0:
+		return check.StatusWarn, fmt.Sprintf("%d expire within %s: %s", len(expiring), h.opts.CertWarning, summarizeNames(expiring))
+	}
+	return check.StatusPass, fmt.Sprintf("%d certificates valid for more than %s", checked, h.opts.CertWarning)
+}
+
+// platformNamespace reports whether namespace belongs to the OpenShift or Kubernetes platform
+func platformNamespace(namespace string) bool {
+	for _, prefix := range platformNamespacePrefixes {
+		if strings.HasPrefix(namespace, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// certificateExpiry returns when the first certificate of a PEM bundle expires, false if it
+// holds none
+func certificateExpiry(data []byte) (time.Time, bool) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return cert.NotAfter, true
+}
+
+// conditionStatuses maps the types of the status.conditions of obj to their status
+func conditionStatuses(obj map[string]interface{}) map[string]string {
+	statuses := make(map[string]string)
+	for _, condition := range parseConditions(obj) {
+		statuses[condition.Type] = condition.Status
+	}
+	return statuses
+}
+
+// summarizeNames lists the first names, e.g. "a, b, c and 2 more"
+func summarizeNames(names []string) string {
+	if len(names) <= healthListLimit {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:healthListLimit], ", "), len(names)-healthListLimit)
+}
@@ -0,0 +1,194 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+var _ = Describe("HealthChecker", func() {
+	var (
+		ctx     context.Context
+		now     time.Time
+		objects []runtime.Object
+		core    []runtime.Object
+	)
+
+	// withConditions builds a cluster-scoped resource with status conditions of type: status
+	withConditions := func(apiVersion, kind, name string, conditions map[string]string) *unstructured.Unstructured {
+		list := make([]interface{}, 0, len(conditions))
+		for conditionType, status := range conditions {
+			list = append(list, map[string]interface{}{"type": conditionType, "status": status})
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name},
+			"status":     map[string]interface{}{"conditions": list},
+		}}
+	}
+	operator := func(name string, conditions map[string]string) *unstructured.Unstructured {
+		return withConditions("config.openshift.io/v1", "ClusterOperator", name, conditions)
+	}
+	pool := func(name string, conditions map[string]string) *unstructured.Unstructured {
+		return withConditions("machineconfiguration.openshift.io/v1", "MachineConfigPool", name, conditions)
+	}
+	node := func(name string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{
+				Type:   corev1.NodeReady,
+				Status: ready,
+			}}},
+		}
+	}
+	tlsSecret := func(namespace, name string, notAfter time.Time) *corev1.Secret {
+		cert, key := clientCertificate(notAfter)
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key},
+		}
+	}
+
+	runHealth := func() check.Report {
+		fakeDynamic := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			{Group: "config.openshift.io", Version: "v1", Resource: "clusteroperators"}:                 "ClusterOperatorList",
+			{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigpools"}: "MachineConfigPoolList",
+		}, objects...)
+		checker := spoke.NewHealthChecker(k8sFake.NewSimpleClientset(core...), fakeDynamic, spoke.HealthOptions{
+			Now: func() time.Time { return now },
+		})
+		return checker.Run(ctx)
+	}
+
+	// statuses maps the names of the checks of report to their status
+	statuses := func(report check.Report) map[string]check.Status {
+		result := make(map[string]check.Status)
+		for _, r := range report.Results {
+			result[r.Name] = r.Status
+		}
+		return result
+	}
+
+	// message returns the message of the named check of report
+	message := func(report check.Report, name string) string {
+		for _, r := range report.Results {
+			if r.Name == name {
+				return r.Message
+			}
+		}
+		return ""
+	}
+
+	healthy := map[string]string{"Available": "True", "Degraded": "False", "Progressing": "False"}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Now()
+		objects = []runtime.Object{
+			operator("etcd", healthy),
+			operator("ingress", healthy),
+			pool("master", map[string]string{"Degraded": "False", "Updating": "False"}),
+			pool("worker", map[string]string{"Degraded": "False", "Updating": "False"}),
+		}
+		core = []runtime.Object{
+			node("master-0", corev1.ConditionTrue),
+			node("worker-0", corev1.ConditionTrue),
+			tlsSecret("openshift-ingress", "router-certs-default", now.Add(365*24*time.Hour)),
+			&certificatesv1.CertificateSigningRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "csr-approved"},
+				Status: certificatesv1.CertificateSigningRequestStatus{Conditions: []certificatesv1.CertificateSigningRequestCondition{{
+					Type:   certificatesv1.CertificateApproved,
+					Status: corev1.ConditionTrue,
+				}}},
+			},
+		}
+	})
+
+	It("should pass every check of a healthy cluster", func() {
+		report := runHealth()
+
+		Expect(report.Name).To(Equal(spoke.HealthReportName))
+		Expect(statuses(report)).To(Equal(map[string]check.Status{
+			"Cluster operators":      check.StatusPass,
+			"Node readiness":         check.StatusPass,
+			"Machine config pools":   check.StatusPass,
+			"Pending CSRs":           check.StatusPass,
+			"Certificate expiration": check.StatusPass,
+		}))
+		Expect(report.Err()).NotTo(HaveOccurred())
+		Expect(message(report, "Cluster operators")).To(Equal("2 operators available"))
+	})
+
+	It("should fail on degraded operators, unready nodes, and degraded pools", func() {
+		objects[0] = operator("etcd", map[string]string{"Available": "True", "Degraded": "True"})
+		objects[1] = operator("ingress", map[string]string{"Available": "False"})
+		objects[3] = pool("worker", map[string]string{"Degraded": "True"})
+		core[1] = node("worker-0", corev1.ConditionFalse)
+
+		report := runHealth()
+
+		Expect(statuses(report)).To(HaveKeyWithValue("Cluster operators", check.StatusFail))
+		Expect(statuses(report)).To(HaveKeyWithValue("Node readiness", check.StatusFail))
+		Expect(statuses(report)).To(HaveKeyWithValue("Machine config pools", check.StatusFail))
+		Expect(message(report, "Cluster operators")).To(Equal("degraded: etcd; unavailable: ingress"))
+		Expect(message(report, "Node readiness")).To(Equal("1 of 2 nodes not ready: worker-0"))
+		Expect(message(report, "Machine config pools")).To(Equal("degraded: worker"))
+		Expect(report.Err()).To(MatchError("3 of 5 checks failed"))
+	})
+
+	It("should warn on progressing operators, updating pools, and pending CSRs", func() {
+		objects[0] = operator("etcd", map[string]string{"Available": "True", "Progressing": "True"})
+		objects[2] = pool("master", map[string]string{"Updating": "True"})
+		core = append(core, &certificatesv1.CertificateSigningRequest{ObjectMeta: metav1.ObjectMeta{Name: "csr-pending"}})
+
+		report := runHealth()
+
+		Expect(statuses(report)).To(HaveKeyWithValue("Cluster operators", check.StatusWarn))
+		Expect(statuses(report)).To(HaveKeyWithValue("Machine config pools", check.StatusWarn))
+		Expect(statuses(report)).To(HaveKeyWithValue("Pending CSRs", check.StatusWarn))
+		Expect(message(report, "Pending CSRs")).To(Equal("1 pending: csr-pending"))
+		Expect(report.Err()).NotTo(HaveOccurred())
+	})
+
+	It("should report expired and expiring platform certificates", func() {
+		core = append(core,
+			tlsSecret("openshift-etcd", "etcd-serving", now.Add(48*time.Hour)),
+			tlsSecret("partner-app", "expired-app-cert", now.Add(-time.Hour)),
+		)
+		report := runHealth()
+		Expect(statuses(report)).To(HaveKeyWithValue("Certificate expiration", check.StatusWarn))
+		Expect(message(report, "Certificate expiration")).To(HavePrefix("1 expire within 168h0m0s: openshift-etcd/etcd-serving"))
+
+		core = append(core, tlsSecret("kube-system", "old", now.Add(-time.Hour)))
+		report = runHealth()
+		Expect(statuses(report)).To(HaveKeyWithValue("Certificate expiration", check.StatusFail))
+		Expect(message(report, "Certificate expiration")).To(Equal("1 expired: kube-system/old"))
+	})
+
+	It("should summarize long lists of names", func() {
+		objects = objects[2:]
+		for _, name := range []string{"a", "b", "c", "d", "e"} {
+			objects = append(objects, operator(name, map[string]string{"Available": "True", "Degraded": "True"}))
+		}
+
+		report := runHealth()
+		Expect(message(report, "Cluster operators")).To(Equal("degraded: a, b, c and 2 more"))
+	})
+})
@@ -0,0 +1,81 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// AdminCredentials are the kubeadmin credentials Hive stores for an installed cluster
+type AdminCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// KubeadminPasswordExtractor provides methods to extract the kubeadmin credentials of spoke clusters
+type KubeadminPasswordExtractor interface {
+	// Extract retrieves the kubeadmin credentials of a spoke cluster
+	Extract(ctx context.Context, clusterName string) (*AdminCredentials, error)
+}
+
+type kubeadminPasswordExtractor struct {
+	dynamicClient dynamic.Interface
+	coreClient    corev1.CoreV1Interface
+	options       kube.Options
+}
+
+// NewKubeadminPasswordExtractor creates a new KubeadminPasswordExtractor
+func NewKubeadminPasswordExtractor(
+	dynamicClient dynamic.Interface,
+	coreClient corev1.CoreV1Interface,
+	options ...kube.Option,
+) KubeadminPasswordExtractor {
+	return &kubeadminPasswordExtractor{
+		dynamicClient: dynamicClient,
+		coreClient:    coreClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Extract reads the secret referenced by spec.clusterMetadata.adminPasswordSecretRef of the
+// ClusterDeployment in namespace=clusterName
+func (a *kubeadminPasswordExtractor) Extract(ctx context.Context, clusterName string) (*AdminCredentials, error) {
+	ctx, cancel, err := a.options.Start(ctx, "extract kubeadmin password", "cluster", clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	cd, err := a.dynamicClient.Resource(clusterDeploymentGVR).Namespace(clusterName).Get(ctx, clusterName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterDeployment %s: %w (cluster not found or not managed by Hive)", clusterName, err)
+	}
+
+	secretName, _, _ := unstructured.NestedString(cd.Object, "spec", "clusterMetadata", "adminPasswordSecretRef", "name")
+	if secretName == "" {
+		return nil, fmt.Errorf("adminPasswordSecretRef not found in ClusterDeployment %s (cluster not installed yet?)", clusterName)
+	}
+
+	secret, err := a.coreClient.Secrets(clusterName).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin password secret %s/%s: %w", clusterName, secretName, err)
+	}
+
+	creds := &AdminCredentials{
+		Username: string(secret.Data["username"]),
+		Password: string(secret.Data["password"]),
+	}
+	if creds.Password == "" {
+		return nil, fmt.Errorf("password key not found in secret %s/%s", clusterName, secretName)
+	}
+	if creds.Username == "" {
+		creds.Username = "kubeadmin"
+	}
+	return creds, nil
+}
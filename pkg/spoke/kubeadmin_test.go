@@ -0,0 +1,64 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("KubeadminPasswordExtractor", func() {
+	var (
+		ctx         context.Context
+		fakeDynamic *fake.FakeDynamicClient
+		fakeK8s     *k8sFake.Clientset
+	)
+
+	newCD := func(clusterMetadata map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterDeployment",
+			"metadata":   map[string]interface{}{"name": "test-cluster", "namespace": "test-cluster"},
+			"spec":       map[string]interface{}{"clusterMetadata": clusterMetadata},
+		}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fakeDynamic = fake.NewSimpleDynamicClient(runtime.NewScheme(), newCD(map[string]interface{}{
+			"adminPasswordSecretRef": map[string]interface{}{"name": "test-cluster-admin-password"},
+		}))
+		fakeK8s = k8sFake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-admin-password", Namespace: "test-cluster"},
+			Data:       map[string][]byte{"username": []byte("kubeadmin"), "password": []byte("s3cret-Pass")},
+		})
+	})
+
+	It("should extract the kubeadmin credentials", func() {
+		creds, err := spoke.NewKubeadminPasswordExtractor(fakeDynamic, fakeK8s.CoreV1()).Extract(ctx, "test-cluster")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds).To(Equal(&spoke.AdminCredentials{Username: "kubeadmin", Password: "s3cret-Pass"}))
+	})
+
+	It("should return an error when the cluster has no admin password secret", func() {
+		fakeDynamic = fake.NewSimpleDynamicClient(runtime.NewScheme(), newCD(map[string]interface{}{}))
+		_, err := spoke.NewKubeadminPasswordExtractor(fakeDynamic, fakeK8s.CoreV1()).Extract(ctx, "test-cluster")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("adminPasswordSecretRef not found"))
+	})
+
+	It("should return an error when the secret is missing", func() {
+		_, err := spoke.NewKubeadminPasswordExtractor(fakeDynamic, k8sFake.NewSimpleClientset().CoreV1()).Extract(ctx, "test-cluster")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to get admin password secret"))
+	})
+})
@@ -10,9 +10,11 @@ import (
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
 )
 
 // KubeconfigExtractor provides methods to extract admin kubeconfig from spoke clusters
@@ -26,16 +28,19 @@ type KubeconfigExtractor interface {
 type kubeconfigExtractor struct {
 	dynamicClient dynamic.Interface
 	coreClient    corev1.CoreV1Interface
+	options       kube.Options
 }
 
 // NewKubeconfigExtractor creates a new KubeconfigExtractor
 func NewKubeconfigExtractor(
 	dynamicClient dynamic.Interface,
 	coreClient corev1.CoreV1Interface,
+	options ...kube.Option,
 ) KubeconfigExtractor {
 	return &kubeconfigExtractor{
 		dynamicClient: dynamicClient,
 		coreClient:    coreClient,
+		options:       kube.NewOptions(options...),
 	}
 }
 
@@ -48,14 +53,19 @@ func NewKubeconfigExtractor(
 // 5. Decode if base64 encoded (beyond Kubernetes' native encoding)
 // 6. Validate and return
 func (k *kubeconfigExtractor) Extract(ctx context.Context, clusterName string) ([]byte, error) {
-	// Step 1: Get ClusterDeployment
-	gvr := schema.GroupVersionResource{
-		Group:    "hive.openshift.io",
-		Version:  "v1",
-		Resource: "clusterdeployments",
+	ctx, cancel, err := k.options.Start(ctx, "extract kubeconfig", "cluster", clusterName)
+	if err != nil {
+		return nil, err
 	}
+	defer cancel()
 
-	cd, err := k.dynamicClient.Resource(gvr).Namespace(clusterName).Get(ctx, clusterName, metav1.GetOptions{})
+	// Step 1: Get ClusterDeployment
+	var cd *unstructured.Unstructured
+	err = k.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		cd, err = k.dynamicClient.Resource(clusterDeploymentGVR).Namespace(clusterName).Get(ctx, clusterName, metav1.GetOptions{})
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ClusterDeployment %s: %w (cluster not found or not managed by Hive)", clusterName, err)
 	}
@@ -82,13 +92,20 @@ func (k *kubeconfigExtractor) Extract(ctx context.Context, clusterName string) (
 	}
 
 	// Step 3: Get Secret
-	secret, err := k.coreClient.Secrets(clusterName).Get(ctx, secretName, metav1.GetOptions{})
+	var secretData map[string][]byte
+	err = k.options.Retry(ctx, func(ctx context.Context) error {
+		secret, err := k.coreClient.Secrets(clusterName).Get(ctx, secretName, metav1.GetOptions{})
+		if err == nil {
+			secretData = secret.Data
+		}
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get admin kubeconfig secret %s/%s: %w", clusterName, secretName, err)
 	}
 
 	// Step 4: Extract kubeconfig data
-	kubeconfigData, ok := secret.Data["kubeconfig"]
+	kubeconfigData, ok := secretData["kubeconfig"]
 	if !ok {
 		return nil, fmt.Errorf("kubeconfig key not found in secret %s/%s", clusterName, secretName)
 	}
@@ -125,7 +142,12 @@ func (k *kubeconfigExtractor) ExtractToFile(ctx context.Context, clusterName, ou
 	if err != nil {
 		return err
 	}
+	return WriteKubeconfig(kubeconfig, outputPath)
+}
 
+// WriteKubeconfig writes a kubeconfig to a file readable by the current user only, replacing
+// the file if it exists
+func WriteKubeconfig(kubeconfig []byte, outputPath string) error {
 	// Create parent directories if needed
 	dir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
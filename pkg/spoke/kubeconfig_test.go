@@ -7,18 +7,34 @@ import (
 	"encoding/base64"
 	"os"
 	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
 	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic/fake"
 	k8sFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
+// failOnce returns a reactor that fails the first matching request with err
+func failOnce(err error) k8stesting.ReactionFunc {
+	failed := false
+	return func(k8stesting.Action) (bool, runtime.Object, error) {
+		if failed {
+			return false, nil, nil
+		}
+		failed = true
+		return true, nil, err
+	}
+}
+
 var _ = Describe("KubeconfigExtractor", func() {
 	var (
 		extractor       spoke.KubeconfigExtractor
@@ -97,6 +113,16 @@ users:
 				Expect(err).NotTo(HaveOccurred())
 				Expect(string(kubeconfig)).To(Equal(validKubeconfig))
 			})
+
+			It("should retry reads that fail transiently with WithRetry", func() {
+				fakeDynamic.PrependReactor("get", "clusterdeployments", failOnce(apierrors.NewTooManyRequests("throttled", 0)))
+				fakeK8s.PrependReactor("get", "secrets", failOnce(apierrors.NewServiceUnavailable("API server restarting")))
+
+				extractor = spoke.NewKubeconfigExtractor(fakeDynamic, fakeK8s.CoreV1(), kube.WithRetry(1, time.Millisecond))
+				kubeconfig, err := extractor.Extract(ctx, clusterName)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(kubeconfig)).To(Equal(validKubeconfig))
+			})
 		})
 
 		Context("with base64-encoded kubeconfig in secret", func() {
@@ -0,0 +1,100 @@
+package spoke
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// KubeconfigReportName is the report (and JUnit test suite) name used by kubeconfig checks
+	KubeconfigReportName = "labrat.spoke.kubeconfig"
+	// KubeconfigExpiryWarning is how long before its client certificate expires a kubeconfig
+	// is reported with a warning
+	KubeconfigExpiryWarning = 30 * 24 * time.Hour
+)
+
+// VerifyKubeconfig checks that the client certificate of a kubeconfig has not expired and that
+// its API server is reachable. The named context is checked, or the current context if the
+// kubeconfig has no context of that name, so a context merged into a shared kubeconfig can
+// be checked as well as a kubeconfig extracted on its own.
+func VerifyKubeconfig(kubeconfig []byte, contextName string, options ...kube.Option) check.Report {
+	report := check.Report{Name: KubeconfigReportName}
+
+	config, err := clientcmd.Load(kubeconfig)
+	if err == nil {
+		if _, ok := config.Contexts[contextName]; ok {
+			config.CurrentContext = contextName
+		}
+		if _, ok := config.Contexts[config.CurrentContext]; !ok {
+			err = fmt.Errorf("context %q not found", config.CurrentContext)
+		}
+	}
+	if err != nil {
+		report.Run("Kubeconfig valid", func() (check.Status, string) {
+			return check.StatusFail, fmt.Sprintf("failed to parse kubeconfig: %v", err)
+		})
+		return report
+	}
+
+	report.Run("Client certificate valid", func() (check.Status, string) {
+		return checkClientCertificate(config, time.Now())
+	})
+	report.Run("API server reachable", func() (check.Status, string) {
+		return checkAPIServer(config, options)
+	})
+	return report
+}
+
+// checkClientCertificate reports the expiry of the client certificate of the current context.
+// Kubeconfigs authenticating with a token have no certificate to expire.
+func checkClientCertificate(config *clientcmdapi.Config, now time.Time) (check.Status, string) {
+	user, ok := config.AuthInfos[config.Contexts[config.CurrentContext].AuthInfo]
+	if !ok || len(user.ClientCertificateData) == 0 {
+		return check.StatusPass, "no client certificate"
+	}
+
+	block, _ := pem.Decode(user.ClientCertificateData)
+	if block == nil {
+		return check.StatusFail, "client certificate is not PEM encoded"
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to parse client certificate: %v", err)
+	}
+
+	expiry := cert.NotAfter.UTC().Format(time.RFC3339)
+	switch {
+	case now.After(cert.NotAfter):
+		return check.StatusFail, fmt.Sprintf("expired at %s", expiry)
+	case cert.NotAfter.Sub(now) < KubeconfigExpiryWarning:
+		return check.StatusWarn, fmt.Sprintf("expires at %s", expiry)
+	default:
+		return check.StatusPass, fmt.Sprintf("valid until %s", expiry)
+	}
+}
+
+// checkAPIServer verifies the API server of the current context responds to a version request
+func checkAPIServer(config *clientcmdapi.Config, options []kube.Option) (check.Status, string) {
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to encode kubeconfig: %v", err)
+	}
+	client, err := kube.NewClientFromKubeconfig(data, options...)
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to create client: %v", err)
+	}
+
+	version, err := client.GetCoreClient().Discovery().ServerVersion()
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to reach API server %s: %v", client.Host(), err)
+	}
+	return check.StatusPass, fmt.Sprintf("Kubernetes %s at %s", version.GitVersion, client.Host())
+}
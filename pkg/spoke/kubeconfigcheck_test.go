@@ -0,0 +1,122 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+// clientCertificate returns a PEM encoded self-signed client certificate expiring at notAfter
+// and its key
+func clientCertificate(notAfter time.Time) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "system:admin"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// certKubeconfig returns a kubeconfig for server authenticating with a client certificate
+// expiring at notAfter
+func certKubeconfig(server string, notAfter time.Time) []byte {
+	cert, key := clientCertificate(notAfter)
+	return []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: lab
+  cluster:
+    server: ` + server + `
+contexts:
+- name: admin
+  context:
+    cluster: lab
+    user: admin
+current-context: admin
+users:
+- name: admin
+  user:
+    client-certificate-data: ` + base64.StdEncoding.EncodeToString(cert) + `
+    client-key-data: ` + base64.StdEncoding.EncodeToString(key) + `
+`)
+}
+
+var _ = Describe("VerifyKubeconfig", func() {
+	var apiServer *httptest.Server
+
+	BeforeEach(func() {
+		apiServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/version" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"major": "1", "minor": "31", "gitVersion": "v1.31.4"}`))
+		}))
+		DeferCleanup(apiServer.Close)
+	})
+
+	It("should pass a valid kubeconfig of a reachable cluster", func() {
+		report := spoke.VerifyKubeconfig(certKubeconfig(apiServer.URL, time.Now().AddDate(1, 0, 0)), "")
+
+		Expect(report.Name).To(Equal(spoke.KubeconfigReportName))
+		Expect(report.Results).To(HaveLen(2))
+		Expect(report.Results[0].Status).To(Equal(check.StatusPass))
+		Expect(report.Results[0].Message).To(HavePrefix("valid until"))
+		Expect(report.Results[1].Status).To(Equal(check.StatusPass))
+		Expect(report.Results[1].Message).To(ContainSubstring("v1.31.4"))
+	})
+
+	It("should fail an expired client certificate", func() {
+		report := spoke.VerifyKubeconfig(certKubeconfig(apiServer.URL, time.Now().Add(-time.Hour)), "")
+
+		Expect(report.Results[0].Status).To(Equal(check.StatusFail))
+		Expect(report.Results[0].Message).To(HavePrefix("expired at"))
+	})
+
+	It("should warn about a client certificate that expires soon", func() {
+		report := spoke.VerifyKubeconfig(certKubeconfig(apiServer.URL, time.Now().AddDate(0, 0, 7)), "")
+
+		Expect(report.Results[0].Status).To(Equal(check.StatusWarn))
+	})
+
+	It("should fail when the API server is unreachable", func() {
+		apiServer.Close()
+		report := spoke.VerifyKubeconfig(adminKubeconfig(apiServer.URL, "token"), "")
+
+		Expect(report.Results[0].Message).To(Equal("no client certificate"))
+		Expect(report.Results[1].Status).To(Equal(check.StatusFail))
+		Expect(report.Failed()).To(BeTrue())
+	})
+
+	It("should check the named context of a merged kubeconfig", func() {
+		report := spoke.VerifyKubeconfig(adminKubeconfig(apiServer.URL, "token"), "admin")
+		Expect(report.Failed()).To(BeFalse())
+
+		report = spoke.VerifyKubeconfig([]byte("apiVersion: v1\nkind: Config\n"), "labrat-lab")
+		Expect(report.Results).To(HaveLen(1))
+		Expect(report.Results[0].Status).To(Equal(check.StatusFail))
+	})
+})
@@ -0,0 +1,291 @@
+package spoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hive"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// machinePoolGVR identifies Hive MachinePool resources
+var machinePoolGVR = hive.MachinePoolGVR
+
+// MachinePoolInfo contains information from a Hive MachinePool resource
+type MachinePoolInfo struct {
+	// Name is the pool name (spec.name), e.g. worker
+	Name string `json:"name"`
+	// ResourceName is the name of the MachinePool resource, usually <cluster>-<pool>
+	ResourceName string `json:"resourceName"`
+	// Replicas is the desired number of machines, 0 for autoscaled pools
+	Replicas int64 `json:"replicas"`
+	// CurrentReplicas is the number of machines reported in the status
+	CurrentReplicas int64 `json:"currentReplicas"`
+	// MinReplicas and MaxReplicas bound autoscaled pools, both 0 otherwise
+	MinReplicas int64 `json:"minReplicas,omitempty"`
+	MaxReplicas int64 `json:"maxReplicas,omitempty"`
+	// InstanceType is the instance type of the cloud platform of the pool
+	InstanceType string `json:"instanceType,omitempty"`
+}
+
+// Autoscaled reports whether the pool is sized by the cluster autoscaler
+func (m MachinePoolInfo) Autoscaled() bool {
+	return m.MaxReplicas > 0
+}
+
+// MachinePoolClient lists and resizes the Hive MachinePools of spoke clusters
+type MachinePoolClient interface {
+	// List returns the MachinePools of a cluster, sorted by pool name
+	List(ctx context.Context, clusterName string) ([]MachinePoolInfo, error)
+	// ListAll returns the MachinePools of every cluster by cluster name, each sorted by pool name
+	ListAll(ctx context.Context) (map[string][]MachinePoolInfo, error)
+	// Scale sets the replicas of the named pool of a cluster
+	Scale(ctx context.Context, clusterName, pool string, replicas int64) error
+	// Autoscale lets the cluster autoscaler size the named pool of a cluster between
+	// minReplicas and maxReplicas
+	Autoscale(ctx context.Context, clusterName, pool string, minReplicas, maxReplicas int64) error
+	// DisableAutoscaling sets the named autoscaled pool of a cluster to a fixed number of replicas
+	DisableAutoscaling(ctx context.Context, clusterName, pool string, replicas int64) error
+}
+
+type machinePoolClient struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewMachinePoolClient creates a new MachinePoolClient
+func NewMachinePoolClient(dynamicClient dynamic.Interface, options ...kube.Option) MachinePoolClient {
+	return &machinePoolClient{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// List returns the MachinePools in namespace=clusterName that reference the cluster's ClusterDeployment
+func (m *machinePoolClient) List(ctx context.Context, clusterName string) ([]MachinePoolInfo, error) {
+	ctx, cancel, err := m.options.Start(ctx, "list MachinePools", "cluster", clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	return m.list(ctx, clusterName)
+}
+
+// ListAll lists the MachinePools of every namespace at once, keeping those that reference the
+// ClusterDeployment of their namespace like List
+func (m *machinePoolClient) ListAll(ctx context.Context) (map[string][]MachinePoolInfo, error) {
+	ctx, cancel, err := m.options.Start(ctx, "list all MachinePools")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = m.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = m.dynamicClient.Resource(machinePoolGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MachinePools: %w", err)
+	}
+
+	pools := make(map[string][]MachinePoolInfo)
+	for _, item := range list.Items {
+		clusterName := item.GetNamespace()
+		if ref, _, _ := unstructured.NestedString(item.Object, "spec", "clusterDeploymentRef", "name"); ref != clusterName {
+			continue
+		}
+		pool, err := parseMachinePool(item.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MachinePool %s/%s: %w", clusterName, item.GetName(), err)
+		}
+		pools[clusterName] = append(pools[clusterName], pool)
+	}
+	for _, clusterPools := range pools {
+		sort.Slice(clusterPools, func(i, j int) bool { return clusterPools[i].Name < clusterPools[j].Name })
+	}
+	return pools, nil
+}
+
+// list lists the MachinePools of a cluster without starting an operation
+func (m *machinePoolClient) list(ctx context.Context, clusterName string) ([]MachinePoolInfo, error) {
+	list, err := m.dynamicClient.Resource(machinePoolGVR).Namespace(clusterName).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MachinePools of %s: %w", clusterName, err)
+	}
+
+	pools := make([]MachinePoolInfo, 0, len(list.Items))
+	for _, item := range list.Items {
+		if ref, _, _ := unstructured.NestedString(item.Object, "spec", "clusterDeploymentRef", "name"); ref != clusterName {
+			continue
+		}
+		pool, err := parseMachinePool(item.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MachinePool %s/%s: %w", clusterName, item.GetName(), err)
+		}
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+	return pools, nil
+}
+
+// Scale patches spec.replicas of the pool. Autoscaled pools are rejected, as the autoscaler
+// would override the replicas.
+func (m *machinePoolClient) Scale(ctx context.Context, clusterName, pool string, replicas int64) error {
+	ctx, cancel, err := m.options.Start(ctx, "scale MachinePool", "cluster", clusterName, "pool", pool, "replicas", replicas)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if replicas < 0 {
+		return fmt.Errorf("invalid replicas %d: must not be negative", replicas)
+	}
+
+	target, err := m.find(ctx, clusterName, pool)
+	if err != nil {
+		return err
+	}
+	if target.Autoscaled() {
+		return fmt.Errorf("MachinePool %s of %s is autoscaled between %d and %d replicas, change its autoscaling bounds instead",
+			pool, clusterName, target.MinReplicas, target.MaxReplicas)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build replicas patch: %w", err)
+	}
+
+	_, err = m.dynamicClient.Resource(machinePoolGVR).Namespace(clusterName).Patch(
+		ctx, target.ResourceName, types.MergePatchType, patch, metav1.PatchOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to scale MachinePool %s: %w", target.ResourceName, err)
+	}
+	return nil
+}
+
+// Autoscale replaces spec.replicas of the pool with autoscaling bounds, which Hive turns into
+// MachineAutoscalers on the cluster
+func (m *machinePoolClient) Autoscale(ctx context.Context, clusterName, pool string, minReplicas, maxReplicas int64) error {
+	ctx, cancel, err := m.options.Start(ctx, "autoscale MachinePool", "cluster", clusterName, "pool", pool, "min", minReplicas, "max", maxReplicas)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	switch {
+	case minReplicas < 0:
+		return fmt.Errorf("invalid minimum replicas %d: must not be negative", minReplicas)
+	case maxReplicas < 1:
+		return fmt.Errorf("invalid maximum replicas %d: must be at least 1", maxReplicas)
+	case minReplicas > maxReplicas:
+		return fmt.Errorf("invalid autoscaling bounds: minimum replicas %d exceed maximum replicas %d", minReplicas, maxReplicas)
+	}
+
+	target, err := m.find(ctx, clusterName, pool)
+	if err != nil {
+		return err
+	}
+	return m.patch(ctx, clusterName, target.ResourceName, map[string]interface{}{
+		"replicas": nil,
+		"autoscaling": map[string]interface{}{
+			"minReplicas": minReplicas,
+			"maxReplicas": maxReplicas,
+		},
+	})
+}
+
+// DisableAutoscaling replaces the autoscaling bounds of the pool with spec.replicas. Pools that
+// are not autoscaled are rejected, since spoke scale resizes them.
+func (m *machinePoolClient) DisableAutoscaling(ctx context.Context, clusterName, pool string, replicas int64) error {
+	ctx, cancel, err := m.options.Start(ctx, "disable MachinePool autoscaling", "cluster", clusterName, "pool", pool, "replicas", replicas)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if replicas < 0 {
+		return fmt.Errorf("invalid replicas %d: must not be negative", replicas)
+	}
+
+	target, err := m.find(ctx, clusterName, pool)
+	if err != nil {
+		return err
+	}
+	if !target.Autoscaled() {
+		return fmt.Errorf("MachinePool %s of %s is not autoscaled", pool, clusterName)
+	}
+	return m.patch(ctx, clusterName, target.ResourceName, map[string]interface{}{
+		"autoscaling": nil,
+		"replicas":    replicas,
+	})
+}
+
+// find returns the named pool of a cluster
+func (m *machinePoolClient) find(ctx context.Context, clusterName, pool string) (*MachinePoolInfo, error) {
+	pools, err := m.list(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(pools))
+	for i := range pools {
+		if pools[i].Name == pool {
+			return &pools[i], nil
+		}
+		names = append(names, pools[i].Name)
+	}
+	return nil, fmt.Errorf("MachinePool %s not found for cluster %s (pools: %v)", pool, clusterName, names)
+}
+
+// patch merges spec into the spec of a MachinePool resource
+func (m *machinePoolClient) patch(ctx context.Context, clusterName, resourceName string, spec map[string]interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return fmt.Errorf("failed to build MachinePool patch: %w", err)
+	}
+
+	_, err = m.dynamicClient.Resource(machinePoolGVR).Namespace(clusterName).Patch(
+		ctx, resourceName, types.MergePatchType, patch, metav1.PatchOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to patch MachinePool %s: %w", resourceName, err)
+	}
+	return nil
+}
+
+// parseMachinePool extracts the pool information of an unstructured MachinePool
+func parseMachinePool(obj map[string]interface{}) (MachinePoolInfo, error) {
+	machinePool, err := hive.MachinePoolFromUnstructured(obj)
+	if err != nil {
+		return MachinePoolInfo{}, err
+	}
+
+	pool := MachinePoolInfo{
+		Name:            machinePool.Spec.Name,
+		ResourceName:    machinePool.Name,
+		CurrentReplicas: int64(machinePool.Status.Replicas),
+		InstanceType:    machinePool.Spec.Platform.InstanceType(),
+	}
+	if machinePool.Spec.Replicas != nil {
+		pool.Replicas = *machinePool.Spec.Replicas
+	}
+	if autoscaling := machinePool.Spec.Autoscaling; autoscaling != nil {
+		pool.MinReplicas = int64(autoscaling.MinReplicas)
+		pool.MaxReplicas = int64(autoscaling.MaxReplicas)
+	}
+	return pool, nil
+}
@@ -0,0 +1,163 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("MachinePoolClient", func() {
+	var (
+		ctx         context.Context
+		fakeDynamic *fake.FakeDynamicClient
+		client      spoke.MachinePoolClient
+		gvr         schema.GroupVersionResource
+	)
+
+	newPool := func(cluster, pool string, spec map[string]interface{}) *unstructured.Unstructured {
+		spec["name"] = pool
+		spec["clusterDeploymentRef"] = map[string]interface{}{"name": cluster}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "MachinePool",
+			"metadata":   map[string]interface{}{"name": cluster + "-" + pool, "namespace": cluster},
+			"spec":       spec,
+			"status":     map[string]interface{}{"replicas": int64(3)},
+		}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		gvr = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "machinepools"}
+		fakeDynamic = fake.NewSimpleDynamicClient(runtime.NewScheme(),
+			newPool("test-cluster", "worker", map[string]interface{}{
+				"replicas": int64(3),
+				"platform": map[string]interface{}{"aws": map[string]interface{}{"type": "m6i.xlarge"}},
+			}),
+			newPool("test-cluster", "infra", map[string]interface{}{
+				"autoscaling": map[string]interface{}{"minReplicas": int64(2), "maxReplicas": int64(6)},
+			}),
+			newPool("other-cluster", "worker", map[string]interface{}{"replicas": int64(5)}),
+		)
+		client = spoke.NewMachinePoolClient(fakeDynamic)
+	})
+
+	Describe("List", func() {
+		It("should list the pools of the cluster sorted by name", func() {
+			pools, err := client.List(ctx, "test-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pools).To(Equal([]spoke.MachinePoolInfo{
+				{Name: "infra", ResourceName: "test-cluster-infra", CurrentReplicas: 3, MinReplicas: 2, MaxReplicas: 6},
+				{Name: "worker", ResourceName: "test-cluster-worker", Replicas: 3, CurrentReplicas: 3, InstanceType: "m6i.xlarge"},
+			}))
+			Expect(pools[0].Autoscaled()).To(BeTrue())
+			Expect(pools[1].Autoscaled()).To(BeFalse())
+		})
+
+		It("should fail on pools with fields of the wrong type", func() {
+			Expect(fakeDynamic.Tracker().Add(newPool("broken-cluster", "worker", map[string]interface{}{"replicas": "three"}))).To(Succeed())
+
+			_, err := client.List(ctx, "broken-cluster")
+			Expect(err).To(MatchError(ContainSubstring("failed to parse MachinePool broken-cluster/broken-cluster-worker: failed to decode MachinePool")))
+		})
+	})
+
+	Describe("ListAll", func() {
+		It("should list the pools of every cluster by cluster name", func() {
+			pools, err := client.ListAll(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pools).To(HaveLen(2))
+			Expect(pools["test-cluster"]).To(HaveLen(2))
+			Expect(pools["test-cluster"][1].InstanceType).To(Equal("m6i.xlarge"))
+			Expect(pools["other-cluster"]).To(Equal([]spoke.MachinePoolInfo{
+				{Name: "worker", ResourceName: "other-cluster-worker", Replicas: 5, CurrentReplicas: 3},
+			}))
+		})
+	})
+
+	Describe("Scale", func() {
+		It("should patch the replicas of the pool", func() {
+			Expect(client.Scale(ctx, "test-cluster", "worker", 5)).To(Succeed())
+
+			pool, err := fakeDynamic.Resource(gvr).Namespace("test-cluster").Get(ctx, "test-cluster-worker", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			replicas, _, _ := unstructured.NestedInt64(pool.Object, "spec", "replicas")
+			Expect(replicas).To(Equal(int64(5)))
+		})
+
+		It("should reject an unknown pool", func() {
+			err := client.Scale(ctx, "test-cluster", "gpu", 1)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("MachinePool gpu not found"))
+		})
+
+		It("should reject autoscaled pools", func() {
+			err := client.Scale(ctx, "test-cluster", "infra", 4)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("autoscaled between 2 and 6"))
+		})
+
+		It("should reject negative replicas", func() {
+			err := client.Scale(ctx, "test-cluster", "worker", -1)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Autoscale", func() {
+		It("should replace the replicas of the pool with autoscaling bounds", func() {
+			Expect(client.Autoscale(ctx, "test-cluster", "worker", 3, 9)).To(Succeed())
+
+			pools, err := client.List(ctx, "test-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pools[1]).To(Equal(spoke.MachinePoolInfo{
+				Name: "worker", ResourceName: "test-cluster-worker", CurrentReplicas: 3, MinReplicas: 3, MaxReplicas: 9, InstanceType: "m6i.xlarge",
+			}))
+		})
+
+		It("should change the bounds of autoscaled pools", func() {
+			Expect(client.Autoscale(ctx, "test-cluster", "infra", 0, 4)).To(Succeed())
+
+			pools, err := client.List(ctx, "test-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pools[0].MinReplicas).To(Equal(int64(0)))
+			Expect(pools[0].MaxReplicas).To(Equal(int64(4)))
+		})
+
+		It("should reject invalid bounds", func() {
+			Expect(client.Autoscale(ctx, "test-cluster", "worker", -1, 3)).To(MatchError("invalid minimum replicas -1: must not be negative"))
+			Expect(client.Autoscale(ctx, "test-cluster", "worker", 0, 0)).To(MatchError("invalid maximum replicas 0: must be at least 1"))
+			Expect(client.Autoscale(ctx, "test-cluster", "worker", 5, 3)).To(MatchError("invalid autoscaling bounds: minimum replicas 5 exceed maximum replicas 3"))
+		})
+
+		It("should reject an unknown pool", func() {
+			err := client.Autoscale(ctx, "test-cluster", "gpu", 1, 2)
+			Expect(err).To(MatchError("MachinePool gpu not found for cluster test-cluster (pools: [infra worker])"))
+		})
+	})
+
+	Describe("DisableAutoscaling", func() {
+		It("should replace the autoscaling bounds of the pool with replicas", func() {
+			Expect(client.DisableAutoscaling(ctx, "test-cluster", "infra", 3)).To(Succeed())
+
+			pools, err := client.List(ctx, "test-cluster")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pools[0]).To(Equal(spoke.MachinePoolInfo{
+				Name: "infra", ResourceName: "test-cluster-infra", Replicas: 3, CurrentReplicas: 3,
+			}))
+		})
+
+		It("should reject pools that are not autoscaled", func() {
+			err := client.DisableAutoscaling(ctx, "test-cluster", "worker", 3)
+			Expect(err).To(MatchError("MachinePool worker of test-cluster is not autoscaled"))
+		})
+	})
+})
@@ -0,0 +1,110 @@
+package spoke
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ContextPrefix starts the names of the contexts, clusters, and users merged into a
+// kubeconfig by MergeKubeconfig, e.g. labrat-my-cluster
+const ContextPrefix = "labrat-"
+
+// ErrContextConflict is returned by MergeKubeconfig when the target kubeconfig already has a
+// context of the same name for another API server
+var ErrContextConflict = errors.New("context conflict")
+
+// MergeOptions controls how MergeKubeconfig updates the target kubeconfig
+type MergeOptions struct {
+	// SetCurrent makes the merged context the current context
+	SetCurrent bool
+	// Force replaces a context of the same name even if it points to another API server
+	Force bool
+}
+
+// ContextName returns the name of the context MergeKubeconfig creates for clusterName
+func ContextName(clusterName string) string {
+	return ContextPrefix + clusterName
+}
+
+// DefaultKubeconfigPath returns the kubeconfig kubectl uses: the first path of $KUBECONFIG,
+// or ~/.kube/config
+func DefaultKubeconfigPath() string {
+	if paths := filepath.SplitList(os.Getenv(clientcmd.RecommendedConfigPathEnvVar)); len(paths) > 0 && paths[0] != "" {
+		return paths[0]
+	}
+	return clientcmd.RecommendedHomeFile
+}
+
+// MergeKubeconfig merges the current context of the spoke kubeconfig into the kubeconfig at
+// targetPath as a context, cluster, and user named labrat-<clusterName>, creating the file if
+// it does not exist. The other entries of the target are kept. A context of the same name for
+// the same API server is replaced, e.g. after the spoke certificates were rotated; one for
+// another API server is only replaced with opts.Force.
+func MergeKubeconfig(kubeconfig []byte, clusterName, targetPath string, opts MergeOptions) error {
+	source, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse kubeconfig of %s: %w", clusterName, err)
+	}
+	contextName := source.CurrentContext
+	if contextName == "" && len(source.Contexts) == 1 {
+		for name := range source.Contexts {
+			contextName = name
+		}
+	}
+	sourceContext, ok := source.Contexts[contextName]
+	if !ok {
+		return fmt.Errorf("kubeconfig of %s has no current context", clusterName)
+	}
+	cluster, ok := source.Clusters[sourceContext.Cluster]
+	if !ok {
+		return fmt.Errorf("kubeconfig of %s has no cluster %q", clusterName, sourceContext.Cluster)
+	}
+	user, ok := source.AuthInfos[sourceContext.AuthInfo]
+	if !ok {
+		return fmt.Errorf("kubeconfig of %s has no user %q", clusterName, sourceContext.AuthInfo)
+	}
+
+	target, err := clientcmd.LoadFromFile(targetPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		target, err = clientcmdapi.NewConfig(), nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig %s: %w", targetPath, err)
+	}
+
+	name := ContextName(clusterName)
+	if existing, ok := target.Contexts[name]; ok && !opts.Force {
+		var server string
+		if existingCluster, ok := target.Clusters[existing.Cluster]; ok {
+			server = existingCluster.Server
+		}
+		if server != cluster.Server {
+			return fmt.Errorf("%w: %s already has a context %s for %q, not %s", ErrContextConflict, targetPath, name, server, cluster.Server)
+		}
+	}
+
+	mergedContext := sourceContext.DeepCopy()
+	mergedContext.Cluster = name
+	mergedContext.AuthInfo = name
+	target.Clusters[name] = cluster.DeepCopy()
+	target.AuthInfos[name] = user.DeepCopy()
+	target.Contexts[name] = mergedContext
+	if opts.SetCurrent || target.CurrentContext == "" {
+		target.CurrentContext = name
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0700); err != nil {
+		return fmt.Errorf("failed to create directory of %s: %w", targetPath, err)
+	}
+	// WriteToFile keeps the file readable by the current user only, as it holds credentials
+	if err := clientcmd.WriteToFile(*target, targetPath); err != nil {
+		return fmt.Errorf("failed to write kubeconfig %s: %w", targetPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// adminKubeconfig returns an admin kubeconfig as Hive stores it for a cluster served at server
+func adminKubeconfig(server, token string) []byte {
+	return []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: lab
+  cluster:
+    server: ` + server + `
+contexts:
+- name: admin
+  context:
+    cluster: lab
+    user: admin
+current-context: admin
+users:
+- name: admin
+  user:
+    token: ` + token + `
+`)
+}
+
+var _ = Describe("MergeKubeconfig", func() {
+	var target string
+
+	BeforeEach(func() {
+		target = filepath.Join(GinkgoT().TempDir(), ".kube", "config")
+	})
+
+	It("should create the target kubeconfig with a labrat context", func() {
+		Expect(spoke.MergeKubeconfig(adminKubeconfig("https://api.lab.example.com:6443", "secret"), "lab", target, spoke.MergeOptions{})).To(Succeed())
+
+		merged, err := clientcmd.LoadFromFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.Contexts).To(HaveKey("labrat-lab"))
+		Expect(merged.Contexts["labrat-lab"].Cluster).To(Equal("labrat-lab"))
+		Expect(merged.Contexts["labrat-lab"].AuthInfo).To(Equal("labrat-lab"))
+		Expect(merged.Clusters["labrat-lab"].Server).To(Equal("https://api.lab.example.com:6443"))
+		Expect(merged.AuthInfos["labrat-lab"].Token).To(Equal("secret"))
+		Expect(merged.CurrentContext).To(Equal("labrat-lab"))
+
+		info, err := os.Stat(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("should keep the other contexts and the current context", func() {
+		Expect(spoke.MergeKubeconfig(adminKubeconfig("https://api.hub.example.com:6443", "hub"), "hub", target, spoke.MergeOptions{})).To(Succeed())
+		Expect(spoke.MergeKubeconfig(adminKubeconfig("https://api.lab.example.com:6443", "lab"), "lab", target, spoke.MergeOptions{})).To(Succeed())
+
+		merged, err := clientcmd.LoadFromFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.Contexts).To(HaveKey("labrat-hub"))
+		Expect(merged.Contexts).To(HaveKey("labrat-lab"))
+		Expect(merged.CurrentContext).To(Equal("labrat-hub"))
+	})
+
+	It("should switch to the merged context with SetCurrent", func() {
+		Expect(spoke.MergeKubeconfig(adminKubeconfig("https://api.hub.example.com:6443", "hub"), "hub", target, spoke.MergeOptions{})).To(Succeed())
+		Expect(spoke.MergeKubeconfig(adminKubeconfig("https://api.lab.example.com:6443", "lab"), "lab", target, spoke.MergeOptions{SetCurrent: true})).To(Succeed())
+
+		merged, err := clientcmd.LoadFromFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.CurrentContext).To(Equal("labrat-lab"))
+	})
+
+	It("should refresh the credentials of a context for the same API server", func() {
+		Expect(spoke.MergeKubeconfig(adminKubeconfig("https://api.lab.example.com:6443", "old"), "lab", target, spoke.MergeOptions{})).To(Succeed())
+		Expect(spoke.MergeKubeconfig(adminKubeconfig("https://api.lab.example.com:6443", "new"), "lab", target, spoke.MergeOptions{})).To(Succeed())
+
+		merged, err := clientcmd.LoadFromFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.AuthInfos["labrat-lab"].Token).To(Equal("new"))
+	})
+
+	It("should refuse to replace a context for another API server unless forced", func() {
+		Expect(spoke.MergeKubeconfig(adminKubeconfig("https://api.lab.example.com:6443", "old"), "lab", target, spoke.MergeOptions{})).To(Succeed())
+
+		err := spoke.MergeKubeconfig(adminKubeconfig("https://api.other.example.com:6443", "new"), "lab", target, spoke.MergeOptions{})
+		Expect(err).To(MatchError(spoke.ErrContextConflict))
+
+		Expect(spoke.MergeKubeconfig(adminKubeconfig("https://api.other.example.com:6443", "new"), "lab", target, spoke.MergeOptions{Force: true})).To(Succeed())
+		merged, err := clientcmd.LoadFromFile(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.Clusters["labrat-lab"].Server).To(Equal("https://api.other.example.com:6443"))
+	})
+})
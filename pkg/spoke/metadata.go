@@ -0,0 +1,213 @@
+package spoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// MetadataKind selects the metadata map a MetadataEditor changes
+type MetadataKind string
+
+const (
+	// MetadataLabels changes the labels of a cluster
+	MetadataLabels MetadataKind = "labels"
+	// MetadataAnnotations changes the annotations of a cluster
+	MetadataAnnotations MetadataKind = "annotations"
+)
+
+// MetadataChange sets a label or annotation, or removes it
+type MetadataChange struct {
+	Key   string
+	Value string
+	// Remove removes the key instead of setting it
+	Remove bool
+}
+
+// ParseMetadataChanges parses changes in the syntax of kubectl label: key=value sets a key
+// and key- removes it. Keys, and the values of labels, are validated like the API server does.
+func ParseMetadataChanges(kind MetadataKind, args []string) ([]MetadataChange, error) {
+	changes := make([]MetadataChange, 0, len(args))
+	for _, arg := range args {
+		var change MetadataChange
+		if key, value, ok := strings.Cut(arg, "="); ok {
+			change = MetadataChange{Key: key, Value: value}
+		} else if key, ok := strings.CutSuffix(arg, "-"); ok {
+			change = MetadataChange{Key: key, Remove: true}
+		} else {
+			return nil, fmt.Errorf("invalid %s %q: expected key=value or key-", strings.TrimSuffix(string(kind), "s"), arg)
+		}
+
+		if errs := validation.IsQualifiedName(change.Key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid key %q: %s", change.Key, strings.Join(errs, "; "))
+		}
+		if kind == MetadataLabels && !change.Remove {
+			if errs := validation.IsValidLabelValue(change.Value); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid value of label %s: %s", change.Key, strings.Join(errs, "; "))
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// MetadataOptions controls how a MetadataEditor applies changes
+type MetadataOptions struct {
+	// Overwrite allows replacing the existing value of a key
+	Overwrite bool
+	// ClusterDeployment also applies the changes to the ClusterDeployment of the cluster
+	ClusterDeployment bool
+}
+
+// MetadataEditor changes the labels and annotations of spoke clusters
+type MetadataEditor interface {
+	// Apply applies the changes to the ManagedCluster of a cluster, and with
+	// opts.ClusterDeployment to its ClusterDeployment too
+	Apply(ctx context.Context, clusterName string, kind MetadataKind, changes []MetadataChange, opts MetadataOptions) error
+	// Current returns the labels or annotations of the ManagedCluster of a cluster
+	Current(ctx context.Context, clusterName string, kind MetadataKind) (map[string]string, error)
+}
+
+type metadataEditor struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewMetadataEditor creates a new MetadataEditor
+func NewMetadataEditor(dynamicClient dynamic.Interface, options ...kube.Option) MetadataEditor {
+	return &metadataEditor{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Apply checks the changes against every resource before patching any of them, so a
+// conflicting key leaves the ManagedCluster and the ClusterDeployment unchanged
+func (m *metadataEditor) Apply(ctx context.Context, clusterName string, kind MetadataKind, changes []MetadataChange, opts MetadataOptions) error {
+	ctx, cancel, err := m.options.Start(ctx, "change "+string(kind), "cluster", clusterName, "changes", len(changes))
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	resources := []dynamic.ResourceInterface{m.dynamicClient.Resource(provisionGVRs["ManagedCluster"])}
+	kinds := []string{"ManagedCluster"}
+	if opts.ClusterDeployment {
+		resources = append(resources, m.dynamicClient.Resource(clusterDeploymentGVR).Namespace(clusterName))
+		kinds = append(kinds, "ClusterDeployment")
+	}
+
+	for i, resource := range resources {
+		obj, err := resource.Get(ctx, clusterName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get %s %s: %w", kinds[i], clusterName, err)
+		}
+		if !opts.Overwrite {
+			if err := checkOverwrite(obj, kind, changes); err != nil {
+				return fmt.Errorf("%s %s: %w", kinds[i], clusterName, err)
+			}
+		}
+	}
+
+	patch, err := metadataPatch(kind, changes)
+	if err != nil {
+		return err
+	}
+	for i, resource := range resources {
+		if _, err := resource.Patch(ctx, clusterName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to update %s of %s %s: %w", kind, kinds[i], clusterName, err)
+		}
+	}
+	return nil
+}
+
+// Current reads the ManagedCluster of the cluster
+func (m *metadataEditor) Current(ctx context.Context, clusterName string, kind MetadataKind) (map[string]string, error) {
+	ctx, cancel, err := m.options.Start(ctx, "get "+string(kind), "cluster", clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var obj *unstructured.Unstructured
+	err = m.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		obj, err = m.dynamicClient.Resource(provisionGVRs["ManagedCluster"]).Get(ctx, clusterName, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ManagedCluster %s: %w", clusterName, err)
+	}
+	if kind == MetadataAnnotations {
+		return obj.GetAnnotations(), nil
+	}
+	return obj.GetLabels(), nil
+}
+
+// DescribeMetadataChanges describes what changes do to the current labels or annotations of
+// a cluster, e.g. "owner: jdoe → asmith, team- (was ai)". Changes that would replace a value
+// without overwrite are described as refused, and changes that change nothing are left out.
+func DescribeMetadataChanges(current map[string]string, changes []MetadataChange, overwrite bool) string {
+	var parts []string
+	for _, change := range changes {
+		value, exists := current[change.Key]
+		switch {
+		case change.Remove && exists:
+			parts = append(parts, fmt.Sprintf("%s- (was %s)", change.Key, value))
+		case change.Remove, exists && value == change.Value:
+		case exists && !overwrite:
+			parts = append(parts, fmt.Sprintf("%s: %s (refused without --overwrite)", change.Key, value))
+		case exists:
+			parts = append(parts, fmt.Sprintf("%s: %s → %s", change.Key, value, change.Value))
+		default:
+			parts = append(parts, fmt.Sprintf("%s=%s", change.Key, change.Value))
+		}
+	}
+	if len(parts) == 0 {
+		return "no change"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// checkOverwrite fails if a change would replace an existing value, like kubectl label
+// without --overwrite
+func checkOverwrite(obj *unstructured.Unstructured, kind MetadataKind, changes []MetadataChange) error {
+	existing := obj.GetLabels()
+	if kind == MetadataAnnotations {
+		existing = obj.GetAnnotations()
+	}
+	for _, change := range changes {
+		if current, ok := existing[change.Key]; ok && !change.Remove && current != change.Value {
+			return fmt.Errorf("%s %s is already set to %q; use --overwrite to replace it", strings.TrimSuffix(string(kind), "s"), change.Key, current)
+		}
+	}
+	return nil
+}
+
+// metadataPatch builds a merge patch setting the changed keys, with null for removed keys
+func metadataPatch(kind MetadataKind, changes []MetadataChange) ([]byte, error) {
+	values := make(map[string]interface{}, len(changes))
+	for _, change := range changes {
+		if change.Remove {
+			values[change.Key] = nil
+		} else {
+			values[change.Key] = change.Value
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{string(kind): values},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s patch: %w", kind, err)
+	}
+	return patch, nil
+}
@@ -0,0 +1,156 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ParseMetadataChanges", func() {
+	It("should parse set and remove syntax", func() {
+		changes, err := spoke.ParseMetadataChanges(spoke.MetadataLabels, []string{"partner=acme", "labrat.io/owner-", "empty="})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changes).To(Equal([]spoke.MetadataChange{
+			{Key: "partner", Value: "acme"},
+			{Key: "labrat.io/owner", Remove: true},
+			{Key: "empty", Value: ""},
+		}))
+	})
+
+	It("should reject arguments without a value or removal", func() {
+		_, err := spoke.ParseMetadataChanges(spoke.MetadataLabels, []string{"partner"})
+		Expect(err).To(MatchError(`invalid label "partner": expected key=value or key-`))
+	})
+
+	It("should reject invalid keys", func() {
+		_, err := spoke.ParseMetadataChanges(spoke.MetadataAnnotations, []string{"bad key=x"})
+		Expect(err).To(MatchError(ContainSubstring(`invalid key "bad key"`)))
+	})
+
+	It("should validate label values but not annotation values", func() {
+		_, err := spoke.ParseMetadataChanges(spoke.MetadataLabels, []string{"note=two words"})
+		Expect(err).To(MatchError(ContainSubstring("invalid value of label note")))
+
+		_, err = spoke.ParseMetadataChanges(spoke.MetadataAnnotations, []string{"note=two words"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("MetadataEditor", func() {
+	var (
+		ctx         context.Context
+		fakeDynamic *fake.FakeDynamicClient
+		editor      spoke.MetadataEditor
+		mcGVR       schema.GroupVersionResource
+		cdGVR       schema.GroupVersionResource
+	)
+
+	object := func(apiVersion, kind, namespace string, labels map[string]interface{}) *unstructured.Unstructured {
+		metadata := map[string]interface{}{"name": "lab-1", "labels": labels}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   metadata,
+		}}
+	}
+
+	labelsOf := func(gvr schema.GroupVersionResource, namespace string) map[string]string {
+		obj, err := fakeDynamic.Resource(gvr).Namespace(namespace).Get(ctx, "lab-1", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return obj.GetLabels()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mcGVR = schema.GroupVersionResource{Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"}
+		cdGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+		fakeDynamic = fake.NewSimpleDynamicClient(runtime.NewScheme(),
+			object("cluster.open-cluster-management.io/v1", "ManagedCluster", "", map[string]interface{}{"partner": "acme", "owner": "jdoe"}),
+			object("hive.openshift.io/v1", "ClusterDeployment", "lab-1", map[string]interface{}{"partner": "acme"}),
+		)
+		editor = spoke.NewMetadataEditor(fakeDynamic)
+	})
+
+	It("should set and remove labels of the ManagedCluster only", func() {
+		changes := []spoke.MetadataChange{{Key: "program", Value: "ai"}, {Key: "owner", Remove: true}}
+		Expect(editor.Apply(ctx, "lab-1", spoke.MetadataLabels, changes, spoke.MetadataOptions{})).To(Succeed())
+
+		Expect(labelsOf(mcGVR, "")).To(Equal(map[string]string{"partner": "acme", "program": "ai"}))
+		Expect(labelsOf(cdGVR, "lab-1")).To(Equal(map[string]string{"partner": "acme"}))
+	})
+
+	It("should also change the ClusterDeployment when asked", func() {
+		changes := []spoke.MetadataChange{{Key: "program", Value: "ai"}}
+		Expect(editor.Apply(ctx, "lab-1", spoke.MetadataLabels, changes, spoke.MetadataOptions{ClusterDeployment: true})).To(Succeed())
+
+		Expect(labelsOf(mcGVR, "")).To(HaveKeyWithValue("program", "ai"))
+		Expect(labelsOf(cdGVR, "lab-1")).To(HaveKeyWithValue("program", "ai"))
+	})
+
+	It("should refuse to replace a value without overwrite", func() {
+		changes := []spoke.MetadataChange{{Key: "program", Value: "ai"}, {Key: "partner", Value: "initech"}}
+		err := editor.Apply(ctx, "lab-1", spoke.MetadataLabels, changes, spoke.MetadataOptions{})
+		Expect(err).To(MatchError(ContainSubstring(`label partner is already set to "acme"; use --overwrite`)))
+		Expect(labelsOf(mcGVR, "")).NotTo(HaveKey("program"))
+
+		Expect(editor.Apply(ctx, "lab-1", spoke.MetadataLabels, changes, spoke.MetadataOptions{Overwrite: true})).To(Succeed())
+		Expect(labelsOf(mcGVR, "")).To(HaveKeyWithValue("partner", "initech"))
+	})
+
+	It("should set annotations", func() {
+		changes := []spoke.MetadataChange{{Key: "labrat.io/notes", Value: "demo on Friday"}}
+		Expect(editor.Apply(ctx, "lab-1", spoke.MetadataAnnotations, changes, spoke.MetadataOptions{})).To(Succeed())
+
+		obj, err := fakeDynamic.Resource(mcGVR).Get(ctx, "lab-1", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetAnnotations()).To(HaveKeyWithValue("labrat.io/notes", "demo on Friday"))
+	})
+
+	It("should fail for a cluster without a ClusterDeployment", func() {
+		Expect(fakeDynamic.Resource(cdGVR).Namespace("lab-1").Delete(ctx, "lab-1", metav1.DeleteOptions{})).To(Succeed())
+
+		err := editor.Apply(ctx, "lab-1", spoke.MetadataLabels, []spoke.MetadataChange{{Key: "program", Value: "ai"}}, spoke.MetadataOptions{ClusterDeployment: true})
+		Expect(err).To(MatchError(ContainSubstring("failed to get ClusterDeployment lab-1")))
+		Expect(labelsOf(mcGVR, "")).NotTo(HaveKey("program"))
+	})
+
+	It("should return the current labels of the ManagedCluster", func() {
+		Expect(editor.Current(ctx, "lab-1", spoke.MetadataLabels)).To(Equal(map[string]string{"partner": "acme", "owner": "jdoe"}))
+		Expect(editor.Current(ctx, "lab-1", spoke.MetadataAnnotations)).To(BeEmpty())
+
+		_, err := editor.Current(ctx, "lab-2", spoke.MetadataLabels)
+		Expect(err).To(MatchError(ContainSubstring("failed to get ManagedCluster lab-2")))
+	})
+})
+
+var _ = Describe("DescribeMetadataChanges", func() {
+	current := map[string]string{"partner": "acme", "owner": "jdoe"}
+
+	It("should describe added, replaced, and removed keys", func() {
+		changes := []spoke.MetadataChange{{Key: "program", Value: "ai"}, {Key: "owner", Value: "asmith"}, {Key: "partner", Remove: true}}
+		Expect(spoke.DescribeMetadataChanges(current, changes, true)).To(Equal("program=ai, owner: jdoe → asmith, partner- (was acme)"))
+	})
+
+	It("should describe replacements refused without overwrite", func() {
+		changes := []spoke.MetadataChange{{Key: "owner", Value: "asmith"}}
+		Expect(spoke.DescribeMetadataChanges(current, changes, false)).To(Equal("owner: jdoe (refused without --overwrite)"))
+	})
+
+	It("should leave out changes that change nothing", func() {
+		changes := []spoke.MetadataChange{{Key: "owner", Value: "jdoe"}, {Key: "program", Remove: true}}
+		Expect(spoke.DescribeMetadataChanges(current, changes, false)).To(Equal("no change"))
+		Expect(spoke.DescribeMetadataChanges(nil, changes[1:], false)).To(Equal("no change"))
+	})
+})
@@ -0,0 +1,172 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// NodeReady is the status of nodes whose kubelet reports the Ready condition
+	NodeReady = "Ready"
+	// NodeNotReady is the status of nodes whose kubelet reports it is not ready
+	NodeNotReady = "NotReady"
+	// NodeUnknown is the status of nodes whose kubelet stopped reporting
+	NodeUnknown = "Unknown"
+	// NodeSchedulingDisabled is appended to the status of cordoned nodes, as by kubectl
+	NodeSchedulingDisabled = "SchedulingDisabled"
+
+	// nodeRoleLabelPrefix prefixes the role labels of nodes, e.g. node-role.kubernetes.io/master
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+	// instanceTypeLabel is set by the cloud provider on each node
+	instanceTypeLabel = "node.kubernetes.io/instance-type"
+	// betaInstanceTypeLabel is the instance type label of older clusters
+	betaInstanceTypeLabel = "beta.kubernetes.io/instance-type"
+)
+
+// NodeInfo contains the status of a node of a spoke cluster
+type NodeInfo struct {
+	// Name is the name of the node
+	Name string `json:"name"`
+	// Status is Ready, NotReady, or Unknown, followed by SchedulingDisabled if cordoned
+	Status string `json:"status"`
+	// Roles are the node roles, e.g. control-plane, master, worker
+	Roles []string `json:"roles"`
+	// Version is the kubelet version
+	Version string `json:"version"`
+	// InstanceType is the cloud instance type, empty on bare metal
+	InstanceType string `json:"instanceType,omitempty"`
+	// CreatedAt is when the node joined the cluster
+	CreatedAt time.Time `json:"createdAt"`
+	// InternalIP and ExternalIP are the addresses the node reports, empty if it has none
+	InternalIP string `json:"internalIP,omitempty"`
+	ExternalIP string `json:"externalIP,omitempty"`
+}
+
+// Ready reports whether the kubelet of the node reports the Ready condition
+func (n NodeInfo) Ready() bool {
+	return n.Status == NodeReady || strings.HasPrefix(n.Status, NodeReady+",")
+}
+
+// NodeLister lists the nodes of a spoke cluster
+type NodeLister interface {
+	// List returns the nodes of the cluster sorted by name
+	List(ctx context.Context) ([]NodeInfo, error)
+	// Get returns a node by name
+	Get(ctx context.Context, name string) (*NodeInfo, error)
+}
+
+type nodeLister struct {
+	coreClient kubernetes.Interface
+	options    kube.Options
+}
+
+// NewNodeLister creates a new NodeLister using a client connected to the spoke cluster
+func NewNodeLister(coreClient kubernetes.Interface, options ...kube.Option) NodeLister {
+	return &nodeLister{
+		coreClient: coreClient,
+		options:    kube.NewOptions(options...),
+	}
+}
+
+// List returns the nodes of the cluster sorted by name
+func (l *nodeLister) List(ctx context.Context) ([]NodeInfo, error) {
+	ctx, cancel, err := l.options.Start(ctx, "list nodes")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *corev1.NodeList
+	err = l.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = l.coreClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	nodes := make([]NodeInfo, 0, len(list.Items))
+	for i := range list.Items {
+		nodes = append(nodes, parseNode(&list.Items[i]))
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes, nil
+}
+
+// Get returns a node by name
+func (l *nodeLister) Get(ctx context.Context, name string) (*NodeInfo, error) {
+	ctx, cancel, err := l.options.Start(ctx, "get node", "node", name)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var node *corev1.Node
+	err = l.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		node, err = l.coreClient.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node %s: %w", name, err)
+	}
+	info := parseNode(node)
+	return &info, nil
+}
+
+// parseNode extracts the status, roles, version, instance type, and addresses of node
+func parseNode(node *corev1.Node) NodeInfo {
+	info := NodeInfo{
+		Name:         node.Name,
+		Status:       NodeUnknown,
+		Roles:        []string{},
+		Version:      node.Status.NodeInfo.KubeletVersion,
+		InstanceType: node.Labels[instanceTypeLabel],
+		CreatedAt:    node.CreationTimestamp.Time,
+	}
+	if info.InstanceType == "" {
+		info.InstanceType = node.Labels[betaInstanceTypeLabel]
+	}
+
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, nodeRoleLabelPrefix); ok && role != "" {
+			info.Roles = append(info.Roles, role)
+		}
+	}
+	sort.Strings(info.Roles)
+
+	for _, address := range node.Status.Addresses {
+		switch {
+		case address.Type == corev1.NodeInternalIP && info.InternalIP == "":
+			info.InternalIP = address.Address
+		case address.Type == corev1.NodeExternalIP && info.ExternalIP == "":
+			info.ExternalIP = address.Address
+		}
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady {
+			continue
+		}
+		switch condition.Status {
+		case corev1.ConditionTrue:
+			info.Status = NodeReady
+		case corev1.ConditionFalse:
+			info.Status = NodeNotReady
+		}
+	}
+	if node.Spec.Unschedulable {
+		info.Status += "," + NodeSchedulingDisabled
+	}
+	return info
+}
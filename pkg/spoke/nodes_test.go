@@ -0,0 +1,106 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("NodeLister", func() {
+	var ctx context.Context
+
+	node := func(name string, labels map[string]string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{
+				NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: "v1.31.6"},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should list nodes with roles, readiness, version, and instance type", func() {
+		master := node("master-0", map[string]string{
+			"node-role.kubernetes.io/master":        "",
+			"node-role.kubernetes.io/control-plane": "",
+			"node.kubernetes.io/instance-type":      "m6i.xlarge",
+		}, corev1.ConditionTrue)
+		worker := node("worker-0", map[string]string{
+			"node-role.kubernetes.io/worker":   "",
+			"beta.kubernetes.io/instance-type": "m6i.2xlarge",
+		}, corev1.ConditionFalse)
+
+		nodes, err := spoke.NewNodeLister(k8sFake.NewSimpleClientset(worker, master)).List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(nodes).To(HaveLen(2))
+		Expect(nodes[0].Name).To(Equal("master-0"))
+		Expect(nodes[0].Status).To(Equal(spoke.NodeReady))
+		Expect(nodes[0].Ready()).To(BeTrue())
+		Expect(nodes[0].Roles).To(Equal([]string{"control-plane", "master"}))
+		Expect(nodes[0].Version).To(Equal("v1.31.6"))
+		Expect(nodes[0].InstanceType).To(Equal("m6i.xlarge"))
+
+		Expect(nodes[1].Name).To(Equal("worker-0"))
+		Expect(nodes[1].Status).To(Equal(spoke.NodeNotReady))
+		Expect(nodes[1].Ready()).To(BeFalse())
+		Expect(nodes[1].Roles).To(Equal([]string{"worker"}))
+		Expect(nodes[1].InstanceType).To(Equal("m6i.2xlarge"))
+	})
+
+	It("should report cordoned nodes and nodes that stopped reporting", func() {
+		cordoned := node("worker-0", nil, corev1.ConditionTrue)
+		cordoned.Spec.Unschedulable = true
+		lost := node("worker-1", nil, corev1.ConditionUnknown)
+
+		nodes, err := spoke.NewNodeLister(k8sFake.NewSimpleClientset(cordoned, lost)).List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(nodes[0].Status).To(Equal("Ready,SchedulingDisabled"))
+		Expect(nodes[0].Ready()).To(BeTrue())
+		Expect(nodes[0].Roles).To(BeEmpty())
+		Expect(nodes[1].Status).To(Equal(spoke.NodeUnknown))
+		Expect(nodes[1].Ready()).To(BeFalse())
+	})
+
+	It("should get a node with its addresses", func() {
+		worker := node("worker-0", nil, corev1.ConditionTrue)
+		worker.Status.Addresses = []corev1.NodeAddress{
+			{Type: corev1.NodeHostName, Address: "worker-0"},
+			{Type: corev1.NodeInternalIP, Address: "10.0.1.12"},
+			{Type: corev1.NodeExternalIP, Address: "203.0.113.7"},
+		}
+
+		info, err := spoke.NewNodeLister(k8sFake.NewSimpleClientset(worker)).Get(ctx, "worker-0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.InternalIP).To(Equal("10.0.1.12"))
+		Expect(info.ExternalIP).To(Equal("203.0.113.7"))
+
+		_, err = spoke.NewNodeLister(k8sFake.NewSimpleClientset()).Get(ctx, "worker-9")
+		Expect(err).To(MatchError(ContainSubstring("failed to get node worker-9")))
+	})
+
+	It("should return an error when nodes cannot be listed", func() {
+		client := k8sFake.NewSimpleClientset()
+		client.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("forbidden")
+		})
+
+		_, err := spoke.NewNodeLister(client).List(ctx)
+		Expect(err).To(MatchError(ContainSubstring("failed to list nodes")))
+	})
+})
@@ -0,0 +1,79 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// operatorManifests returns the Namespace, OperatorGroup, and Subscription that install an
+// operator from the redhat-operators catalog into its own namespace
+func operatorManifests(namespace, packageName, channel string) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata": map[string]interface{}{
+				"name": namespace,
+			},
+		},
+		map[string]interface{}{
+			"apiVersion": "operators.coreos.com/v1",
+			"kind":       "OperatorGroup",
+			"metadata": map[string]interface{}{
+				"name":      packageName,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"targetNamespaces": []interface{}{namespace},
+			},
+		},
+		map[string]interface{}{
+			"apiVersion": "operators.coreos.com/v1alpha1",
+			"kind":       "Subscription",
+			"metadata": map[string]interface{}{
+				"name":      packageName,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"name":                packageName,
+				"channel":             channel,
+				"source":              "redhat-operators",
+				"sourceNamespace":     "openshift-marketplace",
+				"installPlanApproval": "Automatic",
+			},
+		},
+	}
+}
+
+// olmGVRs maps the kinds returned by operatorManifests to their resources
+var olmGVRs = map[string]schema.GroupVersionResource{
+	"Namespace":     {Version: "v1", Resource: "namespaces"},
+	"OperatorGroup": {Group: "operators.coreos.com", Version: "v1", Resource: "operatorgroups"},
+	"Subscription":  {Group: "operators.coreos.com", Version: "v1alpha1", Resource: "subscriptions"},
+}
+
+// installOperator creates the operatorManifests of an operator directly on a spoke, keeping
+// resources that already exist
+func installOperator(ctx context.Context, dynamicClient dynamic.Interface, namespace, packageName, channel string) error {
+	for _, manifest := range operatorManifests(namespace, packageName, channel) {
+		obj := &unstructured.Unstructured{Object: manifest.(map[string]interface{})}
+		gvr := olmGVRs[obj.GetKind()]
+
+		var err error
+		if obj.GetNamespace() == "" {
+			_, err = dynamicClient.Resource(gvr).Create(ctx, obj, metav1.CreateOptions{})
+		} else {
+			_, err = dynamicClient.Resource(gvr).Namespace(obj.GetNamespace()).Create(ctx, obj, metav1.CreateOptions{})
+		}
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package spoke
+
+import (
+	"fmt"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+)
+
+// Networking is the network configuration of a cluster; empty fields use the installer defaults
+type Networking struct {
+	// NetworkType is the cluster network plugin, e.g. OVNKubernetes
+	NetworkType string
+	// MachineCIDR is the network of the nodes; on vSphere it must match the VM network
+	MachineCIDR string
+	// ClusterCIDR is the network pod IPs are allocated from
+	ClusterCIDR string
+	// ServiceCIDR is the network service IPs are allocated from
+	ServiceCIDR string
+}
+
+// defaultNetworking is the networking of the installer
+var defaultNetworking = Networking{
+	NetworkType: "OVNKubernetes",
+	MachineCIDR: "10.0.0.0/16",
+	ClusterCIDR: "10.128.0.0/14",
+	ServiceCIDR: "172.30.0.0/16",
+}
+
+// AzurePlatform holds the settings of clusters on Azure
+type AzurePlatform struct {
+	// BaseDomainResourceGroup is the resource group of the public DNS zone of the base domain
+	BaseDomainResourceGroup string
+	// NetworkResourceGroup, VirtualNetwork, ControlPlaneSubnet, and ComputeSubnet install the
+	// cluster into an existing virtual network; empty creates a new one
+	NetworkResourceGroup string
+	VirtualNetwork       string
+	ControlPlaneSubnet   string
+	ComputeSubnet        string
+}
+
+// GCPPlatform holds the settings of clusters on GCP
+type GCPPlatform struct {
+	// ProjectID is the project of the cluster (default: the project of the service account)
+	ProjectID string
+	// Network, ControlPlaneSubnet, and ComputeSubnet install the cluster into an existing VPC
+	// network; empty creates a new one
+	Network            string
+	ControlPlaneSubnet string
+	ComputeSubnet      string
+}
+
+// VSpherePlatform holds the settings of clusters on vSphere
+type VSpherePlatform struct {
+	VCenter          string
+	Datacenter       string
+	DefaultDatastore string
+	Cluster          string
+	// Network is the port group the VMs are attached to
+	Network string
+	// Folder is the VM folder of the cluster (default: a folder named after the cluster)
+	Folder string
+	// APIVIP and IngressVIP are the virtual IPs of the API and the ingress routers, from the
+	// machine network
+	APIVIP     string
+	IngressVIP string
+}
+
+// vSphere worker machines have no instance types; they are sized like the installer defaults
+const (
+	vsphereCPUs           = 4
+	vsphereCoresPerSocket = 2
+	vsphereMemoryMB       = 16384
+	vsphereDiskSizeGB     = 120
+)
+
+// validatePlatform checks the settings of the platform of the spec
+func validatePlatform(spec ProvisionSpec) error {
+	provider := spec.Credentials.Provider
+	if provider != cloud.ProviderVSphere && spec.Region == "" {
+		return fmt.Errorf("region is required")
+	}
+
+	switch provider {
+	case cloud.ProviderAzure:
+		azure := spec.Azure
+		if azure.BaseDomainResourceGroup == "" {
+			return fmt.Errorf("the resource group of the base domain is required on azure")
+		}
+		if !allOrNone(azure.NetworkResourceGroup, azure.VirtualNetwork, azure.ControlPlaneSubnet, azure.ComputeSubnet) {
+			return fmt.Errorf("an existing azure virtual network requires its resource group, name, and control plane and compute subnets")
+		}
+	case cloud.ProviderGCP:
+		gcp := spec.GCP
+		if !allOrNone(gcp.Network, gcp.ControlPlaneSubnet, gcp.ComputeSubnet) {
+			return fmt.Errorf("an existing gcp network requires its name and control plane and compute subnets")
+		}
+	case cloud.ProviderVSphere:
+		vsphere := spec.VSphere
+		for _, setting := range []struct{ name, value string }{
+			{"vCenter", vsphere.VCenter},
+			{"datacenter", vsphere.Datacenter},
+			{"default datastore", vsphere.DefaultDatastore},
+			{"cluster", vsphere.Cluster},
+			{"network", vsphere.Network},
+			{"API VIP", vsphere.APIVIP},
+			{"ingress VIP", vsphere.IngressVIP},
+		} {
+			if setting.value == "" {
+				return fmt.Errorf("the vsphere %s is required", setting.name)
+			}
+		}
+		if spec.ControlPlane.InstanceType != "" || spec.Compute.InstanceType != "" {
+			return fmt.Errorf("vsphere machines have no instance types")
+		}
+		if len(spec.Zones) > 0 {
+			return fmt.Errorf("vsphere has no availability zones")
+		}
+	}
+	return nil
+}
+
+// installPlatform returns the platform settings of the install-config
+func installPlatform(spec ProvisionSpec) map[string]interface{} {
+	platform := map[string]interface{}{}
+	switch spec.Credentials.Provider {
+	case cloud.ProviderAzure:
+		platform["region"] = spec.Region
+		platform["baseDomainResourceGroupName"] = spec.Azure.BaseDomainResourceGroup
+		setIfNotEmpty(platform, "networkResourceGroupName", spec.Azure.NetworkResourceGroup)
+		setIfNotEmpty(platform, "virtualNetwork", spec.Azure.VirtualNetwork)
+		setIfNotEmpty(platform, "controlPlaneSubnet", spec.Azure.ControlPlaneSubnet)
+		setIfNotEmpty(platform, "computeSubnet", spec.Azure.ComputeSubnet)
+	case cloud.ProviderGCP:
+		platform["region"] = spec.Region
+		projectID := spec.GCP.ProjectID
+		if projectID == "" && spec.Credentials.GCP != nil {
+			projectID = spec.Credentials.GCP.ProjectID
+		}
+		setIfNotEmpty(platform, "projectID", projectID)
+		setIfNotEmpty(platform, "network", spec.GCP.Network)
+		setIfNotEmpty(platform, "controlPlaneSubnet", spec.GCP.ControlPlaneSubnet)
+		setIfNotEmpty(platform, "computeSubnet", spec.GCP.ComputeSubnet)
+	case cloud.ProviderVSphere:
+		// The vCenter username and password are injected by Hive from the credential secret
+		vsphere := spec.VSphere
+		platform["vCenter"] = vsphere.VCenter
+		platform["datacenter"] = vsphere.Datacenter
+		platform["defaultDatastore"] = vsphere.DefaultDatastore
+		platform["cluster"] = vsphere.Cluster
+		platform["network"] = vsphere.Network
+		setIfNotEmpty(platform, "folder", vsphere.Folder)
+		platform["apiVIPs"] = []string{vsphere.APIVIP}
+		platform["ingressVIPs"] = []string{vsphere.IngressVIP}
+	default:
+		platform["region"] = spec.Region
+	}
+	return platform
+}
+
+// deploymentPlatform returns the platform settings of the ClusterDeployment. certificatesSecret
+// holds the vCenter CA on vSphere.
+func deploymentPlatform(spec ProvisionSpec, credentialsSecret, certificatesSecret string) map[string]interface{} {
+	platform := map[string]interface{}{
+		"credentialsSecretRef": map[string]interface{}{"name": credentialsSecret},
+	}
+	switch spec.Credentials.Provider {
+	case cloud.ProviderAzure:
+		platform["region"] = spec.Region
+		platform["baseDomainResourceGroupName"] = spec.Azure.BaseDomainResourceGroup
+	case cloud.ProviderVSphere:
+		vsphere := spec.VSphere
+		platform["certificatesSecretRef"] = map[string]interface{}{"name": certificatesSecret}
+		platform["vCenter"] = vsphere.VCenter
+		platform["datacenter"] = vsphere.Datacenter
+		platform["defaultDatastore"] = vsphere.DefaultDatastore
+		platform["cluster"] = vsphere.Cluster
+		platform["network"] = vsphere.Network
+		setIfNotEmpty(platform, "folder", vsphere.Folder)
+	default:
+		platform["region"] = spec.Region
+	}
+	return platform
+}
+
+// workerPoolPlatform returns the platform settings of a worker MachinePool of instanceType,
+// defaulting to the worker instance type of the installer
+func workerPoolPlatform(spec ProvisionSpec, instanceType string) map[string]interface{} {
+	provider := spec.Credentials.Provider
+	if provider == cloud.ProviderVSphere {
+		return map[string]interface{}{
+			"cpus":           int64(vsphereCPUs),
+			"coresPerSocket": int64(vsphereCoresPerSocket),
+			"memoryMB":       int64(vsphereMemoryMB),
+			"osDisk":         map[string]interface{}{"diskSizeGB": int64(vsphereDiskSizeGB)},
+		}
+	}
+
+	platform := map[string]interface{}{"type": instanceType}
+	if instanceType == "" {
+		platform["type"] = defaultComputeTypes[provider]
+	}
+	if len(spec.Zones) > 0 {
+		platform["zones"] = toInterfaceSlice(spec.Zones)
+	}
+	switch provider {
+	case cloud.ProviderAWS:
+		platform["rootVolume"] = map[string]interface{}{"size": int64(120), "type": "gp3"}
+	case cloud.ProviderAzure:
+		platform["osDisk"] = map[string]interface{}{"diskSizeGB": int64(128)}
+	}
+	return platform
+}
+
+// allOrNone reports whether values are either all set or all empty
+func allOrNone(values ...string) bool {
+	set := 0
+	for _, value := range values {
+		if value != "" {
+			set++
+		}
+	}
+	return set == 0 || set == len(values)
+}
+
+// setIfNotEmpty sets key of settings to value unless value is empty
+func setIfNotEmpty(settings map[string]interface{}, key, value string) {
+	if value != "" {
+		settings[key] = value
+	}
+}
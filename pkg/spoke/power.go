@@ -0,0 +1,75 @@
+package spoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hive"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// PowerStateRunning requests that Hive resumes a hibernating cluster
+	PowerStateRunning = "Running"
+	// PowerStateHibernating requests that Hive hibernates a running cluster
+	PowerStateHibernating = "Hibernating"
+)
+
+// clusterDeploymentGVR identifies Hive ClusterDeployment resources
+var clusterDeploymentGVR = hive.ClusterDeploymentGVR
+
+// PowerManager changes the power state of spoke clusters through their ClusterDeployment
+type PowerManager interface {
+	// SetPowerState sets spec.powerState on the ClusterDeployment of the given cluster
+	SetPowerState(ctx context.Context, clusterName, state string) error
+}
+
+type powerManager struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewPowerManager creates a new PowerManager
+func NewPowerManager(dynamicClient dynamic.Interface, options ...kube.Option) PowerManager {
+	return &powerManager{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// SetPowerState patches the ClusterDeployment in namespace=clusterName with the requested power state.
+// Hive reconciles the change asynchronously; this call returns once the patch is accepted.
+func (p *powerManager) SetPowerState(ctx context.Context, clusterName, state string) error {
+	ctx, cancel, err := p.options.Start(ctx, "set power state", "cluster", clusterName, "state", state)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if state != PowerStateRunning && state != PowerStateHibernating {
+		return fmt.Errorf("invalid power state %q: must be %s or %s", state, PowerStateRunning, PowerStateHibernating)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"powerState": state,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build power state patch: %w", err)
+	}
+
+	_, err = p.dynamicClient.Resource(clusterDeploymentGVR).Namespace(clusterName).Patch(
+		ctx, clusterName, types.MergePatchType, patch, metav1.PatchOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set power state of ClusterDeployment %s: %w", clusterName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("PowerManager", func() {
+	var (
+		ctx         context.Context
+		fakeDynamic *fake.FakeDynamicClient
+		power       spoke.PowerManager
+		gvr         schema.GroupVersionResource
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		gvr = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+
+		cd := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "hive.openshift.io/v1",
+				"kind":       "ClusterDeployment",
+				"metadata": map[string]interface{}{
+					"name":      "test-cluster",
+					"namespace": "test-cluster",
+				},
+				"spec": map[string]interface{}{
+					"powerState": "Running",
+				},
+			},
+		}
+
+		fakeDynamic = fake.NewSimpleDynamicClient(runtime.NewScheme(), cd)
+		power = spoke.NewPowerManager(fakeDynamic)
+	})
+
+	It("should patch the ClusterDeployment power state", func() {
+		err := power.SetPowerState(ctx, "test-cluster", spoke.PowerStateHibernating)
+		Expect(err).NotTo(HaveOccurred())
+
+		cd, err := fakeDynamic.Resource(gvr).Namespace("test-cluster").Get(ctx, "test-cluster", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		state, _, _ := unstructured.NestedString(cd.Object, "spec", "powerState")
+		Expect(state).To(Equal("Hibernating"))
+	})
+
+	It("should reject unknown power states", func() {
+		err := power.SetPowerState(ctx, "test-cluster", "Sleeping")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid power state"))
+	})
+
+	It("should return an error when the ClusterDeployment does not exist", func() {
+		err := power.SetPowerState(ctx, "missing", spoke.PowerStateRunning)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not found"))
+	})
+})
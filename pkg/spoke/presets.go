@@ -0,0 +1,152 @@
+package spoke
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+)
+
+const (
+	// MachinePoolPresetLabel is set on the nodes of preset pools to the name of their preset
+	MachinePoolPresetLabel = "labrat.openshift-partner-labs.io/machine-pool-preset"
+
+	// GPURoleLabel is the node role label of the nodes of the GPU presets
+	GPURoleLabel = "node-role.kubernetes.io/gpu"
+
+	// GPUTaintKey is the taint of the nodes of the GPU presets, tolerated by the GPU workloads
+	// of OpenShift AI, so that other workloads do not fill up the expensive nodes
+	GPUTaintKey = "nvidia.com/gpu"
+)
+
+// Taint is a taint set on the nodes of a MachinePool
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// MachinePoolPreset is a predefined autoscaled worker MachinePool added to a cluster in addition
+// to the default worker pool, e.g. GPU workers for OpenShift AI
+type MachinePoolPreset struct {
+	// Name selects the preset and is the name of the pool, e.g. gpu-small
+	Name string `json:"name"`
+	// Description summarizes the machines of the preset
+	Description string `json:"description"`
+	// InstanceTypes are the instance types of the pool by provider; the preset is not
+	// available on providers without one
+	InstanceTypes map[string]string `json:"instanceTypes"`
+	// MinReplicas and MaxReplicas bound the number of machines the cluster autoscaler keeps
+	MinReplicas int64 `json:"minReplicas"`
+	MaxReplicas int64 `json:"maxReplicas"`
+	// Labels and Taints are set on the nodes of the pool
+	Labels map[string]string `json:"labels"`
+	Taints []Taint           `json:"taints"`
+}
+
+// InstanceType returns the instance type of the preset on provider
+func (p MachinePoolPreset) InstanceType(provider string) (string, error) {
+	instanceType, ok := p.InstanceTypes[provider]
+	if !ok {
+		return "", fmt.Errorf("machine pool preset %s is not available on %s", p.Name, provider)
+	}
+	return instanceType, nil
+}
+
+// gpuPreset returns a GPU preset with the labels and taint of the GPU nodes
+func gpuPreset(name, description string, instanceTypes map[string]string, minReplicas, maxReplicas int64) MachinePoolPreset {
+	return MachinePoolPreset{
+		Name:          name,
+		Description:   description,
+		InstanceTypes: instanceTypes,
+		MinReplicas:   minReplicas,
+		MaxReplicas:   maxReplicas,
+		Labels:        map[string]string{GPURoleLabel: "", MachinePoolPresetLabel: name},
+		Taints:        []Taint{{Key: GPUTaintKey, Effect: "NoSchedule"}},
+	}
+}
+
+// machinePoolPresets are the presets selectable by name
+var machinePoolPresets = []MachinePoolPreset{
+	gpuPreset("gpu-small", "1 NVIDIA GPU per node (A10G on AWS, T4 on Azure, L4 on GCP)", map[string]string{
+		cloud.ProviderAWS:   "g5.2xlarge",
+		cloud.ProviderAzure: "Standard_NC8as_T4_v3",
+		cloud.ProviderGCP:   "g2-standard-8",
+	}, 1, 3),
+	gpuPreset("gpu-medium", "4 NVIDIA GPUs per node (A10G on AWS, T4 on Azure, L4 on GCP)", map[string]string{
+		cloud.ProviderAWS:   "g5.12xlarge",
+		cloud.ProviderAzure: "Standard_NC64as_T4_v3",
+		cloud.ProviderGCP:   "g2-standard-48",
+	}, 1, 2),
+	gpuPreset("gpu-a100", "NVIDIA A100 GPUs (8 per node on AWS, 1 on Azure and GCP)", map[string]string{
+		cloud.ProviderAWS:   "p4d.24xlarge",
+		cloud.ProviderAzure: "Standard_NC24ads_A100_v4",
+		cloud.ProviderGCP:   "a2-highgpu-1g",
+	}, 1, 2),
+}
+
+// MachinePoolPresets returns the machine pool presets, sorted by name
+func MachinePoolPresets() []MachinePoolPreset {
+	presets := make([]MachinePoolPreset, len(machinePoolPresets))
+	copy(presets, machinePoolPresets)
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets
+}
+
+// MachinePoolPresetNames returns the names of the machine pool presets, sorted
+func MachinePoolPresetNames() []string {
+	presets := MachinePoolPresets()
+	names := make([]string, 0, len(presets))
+	for _, preset := range presets {
+		names = append(names, preset.Name)
+	}
+	return names
+}
+
+// GetMachinePoolPreset returns the machine pool preset of the given name
+func GetMachinePoolPreset(name string) (MachinePoolPreset, error) {
+	for _, preset := range machinePoolPresets {
+		if preset.Name == name {
+			return preset, nil
+		}
+	}
+	return MachinePoolPreset{}, fmt.Errorf("unknown machine pool preset %q (presets: %s)", name, strings.Join(MachinePoolPresetNames(), ", "))
+}
+
+// presetPoolSpec returns the spec of the MachinePool of a preset on the provider of spec
+func presetPoolSpec(spec ProvisionSpec, preset MachinePoolPreset) (map[string]interface{}, error) {
+	provider := spec.Credentials.Provider
+	instanceType, err := preset.InstanceType(provider)
+	if err != nil {
+		return nil, err
+	}
+	platform := workerPoolPlatform(spec, instanceType)
+	if provider == cloud.ProviderGCP {
+		// GPU machines cannot be live migrated
+		platform["onHostMaintenance"] = "Terminate"
+	}
+
+	labels := make(map[string]interface{}, len(preset.Labels))
+	for key, value := range preset.Labels {
+		labels[key] = value
+	}
+	taints := make([]interface{}, 0, len(preset.Taints))
+	for _, taint := range preset.Taints {
+		fields := map[string]interface{}{"key": taint.Key, "effect": taint.Effect}
+		setIfNotEmpty(fields, "value", taint.Value)
+		taints = append(taints, fields)
+	}
+
+	return map[string]interface{}{
+		"clusterDeploymentRef": map[string]interface{}{"name": spec.Name},
+		"name":                 preset.Name,
+		"platform":             map[string]interface{}{provider: platform},
+		"autoscaling": map[string]interface{}{
+			"minReplicas": preset.MinReplicas,
+			"maxReplicas": preset.MaxReplicas,
+		},
+		"labels": labels,
+		"taints": taints,
+	}, nil
+}
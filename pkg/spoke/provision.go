@@ -0,0 +1,629 @@
+package spoke
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// provisionGVRs maps the kinds of the rendered provisioning resources to their resources
+var provisionGVRs = map[string]schema.GroupVersionResource{
+	"Namespace":         {Version: "v1", Resource: "namespaces"},
+	"Secret":            {Version: "v1", Resource: "secrets"},
+	"ClusterDeployment": clusterDeploymentGVR,
+	"MachinePool":       machinePoolGVR,
+	"ManagedCluster":    {Group: "cluster.open-cluster-management.io", Version: "v1", Resource: "managedclusters"},
+	"ConfigMap":         {Version: "v1", Resource: "configmaps"},
+	"KlusterletAddonConfig": {
+		Group: "agent.open-cluster-management.io", Version: "v1", Resource: "klusterletaddonconfigs",
+	},
+}
+
+// clusterScopedKinds are the kinds of provisionGVRs that are not namespaced
+var clusterScopedKinds = map[string]bool{
+	"Namespace":      true,
+	"ManagedCluster": true,
+}
+
+// credentialKeys are the keys Hive reads from the cloud credential secret of each provider
+var credentialKeys = map[string][]string{
+	cloud.ProviderAWS:     {"aws_access_key_id", "aws_secret_access_key"},
+	cloud.ProviderAzure:   {"osServicePrincipal.json"},
+	cloud.ProviderGCP:     {"osServiceAccount.json"},
+	cloud.ProviderVSphere: {"username", "password"},
+}
+
+// defaultComputeTypes are the worker instance types of the installer, used for the worker
+// MachinePool when no compute type is requested
+var defaultComputeTypes = map[string]string{
+	cloud.ProviderAWS:   cloud.DefaultAWSInstanceType,
+	cloud.ProviderAzure: "Standard_D4s_v3",
+	cloud.ProviderGCP:   "n2-standard-4",
+}
+
+// ProvisionSpec describes a spoke cluster to provision through Hive
+type ProvisionSpec struct {
+	// Name is the cluster name, also used as the namespace of its hub resources
+	Name string
+	// RequestID is the partner request the cluster is provisioned for
+	RequestID string
+	// BaseDomain is the DNS domain the cluster's records are created under
+	BaseDomain string
+	// ImageSet is the ClusterImageSet providing the OpenShift release to install
+	ImageSet string
+	// Region is the region the cluster is provisioned into; vSphere has no regions
+	Region string
+	// Zones restricts the machine pools to specific availability zones; empty means every zone
+	Zones []string
+	// ControlPlane and Compute are the machine pools of the cluster
+	ControlPlane cloud.MachinePool
+	Compute      cloud.MachinePool
+	// Credentials is the cloud credential secret Hive provisions with; its data is copied
+	// into the cluster namespace
+	Credentials *cloud.Credentials
+	// PullSecret is the OpenShift pull secret (.dockerconfigjson)
+	PullSecret []byte
+	// SSHPrivateKey and SSHPublicKey are the node SSH key pair
+	SSHPrivateKey []byte
+	SSHPublicKey  string
+	// Networking is the network configuration of the cluster
+	Networking Networking
+	// Azure, GCP, and VSphere are the settings of the platform of the credentials; the
+	// settings of other platforms are ignored
+	Azure   AzurePlatform
+	GCP     GCPPlatform
+	VSphere VSpherePlatform
+	// Template, if set, renders the ClusterDeployment and the other manifests of the cluster
+	// instead of the built-in manifests. The namespace and the secrets holding the credentials,
+	// pull secret, and SSH key are always rendered by labrat, so templates never see them.
+	Template ManifestTemplate
+	// Values are the variables passed to Template
+	Values map[string]string
+	// Snippets are merged into the rendered install-config in order, e.g. to enable FIPS or
+	// a proxy
+	Snippets []InstallConfigSnippet
+	// Presets add an autoscaled MachinePool each, e.g. GPU workers
+	Presets []MachinePoolPreset
+}
+
+// ManifestTemplate renders the manifests of a cluster from TemplateData
+type ManifestTemplate interface {
+	Render(data interface{}) ([]*unstructured.Unstructured, error)
+}
+
+// TemplateData is passed to the ManifestTemplate of a ProvisionSpec
+type TemplateData struct {
+	Name         string
+	RequestID    string
+	Provider     string
+	BaseDomain   string
+	ImageSet     string
+	Region       string
+	Zones        []string
+	ControlPlane cloud.MachinePool
+	Compute      cloud.MachinePool
+	SSHPublicKey string
+	Networking   Networking
+	Azure        AzurePlatform
+	GCP          GCPPlatform
+	VSphere      VSpherePlatform
+	// InstallConfig is the install-config labrat renders from the spec, for templates that
+	// only add manifests
+	InstallConfig string
+	// Secrets names the secrets rendered by labrat
+	Secrets TemplateSecrets
+	// Values are the template variables of the config and --set
+	Values map[string]string
+}
+
+// TemplateSecrets names the secrets of a cluster, for templates to reference
+type TemplateSecrets struct {
+	// Credentials holds the cloud credentials, rendered by labrat
+	Credentials string
+	// PullSecret holds the pull secret, rendered by labrat
+	PullSecret string
+	// SSHKey holds the SSH private key, rendered by labrat
+	SSHKey string
+	// Certificates holds the vCenter CA on vSphere, rendered by labrat
+	Certificates string
+	// InstallConfig is the name the built-in manifests give the install-config secret
+	InstallConfig string
+}
+
+// ProvisionedResource is a hub resource applied for a new cluster
+type ProvisionedResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Created is false when the resource already existed and was left unchanged
+	Created bool `json:"created"`
+}
+
+// Provisioner provisions spoke clusters by applying Hive resources to the hub
+type Provisioner interface {
+	// Provision applies the namespace, secrets, ClusterDeployment, worker MachinePool, and
+	// ManagedCluster of a new cluster. Resources that already exist are left unchanged, so a
+	// failed run can be retried.
+	Provision(ctx context.Context, spec ProvisionSpec) ([]ProvisionedResource, error)
+	// Render returns the resources Provision would apply, without applying them
+	Render(ctx context.Context, spec ProvisionSpec) ([]*unstructured.Unstructured, error)
+}
+
+type provisioner struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewProvisioner creates a new Provisioner using a dynamic client connected to the hub
+func NewProvisioner(dynamicClient dynamic.Interface, options ...kube.Option) Provisioner {
+	return &provisioner{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Provision copies the cloud credentials into the cluster namespace, renders the remaining
+// resources, and creates them in order
+func (p *provisioner) Provision(ctx context.Context, spec ProvisionSpec) ([]ProvisionedResource, error) {
+	ctx, cancel, err := p.options.Start(ctx, "provision cluster", "cluster", spec.Name, "requestID", spec.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	objects, err := p.render(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ProvisionedResource, 0, len(objects))
+	for _, obj := range objects {
+		resource := ProvisionedResource{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), Created: true}
+		client := p.dynamicClient.Resource(provisionGVRs[resource.Kind])
+		var ri dynamic.ResourceInterface = client
+		if resource.Namespace != "" {
+			ri = client.Namespace(resource.Namespace)
+		}
+
+		err := p.options.Retry(ctx, func(ctx context.Context) error {
+			_, err := ri.Create(ctx, obj, metav1.CreateOptions{})
+			return err
+		})
+		if apierrors.IsAlreadyExists(err) {
+			resource.Created = false
+		} else if err != nil {
+			return resources, fmt.Errorf("failed to create %s %s: %w", resource.Kind, resource.Name, err)
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// Render reads the cloud credentials and renders the resources of the cluster
+func (p *provisioner) Render(ctx context.Context, spec ProvisionSpec) ([]*unstructured.Unstructured, error) {
+	ctx, cancel, err := p.options.Start(ctx, "render cluster", "cluster", spec.Name, "requestID", spec.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	return p.render(ctx, spec)
+}
+
+// render renders the resources of the cluster without starting an operation
+func (p *provisioner) render(ctx context.Context, spec ProvisionSpec) ([]*unstructured.Unstructured, error) {
+	if spec.Credentials == nil {
+		return nil, fmt.Errorf("cloud credentials are required")
+	}
+	var secret *unstructured.Unstructured
+	err := p.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		secret, err = p.dynamicClient.Resource(provisionGVRs["Secret"]).Namespace(spec.Credentials.Namespace).
+			Get(ctx, spec.Credentials.Name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credential secret %s/%s: %w", spec.Credentials.Namespace, spec.Credentials.Name, err)
+	}
+	credentialData, _, _ := unstructured.NestedStringMap(secret.Object, "data")
+
+	return RenderProvision(spec, credentialData)
+}
+
+// RenderProvision renders the hub resources that provision a cluster, in the order they must
+// be created. credentialData is the base64-encoded data of the cloud credential secret.
+func RenderProvision(spec ProvisionSpec, credentialData map[string]string) ([]*unstructured.Unstructured, error) {
+	switch {
+	case spec.Name == "":
+		return nil, fmt.Errorf("cluster name is required")
+	case spec.BaseDomain == "":
+		return nil, fmt.Errorf("base domain is required")
+	case spec.ImageSet == "":
+		return nil, fmt.Errorf("ClusterImageSet is required")
+	case spec.Credentials == nil:
+		return nil, fmt.Errorf("cloud credentials are required")
+	case len(spec.PullSecret) == 0:
+		return nil, fmt.Errorf("pull secret is required")
+	case len(spec.SSHPrivateKey) == 0:
+		return nil, fmt.Errorf("SSH private key is required")
+	}
+
+	provider := spec.Credentials.Provider
+	keys, ok := credentialKeys[provider]
+	if !ok {
+		return nil, fmt.Errorf("provisioning on %s is not supported", provider)
+	}
+	credentials := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		value, ok := credentialData[key]
+		if !ok {
+			return nil, fmt.Errorf("credential secret %s/%s has no %s", spec.Credentials.Namespace, spec.Credentials.Name, key)
+		}
+		credentials[key] = value
+	}
+	if err := validatePlatform(spec); err != nil {
+		return nil, err
+	}
+
+	if spec.ControlPlane.Replicas <= 0 {
+		spec.ControlPlane.Replicas = cloud.DefaultControlPlaneReplicas
+	}
+
+	installConfigData, err := renderInstallConfig(spec)
+	if err != nil {
+		return nil, err
+	}
+	if installConfigData, err = mergeInstallConfigSnippets(installConfigData, spec.Snippets); err != nil {
+		return nil, err
+	}
+
+	name := spec.Name
+	credentialsSecret := fmt.Sprintf("%s-%s-creds", name, provider)
+	installConfigSecret := name + "-install-config"
+	pullSecret := name + "-pull-secret"
+	sshKeySecret := name + "-ssh-private-key"
+	certificatesSecret := name + "-vsphere-certs"
+
+	newSecret := func(secretName, secretType string, data map[string]interface{}) *unstructured.Unstructured {
+		return provisionObject("v1", "Secret", name, secretName, spec.RequestID, map[string]interface{}{
+			"type": secretType,
+			"data": data,
+		})
+	}
+
+	clusterDeployment := provisionObject("hive.openshift.io/v1", "ClusterDeployment", name, name, spec.RequestID, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"baseDomain":  spec.BaseDomain,
+			"clusterName": name,
+			"platform": map[string]interface{}{
+				provider: deploymentPlatform(spec, credentialsSecret, certificatesSecret),
+			},
+			"provisioning": map[string]interface{}{
+				"installConfigSecretRef": map[string]interface{}{"name": installConfigSecret},
+				"sshPrivateKeySecretRef": map[string]interface{}{"name": sshKeySecret},
+				"imageSetRef":            map[string]interface{}{"name": spec.ImageSet},
+			},
+			"pullSecretRef": map[string]interface{}{"name": pullSecret},
+		},
+	})
+	labels := map[string]string{
+		hub.RequestIDLabel:                   spec.RequestID,
+		"hive.openshift.io/cluster-platform": provider,
+	}
+	if spec.Region != "" {
+		labels["hive.openshift.io/cluster-region"] = spec.Region
+	}
+	clusterDeployment.SetLabels(labels)
+
+	workerPool := provisionObject("hive.openshift.io/v1", "MachinePool", name, name+"-worker", spec.RequestID, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"clusterDeploymentRef": map[string]interface{}{"name": name},
+			"name":                 "worker",
+			"replicas":             int64(spec.Compute.Replicas),
+			"platform":             map[string]interface{}{provider: workerPoolPlatform(spec, spec.Compute.InstanceType)},
+		},
+	})
+
+	var presetPools []*unstructured.Unstructured
+	for _, preset := range spec.Presets {
+		for _, pool := range presetPools {
+			if pool.GetName() == name+"-"+preset.Name {
+				return nil, fmt.Errorf("machine pool preset %s is given twice", preset.Name)
+			}
+		}
+		poolSpec, err := presetPoolSpec(spec, preset)
+		if err != nil {
+			return nil, err
+		}
+		presetPools = append(presetPools, provisionObject("hive.openshift.io/v1", "MachinePool", name, name+"-"+preset.Name, spec.RequestID, map[string]interface{}{
+			"spec": poolSpec,
+		}))
+	}
+
+	managedCluster := provisionObject("cluster.open-cluster-management.io/v1", "ManagedCluster", "", name, spec.RequestID, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"hubAcceptsClient": true,
+		},
+	})
+	managedCluster.SetLabels(map[string]string{
+		"name":   name,
+		"vendor": "OpenShift",
+	})
+
+	objects := []*unstructured.Unstructured{
+		provisionObject("v1", "Namespace", "", name, spec.RequestID, nil),
+		newSecret(credentialsSecret, "Opaque", credentials),
+		newSecret(pullSecret, "kubernetes.io/dockerconfigjson", map[string]interface{}{
+			".dockerconfigjson": base64.StdEncoding.EncodeToString(spec.PullSecret),
+		}),
+		newSecret(sshKeySecret, "Opaque", map[string]interface{}{
+			"ssh-privatekey": base64.StdEncoding.EncodeToString(spec.SSHPrivateKey),
+		}),
+	}
+	if provider == cloud.ProviderVSphere {
+		caCertificate, ok := credentialData["cacertificate"]
+		if !ok {
+			return nil, fmt.Errorf("credential secret %s/%s has no cacertificate", spec.Credentials.Namespace, spec.Credentials.Name)
+		}
+		objects = append(objects, newSecret(certificatesSecret, "Opaque", map[string]interface{}{
+			".cacert": caCertificate,
+		}))
+	}
+	if spec.Template == nil {
+		return append(append(objects,
+			newSecret(installConfigSecret, "Opaque", map[string]interface{}{
+				"install-config.yaml": base64.StdEncoding.EncodeToString(installConfigData),
+			}),
+			clusterDeployment,
+			workerPool,
+		), append(presetPools, managedCluster)...), nil
+	}
+
+	templated, err := spec.Template.Render(TemplateData{
+		Name:          name,
+		RequestID:     spec.RequestID,
+		Provider:      provider,
+		BaseDomain:    spec.BaseDomain,
+		ImageSet:      spec.ImageSet,
+		Region:        spec.Region,
+		Zones:         spec.Zones,
+		ControlPlane:  spec.ControlPlane,
+		Compute:       spec.Compute,
+		SSHPublicKey:  spec.SSHPublicKey,
+		Networking:    spec.Networking,
+		Azure:         spec.Azure,
+		GCP:           spec.GCP,
+		VSphere:       spec.VSphere,
+		InstallConfig: string(installConfigData),
+		Secrets: TemplateSecrets{
+			Credentials:   credentialsSecret,
+			PullSecret:    pullSecret,
+			SSHKey:        sshKeySecret,
+			InstallConfig: installConfigSecret,
+			Certificates:  certificatesSecret,
+		},
+		Values: spec.Values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTemplated(name, spec.RequestID, templated); err != nil {
+		return nil, err
+	}
+	for _, pool := range presetPools {
+		for _, obj := range templated {
+			if obj.GetKind() == "MachinePool" && obj.GetName() == pool.GetName() {
+				return nil, fmt.Errorf("template renders MachinePool %s, which conflicts with a machine pool preset", obj.GetName())
+			}
+		}
+	}
+	return append(append(objects, templated...), presetPools...), nil
+}
+
+// checkTemplated checks that the objects rendered by a template can be applied and contain a
+// ClusterDeployment, and annotates them with the request like the built-in manifests. Objects
+// of namespaced kinds default to the namespace of the cluster.
+func checkTemplated(name, requestID string, objects []*unstructured.Unstructured) error {
+	hasClusterDeployment := false
+	for _, obj := range objects {
+		kind := obj.GetKind()
+		if _, ok := provisionGVRs[kind]; !ok {
+			return fmt.Errorf("template renders %s %s, but only %s can be applied", kind, obj.GetName(), supportedKinds())
+		}
+		if kind == "Namespace" || kind == "Secret" && isRenderedSecret(name, obj.GetName()) {
+			return fmt.Errorf("template renders %s %s, which labrat renders itself", kind, obj.GetName())
+		}
+		if !clusterScopedKinds[kind] && obj.GetNamespace() == "" {
+			obj.SetNamespace(name)
+		}
+		if requestID != "" {
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[hub.RequestIDAnnotation] = requestID
+			obj.SetAnnotations(annotations)
+		}
+		hasClusterDeployment = hasClusterDeployment || kind == "ClusterDeployment"
+	}
+	if !hasClusterDeployment {
+		return fmt.Errorf("template renders no ClusterDeployment")
+	}
+	return nil
+}
+
+// isRenderedSecret reports whether secretName is one of the credential secrets labrat renders
+// for the cluster name
+func isRenderedSecret(name, secretName string) bool {
+	return secretName == name+"-pull-secret" || secretName == name+"-ssh-private-key" || secretName == name+"-vsphere-certs" ||
+		strings.HasPrefix(secretName, name+"-") && strings.HasSuffix(secretName, "-creds")
+}
+
+// supportedKinds returns the kinds templates can render, sorted
+func supportedKinds() string {
+	kinds := make([]string, 0, len(provisionGVRs))
+	for kind := range provisionGVRs {
+		if kind != "Namespace" {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	return strings.Join(kinds, ", ")
+}
+
+// RedactedValue replaces secret values in the manifests returned by RedactSecrets
+const RedactedValue = "<redacted>"
+
+// RedactSecrets returns copies of objects safe to print for review: the data of Secrets is
+// moved to stringData, with the install-config decoded and every other value redacted
+func RedactSecrets(objects []*unstructured.Unstructured) []*unstructured.Unstructured {
+	redacted := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, obj := range objects {
+		obj = obj.DeepCopy()
+		if obj.GetKind() == "Secret" {
+			data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+			stringData := make(map[string]interface{}, len(data))
+			for key, value := range data {
+				stringData[key] = RedactedValue
+				if key == "install-config.yaml" {
+					if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+						stringData[key] = string(decoded)
+					}
+				}
+			}
+			unstructured.RemoveNestedField(obj.Object, "data")
+			obj.Object["stringData"] = stringData
+		}
+		redacted = append(redacted, obj)
+	}
+	return redacted
+}
+
+// toInterfaceSlice converts values to the slice type of unstructured objects
+func toInterfaceSlice(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
+
+// provisionObject builds an object annotated with the request it is provisioned for
+func provisionObject(apiVersion, kind, namespace, name, requestID string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for key, value := range fields {
+		obj.Object[key] = value
+	}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	if requestID != "" {
+		obj.SetAnnotations(map[string]string{hub.RequestIDAnnotation: requestID})
+	}
+	return obj
+}
+
+// installConfig is the subset of the openshift-install install-config rendered by labrat
+type installConfig struct {
+	APIVersion   string                 `yaml:"apiVersion"`
+	Metadata     map[string]string      `yaml:"metadata"`
+	BaseDomain   string                 `yaml:"baseDomain"`
+	ControlPlane installMachinePool     `yaml:"controlPlane"`
+	Compute      []installMachinePool   `yaml:"compute"`
+	Networking   installNetworking      `yaml:"networking"`
+	Platform     map[string]interface{} `yaml:"platform"`
+	// PullSecret is injected by Hive from the pull secret of the ClusterDeployment
+	PullSecret string `yaml:"pullSecret"`
+	SSHKey     string `yaml:"sshKey,omitempty"`
+}
+
+type installMachinePool struct {
+	Name     string                 `yaml:"name"`
+	Replicas int                    `yaml:"replicas"`
+	Platform map[string]interface{} `yaml:"platform"`
+}
+
+type installNetworking struct {
+	NetworkType    string                   `yaml:"networkType"`
+	ClusterNetwork []map[string]interface{} `yaml:"clusterNetwork"`
+	MachineNetwork []map[string]string      `yaml:"machineNetwork"`
+	ServiceNetwork []string                 `yaml:"serviceNetwork"`
+}
+
+// renderInstallConfig renders the install-config.yaml of a cluster, with the installer's
+// defaults for the networking the spec leaves empty
+func renderInstallConfig(spec ProvisionSpec) ([]byte, error) {
+	provider := spec.Credentials.Provider
+
+	machinePlatform := func(pool cloud.MachinePool) map[string]interface{} {
+		settings := map[string]interface{}{}
+		if pool.InstanceType != "" && provider != cloud.ProviderVSphere {
+			settings["type"] = pool.InstanceType
+		}
+		if len(spec.Zones) > 0 {
+			settings["zones"] = spec.Zones
+		}
+		return map[string]interface{}{provider: settings}
+	}
+
+	networking := spec.Networking
+	for _, field := range []struct {
+		value    *string
+		fallback string
+	}{
+		{&networking.NetworkType, defaultNetworking.NetworkType},
+		{&networking.MachineCIDR, defaultNetworking.MachineCIDR},
+		{&networking.ClusterCIDR, defaultNetworking.ClusterCIDR},
+		{&networking.ServiceCIDR, defaultNetworking.ServiceCIDR},
+	} {
+		if *field.value == "" {
+			*field.value = field.fallback
+		}
+	}
+
+	config := installConfig{
+		APIVersion: "v1",
+		Metadata:   map[string]string{"name": spec.Name},
+		BaseDomain: spec.BaseDomain,
+		ControlPlane: installMachinePool{
+			Name:     "master",
+			Replicas: spec.ControlPlane.Replicas,
+			Platform: machinePlatform(spec.ControlPlane),
+		},
+		Compute: []installMachinePool{{
+			Name:     "worker",
+			Replicas: spec.Compute.Replicas,
+			Platform: machinePlatform(spec.Compute),
+		}},
+		Networking: installNetworking{
+			NetworkType:    networking.NetworkType,
+			ClusterNetwork: []map[string]interface{}{{"cidr": networking.ClusterCIDR, "hostPrefix": 23}},
+			MachineNetwork: []map[string]string{{"cidr": networking.MachineCIDR}},
+			ServiceNetwork: []string{networking.ServiceCIDR},
+		},
+		Platform: map[string]interface{}{provider: installPlatform(spec)},
+		SSHKey:   spec.SSHPublicKey,
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render install-config: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,663 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/redhat-openshift-partner-labs/labrat/internal/template"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/cloud"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+var _ = Describe("Provisioner", func() {
+	var (
+		ctx            context.Context
+		spec           spoke.ProvisionSpec
+		credentialData map[string]string
+	)
+
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	nestedString := func(obj *unstructured.Unstructured, fields ...string) string {
+		value, _, _ := unstructured.NestedString(obj.Object, fields...)
+		return value
+	}
+
+	decodeInstallConfig := func(secret *unstructured.Unstructured) map[string]interface{} {
+		encoded, _, _ := unstructured.NestedString(secret.Object, "data", "install-config.yaml")
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		Expect(err).NotTo(HaveOccurred())
+		installConfig := map[string]interface{}{}
+		Expect(yaml.Unmarshal(data, &installConfig)).To(Succeed())
+		return installConfig
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		credentialData = map[string]string{
+			"aws_access_key_id":     encode("AKIAEXAMPLE"),
+			"aws_secret_access_key": encode("secret"),
+			"baseDomain":            encode("labs.example.com"),
+		}
+		spec = spoke.ProvisionSpec{
+			Name:          "partner-1234",
+			RequestID:     "1234",
+			BaseDomain:    "labs.example.com",
+			ImageSet:      "img4.16.12-x86-64-appsub",
+			Region:        "us-east-2",
+			Zones:         []string{"us-east-2a", "us-east-2b"},
+			ControlPlane:  cloud.MachinePool{Replicas: cloud.DefaultControlPlaneReplicas},
+			Compute:       cloud.MachinePool{InstanceType: "m6i.2xlarge", Replicas: 2},
+			Credentials:   &cloud.Credentials{Name: "aws-lab", Namespace: "labrat", Provider: cloud.ProviderAWS},
+			PullSecret:    []byte(`{"auths":{}}`),
+			SSHPrivateKey: []byte("PRIVATE KEY"),
+			SSHPublicKey:  "ssh-ed25519 AAAA lab",
+		}
+	})
+
+	Describe("RenderProvision", func() {
+		It("should render the namespace, secrets, ClusterDeployment, and ManagedCluster in order", func() {
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			var kinds []string
+			for _, obj := range objects {
+				kinds = append(kinds, obj.GetKind()+"/"+obj.GetName())
+				Expect(obj.GetAnnotations()).To(HaveKeyWithValue(hub.RequestIDAnnotation, "1234"))
+			}
+			Expect(kinds).To(Equal([]string{
+				"Namespace/partner-1234",
+				"Secret/partner-1234-aws-creds",
+				"Secret/partner-1234-pull-secret",
+				"Secret/partner-1234-ssh-private-key",
+				"Secret/partner-1234-install-config",
+				"ClusterDeployment/partner-1234",
+				"MachinePool/partner-1234-worker",
+				"ManagedCluster/partner-1234",
+			}))
+		})
+
+		It("should render the worker MachinePool from the compute pool", func() {
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			pool := objects[6]
+			Expect(pool.GetNamespace()).To(Equal("partner-1234"))
+			Expect(nestedString(pool, "spec", "clusterDeploymentRef", "name")).To(Equal("partner-1234"))
+			Expect(nestedString(pool, "spec", "name")).To(Equal("worker"))
+			Expect(nestedString(pool, "spec", "platform", "aws", "type")).To(Equal("m6i.2xlarge"))
+			replicas, _, _ := unstructured.NestedInt64(pool.Object, "spec", "replicas")
+			Expect(replicas).To(Equal(int64(2)))
+			zones, _, _ := unstructured.NestedStringSlice(pool.Object, "spec", "platform", "aws", "zones")
+			Expect(zones).To(Equal([]string{"us-east-2a", "us-east-2b"}))
+
+			spec.Compute.InstanceType = ""
+			objects, err = spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nestedString(objects[6], "spec", "platform", "aws", "type")).To(Equal(cloud.DefaultAWSInstanceType))
+		})
+
+		It("should copy only the credential keys Hive reads", func() {
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, _, _ := unstructured.NestedStringMap(objects[1].Object, "data")
+			Expect(data).To(Equal(map[string]string{
+				"aws_access_key_id":     encode("AKIAEXAMPLE"),
+				"aws_secret_access_key": encode("secret"),
+			}))
+		})
+
+		It("should reference the secrets and image set from the ClusterDeployment", func() {
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			cd := objects[5]
+			Expect(cd.GetNamespace()).To(Equal("partner-1234"))
+			Expect(cd.GetLabels()).To(HaveKeyWithValue(hub.RequestIDLabel, "1234"))
+			Expect(nestedString(cd, "spec", "platform", "aws", "credentialsSecretRef", "name")).To(Equal("partner-1234-aws-creds"))
+			Expect(nestedString(cd, "spec", "platform", "aws", "region")).To(Equal("us-east-2"))
+			Expect(nestedString(cd, "spec", "provisioning", "imageSetRef", "name")).To(Equal("img4.16.12-x86-64-appsub"))
+			Expect(nestedString(cd, "spec", "provisioning", "installConfigSecretRef", "name")).To(Equal("partner-1234-install-config"))
+			Expect(nestedString(cd, "spec", "provisioning", "sshPrivateKeySecretRef", "name")).To(Equal("partner-1234-ssh-private-key"))
+			Expect(nestedString(cd, "spec", "pullSecretRef", "name")).To(Equal("partner-1234-pull-secret"))
+		})
+
+		It("should render the install-config from the spec", func() {
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			encoded, _, _ := unstructured.NestedString(objects[4].Object, "data", "install-config.yaml")
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			Expect(err).NotTo(HaveOccurred())
+
+			var installConfig struct {
+				Metadata     map[string]string `yaml:"metadata"`
+				BaseDomain   string            `yaml:"baseDomain"`
+				ControlPlane struct {
+					Replicas int `yaml:"replicas"`
+				} `yaml:"controlPlane"`
+				Compute []struct {
+					Replicas int `yaml:"replicas"`
+					Platform struct {
+						AWS struct {
+							Type  string   `yaml:"type"`
+							Zones []string `yaml:"zones"`
+						} `yaml:"aws"`
+					} `yaml:"platform"`
+				} `yaml:"compute"`
+				Platform struct {
+					AWS struct {
+						Region string `yaml:"region"`
+					} `yaml:"aws"`
+				} `yaml:"platform"`
+				SSHKey string `yaml:"sshKey"`
+			}
+			Expect(yaml.Unmarshal(data, &installConfig)).To(Succeed())
+			Expect(installConfig.Metadata["name"]).To(Equal("partner-1234"))
+			Expect(installConfig.BaseDomain).To(Equal("labs.example.com"))
+			Expect(installConfig.ControlPlane.Replicas).To(Equal(3))
+			Expect(installConfig.Compute).To(HaveLen(1))
+			Expect(installConfig.Compute[0].Replicas).To(Equal(2))
+			Expect(installConfig.Compute[0].Platform.AWS.Type).To(Equal("m6i.2xlarge"))
+			Expect(installConfig.Compute[0].Platform.AWS.Zones).To(Equal([]string{"us-east-2a", "us-east-2b"}))
+			Expect(installConfig.Platform.AWS.Region).To(Equal("us-east-2"))
+			Expect(installConfig.SSHKey).To(Equal("ssh-ed25519 AAAA lab"))
+		})
+
+		It("should reject incomplete specs", func() {
+			spec.ImageSet = ""
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError("ClusterImageSet is required"))
+		})
+
+		It("should reject credential secrets missing a key", func() {
+			delete(credentialData, "aws_secret_access_key")
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError(ContainSubstring("has no aws_secret_access_key")))
+		})
+
+		It("should reject unsupported providers", func() {
+			spec.Credentials.Provider = "openstack"
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError("provisioning on openstack is not supported"))
+		})
+
+		It("should render the networking of the spec", func() {
+			spec.Networking = spoke.Networking{MachineCIDR: "10.10.0.0/16", ServiceCIDR: "172.31.0.0/16"}
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			installConfig := decodeInstallConfig(objects[4])
+			Expect(installConfig).To(HaveKeyWithValue("networking", map[string]interface{}{
+				"networkType":    "OVNKubernetes",
+				"clusterNetwork": []interface{}{map[string]interface{}{"cidr": "10.128.0.0/14", "hostPrefix": 23}},
+				"machineNetwork": []interface{}{map[string]interface{}{"cidr": "10.10.0.0/16"}},
+				"serviceNetwork": []interface{}{"172.31.0.0/16"},
+			}))
+		})
+	})
+
+	Describe("RenderProvision with snippets", func() {
+		It("should merge the snippets into the install-config", func() {
+			spec.Snippets = []spoke.InstallConfigSnippet{
+				{Name: "fips", Values: map[string]interface{}{"fips": true}},
+				{Name: "acme-network", Values: map[string]interface{}{
+					"networking": map[string]interface{}{
+						"machineNetwork": []interface{}{map[string]interface{}{"cidr": "10.20.0.0/16"}},
+					},
+				}},
+				{Name: "proxy-acme", Values: map[string]interface{}{
+					"proxy": map[string]interface{}{"httpProxy": "http://proxy.acme.example:3128"},
+				}},
+			}
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			installConfig := decodeInstallConfig(objects[4])
+			Expect(installConfig).To(HaveKeyWithValue("fips", true))
+			Expect(installConfig).To(HaveKeyWithValue("proxy", map[string]interface{}{"httpProxy": "http://proxy.acme.example:3128"}))
+			Expect(installConfig).To(HaveKeyWithValue("baseDomain", "labs.example.com"))
+			networking := installConfig["networking"].(map[string]interface{})
+			Expect(networking).To(HaveKeyWithValue("machineNetwork", []interface{}{map[string]interface{}{"cidr": "10.20.0.0/16"}}))
+			Expect(networking).To(HaveKeyWithValue("networkType", "OVNKubernetes"))
+		})
+
+		It("should allow snippets that agree on a field", func() {
+			spec.Snippets = []spoke.InstallConfigSnippet{
+				{Name: "fips", Values: map[string]interface{}{"fips": true}},
+				{Name: "hardened", Values: map[string]interface{}{"fips": true, "publish": "Internal"}},
+			}
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject snippets that set a field to different values", func() {
+			spec.Snippets = []spoke.InstallConfigSnippet{
+				{Name: "proxy-acme", Values: map[string]interface{}{
+					"proxy": map[string]interface{}{"httpProxy": "http://proxy.acme.example:3128"},
+				}},
+				{Name: "proxy-globex", Values: map[string]interface{}{
+					"proxy": map[string]interface{}{"httpProxy": "http://proxy.globex.example:8080"},
+				}},
+			}
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError("snippets proxy-acme and proxy-globex conflict: both set proxy.httpProxy"))
+		})
+
+		It("should reject snippets replacing a mapping another snippet set fields of", func() {
+			spec.Snippets = []spoke.InstallConfigSnippet{
+				{Name: "proxy-acme", Values: map[string]interface{}{
+					"proxy": map[string]interface{}{"httpProxy": "http://proxy.acme.example:3128"},
+				}},
+				{Name: "no-proxy", Values: map[string]interface{}{"proxy": nil}},
+			}
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError("snippets proxy-acme and no-proxy conflict: both set proxy"))
+		})
+
+		It("should reject snippets setting fields labrat manages", func() {
+			spec.Snippets = []spoke.InstallConfigSnippet{
+				{Name: "rename", Values: map[string]interface{}{"metadata": map[string]interface{}{"name": "other"}}},
+			}
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError("snippet rename sets metadata, which labrat sets from the cluster spec"))
+		})
+	})
+
+	Describe("RenderProvision with machine pool presets", func() {
+		preset := func(name string) spoke.MachinePoolPreset {
+			p, err := spoke.GetMachinePoolPreset(name)
+			Expect(err).NotTo(HaveOccurred())
+			return p
+		}
+
+		It("should render an autoscaled MachinePool per preset before the ManagedCluster", func() {
+			spec.Presets = []spoke.MachinePoolPreset{preset("gpu-small")}
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(objects).To(HaveLen(9))
+			pool := objects[7]
+			Expect(pool.GetKind() + "/" + pool.GetName()).To(Equal("MachinePool/partner-1234-gpu-small"))
+			Expect(pool.GetAnnotations()).To(HaveKeyWithValue(hub.RequestIDAnnotation, "1234"))
+			Expect(nestedString(pool, "spec", "name")).To(Equal("gpu-small"))
+			Expect(nestedString(pool, "spec", "platform", "aws", "type")).To(Equal("g5.2xlarge"))
+			zones, _, _ := unstructured.NestedStringSlice(pool.Object, "spec", "platform", "aws", "zones")
+			Expect(zones).To(Equal([]string{"us-east-2a", "us-east-2b"}))
+			Expect(pool.Object["spec"]).To(HaveKeyWithValue("autoscaling", map[string]interface{}{
+				"minReplicas": int64(1),
+				"maxReplicas": int64(3),
+			}))
+			Expect(pool.Object["spec"]).NotTo(HaveKey("replicas"))
+			labels, _, _ := unstructured.NestedStringMap(pool.Object, "spec", "labels")
+			Expect(labels).To(Equal(map[string]string{spoke.GPURoleLabel: "", spoke.MachinePoolPresetLabel: "gpu-small"}))
+			Expect(pool.Object["spec"]).To(HaveKeyWithValue("taints", []interface{}{
+				map[string]interface{}{"key": spoke.GPUTaintKey, "effect": "NoSchedule"},
+			}))
+			Expect(objects[8].GetKind()).To(Equal("ManagedCluster"))
+		})
+
+		It("should not live migrate GCP GPU machines", func() {
+			spec.Credentials = &cloud.Credentials{Name: "gcp-lab", Namespace: "labrat", Provider: cloud.ProviderGCP,
+				GCP: &cloud.GCPCredentials{ProjectID: "lab-project"}}
+			spec.Region = "us-central1"
+			spec.Zones = nil
+			spec.Presets = []spoke.MachinePoolPreset{preset("gpu-a100")}
+			credentialData = map[string]string{"osServiceAccount.json": encode(`{"project_id":"lab-project"}`)}
+
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nestedString(objects[7], "spec", "platform", "gcp", "type")).To(Equal("a2-highgpu-1g"))
+			Expect(nestedString(objects[7], "spec", "platform", "gcp", "onHostMaintenance")).To(Equal("Terminate"))
+		})
+
+		It("should reject presets not available on the provider or given twice", func() {
+			spec.Presets = []spoke.MachinePoolPreset{preset("gpu-small"), preset("gpu-small")}
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError("machine pool preset gpu-small is given twice"))
+
+			spec.Presets = []spoke.MachinePoolPreset{{Name: "gpu-onprem", InstanceTypes: map[string]string{cloud.ProviderVSphere: ""}}}
+			_, err = spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError("machine pool preset gpu-onprem is not available on aws"))
+		})
+
+		It("should reject unknown presets", func() {
+			Expect(spoke.MachinePoolPresetNames()).To(Equal([]string{"gpu-a100", "gpu-medium", "gpu-small"}))
+			_, err := spoke.GetMachinePoolPreset("gpu-huge")
+			Expect(err).To(MatchError(`unknown machine pool preset "gpu-huge" (presets: gpu-a100, gpu-medium, gpu-small)`))
+		})
+	})
+
+	Describe("RenderProvision on other platforms", func() {
+		It("should render Azure clusters with the resource group of the base domain", func() {
+			spec.Credentials = &cloud.Credentials{Name: "azure-lab", Namespace: "labrat", Provider: cloud.ProviderAzure}
+			spec.Region = "eastus"
+			spec.Zones = nil
+			spec.Compute.InstanceType = ""
+			spec.Azure = spoke.AzurePlatform{BaseDomainResourceGroup: "labs-dns"}
+			credentialData = map[string]string{"osServicePrincipal.json": encode(`{"clientId":"id"}`)}
+
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(objects[1].GetName()).To(Equal("partner-1234-azure-creds"))
+			cd := objects[5]
+			Expect(nestedString(cd, "spec", "platform", "azure", "region")).To(Equal("eastus"))
+			Expect(nestedString(cd, "spec", "platform", "azure", "baseDomainResourceGroupName")).To(Equal("labs-dns"))
+			Expect(nestedString(objects[6], "spec", "platform", "azure", "type")).To(Equal("Standard_D4s_v3"))
+
+			installConfig := decodeInstallConfig(objects[4])
+			Expect(installConfig["platform"]).To(Equal(map[string]interface{}{
+				"azure": map[string]interface{}{"region": "eastus", "baseDomainResourceGroupName": "labs-dns"},
+			}))
+		})
+
+		It("should require the resource group of the base domain on Azure", func() {
+			spec.Credentials.Provider = cloud.ProviderAzure
+			credentialData = map[string]string{"osServicePrincipal.json": encode(`{}`)}
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError("the resource group of the base domain is required on azure"))
+		})
+
+		It("should render GCP clusters in the project of the service account", func() {
+			spec.Credentials = &cloud.Credentials{Name: "gcp-lab", Namespace: "labrat", Provider: cloud.ProviderGCP,
+				GCP: &cloud.GCPCredentials{ProjectID: "lab-project"}}
+			spec.Region = "us-central1"
+			spec.Zones = nil
+			spec.GCP = spoke.GCPPlatform{Network: "labs", ControlPlaneSubnet: "labs-master", ComputeSubnet: "labs-worker"}
+			credentialData = map[string]string{"osServiceAccount.json": encode(`{"project_id":"lab-project"}`)}
+
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			installConfig := decodeInstallConfig(objects[4])
+			Expect(installConfig["platform"]).To(Equal(map[string]interface{}{
+				"gcp": map[string]interface{}{
+					"projectID":          "lab-project",
+					"region":             "us-central1",
+					"network":            "labs",
+					"controlPlaneSubnet": "labs-master",
+					"computeSubnet":      "labs-worker",
+				},
+			}))
+			Expect(nestedString(objects[5], "spec", "platform", "gcp", "region")).To(Equal("us-central1"))
+		})
+
+		It("should reject partial existing GCP networks", func() {
+			spec.Credentials.Provider = cloud.ProviderGCP
+			spec.GCP = spoke.GCPPlatform{Network: "labs"}
+			credentialData = map[string]string{"osServiceAccount.json": encode(`{}`)}
+			_, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError(ContainSubstring("an existing gcp network requires")))
+		})
+
+		Context("on vSphere", func() {
+			BeforeEach(func() {
+				spec.Credentials = &cloud.Credentials{Name: "vsphere-lab", Namespace: "labrat", Provider: cloud.ProviderVSphere}
+				spec.Region = ""
+				spec.Zones = nil
+				spec.Compute.InstanceType = ""
+				spec.VSphere = spoke.VSpherePlatform{
+					VCenter:          "vcenter.lab.example.com",
+					Datacenter:       "dc1",
+					DefaultDatastore: "ds1",
+					Cluster:          "cluster1",
+					Network:          "VM Network",
+					APIVIP:           "10.0.0.10",
+					IngressVIP:       "10.0.0.11",
+				}
+				credentialData = map[string]string{
+					"username":      encode("labrat@vsphere.local"),
+					"password":      encode("secret"),
+					"cacertificate": encode("CERTIFICATE"),
+					"vCenter":       encode("vcenter.lab.example.com"),
+				}
+			})
+
+			It("should render the vCenter CA and the placement of the cluster", func() {
+				objects, err := spoke.RenderProvision(spec, credentialData)
+				Expect(err).NotTo(HaveOccurred())
+
+				var kinds []string
+				for _, obj := range objects {
+					kinds = append(kinds, obj.GetKind()+"/"+obj.GetName())
+				}
+				Expect(kinds).To(ContainElements("Secret/partner-1234-vsphere-creds", "Secret/partner-1234-vsphere-certs"))
+
+				data, _, _ := unstructured.NestedStringMap(objects[1].Object, "data")
+				Expect(data).To(HaveLen(2))
+				certs, _, _ := unstructured.NestedStringMap(objects[4].Object, "data")
+				Expect(certs).To(Equal(map[string]string{".cacert": encode("CERTIFICATE")}))
+
+				cd := objects[6]
+				Expect(cd.GetLabels()).NotTo(HaveKey("hive.openshift.io/cluster-region"))
+				Expect(nestedString(cd, "spec", "platform", "vsphere", "vCenter")).To(Equal("vcenter.lab.example.com"))
+				Expect(nestedString(cd, "spec", "platform", "vsphere", "certificatesSecretRef", "name")).To(Equal("partner-1234-vsphere-certs"))
+				Expect(nestedString(cd, "spec", "platform", "vsphere", "network")).To(Equal("VM Network"))
+
+				cpus, _, _ := unstructured.NestedInt64(objects[7].Object, "spec", "platform", "vsphere", "cpus")
+				Expect(cpus).To(BeNumerically(">", 0))
+
+				installConfig := decodeInstallConfig(objects[5])
+				platform := installConfig["platform"].(map[string]interface{})["vsphere"]
+				Expect(platform).To(HaveKeyWithValue("apiVIPs", []interface{}{"10.0.0.10"}))
+				Expect(platform).To(HaveKeyWithValue("ingressVIPs", []interface{}{"10.0.0.11"}))
+				Expect(platform).NotTo(HaveKey("password"))
+			})
+
+			It("should require the vCenter CA", func() {
+				delete(credentialData, "cacertificate")
+				_, err := spoke.RenderProvision(spec, credentialData)
+				Expect(err).To(MatchError(ContainSubstring("has no cacertificate")))
+			})
+
+			It("should require the virtual IPs", func() {
+				spec.VSphere.IngressVIP = ""
+				_, err := spoke.RenderProvision(spec, credentialData)
+				Expect(err).To(MatchError("the vsphere ingress VIP is required"))
+			})
+
+			It("should reject instance types", func() {
+				spec.Compute.InstanceType = "m6i.xlarge"
+				_, err := spoke.RenderProvision(spec, credentialData)
+				Expect(err).To(MatchError("vsphere machines have no instance types"))
+			})
+		})
+	})
+
+	Describe("RenderProvision with a template", func() {
+		const clusterTemplate = `apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Secrets.InstallConfig }}
+stringData:
+  install-config.yaml: |
+{{ .InstallConfig | indent 4 }}
+---
+apiVersion: hive.openshift.io/v1
+kind: ClusterDeployment
+metadata:
+  name: {{ .Name }}
+spec:
+  baseDomain: {{ .BaseDomain }}
+  clusterName: {{ .Name }}
+  platform:
+    {{ .Provider }}:
+      credentialsSecretRef:
+        name: {{ .Secrets.Credentials }}
+      region: {{ .Region }}
+  pullSecretRef:
+    name: {{ .Secrets.PullSecret }}
+---
+apiVersion: hive.openshift.io/v1
+kind: MachinePool
+metadata:
+  name: {{ .Name }}-gpu
+spec:
+  clusterDeploymentRef:
+    name: {{ .Name }}
+  name: gpu
+  replicas: {{ .Values.gpuReplicas }}
+`
+
+		BeforeEach(func() {
+			tmpl, err := template.Parse("gpu", clusterTemplate)
+			Expect(err).NotTo(HaveOccurred())
+			spec.Template = tmpl
+			spec.Values = map[string]string{"gpuReplicas": "2"}
+		})
+
+		It("should render the credential secrets and the manifests of the template", func() {
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			var kinds []string
+			for _, obj := range objects {
+				kinds = append(kinds, obj.GetKind()+"/"+obj.GetNamespace()+"/"+obj.GetName())
+				Expect(obj.GetAnnotations()).To(HaveKeyWithValue(hub.RequestIDAnnotation, "1234"))
+			}
+			Expect(kinds).To(Equal([]string{
+				"Namespace//partner-1234",
+				"Secret/partner-1234/partner-1234-aws-creds",
+				"Secret/partner-1234/partner-1234-pull-secret",
+				"Secret/partner-1234/partner-1234-ssh-private-key",
+				"Secret/partner-1234/partner-1234-install-config",
+				"ClusterDeployment/partner-1234/partner-1234",
+				"MachinePool/partner-1234/partner-1234-gpu",
+			}))
+			Expect(nestedString(objects[4], "stringData", "install-config.yaml")).To(ContainSubstring("baseDomain: labs.example.com"))
+			Expect(nestedString(objects[5], "spec", "platform", "aws", "credentialsSecretRef", "name")).To(Equal("partner-1234-aws-creds"))
+			replicas, _, _ := unstructured.NestedInt64(objects[6].Object, "spec", "replicas")
+			Expect(replicas).To(Equal(int64(2)))
+		})
+
+		It("should reject templates without a ClusterDeployment", func() {
+			tmpl, err := template.Parse("configmap", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n")
+			Expect(err).NotTo(HaveOccurred())
+			spec.Template = tmpl
+
+			_, err = spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError("template renders no ClusterDeployment"))
+		})
+
+		It("should reject kinds that cannot be applied and secrets rendered by labrat", func() {
+			tmpl, err := template.Parse("deployment", "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n")
+			Expect(err).NotTo(HaveOccurred())
+			spec.Template = tmpl
+			_, err = spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError(ContainSubstring("template renders Deployment web, but only")))
+
+			tmpl, err = template.Parse("pull-secret", "apiVersion: v1\nkind: Secret\nmetadata:\n  name: partner-1234-pull-secret\n")
+			Expect(err).NotTo(HaveOccurred())
+			spec.Template = tmpl
+			_, err = spoke.RenderProvision(spec, credentialData)
+			Expect(err).To(MatchError(ContainSubstring("which labrat renders itself")))
+		})
+	})
+
+	Describe("RedactSecrets", func() {
+		It("should redact secret values and decode the install-config", func() {
+			objects, err := spoke.RenderProvision(spec, credentialData)
+			Expect(err).NotTo(HaveOccurred())
+
+			redacted := spoke.RedactSecrets(objects)
+			Expect(redacted).To(HaveLen(len(objects)))
+
+			creds, _, _ := unstructured.NestedStringMap(redacted[1].Object, "stringData")
+			Expect(creds).To(Equal(map[string]string{
+				"aws_access_key_id":     spoke.RedactedValue,
+				"aws_secret_access_key": spoke.RedactedValue,
+			}))
+			Expect(redacted[1].Object).NotTo(HaveKey("data"))
+			Expect(nestedString(redacted[4], "stringData", "install-config.yaml")).To(ContainSubstring("baseDomain: labs.example.com"))
+			Expect(redacted[5].Object).To(Equal(objects[5].Object))
+
+			// The rendered objects are left unchanged
+			Expect(objects[1].Object).To(HaveKey("data"))
+		})
+	})
+
+	Describe("Provision", func() {
+		var fakeDynamic *fake.FakeDynamicClient
+
+		BeforeEach(func() {
+			data := make(map[string]interface{}, len(credentialData))
+			for key, value := range credentialData {
+				data[key] = value
+			}
+			credentialSecret := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]interface{}{"name": "aws-lab", "namespace": "labrat"},
+				"data":       data,
+			}}
+			fakeDynamic = fake.NewSimpleDynamicClient(runtime.NewScheme(), credentialSecret)
+		})
+
+		It("should create every resource and leave existing ones on retry", func() {
+			provisioner := spoke.NewProvisioner(fakeDynamic)
+
+			resources, err := provisioner.Provision(ctx, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resources).To(HaveLen(8))
+			for _, r := range resources {
+				Expect(r.Created).To(BeTrue(), r.Kind+" "+r.Name)
+			}
+
+			cdGVR := schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+			cd, err := fakeDynamic.Resource(cdGVR).Namespace("partner-1234").Get(ctx, "partner-1234", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cd.GetLabels()).To(HaveKeyWithValue(hub.RequestIDLabel, "1234"))
+
+			resources, err = provisioner.Provision(ctx, spec)
+			Expect(err).NotTo(HaveOccurred())
+			for _, r := range resources {
+				Expect(r.Created).To(BeFalse(), r.Kind+" "+r.Name)
+			}
+		})
+
+		It("should retry creates that fail transiently with WithRetry", func() {
+			fakeDynamic.PrependReactor("create", "managedclusters", failOnce(apierrors.NewServiceUnavailable("API server restarting")))
+
+			resources, err := spoke.NewProvisioner(fakeDynamic, kube.WithRetry(1, time.Millisecond)).Provision(ctx, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resources).To(HaveLen(8))
+			Expect(resources[7].Kind).To(Equal("ManagedCluster"))
+			Expect(resources[7].Created).To(BeTrue())
+		})
+
+		It("should render the resources without creating them", func() {
+			objects, err := spoke.NewProvisioner(fakeDynamic).Render(ctx, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objects).To(HaveLen(8))
+
+			cdGVR := schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+			_, err = fakeDynamic.Resource(cdGVR).Namespace("partner-1234").Get(ctx, "partner-1234", metav1.GetOptions{})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail when the credential secret does not exist", func() {
+			spec.Credentials.Name = "missing"
+			_, err := spoke.NewProvisioner(fakeDynamic).Provision(ctx, spec)
+			Expect(err).To(MatchError(ContainSubstring("failed to get credential secret labrat/missing")))
+		})
+	})
+})
@@ -0,0 +1,206 @@
+package spoke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+// installerContainer is the container of the Hive install pod running the installer
+const installerContainer = "hive"
+
+// ErrNoInstallPod is returned when the install pod of a cluster does not exist, e.g. because
+// Hive cleaned it up after the install
+var ErrNoInstallPod = errors.New("no install pod found")
+
+// ProvisionLogOptions selects the part of an installer log to read
+type ProvisionLogOptions struct {
+	// Follow keeps streaming the log until the install pod ends or the context is done
+	Follow bool
+	// TailLines limits the log to its last lines; 0 or less reads the whole log
+	TailLines int
+	// PollInterval is how often Stream checks whether a pending install pod started
+	PollInterval time.Duration
+}
+
+// ProvisionLogReader reads the installer logs of the Hive install attempts of spoke clusters
+type ProvisionLogReader interface {
+	// InstallPod returns the newest install pod of the latest install attempt of a cluster,
+	// or ErrNoInstallPod
+	InstallPod(ctx context.Context, clusterName string) (*corev1.Pod, error)
+	// SavedLog returns the end of the installer log Hive saved in the ClusterProvision of the
+	// latest install attempt, or "" if there is none
+	SavedLog(ctx context.Context, clusterName string) (string, error)
+	// Stream writes the installer log of the install pod of the latest attempt to out, or the
+	// log saved by Hive if the pod is gone
+	Stream(ctx context.Context, clusterName string, opts ProvisionLogOptions, out io.Writer) error
+}
+
+type provisionLogReader struct {
+	dynamicClient dynamic.Interface
+	coreClient    kubernetes.Interface
+	options       kube.Options
+}
+
+// NewProvisionLogReader creates a new ProvisionLogReader using clients connected to the hub
+func NewProvisionLogReader(dynamicClient dynamic.Interface, coreClient kubernetes.Interface, options ...kube.Option) ProvisionLogReader {
+	return &provisionLogReader{
+		dynamicClient: dynamicClient,
+		coreClient:    coreClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// InstallPod finds the pods of the install job of the latest ClusterProvision. Hive names the
+// job after the ClusterProvision, and the job controller labels its pods with the job name.
+func (r *provisionLogReader) InstallPod(ctx context.Context, clusterName string) (*corev1.Pod, error) {
+	ctx, cancel, err := r.options.Start(ctx, "find install pod", "cluster", clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	provision, err := r.latestProvision(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if provision == nil {
+		return nil, fmt.Errorf("%w: cluster %s has no ClusterProvision", ErrNoInstallPod, clusterName)
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{"job-name": provision.GetName() + "-provision"})
+	var pods *corev1.PodList
+	err = r.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		pods, err = r.coreClient.CoreV1().Pods(clusterName).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list install pods of %s: %w", clusterName, err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("%w for ClusterProvision %s/%s", ErrNoInstallPod, clusterName, provision.GetName())
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.Before(&pods.Items[j].CreationTimestamp)
+	})
+	return &pods.Items[len(pods.Items)-1], nil
+}
+
+// SavedLog reads spec.installLog of the latest ClusterProvision
+func (r *provisionLogReader) SavedLog(ctx context.Context, clusterName string) (string, error) {
+	ctx, cancel, err := r.options.Start(ctx, "read saved install log", "cluster", clusterName)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	provision, err := r.latestProvision(ctx, clusterName)
+	if err != nil || provision == nil {
+		return "", err
+	}
+	log, _, _ := unstructured.NestedString(provision.Object, "spec", "installLog")
+	return log, nil
+}
+
+// Stream copies the log of the installer container to out. With Follow, a pending pod is
+// waited for until it starts.
+func (r *provisionLogReader) Stream(ctx context.Context, clusterName string, opts ProvisionLogOptions, out io.Writer) error {
+	pod, err := r.InstallPod(ctx, clusterName)
+	if err != nil {
+		saved, savedErr := r.SavedLog(ctx, clusterName)
+		if savedErr != nil || saved == "" {
+			return err
+		}
+		if opts.TailLines > 0 {
+			saved = strings.Join(LastLines(saved, opts.TailLines), "\n") + "\n"
+		}
+		_, err := io.WriteString(out, saved)
+		return err
+	}
+
+	if opts.Follow && pod.Status.Phase == corev1.PodPending {
+		if pod, err = r.waitForStart(ctx, pod, opts.PollInterval); err != nil {
+			return err
+		}
+	}
+
+	logOptions := &corev1.PodLogOptions{Container: installerContainer, Follow: opts.Follow}
+	if opts.TailLines > 0 {
+		tail := int64(opts.TailLines)
+		logOptions.TailLines = &tail
+	}
+	stream, err := r.coreClient.CoreV1().Pods(clusterName).GetLogs(pod.Name, logOptions).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the log of install pod %s/%s: %w", clusterName, pod.Name, err)
+	}
+	defer stream.Close()
+
+	if _, err := io.Copy(out, stream); err != nil {
+		return fmt.Errorf("failed to stream the log of install pod %s/%s: %w", clusterName, pod.Name, err)
+	}
+	return nil
+}
+
+// waitForStart polls a pending pod until it leaves the Pending phase
+func (r *provisionLogReader) waitForStart(ctx context.Context, pod *corev1.Pod, interval time.Duration) (*corev1.Pod, error) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	err := wait.PollUntilContextCancel(ctx, interval, true, func(ctx context.Context) (bool, error) {
+		current, err := r.coreClient.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get install pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		pod = current
+		return pod.Status.Phase != corev1.PodPending, nil
+	})
+	return pod, err
+}
+
+// latestProvision returns the ClusterProvision with the highest attempt of a cluster, or nil
+func (r *provisionLogReader) latestProvision(ctx context.Context, clusterName string) (*unstructured.Unstructured, error) {
+	selector := labels.SelectorFromSet(labels.Set{clusterDeploymentNameLabel: clusterName})
+	var list *unstructured.UnstructuredList
+	err := r.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = r.dynamicClient.Resource(clusterProvisionGVR).Namespace(clusterName).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterProvisions of %s: %w", clusterName, err)
+	}
+
+	var latest *unstructured.Unstructured
+	var latestAttempt int64 = -1
+	for i := range list.Items {
+		attempt, _, _ := unstructured.NestedInt64(list.Items[i].Object, "spec", "attempt")
+		if attempt > latestAttempt {
+			latest, latestAttempt = &list.Items[i], attempt
+		}
+	}
+	return latest, nil
+}
+
+// LastLines returns the last n lines of log
+func LastLines(log string, n int) []string {
+	lines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -0,0 +1,99 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("ProvisionLogReader", func() {
+	var ctx context.Context
+
+	newProvision := func(attempt int64, installLog string) *unstructured.Unstructured {
+		spec := map[string]interface{}{"attempt": attempt, "stage": "failed"}
+		if installLog != "" {
+			spec["installLog"] = installLog
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterProvision",
+			"metadata": map[string]interface{}{
+				"name":      fmt.Sprintf("test-cluster-%d-abcde", attempt),
+				"namespace": "test-cluster",
+				"labels":    map[string]interface{}{"hive.openshift.io/cluster-deployment-name": "test-cluster"},
+			},
+			"spec": spec,
+		}}
+	}
+	newPod := func(name, job string, created time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "test-cluster", CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{"job-name": job},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	newReader := func(provisions []runtime.Object, pods ...runtime.Object) spoke.ProvisionLogReader {
+		fakeDynamic := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			{Group: "hive.openshift.io", Version: "v1", Resource: "clusterprovisions"}: "ClusterProvisionList",
+		}, provisions...)
+		return spoke.NewProvisionLogReader(fakeDynamic, k8sFake.NewSimpleClientset(pods...))
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should find the newest install pod of the latest attempt", func() {
+		now := time.Now()
+		reader := newReader([]runtime.Object{newProvision(0, ""), newProvision(1, "")},
+			newPod("attempt-0", "test-cluster-0-abcde-provision", now),
+			newPod("attempt-1-retried", "test-cluster-1-abcde-provision", now.Add(-time.Minute)),
+			newPod("attempt-1", "test-cluster-1-abcde-provision", now),
+		)
+
+		pod, err := reader.InstallPod(ctx, "test-cluster")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pod.Name).To(Equal("attempt-1"))
+	})
+
+	It("should report a missing install pod", func() {
+		_, err := newReader([]runtime.Object{newProvision(0, "")}).InstallPod(ctx, "test-cluster")
+		Expect(err).To(MatchError(spoke.ErrNoInstallPod))
+
+		_, err = newReader(nil).InstallPod(ctx, "test-cluster")
+		Expect(err).To(MatchError(ContainSubstring("cluster test-cluster has no ClusterProvision")))
+	})
+
+	It("should stream the log of the install pod", func() {
+		reader := newReader([]runtime.Object{newProvision(0, "")}, newPod("attempt-0", "test-cluster-0-abcde-provision", time.Now()))
+
+		var out bytes.Buffer
+		Expect(reader.Stream(ctx, "test-cluster", spoke.ProvisionLogOptions{Follow: true, TailLines: 10}, &out)).To(Succeed())
+		// The fake clientset returns a fixed log for every pod
+		Expect(out.String()).To(Equal("fake logs"))
+	})
+
+	It("should fall back to the log saved by Hive once the pod is gone", func() {
+		reader := newReader([]runtime.Object{newProvision(0, "one\ntwo\nthree\n")})
+
+		var out bytes.Buffer
+		Expect(reader.Stream(ctx, "test-cluster", spoke.ProvisionLogOptions{TailLines: 2}, &out)).To(Succeed())
+		Expect(out.String()).To(Equal("two\nthree\n"))
+	})
+})
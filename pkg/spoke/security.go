@@ -0,0 +1,188 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// EncryptionIdentity is reported when etcd encryption is not enabled
+	EncryptionIdentity = "identity"
+	// AuditProfileDefault is the audit profile of an APIServer without an explicit profile
+	AuditProfileDefault = "Default"
+	// PodSecurityPrivileged is the PodSecurity level enforced when no default is configured
+	PodSecurityPrivileged = "privileged"
+)
+
+var (
+	// apiServerGVR identifies the cluster-scoped OpenShift APIServer config
+	apiServerGVR = schema.GroupVersionResource{
+		Group:    "config.openshift.io",
+		Version:  "v1",
+		Resource: "apiservers",
+	}
+	// sccGVR identifies OpenShift SecurityContextConstraints
+	sccGVR = schema.GroupVersionResource{
+		Group:    "security.openshift.io",
+		Version:  "v1",
+		Resource: "securitycontextconstraints",
+	}
+)
+
+// SecurityPosture summarizes the security-relevant cluster configuration of a spoke
+type SecurityPosture struct {
+	// EtcdEncryption is the etcd encryption type (aescbc, aesgcm, or identity when disabled)
+	EtcdEncryption string `json:"etcdEncryption"`
+	// AuditProfile is the API server audit profile
+	AuditProfile string `json:"auditProfile"`
+	// DefaultSCC lists the SCCs granted to all authenticated users (restricted-v2, or restricted on legacy clusters)
+	DefaultSCC string `json:"defaultSCC"`
+	// PodSecurity is the cluster-wide default PodSecurity admission enforce level
+	PodSecurity string `json:"podSecurity"`
+	// FIPS reports whether the cluster was installed in FIPS mode
+	FIPS bool `json:"fips"`
+}
+
+// SecurityInspector reads the security posture of a spoke cluster
+type SecurityInspector interface {
+	// Inspect returns the security posture of the cluster
+	Inspect(ctx context.Context) (*SecurityPosture, error)
+}
+
+type securityInspector struct {
+	coreClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewSecurityInspector creates a new SecurityInspector using clients connected to the spoke cluster
+func NewSecurityInspector(coreClient kubernetes.Interface, dynamicClient dynamic.Interface, options ...kube.Option) SecurityInspector {
+	return &securityInspector{
+		coreClient:    coreClient,
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Inspect reads the APIServer config, the SCCs, the kube-apiserver admission config, and the
+// install config of the spoke
+func (s *securityInspector) Inspect(ctx context.Context) (*SecurityPosture, error) {
+	ctx, cancel, err := s.options.Start(ctx, "inspect security posture")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	posture := &SecurityPosture{
+		EtcdEncryption: EncryptionIdentity,
+		AuditProfile:   AuditProfileDefault,
+		PodSecurity:    PodSecurityPrivileged,
+	}
+
+	apiServer, err := s.dynamicClient.Resource(apiServerGVR).Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get APIServer config: %w", err)
+	}
+	if encryption, _, _ := unstructured.NestedString(apiServer.Object, "spec", "encryption", "type"); encryption != "" {
+		posture.EtcdEncryption = encryption
+	}
+	if profile, _, _ := unstructured.NestedString(apiServer.Object, "spec", "audit", "profile"); profile != "" {
+		posture.AuditProfile = profile
+	}
+
+	posture.DefaultSCC, err = s.defaultSCC(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	posture.PodSecurity, err = s.podSecurity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	posture.FIPS, err = s.fips(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return posture, nil
+}
+
+// defaultSCC returns the SCCs granted to system:authenticated, which every workload can use
+func (s *securityInspector) defaultSCC(ctx context.Context) (string, error) {
+	list, err := s.dynamicClient.Resource(sccGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list SecurityContextConstraints: %w", err)
+	}
+
+	var granted []string
+	for _, scc := range list.Items {
+		groups, _, _ := unstructured.NestedStringSlice(scc.Object, "groups")
+		if slices.Contains(groups, "system:authenticated") {
+			granted = append(granted, scc.GetName())
+		}
+	}
+	if len(granted) == 0 {
+		return "none", nil
+	}
+	slices.Sort(granted)
+	return strings.Join(granted, ","), nil
+}
+
+// podSecurity returns the default PodSecurity enforce level from the kube-apiserver admission config
+func (s *securityInspector) podSecurity(ctx context.Context) (string, error) {
+	cm, err := s.coreClient.CoreV1().ConfigMaps("openshift-kube-apiserver").Get(ctx, "config", metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get kube-apiserver config: %w", err)
+	}
+
+	// The operator renders config.yaml as JSON, which is also valid YAML
+	var config struct {
+		Admission struct {
+			PluginConfig struct {
+				PodSecurity struct {
+					Configuration struct {
+						Defaults struct {
+							Enforce string `yaml:"enforce"`
+						} `yaml:"defaults"`
+					} `yaml:"configuration"`
+				} `yaml:"PodSecurity"`
+			} `yaml:"pluginConfig"`
+		} `yaml:"admission"`
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data["config.yaml"]), &config); err != nil {
+		return "", fmt.Errorf("failed to parse kube-apiserver config: %w", err)
+	}
+
+	if enforce := config.Admission.PluginConfig.PodSecurity.Configuration.Defaults.Enforce; enforce != "" {
+		return enforce, nil
+	}
+	return PodSecurityPrivileged, nil
+}
+
+// fips reads the fips flag from the install config recorded in kube-system
+func (s *securityInspector) fips(ctx context.Context) (bool, error) {
+	cm, err := s.coreClient.CoreV1().ConfigMaps("kube-system").Get(ctx, "cluster-config-v1", metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get install config: %w", err)
+	}
+
+	var installConfig struct {
+		FIPS bool `yaml:"fips"`
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data["install-config"]), &installConfig); err != nil {
+		return false, fmt.Errorf("failed to parse install config: %w", err)
+	}
+	return installConfig.FIPS, nil
+}
@@ -0,0 +1,115 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("SecurityInspector", func() {
+	var (
+		ctx        context.Context
+		apiServer  *unstructured.Unstructured
+		sccs       []*unstructured.Unstructured
+		configMaps []runtime.Object
+	)
+
+	scc := func(name string, groups ...interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "security.openshift.io/v1",
+			"kind":       "SecurityContextConstraints",
+			"metadata":   map[string]interface{}{"name": name},
+			"groups":     groups,
+		}}
+	}
+
+	inspect := func() (*spoke.SecurityPosture, error) {
+		sccGVR := schema.GroupVersionResource{Group: "security.openshift.io", Version: "v1", Resource: "securitycontextconstraints"}
+		fakeDynamic := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			sccGVR: "SecurityContextConstraintsList",
+		}, apiServer)
+		// The fake tracker guesses the wrong resource name for SecurityContextConstraints, so create them explicitly
+		for _, obj := range sccs {
+			_, err := fakeDynamic.Resource(sccGVR).Create(ctx, obj, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return spoke.NewSecurityInspector(k8sFake.NewSimpleClientset(configMaps...), fakeDynamic).Inspect(ctx)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		apiServer = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "config.openshift.io/v1",
+			"kind":       "APIServer",
+			"metadata":   map[string]interface{}{"name": "cluster"},
+			"spec": map[string]interface{}{
+				"encryption": map[string]interface{}{"type": "aesgcm"},
+				"audit":      map[string]interface{}{"profile": "WriteRequestBodies"},
+			},
+		}}
+		sccs = []*unstructured.Unstructured{
+			scc("restricted-v2", "system:authenticated"),
+			scc("anyuid", "system:cluster-admins"),
+		}
+		configMaps = []runtime.Object{
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "openshift-kube-apiserver"},
+				Data: map[string]string{
+					"config.yaml": `{"admission":{"pluginConfig":{"PodSecurity":{"configuration":{"defaults":{"enforce":"restricted","audit":"restricted"}}}}}}`,
+				},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-config-v1", Namespace: "kube-system"},
+				Data: map[string]string{
+					"install-config": "apiVersion: v1\nbaseDomain: example.com\nfips: true\n",
+				},
+			},
+		}
+	})
+
+	It("should report the configured security settings", func() {
+		posture, err := inspect()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*posture).To(Equal(spoke.SecurityPosture{
+			EtcdEncryption: "aesgcm",
+			AuditProfile:   "WriteRequestBodies",
+			DefaultSCC:     "restricted-v2",
+			PodSecurity:    "restricted",
+			FIPS:           true,
+		}))
+	})
+
+	It("should report defaults for a cluster without explicit settings", func() {
+		apiServer.Object["spec"] = map[string]interface{}{}
+		sccs = append(sccs, scc("restricted", "system:authenticated"))
+		configMaps[0].(*corev1.ConfigMap).Data["config.yaml"] = `{"admission":{}}`
+		configMaps[1].(*corev1.ConfigMap).Data["install-config"] = "apiVersion: v1\n"
+
+		posture, err := inspect()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*posture).To(Equal(spoke.SecurityPosture{
+			EtcdEncryption: spoke.EncryptionIdentity,
+			AuditProfile:   spoke.AuditProfileDefault,
+			DefaultSCC:     "restricted,restricted-v2",
+			PodSecurity:    spoke.PodSecurityPrivileged,
+			FIPS:           false,
+		}))
+	})
+
+	It("should return an error when the install config is missing", func() {
+		configMaps = configMaps[:1]
+		_, err := inspect()
+		Expect(err).To(MatchError(ContainSubstring("failed to get install config")))
+	})
+})
@@ -0,0 +1,279 @@
+package spoke
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// SmokeReportName is the report (and JUnit test suite) name used by smoke tests
+	SmokeReportName = "labrat.spoke.smoke"
+	// DefaultSmokeImage is the image pulled and run by the smoke test pod
+	DefaultSmokeImage = "registry.access.redhat.com/ubi9/ubi:latest"
+	// DefaultSmokeTimeout bounds how long the smoke test pod may take to complete
+	DefaultSmokeTimeout = 3 * time.Minute
+
+	// smokeNamespacePrefix is the generateName prefix of the temporary test namespace
+	smokeNamespacePrefix = "labrat-smoke-"
+	// smokeDNSName is resolved from inside the test pod to verify cluster DNS
+	smokeDNSName = "kubernetes.default.svc.cluster.local"
+)
+
+// routeGVR identifies OpenShift Route resources
+var routeGVR = schema.GroupVersionResource{
+	Group:    "route.openshift.io",
+	Version:  "v1",
+	Resource: "routes",
+}
+
+// SmokeOptions configures a smoke test run
+type SmokeOptions struct {
+	// Image is the container image used for the test pod
+	Image string
+	// Timeout bounds how long the test pod may take to complete
+	Timeout time.Duration
+	// PollInterval is how often the test pod status is checked
+	PollInterval time.Duration
+	// HTTPClient is used for the ingress check. Lab clusters commonly serve the default
+	// self-signed ingress certificate, so the default client skips TLS verification.
+	HTTPClient *http.Client
+}
+
+// SmokeTester runs a curated set of quick functional checks against a spoke cluster
+type SmokeTester interface {
+	// Run executes the smoke tests and returns their results
+	Run(ctx context.Context) check.Report
+}
+
+type smokeTester struct {
+	coreClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
+	opts          SmokeOptions
+	options       kube.Options
+}
+
+// NewSmokeTester creates a new SmokeTester using clients connected to the spoke cluster
+func NewSmokeTester(coreClient kubernetes.Interface, dynamicClient dynamic.Interface, opts SmokeOptions, options ...kube.Option) SmokeTester {
+	if opts.Image == "" {
+		opts.Image = DefaultSmokeImage
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultSmokeTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // lab ingress uses self-signed certs
+			},
+		}
+	}
+
+	return &smokeTester{
+		coreClient:    coreClient,
+		dynamicClient: dynamicClient,
+		opts:          opts,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Run executes the smoke tests:
+// 1. Create a temporary namespace (remaining pod checks are skipped if this fails)
+// 2. Run a pod with the test image, verifying the image can be pulled
+// 3. Resolve the kubernetes service name from inside the pod, verifying cluster DNS
+// 4. Request the ingress canary route, verifying the default ingress controller
+// 5. Delete the temporary namespace
+func (s *smokeTester) Run(ctx context.Context) check.Report {
+	report := check.Report{Name: SmokeReportName}
+	ctx, cancel, err := s.options.Start(ctx, "run smoke test")
+	if err != nil {
+		report.Add(check.Result{Name: "Rate limit", Status: check.StatusFail, Message: err.Error()})
+		return report
+	}
+	defer cancel()
+
+	var namespace string
+	result := report.Run("Create test namespace", func() (check.Status, string) {
+		ns, err := s.coreClient.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: smokeNamespacePrefix},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return check.StatusFail, fmt.Sprintf("failed to create namespace: %v", err)
+		}
+		namespace = ns.Name
+		return check.StatusPass, namespace
+	})
+
+	if result.Status == check.StatusPass {
+		s.runPodChecks(ctx, &report, namespace)
+	}
+
+	report.Run("Ingress canary route", func() (check.Status, string) {
+		return s.checkIngress(ctx)
+	})
+
+	if result.Status == check.StatusPass {
+		report.Run("Delete test namespace", func() (check.Status, string) {
+			err := s.coreClient.CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+			if err != nil {
+				return check.StatusWarn, fmt.Sprintf("failed to delete namespace %s: %v", namespace, err)
+			}
+			return check.StatusPass, namespace
+		})
+	}
+
+	return report
+}
+
+// runPodChecks creates the test pod and records the image pull and DNS results
+func (s *smokeTester) runPodChecks(ctx context.Context, report *check.Report, namespace string) {
+	start := time.Now()
+	pod, err := s.coreClient.CoreV1().Pods(namespace).Create(ctx, s.smokePod(), metav1.CreateOptions{})
+	if err != nil {
+		report.Add(check.Result{
+			Name:     "Pull test image",
+			Status:   check.StatusFail,
+			Message:  fmt.Sprintf("failed to create test pod: %v", err),
+			Duration: time.Since(start),
+		})
+		return
+	}
+
+	var finalPod *corev1.Pod
+	var pullErr string
+	pollErr := wait.PollUntilContextTimeout(ctx, s.opts.PollInterval, s.opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		current, err := s.coreClient.CoreV1().Pods(namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		finalPod = current
+		if reason := imagePullFailure(current); reason != "" {
+			pullErr = reason
+			return true, nil
+		}
+		return current.Status.Phase == corev1.PodSucceeded || current.Status.Phase == corev1.PodFailed, nil
+	})
+
+	pullResult := check.Result{Name: "Pull test image", Duration: time.Since(start)}
+	dnsResult := check.Result{Name: "Cluster DNS resolution"}
+
+	switch {
+	case pullErr != "":
+		pullResult.Status = check.StatusFail
+		pullResult.Message = fmt.Sprintf("%s: %s", s.opts.Image, pullErr)
+		dnsResult.Status = check.StatusFail
+		dnsResult.Message = "not run: test pod did not start"
+	case pollErr != nil || finalPod == nil:
+		pullResult.Status = check.StatusFail
+		pullResult.Message = fmt.Sprintf("test pod did not complete within %s", s.opts.Timeout)
+		dnsResult.Status = check.StatusFail
+		dnsResult.Message = "not run: test pod did not complete"
+	default:
+		pullResult.Status = check.StatusPass
+		pullResult.Message = s.opts.Image
+		if finalPod.Status.Phase == corev1.PodSucceeded {
+			dnsResult.Status = check.StatusPass
+			dnsResult.Message = fmt.Sprintf("resolved %s", smokeDNSName)
+		} else {
+			dnsResult.Status = check.StatusFail
+			dnsResult.Message = fmt.Sprintf("failed to resolve %s from test pod", smokeDNSName)
+		}
+	}
+
+	report.Add(pullResult)
+	report.Add(dnsResult)
+}
+
+// smokePod builds the test pod. It satisfies the restricted-v2 SCC so it can run in any namespace.
+func (s *smokeTester) smokePod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "smoke-",
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "labrat",
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:            "smoke",
+					Image:           s.opts.Image,
+					ImagePullPolicy: corev1.PullAlways,
+					Command:         []string{"getent", "hosts", smokeDNSName},
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: ptr.To(false),
+						RunAsNonRoot:             ptr.To(true),
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"ALL"},
+						},
+						SeccompProfile: &corev1.SeccompProfile{
+							Type: corev1.SeccompProfileTypeRuntimeDefault,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// imagePullFailure returns the waiting reason if any container cannot pull its image
+func imagePullFailure(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting == nil {
+			continue
+		}
+		switch status.State.Waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+			return status.State.Waiting.Reason
+		}
+	}
+	return ""
+}
+
+// checkIngress requests the ingress canary route served by the default ingress controller
+func (s *smokeTester) checkIngress(ctx context.Context) (check.Status, string) {
+	route, err := s.dynamicClient.Resource(routeGVR).Namespace("openshift-ingress-canary").Get(ctx, "canary", metav1.GetOptions{})
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to get ingress canary route: %v", err)
+	}
+
+	host, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+	if host == "" {
+		return check.StatusFail, "ingress canary route has no host"
+	}
+
+	url := "https://" + host
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("failed to build request: %v", err)
+	}
+
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return check.StatusFail, fmt.Sprintf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return check.StatusFail, fmt.Sprintf("GET %s returned %s", url, resp.Status)
+	}
+	return check.StatusPass, url
+}
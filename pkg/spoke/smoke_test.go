@@ -0,0 +1,147 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/check"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("SmokeTester", func() {
+	var (
+		ctx         context.Context
+		fakeK8s     *k8sFake.Clientset
+		fakeDynamic *fake.FakeDynamicClient
+		server      *httptest.Server
+		podPhase    corev1.PodPhase
+		waiting     string
+	)
+
+	// nameGenerator emulates the API server's generateName handling, which the fake clientset lacks
+	nameGenerator := func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject()
+		switch o := obj.(type) {
+		case *corev1.Namespace:
+			o.Name = o.GenerateName + "abcde"
+		case *corev1.Pod:
+			o.Name = o.GenerateName + "abcde"
+		}
+		return false, nil, nil
+	}
+
+	runSmoke := func() check.Report {
+		fakeK8s.PrependReactor("create", "*", nameGenerator)
+		fakeK8s.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			pod := &corev1.Pod{Status: corev1.PodStatus{Phase: podPhase}}
+			pod.Name = action.(k8stesting.GetAction).GetName()
+			if waiting != "" {
+				pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waiting}},
+				}}
+			}
+			return true, pod, nil
+		})
+
+		tester := spoke.NewSmokeTester(fakeK8s, fakeDynamic, spoke.SmokeOptions{
+			Timeout:      time.Second,
+			PollInterval: 10 * time.Millisecond,
+			HTTPClient:   server.Client(),
+		})
+		return tester.Run(ctx)
+	}
+
+	resultFor := func(report check.Report, name string) check.Result {
+		for _, result := range report.Results {
+			if result.Name == name {
+				return result
+			}
+		}
+		return check.Result{}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		podPhase = corev1.PodSucceeded
+		waiting = ""
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		route := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "route.openshift.io/v1",
+			"kind":       "Route",
+			"metadata":   map[string]interface{}{"name": "canary", "namespace": "openshift-ingress-canary"},
+			"spec":       map[string]interface{}{"host": strings.TrimPrefix(server.URL, "https://")},
+		}}
+
+		fakeK8s = k8sFake.NewSimpleClientset()
+		fakeDynamic = fake.NewSimpleDynamicClient(runtime.NewScheme(), route)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should pass every check on a healthy cluster", func() {
+		report := runSmoke()
+
+		Expect(report.Name).To(Equal(spoke.SmokeReportName))
+		Expect(report.Results).To(HaveLen(5))
+		for _, result := range report.Results {
+			Expect(result.Status).To(Equal(check.StatusPass), result.Name+": "+result.Message)
+		}
+	})
+
+	It("should fail the image check when the image cannot be pulled", func() {
+		podPhase = corev1.PodPending
+		waiting = "ImagePullBackOff"
+
+		report := runSmoke()
+		Expect(resultFor(report, "Pull test image").Status).To(Equal(check.StatusFail))
+		Expect(resultFor(report, "Pull test image").Message).To(ContainSubstring("ImagePullBackOff"))
+		Expect(resultFor(report, "Cluster DNS resolution").Status).To(Equal(check.StatusFail))
+	})
+
+	It("should fail the DNS check when the pod fails", func() {
+		podPhase = corev1.PodFailed
+
+		report := runSmoke()
+		Expect(resultFor(report, "Pull test image").Status).To(Equal(check.StatusPass))
+		Expect(resultFor(report, "Cluster DNS resolution").Status).To(Equal(check.StatusFail))
+	})
+
+	It("should fail the ingress check when the canary is unhealthy", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		report := runSmoke()
+		Expect(resultFor(report, "Ingress canary route").Status).To(Equal(check.StatusFail))
+		Expect(report.Failed()).To(BeTrue())
+	})
+
+	It("should skip pod checks when the namespace cannot be created", func() {
+		fakeK8s.PrependReactor("create", "namespaces", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, context.DeadlineExceeded
+		})
+
+		report := runSmoke()
+		Expect(resultFor(report, "Create test namespace").Status).To(Equal(check.StatusFail))
+		Expect(resultFor(report, "Pull test image").Name).To(BeEmpty())
+		Expect(resultFor(report, "Delete test namespace").Name).To(BeEmpty())
+	})
+})
@@ -0,0 +1,121 @@
+package spoke
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// managedInstallConfigFields are the install-config fields labrat derives from the spec or
+// Hive injects, which snippets cannot set
+var managedInstallConfigFields = map[string]bool{
+	"apiVersion": true,
+	"metadata":   true,
+	"baseDomain": true,
+	"pullSecret": true,
+	"sshKey":     true,
+}
+
+// InstallConfigSnippet is a reusable fragment of an install-config, e.g. FIPS or proxy
+// settings, merged into the install-config labrat renders
+type InstallConfigSnippet struct {
+	// Name identifies the snippet in conflicts
+	Name string
+	// Values are the install-config fields the snippet sets
+	Values map[string]interface{}
+}
+
+// snippetMerger merges snippets into an install-config, recording which snippet set each
+// field to detect conflicts
+type snippetMerger struct {
+	owners map[string]string
+}
+
+// mergeInstallConfigSnippets merges snippets into the rendered install-config data in order.
+// Mappings are merged field by field; every other value, including lists, replaces the value
+// labrat rendered. Two snippets setting a field to different values conflict, as do snippets
+// setting a field labrat manages.
+func mergeInstallConfigSnippets(data []byte, snippets []InstallConfigSnippet) ([]byte, error) {
+	if len(snippets) == 0 {
+		return data, nil
+	}
+
+	installConfig := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &installConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse install-config: %w", err)
+	}
+	m := &snippetMerger{owners: map[string]string{}}
+	for _, snippet := range snippets {
+		if err := m.merge(installConfig, snippet.Values, "", snippet.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	merged, err := yaml.Marshal(installConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render install-config: %w", err)
+	}
+	return merged, nil
+}
+
+// merge merges the fields of src under path into dst on behalf of the snippet name
+func (m *snippetMerger) merge(dst, src map[string]interface{}, path, name string) error {
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field := key
+		if path != "" {
+			field = path + "." + key
+		}
+		if path == "" && managedInstallConfigFields[key] {
+			return fmt.Errorf("snippet %s sets %s, which labrat sets from the cluster spec", name, field)
+		}
+
+		value := src[key]
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			if err := m.merge(dstMap, srcMap, field, name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if owner := m.owner(field, name); owner != "" && !reflect.DeepEqual(dst[key], value) {
+			return fmt.Errorf("snippets %s and %s conflict: both set %s", owner, name, field)
+		}
+		dst[key] = value
+		m.own(field, value, name)
+	}
+	return nil
+}
+
+// owner returns the snippet other than name that set field or a field below it, if any
+func (m *snippetMerger) owner(field, name string) string {
+	if owner, ok := m.owners[field]; ok && owner != name {
+		return owner
+	}
+	for owned, owner := range m.owners {
+		if owner != name && strings.HasPrefix(owned, field+".") {
+			return owner
+		}
+	}
+	return ""
+}
+
+// own records that the snippet name set field to value, and every field below it
+func (m *snippetMerger) own(field string, value interface{}, name string) {
+	m.owners[field] = name
+	if values, ok := value.(map[string]interface{}); ok {
+		for key, v := range values {
+			m.own(field+"."+key, v, name)
+		}
+	}
+}
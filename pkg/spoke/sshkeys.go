@@ -0,0 +1,419 @@
+package spoke
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	apicorev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/sshkey"
+)
+
+const (
+	// SSHBastionAnnotation on a ClusterDeployment names the host `spoke ssh` jumps through to
+	// reach nodes without a public address, e.g. core@bastion.acme.example
+	SSHBastionAnnotation = "labrat.openshift-partner-labs.io/ssh-bastion"
+	// SSHKeyRotatedAnnotation records on the SSH key secret of a cluster when labrat last
+	// replaced the key
+	SSHKeyRotatedAnnotation = "labrat.openshift-partner-labs.io/ssh-key-rotated-at"
+	// CoreUser is the user of RHCOS nodes that SSH keys are authorized for
+	CoreUser = "core"
+
+	// sshPrivateKeyKey and sshPublicKeyKey are the keys of SSH key secrets
+	sshPrivateKeyKey = "ssh-privatekey"
+	sshPublicKeyKey  = "ssh-publickey"
+
+	// generatedByControllerAnnotation marks the MachineConfigs the machine config operator
+	// renders itself, which are never edited
+	generatedByControllerAnnotation = "machineconfiguration.openshift.io/generated-by-controller-version"
+)
+
+// machineConfigGVR identifies the MachineConfigs of the machine config operator
+var machineConfigGVR = schema.GroupVersionResource{
+	Group:    "machineconfiguration.openshift.io",
+	Version:  "v1",
+	Resource: "machineconfigs",
+}
+
+// SSHKeyInfo describes the SSH key Hive installed the nodes of a cluster with
+type SSHKeyInfo struct {
+	// Cluster is the name of the cluster
+	Cluster string `json:"cluster"`
+	// Secret is the name of the secret holding the key in the cluster namespace
+	Secret string `json:"secret"`
+	// PublicKey is the public key in the authorized_keys format, empty if the key is missing
+	PublicKey string `json:"publicKey,omitempty"`
+	// Fingerprint is the SHA256 fingerprint of the public key
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// UpdatedAt is when the key was created or last rotated by labrat
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+	// Bastion is the host to jump through to reach the nodes, from SSHBastionAnnotation
+	Bastion string `json:"bastion,omitempty"`
+	// Problem explains why the key cannot be used, e.g. a missing secret
+	Problem string `json:"problem,omitempty"`
+}
+
+// SSHKey is the SSH key of a cluster with its private key
+type SSHKey struct {
+	SSHKeyInfo
+	// PrivateKey is the PEM private key
+	PrivateKey []byte `json:"-"`
+}
+
+// SSHKeyManager manages the SSH key secrets Hive installs the nodes of clusters with, the
+// secret referenced by spec.provisioning.sshPrivateKeySecretRef of their ClusterDeployment
+type SSHKeyManager interface {
+	// List returns the SSH keys of every ClusterDeployment, sorted by cluster
+	List(ctx context.Context) ([]SSHKeyInfo, error)
+	// Get returns the SSH key of a cluster
+	Get(ctx context.Context, clusterName string) (*SSHKey, error)
+	// Create stores the SSH key of a cluster that has none
+	Create(ctx context.Context, clusterName string, pair sshkey.KeyPair) (*SSHKeyInfo, error)
+	// Update replaces the SSH key of a cluster
+	Update(ctx context.Context, clusterName string, pair sshkey.KeyPair) (*SSHKeyInfo, error)
+}
+
+type sshKeyManager struct {
+	dynamicClient dynamic.Interface
+	coreClient    corev1.CoreV1Interface
+	options       kube.Options
+	now           func() time.Time
+}
+
+// NewSSHKeyManager creates a new SSHKeyManager using clients connected to the hub
+func NewSSHKeyManager(
+	dynamicClient dynamic.Interface,
+	coreClient corev1.CoreV1Interface,
+	options ...kube.Option,
+) SSHKeyManager {
+	return &sshKeyManager{
+		dynamicClient: dynamicClient,
+		coreClient:    coreClient,
+		options:       kube.NewOptions(options...),
+		now:           time.Now,
+	}
+}
+
+// List reads the SSH key secret of every ClusterDeployment. Keys that are missing or
+// unreadable are listed with a Problem rather than failing the list.
+func (m *sshKeyManager) List(ctx context.Context) ([]SSHKeyInfo, error) {
+	ctx, cancel, err := m.options.Start(ctx, "list SSH keys")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	err = m.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = m.dynamicClient.Resource(clusterDeploymentGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterDeployments: %w", err)
+	}
+
+	keys := make([]SSHKeyInfo, 0, len(list.Items))
+	for i := range list.Items {
+		key, err := m.read(ctx, &list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key.SSHKeyInfo)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Cluster < keys[j].Cluster })
+	return keys, nil
+}
+
+// Get reads the SSH key secret of the ClusterDeployment in namespace=clusterName
+func (m *sshKeyManager) Get(ctx context.Context, clusterName string) (*SSHKey, error) {
+	ctx, cancel, err := m.options.Start(ctx, "get SSH key", "cluster", clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	cd, err := m.clusterDeployment(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := m.read(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	if key.Problem != "" {
+		return nil, fmt.Errorf("SSH key of %s: %s", clusterName, key.Problem)
+	}
+	return key, nil
+}
+
+// Create creates the SSH key secret of a cluster, named by its ClusterDeployment or
+// <cluster>-ssh-private-key. Clusters whose secret exists keep it; use Update to replace it.
+func (m *sshKeyManager) Create(ctx context.Context, clusterName string, pair sshkey.KeyPair) (*SSHKeyInfo, error) {
+	ctx, cancel, err := m.options.Start(ctx, "create SSH key", "cluster", clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	cd, err := m.clusterDeployment(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	secret := &apicorev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: sshKeySecretName(cd), Namespace: clusterName},
+		Type:       apicorev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			sshPrivateKeyKey: pair.PrivateKey,
+			sshPublicKeyKey:  []byte(pair.PublicKey),
+		},
+	}
+	_, err = m.coreClient.Secrets(clusterName).Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("SSH key secret %s/%s already exists", clusterName, secret.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSH key secret %s/%s: %w", clusterName, secret.Name, err)
+	}
+
+	key, err := m.read(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return &key.SSHKeyInfo, nil
+}
+
+// Update replaces the key in the SSH key secret of a cluster and records when it was rotated.
+// Hive only uses the key for installs and deprovisions; the nodes are not changed.
+func (m *sshKeyManager) Update(ctx context.Context, clusterName string, pair sshkey.KeyPair) (*SSHKeyInfo, error) {
+	ctx, cancel, err := m.options.Start(ctx, "update SSH key", "cluster", clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	cd, err := m.clusterDeployment(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	name := sshKeySecretName(cd)
+	secrets := m.coreClient.Secrets(clusterName)
+	secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSH key secret %s/%s: %w", clusterName, name, err)
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[sshPrivateKeyKey] = pair.PrivateKey
+	secret.Data[sshPublicKeyKey] = []byte(pair.PublicKey)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[SSHKeyRotatedAnnotation] = m.now().UTC().Format(time.RFC3339)
+	if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to update SSH key secret %s/%s: %w", clusterName, name, err)
+	}
+
+	key, err := m.read(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return &key.SSHKeyInfo, nil
+}
+
+// clusterDeployment gets the ClusterDeployment of a cluster
+func (m *sshKeyManager) clusterDeployment(ctx context.Context, clusterName string) (*unstructured.Unstructured, error) {
+	var cd *unstructured.Unstructured
+	err := m.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		cd, err = m.dynamicClient.Resource(clusterDeploymentGVR).Namespace(clusterName).Get(ctx, clusterName, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterDeployment %s: %w (cluster not found or not managed by Hive)", clusterName, err)
+	}
+	return cd, nil
+}
+
+// read reads the SSH key secret of a ClusterDeployment, reporting a missing secret or an
+// unreadable key as the Problem of the key
+func (m *sshKeyManager) read(ctx context.Context, cd *unstructured.Unstructured) (*SSHKey, error) {
+	key := &SSHKey{SSHKeyInfo: SSHKeyInfo{
+		Cluster: cd.GetName(),
+		Secret:  sshKeySecretName(cd),
+		Bastion: cd.GetAnnotations()[SSHBastionAnnotation],
+	}}
+
+	var secret *apicorev1.Secret
+	err := m.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		secret, err = m.coreClient.Secrets(cd.GetNamespace()).Get(ctx, key.Secret, metav1.GetOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		key.Problem = "secret " + key.Secret + " not found"
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSH key secret %s/%s: %w", cd.GetNamespace(), key.Secret, err)
+	}
+
+	key.UpdatedAt = secret.CreationTimestamp.Time
+	if rotated, err := time.Parse(time.RFC3339, secret.Annotations[SSHKeyRotatedAnnotation]); err == nil {
+		key.UpdatedAt = rotated
+	}
+	key.PrivateKey = secret.Data[sshPrivateKeyKey]
+	if len(key.PrivateKey) == 0 {
+		key.Problem = "secret " + key.Secret + " has no " + sshPrivateKeyKey
+		return key, nil
+	}
+	publicKey, err := sshkey.PublicKey(key.PrivateKey)
+	if err != nil {
+		key.Problem = err.Error()
+		return key, nil
+	}
+	key.PublicKey = publicKey
+	key.Fingerprint, _ = sshkey.Fingerprint(publicKey)
+	return key, nil
+}
+
+// sshKeySecretName returns the SSH key secret a ClusterDeployment references, or the name
+// labrat gives it when provisioning
+func sshKeySecretName(cd *unstructured.Unstructured) string {
+	if name, _, _ := unstructured.NestedString(cd.Object, "spec", "provisioning", "sshPrivateKeySecretRef", "name"); name != "" {
+		return name
+	}
+	return cd.GetName() + "-ssh-private-key"
+}
+
+// AuthorizedKeyManager changes the SSH keys authorized for the core user on the nodes of a
+// spoke cluster, through the MachineConfigs that set them. The machine config operator rolls
+// the change out to the nodes without rebooting them.
+type AuthorizedKeyManager interface {
+	// Authorize adds publicKey to the authorized keys and returns the MachineConfigs changed
+	Authorize(ctx context.Context, publicKey string) ([]string, error)
+	// Revoke removes publicKey from the authorized keys and returns the MachineConfigs changed
+	Revoke(ctx context.Context, publicKey string) ([]string, error)
+}
+
+type authorizedKeyManager struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewAuthorizedKeyManager creates a new AuthorizedKeyManager using a client connected to the
+// spoke cluster
+func NewAuthorizedKeyManager(dynamicClient dynamic.Interface, options ...kube.Option) AuthorizedKeyManager {
+	return &authorizedKeyManager{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Authorize adds publicKey to every MachineConfig that authorizes SSH keys for the core user,
+// e.g. 99-master-ssh and 99-worker-ssh created by the installer
+func (a *authorizedKeyManager) Authorize(ctx context.Context, publicKey string) ([]string, error) {
+	ctx, cancel, err := a.options.Start(ctx, "authorize SSH key")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	return a.update(ctx, func(keys []string) []string {
+		for _, key := range keys {
+			if sshkey.Equal(key, publicKey) {
+				return keys
+			}
+		}
+		return append(keys, publicKey)
+	})
+}
+
+// Revoke removes publicKey from every MachineConfig that authorizes it for the core user
+func (a *authorizedKeyManager) Revoke(ctx context.Context, publicKey string) ([]string, error) {
+	ctx, cancel, err := a.options.Start(ctx, "revoke SSH key")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	return a.update(ctx, func(keys []string) []string {
+		kept := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if !sshkey.Equal(key, publicKey) {
+				kept = append(kept, key)
+			}
+		}
+		return kept
+	})
+}
+
+// update applies change to the authorized keys of the core user in each MachineConfig that
+// sets them, skipping the MachineConfigs rendered by the operator, and updates those changed
+func (a *authorizedKeyManager) update(ctx context.Context, change func(keys []string) []string) ([]string, error) {
+	var list *unstructured.UnstructuredList
+	err := a.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = a.dynamicClient.Resource(machineConfigGVR).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MachineConfigs: %w", err)
+	}
+
+	var found bool
+	var changed []string
+	for i := range list.Items {
+		mc := &list.Items[i]
+		if _, ok := mc.GetAnnotations()[generatedByControllerAnnotation]; ok {
+			continue
+		}
+		users, _, _ := unstructured.NestedSlice(mc.Object, "spec", "config", "passwd", "users")
+		updated := false
+		for j, u := range users {
+			user, ok := u.(map[string]interface{})
+			if !ok || user["name"] != CoreUser {
+				continue
+			}
+			keys, ok, _ := unstructured.NestedStringSlice(user, "sshAuthorizedKeys")
+			if !ok {
+				continue
+			}
+			found = true
+			newKeys := change(keys)
+			if slices.Equal(keys, newKeys) {
+				continue
+			}
+			user["sshAuthorizedKeys"] = toInterfaceSlice(newKeys)
+			users[j] = user
+			updated = true
+		}
+		if !updated {
+			continue
+		}
+
+		if err := unstructured.SetNestedSlice(mc.Object, users, "spec", "config", "passwd", "users"); err != nil {
+			return changed, fmt.Errorf("failed to set the SSH keys of MachineConfig %s: %w", mc.GetName(), err)
+		}
+		if _, err := a.dynamicClient.Resource(machineConfigGVR).Update(ctx, mc, metav1.UpdateOptions{}); err != nil {
+			return changed, fmt.Errorf("failed to update MachineConfig %s: %w", mc.GetName(), err)
+		}
+		changed = append(changed, mc.GetName())
+	}
+	if !found {
+		return nil, fmt.Errorf("no MachineConfig sets the SSH keys of the %s user", CoreUser)
+	}
+	return changed, nil
+}
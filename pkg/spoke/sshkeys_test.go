@@ -0,0 +1,201 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/sshkey"
+)
+
+var _ = Describe("SSHKeyManager", func() {
+	var (
+		ctx         context.Context
+		fakeDynamic *fake.FakeDynamicClient
+		fakeK8s     *k8sFake.Clientset
+		pair        *sshkey.KeyPair
+	)
+
+	newCD := func(name, secretName string, annotations map[string]interface{}) *unstructured.Unstructured {
+		spec := map[string]interface{}{}
+		if secretName != "" {
+			spec["provisioning"] = map[string]interface{}{
+				"sshPrivateKeySecretRef": map[string]interface{}{"name": secretName},
+			}
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterDeployment",
+			"metadata":   map[string]interface{}{"name": name, "namespace": name, "annotations": annotations},
+			"spec":       spec,
+		}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		pair, err = sshkey.Generate("labrat")
+		Expect(err).NotTo(HaveOccurred())
+
+		fakeDynamic = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}: "ClusterDeploymentList",
+		},
+			newCD("partner-a", "partner-a-ssh", map[string]interface{}{spoke.SSHBastionAnnotation: "core@bastion.acme.example"}),
+			newCD("partner-b", "", nil),
+		)
+		fakeK8s = k8sFake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "partner-a-ssh", Namespace: "partner-a"},
+			Data:       map[string][]byte{"ssh-privatekey": pair.PrivateKey},
+		})
+	})
+
+	It("should list the SSH keys of every cluster, with missing keys as problems", func() {
+		keys, err := spoke.NewSSHKeyManager(fakeDynamic, fakeK8s.CoreV1()).List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(HaveLen(2))
+
+		fingerprint, err := sshkey.Fingerprint(pair.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys[0].Cluster).To(Equal("partner-a"))
+		Expect(keys[0].Secret).To(Equal("partner-a-ssh"))
+		Expect(keys[0].Fingerprint).To(Equal(fingerprint))
+		Expect(keys[0].Bastion).To(Equal("core@bastion.acme.example"))
+		Expect(keys[0].Problem).To(BeEmpty())
+
+		Expect(keys[1].Cluster).To(Equal("partner-b"))
+		Expect(keys[1].Secret).To(Equal("partner-b-ssh-private-key"))
+		Expect(keys[1].Problem).To(Equal("secret partner-b-ssh-private-key not found"))
+	})
+
+	It("should get the private key of a cluster", func() {
+		key, err := spoke.NewSSHKeyManager(fakeDynamic, fakeK8s.CoreV1()).Get(ctx, "partner-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.PrivateKey).To(Equal(pair.PrivateKey))
+		Expect(sshkey.Equal(key.PublicKey, pair.PublicKey)).To(BeTrue())
+	})
+
+	It("should fail to get a missing key", func() {
+		_, err := spoke.NewSSHKeyManager(fakeDynamic, fakeK8s.CoreV1()).Get(ctx, "partner-b")
+		Expect(err).To(MatchError("SSH key of partner-b: secret partner-b-ssh-private-key not found"))
+	})
+
+	It("should create the key of a cluster that has none, but not replace one", func() {
+		manager := spoke.NewSSHKeyManager(fakeDynamic, fakeK8s.CoreV1())
+		info, err := manager.Create(ctx, "partner-b", *pair)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Secret).To(Equal("partner-b-ssh-private-key"))
+		Expect(info.Problem).To(BeEmpty())
+
+		secret, err := fakeK8s.CoreV1().Secrets("partner-b").Get(ctx, "partner-b-ssh-private-key", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.Data).To(HaveKeyWithValue("ssh-publickey", []byte(pair.PublicKey)))
+
+		_, err = manager.Create(ctx, "partner-a", *pair)
+		Expect(err).To(MatchError("SSH key secret partner-a/partner-a-ssh already exists"))
+	})
+
+	It("should replace the key of a cluster and record the rotation", func() {
+		rotated, err := sshkey.Generate("rotated")
+		Expect(err).NotTo(HaveOccurred())
+
+		info, err := spoke.NewSSHKeyManager(fakeDynamic, fakeK8s.CoreV1()).Update(ctx, "partner-a", *rotated)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sshkey.Equal(info.PublicKey, rotated.PublicKey)).To(BeTrue())
+
+		secret, err := fakeK8s.CoreV1().Secrets("partner-a").Get(ctx, "partner-a-ssh", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.Data).To(HaveKeyWithValue("ssh-privatekey", rotated.PrivateKey))
+		Expect(secret.Annotations).To(HaveKey(spoke.SSHKeyRotatedAnnotation))
+	})
+})
+
+var _ = Describe("AuthorizedKeyManager", func() {
+	var (
+		ctx         context.Context
+		fakeDynamic *fake.FakeDynamicClient
+		oldKey      *sshkey.KeyPair
+		newKey      *sshkey.KeyPair
+	)
+
+	mcGVR := schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigs"}
+
+	newMC := func(name string, annotations map[string]interface{}, keys ...interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "machineconfiguration.openshift.io/v1",
+			"kind":       "MachineConfig",
+			"metadata":   map[string]interface{}{"name": name, "annotations": annotations},
+			"spec": map[string]interface{}{"config": map[string]interface{}{
+				"passwd": map[string]interface{}{"users": []interface{}{
+					map[string]interface{}{"name": "core", "sshAuthorizedKeys": keys},
+				}},
+			}},
+		}}
+	}
+
+	authorizedKeys := func(name string) []string {
+		mc, err := fakeDynamic.Resource(mcGVR).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		users, _, _ := unstructured.NestedSlice(mc.Object, "spec", "config", "passwd", "users")
+		keys, _, _ := unstructured.NestedStringSlice(users[0].(map[string]interface{}), "sshAuthorizedKeys")
+		return keys
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		oldKey, err = sshkey.Generate("old")
+		Expect(err).NotTo(HaveOccurred())
+		newKey, err = sshkey.Generate("new")
+		Expect(err).NotTo(HaveOccurred())
+
+		fakeDynamic = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			mcGVR: "MachineConfigList",
+		},
+			newMC("99-master-ssh", nil, oldKey.PublicKey),
+			newMC("99-worker-ssh", nil, oldKey.PublicKey, "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQC8 partner"),
+			newMC("rendered-worker-0123", map[string]interface{}{
+				"machineconfiguration.openshift.io/generated-by-controller-version": "4.16.0",
+			}, oldKey.PublicKey),
+		)
+	})
+
+	It("should authorize a key once in every MachineConfig of the core user", func() {
+		manager := spoke.NewAuthorizedKeyManager(fakeDynamic)
+		changed, err := manager.Authorize(ctx, newKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(ConsistOf("99-master-ssh", "99-worker-ssh"))
+		Expect(authorizedKeys("99-master-ssh")).To(Equal([]string{oldKey.PublicKey, newKey.PublicKey}))
+		Expect(authorizedKeys("rendered-worker-0123")).To(Equal([]string{oldKey.PublicKey}))
+
+		changed, err = manager.Authorize(ctx, newKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeEmpty())
+	})
+
+	It("should revoke a key and keep the others", func() {
+		changed, err := spoke.NewAuthorizedKeyManager(fakeDynamic).Revoke(ctx, oldKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(ConsistOf("99-master-ssh", "99-worker-ssh"))
+		Expect(authorizedKeys("99-master-ssh")).To(BeEmpty())
+		Expect(authorizedKeys("99-worker-ssh")).To(Equal([]string{"ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQC8 partner"}))
+	})
+
+	It("should fail when no MachineConfig sets the keys of the core user", func() {
+		fakeDynamic = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			mcGVR: "MachineConfigList",
+		})
+		_, err := spoke.NewAuthorizedKeyManager(fakeDynamic).Authorize(ctx, newKey.PublicKey)
+		Expect(err).To(MatchError("no MachineConfig sets the SSH keys of the core user"))
+	})
+})
@@ -0,0 +1,417 @@
+package spoke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// InstallPhasePending means Hive has not started provisioning the cluster yet
+	InstallPhasePending = "Pending"
+	// InstallPhaseProvisioning means an install attempt is running
+	InstallPhaseProvisioning = "Provisioning"
+	// InstallPhaseFailed means Hive reported the install failed or stopped retrying it
+	InstallPhaseFailed = "Failed"
+	// InstallPhaseInstalled means the cluster is installed
+	InstallPhaseInstalled = "Installed"
+	// InstallPhaseImported means the cluster was imported rather than provisioned by Hive
+	InstallPhaseImported = "Imported"
+
+	// JobStatusPending means the install job of a ClusterProvision has no pod yet
+	JobStatusPending = "Pending"
+	// JobStatusRunning means the installer pod of the job is running
+	JobStatusRunning = "Running"
+	// JobStatusSucceeded means the installer completed
+	JobStatusSucceeded = "Succeeded"
+	// JobStatusFailed means the installer pod failed
+	JobStatusFailed = "Failed"
+
+	// clusterDeploymentNameLabel is set by Hive on the ClusterProvisions and jobs of a ClusterDeployment
+	clusterDeploymentNameLabel = "hive.openshift.io/cluster-deployment-name"
+	// jobTypeLabel is set by Hive on its jobs, with jobTypeProvision for install jobs
+	jobTypeLabel     = "hive.openshift.io/job-type"
+	jobTypeProvision = "provision"
+)
+
+// clusterProvisionGVR identifies the Hive ClusterProvision created for each install attempt
+var clusterProvisionGVR = schema.GroupVersionResource{
+	Group:    "hive.openshift.io",
+	Version:  "v1",
+	Resource: "clusterprovisions",
+}
+
+// installFailedConditions are the ClusterDeployment conditions that mark a failed install
+var installFailedConditions = []string{"ProvisionStopped", "ProvisionFailed", "InstallLaunchError"}
+
+// StatusCondition is a condition of the ManagedCluster or ClusterDeployment of a cluster
+type StatusCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// InstallProgress summarizes the Hive install of a cluster
+type InstallProgress struct {
+	// Phase is one of the InstallPhase constants
+	Phase string `json:"phase"`
+	// Attempt is the number of the latest install attempt, starting at 0
+	Attempt int `json:"attempt"`
+	// Stage is the stage of the ClusterProvision of the latest attempt
+	Stage string `json:"stage,omitempty"`
+	// Restarts is how often Hive restarted the install after a failed attempt
+	Restarts int `json:"restarts"`
+	// StartedAt is when the first install attempt started
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// InstalledAt is when the install completed
+	InstalledAt *time.Time `json:"installedAt,omitempty"`
+	// Message explains a failed install
+	Message string `json:"message,omitempty"`
+}
+
+// ProvisionAttempt is a ClusterProvision of a cluster with the status of its install job
+type ProvisionAttempt struct {
+	Name    string `json:"name"`
+	Attempt int    `json:"attempt"`
+	// Stage is initializing, provisioning, complete, or failed
+	Stage string `json:"stage"`
+	// Job is one of the JobStatus constants, empty once Hive cleaned up the job
+	Job       string    `json:"job,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// StatusEvent is an event recorded in the namespace of a cluster on the hub
+type StatusEvent struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+	// Object is the kind and name of the object the event is about, e.g. Job/my-cluster-0-provision
+	Object   string    `json:"object"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// ClusterStatus gathers everything the hub knows about a spoke cluster
+type ClusterStatus struct {
+	Name string `json:"name"`
+	// Managed reports whether a ManagedCluster exists for the cluster, i.e. ACM manages it
+	Managed bool `json:"managed"`
+	// Provisioned reports whether a ClusterDeployment exists for the cluster
+	Provisioned bool   `json:"provisioned"`
+	Platform    string `json:"platform,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Version     string `json:"version,omitempty"`
+	PowerState  string `json:"powerState,omitempty"`
+	APIURL      string `json:"apiURL,omitempty"`
+	ConsoleURL  string `json:"consoleURL,omitempty"`
+
+	Install                     InstallProgress    `json:"install"`
+	ManagedClusterConditions    []StatusCondition  `json:"managedClusterConditions"`
+	ClusterDeploymentConditions []StatusCondition  `json:"clusterDeploymentConditions"`
+	Provisions                  []ProvisionAttempt `json:"provisions"`
+	AddOns                      []hub.AddOnInfo    `json:"addOns"`
+	// Events are the most recent events of the cluster namespace, newest first
+	Events []StatusEvent `json:"events"`
+}
+
+// ErrClusterNotFound is returned by StatusReader.Read for clusters with neither a ManagedCluster
+// nor a ClusterDeployment
+var ErrClusterNotFound = errors.New("not found")
+
+// StatusReader reads the status of spoke clusters from the hub
+type StatusReader interface {
+	// Read gathers the status of a cluster with at most maxEvents events; maxEvents <= 0
+	// returns all events
+	Read(ctx context.Context, clusterName string, maxEvents int) (*ClusterStatus, error)
+}
+
+type statusReader struct {
+	dynamicClient dynamic.Interface
+	coreClient    kubernetes.Interface
+	addOns        hub.ManagedClusterAddOnClient
+	options       kube.Options
+}
+
+// NewStatusReader creates a new StatusReader using clients connected to the hub
+func NewStatusReader(dynamicClient dynamic.Interface, coreClient kubernetes.Interface, options ...kube.Option) StatusReader {
+	return &statusReader{
+		dynamicClient: dynamicClient,
+		coreClient:    coreClient,
+		addOns:        hub.NewManagedClusterAddOnClient(dynamicClient, options...),
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Read gathers the ManagedCluster, ClusterDeployment, ClusterProvisions, install jobs, add-ons,
+// and events of a cluster. Either the ManagedCluster or the ClusterDeployment may be missing,
+// for imported clusters and clusters that are still being created.
+func (r *statusReader) Read(ctx context.Context, clusterName string, maxEvents int) (*ClusterStatus, error) {
+	ctx, cancel, err := r.options.Start(ctx, "read cluster status", "cluster", clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	status := &ClusterStatus{
+		Name:                        clusterName,
+		ManagedClusterConditions:    []StatusCondition{},
+		ClusterDeploymentConditions: []StatusCondition{},
+		Provisions:                  []ProvisionAttempt{},
+		AddOns:                      []hub.AddOnInfo{},
+		Events:                      []StatusEvent{},
+	}
+
+	mc, err := r.get(ctx, provisionGVRs["ManagedCluster"], "", clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ManagedCluster %s: %w", clusterName, err)
+	}
+	cd, err := r.get(ctx, clusterDeploymentGVR, clusterName, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterDeployment %s: %w", clusterName, err)
+	}
+	if mc == nil && cd == nil {
+		return nil, fmt.Errorf("cluster %s %w: it has neither a ManagedCluster nor a ClusterDeployment", clusterName, ErrClusterNotFound)
+	}
+
+	if mc != nil {
+		status.Managed = true
+		status.ManagedClusterConditions = parseConditions(mc.Object)
+		status.AddOns, err = r.addOns.List(ctx, clusterName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cd == nil {
+		status.Install.Phase = InstallPhaseImported
+	} else {
+		status.Provisioned = true
+		status.ClusterDeploymentConditions = parseConditions(cd.Object)
+		cdLabels := cd.GetLabels()
+		status.Platform = cdLabels["hive.openshift.io/cluster-platform"]
+		status.Region = cdLabels["hive.openshift.io/cluster-region"]
+		status.Version, _, _ = unstructured.NestedString(cd.Object, "status", "installVersion")
+		status.APIURL, _, _ = unstructured.NestedString(cd.Object, "status", "apiURL")
+		status.ConsoleURL, _, _ = unstructured.NestedString(cd.Object, "status", "webConsoleURL")
+		status.PowerState, _, _ = unstructured.NestedString(cd.Object, "status", "powerState")
+		if status.PowerState == "" {
+			status.PowerState, _, _ = unstructured.NestedString(cd.Object, "spec", "powerState")
+		}
+
+		if status.Provisions, err = r.provisions(ctx, clusterName); err != nil {
+			return nil, err
+		}
+		status.Install = installProgress(cd, status.ClusterDeploymentConditions, status.Provisions)
+	}
+
+	if status.Events, err = r.events(ctx, clusterName, maxEvents); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// get returns the object of gvr, or nil if it does not exist
+func (r *statusReader) get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	var obj *unstructured.Unstructured
+	err := r.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		obj, err = r.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return obj, err
+}
+
+// provisions lists the ClusterProvisions of a cluster by attempt, with the status of their
+// install jobs
+func (r *statusReader) provisions(ctx context.Context, clusterName string) ([]ProvisionAttempt, error) {
+	selector := labels.SelectorFromSet(labels.Set{clusterDeploymentNameLabel: clusterName})
+	var list *unstructured.UnstructuredList
+	err := r.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = r.dynamicClient.Resource(clusterProvisionGVR).Namespace(clusterName).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterProvisions of %s: %w", clusterName, err)
+	}
+
+	jobSelector := labels.SelectorFromSet(labels.Set{clusterDeploymentNameLabel: clusterName, jobTypeLabel: jobTypeProvision})
+	var jobs *batchv1.JobList
+	err = r.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		jobs, err = r.coreClient.BatchV1().Jobs(clusterName).List(ctx, metav1.ListOptions{LabelSelector: jobSelector.String()})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list install jobs of %s: %w", clusterName, err)
+	}
+	jobStatuses := make(map[string]string, len(jobs.Items))
+	for i := range jobs.Items {
+		jobStatuses[jobs.Items[i].Name] = jobStatus(&jobs.Items[i])
+	}
+
+	attempts := make([]ProvisionAttempt, 0, len(list.Items))
+	for _, item := range list.Items {
+		attempt, _, _ := unstructured.NestedInt64(item.Object, "spec", "attempt")
+		stage, _, _ := unstructured.NestedString(item.Object, "spec", "stage")
+		attempts = append(attempts, ProvisionAttempt{
+			Name:    item.GetName(),
+			Attempt: int(attempt),
+			Stage:   stage,
+			// Hive names the install job of a ClusterProvision after it
+			Job:       jobStatuses[item.GetName()+"-provision"],
+			CreatedAt: item.GetCreationTimestamp().Time,
+		})
+	}
+	sort.Slice(attempts, func(i, j int) bool { return attempts[i].Attempt < attempts[j].Attempt })
+	return attempts, nil
+}
+
+// events returns the most recent events of the cluster namespace, newest first
+func (r *statusReader) events(ctx context.Context, clusterName string, maxEvents int) ([]StatusEvent, error) {
+	var list *corev1.EventList
+	err := r.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		list, err = r.coreClient.CoreV1().Events(clusterName).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events of %s: %w", clusterName, err)
+	}
+
+	events := make([]StatusEvent, 0, len(list.Items))
+	for i := range list.Items {
+		events = append(events, toStatusEvent(&list.Items[i]))
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeen.After(events[j].LastSeen) })
+	if maxEvents > 0 && len(events) > maxEvents {
+		events = events[:maxEvents]
+	}
+	return events, nil
+}
+
+// toStatusEvent converts an event to the fields labrat shows
+func toStatusEvent(event *corev1.Event) StatusEvent {
+	return StatusEvent{
+		Type:     event.Type,
+		Reason:   event.Reason,
+		Object:   event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+		Message:  event.Message,
+		Count:    event.Count,
+		LastSeen: eventLastSeen(event),
+	}
+}
+
+// eventLastSeen returns when an event last occurred, falling back to the fields set by
+// older and newer event recorders
+func eventLastSeen(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// jobStatus derives the status of an install job from its pod counts
+func jobStatus(job *batchv1.Job) string {
+	switch {
+	case job.Status.Succeeded > 0:
+		return JobStatusSucceeded
+	case job.Status.Active > 0:
+		return JobStatusRunning
+	case job.Status.Failed > 0:
+		return JobStatusFailed
+	default:
+		return JobStatusPending
+	}
+}
+
+// installProgress derives the install phase of a ClusterDeployment from its spec, status,
+// conditions, and ClusterProvisions
+func installProgress(cd *unstructured.Unstructured, conditions []StatusCondition, provisions []ProvisionAttempt) InstallProgress {
+	progress := InstallProgress{Phase: InstallPhasePending}
+	restarts, _, _ := unstructured.NestedInt64(cd.Object, "status", "installRestarts")
+	progress.Restarts = int(restarts)
+	progress.StartedAt = nestedTime(cd.Object, "status", "installStartedTimestamp")
+	progress.InstalledAt = nestedTime(cd.Object, "status", "installedTimestamp")
+	if len(provisions) > 0 {
+		latest := provisions[len(provisions)-1]
+		progress.Attempt = latest.Attempt
+		progress.Stage = latest.Stage
+		progress.Phase = InstallPhaseProvisioning
+	}
+
+	if installed, _, _ := unstructured.NestedBool(cd.Object, "spec", "installed"); installed {
+		progress.Phase = InstallPhaseInstalled
+		return progress
+	}
+	for _, failed := range installFailedConditions {
+		for _, condition := range conditions {
+			if condition.Type == failed && condition.Status == string(metav1.ConditionTrue) {
+				progress.Phase = InstallPhaseFailed
+				progress.Message = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+				return progress
+			}
+		}
+	}
+	return progress
+}
+
+// parseConditions returns the status.conditions of obj
+func parseConditions(obj map[string]interface{}) []StatusCondition {
+	raw, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	conditions := make([]StatusCondition, 0, len(raw))
+	for _, c := range raw {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		parsed := StatusCondition{}
+		parsed.Type, _, _ = unstructured.NestedString(condition, "type")
+		parsed.Status, _, _ = unstructured.NestedString(condition, "status")
+		parsed.Reason, _, _ = unstructured.NestedString(condition, "reason")
+		parsed.Message, _, _ = unstructured.NestedString(condition, "message")
+		if transition := nestedTime(condition, "lastTransitionTime"); transition != nil {
+			parsed.LastTransitionTime = *transition
+		}
+		conditions = append(conditions, parsed)
+	}
+	return conditions
+}
+
+// nestedTime parses the RFC 3339 timestamp at fields of obj, or returns nil
+func nestedTime(obj map[string]interface{}, fields ...string) *time.Time {
+	value, _, _ := unstructured.NestedString(obj, fields...)
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
@@ -0,0 +1,207 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("StatusReader", func() {
+	var (
+		ctx       context.Context
+		now       time.Time
+		newReader func(dynamicObjects []runtime.Object, coreObjects ...runtime.Object) spoke.StatusReader
+	)
+
+	newMC := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cluster.open-cluster-management.io/v1",
+			"kind":       "ManagedCluster",
+			"metadata":   map[string]interface{}{"name": "test-cluster"},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type": "ManagedClusterConditionAvailable", "status": "True", "reason": "ManagedClusterAvailable",
+						"message": "Managed cluster is available", "lastTransitionTime": "2026-01-02T03:04:05Z",
+					},
+				},
+			},
+		}}
+	}
+	newCD := func(spec, status map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterDeployment",
+			"metadata": map[string]interface{}{
+				"name": "test-cluster", "namespace": "test-cluster",
+				"labels": map[string]interface{}{
+					"hive.openshift.io/cluster-platform": "aws",
+					"hive.openshift.io/cluster-region":   "us-east-2",
+				},
+			},
+			"spec":   spec,
+			"status": status,
+		}}
+	}
+	newProvision := func(attempt int64, stage string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterProvision",
+			"metadata": map[string]interface{}{
+				"name":      fmt.Sprintf("test-cluster-%d-abcde", attempt),
+				"namespace": "test-cluster",
+				"labels":    map[string]interface{}{"hive.openshift.io/cluster-deployment-name": "test-cluster"},
+			},
+			"spec": map[string]interface{}{"attempt": attempt, "stage": stage},
+		}}
+	}
+	newJob := func(name string, status batchv1.JobStatus) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-cluster", Labels: map[string]string{
+				"hive.openshift.io/cluster-deployment-name": "test-cluster",
+				"hive.openshift.io/job-type":                "provision",
+			}},
+			Status: status,
+		}
+	}
+	newEvent := func(name, reason string, lastSeen time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "test-cluster"},
+			InvolvedObject: corev1.ObjectReference{Kind: "ClusterDeployment", Name: "test-cluster"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         reason,
+			Count:          1,
+			LastTimestamp:  metav1.NewTime(lastSeen),
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Now().Truncate(time.Second)
+		newReader = func(dynamicObjects []runtime.Object, coreObjects ...runtime.Object) spoke.StatusReader {
+			fakeDynamic := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				{Group: "hive.openshift.io", Version: "v1", Resource: "clusterprovisions"}:                         "ClusterProvisionList",
+				{Group: "addon.open-cluster-management.io", Version: "v1alpha1", Resource: "managedclusteraddons"}: "ManagedClusterAddOnList",
+			}, dynamicObjects...)
+			return spoke.NewStatusReader(fakeDynamic, k8sFake.NewSimpleClientset(coreObjects...))
+		}
+	})
+
+	It("should report an installed cluster", func() {
+		cd := newCD(map[string]interface{}{"installed": true, "powerState": "Running"}, map[string]interface{}{
+			"installVersion":     "4.16.12",
+			"apiURL":             "https://api.test-cluster.example.com:6443",
+			"powerState":         "Running",
+			"installedTimestamp": "2026-01-02T03:04:05Z",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "ProvisionFailed", "status": "False", "reason": "ProvisionSucceeded"},
+			},
+		})
+		addon := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "addon.open-cluster-management.io/v1alpha1",
+			"kind":       "ManagedClusterAddOn",
+			"metadata":   map[string]interface{}{"name": "search-collector", "namespace": "test-cluster"},
+		}}
+		reader := newReader([]runtime.Object{newMC(), cd, newProvision(0, "complete"), addon},
+			newJob("test-cluster-0-abcde-provision", batchv1.JobStatus{Succeeded: 1}))
+
+		status, err := reader.Read(ctx, "test-cluster", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Managed).To(BeTrue())
+		Expect(status.Provisioned).To(BeTrue())
+		Expect(status.Platform).To(Equal("aws"))
+		Expect(status.Region).To(Equal("us-east-2"))
+		Expect(status.Version).To(Equal("4.16.12"))
+		Expect(status.PowerState).To(Equal("Running"))
+		Expect(status.Install.Phase).To(Equal(spoke.InstallPhaseInstalled))
+		Expect(status.Install.InstalledAt).NotTo(BeNil())
+		Expect(status.ManagedClusterConditions).To(HaveLen(1))
+		Expect(status.ManagedClusterConditions[0].Type).To(Equal("ManagedClusterConditionAvailable"))
+		Expect(status.ManagedClusterConditions[0].LastTransitionTime).To(Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+		Expect(status.ClusterDeploymentConditions).To(HaveLen(1))
+		Expect(status.Provisions).To(HaveLen(1))
+		Expect(status.Provisions[0].Job).To(Equal(spoke.JobStatusSucceeded))
+		Expect(status.AddOns).To(HaveLen(1))
+		Expect(status.AddOns[0].Name).To(Equal("search-collector"))
+	})
+
+	It("should report the running attempt of a cluster being installed", func() {
+		cd := newCD(map[string]interface{}{"installed": false}, map[string]interface{}{
+			"installRestarts":         int64(1),
+			"installStartedTimestamp": "2026-01-02T03:04:05Z",
+		})
+		reader := newReader([]runtime.Object{cd, newProvision(1, "provisioning"), newProvision(0, "failed")},
+			newJob("test-cluster-0-abcde-provision", batchv1.JobStatus{Failed: 1}),
+			newJob("test-cluster-1-abcde-provision", batchv1.JobStatus{Active: 1}))
+
+		status, err := reader.Read(ctx, "test-cluster", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Managed).To(BeFalse())
+		Expect(status.Install.Phase).To(Equal(spoke.InstallPhaseProvisioning))
+		Expect(status.Install.Attempt).To(Equal(1))
+		Expect(status.Install.Stage).To(Equal("provisioning"))
+		Expect(status.Install.Restarts).To(Equal(1))
+		Expect(status.Install.StartedAt).NotTo(BeNil())
+		Expect(status.Provisions).To(HaveLen(2))
+		Expect(status.Provisions[0].Job).To(Equal(spoke.JobStatusFailed))
+		Expect(status.Provisions[1].Job).To(Equal(spoke.JobStatusRunning))
+	})
+
+	It("should report a failed install with the message of the condition", func() {
+		cd := newCD(map[string]interface{}{"installed": false}, map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "ProvisionFailed", "status": "True", "reason": "InvalidCredentials", "message": "credentials are invalid"},
+			},
+		})
+		reader := newReader([]runtime.Object{cd})
+
+		status, err := reader.Read(ctx, "test-cluster", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Install.Phase).To(Equal(spoke.InstallPhaseFailed))
+		Expect(status.Install.Message).To(Equal("InvalidCredentials: credentials are invalid"))
+	})
+
+	It("should report imported clusters without a ClusterDeployment", func() {
+		status, err := newReader([]runtime.Object{newMC()}).Read(ctx, "test-cluster", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Provisioned).To(BeFalse())
+		Expect(status.Install.Phase).To(Equal(spoke.InstallPhaseImported))
+		Expect(status.AddOns).To(Equal([]hub.AddOnInfo{}))
+	})
+
+	It("should return the most recent events first", func() {
+		reader := newReader([]runtime.Object{newMC()},
+			newEvent("old", "Created", now.Add(-time.Hour)),
+			newEvent("new", "Hibernating", now),
+			newEvent("middle", "Provisioned", now.Add(-time.Minute)),
+		)
+
+		status, err := reader.Read(ctx, "test-cluster", 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Events).To(HaveLen(2))
+		Expect(status.Events[0].Reason).To(Equal("Hibernating"))
+		Expect(status.Events[0].Object).To(Equal("ClusterDeployment/test-cluster"))
+		Expect(status.Events[1].Reason).To(Equal("Provisioned"))
+	})
+
+	It("should return an error when the cluster does not exist", func() {
+		_, err := newReader(nil).Read(ctx, "test-cluster", 0)
+		Expect(err).To(MatchError(ContainSubstring("cluster test-cluster not found")))
+		Expect(err).To(MatchError(spoke.ErrClusterNotFound))
+	})
+})
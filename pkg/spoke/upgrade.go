@@ -0,0 +1,347 @@
+package spoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/hub"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+)
+
+const (
+	// DefaultUpgradeTimeout bounds how long WaitForUpgrade waits; updates of a minor version
+	// take one to two hours
+	DefaultUpgradeTimeout = 3 * time.Hour
+	// clusterVersionName is the name of the cluster-scoped ClusterVersion of OpenShift clusters
+	clusterVersionName = "version"
+	// historyCompleted is the state of a ClusterVersion history entry that was fully rolled out
+	historyCompleted = "Completed"
+)
+
+// clusterVersionGVR identifies the OpenShift ClusterVersion resource
+var clusterVersionGVR = schema.GroupVersionResource{
+	Group:    "config.openshift.io",
+	Version:  "v1",
+	Resource: "clusterversions",
+}
+
+// VersionStatus is the installed OpenShift version of a spoke and the updates it is offered
+type VersionStatus struct {
+	// Version is the last version that was completely rolled out
+	Version string `json:"version"`
+	// Updating is the version an update is rolling out to, empty without an update in progress
+	Updating string `json:"updating,omitempty"`
+	// Channel is the update channel, e.g. stable-4.16
+	Channel string `json:"channel,omitempty"`
+	// AvailableUpdates are the versions the update graph of the channel offers
+	AvailableUpdates []string `json:"availableUpdates,omitempty"`
+	// DesiredUpdate is the version requested by the ClusterCurator of the cluster on the hub
+	DesiredUpdate string `json:"desiredUpdate,omitempty"`
+	// Progress is the message of the Progressing condition while an update is rolling out,
+	// e.g. "Working towards 4.16.2: 512 of 845 done (60% complete)"
+	Progress string `json:"progress,omitempty"`
+	// Failing is the message of the Failing condition, empty while the cluster version
+	// operator reports no failure
+	Failing string `json:"failing,omitempty"`
+}
+
+// LatestUpdate returns the newest of the available updates, empty if there are none
+func (s *VersionStatus) LatestUpdate() string {
+	latest := ""
+	for _, version := range s.AvailableUpdates {
+		if latest == "" || hub.CompareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// Target returns the version the cluster should run: the update requested by its
+// ClusterCurator, otherwise the newer of the latest available update and the minor version
+// of its channel, e.g. 4.16 for stable-4.16. It is empty if none of them is known.
+func (s *VersionStatus) Target() string {
+	if s.DesiredUpdate != "" {
+		return s.DesiredUpdate
+	}
+	target := s.LatestUpdate()
+	if channelVersion := ChannelVersion(s.Channel); channelVersion != "" {
+		if target == "" || hub.CompareVersions(minorOf(target), channelVersion) < 0 {
+			target = channelVersion
+		}
+	}
+	return target
+}
+
+// MinorsBehind returns how many minor versions the installed version is behind Target, 0 if
+// it is not behind or either version is unknown
+func (s *VersionStatus) MinorsBehind() int {
+	major, minor, ok := parseMinor(s.Version)
+	targetMajor, targetMinor, targetOK := parseMinor(s.Target())
+	if !ok || !targetOK || major != targetMajor || targetMinor < minor {
+		return 0
+	}
+	return targetMinor - minor
+}
+
+// ChannelVersion returns the minor version of an update channel, e.g. 4.16 for stable-4.16,
+// or empty if the channel does not name one
+func ChannelVersion(channel string) string {
+	i := strings.LastIndex(channel, "-")
+	if i < 0 {
+		return ""
+	}
+	if _, _, ok := parseMinor(channel[i+1:]); !ok {
+		return ""
+	}
+	return channel[i+1:]
+}
+
+// minorOf truncates a version to its major and minor version, e.g. 4.16.3 to 4.16
+func minorOf(version string) string {
+	if major, minor, ok := parseMinor(version); ok {
+		return fmt.Sprintf("%d.%d", major, minor)
+	}
+	return version
+}
+
+// parseMinor returns the major and minor version of a version such as 4.16 or 4.16.3
+func parseMinor(version string) (int, int, bool) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// VersionInspector reads the OpenShift version of a spoke cluster
+type VersionInspector interface {
+	// Inspect returns the installed version, channel, and available updates of the cluster
+	Inspect(ctx context.Context) (*VersionStatus, error)
+}
+
+type versionInspector struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewVersionInspector creates a new VersionInspector using a client connected to the spoke cluster
+func NewVersionInspector(dynamicClient dynamic.Interface, options ...kube.Option) VersionInspector {
+	return &versionInspector{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Inspect reads the ClusterVersion of the spoke. The installed version is the newest
+// completed entry of its history, as status.desired already changes when an update starts.
+func (v *versionInspector) Inspect(ctx context.Context) (*VersionStatus, error) {
+	ctx, cancel, err := v.options.Start(ctx, "inspect ClusterVersion")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var obj *unstructured.Unstructured
+	err = v.options.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		obj, err = v.dynamicClient.Resource(clusterVersionGVR).Get(ctx, clusterVersionName, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterVersion: %w", err)
+	}
+
+	status := &VersionStatus{}
+	status.Channel, _, _ = unstructured.NestedString(obj.Object, "spec", "channel")
+	desired, _, _ := unstructured.NestedString(obj.Object, "status", "desired", "version")
+
+	history, _, _ := unstructured.NestedSlice(obj.Object, "status", "history")
+	for _, entry := range history {
+		entryMap, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if state, _, _ := unstructured.NestedString(entryMap, "state"); state == historyCompleted {
+			status.Version, _, _ = unstructured.NestedString(entryMap, "version")
+			break
+		}
+	}
+	if status.Version == "" {
+		// The initial install has not completed yet
+		status.Version = desired
+	} else if desired != status.Version {
+		status.Updating = desired
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if !ok || conditionMap["status"] != "True" {
+			continue
+		}
+		message, _, _ := unstructured.NestedString(conditionMap, "message")
+		switch conditionMap["type"] {
+		case "Progressing":
+			if status.Updating != "" {
+				status.Progress = message
+			}
+		case "Failing":
+			status.Failing = message
+		}
+	}
+
+	updates, _, _ := unstructured.NestedSlice(obj.Object, "status", "availableUpdates")
+	for _, update := range updates {
+		if updateMap, ok := update.(map[string]interface{}); ok {
+			if version, _, _ := unstructured.NestedString(updateMap, "version"); version != "" {
+				status.AvailableUpdates = append(status.AvailableUpdates, version)
+			}
+		}
+	}
+	return status, nil
+}
+
+// VersionUpgrader updates spoke clusters through their ClusterVersion, without ACM
+type VersionUpgrader interface {
+	// Upgrade sets the desired update of the cluster to version, switching it to channel
+	// first if it is not empty
+	Upgrade(ctx context.Context, version, channel string) error
+}
+
+type versionUpgrader struct {
+	dynamicClient dynamic.Interface
+	options       kube.Options
+}
+
+// NewVersionUpgrader creates a new VersionUpgrader using a client connected to the spoke cluster
+func NewVersionUpgrader(dynamicClient dynamic.Interface, options ...kube.Option) VersionUpgrader {
+	return &versionUpgrader{
+		dynamicClient: dynamicClient,
+		options:       kube.NewOptions(options...),
+	}
+}
+
+// Upgrade patches spec.desiredUpdate of the ClusterVersion. Without a channel change the
+// version must be one of the available updates, as the cluster version operator would
+// refuse it otherwise; with one, the updates of the new channel are not known yet.
+func (u *versionUpgrader) Upgrade(ctx context.Context, version, channel string) error {
+	ctx, cancel, err := u.options.Start(ctx, "upgrade ClusterVersion", "version", version, "channel", channel)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	spec := map[string]interface{}{
+		"desiredUpdate": map[string]interface{}{"version": version},
+	}
+	if channel != "" {
+		spec["channel"] = channel
+	} else {
+		status, err := (&versionInspector{dynamicClient: u.dynamicClient, options: u.options}).Inspect(ctx)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(status.AvailableUpdates, version) {
+			available := "none"
+			if len(status.AvailableUpdates) > 0 {
+				available = strings.Join(status.AvailableUpdates, ", ")
+			}
+			return fmt.Errorf("%s is not an available update of the cluster in channel %s (available: %s)", version, status.Channel, available)
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ClusterVersion patch: %w", err)
+	}
+	_, err = u.dynamicClient.Resource(clusterVersionGVR).Patch(ctx, clusterVersionName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update ClusterVersion: %w", err)
+	}
+	return nil
+}
+
+// UpgradeWaitOptions controls how long and how often WaitForUpgrade polls an update
+type UpgradeWaitOptions struct {
+	// Timeout bounds how long WaitForUpgrade waits for the update to complete
+	Timeout time.Duration
+	// PollInterval is how often the update is checked
+	PollInterval time.Duration
+	// Failed reports a failure of what drives the update, e.g. the job of a ClusterCurator,
+	// as a message; an empty message means no failure
+	Failed func(ctx context.Context) (string, error)
+}
+
+// WaitForUpgrade polls the ClusterVersion of a spoke until version is completely rolled out,
+// calling progress whenever the progress of the update changes. Failing conditions of the
+// cluster version operator are reported as progress, as it retries them, but a failure
+// reported by opts.Failed ends the wait.
+func WaitForUpgrade(ctx context.Context, inspector VersionInspector, version string, opts UpgradeWaitOptions, progress func(*VersionStatus)) error {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultUpgradeTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 30 * time.Second
+	}
+
+	var last VersionStatus
+	var failure string
+	err := wait.PollUntilContextTimeout(ctx, opts.PollInterval, opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		status, err := inspector.Inspect(ctx)
+		if err != nil {
+			// The API server of the spoke restarts during the update
+			return false, nil
+		}
+		if status.Version != last.Version || status.Updating != last.Updating || status.Progress != last.Progress || status.Failing != last.Failing {
+			last = *status
+			if progress != nil {
+				progress(status)
+			}
+		}
+		if status.Version == version && status.Updating == "" {
+			return true, nil
+		}
+
+		if opts.Failed != nil {
+			failure, err = opts.Failed(ctx)
+			if err != nil {
+				return false, err
+			}
+			if failure != "" {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		running := last.Version
+		if running == "" {
+			running = "unknown"
+		}
+		return fmt.Errorf("update to %s did not complete (running %s): %w", version, running, err)
+	}
+	if failure != "" {
+		return fmt.Errorf("update to %s failed: %s", version, failure)
+	}
+	return nil
+}
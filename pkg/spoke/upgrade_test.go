@@ -0,0 +1,240 @@
+//go:build test
+
+package spoke_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("VersionInspector", func() {
+	clusterVersion := func(channel, desired string, history []interface{}, updates ...string) *unstructured.Unstructured {
+		available := make([]interface{}, 0, len(updates))
+		for _, update := range updates {
+			available = append(available, map[string]interface{}{"version": update, "image": "quay.io/openshift-release-dev/ocp-release:" + update})
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "config.openshift.io/v1",
+			"kind":       "ClusterVersion",
+			"metadata":   map[string]interface{}{"name": "version"},
+			"spec":       map[string]interface{}{"channel": channel},
+			"status": map[string]interface{}{
+				"desired":          map[string]interface{}{"version": desired},
+				"history":          history,
+				"availableUpdates": available,
+			},
+		}}
+	}
+
+	entry := func(version, state string) interface{} {
+		return map[string]interface{}{"version": version, "state": state}
+	}
+
+	inspect := func(cv *unstructured.Unstructured) *spoke.VersionStatus {
+		status, err := spoke.NewVersionInspector(fake.NewSimpleDynamicClient(runtime.NewScheme(), cv)).Inspect(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		return status
+	}
+
+	It("should read the installed version, channel, and available updates", func() {
+		status := inspect(clusterVersion("stable-4.14", "4.14.20",
+			[]interface{}{entry("4.14.20", "Completed"), entry("4.14.8", "Completed")},
+			"4.14.38", "4.14.25"))
+
+		Expect(status).To(Equal(&spoke.VersionStatus{
+			Version:          "4.14.20",
+			Channel:          "stable-4.14",
+			AvailableUpdates: []string{"4.14.38", "4.14.25"},
+		}))
+		Expect(status.LatestUpdate()).To(Equal("4.14.38"))
+	})
+
+	It("should report an update in progress", func() {
+		status := inspect(clusterVersion("stable-4.15", "4.15.3",
+			[]interface{}{entry("4.15.3", "Partial"), entry("4.14.20", "Completed")}))
+
+		Expect(status.Version).To(Equal("4.14.20"))
+		Expect(status.Updating).To(Equal("4.15.3"))
+	})
+
+	It("should use the desired version while the install is in progress", func() {
+		status := inspect(clusterVersion("stable-4.16", "4.16.2", []interface{}{entry("4.16.2", "Partial")}))
+
+		Expect(status.Version).To(Equal("4.16.2"))
+		Expect(status.Updating).To(BeEmpty())
+	})
+
+	It("should report the progress and failures of an update", func() {
+		cv := clusterVersion("stable-4.15", "4.15.3",
+			[]interface{}{entry("4.15.3", "Partial"), entry("4.14.20", "Completed")})
+		cv.Object["status"].(map[string]interface{})["conditions"] = []interface{}{
+			map[string]interface{}{"type": "Progressing", "status": "True", "message": "Working towards 4.15.3: 512 of 845 done (60% complete)"},
+			map[string]interface{}{"type": "Failing", "status": "True", "message": "Cluster operator etcd is degraded"},
+			map[string]interface{}{"type": "RetrievedUpdates", "status": "False", "message": "Unable to retrieve available updates"},
+		}
+
+		status := inspect(cv)
+		Expect(status.Progress).To(Equal("Working towards 4.15.3: 512 of 845 done (60% complete)"))
+		Expect(status.Failing).To(Equal("Cluster operator etcd is degraded"))
+	})
+
+	It("should fail without a ClusterVersion", func() {
+		_, err := spoke.NewVersionInspector(fake.NewSimpleDynamicClient(runtime.NewScheme())).Inspect(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("failed to get ClusterVersion")))
+	})
+})
+
+var _ = Describe("VersionStatus", func() {
+	It("should target the minor version of the channel when no newer update is offered", func() {
+		status := &spoke.VersionStatus{Version: "4.14.20", Channel: "stable-4.16", AvailableUpdates: []string{"4.14.38", "4.15.12"}}
+		Expect(status.Target()).To(Equal("4.16"))
+		Expect(status.MinorsBehind()).To(Equal(2))
+	})
+
+	It("should target the latest update within the channel", func() {
+		status := &spoke.VersionStatus{Version: "4.15.2", Channel: "stable-4.15", AvailableUpdates: []string{"4.15.9", "4.15.30"}}
+		Expect(status.Target()).To(Equal("4.15.30"))
+		Expect(status.MinorsBehind()).To(Equal(0))
+	})
+
+	It("should target the update requested by the ClusterCurator", func() {
+		status := &spoke.VersionStatus{Version: "4.13.4", Channel: "stable-4.16", DesiredUpdate: "4.14.38"}
+		Expect(status.Target()).To(Equal("4.14.38"))
+		Expect(status.MinorsBehind()).To(Equal(1))
+	})
+
+	It("should not be behind without a known target", func() {
+		status := &spoke.VersionStatus{Version: "4.15.2"}
+		Expect(status.Target()).To(BeEmpty())
+		Expect(status.MinorsBehind()).To(Equal(0))
+	})
+
+	It("should parse the version of update channels", func() {
+		Expect(spoke.ChannelVersion("stable-4.16")).To(Equal("4.16"))
+		Expect(spoke.ChannelVersion("eus-4.14")).To(Equal("4.14"))
+		Expect(spoke.ChannelVersion("candidate")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("VersionUpgrader", func() {
+	clusterVersionGVR := schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clusterversions"}
+
+	clusterVersion := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "config.openshift.io/v1",
+			"kind":       "ClusterVersion",
+			"metadata":   map[string]interface{}{"name": "version"},
+			"spec":       map[string]interface{}{"channel": "stable-4.15"},
+			"status": map[string]interface{}{
+				"desired":          map[string]interface{}{"version": "4.15.2"},
+				"history":          []interface{}{map[string]interface{}{"version": "4.15.2", "state": "Completed"}},
+				"availableUpdates": []interface{}{map[string]interface{}{"version": "4.15.9"}},
+			},
+		}}
+	}
+
+	It("should set the desired update to an available update", func() {
+		dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), clusterVersion())
+
+		Expect(spoke.NewVersionUpgrader(dynamicClient).Upgrade(context.Background(), "4.15.9", "")).To(Succeed())
+
+		obj, err := dynamicClient.Resource(clusterVersionGVR).Get(context.Background(), "version", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		version, _, _ := unstructured.NestedString(obj.Object, "spec", "desiredUpdate", "version")
+		Expect(version).To(Equal("4.15.9"))
+	})
+
+	It("should refuse a version that is not an available update", func() {
+		dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), clusterVersion())
+
+		err := spoke.NewVersionUpgrader(dynamicClient).Upgrade(context.Background(), "4.16.3", "")
+		Expect(err).To(MatchError(ContainSubstring("4.16.3 is not an available update of the cluster in channel stable-4.15 (available: 4.15.9)")))
+	})
+
+	It("should switch the channel along with the update", func() {
+		dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), clusterVersion())
+
+		Expect(spoke.NewVersionUpgrader(dynamicClient).Upgrade(context.Background(), "4.16.3", "stable-4.16")).To(Succeed())
+
+		obj, err := dynamicClient.Resource(clusterVersionGVR).Get(context.Background(), "version", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		channel, _, _ := unstructured.NestedString(obj.Object, "spec", "channel")
+		Expect(channel).To(Equal("stable-4.16"))
+	})
+})
+
+// fakeVersionInspector returns a sequence of statuses, repeating the last one
+type fakeVersionInspector struct {
+	statuses []*spoke.VersionStatus
+	errs     []error
+}
+
+func (f *fakeVersionInspector) Inspect(ctx context.Context) (*spoke.VersionStatus, error) {
+	status, err := f.statuses[0], f.errs[0]
+	if len(f.statuses) > 1 {
+		f.statuses, f.errs = f.statuses[1:], f.errs[1:]
+	}
+	return status, err
+}
+
+var _ = Describe("WaitForUpgrade", func() {
+	opts := spoke.UpgradeWaitOptions{Timeout: 5 * time.Second, PollInterval: time.Millisecond}
+
+	It("should wait until the version is rolled out, ignoring unavailable API servers", func() {
+		inspector := &fakeVersionInspector{
+			statuses: []*spoke.VersionStatus{
+				{Version: "4.15.2", Updating: "4.15.9", Progress: "Working towards 4.15.9: 10 of 845 done (1% complete)"},
+				nil,
+				{Version: "4.15.2", Updating: "4.15.9", Progress: "Working towards 4.15.9: 700 of 845 done (82% complete)"},
+				{Version: "4.15.9"},
+			},
+			errs: []error{nil, errors.New("connection refused"), nil, nil},
+		}
+
+		var progress []string
+		err := spoke.WaitForUpgrade(context.Background(), inspector, "4.15.9", opts, func(status *spoke.VersionStatus) {
+			progress = append(progress, status.Progress)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress).To(Equal([]string{
+			"Working towards 4.15.9: 10 of 845 done (1% complete)",
+			"Working towards 4.15.9: 700 of 845 done (82% complete)",
+			"",
+		}))
+	})
+
+	It("should stop at a failure of what drives the update", func() {
+		inspector := &fakeVersionInspector{
+			statuses: []*spoke.VersionStatus{{Version: "4.15.2"}},
+			errs:     []error{nil},
+		}
+		failedOpts := opts
+		failedOpts.Failed = func(context.Context) (string, error) {
+			return "curator-job-x4k2p DesiredCuration: upgrade Failed", nil
+		}
+
+		err := spoke.WaitForUpgrade(context.Background(), inspector, "4.15.9", failedOpts, nil)
+		Expect(err).To(MatchError("update to 4.15.9 failed: curator-job-x4k2p DesiredCuration: upgrade Failed"))
+	})
+
+	It("should time out with the running version", func() {
+		inspector := &fakeVersionInspector{
+			statuses: []*spoke.VersionStatus{{Version: "4.15.2", Updating: "4.15.9"}},
+			errs:     []error{nil},
+		}
+		shortOpts := spoke.UpgradeWaitOptions{Timeout: 20 * time.Millisecond, PollInterval: time.Millisecond}
+
+		err := spoke.WaitForUpgrade(context.Background(), inspector, "4.15.9", shortOpts, nil)
+		Expect(err).To(MatchError(ContainSubstring("update to 4.15.9 did not complete (running 4.15.2)")))
+	})
+})
@@ -0,0 +1,76 @@
+package waiter
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// spinnerFrames are drawn in turn in front of the progress of a wait
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Spinner redraws the current progress of a wait on one terminal line, with the time elapsed
+type Spinner struct {
+	out      io.Writer
+	interval time.Duration
+	started  time.Time
+
+	mu   sync.Mutex
+	text string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSpinner creates a Spinner drawing on out, which should be a terminal
+func NewSpinner(out io.Writer) *Spinner {
+	return &Spinner{out: out, interval: 100 * time.Millisecond}
+}
+
+// Start draws the spinner until Stop is called
+func (s *Spinner) Start() {
+	s.started = time.Now()
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			s.draw(spinnerFrames[frame%len(spinnerFrames)])
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Update replaces the progress shown by the spinner
+func (s *Spinner) Update(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.text = text
+}
+
+// Stop stops the spinner and replaces its line with final, unless final is empty
+func (s *Spinner) Stop(final string) {
+	close(s.stop)
+	<-s.done
+	fmt.Fprint(s.out, "\r\033[K")
+	if final != "" {
+		fmt.Fprintln(s.out, final)
+	}
+}
+
+// draw redraws the spinner line with frame
+func (s *Spinner) draw(frame string) {
+	s.mu.Lock()
+	text := s.text
+	s.mu.Unlock()
+	fmt.Fprintf(s.out, "\r\033[K%s %s [%s]", frame, text, duration.HumanDuration(time.Since(s.started)))
+}
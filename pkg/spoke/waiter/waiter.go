@@ -0,0 +1,174 @@
+// Package waiter waits for Hive to install spoke clusters, reporting the progress of the
+// install and the last lines of the installer log when it fails.
+package waiter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+)
+
+const (
+	// DefaultTimeout bounds how long Wait waits for an install; installs take 40 to 60 minutes
+	DefaultTimeout = 90 * time.Minute
+	// DefaultLogLines is the number of installer log lines returned with a failed install
+	DefaultLogLines = 20
+)
+
+// Options controls how long and how often Wait polls an install
+type Options struct {
+	// Timeout bounds how long Wait waits for the install to complete
+	Timeout time.Duration
+	// PollInterval is how often the install is checked
+	PollInterval time.Duration
+	// LogLines is the number of installer log lines returned with a failed install
+	LogLines int
+}
+
+// Progress is the state of an install, reported whenever it changes
+type Progress struct {
+	// Phase is one of the spoke.InstallPhase constants
+	Phase string
+	// Attempt is the number of the running install attempt, starting at 0
+	Attempt int
+	// Stage is the stage of the ClusterProvision of the attempt
+	Stage string
+	// Job is the status of the install job of the attempt
+	Job string
+}
+
+// String describes the progress in one line
+func (p Progress) String() string {
+	if p.Phase != spoke.InstallPhaseProvisioning {
+		return p.Phase
+	}
+	description := fmt.Sprintf("%s (attempt %d", p.Phase, p.Attempt)
+	if p.Stage != "" {
+		description += ", stage " + p.Stage
+	}
+	if p.Job != "" {
+		description += ", job " + p.Job
+	}
+	return description + ")"
+}
+
+// InstallFailedError is returned by Wait when Hive reports the install failed
+type InstallFailedError struct {
+	Cluster string
+	// Reason is the reason and message of the condition reporting the failure
+	Reason string
+	// Logs are the last lines of the installer log, empty if the log is not available
+	Logs []string
+}
+
+func (e *InstallFailedError) Error() string {
+	return fmt.Sprintf("install of %s failed: %s", e.Cluster, e.Reason)
+}
+
+// Waiter waits for spoke clusters to be installed
+type Waiter interface {
+	// Wait polls the install of a cluster until it completes, calling progress whenever it
+	// changes. A failed install returns an *InstallFailedError.
+	Wait(ctx context.Context, clusterName string, progress func(Progress)) error
+}
+
+type waiter struct {
+	status spoke.StatusReader
+	logs   spoke.ProvisionLogReader
+	opts   Options
+}
+
+// New creates a new Waiter using clients connected to the hub
+func New(dynamicClient dynamic.Interface, coreClient kubernetes.Interface, opts Options, options ...kube.Option) Waiter {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 15 * time.Second
+	}
+	if opts.LogLines <= 0 {
+		opts.LogLines = DefaultLogLines
+	}
+	return &waiter{
+		status: spoke.NewStatusReader(dynamicClient, coreClient, options...),
+		logs:   spoke.NewProvisionLogReader(dynamicClient, coreClient, options...),
+		opts:   opts,
+	}
+}
+
+// Wait polls the ClusterDeployment, ClusterProvisions, and install jobs of a cluster until
+// the cluster is installed or Hive reports the install failed. Hive may retry a failed
+// install, but Wait returns at the first failure so it can be investigated.
+func (w *waiter) Wait(ctx context.Context, clusterName string, progress func(Progress)) error {
+	var last Progress
+	var failure *InstallFailedError
+	pollErr := wait.PollUntilContextTimeout(ctx, w.opts.PollInterval, w.opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		status, err := w.status.Read(ctx, clusterName, 1)
+		if err != nil {
+			return false, err
+		}
+		if !status.Provisioned {
+			return false, fmt.Errorf("cluster %s has no ClusterDeployment to wait for", clusterName)
+		}
+
+		current := Progress{Phase: status.Install.Phase, Attempt: status.Install.Attempt, Stage: status.Install.Stage}
+		if len(status.Provisions) > 0 {
+			current.Job = status.Provisions[len(status.Provisions)-1].Job
+		}
+		if current != last {
+			last = current
+			if progress != nil {
+				progress(last)
+			}
+		}
+
+		switch status.Install.Phase {
+		case spoke.InstallPhaseInstalled:
+			return true, nil
+		case spoke.InstallPhaseFailed:
+			failure = &InstallFailedError{Cluster: clusterName, Reason: status.Install.Message}
+			// The log is best effort: the failure is reported even if it cannot be read
+			failure.Logs, _ = w.installLog(ctx, clusterName)
+			return true, nil
+		}
+		return false, nil
+	})
+	if pollErr != nil {
+		return fmt.Errorf("install of %s did not complete (last phase %s): %w", clusterName, last, pollErr)
+	}
+	if failure != nil {
+		return failure
+	}
+	return nil
+}
+
+// installLog returns the last lines of the installer log of the latest install attempt: the
+// log Hive saves in the ClusterProvision, or the log of the install pod if it is still there
+func (w *waiter) installLog(ctx context.Context, clusterName string) ([]string, error) {
+	saved, err := w.logs.SavedLog(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if saved != "" {
+		return spoke.LastLines(saved, w.opts.LogLines), nil
+	}
+
+	var log bytes.Buffer
+	err = w.logs.Stream(ctx, clusterName, spoke.ProvisionLogOptions{TailLines: w.opts.LogLines}, &log)
+	if errors.Is(err, spoke.ErrNoInstallPod) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return spoke.LastLines(log.String(), w.opts.LogLines), nil
+}
@@ -0,0 +1,144 @@
+//go:build test
+
+package waiter_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke"
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/spoke/waiter"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Waiter", func() {
+	var (
+		ctx   context.Context
+		cdGVR schema.GroupVersionResource
+	)
+
+	newCD := func(installed bool, conditions ...interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterDeployment",
+			"metadata":   map[string]interface{}{"name": "test-cluster", "namespace": "test-cluster"},
+			"spec":       map[string]interface{}{"installed": installed},
+			"status":     map[string]interface{}{"conditions": conditions},
+		}}
+	}
+	newProvision := func(stage, installLog string) *unstructured.Unstructured {
+		spec := map[string]interface{}{"attempt": int64(0), "stage": stage}
+		if installLog != "" {
+			spec["installLog"] = installLog
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "hive.openshift.io/v1",
+			"kind":       "ClusterProvision",
+			"metadata": map[string]interface{}{
+				"name":      "test-cluster-0-abcde",
+				"namespace": "test-cluster",
+				"labels":    map[string]interface{}{"hive.openshift.io/cluster-deployment-name": "test-cluster"},
+			},
+			"spec": spec,
+		}}
+	}
+	failed := map[string]interface{}{"type": "ProvisionFailed", "status": "True", "reason": "InstallFailed", "message": "bootstrap failed"}
+
+	newFakeDynamic := func(objects ...runtime.Object) *fake.FakeDynamicClient {
+		return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			{Group: "hive.openshift.io", Version: "v1", Resource: "clusterprovisions"}:                         "ClusterProvisionList",
+			{Group: "addon.open-cluster-management.io", Version: "v1alpha1", Resource: "managedclusteraddons"}: "ManagedClusterAddOnList",
+		}, objects...)
+	}
+	newWaiter := func(fakeDynamic *fake.FakeDynamicClient, coreObjects ...runtime.Object) waiter.Waiter {
+		return waiter.New(fakeDynamic, k8sFake.NewSimpleClientset(coreObjects...), waiter.Options{
+			Timeout:      time.Second,
+			PollInterval: 10 * time.Millisecond,
+			LogLines:     2,
+		})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cdGVR = schema.GroupVersionResource{Group: "hive.openshift.io", Version: "v1", Resource: "clusterdeployments"}
+	})
+
+	It("should report the progress until the cluster is installed", func() {
+		fakeDynamic := newFakeDynamic(newCD(false), newProvision("provisioning", ""))
+		var phases []string
+		err := newWaiter(fakeDynamic).Wait(ctx, "test-cluster", func(progress waiter.Progress) {
+			phases = append(phases, progress.Phase)
+			if progress.Phase == spoke.InstallPhaseProvisioning {
+				_, err := fakeDynamic.Resource(cdGVR).Namespace("test-cluster").Update(ctx, newCD(true), metav1.UpdateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(phases).To(Equal([]string{spoke.InstallPhaseProvisioning, spoke.InstallPhaseInstalled}))
+	})
+
+	It("should return the end of the install log saved by Hive when the install fails", func() {
+		fakeDynamic := newFakeDynamic(newCD(false, failed), newProvision("failed", "level=info msg=one\nlevel=error msg=two\nlevel=fatal msg=three\n"))
+
+		err := newWaiter(fakeDynamic).Wait(ctx, "test-cluster", nil)
+		var installErr *waiter.InstallFailedError
+		Expect(errors.As(err, &installErr)).To(BeTrue())
+		Expect(installErr.Error()).To(Equal("install of test-cluster failed: InstallFailed: bootstrap failed"))
+		Expect(installErr.Logs).To(Equal([]string{"level=error msg=two", "level=fatal msg=three"}))
+	})
+
+	It("should read the log of the install pod when Hive has not saved it", func() {
+		fakeDynamic := newFakeDynamic(newCD(false, failed), newProvision("failed", ""))
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "test-cluster-0-abcde-provision-xyz", Namespace: "test-cluster",
+			Labels: map[string]string{"job-name": "test-cluster-0-abcde-provision"},
+		}}
+
+		err := newWaiter(fakeDynamic, pod).Wait(ctx, "test-cluster", nil)
+		var installErr *waiter.InstallFailedError
+		Expect(errors.As(err, &installErr)).To(BeTrue())
+		// The fake clientset returns a fixed log for every pod
+		Expect(installErr.Logs).To(Equal([]string{"fake logs"}))
+	})
+
+	It("should time out while the install is still running", func() {
+		fakeDynamic := newFakeDynamic(newCD(false), newProvision("provisioning", ""))
+
+		err := newWaiter(fakeDynamic).Wait(ctx, "test-cluster", nil)
+		Expect(err).To(MatchError(ContainSubstring("install of test-cluster did not complete (last phase Provisioning (attempt 0, stage provisioning))")))
+	})
+
+	It("should fail for clusters without a ClusterDeployment", func() {
+		mc := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cluster.open-cluster-management.io/v1",
+			"kind":       "ManagedCluster",
+			"metadata":   map[string]interface{}{"name": "test-cluster"},
+		}}
+
+		err := newWaiter(newFakeDynamic(mc)).Wait(ctx, "test-cluster", nil)
+		Expect(err).To(MatchError(ContainSubstring("cluster test-cluster has no ClusterDeployment to wait for")))
+	})
+})
+
+var _ = Describe("Spinner", func() {
+	It("should replace its line with the final message", func() {
+		var out bytes.Buffer
+		spinner := waiter.NewSpinner(&out)
+		spinner.Update("Provisioning")
+		spinner.Start()
+		spinner.Stop("✓ Installed")
+
+		Expect(out.String()).To(ContainSubstring("Provisioning"))
+		Expect(out.String()).To(HaveSuffix("\r\033[K✓ Installed\n"))
+	})
+})
@@ -0,0 +1,263 @@
+// Package sshkey generates and inspects the SSH keys of cluster nodes in the OpenSSH formats,
+// e.g. the ssh-privatekey of the Hive secret a cluster was installed with and the public keys
+// authorized on its nodes
+package sshkey
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// TypeED25519 is the type of the keys Generate creates
+	TypeED25519 = "ssh-ed25519"
+	// TypeRSA is the type of RSA keys
+	TypeRSA = "ssh-rsa"
+
+	// openSSHMagic starts the OpenSSH private key format
+	openSSHMagic = "openssh-key-v1\x00"
+)
+
+// KeyPair is an SSH key pair
+type KeyPair struct {
+	// PrivateKey is the private key in the OpenSSH PEM format
+	PrivateKey []byte
+	// PublicKey is the public key in the authorized_keys format, e.g. "ssh-ed25519 AAAA... comment"
+	PublicKey string
+}
+
+// Generate creates an ed25519 key pair, with comment added to the public key
+func Generate(comment string) (*KeyPair, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SSH key: %w", err)
+	}
+	publicBlob := ed25519Blob(public)
+
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate SSH key: %w", err)
+	}
+	var secret bytes.Buffer
+	secret.Write(check[:])
+	secret.Write(check[:])
+	writeString(&secret, []byte(TypeED25519))
+	writeString(&secret, public)
+	writeString(&secret, private)
+	writeString(&secret, []byte(comment))
+	for i := byte(1); secret.Len()%8 != 0; i++ {
+		secret.WriteByte(i)
+	}
+
+	var body bytes.Buffer
+	body.WriteString(openSSHMagic)
+	writeString(&body, []byte("none"))
+	writeString(&body, []byte("none"))
+	writeString(&body, nil)
+	_ = binary.Write(&body, binary.BigEndian, uint32(1))
+	writeString(&body, publicBlob)
+	writeString(&body, secret.Bytes())
+
+	return &KeyPair{
+		PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: body.Bytes()}),
+		PublicKey:  strings.TrimSpace(TypeED25519 + " " + base64.StdEncoding.EncodeToString(publicBlob) + " " + comment),
+	}, nil
+}
+
+// PublicKey returns the public key of a PEM private key in the authorized_keys format. Keys
+// in the OpenSSH format, which may be encrypted, and unencrypted RSA, ECDSA, and ed25519 keys
+// in the PKCS #1, SEC 1, and PKCS #8 formats are supported.
+func PublicKey(privateKey []byte) (string, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return "", errors.New("SSH private key is not PEM encoded")
+	}
+
+	var blob []byte
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		var err error
+		if blob, err = openSSHPublicBlob(block.Bytes); err != nil {
+			return "", err
+		}
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		blob = rsaBlob(&key.PublicKey)
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse ECDSA private key: %w", err)
+		}
+		if blob, err = ecdsaBlob(&key.PublicKey); err != nil {
+			return "", err
+		}
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse private key: %w", err)
+		}
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			blob = rsaBlob(&key.PublicKey)
+		case *ecdsa.PrivateKey:
+			if blob, err = ecdsaBlob(&key.PublicKey); err != nil {
+				return "", err
+			}
+		case ed25519.PrivateKey:
+			blob = ed25519Blob(key.Public().(ed25519.PublicKey))
+		default:
+			return "", fmt.Errorf("unsupported private key type %T", key)
+		}
+	default:
+		return "", fmt.Errorf("unsupported SSH private key format %q", block.Type)
+	}
+
+	keyType, _, err := readString(blob)
+	if err != nil {
+		return "", fmt.Errorf("invalid SSH public key: %w", err)
+	}
+	return string(keyType) + " " + base64.StdEncoding.EncodeToString(blob), nil
+}
+
+// Fingerprint returns the SHA256 fingerprint of a public key in the authorized_keys format, as
+// printed by ssh-keygen -l, e.g. SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s
+func Fingerprint(publicKey string) (string, error) {
+	blob, err := publicBlob(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// Equal reports whether two public keys in the authorized_keys format are the same key,
+// ignoring their comments and options
+func Equal(a, b string) bool {
+	blobA, errA := publicBlob(a)
+	blobB, errB := publicBlob(b)
+	return errA == nil && errB == nil && bytes.Equal(blobA, blobB)
+}
+
+// publicBlob decodes the key of an authorized_keys line: the base64 field after the key type
+func publicBlob(publicKey string) ([]byte, error) {
+	fields := strings.Fields(publicKey)
+	for i := 0; i+1 < len(fields); i++ {
+		if !strings.HasPrefix(fields[i], "ssh-") && !strings.HasPrefix(fields[i], "ecdsa-") {
+			continue
+		}
+		blob, err := base64.StdEncoding.DecodeString(fields[i+1])
+		if err != nil {
+			continue
+		}
+		if keyType, _, err := readString(blob); err == nil && string(keyType) == fields[i] {
+			return blob, nil
+		}
+	}
+	return nil, errors.New("invalid SSH public key: want the authorized_keys format, e.g. ssh-ed25519 AAAA...")
+}
+
+// openSSHPublicBlob reads the public key from the unencrypted header of an OpenSSH private key
+func openSSHPublicBlob(data []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(openSSHMagic))
+	if !ok {
+		return nil, errors.New("invalid OpenSSH private key")
+	}
+	// cipher, KDF, and KDF options precede the number of keys
+	for range 3 {
+		var err error
+		if _, rest, err = readString(rest); err != nil {
+			return nil, fmt.Errorf("invalid OpenSSH private key: %w", err)
+		}
+	}
+	if len(rest) < 4 || binary.BigEndian.Uint32(rest) != 1 {
+		return nil, errors.New("OpenSSH private key must contain exactly one key")
+	}
+	blob, _, err := readString(rest[4:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenSSH private key: %w", err)
+	}
+	return blob, nil
+}
+
+// ed25519Blob encodes an ed25519 public key in the SSH wire format
+func ed25519Blob(key ed25519.PublicKey) []byte {
+	var blob bytes.Buffer
+	writeString(&blob, []byte(TypeED25519))
+	writeString(&blob, key)
+	return blob.Bytes()
+}
+
+// rsaBlob encodes an RSA public key in the SSH wire format
+func rsaBlob(key *rsa.PublicKey) []byte {
+	var blob bytes.Buffer
+	writeString(&blob, []byte(TypeRSA))
+	writeString(&blob, mpint(big.NewInt(int64(key.E))))
+	writeString(&blob, mpint(key.N))
+	return blob.Bytes()
+}
+
+// ecdsaBlob encodes an ECDSA public key of a NIST curve in the SSH wire format
+func ecdsaBlob(key *ecdsa.PublicKey) ([]byte, error) {
+	curves := map[elliptic.Curve]string{
+		elliptic.P256(): "nistp256",
+		elliptic.P384(): "nistp384",
+		elliptic.P521(): "nistp521",
+	}
+	curve, ok := curves[key.Curve]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ECDSA curve %s", key.Params().Name)
+	}
+	point, err := key.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ECDSA public key: %w", err)
+	}
+	var blob bytes.Buffer
+	writeString(&blob, []byte("ecdsa-sha2-"+curve))
+	writeString(&blob, []byte(curve))
+	writeString(&blob, point)
+	return blob.Bytes(), nil
+}
+
+// mpint encodes n as an SSH multiple precision integer, with a leading zero byte if its high
+// bit is set
+func mpint(n *big.Int) []byte {
+	b := n.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return b
+}
+
+// writeString writes s with its 32-bit length, the SSH wire format of strings
+func writeString(buf *bytes.Buffer, s []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(s)))
+	buf.Write(s)
+}
+
+// readString reads an SSH wire format string from data and returns it and the remaining data
+func readString(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, errors.New("truncated data")
+	}
+	n := binary.BigEndian.Uint32(data)
+	if uint64(len(data)-4) < uint64(n) {
+		return nil, nil, errors.New("truncated data")
+	}
+	return data[4 : 4+n], data[4+n:], nil
+}
@@ -0,0 +1,15 @@
+//go:build test
+
+package sshkey_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSSHKey(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SSHKey Suite")
+}
@@ -2,14 +2,13 @@ package helpers
 
 import (
 	"fmt"
-	"os"
+
+	"github.com/redhat-openshift-partner-labs/labrat/pkg/kube"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
-	"sigs.k8s.io/yaml"
 )
 
 // CreateTestManagedCluster creates a test ManagedCluster with the specified name and availability status
@@ -66,58 +65,39 @@ func CreateTestManagedCluster(name string, available string) *clusterv1.ManagedC
 	return cluster
 }
 
-// LoadManagedClusterFromFile loads a ManagedCluster from a YAML file
+// LoadManagedClusterFromFile loads the first ManagedCluster of a YAML file, decoded like the
+// fixtures of the file backend of labrat --backend
 func LoadManagedClusterFromFile(path string) (*clusterv1.ManagedCluster, error) {
-	// Read the YAML file
-	data, err := os.ReadFile(path)
+	obj, err := loadFirstObject(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
-	}
-
-	// Create a scheme with the ManagedCluster type registered
-	scheme := runtime.NewScheme()
-	if err := clusterv1.AddToScheme(scheme); err != nil {
-		return nil, fmt.Errorf("failed to add clusterv1 to scheme: %w", err)
+		return nil, err
 	}
-
-	// Create a decoder
-	codecs := serializer.NewCodecFactory(scheme)
-	decoder := codecs.UniversalDeserializer()
-
-	// Decode the YAML
-	obj, _, err := decoder.Decode(data, nil, nil)
-	if err != nil {
-		// Try using sigs.k8s.io/yaml as fallback
-		cluster := &clusterv1.ManagedCluster{}
-		if yamlErr := yaml.Unmarshal(data, cluster); yamlErr != nil {
-			return nil, fmt.Errorf("failed to decode YAML (decoder: %v, yaml: %v)", err, yamlErr)
-		}
-		return cluster, nil
+	if obj.GetKind() != "ManagedCluster" {
+		return nil, fmt.Errorf("decoded object is not a ManagedCluster, got %s", obj.GetKind())
 	}
 
-	// Type assert to ManagedCluster
-	cluster, ok := obj.(*clusterv1.ManagedCluster)
-	if !ok {
-		return nil, fmt.Errorf("decoded object is not a ManagedCluster, got %T", obj)
+	cluster := &clusterv1.ManagedCluster{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, cluster); err != nil {
+		return nil, fmt.Errorf("failed to convert ManagedCluster: %w", err)
 	}
-
 	return cluster, nil
 }
 
 // LoadClusterDeploymentFromFile loads a ClusterDeployment as an unstructured object from a YAML file
 // We use unstructured.Unstructured to avoid importing the full Hive API
 func LoadClusterDeploymentFromFile(path string) (*unstructured.Unstructured, error) {
-	// Read the YAML file
-	data, err := os.ReadFile(path)
+	return loadFirstObject(path)
+}
+
+// loadFirstObject decodes the first Kubernetes object of a YAML file with the loader of the
+// file backend
+func loadFirstObject(path string) (*unstructured.Unstructured, error) {
+	objects, err := kube.LoadManifestFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+		return nil, err
 	}
-
-	// Parse YAML into unstructured format
-	obj := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal(data, obj); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("file %s contains no Kubernetes object", path)
 	}
-
-	return obj, nil
+	return objects[0], nil
 }